@@ -0,0 +1,89 @@
+// Package approval decides if the tool calls of the agents are executed (human-in-the-loop): an Approver allows,
+// denies or modifies each tool call. The approvers ask the user in the terminal (Terminal), apply policies
+// (Policy), or ask a remote reviewer through a webhook (Webhook) or Slack (Slack). Wrap plugs an approver into
+// the tool callback of DetectToolCalls.
+package approval
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Action is the decision of an approver on a tool call
+type Action string
+
+const (
+	// ActionAllow executes the tool call
+	ActionAllow Action = "allow"
+	// ActionDeny doesn't execute the tool call, the model is told it was not executed
+	ActionDeny Action = "deny"
+	// ActionModify executes the tool call with the arguments of the decision
+	ActionModify Action = "modify"
+	// ActionAsk is only used by the policies: the tool call is decided by the Ask approver of the policy
+	ActionAsk Action = "ask"
+)
+
+// ToolCall is a tool call to approve
+type ToolCall struct {
+	Name string `json:"name"`
+	// Arguments are the JSON arguments of the call
+	Arguments string `json:"arguments"`
+}
+
+// Decision is the answer of an approver
+type Decision struct {
+	Action Action `json:"action"`
+	// Arguments replace the arguments of the tool call with ActionModify
+	Arguments string `json:"arguments,omitempty"`
+	// Reason explains the decision, it is given to the model when the tool call is denied
+	Reason string `json:"reason,omitempty"`
+}
+
+// Approver decides if the tool calls are executed.
+// An error leaves the tool call unexecuted; an approver stops the tool calls loop with a mu.ExitToolCallsLoopError.
+type Approver interface {
+	Approve(ctx context.Context, call ToolCall) (Decision, error)
+}
+
+// ApproverFunc is a function implementing Approver
+type ApproverFunc func(ctx context.Context, call ToolCall) (Decision, error)
+
+// Approve calls the function
+func (f ApproverFunc) Approve(ctx context.Context, call ToolCall) (Decision, error) {
+	return f(ctx, call)
+}
+
+// NotExecutedResult returns the result given to the model for a tool call which was not executed
+func NotExecutedResult(reason string) string {
+	if reason == "" {
+		return `{"result": "Function not executed"}`
+	}
+	data, _ := json.Marshal(map[string]string{"result": "Function not executed", "reason": reason})
+	return string(data)
+}
+
+// Wrap returns a tool callback executing the tool calls approved by the approver with the next callback
+// (with the modified arguments for ActionModify)
+//
+// Example usage:
+//
+//	approver := &approval.Policy{
+//	  Rules: map[string]approval.Action{"read_file": approval.ActionAllow, "delete_*": approval.ActionDeny},
+//	  Ask:   approval.NewTerminal(),
+//	}
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, approval.Wrap(ctx, approver, executeFn))
+func Wrap(ctx context.Context, approver Approver, next func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		decision, err := approver.Approve(ctx, ToolCall{Name: functionName, Arguments: arguments})
+		if err != nil {
+			return NotExecutedResult(""), err
+		}
+		switch decision.Action {
+		case ActionAllow:
+			return next(functionName, arguments)
+		case ActionModify:
+			return next(functionName, decision.Arguments)
+		}
+		return NotExecutedResult(decision.Reason), nil
+	}
+}
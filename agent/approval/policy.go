@@ -0,0 +1,58 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// Policy decides the tool calls with rules by tool name, without asking anybody except for the ActionAsk tools
+type Policy struct {
+	// Rules give the action of the tools by name: ActionAllow, ActionDeny or ActionAsk.
+	// The names can be path.Match patterns ("github_*"), an exact name wins over the patterns.
+	Rules map[string]Action
+	// Default is the action of the tools without rule (ActionAsk if empty)
+	Default Action
+	// Ask decides the tool calls whose action is ActionAsk, they are denied if nil (nobody to ask)
+	Ask Approver
+}
+
+// Action returns the action of the rules for a tool
+func (policy *Policy) Action(toolName string) Action {
+	if action, ok := policy.Rules[toolName]; ok {
+		return action
+	}
+	// The patterns are sorted so the first matching pattern is always the same
+	patterns := make([]string, 0, len(policy.Rules))
+	for pattern := range policy.Rules {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, toolName); matched {
+			return policy.Rules[pattern]
+		}
+	}
+	if policy.Default != "" {
+		return policy.Default
+	}
+	return ActionAsk
+}
+
+// Approve applies the action of the tool
+func (policy *Policy) Approve(ctx context.Context, call ToolCall) (Decision, error) {
+	switch action := policy.Action(call.Name); action {
+	case ActionAllow:
+		return Decision{Action: ActionAllow}, nil
+	case ActionDeny:
+		return Decision{Action: ActionDeny, Reason: "denied by the policy"}, nil
+	case ActionAsk:
+		if policy.Ask == nil {
+			return Decision{Action: ActionDeny, Reason: "nobody can approve the tool call"}, nil
+		}
+		return policy.Ask.Approve(ctx, call)
+	default:
+		return Decision{}, fmt.Errorf("invalid policy action %q for the tool %s", action, call.Name)
+	}
+}
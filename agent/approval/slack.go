@@ -0,0 +1,227 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
+// The reactions of the Slack reviewers
+var (
+	slackAllowReactions = []string{"white_check_mark", "heavy_check_mark", "+1"}
+	slackDenyReactions  = []string{"x", "no_entry", "-1"}
+)
+
+// Slack asks the reviewers of a Slack channel: each tool call is posted by a bot, a reviewer allows it with
+// a ✅ reaction, denies it with ❌, or replies in the thread with the modified JSON arguments.
+// The bot needs the chat:write, reactions:read and channels:history (or groups:history) scopes.
+type Slack struct {
+	// Token is the token of the bot (xoxb-...)
+	Token   string
+	Channel string
+	// Title is the name of the agent in the messages ("An agent" if empty)
+	Title string
+	// Users are the IDs of the reviewers (everybody in the channel if empty)
+	Users []string
+	// Timeout is the time to wait for an answer, then the tool call is denied (5 minutes if zero)
+	Timeout time.Duration
+	// PollInterval is the interval between the checks of the answers (3 seconds if zero)
+	PollInterval time.Duration
+	// BaseURL is the URL of the Slack Web API (https://slack.com/api if empty)
+	BaseURL string
+	// Client sends the requests (an http.Client with a 30 seconds timeout if nil)
+	Client *http.Client
+}
+
+// slackMessage is a message of the Slack API
+type slackMessage struct {
+	User      string `json:"user"`
+	Text      string `json:"text"`
+	TS        string `json:"ts"`
+	Reactions []struct {
+		Name  string   `json:"name"`
+		Users []string `json:"users"`
+	} `json:"reactions"`
+}
+
+// slackResponse is the envelope of the responses of the Slack API
+type slackResponse struct {
+	OK       bool           `json:"ok"`
+	Error    string         `json:"error"`
+	Channel  string         `json:"channel"`
+	TS       string         `json:"ts"`
+	Message  slackMessage   `json:"message"`
+	Messages []slackMessage `json:"messages"`
+}
+
+// Approve posts the tool call to the channel and waits for the answer of a reviewer
+func (slack *Slack) Approve(ctx context.Context, call ToolCall) (Decision, error) {
+	title := slack.Title
+	if title == "" {
+		title = "An agent"
+	}
+	arguments := call.Arguments
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(arguments), "", "  ") == nil {
+		arguments = indented.String()
+	}
+	text := fmt.Sprintf("🔧 %s wants to call `%s` with:\n```%s```\nReact with :white_check_mark: to allow or :x: to deny, or reply in the thread with the modified JSON arguments.",
+		title, call.Name, arguments)
+	posted, err := slack.call(ctx, http.MethodPost, "chat.postMessage", map[string]any{"channel": slack.Channel, "text": text})
+	if err != nil {
+		return Decision{}, err
+	}
+	channel, ts := posted.Channel, posted.TS
+
+	timeout := slack.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+	interval := slack.PollInterval
+	if interval <= 0 {
+		interval = 3 * time.Second
+	}
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return Decision{}, ctx.Err()
+		case <-deadline.C:
+			decision := Decision{Action: ActionDeny, Reason: "no answer of the reviewers"}
+			slack.conclude(ctx, channel, ts, text, "⌛ No answer: denied")
+			return decision, nil
+		case <-ticker.C:
+			decision, user, decided, err := slack.answer(ctx, channel, ts)
+			if err != nil {
+				return Decision{}, err
+			}
+			if !decided {
+				continue
+			}
+			conclusion := map[Action]string{
+				ActionAllow:  "✅ Allowed by <@" + user + ">",
+				ActionDeny:   "❌ Denied by <@" + user + ">",
+				ActionModify: "✏️ Modified by <@" + user + ">",
+			}[decision.Action]
+			slack.conclude(ctx, channel, ts, text, conclusion)
+			return decision, nil
+		}
+	}
+}
+
+// answer returns the decision of the first reviewer who answered: a reaction or a reply with JSON arguments
+func (slack *Slack) answer(ctx context.Context, channel, ts string) (Decision, string, bool, error) {
+	reactions, err := slack.call(ctx, http.MethodGet, "reactions.get", map[string]any{"channel": channel, "timestamp": ts, "full": "true"})
+	if err != nil {
+		return Decision{}, "", false, err
+	}
+	for _, reaction := range reactions.Message.Reactions {
+		for _, user := range reaction.Users {
+			if !slack.reviewer(user) {
+				continue
+			}
+			name := strings.SplitN(reaction.Name, "::", 2)[0] // without the skin tone
+			switch {
+			case slices.Contains(slackAllowReactions, name):
+				return Decision{Action: ActionAllow}, user, true, nil
+			case slices.Contains(slackDenyReactions, name):
+				return Decision{Action: ActionDeny, Reason: "denied by a reviewer"}, user, true, nil
+			}
+		}
+	}
+	replies, err := slack.call(ctx, http.MethodGet, "conversations.replies", map[string]any{"channel": channel, "ts": ts})
+	if err != nil {
+		return Decision{}, "", false, err
+	}
+	for _, reply := range replies.Messages {
+		if reply.TS == ts || !slack.reviewer(reply.User) {
+			continue
+		}
+		if arguments, ok := slackJSON(reply.Text); ok {
+			return Decision{Action: ActionModify, Arguments: arguments, Reason: "arguments modified by a reviewer"}, reply.User, true, nil
+		}
+	}
+	return Decision{}, "", false, nil
+}
+
+// reviewer reports if a user can answer
+func (slack *Slack) reviewer(user string) bool {
+	return user != "" && (len(slack.Users) == 0 || slices.Contains(slack.Users, user))
+}
+
+// conclude adds the decision to the message (a failure is ignored: the decision is taken)
+func (slack *Slack) conclude(ctx context.Context, channel, ts, text, conclusion string) {
+	slack.call(ctx, http.MethodPost, "chat.update", map[string]any{"channel": channel, "ts": ts, "text": text + "\n\n" + conclusion})
+}
+
+// slackJSON extracts the JSON object of a reply, with or without a code block
+func slackJSON(text string) (string, bool) {
+	text = strings.TrimSpace(text)
+	text = strings.TrimPrefix(strings.TrimSuffix(text, "```"), "```")
+	text = strings.TrimPrefix(strings.TrimSpace(text), "json\n")
+	// Slack escapes these characters in the texts
+	text = strings.NewReplacer("&lt;", "<", "&gt;", ">", "&amp;", "&").Replace(strings.TrimSpace(text))
+	var compacted bytes.Buffer
+	if !strings.HasPrefix(text, "{") || json.Compact(&compacted, []byte(text)) != nil {
+		return "", false
+	}
+	return compacted.String(), true
+}
+
+// call calls a method of the Slack Web API: the POST parameters are sent as JSON, the GET ones in the query
+func (slack *Slack) call(ctx context.Context, method, apiMethod string, parameters map[string]any) (slackResponse, error) {
+	baseURL := slack.BaseURL
+	if baseURL == "" {
+		baseURL = "https://slack.com/api"
+	}
+	endpoint := strings.TrimSuffix(baseURL, "/") + "/" + apiMethod
+	var body io.Reader
+	if method == http.MethodGet {
+		query := url.Values{}
+		for name, value := range parameters {
+			query.Set(name, fmt.Sprint(value))
+		}
+		endpoint += "?" + query.Encode()
+	} else {
+		data, err := json.Marshal(parameters)
+		if err != nil {
+			return slackResponse{}, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return slackResponse{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+slack.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	}
+	client := slack.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return slackResponse{}, fmt.Errorf("slack %s: %w", apiMethod, err)
+	}
+	defer resp.Body.Close()
+	var response slackResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return slackResponse{}, fmt.Errorf("slack %s: %s: %w", apiMethod, resp.Status, err)
+	}
+	if !response.OK {
+		return slackResponse{}, fmt.Errorf("slack %s: %s", apiMethod, response.Error)
+	}
+	return response, nil
+}
@@ -0,0 +1,120 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// Terminal asks the user in the terminal to approve each tool call: yes, no, allow the tool for the session,
+// edit the arguments in the external editor, or abort the tool calls loop.
+// The questions are serialized, so it can be shared by concurrent tool calls.
+type Terminal struct {
+	// Color is the color of the question (ui.Gray if empty)
+	Color string
+	// Display shows the tool call before the question (its name and arguments if nil)
+	Display func(call ToolCall)
+	// Remember, if not nil, offers the "always" and "never" answers and persists them
+	// (ActionAllow or ActionDeny for the tool)
+	Remember func(toolName string, action Action) error
+
+	mutex          sync.Mutex
+	sessionAllowed map[string]bool
+}
+
+// NewTerminal creates a terminal approver
+func NewTerminal() *Terminal {
+	return &Terminal{sessionAllowed: map[string]bool{}}
+}
+
+// Approve asks the user, unless the tool was allowed for the session
+func (terminal *Terminal) Approve(ctx context.Context, call ToolCall) (Decision, error) {
+	terminal.mutex.Lock()
+	defer terminal.mutex.Unlock()
+	if terminal.sessionAllowed == nil {
+		terminal.sessionAllowed = map[string]bool{}
+	}
+	if terminal.sessionAllowed[call.Name] {
+		ui.Println(ui.GetTheme().Info, "✔ auto-approved for this session")
+		return Decision{Action: ActionAllow}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return Decision{}, err
+	}
+
+	if terminal.Display != nil {
+		terminal.Display(call)
+	} else {
+		fmt.Printf("🟢 %s with arguments:\n", call.Name)
+		ui.PrintJSON(call.Arguments)
+	}
+	color := terminal.Color
+	if color == "" {
+		color = ui.Gray
+	}
+	question := "Do you want to execute this function? (y)es (n)o (s)ession: allow for this session, (e)dit the arguments"
+	choices := []string{"y", "n", "s", "e"}
+	if terminal.Remember != nil {
+		question += ", always, never"
+		choices = append(choices, "always", "never")
+	}
+	question += ", (a)bort"
+	choices = append(choices, "a")
+
+	for {
+		switch choice := ui.GetChoice(color, question, choices, "y"); choice {
+		case "n":
+			return Decision{Action: ActionDeny, Reason: "denied by the user"}, nil
+		case "a":
+			return Decision{}, &mu.ExitToolCallsLoopError{Message: "Tool execution aborted by user"}
+		case "s":
+			terminal.sessionAllowed[call.Name] = true
+			return Decision{Action: ActionAllow}, nil
+		case "e":
+			arguments, err := editArguments(call.Arguments)
+			if err != nil {
+				ui.Println(ui.GetTheme().Warning, "✖", err)
+				continue
+			}
+			return Decision{Action: ActionModify, Arguments: arguments, Reason: "arguments edited by the user"}, nil
+		case "always", "never":
+			action := ActionAllow
+			if choice == "never" {
+				action = ActionDeny
+			}
+			if err := terminal.Remember(call.Name, action); err != nil {
+				ui.Println(ui.GetTheme().Warning, "✖ the policy is not saved:", err)
+			}
+			if action == ActionDeny {
+				return Decision{Action: ActionDeny, Reason: "denied by the user"}, nil
+			}
+			return Decision{Action: ActionAllow}, nil
+		default:
+			return Decision{Action: ActionAllow}, nil
+		}
+	}
+}
+
+// editArguments opens the arguments in the external editor and returns the edited JSON (compacted)
+func editArguments(arguments string) (string, error) {
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, []byte(arguments), "", "  "); err != nil {
+		indented.Reset()
+		indented.WriteString(arguments)
+	}
+	edited, err := ui.EditInEditor(indented.String())
+	if err != nil {
+		return "", err
+	}
+	var compacted bytes.Buffer
+	if err := json.Compact(&compacted, []byte(strings.TrimSpace(edited))); err != nil {
+		return "", fmt.Errorf("the edited arguments are not valid JSON: %w", err)
+	}
+	return compacted.String(), nil
+}
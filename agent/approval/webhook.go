@@ -0,0 +1,88 @@
+package approval
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/async"
+
+	"github.com/google/uuid"
+)
+
+// Request is the body posted by Webhook for each tool call
+type Request struct {
+	ID       string    `json:"id"`
+	ToolCall ToolCall  `json:"tool_call"`
+	Time     time.Time `json:"time"`
+}
+
+// Webhook asks a remote reviewer: each tool call is posted to the URL (signed like the async webhooks, see
+// async.Verify) and the response is the JSON decision, e.g. {"action": "deny", "reason": "not during a freeze"}.
+// The receiver can hold the request until a human answers.
+type Webhook struct {
+	URL string
+	// Secret signs the requests (no signature if empty)
+	Secret string
+	// Headers are added to the requests
+	Headers map[string]string
+	// Client sends the requests (an http.Client with a 5 minutes timeout if nil)
+	Client *http.Client
+}
+
+// Approve posts the tool call and returns the decision of the response
+func (webhook *Webhook) Approve(ctx context.Context, call ToolCall) (Decision, error) {
+	body, err := json.Marshal(Request{ID: "approval-" + uuid.New().String(), ToolCall: call, Time: time.Now()})
+	if err != nil {
+		return Decision{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return Decision{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(async.EventHeader, "tool_call.approval")
+	timestamp := time.Now().Unix()
+	req.Header.Set(async.TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if webhook.Secret != "" {
+		req.Header.Set(async.SignatureHeader, async.Sign(webhook.Secret, timestamp, body))
+	}
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	client := webhook.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Decision{}, fmt.Errorf("approval webhook %s: %w", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return Decision{}, err
+	}
+	if resp.StatusCode >= 300 {
+		return Decision{}, fmt.Errorf("approval webhook %s: %s: %s", webhook.URL, resp.Status, bytes.TrimSpace(data))
+	}
+	var decision Decision
+	if err := json.Unmarshal(data, &decision); err != nil {
+		return Decision{}, fmt.Errorf("approval webhook %s: invalid decision: %w", webhook.URL, err)
+	}
+	switch decision.Action {
+	case ActionAllow, ActionDeny:
+	case ActionModify:
+		if !json.Valid([]byte(decision.Arguments)) {
+			return Decision{}, fmt.Errorf("approval webhook %s: the modified arguments are not valid JSON", webhook.URL)
+		}
+	default:
+		return Decision{}, fmt.Errorf("approval webhook %s: invalid action %q", webhook.URL, decision.Action)
+	}
+	return decision, nil
+}
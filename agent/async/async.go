@@ -0,0 +1,330 @@
+// Package async runs long completions in the background for the job systems: a run is submitted and
+// gets an ID immediately, its progress (the streamed content) and its result are delivered to a webhook
+// signed with HMAC-SHA256, and its status can be polled.
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+)
+
+// Status is the status of a run
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusCompleted Status = "completed"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// EventType is the type of an event of a run
+type EventType string
+
+const (
+	EventStarted   EventType = "run.started"
+	EventProgress  EventType = "run.progress"
+	EventCompleted EventType = "run.completed"
+	EventFailed    EventType = "run.failed"
+	EventCancelled EventType = "run.cancelled"
+)
+
+// Event is delivered to the webhook
+type Event struct {
+	Type  EventType `json:"type"`
+	RunID string    `json:"run_id"`
+	Time  time.Time `json:"time"`
+	// Delta is the content streamed since the previous progress event
+	Delta string `json:"delta,omitempty"`
+	// Output is the answer of a completed run
+	Output   string            `json:"output,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Run is the state of a run
+type Run struct {
+	ID       string            `json:"id"`
+	Status   Status            `json:"status"`
+	Output   string            `json:"output,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Created  time.Time         `json:"created"`
+	Finished time.Time         `json:"finished,omitzero"`
+}
+
+// Job is the work of a run: it reports the streamed content with progress (an error of progress stops the job)
+// and returns the output
+type Job func(ctx context.Context, progress func(delta string) error) (string, error)
+
+// AgentJob is the job of an agent answering the messages, through the tool calls loop with a tool callback
+func AgentJob(agent mu.Agent, messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error)) Job {
+	return func(ctx context.Context, progress func(delta string) error) (string, error) {
+		if toolCallback == nil {
+			return agent.RunStream(messages, progress)
+		}
+		_, _, output, err := agent.DetectToolCallsStream(messages, toolCallback, progress)
+		return output, err
+	}
+}
+
+// RunnerOption is a functional option for configuring Runner instances
+type RunnerOption func(*Runner)
+
+// Runner executes the runs in the background and delivers their events to its webhook
+type Runner struct {
+	mutex            sync.Mutex
+	runs             map[string]*run
+	order            []string
+	webhook          *Webhook
+	progressInterval time.Duration
+	retention        time.Duration
+	maxRuns          int
+	logger           logging.Logger
+	running          sync.WaitGroup
+}
+
+// run is a run with its cancellation
+type run struct {
+	Run
+	cancel context.CancelFunc
+}
+
+// NewRunner creates a runner
+//
+// Example usage:
+//
+//	runner := async.NewRunner(
+//	  async.WithWebhook(&async.Webhook{URL: "https://jobs.example.com/hooks/agent", Secret: secret}),
+//	  async.WithProgressInterval(2*time.Second),
+//	)
+//	id := runner.Submit(async.AgentJob(agent, messages, executeFn), map[string]string{"job": "42"})
+func NewRunner(options ...RunnerOption) *Runner {
+	runner := &Runner{
+		runs:      map[string]*run{},
+		retention: time.Hour,
+		maxRuns:   1000,
+	}
+	for _, option := range options {
+		option(runner)
+	}
+	return runner
+}
+
+// WithWebhook sets the webhook receiving the events of the runs (the runs are only polled without webhook)
+func WithWebhook(webhook *Webhook) RunnerOption {
+	return func(r *Runner) {
+		r.webhook = webhook
+	}
+}
+
+// WithProgressInterval enables the progress events: the content streamed during each interval is delivered
+// in a run.progress event (no progress events by default)
+func WithProgressInterval(interval time.Duration) RunnerOption {
+	return func(r *Runner) {
+		r.progressInterval = interval
+	}
+}
+
+// WithRetention sets how long the finished runs are kept for polling (1 hour by default), and the maximum
+// number of runs kept (1000 by default, the oldest finished runs are removed first)
+func WithRetention(retention time.Duration, maxRuns int) RunnerOption {
+	return func(r *Runner) {
+		r.retention = retention
+		r.maxRuns = maxRuns
+	}
+}
+
+// WithLogger sets the logger of the runner (logging.Default() otherwise)
+func WithLogger(logger logging.Logger) RunnerOption {
+	return func(r *Runner) {
+		r.logger = logger
+	}
+}
+
+// log returns the logger of the runner
+func (r *Runner) log() logging.Logger {
+	return logging.OrDefault(r.logger)
+}
+
+// Submit starts a job in the background and returns the ID of its run
+func (r *Runner) Submit(job Job, metadata map[string]string) string {
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &run{
+		Run: Run{
+			ID:       "run-" + uuid.New().String(),
+			Status:   StatusRunning,
+			Metadata: metadata,
+			Created:  time.Now(),
+		},
+		cancel: cancel,
+	}
+	r.mutex.Lock()
+	r.prune()
+	r.runs[current.ID] = current
+	r.order = append(r.order, current.ID)
+	r.mutex.Unlock()
+
+	r.running.Add(1)
+	go func() {
+		defer r.running.Done()
+		defer cancel()
+		r.execute(ctx, current, job)
+	}()
+	return current.ID
+}
+
+// execute runs a job and delivers its events, in order
+func (r *Runner) execute(ctx context.Context, current *run, job Job) {
+	events := make(chan Event, 16)
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		for event := range events {
+			if r.webhook == nil {
+				continue
+			}
+			if err := r.webhook.Deliver(context.Background(), event); err != nil {
+				r.log().Error("webhook delivery failed", "run", event.RunID, "event", event.Type, "error", err)
+			}
+		}
+	}()
+	newEvent := func(eventType EventType) Event {
+		return Event{Type: eventType, RunID: current.ID, Time: time.Now(), Metadata: current.Metadata}
+	}
+	events <- newEvent(EventStarted)
+
+	// the streamed content is buffered and delivered at each interval
+	var buffer strings.Builder
+	var bufferMutex sync.Mutex
+	flush := func() {
+		bufferMutex.Lock()
+		defer bufferMutex.Unlock()
+		if buffer.Len() == 0 {
+			return
+		}
+		event := newEvent(EventProgress)
+		event.Delta = buffer.String()
+		buffer.Reset()
+		events <- event
+	}
+	stopTicker := make(chan struct{})
+	tickerDone := make(chan struct{})
+	go func() {
+		defer close(tickerDone)
+		if r.progressInterval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(r.progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stopTicker:
+				return
+			case <-ticker.C:
+				flush()
+			}
+		}
+	}()
+	progress := func(delta string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if r.progressInterval > 0 {
+			bufferMutex.Lock()
+			buffer.WriteString(delta)
+			bufferMutex.Unlock()
+		}
+		return nil
+	}
+
+	output, err := job(ctx, progress)
+	close(stopTicker)
+	<-tickerDone
+	flush()
+
+	r.mutex.Lock()
+	current.Finished = time.Now()
+	var event Event
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		current.Status = StatusCancelled
+		current.Output = output
+		event = newEvent(EventCancelled)
+	case err != nil:
+		current.Status = StatusFailed
+		current.Error = err.Error()
+		event = newEvent(EventFailed)
+		event.Error = current.Error
+	default:
+		current.Status = StatusCompleted
+		current.Output = output
+		event = newEvent(EventCompleted)
+		event.Output = output
+	}
+	r.mutex.Unlock()
+	r.log().Info("run finished", "run", current.ID, "status", current.Status)
+
+	events <- event
+	close(events)
+	<-delivered
+}
+
+// Get returns the state of a run
+func (r *Runner) Get(id string) (Run, bool) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	current, ok := r.runs[id]
+	if !ok {
+		return Run{}, false
+	}
+	return current.Run, true
+}
+
+// Cancel cancels a running run (the job stops at its next progress)
+func (r *Runner) Cancel(id string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	current, ok := r.runs[id]
+	if !ok {
+		return fmt.Errorf("unknown run %s", id)
+	}
+	if current.Status != StatusRunning {
+		return fmt.Errorf("the run %s is %s", id, current.Status)
+	}
+	current.cancel()
+	return nil
+}
+
+// Wait waits for the runs in progress and the delivery of their events
+func (r *Runner) Wait() {
+	r.running.Wait()
+}
+
+// prune removes the finished runs older than the retention, and the oldest finished runs above the maximum
+// (the mutex must be held)
+func (r *Runner) prune() {
+	kept := r.order[:0]
+	excess := len(r.order) - r.maxRuns + 1
+	for _, id := range r.order {
+		current := r.runs[id]
+		finished := current.Status != StatusRunning
+		if finished && (time.Since(current.Finished) > r.retention || (r.maxRuns > 0 && excess > 0)) {
+			delete(r.runs, id)
+			excess--
+			continue
+		}
+		kept = append(kept, id)
+	}
+	r.order = kept
+}
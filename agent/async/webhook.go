@@ -0,0 +1,151 @@
+package async
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The headers of the webhook requests
+const (
+	// SignatureHeader is "sha256=<hex HMAC-SHA256 of <timestamp>.<body> with the secret>"
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader is the Unix time of the delivery, signed with the body against replays
+	TimestampHeader = "X-Webhook-Timestamp"
+	// EventHeader is the type of the event
+	EventHeader = "X-Webhook-Event"
+	// RunIDHeader is the ID of the run
+	RunIDHeader = "X-Run-ID"
+)
+
+// Webhook delivers the events of the runs to a URL, signed with HMAC-SHA256 when it has a secret
+type Webhook struct {
+	URL string
+	// Secret signs the deliveries (no signature if empty)
+	Secret string
+	// Headers are added to the requests
+	Headers map[string]string
+	// Client sends the requests (an http.Client with a 30 seconds timeout if nil)
+	Client *http.Client
+	// MaxAttempts is the number of attempts of a delivery failing with a network error or a 5xx/429 status (3 if zero)
+	MaxAttempts int
+	// Backoff is the delay before the second attempt, doubled at each attempt (1 second if zero)
+	Backoff time.Duration
+}
+
+// Sign returns the signature of a body at a timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver posts an event to the URL of the webhook, retrying the transient failures
+func (webhook *Webhook) Deliver(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	attempts := webhook.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	backoff := webhook.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	for attempt := 1; ; attempt++ {
+		retry, err := webhook.send(ctx, event, body)
+		if err == nil || !retry || attempt >= attempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send posts the body of an event once, it reports if the failure is transient
+func (webhook *Webhook) send(ctx context.Context, event Event, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(event.Type))
+	req.Header.Set(RunIDHeader, event.RunID)
+	timestamp := time.Now().Unix()
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	if webhook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, body))
+	}
+	for name, value := range webhook.Headers {
+		req.Header.Set(name, value)
+	}
+	client := webhook.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("webhook %s: %w", webhook.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		retry := resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		return retry, fmt.Errorf("webhook %s: %s: %s", webhook.URL, resp.Status, bytes.TrimSpace(message))
+	}
+	return false, nil
+}
+
+// ErrInvalidSignature is returned by Verify for the requests without a valid signature
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verify checks the signature and the timestamp of a webhook request received by a receiver, and returns its body.
+// The requests older than tolerance are refused (no check of the age if zero).
+//
+// Example usage:
+//
+//	body, err := async.Verify(r, secret, 5*time.Minute)
+//	if err != nil {
+//	  http.Error(w, err.Error(), http.StatusUnauthorized)
+//	  return
+//	}
+//	var event async.Event
+//	err = json.Unmarshal(body, &event)
+func Verify(r *http.Request, secret string, tolerance time.Duration) ([]byte, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid timestamp", ErrInvalidSignature)
+	}
+	if tolerance > 0 {
+		age := time.Since(time.Unix(timestamp, 0))
+		if age > tolerance || age < -tolerance {
+			return nil, fmt.Errorf("%w: expired timestamp", ErrInvalidSignature)
+		}
+	}
+	signature := strings.TrimSpace(r.Header.Get(SignatureHeader))
+	if !hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return nil, ErrInvalidSignature
+	}
+	return body, nil
+}
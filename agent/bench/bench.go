@@ -0,0 +1,348 @@
+// Package bench compares models on a prompt set: each prompt is streamed to each model to measure the time to
+// the first token and the generation speed, the tool calls are checked against the expected tool and the
+// structured outputs against their JSON schema, and the results are summed up in a comparison table.
+// It helps to choose a small local model for a task.
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// Prompt is a prompt of the set, declared in Go or in a JSON file
+type Prompt struct {
+	Name   string `json:"name"`
+	System string `json:"system,omitempty"`
+	Prompt string `json:"prompt"`
+	// Tools are offered to the model
+	Tools []Tool `json:"tools,omitempty"`
+	// ExpectTool is the name of the tool the model must call (the tool-call success is not measured if empty)
+	ExpectTool string `json:"expect_tool,omitempty"`
+	// ExpectArguments are arguments the tool call must have (the other arguments are ignored)
+	ExpectArguments map[string]any `json:"expect_arguments,omitempty"`
+	// Schema asks a structured output: the answer must be a JSON value valid against this JSON schema
+	Schema map[string]any `json:"schema,omitempty"`
+}
+
+// Tool is a function tool offered to the model
+type Tool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// Target is a model to benchmark
+type Target struct {
+	// Name is the label of the model in the report (the model if empty)
+	Name   string
+	Client openai.Client
+	Model  string
+	// Params are the completion parameters (temperature...), their model, messages, tools and response format are replaced
+	Params openai.ChatCompletionNewParams
+}
+
+// Result is the measure of a prompt run against a model
+type Result struct {
+	Target string `json:"target"`
+	Prompt string `json:"prompt"`
+	Run    int    `json:"run"`
+	// TimeToFirstToken is the time to the first content or tool call chunk
+	TimeToFirstToken time.Duration `json:"time_to_first_token"`
+	Duration         time.Duration `json:"duration"`
+	CompletionTokens int64         `json:"completion_tokens"`
+	// TokensPerSecond is the generation speed after the first token
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	// EstimatedTokens is true when the provider didn't report the usage (see helpers.EstimateTokens)
+	EstimatedTokens bool   `json:"estimated_tokens,omitempty"`
+	Answer          string `json:"answer,omitempty"`
+	ToolCall        string `json:"tool_call,omitempty"`
+	// ToolCallOK and StructuredOK are nil when the prompt doesn't measure them
+	ToolCallOK   *bool  `json:"tool_call_ok,omitempty"`
+	StructuredOK *bool  `json:"structured_ok,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// BenchmarkOption is a functional option for configuring Benchmark instances
+type BenchmarkOption func(*Benchmark)
+
+// Benchmark runs a prompt set against models
+type Benchmark struct {
+	targets  []Target
+	prompts  []Prompt
+	runs     int
+	timeout  time.Duration
+	onResult func(result Result)
+}
+
+// NewBenchmark creates a benchmark
+//
+// Example usage:
+//
+//	benchmark := bench.NewBenchmark(
+//	  bench.WithTargets(
+//	    bench.Target{Client: client, Model: "ai/qwen2.5:1.5B-F16"},
+//	    bench.Target{Client: client, Model: "hf.co/menlo/jan-nano-gguf:q4_k_m"},
+//	  ),
+//	  bench.WithPrompts(prompts...),
+//	  bench.WithRuns(3),
+//	)
+//	report, err := benchmark.Run(ctx)
+//	fmt.Println(report.Markdown())
+func NewBenchmark(options ...BenchmarkOption) *Benchmark {
+	benchmark := &Benchmark{runs: 1, timeout: 2 * time.Minute}
+	for _, option := range options {
+		option(benchmark)
+	}
+	return benchmark
+}
+
+// WithTargets is a functional option that adds models to the benchmark
+func WithTargets(targets ...Target) BenchmarkOption {
+	return func(benchmark *Benchmark) {
+		benchmark.targets = append(benchmark.targets, targets...)
+	}
+}
+
+// WithPrompts is a functional option that adds prompts to the benchmark
+func WithPrompts(prompts ...Prompt) BenchmarkOption {
+	return func(benchmark *Benchmark) {
+		benchmark.prompts = append(benchmark.prompts, prompts...)
+	}
+}
+
+// WithRuns is a functional option that sets the number of runs of each prompt (1 by default),
+// the measures of the runs are averaged in the summary
+func WithRuns(runs int) BenchmarkOption {
+	return func(benchmark *Benchmark) {
+		benchmark.runs = runs
+	}
+}
+
+// WithTimeout is a functional option that sets the maximum duration of a completion (2 minutes by default)
+func WithTimeout(timeout time.Duration) BenchmarkOption {
+	return func(benchmark *Benchmark) {
+		benchmark.timeout = timeout
+	}
+}
+
+// WithOnResult is a functional option that sets the callback called after each completion (e.g. a progress display)
+func WithOnResult(callback func(result Result)) BenchmarkOption {
+	return func(benchmark *Benchmark) {
+		benchmark.onResult = callback
+	}
+}
+
+// LoadPrompts reads the prompts from a JSON file (an array of prompts)
+func LoadPrompts(path string) ([]Prompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prompts := []Prompt{}
+	if err := json.Unmarshal(data, &prompts); err != nil {
+		return nil, fmt.Errorf("invalid prompts file %s: %w", path, err)
+	}
+	return prompts, nil
+}
+
+// Run runs each prompt against each model, one completion at a time so the measures don't interfere.
+// The errors of the completions are reported in their results, Run only fails without models or prompts
+// or when the context is canceled.
+func (benchmark *Benchmark) Run(ctx context.Context) (*Report, error) {
+	if len(benchmark.targets) == 0 {
+		return nil, errors.New("no model to benchmark")
+	}
+	if len(benchmark.prompts) == 0 {
+		return nil, errors.New("no prompt to run")
+	}
+	for _, prompt := range benchmark.prompts {
+		if prompt.Prompt == "" {
+			return nil, fmt.Errorf("the prompt %q is empty", prompt.Name)
+		}
+	}
+
+	start := time.Now()
+	results := []Result{}
+	for _, target := range benchmark.targets {
+		for _, prompt := range benchmark.prompts {
+			for run := 1; run <= max(benchmark.runs, 1); run++ {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				result := benchmark.measure(ctx, target, prompt)
+				result.Run = run
+				results = append(results, result)
+				if benchmark.onResult != nil {
+					benchmark.onResult(result)
+				}
+			}
+		}
+	}
+	return newReport(benchmark.targets, results, time.Since(start)), nil
+}
+
+// measure streams a prompt to a model and checks its answer
+func (benchmark *Benchmark) measure(ctx context.Context, target Target, prompt Prompt) Result {
+	result := Result{Target: target.label(), Prompt: prompt.Name}
+	if benchmark.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, benchmark.timeout)
+		defer cancel()
+	}
+
+	params := target.Params
+	params.Model = target.Model
+	params.Messages = []openai.ChatCompletionMessageParamUnion{}
+	if prompt.System != "" {
+		params.Messages = append(params.Messages, openai.SystemMessage(prompt.System))
+	}
+	params.Messages = append(params.Messages, openai.UserMessage(prompt.Prompt))
+	params.Tools = nil
+	for _, tool := range prompt.Tools {
+		parameters := tool.Parameters
+		if parameters == nil {
+			parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+		}
+		params.Tools = append(params.Tools, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        tool.Name,
+			Description: openai.String(tool.Description),
+			Parameters:  shared.FunctionParameters(parameters),
+		}))
+	}
+	params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{}
+	if prompt.Schema != nil {
+		params.ResponseFormat.OfJSONSchema = &openai.ResponseFormatJSONSchemaParam{
+			JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{Name: "answer", Schema: prompt.Schema},
+		}
+	}
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	start := time.Now()
+	stream := target.Client.Chat.Completions.NewStreaming(ctx, params)
+	accumulator := openai.ChatCompletionAccumulator{}
+	for stream.Next() {
+		chunk := stream.Current()
+		if result.TimeToFirstToken == 0 && len(chunk.Choices) > 0 &&
+			(chunk.Choices[0].Delta.Content != "" || len(chunk.Choices[0].Delta.ToolCalls) > 0) {
+			result.TimeToFirstToken = time.Since(start)
+		}
+		accumulator.AddChunk(chunk)
+	}
+	result.Duration = time.Since(start)
+	err := stream.Err()
+	stream.Close()
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	var toolCalls []openai.ChatCompletionMessageToolCallUnion
+	if len(accumulator.Choices) > 0 {
+		result.Answer = accumulator.Choices[0].Message.Content
+		toolCalls = accumulator.Choices[0].Message.ToolCalls
+	}
+	result.CompletionTokens = accumulator.Usage.CompletionTokens
+	if result.CompletionTokens == 0 {
+		// Estimated when the provider doesn't report the usage
+		generated := result.Answer
+		for _, toolCall := range toolCalls {
+			generated += toolCall.Function.Name + toolCall.Function.Arguments
+		}
+		result.CompletionTokens = int64(helpers.EstimateTokens(generated, target.Model))
+		result.EstimatedTokens = true
+	}
+	if generation := result.Duration - result.TimeToFirstToken; generation > 0 && result.CompletionTokens > 1 {
+		// The first token is generated during the time to the first token
+		result.TokensPerSecond = float64(result.CompletionTokens-1) / generation.Seconds()
+	}
+
+	if prompt.ExpectTool != "" {
+		ok, reason := checkToolCall(prompt, toolCalls)
+		result.ToolCallOK, result.Reason = &ok, reason
+	}
+	if len(toolCalls) > 0 {
+		result.ToolCall = toolCalls[0].Function.Name + " " + toolCalls[0].Function.Arguments
+	}
+	if prompt.Schema != nil {
+		ok, reason := checkStructuredOutput(prompt.Schema, result.Answer)
+		result.StructuredOK = &ok
+		if reason != "" {
+			result.Reason = joinReasons(result.Reason, reason)
+		}
+	}
+	return result
+}
+
+// label returns the name of a target in the report
+func (target Target) label() string {
+	if target.Name != "" {
+		return target.Name
+	}
+	return target.Model
+}
+
+// checkToolCall checks the first tool call against the expected tool and arguments
+func checkToolCall(prompt Prompt, toolCalls []openai.ChatCompletionMessageToolCallUnion) (bool, string) {
+	if len(toolCalls) == 0 {
+		return false, "no tool call"
+	}
+	function := toolCalls[0].Function
+	if function.Name != prompt.ExpectTool {
+		return false, fmt.Sprintf("called %s instead of %s", function.Name, prompt.ExpectTool)
+	}
+	arguments := map[string]any{}
+	if err := json.Unmarshal([]byte(function.Arguments), &arguments); err != nil {
+		return false, "invalid JSON arguments"
+	}
+	for name, expected := range prompt.ExpectArguments {
+		if !sameJSON(arguments[name], expected) {
+			return false, fmt.Sprintf("argument %s is %v instead of %v", name, arguments[name], expected)
+		}
+	}
+	return true, ""
+}
+
+// checkStructuredOutput checks that an answer is a JSON value valid against a schema
+func checkStructuredOutput(schema map[string]any, answer string) (bool, string) {
+	var value any
+	if err := json.Unmarshal([]byte(answer), &value); err != nil {
+		return false, "the answer is not valid JSON"
+	}
+	if err := validateSchema(schema, value, "$"); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// sameJSON compares two JSON values (the numbers as float64)
+func sameJSON(a, b any) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	var normalizedA, normalizedB any
+	json.Unmarshal(dataA, &normalizedA)
+	json.Unmarshal(dataB, &normalizedB)
+	dataA, _ = json.Marshal(normalizedA)
+	dataB, _ = json.Marshal(normalizedB)
+	return string(dataA) == string(dataB)
+}
+
+// joinReasons joins two failure reasons
+func joinReasons(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "; " + b
+}
@@ -0,0 +1,147 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report gathers the results of a benchmark run
+type Report struct {
+	Date      time.Time     `json:"date"`
+	Duration  time.Duration `json:"duration"`
+	Summaries []Summary     `json:"summaries"`
+	Results   []Result      `json:"results"`
+}
+
+// Summary is the mean measures of a model on all the prompts
+type Summary struct {
+	Target string `json:"target"`
+	Model  string `json:"model"`
+	Runs   int    `json:"runs"`
+	Errors int    `json:"errors"`
+	// MeanTimeToFirstToken and MeanTokensPerSecond are the means of the completions without error
+	MeanTimeToFirstToken time.Duration `json:"mean_time_to_first_token"`
+	MeanTokensPerSecond  float64       `json:"mean_tokens_per_second"`
+	EstimatedTokens      bool          `json:"estimated_tokens,omitempty"`
+	ToolCalls            int           `json:"tool_calls"`
+	ToolCallsOK          int           `json:"tool_calls_ok"`
+	StructuredOutputs    int           `json:"structured_outputs"`
+	StructuredOutputsOK  int           `json:"structured_outputs_ok"`
+}
+
+// newReport computes the summaries of the models
+func newReport(targets []Target, results []Result, duration time.Duration) *Report {
+	report := &Report{Date: time.Now(), Duration: duration, Results: results}
+	for _, target := range targets {
+		summary := Summary{Target: target.label(), Model: target.Model}
+		var timeToFirstToken time.Duration
+		tokensPerSecond, completed := 0.0, 0
+		for _, result := range results {
+			if result.Target != summary.Target {
+				continue
+			}
+			summary.Runs++
+			if result.Error != "" {
+				summary.Errors++
+				continue
+			}
+			completed++
+			timeToFirstToken += result.TimeToFirstToken
+			tokensPerSecond += result.TokensPerSecond
+			summary.EstimatedTokens = summary.EstimatedTokens || result.EstimatedTokens
+			if result.ToolCallOK != nil {
+				summary.ToolCalls++
+				if *result.ToolCallOK {
+					summary.ToolCallsOK++
+				}
+			}
+			if result.StructuredOK != nil {
+				summary.StructuredOutputs++
+				if *result.StructuredOK {
+					summary.StructuredOutputsOK++
+				}
+			}
+		}
+		if completed > 0 {
+			summary.MeanTimeToFirstToken = timeToFirstToken / time.Duration(completed)
+			summary.MeanTokensPerSecond = tokensPerSecond / float64(completed)
+		}
+		report.Summaries = append(report.Summaries, summary)
+	}
+	return report
+}
+
+// Markdown returns the report as a comparison table of the models followed by the failures
+func (report *Report) Markdown() string {
+	var builder strings.Builder
+	builder.WriteString("# Benchmark\n\n")
+	fmt.Fprintf(&builder, "%s, in %s\n\n", report.Date.Format("2006-01-02 15:04"), report.Duration.Round(time.Millisecond))
+	builder.WriteString("| Model | Runs | Time to first token | Tokens/s | Tool calls | Structured outputs | Errors |\n")
+	builder.WriteString("|-------|------|---------------------|----------|------------|--------------------|--------|\n")
+	estimated := false
+	for _, summary := range report.Summaries {
+		speed := fmt.Sprintf("%.1f", summary.MeanTokensPerSecond)
+		if summary.EstimatedTokens {
+			speed += "*"
+			estimated = true
+		}
+		fmt.Fprintf(&builder, "| %s | %d | %s | %s | %s | %s | %d |\n", summary.Target, summary.Runs,
+			summary.MeanTimeToFirstToken.Round(time.Millisecond), speed,
+			ratio(summary.ToolCallsOK, summary.ToolCalls), ratio(summary.StructuredOutputsOK, summary.StructuredOutputs),
+			summary.Errors)
+	}
+	if estimated {
+		builder.WriteString("\n\\* the provider didn't report the usage, the tokens are estimated\n")
+	}
+
+	failures := []string{}
+	replacer := strings.NewReplacer("|", "\\|", "\n", " ")
+	for _, result := range report.Results {
+		reason := result.Reason
+		if result.Error != "" {
+			reason = "error: " + result.Error
+		}
+		if reason == "" {
+			continue
+		}
+		failures = append(failures, fmt.Sprintf("| %s | %s | %d | %s |", result.Target, result.Prompt, result.Run, replacer.Replace(reason)))
+	}
+	if len(failures) > 0 {
+		builder.WriteString("\n## Failures\n\n| Model | Prompt | Run | Reason |\n|-------|--------|-----|--------|\n")
+		builder.WriteString(strings.Join(failures, "\n") + "\n")
+	}
+	return builder.String()
+}
+
+// ratio formats a success count, "-" when nothing was measured
+func ratio(ok, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (%.0f%%)", ok, total, 100*float64(ok)/float64(total))
+}
+
+// Save writes the report to a JSON file
+func (report *Report) Save(path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadReport reads a report saved as JSON
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid report file %s: %w", path, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,121 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// validateSchema checks a JSON value against the common keywords of a JSON schema: type, properties,
+// required, additionalProperties (false), items, enum, minItems and maxItems
+func validateSchema(schema map[string]any, value any, path string) error {
+	if expected, ok := schema["type"]; ok {
+		types := []string{}
+		switch expected := expected.(type) {
+		case string:
+			types = append(types, expected)
+		case []any:
+			for _, t := range expected {
+				if name, ok := t.(string); ok {
+					types = append(types, name)
+				}
+			}
+		}
+		matched := false
+		for _, name := range types {
+			if hasType(value, name) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s is not of type %v", path, expected)
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if sameJSON(value, allowed) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s is not one of %v", path, enum)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if name, ok := name.(string); ok {
+					if _, exists := value[name]; !exists {
+						return fmt.Errorf("%s.%s is required", path, name)
+					}
+				}
+			}
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					return fmt.Errorf("%s.%s is not allowed", path, name)
+				}
+				continue
+			}
+			if err := validateSchema(property, value[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if minItems, ok := schema["minItems"].(float64); ok && float64(len(value)) < minItems {
+			return fmt.Errorf("%s has less than %v items", path, minItems)
+		}
+		if maxItems, ok := schema["maxItems"].(float64); ok && float64(len(value)) > maxItems {
+			return fmt.Errorf("%s has more than %v items", path, maxItems)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports if a JSON value has a JSON schema type
+func hasType(value any, name string) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		number, ok := value.(float64)
+		return ok && number == math.Trunc(number)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Agent is an agent built from its definition, with its tools, its tool policies and its retriever
+type Agent struct {
+	mu.Agent
+	Definition AgentDefinition
+	// Policy decides the tool calls (see ToolCallback)
+	Policy *approval.Policy
+	// Retriever is nil without retriever definition
+	Retriever *Retriever
+
+	mcpClients []*tools.MCPClient
+	toolClient map[string]*tools.MCPClient
+}
+
+// Fleet is the set of the agents of a document
+type Fleet struct {
+	agents []*Agent
+}
+
+// BuildOption is a functional option for configuring the builds of the agents
+type BuildOption func(*builder)
+
+// builder holds the build options
+type builder struct {
+	logger   logging.Logger
+	approver approval.Approver
+}
+
+// WithLogger sets the logger of the agents and of their MCP clients
+func WithLogger(logger logging.Logger) BuildOption {
+	return func(b *builder) {
+		b.logger = logger
+	}
+}
+
+// WithApprover sets the approver of the tool calls whose policy is ask (they are denied without approver)
+func WithApprover(approver approval.Approver) BuildOption {
+	return func(b *builder) {
+		b.approver = approver
+	}
+}
+
+// Build creates the agents of a document and connects them to their MCP servers
+//
+// Example usage:
+//
+//	document, err := config.Load("agents.yaml")
+//	fleet, err := config.Build(ctx, document, config.WithApprover(approval.NewTerminal()))
+//	defer fleet.Close()
+//	calculator, _ := fleet.Agent("calculator")
+//	messages, err := calculator.Messages("What is 40 + 2?")
+//	finishReason, results, answer, err := calculator.DetectToolCalls(messages, calculator.ToolCallback(ctx))
+func Build(ctx context.Context, document *Document, options ...BuildOption) (*Fleet, error) {
+	if err := document.Validate(); err != nil {
+		return nil, err
+	}
+	fleet := &Fleet{}
+	for _, definition := range document.Agents {
+		agent, err := BuildAgent(ctx, definition, options...)
+		if err != nil {
+			fleet.Close()
+			return nil, fmt.Errorf("agent %s: %w", definition.Name, err)
+		}
+		fleet.agents = append(fleet.agents, agent)
+	}
+	return fleet, nil
+}
+
+// BuildAgent creates an agent from its definition (the defaults of the document must be applied)
+func BuildAgent(ctx context.Context, definition AgentDefinition, options ...BuildOption) (*Agent, error) {
+	b := &builder{}
+	for _, option := range options {
+		option(b)
+	}
+
+	clientOptions := []option.RequestOption{option.WithAPIKey("")}
+	if definition.BaseURL != "" {
+		clientOptions = append(clientOptions, option.WithBaseURL(definition.BaseURL))
+	}
+	if definition.APIKeyEnv != "" {
+		apiKey := os.Getenv(definition.APIKeyEnv)
+		if apiKey == "" {
+			return nil, fmt.Errorf("the environment variable %s of the API key is empty", definition.APIKeyEnv)
+		}
+		clientOptions = append(clientOptions, option.WithAPIKey(apiKey))
+	}
+	client := openai.NewClient(clientOptions...)
+
+	agent := &Agent{Definition: definition, toolClient: map[string]*tools.MCPClient{}}
+	params := definition.Params.completionParams(definition.Model)
+	for _, server := range definition.MCPServers {
+		mcpClient, err := tools.NewStreamableHttpMCPClient(ctx, server.URL, tools.WithLogger(b.logger))
+		if err != nil {
+			agent.Close()
+			return nil, fmt.Errorf("unable to connect to the MCP server %s: %w", server.URL, err)
+		}
+		agent.mcpClients = append(agent.mcpClients, mcpClient)
+		serverTools := mcpClient.OpenAITools()
+		if len(server.Tools) > 0 {
+			serverTools = mcpClient.OpenAIToolsWithFilter(server.Tools)
+		}
+		for _, tool := range serverTools {
+			name := tool.GetFunction().Name
+			if _, exists := agent.toolClient[name]; exists {
+				agent.Close()
+				return nil, fmt.Errorf("the tool %s is given by two MCP servers", name)
+			}
+			agent.toolClient[name] = mcpClient
+		}
+		params.Tools = append(params.Tools, serverTools...)
+	}
+
+	agentOptions := []mu.AgentOption{mu.WithClient(client), mu.WithParams(params)}
+	if b.logger != nil {
+		agentOptions = append(agentOptions, mu.WithLogger(b.logger))
+	}
+	var err error
+	if agent.Agent, err = mu.NewAgentWithDescription(ctx, definition.Name, definition.Description, agentOptions...); err != nil {
+		agent.Close()
+		return nil, err
+	}
+
+	agent.Policy = &approval.Policy{Rules: map[string]approval.Action{}, Ask: b.approver}
+	if policies := definition.ToolPolicies; policies != nil {
+		agent.Policy.Default = approval.Action(policies.Default)
+		for pattern, action := range policies.Rules {
+			agent.Policy.Rules[pattern] = approval.Action(action)
+		}
+	}
+
+	if definition.Retriever != nil {
+		if agent.Retriever, err = newRetriever(ctx, client, *definition.Retriever); err != nil {
+			agent.Close()
+			return nil, err
+		}
+	}
+	return agent, nil
+}
+
+// completionParams returns the completion parameters of a model
+func (params Params) completionParams(model string) openai.ChatCompletionNewParams {
+	completion := openai.ChatCompletionNewParams{Model: model}
+	if params.Temperature != nil {
+		completion.Temperature = openai.Opt(*params.Temperature)
+	}
+	if params.TopP != nil {
+		completion.TopP = openai.Opt(*params.TopP)
+	}
+	if params.MaxTokens != nil {
+		completion.MaxTokens = openai.Opt(*params.MaxTokens)
+	}
+	if params.Seed != nil {
+		completion.Seed = openai.Opt(*params.Seed)
+	}
+	if params.PresencePenalty != nil {
+		completion.PresencePenalty = openai.Opt(*params.PresencePenalty)
+	}
+	if params.FrequencyPenalty != nil {
+		completion.FrequencyPenalty = openai.Opt(*params.FrequencyPenalty)
+	}
+	if len(params.Stop) > 0 {
+		completion.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: params.Stop}
+	}
+	if params.ParallelToolCalls != nil {
+		completion.ParallelToolCalls = openai.Opt(*params.ParallelToolCalls)
+	}
+	return completion
+}
+
+// Messages returns the messages of a prompt: the system message, the chunks of the retriever
+// similar to the prompt and the prompt
+func (agent *Agent) Messages(prompt string) ([]openai.ChatCompletionMessageParamUnion, error) {
+	messages := []openai.ChatCompletionMessageParamUnion{}
+	if agent.Definition.System != "" {
+		messages = append(messages, openai.SystemMessage(agent.Definition.System))
+	}
+	if agent.Retriever != nil {
+		records, err := agent.Retriever.Retrieve(prompt)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) > 0 {
+			var context strings.Builder
+			context.WriteString("Use the following documents to answer:\n")
+			for _, record := range records {
+				context.WriteString("\n---\n" + record.Prompt + "\n")
+			}
+			messages = append(messages, openai.SystemMessage(context.String()))
+		}
+	}
+	return append(messages, openai.UserMessage(prompt)), nil
+}
+
+// ToolCallback returns the tool callback of DetectToolCalls: the tool calls are decided by the policy
+// of the agent, then executed by their MCP server
+func (agent *Agent) ToolCallback(ctx context.Context) func(functionName string, arguments string) (string, error) {
+	return approval.Wrap(ctx, agent.Policy, func(functionName string, arguments string) (string, error) {
+		mcpClient, ok := agent.toolClient[functionName]
+		if !ok {
+			return "", fmt.Errorf("unknown tool %s", functionName)
+		}
+		result, err := mcpClient.CallTool(ctx, functionName, arguments)
+		if err != nil {
+			return "", err
+		}
+		texts := []string{}
+		for _, content := range result.Content {
+			if text, ok := content.(mcp.TextContent); ok {
+				texts = append(texts, text.Text)
+			}
+		}
+		return strings.Join(texts, "\n"), nil
+	})
+}
+
+// Close closes the connections to the MCP servers
+func (agent *Agent) Close() error {
+	errs := []error{}
+	for _, mcpClient := range agent.mcpClients {
+		errs = append(errs, mcpClient.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// Agent returns an agent by name
+func (fleet *Fleet) Agent(name string) (*Agent, bool) {
+	for _, agent := range fleet.agents {
+		if agent.GetName() == name {
+			return agent, true
+		}
+	}
+	return nil, false
+}
+
+// Agents returns the agents in the order of the document
+func (fleet *Fleet) Agents() []*Agent {
+	return fleet.agents
+}
+
+// Close closes the connections of all the agents
+func (fleet *Fleet) Close() error {
+	errs := []error{}
+	for _, agent := range fleet.agents {
+		errs = append(errs, agent.Close())
+	}
+	return errors.Join(errs...)
+}
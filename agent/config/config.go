@@ -0,0 +1,198 @@
+// Package config builds agents from declarative definitions: a YAML or JSON document describes each agent
+// (model, completion parameters, system prompt, MCP servers, tool policies, retriever), so a fleet of agents
+// is defined as data instead of Go code per agent.
+//
+// Example document:
+//
+//	defaults:
+//	  base_url: http://localhost:12434/engines/llama.cpp/v1
+//	  model: hf.co/menlo/jan-nano-gguf:q4_k_m
+//	agents:
+//	  - name: calculator
+//	    system: You are a calculator, use the tools.
+//	    params:
+//	      temperature: 0
+//	    mcp_servers:
+//	      - url: http://localhost:9011/mcp
+//	        tools: [add, multiply]
+//	    tool_policies:
+//	      default: deny
+//	      rules:
+//	        add: allow
+//	        multiply: allow
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a set of agent definitions
+type Document struct {
+	// Defaults are the settings of the agents which don't set them (base URL, model, parameters...)
+	Defaults AgentDefinition `yaml:"defaults,omitempty" json:"defaults,omitempty"`
+	// Agents are the definitions of the agents, their names are unique
+	Agents []AgentDefinition `yaml:"agents" json:"agents"`
+}
+
+// AgentDefinition is the definition of an agent
+type AgentDefinition struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// BaseURL is the URL of the OpenAI-compatible API
+	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+	// APIKeyEnv is the name of the environment variable of the API key (no key if empty)
+	APIKeyEnv string `yaml:"api_key_env,omitempty" json:"api_key_env,omitempty"`
+	Model     string `yaml:"model" json:"model"`
+	// System is the system message of the agent
+	System string `yaml:"system,omitempty" json:"system,omitempty"`
+	Params Params `yaml:"params,omitempty" json:"params,omitempty"`
+	// MCPServers give the tools of the agent
+	MCPServers []MCPServer `yaml:"mcp_servers,omitempty" json:"mcp_servers,omitempty"`
+	// ToolPolicies decide the tool calls of the agent
+	ToolPolicies *ToolPolicies `yaml:"tool_policies,omitempty" json:"tool_policies,omitempty"`
+	// Retriever adds the similar chunks of a vector store to the prompts
+	Retriever *RetrieverDefinition `yaml:"retriever,omitempty" json:"retriever,omitempty"`
+}
+
+// Params are the completion parameters of an agent, the parameters which are not set are not sent
+type Params struct {
+	Temperature       *float64 `yaml:"temperature,omitempty" json:"temperature,omitempty"`
+	TopP              *float64 `yaml:"top_p,omitempty" json:"top_p,omitempty"`
+	MaxTokens         *int64   `yaml:"max_tokens,omitempty" json:"max_tokens,omitempty"`
+	Seed              *int64   `yaml:"seed,omitempty" json:"seed,omitempty"`
+	PresencePenalty   *float64 `yaml:"presence_penalty,omitempty" json:"presence_penalty,omitempty"`
+	FrequencyPenalty  *float64 `yaml:"frequency_penalty,omitempty" json:"frequency_penalty,omitempty"`
+	Stop              []string `yaml:"stop,omitempty" json:"stop,omitempty"`
+	ParallelToolCalls *bool    `yaml:"parallel_tool_calls,omitempty" json:"parallel_tool_calls,omitempty"`
+}
+
+// MCPServer is a streamable HTTP MCP server giving tools to an agent
+type MCPServer struct {
+	URL string `yaml:"url" json:"url"`
+	// Tools are the names of the tools of the agent (all the tools of the server if empty)
+	Tools []string `yaml:"tools,omitempty" json:"tools,omitempty"`
+}
+
+// ToolPolicies are the approval policies of the tools of an agent (see approval.Policy)
+type ToolPolicies struct {
+	// Default is the action of the tools without rule: allow, deny or ask (ask if empty)
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
+	// Rules give the action of the tools by name, the names can be patterns ("github_*")
+	Rules map[string]string `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// RetrieverDefinition is the vector store of an agent: the chunks similar to a prompt are added to it
+type RetrieverDefinition struct {
+	// Store is the JSON file of a rag.MemoryVectorStore
+	Store string `yaml:"store" json:"store"`
+	// EmbeddingModel computes the embeddings of the prompts, it must be the model of the store
+	EmbeddingModel string `yaml:"embedding_model" json:"embedding_model"`
+	// Similarity is the minimum cosine similarity of the chunks (0.5 if zero)
+	Similarity float64 `yaml:"similarity,omitempty" json:"similarity,omitempty"`
+	// TopN is the maximum number of chunks (3 if zero)
+	TopN int `yaml:"top_n,omitempty" json:"top_n,omitempty"`
+}
+
+// Load reads and validates a document from a YAML or JSON file
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	document, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return document, nil
+}
+
+// Parse reads and validates a YAML or JSON document (JSON is valid YAML), the unknown fields are errors.
+// The defaults are applied to the agents.
+func Parse(data []byte) (*Document, error) {
+	document := &Document{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid agent definitions: %w", err)
+	}
+	document.applyDefaults()
+	if err := document.Validate(); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// applyDefaults sets the settings of the agents which don't set them
+func (document *Document) applyDefaults() {
+	defaults := document.Defaults
+	for i := range document.Agents {
+		agent := &document.Agents[i]
+		if agent.BaseURL == "" {
+			agent.BaseURL = defaults.BaseURL
+		}
+		if agent.APIKeyEnv == "" {
+			agent.APIKeyEnv = defaults.APIKeyEnv
+		}
+		if agent.Model == "" {
+			agent.Model = defaults.Model
+		}
+		if agent.System == "" {
+			agent.System = defaults.System
+		}
+		agent.Params = agent.Params.withDefaults(defaults.Params)
+		if agent.MCPServers == nil {
+			agent.MCPServers = defaults.MCPServers
+		}
+		if agent.ToolPolicies == nil {
+			agent.ToolPolicies = defaults.ToolPolicies
+		}
+		if agent.Retriever == nil {
+			agent.Retriever = defaults.Retriever
+		}
+	}
+}
+
+// withDefaults returns the parameters completed by the default parameters
+func (params Params) withDefaults(defaults Params) Params {
+	if params.Temperature == nil {
+		params.Temperature = defaults.Temperature
+	}
+	if params.TopP == nil {
+		params.TopP = defaults.TopP
+	}
+	if params.MaxTokens == nil {
+		params.MaxTokens = defaults.MaxTokens
+	}
+	if params.Seed == nil {
+		params.Seed = defaults.Seed
+	}
+	if params.PresencePenalty == nil {
+		params.PresencePenalty = defaults.PresencePenalty
+	}
+	if params.FrequencyPenalty == nil {
+		params.FrequencyPenalty = defaults.FrequencyPenalty
+	}
+	if params.Stop == nil {
+		params.Stop = defaults.Stop
+	}
+	if params.ParallelToolCalls == nil {
+		params.ParallelToolCalls = defaults.ParallelToolCalls
+	}
+	return params
+}
+
+// Agent returns the definition of an agent by name
+func (document *Document) Agent(name string) (AgentDefinition, bool) {
+	for _, agent := range document.Agents {
+		if agent.Name == name {
+			return agent, true
+		}
+	}
+	return AgentDefinition{}, false
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Retriever searches the chunks of a vector store similar to a question
+type Retriever struct {
+	embedder   mu.Agent
+	store      *rag.MemoryVectorStore
+	similarity float64
+	topN       int
+}
+
+// newRetriever loads the vector store of a retriever definition
+func newRetriever(ctx context.Context, client openai.Client, definition RetrieverDefinition) (*Retriever, error) {
+	store := &rag.MemoryVectorStore{Records: map[string]rag.VectorRecord{}}
+	if err := store.Load(definition.Store); err != nil {
+		return nil, fmt.Errorf("unable to load the vector store %s: %w", definition.Store, err)
+	}
+	embedder, err := mu.NewAgent(ctx, "retriever",
+		mu.WithClient(client),
+		mu.WithEmbeddingParams(openai.EmbeddingNewParams{Model: definition.EmbeddingModel}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	retriever := &Retriever{embedder: embedder, store: store, similarity: definition.Similarity, topN: definition.TopN}
+	if retriever.similarity == 0 {
+		retriever.similarity = 0.5
+	}
+	if retriever.topN == 0 {
+		retriever.topN = 3
+	}
+	return retriever, nil
+}
+
+// Retrieve returns the most similar chunks to a question, the most similar first
+func (retriever *Retriever) Retrieve(question string) ([]rag.VectorRecord, error) {
+	embedding, err := retriever.embedder.GenerateEmbeddingVector(question)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute the embedding of the question: %w", err)
+	}
+	return retriever.store.SearchTopNSimilarities(rag.VectorRecord{Embedding: embedding}, retriever.similarity, retriever.topN)
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+)
+
+// ValidationError lists the problems of a document
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	message := "invalid agent definitions:"
+	for _, problem := range e.Problems {
+		message += "\n  - " + problem
+	}
+	return message
+}
+
+// Validate checks the definitions of the agents (once the defaults are applied), it returns a *ValidationError
+// listing all the problems
+func (document *Document) Validate() error {
+	problems := []string{}
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if len(document.Agents) == 0 {
+		add("agents: no agent is defined")
+	}
+	names := map[string]bool{}
+	for i, agent := range document.Agents {
+		field := fmt.Sprintf("agents[%d]", i)
+		if agent.Name != "" {
+			field = fmt.Sprintf("agents[%d] (%s)", i, agent.Name)
+		}
+		switch {
+		case agent.Name == "":
+			add("%s.name: required", field)
+		case names[agent.Name]:
+			add("%s.name: duplicated", field)
+		}
+		names[agent.Name] = true
+
+		if agent.Model == "" {
+			add("%s.model: required (or defaults.model)", field)
+		}
+		if agent.BaseURL != "" {
+			if err := validateURL(agent.BaseURL); err != nil {
+				add("%s.base_url: %v", field, err)
+			}
+		}
+		params := agent.Params
+		if params.Temperature != nil && (*params.Temperature < 0 || *params.Temperature > 2) {
+			add("%s.params.temperature: must be between 0 and 2", field)
+		}
+		if params.TopP != nil && (*params.TopP < 0 || *params.TopP > 1) {
+			add("%s.params.top_p: must be between 0 and 1", field)
+		}
+		if params.MaxTokens != nil && *params.MaxTokens <= 0 {
+			add("%s.params.max_tokens: must be positive", field)
+		}
+
+		for j, server := range agent.MCPServers {
+			if err := validateURL(server.URL); err != nil {
+				add("%s.mcp_servers[%d].url: %v", field, j, err)
+			}
+		}
+		if policies := agent.ToolPolicies; policies != nil {
+			if policies.Default != "" && !validAction(policies.Default) {
+				add("%s.tool_policies.default: %q is not allow, deny or ask", field, policies.Default)
+			}
+			for pattern, action := range policies.Rules {
+				if _, err := path.Match(pattern, ""); err != nil {
+					add("%s.tool_policies.rules: invalid pattern %q", field, pattern)
+				}
+				if !validAction(action) {
+					add("%s.tool_policies.rules[%s]: %q is not allow, deny or ask", field, pattern, action)
+				}
+			}
+		}
+		if retriever := agent.Retriever; retriever != nil {
+			if retriever.Store == "" {
+				add("%s.retriever.store: required", field)
+			}
+			if retriever.EmbeddingModel == "" {
+				add("%s.retriever.embedding_model: required", field)
+			}
+			if retriever.Similarity < 0 || retriever.Similarity > 1 {
+				add("%s.retriever.similarity: must be between 0 and 1", field)
+			}
+			if retriever.TopN < 0 {
+				add("%s.retriever.top_n: must be positive", field)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateURL checks an http or https URL
+func validateURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.New("must be an http or https URL")
+	}
+	return nil
+}
+
+// validAction reports if an action is a valid policy action
+func validAction(action string) bool {
+	switch approval.Action(action) {
+	case approval.ActionAllow, approval.ActionDeny, approval.ActionAsk:
+		return true
+	}
+	return false
+}
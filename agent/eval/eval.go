@@ -0,0 +1,263 @@
+// Package eval regression-tests the prompts and the models: test cases (a prompt and the expected properties
+// of the answer) are run against an agent, the answers are scored by exact-match, regex, embedding-similarity
+// or LLM-as-judge scorers, and the results are gathered in a report which can be compared to a baseline.
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Expectations are the expected properties of an answer, declared in the cases (e.g. in a JSON file)
+type Expectations struct {
+	// Equals is the exact expected answer (spaces trimmed, case insensitive)
+	Equals string `json:"equals,omitempty"`
+	// Contains are the substrings the answer must contain (case insensitive)
+	Contains []string `json:"contains,omitempty"`
+	// NotContains are the substrings the answer must not contain (case insensitive)
+	NotContains []string `json:"not_contains,omitempty"`
+	// Matches is a regular expression the answer must match
+	Matches string `json:"matches,omitempty"`
+	// SimilarTo is a reference answer the answer must be semantically close to (needs WithEmbedder)
+	SimilarTo string `json:"similar_to,omitempty"`
+	// Criteria are evaluated by the judge agent (needs WithJudge)
+	Criteria string `json:"criteria,omitempty"`
+}
+
+// Case is a test case: a prompt and the expected properties of the answer
+type Case struct {
+	Name   string       `json:"name"`
+	System string       `json:"system,omitempty"` // system message of the case (the one of the suite if empty)
+	Prompt string       `json:"prompt"`
+	Expect Expectations `json:"expect"`
+	// Scorers are custom scorers, added to the scorers of the expectations
+	Scorers []Scorer `json:"-"`
+}
+
+// Score is the result of a scorer
+type Score struct {
+	Scorer string  `json:"scorer"`
+	Value  float64 `json:"value"` // between 0 and 1
+	Passed bool    `json:"passed"`
+	Reason string  `json:"reason,omitempty"`
+}
+
+// CaseResult is the evaluation of a case
+type CaseResult struct {
+	Name     string        `json:"name"`
+	Prompt   string        `json:"prompt"`
+	Answer   string        `json:"answer"`
+	Scores   []Score       `json:"scores"`
+	Passed   bool          `json:"passed"` // all the scores passed
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// SuiteOption is a functional option for configuring Suite instances
+type SuiteOption func(*Suite)
+
+// Suite is a list of cases evaluated against an agent
+type Suite struct {
+	name      string
+	cases     []Case
+	system    string
+	embedder  mu.Agent
+	judge     mu.Agent
+	threshold float64
+	onCase    func(result CaseResult)
+}
+
+// NewSuite creates an evaluation suite
+//
+// Example usage:
+//
+//	suite := eval.NewSuite("capitals",
+//	  eval.WithCases(
+//	    eval.Case{Name: "france", Prompt: "What is the capital of France?", Expect: eval.Expectations{Contains: []string{"Paris"}}},
+//	    eval.Case{Name: "tone", Prompt: "Say hello", Expect: eval.Expectations{Criteria: "The answer is polite"}},
+//	  ),
+//	  eval.WithJudge(judgeAgent),
+//	)
+//	report, err := suite.Run(chatAgent)
+//	fmt.Println(report.Markdown())
+func NewSuite(name string, options ...SuiteOption) *Suite {
+	suite := &Suite{name: name, threshold: 0.8}
+	for _, option := range options {
+		option(suite)
+	}
+	return suite
+}
+
+// WithCases is a functional option that adds cases to the suite
+func WithCases(cases ...Case) SuiteOption {
+	return func(suite *Suite) {
+		suite.cases = append(suite.cases, cases...)
+	}
+}
+
+// WithSystemMessage is a functional option that sets the system message of the cases without one
+func WithSystemMessage(system string) SuiteOption {
+	return func(suite *Suite) {
+		suite.system = system
+	}
+}
+
+// WithEmbedder is a functional option that sets the agent computing the embeddings of the SimilarTo expectations
+func WithEmbedder(embedder mu.Agent) SuiteOption {
+	return func(suite *Suite) {
+		suite.embedder = embedder
+	}
+}
+
+// WithJudge is a functional option that sets the agent evaluating the Criteria expectations
+func WithJudge(judge mu.Agent) SuiteOption {
+	return func(suite *Suite) {
+		suite.judge = judge
+	}
+}
+
+// WithThreshold is a functional option that sets the minimum value of the similarity and judge scores (0.8 by default)
+func WithThreshold(threshold float64) SuiteOption {
+	return func(suite *Suite) {
+		suite.threshold = threshold
+	}
+}
+
+// WithOnCase is a functional option that sets the callback called after each case
+func WithOnCase(callback func(result CaseResult)) SuiteOption {
+	return func(suite *Suite) {
+		suite.onCase = callback
+	}
+}
+
+// LoadCases reads the cases from a JSON file (an array of cases)
+func LoadCases(path string) ([]Case, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cases := []Case{}
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("invalid cases file %s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// Cases returns the cases of the suite
+func (suite *Suite) Cases() []Case {
+	return suite.cases
+}
+
+// Run evaluates the cases against the agent, the messages of the agent are restored after each case.
+// The errors of the cases are reported in their results, Run only fails if a case can't be scored
+// (an expectation without its embedder or judge).
+func (suite *Suite) Run(agent mu.Agent) (*Report, error) {
+	scorers := make([][]Scorer, len(suite.cases))
+	for idx, testCase := range suite.cases {
+		caseScorers, err := suite.scorers(testCase)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: %w", testCase.Name, err)
+		}
+		scorers[idx] = caseScorers
+	}
+
+	start := time.Now()
+	results := make([]CaseResult, 0, len(suite.cases))
+	for idx, testCase := range suite.cases {
+		result := suite.runCase(agent, testCase, scorers[idx])
+		results = append(results, result)
+		if suite.onCase != nil {
+			suite.onCase(result)
+		}
+	}
+	return newReport(suite.name, agent.GetModel(), results, time.Since(start)), nil
+}
+
+// runCase runs the agent on a case and scores its answer
+func (suite *Suite) runCase(agent mu.Agent, testCase Case, scorers []Scorer) CaseResult {
+	result := CaseResult{Name: testCase.Name, Prompt: testCase.Prompt, Scores: []Score{}}
+	start := time.Now()
+	answer, err := suite.answer(agent, testCase)
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Answer = answer
+
+	result.Passed = true
+	for _, scorer := range scorers {
+		score, err := scorer(testCase, answer)
+		if err != nil {
+			score.Passed = false
+			score.Reason = "scorer error: " + err.Error()
+		}
+		result.Scores = append(result.Scores, score)
+		result.Passed = result.Passed && score.Passed
+	}
+	return result
+}
+
+// answer runs the agent on the prompt of a case
+func (suite *Suite) answer(agent mu.Agent, testCase Case) (string, error) {
+	saved := append([]openai.ChatCompletionMessageParamUnion{}, agent.GetMessages()...)
+	defer agent.SetMessages(saved)
+	agent.SetMessages(nil)
+
+	messages := []openai.ChatCompletionMessageParamUnion{}
+	system := testCase.System
+	if system == "" {
+		system = suite.system
+	}
+	if system != "" {
+		messages = append(messages, openai.SystemMessage(system))
+	}
+	messages = append(messages, openai.UserMessage(testCase.Prompt))
+	return agent.Run(messages)
+}
+
+// scorers returns the scorers of the expectations and the custom scorers of a case
+func (suite *Suite) scorers(testCase Case) ([]Scorer, error) {
+	expect := testCase.Expect
+	scorers := []Scorer{}
+	if expect.Equals != "" {
+		scorers = append(scorers, ExactMatch(expect.Equals))
+	}
+	for _, substring := range expect.Contains {
+		scorers = append(scorers, Contains(substring))
+	}
+	for _, substring := range expect.NotContains {
+		scorers = append(scorers, NotContains(substring))
+	}
+	if expect.Matches != "" {
+		scorer, err := Regex(expect.Matches)
+		if err != nil {
+			return nil, err
+		}
+		scorers = append(scorers, scorer)
+	}
+	if expect.SimilarTo != "" {
+		if suite.embedder == nil {
+			return nil, errors.New("the similar_to expectation needs an embedder (WithEmbedder)")
+		}
+		scorers = append(scorers, Similarity(suite.embedder, expect.SimilarTo, suite.threshold))
+	}
+	if expect.Criteria != "" {
+		if suite.judge == nil {
+			return nil, errors.New("the criteria expectation needs a judge (WithJudge)")
+		}
+		scorers = append(scorers, Judge(suite.judge, expect.Criteria, suite.threshold))
+	}
+	scorers = append(scorers, testCase.Scorers...)
+	if len(scorers) == 0 {
+		return nil, errors.New("the case has no expectation")
+	}
+	return scorers, nil
+}
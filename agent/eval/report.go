@@ -0,0 +1,118 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Report gathers the results of a suite run
+type Report struct {
+	Suite    string        `json:"suite"`
+	Model    string        `json:"model"`
+	Date     time.Time     `json:"date"`
+	Duration time.Duration `json:"duration"`
+	Passed   int           `json:"passed"`
+	Failed   int           `json:"failed"`
+	// Score is the mean value of all the scores
+	Score   float64      `json:"score"`
+	Results []CaseResult `json:"results"`
+}
+
+// newReport computes the totals of the results
+func newReport(suite, model string, results []CaseResult, duration time.Duration) *Report {
+	report := &Report{Suite: suite, Model: model, Date: time.Now(), Duration: duration, Results: results}
+	total, count := 0.0, 0
+	for _, result := range results {
+		if result.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		for _, score := range result.Scores {
+			total += score.Value
+			count++
+		}
+	}
+	if count > 0 {
+		report.Score = total / float64(count)
+	}
+	return report
+}
+
+// PassRate returns the ratio of the passed cases
+func (report *Report) PassRate() float64 {
+	if len(report.Results) == 0 {
+		return 0
+	}
+	return float64(report.Passed) / float64(len(report.Results))
+}
+
+// Regressions returns the names of the cases passed in the baseline report and failed in this one
+func (report *Report) Regressions(baseline *Report) []string {
+	passedBefore := map[string]bool{}
+	for _, result := range baseline.Results {
+		passedBefore[result.Name] = result.Passed
+	}
+	regressions := []string{}
+	for _, result := range report.Results {
+		if !result.Passed && passedBefore[result.Name] {
+			regressions = append(regressions, result.Name)
+		}
+	}
+	return regressions
+}
+
+// Markdown returns the report as a markdown table
+func (report *Report) Markdown() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Evaluation %s\n\n", report.Suite)
+	fmt.Fprintf(&builder, "Model `%s`, %s: **%d/%d passed** (%.0f%%), mean score %.2f, in %s\n\n",
+		report.Model, report.Date.Format("2006-01-02 15:04"), report.Passed, len(report.Results),
+		100*report.PassRate(), report.Score, report.Duration.Round(time.Millisecond))
+	builder.WriteString("| Case | Result | Scores | Duration |\n|------|--------|--------|----------|\n")
+	for _, result := range report.Results {
+		status := "✅"
+		if !result.Passed {
+			status = "❌"
+		}
+		scores := []string{}
+		if result.Error != "" {
+			scores = append(scores, "error: "+result.Error)
+		}
+		for _, score := range result.Scores {
+			text := fmt.Sprintf("%s %.2f", score.Scorer, score.Value)
+			if score.Reason != "" {
+				text += " (" + score.Reason + ")"
+			}
+			scores = append(scores, text)
+		}
+		cell := strings.NewReplacer("|", "\\|", "\n", " ").Replace(strings.Join(scores, "; "))
+		fmt.Fprintf(&builder, "| %s | %s | %s | %s |\n", result.Name, status, cell, result.Duration.Round(time.Millisecond))
+	}
+	return builder.String()
+}
+
+// Save writes the report to a JSON file (e.g. the baseline of the next runs)
+func (report *Report) Save(path string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadReport reads a report saved as JSON
+func LoadReport(path string) (*Report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, fmt.Errorf("invalid report file %s: %w", path, err)
+	}
+	return report, nil
+}
@@ -0,0 +1,136 @@
+package eval
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Scorer scores the answer of a case
+type Scorer func(testCase Case, answer string) (Score, error)
+
+// passFail returns a binary score
+func passFail(scorer string, passed bool, reason string) Score {
+	score := Score{Scorer: scorer, Passed: passed}
+	if passed {
+		score.Value = 1
+	} else {
+		score.Reason = reason
+	}
+	return score
+}
+
+// ExactMatch passes if the answer is the expected one (spaces trimmed, case insensitive)
+func ExactMatch(expected string) Scorer {
+	return func(testCase Case, answer string) (Score, error) {
+		passed := strings.EqualFold(strings.TrimSpace(answer), strings.TrimSpace(expected))
+		return passFail("exact_match", passed, fmt.Sprintf("expected %q", expected)), nil
+	}
+}
+
+// Contains passes if the answer contains the substring (case insensitive)
+func Contains(substring string) Scorer {
+	return func(testCase Case, answer string) (Score, error) {
+		passed := strings.Contains(strings.ToLower(answer), strings.ToLower(substring))
+		return passFail("contains", passed, fmt.Sprintf("%q not found", substring)), nil
+	}
+}
+
+// NotContains passes if the answer doesn't contain the substring (case insensitive)
+func NotContains(substring string) Scorer {
+	return func(testCase Case, answer string) (Score, error) {
+		passed := !strings.Contains(strings.ToLower(answer), strings.ToLower(substring))
+		return passFail("not_contains", passed, fmt.Sprintf("%q found", substring)), nil
+	}
+}
+
+// Regex passes if the answer matches the regular expression
+func Regex(expression string) (Scorer, error) {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %q: %w", expression, err)
+	}
+	return func(testCase Case, answer string) (Score, error) {
+		return passFail("regex", re.MatchString(answer), fmt.Sprintf("doesn't match %q", expression)), nil
+	}, nil
+}
+
+// Similarity scores the cosine similarity between the embeddings of the answer and of a reference answer,
+// it passes above the threshold
+func Similarity(embedder mu.Agent, reference string, threshold float64) Scorer {
+	var referenceEmbedding []float64
+	return func(testCase Case, answer string) (Score, error) {
+		if referenceEmbedding == nil {
+			embedding, err := embedder.GenerateEmbeddingVector(reference)
+			if err != nil {
+				return Score{Scorer: "similarity"}, err
+			}
+			referenceEmbedding = embedding
+		}
+		embedding, err := embedder.GenerateEmbeddingVector(answer)
+		if err != nil {
+			return Score{Scorer: "similarity"}, err
+		}
+		similarity := rag.CosineSimilarity(referenceEmbedding, embedding)
+		score := Score{Scorer: "similarity", Value: similarity, Passed: similarity >= threshold}
+		if !score.Passed {
+			score.Reason = fmt.Sprintf("similarity %.2f below %.2f", similarity, threshold)
+		}
+		return score, nil
+	}
+}
+
+// judgePrompt asks the judge for a score and its reason
+const judgePrompt = `Evaluate the answer to the question according to the criteria.
+Reply with the score from 0 (the criteria are not met at all) to 10 (the criteria are fully met) on the first line,
+then the reason of the score in one sentence.
+
+<criteria>
+%s
+</criteria>
+<question>
+%s
+</question>
+<answer>
+%s
+</answer>`
+
+// scorePattern finds the score in the reply of the judge
+var scorePattern = regexp.MustCompile(`\d+(\.\d+)?`)
+
+// Judge asks a judge agent (LLM-as-judge) to score the answer from 0 to 10 according to criteria,
+// it passes when the score divided by 10 reaches the threshold. The messages of the judge are restored after each case.
+func Judge(judge mu.Agent, criteria string, threshold float64) Scorer {
+	return func(testCase Case, answer string) (Score, error) {
+		saved := append([]openai.ChatCompletionMessageParamUnion{}, judge.GetMessages()...)
+		defer judge.SetMessages(saved)
+		judge.SetMessages(nil)
+
+		reply, err := judge.Run([]openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(fmt.Sprintf(judgePrompt, criteria, testCase.Prompt, answer)),
+		})
+		if err != nil {
+			return Score{Scorer: "judge"}, err
+		}
+		reply = strings.TrimSpace(reply)
+		firstLine, reason, _ := strings.Cut(reply, "\n")
+		match := scorePattern.FindString(firstLine)
+		if match == "" {
+			return Score{Scorer: "judge"}, fmt.Errorf("the judge %s didn't reply with a score: %q", judge.GetName(), reply)
+		}
+		value, _ := strconv.ParseFloat(match, 64)
+		value = min(max(value/10, 0), 1)
+		return Score{
+			Scorer: "judge",
+			Value:  value,
+			Passed: value >= threshold,
+			Reason: strings.TrimSpace(reason),
+		}, nil
+	}
+}
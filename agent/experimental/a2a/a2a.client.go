@@ -9,24 +9,195 @@ package a2a
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
+	"time"
 )
 
+// ErrRequestTimeout is returned when a request to the agent exceeds the client timeout
+var ErrRequestTimeout = errors.New("a2a request timeout")
+
 type A2AClient struct {
-	agentBaseURL string
+	agentBaseURL   string
+	httpClient     *http.Client
+	transport      http.RoundTripper
+	timeout        time.Duration
+	maxRetries     int
+	retryBaseDelay time.Duration
+	retryMaxDelay  time.Duration
+	headers        map[string]string
+}
+
+// A2AClientOption is a functional option for configuring A2AClient instances
+type A2AClientOption func(*A2AClient)
+
+// WithHTTPClient sets the http.Client used by the A2A client (custom transport, proxy, TLS settings...),
+// the client isn't modified by the other options
+func WithHTTPClient(httpClient *http.Client) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		a2acli.httpClient = httpClient
+	}
+}
+
+// WithTransport sets the http.RoundTripper of the requests, on a copy of the http.Client of WithHTTPClient
+// (whatever the order of the options)
+func WithTransport(transport http.RoundTripper) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		a2acli.transport = transport
+	}
+}
+
+// WithTimeout sets the timeout of a request (no timeout by default).
+// For streaming requests, the timeout only applies until the response headers are received.
+func WithTimeout(timeout time.Duration) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		a2acli.timeout = timeout
+	}
+}
+
+// WithRetry enables retries with exponential backoff on network errors and 5xx responses.
+// The delay starts at baseDelay and is doubled after each attempt, up to maxDelay.
+func WithRetry(maxRetries int, baseDelay time.Duration, maxDelay time.Duration) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		a2acli.maxRetries = maxRetries
+		a2acli.retryBaseDelay = baseDelay
+		a2acli.retryMaxDelay = maxDelay
+	}
+}
+
+// WithHeader adds a default header sent with every request (e.g. Authorization)
+func WithHeader(key, value string) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		a2acli.headers[key] = value
+	}
+}
+
+// WithHeaders adds default headers sent with every request
+func WithHeaders(headers map[string]string) A2AClientOption {
+	return func(a2acli *A2AClient) {
+		for key, value := range headers {
+			a2acli.headers[key] = value
+		}
+	}
+}
+
+// NewA2AClient creates a new A2A client for the agent at the given base URL.
+// By default, requests don't time out (see WithTimeout) and are not retried.
+func NewA2AClient(agentBaseURL string, options ...A2AClientOption) *A2AClient {
+	a2acli := &A2AClient{
+		agentBaseURL:   strings.TrimRight(agentBaseURL, "/"),
+		httpClient:     &http.Client{},
+		retryBaseDelay: 500 * time.Millisecond,
+		retryMaxDelay:  10 * time.Second,
+		headers:        map[string]string{},
+	}
+	// Apply all options
+	for _, option := range options {
+		option(a2acli)
+	}
+	if a2acli.transport != nil {
+		httpClient := *a2acli.httpClient
+		httpClient.Transport = a2acli.transport
+		a2acli.httpClient = &httpClient
+	}
+	return a2acli
+}
+
+// do sends the request built by newRequest, retrying on network errors and 5xx responses until ctx is done.
+// newRequest is called for every attempt so the request body can be read again.
+// When stream is true, the timeout only covers the wait for the response headers.
+func (a2acli *A2AClient) do(ctx context.Context, newRequest func() (*http.Request, error), stream bool) (*http.Response, error) {
+	delay := a2acli.retryBaseDelay
+	var lastErr error
+
+	for attempt := 0; attempt <= a2acli.maxRetries; attempt++ {
+		if attempt > 0 {
+			wait := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				wait.Stop()
+				return nil, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+			case <-wait.C:
+			}
+			delay *= 2
+			if delay > a2acli.retryMaxDelay {
+				delay = a2acli.retryMaxDelay
+			}
+		}
+
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range a2acli.headers {
+			req.Header.Set(key, value)
+		}
+
+		requestCtx, cancelCause := context.WithCancelCause(ctx)
+		cancel := func() { cancelCause(nil) }
+		var timer *time.Timer
+		if a2acli.timeout > 0 {
+			timer = time.AfterFunc(a2acli.timeout, func() { cancelCause(ErrRequestTimeout) })
+		}
+		resp, err := a2acli.httpClient.Do(req.WithContext(requestCtx))
+		if stream && timer != nil {
+			// the headers are received, do not interrupt the stream
+			timer.Stop()
+		}
+		if err != nil {
+			if errors.Is(context.Cause(requestCtx), ErrRequestTimeout) {
+				err = fmt.Errorf("%w after %s: %s %s", ErrRequestTimeout, a2acli.timeout, req.Method, req.URL)
+			}
+			cancel()
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			lastErr = err
+			continue
+		}
+		if resp.StatusCode >= http.StatusInternalServerError && attempt < a2acli.maxRetries {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			cancel()
+			lastErr = errors.New("server error: " + resp.Status)
+			continue
+		}
+		resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel, timer: timer}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// cancelOnCloseBody releases the request context when the response body is closed
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+	timer  *time.Timer
 }
 
-func NewA2AClient(agentBaseURL string) *A2AClient {
-	return &A2AClient{
-		agentBaseURL: strings.TrimRight(agentBaseURL, "/"),
+func (b *cancelOnCloseBody) Close() error {
+	if b.timer != nil {
+		b.timer.Stop()
 	}
+	b.cancel()
+	return b.ReadCloser.Close()
 }
 
+// PingAgent returns the agent card of the agent
 func (a2acli *A2AClient) PingAgent() (AgentCard, error) {
-	resp, err := http.Get(a2acli.agentBaseURL + "/.well-known/agent.json")
+	return a2acli.PingAgentContext(context.Background())
+}
+
+// PingAgentContext is PingAgent with a context, which also stops the retries
+func (a2acli *A2AClient) PingAgentContext(ctx context.Context) (AgentCard, error) {
+	resp, err := a2acli.do(ctx, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, a2acli.agentBaseURL+"/.well-known/agent.json", nil)
+	}, false)
 	if err != nil {
 		return AgentCard{}, err
 	}
@@ -43,13 +214,26 @@ func (a2acli *A2AClient) PingAgent() (AgentCard, error) {
 	}
 }
 
+// SendToAgent sends a task request to the agent and returns its response
 func (a2acli *A2AClient) SendToAgent(taskRequest TaskRequest) (TaskResponse, error) {
+	return a2acli.SendToAgentContext(context.Background(), taskRequest)
+}
+
+// SendToAgentContext is SendToAgent with a context, which also stops the retries
+func (a2acli *A2AClient) SendToAgentContext(ctx context.Context, taskRequest TaskRequest) (TaskResponse, error) {
 	jsonTaskRequest, err := TaskRequestToJSONString(taskRequest)
 	if err != nil {
 		return TaskResponse{}, err
 	}
 
-	resp, err := http.Post(a2acli.agentBaseURL+"/", "application/json", strings.NewReader(jsonTaskRequest))
+	resp, err := a2acli.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, a2acli.agentBaseURL+"/", strings.NewReader(jsonTaskRequest))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}, false)
 	if err != nil {
 		return TaskResponse{}, err
 	}
@@ -71,21 +255,25 @@ func (a2acli *A2AClient) SendToAgent(taskRequest TaskRequest) (TaskResponse, err
 // streamCallback is called for each chunk of content received
 // Returns the complete response and any error
 func (a2acli *A2AClient) SendToAgentStream(taskRequest TaskRequest, streamCallback func(content string) error) (TaskResponse, error) {
-	jsonTaskRequest, err := TaskRequestToJSONString(taskRequest)
-	if err != nil {
-		return TaskResponse{}, err
-	}
+	return a2acli.SendToAgentStreamContext(context.Background(), taskRequest, streamCallback)
+}
 
-	req, err := http.NewRequest("POST", a2acli.agentBaseURL+"/stream", strings.NewReader(jsonTaskRequest))
+// SendToAgentStreamContext is SendToAgentStream with a context, which also stops the retries and the stream
+func (a2acli *A2AClient) SendToAgentStreamContext(ctx context.Context, taskRequest TaskRequest, streamCallback func(content string) error) (TaskResponse, error) {
+	jsonTaskRequest, err := TaskRequestToJSONString(taskRequest)
 	if err != nil {
 		return TaskResponse{}, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "text/event-stream")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := a2acli.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequest("POST", a2acli.agentBaseURL+"/stream", strings.NewReader(jsonTaskRequest))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		return req, nil
+	}, true)
 	if err != nil {
 		return TaskResponse{}, err
 	}
@@ -117,6 +305,11 @@ func (a2acli *A2AClient) SendToAgentStream(taskRequest TaskRequest, streamCallba
 				continue // Skip malformed JSON
 			}
 
+			// The remote agent failed during the stream
+			if chunkType, exists := chunkResponse["type"]; exists && chunkType == "error" {
+				return TaskResponse{}, fmt.Errorf("the agent failed: %v", chunkResponse["error"])
+			}
+
 			// Handle streaming chunks
 			if chunkType, exists := chunkResponse["type"]; exists && chunkType == "chunk" {
 				if content, exists := chunkResponse["content"]; exists {
@@ -155,10 +348,10 @@ func (a2acli *A2AClient) SendToAgentStream(taskRequest TaskRequest, streamCallba
 				History: []AgentMessage{
 					{
 						Role: "assistant",
-						Parts: []TextPart{
+						Parts: []Part{
 							{
 								Text: fullContent.String(),
-								Type: "text",
+								Type: TextPartType,
 							},
 						},
 					},
@@ -0,0 +1,72 @@
+package a2a
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingTransport counts the requests sent through it
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestA2AClientWithTransport(t *testing.T) {
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "bob"}`))
+	}))
+	defer agent.Close()
+
+	for _, order := range []string{"transport first", "client first"} {
+		httpClient := &http.Client{}
+		transport := &countingTransport{}
+		options := []A2AClientOption{WithTransport(transport), WithHTTPClient(httpClient)}
+		if order == "client first" {
+			options = []A2AClientOption{WithHTTPClient(httpClient), WithTransport(transport)}
+		}
+		client := NewA2AClient(agent.URL, options...)
+		if _, err := client.PingAgent(); err != nil {
+			t.Fatal(err)
+		}
+		if transport.requests.Load() != 1 {
+			t.Errorf("%s: the request should use the transport", order)
+		}
+		if httpClient.Transport != nil {
+			t.Errorf("%s: the http.Client of WithHTTPClient shouldn't be modified", order)
+		}
+	}
+}
+
+func TestA2AClientHasNoDefaultTimeout(t *testing.T) {
+	if client := NewA2AClient("http://localhost:7777"); client.timeout != 0 {
+		t.Fatalf("no default timeout expected, got %s", client.timeout)
+	}
+}
+
+func TestA2AClientRetryStopsWithContext(t *testing.T) {
+	agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer agent.Close()
+
+	client := NewA2AClient(agent.URL, WithRetry(5, time.Minute, time.Minute))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.PingAgentContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("the deadline of the context expected, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("the wait between the retries should stop with the context, it took %s", elapsed)
+	}
+}
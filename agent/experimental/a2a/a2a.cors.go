@@ -0,0 +1,133 @@
+// Package a2a provides experimental functionality for µ-agent.
+//
+// WARNING: This package is experimental and subject to change.
+// The API may change or be removed in future versions without notice.
+// Use at your own risk in production environments.
+// NOTE: This is a partial implementation of the A2A protocol.
+// IMPORTANT: This is a work in progress and may not cover all aspects of the A2A protocol.
+package a2a
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CORSConfig holds the Cross-Origin Resource Sharing settings of an A2A server
+type CORSConfig struct {
+	AllowedOrigins   []string // "*" allows every origin, except with AllowCredentials
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           int // in seconds, 0 means the header is not sent
+}
+
+// DefaultCORSConfig returns a permissive CORS configuration suitable for browser-based A2A clients,
+// to give to WithCORS (every origin is allowed, without credentials)
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodOptions},
+		AllowedHeaders: []string{"Content-Type", "Accept", "Authorization", "Cache-Control"},
+		MaxAge:         600,
+	}
+}
+
+// ParseCORSOrigins returns the origins of a comma-separated list, e.g. the value of an environment variable
+// ("http://localhost:5173,https://app.example.com", "*" for every origin), nil if the list is empty
+//
+// Example usage:
+//
+//	handler := http.Handler(mux)
+//	if origins := a2a.ParseCORSOrigins(os.Getenv("CORS_ORIGINS")); origins != nil {
+//	  cfg := a2a.DefaultCORSConfig()
+//	  cfg.AllowedOrigins = origins
+//	  handler = a2a.CORSHandler(cfg, mux)
+//	}
+func ParseCORSOrigins(origins string) []string {
+	var parsed []string
+	for _, origin := range strings.Split(origins, ",") {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			parsed = append(parsed, origin)
+		}
+	}
+	return parsed
+}
+
+// Validate reports an error if the configuration allows every origin with credentials: any website could
+// then call the server with the cookies or the credentials of its visitors
+func (cfg CORSConfig) Validate() error {
+	if cfg.AllowCredentials && cfg.allowsEveryOrigin() {
+		return errors.New("cors: the origin \"*\" can't be allowed with credentials, list the allowed origins")
+	}
+	return nil
+}
+
+// allowsEveryOrigin reports whether the wildcard origin is allowed
+func (cfg CORSConfig) allowsEveryOrigin() bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// isOriginAllowed checks if the origin matches one of the allowed origins.
+// The wildcard origin is ignored with credentials (see Validate).
+func (cfg CORSConfig) isOriginAllowed(origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" && !cfg.AllowCredentials {
+			return true
+		}
+		if allowed != "*" && strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// allowOriginValue returns the value of the Access-Control-Allow-Origin header for an allowed origin
+func (cfg CORSConfig) allowOriginValue(origin string) string {
+	if cfg.allowsEveryOrigin() && !cfg.AllowCredentials {
+		return "*"
+	}
+	return origin
+}
+
+// CORSHandler wraps an http.Handler with CORS headers and answers preflight (OPTIONS) requests.
+// Requests without an Origin header, or with an origin that is not allowed, are passed through untouched.
+// The wildcard origin is ignored with credentials, check the configuration with Validate.
+func CORSHandler(cfg CORSConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !cfg.isOriginAllowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", cfg.allowOriginValue(origin))
+		w.Header().Add("Vary", "Origin")
+		if cfg.AllowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		if len(cfg.ExposedHeaders) > 0 {
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+		}
+
+		// Preflight request
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ", "))
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+			if cfg.MaxAge > 0 {
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(cfg.MaxAge))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
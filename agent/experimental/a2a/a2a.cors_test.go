@@ -0,0 +1,106 @@
+package a2a
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSConfigValidate(t *testing.T) {
+	cfg := DefaultCORSConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("the default configuration should be valid: %v", err)
+	}
+	cfg.AllowCredentials = true
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("every origin with credentials should be rejected")
+	}
+	cfg.AllowedOrigins = []string{"https://app.example.com"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("listed origins with credentials should be valid: %v", err)
+	}
+}
+
+func TestCORSHandler(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func(cfg CORSConfig, method string, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		w := httptest.NewRecorder()
+		CORSHandler(cfg, next).ServeHTTP(w, r)
+		return w
+	}
+
+	// Every origin, without credentials
+	w := request(DefaultCORSConfig(), http.MethodPost, "https://any.example.com")
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("the wildcard origin expected, got %q", got)
+	}
+
+	// Preflight request
+	w = request(DefaultCORSConfig(), http.MethodOptions, "https://any.example.com")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Methods") == "" {
+		t.Fatalf("the preflight request should be answered, got %d %v", w.Code, w.Header())
+	}
+
+	// Listed origins with credentials
+	cfg := DefaultCORSConfig()
+	cfg.AllowedOrigins = []string{"https://app.example.com"}
+	cfg.AllowCredentials = true
+	w = request(cfg, http.MethodPost, "https://app.example.com")
+	if w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || w.Header().Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("the origin should be allowed with credentials, got %v", w.Header())
+	}
+	w = request(cfg, http.MethodPost, "https://evil.example.com")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("an unlisted origin shouldn't be allowed, got %v", w.Header())
+	}
+
+	// The wildcard origin is ignored with credentials
+	cfg.AllowedOrigins = []string{"*"}
+	w = request(cfg, http.MethodPost, "https://evil.example.com")
+	if w.Header().Get("Access-Control-Allow-Origin") != "" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Fatalf("the wildcard origin shouldn't be allowed with credentials, got %v", w.Header())
+	}
+}
+
+func TestA2AServerCORSIsOptIn(t *testing.T) {
+	callback := func(taskRequest TaskRequest) (TaskResponse, error) { return TaskResponse{}, nil }
+	r := httptest.NewRequest(http.MethodGet, "/.well-known/agent.json", nil)
+	r.Header.Set("Origin", "https://any.example.com")
+
+	w := httptest.NewRecorder()
+	NewA2AServer(0, AgentCard{}, callback).Handler().ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Fatalf("no CORS header expected without WithCORS, got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	NewA2AServer(0, AgentCard{}, callback, WithCORS(DefaultCORSConfig())).Handler().ServeHTTP(w, r)
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+		t.Fatalf("the CORS headers expected with WithCORS, got %q", got)
+	}
+
+	cfg := DefaultCORSConfig()
+	cfg.AllowCredentials = true
+	if err := NewA2AServer(0, AgentCard{}, callback, WithCORS(cfg)).Start(); err == nil {
+		t.Fatal("Start should reject every origin with credentials")
+	}
+}
+
+func TestParseCORSOrigins(t *testing.T) {
+	if origins := ParseCORSOrigins(" , "); origins != nil {
+		t.Fatalf("no origin expected, got %v", origins)
+	}
+	origins := ParseCORSOrigins("http://localhost:5173, https://app.example.com,")
+	if len(origins) != 2 || origins[0] != "http://localhost:5173" || origins[1] != "https://app.example.com" {
+		t.Fatalf("unexpected origins %v", origins)
+	}
+}
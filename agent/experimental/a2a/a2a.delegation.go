@@ -0,0 +1,233 @@
+// Package a2a provides experimental functionality for µ-agent.
+//
+// WARNING: This package is experimental and subject to change.
+// The API may change or be removed in future versions without notice.
+// Use at your own risk in production environments.
+// NOTE: This is a partial implementation of the A2A protocol.
+// IMPORTANT: This is a work in progress and may not cover all aspects of the A2A protocol.
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// DelegateTool is a tool delegating the tasks to a skill of a remote agent
+type DelegateTool struct {
+	// Name is the name of the tool: delegate_<agent>_<skill>
+	Name        string
+	Description string
+	AgentName   string
+	AgentURL    string
+	// SkillID is empty when the remote agent declares no skill (one tool for the whole agent)
+	SkillID string
+	client  *A2AClient
+}
+
+// Delegation routes the calls of the delegate tools to the remote agents
+type Delegation struct {
+	tools   []DelegateTool
+	byName  map[string]DelegateTool
+	options []A2AClientOption
+}
+
+// DelegationOption is a functional option for configuring Delegation instances
+type DelegationOption func(*Delegation)
+
+// WithDelegationClientOptions sets the options of the A2A clients of the remote agents (timeout, retries, headers...)
+func WithDelegationClientOptions(options ...A2AClientOption) DelegationOption {
+	return func(delegation *Delegation) {
+		delegation.options = append(delegation.options, options...)
+	}
+}
+
+// invalidToolNameChars are the characters not allowed in the names of the tools
+var invalidToolNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// NewDelegation pings the agent card of each remote agent and creates one delegate tool per skill
+// (one tool for an agent without skills). It returns an error if an agent can't be reached.
+func NewDelegation(agentBaseURLs []string, options ...DelegationOption) (*Delegation, error) {
+	delegation := &Delegation{byName: map[string]DelegateTool{}}
+	for _, option := range options {
+		option(delegation)
+	}
+	for _, agentBaseURL := range agentBaseURLs {
+		client := NewA2AClient(agentBaseURL, delegation.options...)
+		agentCard, err := client.PingAgent()
+		if err != nil {
+			return nil, fmt.Errorf("unable to ping the agent %s: %w", agentBaseURL, err)
+		}
+		skills := agentCard.Skills
+		if len(skills) == 0 {
+			skills = []map[string]any{{}}
+		}
+		for _, skill := range skills {
+			tool := newDelegateTool(client, agentBaseURL, agentCard, skill)
+			if _, exists := delegation.byName[tool.Name]; exists {
+				return nil, fmt.Errorf("two remote skills have the same tool name %s", tool.Name)
+			}
+			delegation.tools = append(delegation.tools, tool)
+			delegation.byName[tool.Name] = tool
+		}
+	}
+	return delegation, nil
+}
+
+// RegisterDelegateTools creates the delegate tools of the remote agents (see NewDelegation)
+// and adds them to the tools of the local agent
+//
+// Example usage:
+//
+//	delegation, err := a2a.RegisterDelegateTools(agent, []string{"http://localhost:7777", "http://localhost:8888"})
+//	display := func(content string) error { fmt.Print(content); return nil }
+//	finishReason, results, answer, err := agent.DetectToolCallsStream(messages, delegation.ToolCallback(display, mcpCallback), display)
+func RegisterDelegateTools(agent mu.Agent, agentBaseURLs []string, options ...DelegationOption) (*Delegation, error) {
+	delegation, err := NewDelegation(agentBaseURLs, options...)
+	if err != nil {
+		return nil, err
+	}
+	toolsAgent, ok := agent.(interface {
+		GetTools() []openai.ChatCompletionToolUnionParam
+		SetTools(tools []openai.ChatCompletionToolUnionParam)
+	})
+	if !ok {
+		return nil, fmt.Errorf("the agent %s doesn't support the registration of tools", agent.GetName())
+	}
+	toolsAgent.SetTools(append(toolsAgent.GetTools(), delegation.OpenAITools()...))
+	return delegation, nil
+}
+
+// newDelegateTool creates the delegate tool of a skill of a remote agent
+func newDelegateTool(client *A2AClient, agentBaseURL string, agentCard AgentCard, skill map[string]any) DelegateTool {
+	skillID, _ := skill["id"].(string)
+	skillName, _ := skill["name"].(string)
+	skillDescription, _ := skill["description"].(string)
+
+	name := "delegate_" + agentCard.Name
+	if skillID != "" {
+		name += "_" + skillID
+	}
+	name = strings.Trim(invalidToolNameChars.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	description := fmt.Sprintf("Delegate a task to the remote agent %s", agentCard.Name)
+	if agentCard.Description != "" {
+		description += " (" + agentCard.Description + ")"
+	}
+	switch {
+	case skillName != "" && skillDescription != "":
+		description += ", skill " + skillName + ": " + skillDescription
+	case skillName != "" || skillDescription != "":
+		description += ", skill " + skillName + skillDescription
+	}
+	description += ". Give it a complete task, it doesn't know the conversation."
+
+	return DelegateTool{
+		Name:        name,
+		Description: description,
+		AgentName:   agentCard.Name,
+		AgentURL:    agentBaseURL,
+		SkillID:     skillID,
+		client:      client,
+	}
+}
+
+// Tools returns the delegate tools
+func (delegation *Delegation) Tools() []DelegateTool {
+	return delegation.tools
+}
+
+// OpenAITools returns the definitions of the delegate tools
+func (delegation *Delegation) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	tools := make([]openai.ChatCompletionToolUnionParam, 0, len(delegation.tools))
+	for _, tool := range delegation.tools {
+		tools = append(tools, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        tool.Name,
+			Description: openai.String(tool.Description),
+			Parameters: shared.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"task": map[string]any{"type": "string", "description": "the task for the remote agent"},
+				},
+				"required": []string{"task"},
+			},
+		}))
+	}
+	return tools
+}
+
+// IsTool returns true if the function is a delegate tool
+func (delegation *Delegation) IsTool(functionName string) bool {
+	_, ok := delegation.byName[functionName]
+	return ok
+}
+
+// CallTool sends the task of the arguments to the remote agent with SendToAgentStream: the chunks of the
+// answer are given to streamCallback (if not nil) as they arrive, and the whole answer is returned
+func (delegation *Delegation) CallTool(functionName string, arguments string, streamCallback func(content string) error) (string, error) {
+	tool, ok := delegation.byName[functionName]
+	if !ok {
+		return "", fmt.Errorf("unknown delegate tool %s", functionName)
+	}
+	var args struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Task) == "" {
+		return "", errors.New("the task is empty")
+	}
+
+	metadata := map[string]any{}
+	if tool.SkillID != "" {
+		metadata["skill"] = tool.SkillID
+	}
+	taskRequest := TaskRequest{
+		JSONRpcVersion: "2.0",
+		ID:             "task-" + uuid.New().String(),
+		Method:         "message/send",
+		Params: AgentMessageParams{
+			Message: AgentMessage{
+				Role:      "user",
+				Parts:     []Part{NewTextPart(args.Task)},
+				MessageID: uuid.New().String(),
+			},
+			MetaData: metadata,
+		},
+	}
+	taskResponse, err := tool.client.SendToAgentStream(taskRequest, streamCallback)
+	if err != nil {
+		return "", fmt.Errorf("remote agent %s: %w", tool.AgentName, err)
+	}
+	if taskResponse.Result.Status.State == "failed" {
+		return "", fmt.Errorf("remote agent %s: the task failed: %s", tool.AgentName, responseAnswer(taskResponse))
+	}
+	return responseAnswer(taskResponse), nil
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls or DetectToolCallsStream executing the delegate tools,
+// the remote chunks are streamed through streamCallback (usually the stream callback of the local agent).
+// The other tools are executed by next (an error if nil).
+func (delegation *Delegation) ToolCallback(streamCallback func(content string) error, next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if delegation.IsTool(functionName) {
+			return delegation.CallTool(functionName, arguments, streamCallback)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
@@ -7,7 +7,10 @@
 // IMPORTANT: This is a work in progress and may not cover all aspects of the A2A protocol.
 package a2a
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"errors"
+)
 
 func TaskRequestToJSONString(taskRequest TaskRequest) (string, error) {
 	jsonData, err := json.MarshalIndent(taskRequest, "", "    ")
@@ -32,3 +35,58 @@ func TaskResponseToJSONString(taskResponse TaskResponse) (string, error) {
 	}
 	return string(jsonData), nil
 }
+
+// NewTextPart creates a text part with the given text
+func NewTextPart(text string) Part {
+	return Part{
+		Text: text,
+		Type: TextPartType,
+	}
+}
+
+// NewDataPart creates a structured data part by marshaling the given value (struct, map, slice...) to JSON
+func NewDataPart(data any) (Part, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return Part{}, err
+	}
+	return Part{
+		Data: jsonData,
+		Type: DataPartType,
+	}, nil
+}
+
+// IsData returns true if the part carries structured (JSON) data
+func (p Part) IsData() bool {
+	return p.Type == DataPartType
+}
+
+// UnmarshalData decodes the JSON payload of a data part into the value pointed to by target
+func (p Part) UnmarshalData(target any) error {
+	if !p.IsData() {
+		return errors.New("part is not a data part: " + p.Type)
+	}
+	return json.Unmarshal(p.Data, target)
+}
+
+// GetTextParts returns the text parts of the message
+func (m AgentMessage) GetTextParts() []Part {
+	var parts []Part
+	for _, part := range m.Parts {
+		if part.Type == TextPartType {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// GetDataParts returns the structured data parts of the message
+func (m AgentMessage) GetDataParts() []Part {
+	var parts []Part
+	for _, part := range m.Parts {
+		if part.IsData() {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
@@ -10,9 +10,11 @@ package a2a
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"strconv"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
 )
 
 type A2AServer struct {
@@ -22,10 +24,37 @@ type A2AServer struct {
 	agentCard           AgentCard
 	agentCallback       func(taskRequest TaskRequest) (TaskResponse, error)
 	agentStreamCallback func(taskRequest TaskRequest, streamFunc func(content string) error) error
+	corsConfig          *CORSConfig
+	sessions            *sessions.Manager
+	logger              logging.Logger
+}
+
+// A2AServerOption is a functional option for configuring A2AServer instances
+type A2AServerOption func(*A2AServer)
+
+// WithCORS enables CORS on all the endpoints of the server, with the preflight (OPTIONS) requests answered.
+// Without it, only the streaming endpoint allows every origin, as before the CORS support.
+//
+// Example usage:
+//
+//	corsConfig := a2a.DefaultCORSConfig()
+//	corsConfig.AllowedOrigins = []string{"https://app.example.com"}
+//	server := a2a.NewA2AServerWithStreaming(8080, agentCard, callback, a2a.WithCORS(corsConfig))
+func WithCORS(corsConfig CORSConfig) A2AServerOption {
+	return func(a2asvr *A2AServer) {
+		a2asvr.corsConfig = &corsConfig
+	}
+}
+
+// WithLogger sets the logger of the server (logging.Default() otherwise)
+func WithLogger(logger logging.Logger) A2AServerOption {
+	return func(a2asvr *A2AServer) {
+		a2asvr.logger = logger
+	}
 }
 
 // NewA2AServer creates a new A2A server with the given parameters
-func NewA2AServer(port int, agentCard AgentCard, agentCallback func(taskRequest TaskRequest) (TaskResponse, error)) *A2AServer {
+func NewA2AServer(port int, agentCard AgentCard, agentCallback func(taskRequest TaskRequest) (TaskResponse, error), options ...A2AServerOption) *A2AServer {
 	mux := http.NewServeMux()
 	server := &A2AServer{
 		httpPort: port,
@@ -34,6 +63,10 @@ func NewA2AServer(port int, agentCard AgentCard, agentCallback func(taskRequest
 		agentCard:     agentCard,
 		agentCallback: agentCallback,
 	}
+	// Apply all options
+	for _, option := range options {
+		option(server)
+	}
 	// Register handlers
 	mux.HandleFunc("/.well-known/agentcard", server.getAgentCard)
 	mux.HandleFunc("/.well-known/agent.json", server.getAgentCard)
@@ -44,7 +77,7 @@ func NewA2AServer(port int, agentCard AgentCard, agentCallback func(taskRequest
 }
 
 // NewA2AServerWithStreaming creates a new A2A server with streaming support
-func NewA2AServerWithStreaming(port int, agentCard AgentCard, agentStreamCallback func(taskRequest TaskRequest, streamFunc func(content string) error) error) *A2AServer {
+func NewA2AServerWithStreaming(port int, agentCard AgentCard, agentStreamCallback func(taskRequest TaskRequest, streamFunc func(content string) error) error, options ...A2AServerOption) *A2AServer {
 	mux := http.NewServeMux()
 	server := &A2AServer{
 		httpPort: port,
@@ -53,6 +86,10 @@ func NewA2AServerWithStreaming(port int, agentCard AgentCard, agentStreamCallbac
 		agentCard:           agentCard,
 		agentStreamCallback: agentStreamCallback,
 	}
+	// Apply all options
+	for _, option := range options {
+		option(server)
+	}
 	// Register handlers
 	mux.HandleFunc("/.well-known/agentcard", server.getAgentCard)
 	mux.HandleFunc("/.well-known/agent.json", server.getAgentCard)
@@ -65,14 +102,33 @@ func NewA2AServerWithStreaming(port int, agentCard AgentCard, agentStreamCallbac
 	return server
 }
 
+// Handler returns the HTTP handler of the server, wrapped with CORS support when enabled (see WithCORS)
+func (a2asvr *A2AServer) Handler() http.Handler {
+	if a2asvr.corsConfig == nil {
+		return a2asvr.httpServer
+	}
+	return CORSHandler(*a2asvr.corsConfig, a2asvr.httpServer)
+}
+
+// Start listens on the port of the server, an invalid CORS configuration is an error
 func (a2asvr *A2AServer) Start() error {
-	errListening := http.ListenAndServe(":"+strconv.Itoa(a2asvr.httpPort), a2asvr.httpServer)
+	if a2asvr.corsConfig != nil {
+		if err := a2asvr.corsConfig.Validate(); err != nil {
+			return err
+		}
+	}
+	errListening := http.ListenAndServe(":"+strconv.Itoa(a2asvr.httpPort), a2asvr.Handler())
 	if errListening != nil {
 		return errListening
 	}
 	return nil
 }
 
+// log returns the logger of the server
+func (a2asvr *A2AServer) log() logging.Logger {
+	return logging.OrDefault(a2asvr.logger)
+}
+
 // Serve the Agent Card at the well-known URL
 func (a2asvr *A2AServer) getAgentCard(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -100,14 +156,21 @@ func (a2asvr *A2AServer) handleTaskSync(w http.ResponseWriter, r *http.Request)
 	switch taskRequest.Method {
 	case "message/send":
 		if len(taskRequest.Params.Message.Parts) > 0 {
+			a2asvr.openContext(&taskRequest)
 			// Process the task synchronously without mutex in the HTTP handler
 			// The mutex should only be in the AgentCallback if needed
 			responseTask, err := a2asvr.agentCallback(taskRequest)
 			if err != nil {
-				log.Printf("Agent callback failed for task %s: %v", taskRequest.ID, err)
+				a2asvr.log().Error("agent callback failed", "task", taskRequest.ID, "error", err)
 				http.Error(w, `{"error": "agent callback failed"}`, http.StatusInternalServerError)
 				return
 			}
+			if a2asvr.sessions != nil {
+				if responseTask.Result.ContextID == "" {
+					responseTask.Result.ContextID = taskRequest.Params.Message.ContextID
+				}
+				a2asvr.recordExchange(taskRequest, responseAnswer(responseTask), responseTask.Result.Artifacts)
+			}
 
 			w.Header().Set("Content-Type", "application/json")
 			json.NewEncoder(w).Encode(responseTask)
@@ -141,18 +204,23 @@ func (a2asvr *A2AServer) handleTaskStream(w http.ResponseWriter, r *http.Request
 	switch taskRequest.Method {
 	case "message/send":
 		if len(taskRequest.Params.Message.Parts) > 0 {
+			a2asvr.openContext(&taskRequest)
 			// Set up Server-Sent Events headers
 			w.Header().Set("Content-Type", "text/event-stream")
 			w.Header().Set("Cache-Control", "no-cache")
 			w.Header().Set("Connection", "keep-alive")
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
+			// Without WithCORS, the stream can be read by every origin, without credentials
+			if a2asvr.corsConfig == nil {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+				w.Header().Set("Access-Control-Allow-Headers", "Cache-Control")
+			}
 
 			// Send initial response with task metadata
 			initialResponse := TaskResponse{
 				ID:             taskRequest.ID,
 				JSONRpcVersion: "2.0",
 				Result: Result{
+					ContextID: taskRequest.Params.Message.ContextID,
 					Status: TaskStatus{
 						State: "streaming",
 					},
@@ -188,7 +256,7 @@ func (a2asvr *A2AServer) handleTaskStream(w http.ResponseWriter, r *http.Request
 			// Call the streaming callback
 			err := a2asvr.agentStreamCallback(taskRequest, streamFunc)
 			if err != nil {
-				log.Printf("Agent stream callback failed for task %s: %v", taskRequest.ID, err)
+				a2asvr.log().Error("agent stream callback failed", "task", taskRequest.ID, "error", err)
 				errorResponse := map[string]any{
 					"id":    taskRequest.ID,
 					"type":  "error",
@@ -200,21 +268,24 @@ func (a2asvr *A2AServer) handleTaskStream(w http.ResponseWriter, r *http.Request
 				return
 			}
 
+			a2asvr.recordExchange(taskRequest, fullContent, nil)
+
 			// Send final response
 			finalResponse := TaskResponse{
 				ID:             taskRequest.ID,
 				JSONRpcVersion: "2.0",
 				Result: Result{
+					ContextID: taskRequest.Params.Message.ContextID,
 					Status: TaskStatus{
 						State: "completed",
 					},
 					History: []AgentMessage{
 						{
 							Role: "assistant",
-							Parts: []TextPart{
+							Parts: []Part{
 								{
 									Text: fullContent,
-									Type: "text",
+									Type: TextPartType,
 								},
 							},
 						},
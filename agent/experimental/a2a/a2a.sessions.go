@@ -0,0 +1,80 @@
+package a2a
+
+import (
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+)
+
+// WithSessions keeps the conversation of each contextId in a session: a request without contextId gets a new one,
+// and after each task the user message, the answer and the artifacts are added to the session of its context.
+// The callbacks read the previous messages of the context with manager.Get(taskRequest.Params.Message.ContextID).
+func WithSessions(manager *sessions.Manager) A2AServerOption {
+	return func(a2asvr *A2AServer) {
+		a2asvr.sessions = manager
+	}
+}
+
+// Sessions returns the session manager of the server (nil without WithSessions)
+func (a2asvr *A2AServer) Sessions() *sessions.Manager {
+	return a2asvr.sessions
+}
+
+// openContext gives a context ID to a request which has none
+func (a2asvr *A2AServer) openContext(taskRequest *TaskRequest) {
+	if a2asvr.sessions != nil && taskRequest.Params.Message.ContextID == "" {
+		taskRequest.Params.Message.ContextID = uuid.New().String()
+	}
+}
+
+// recordExchange adds the user message, the answer and the artifacts of a task to the session of its context
+func (a2asvr *A2AServer) recordExchange(taskRequest TaskRequest, answer string, artifacts []Artifact) {
+	if a2asvr.sessions == nil {
+		return
+	}
+	contextID := taskRequest.Params.Message.ContextID
+	_, err := a2asvr.sessions.Append(contextID,
+		openai.UserMessage(partsText(taskRequest.Params.Message.Parts)),
+		openai.AssistantMessage(answer),
+	)
+	if err != nil {
+		a2asvr.log().Error("failed to save the session", "context", contextID, "error", err)
+		return
+	}
+	for _, artifact := range artifacts {
+		_, err := a2asvr.sessions.AddArtifact(contextID, sessions.Artifact{
+			ID:       artifact.ArtifactID,
+			Name:     artifact.Name,
+			MimeType: "text/plain",
+			Content:  partsText(artifact.Parts),
+		})
+		if err != nil {
+			a2asvr.log().Error("failed to save the artifact", "context", contextID, "artifact", artifact.ArtifactID, "error", err)
+		}
+	}
+}
+
+// responseAnswer returns the text of the last assistant message of the history of a response
+func responseAnswer(taskResponse TaskResponse) string {
+	history := taskResponse.Result.History
+	for idx := len(history) - 1; idx >= 0; idx-- {
+		if history[idx].Role != "user" {
+			return partsText(history[idx].Parts)
+		}
+	}
+	return ""
+}
+
+// partsText joins the text parts
+func partsText(parts []Part) string {
+	texts := []string{}
+	for _, part := range parts {
+		if part.Type == TextPartType {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
@@ -0,0 +1,142 @@
+// Package a2a provides experimental functionality for µ-agent.
+//
+// WARNING: This package is experimental and subject to change.
+// The API may change or be removed in future versions without notice.
+// Use at your own risk in production environments.
+// NOTE: This is a partial implementation of the A2A protocol.
+// IMPORTANT: This is a work in progress and may not cover all aspects of the A2A protocol.
+package a2a
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SkillHandler processes a task request for a given skill
+type SkillHandler func(taskRequest TaskRequest) (TaskResponse, error)
+
+// SkillStreamHandler processes a task request for a given skill and streams the response
+type SkillStreamHandler func(taskRequest TaskRequest, streamFunc func(content string) error) error
+
+// SkillRouter dispatches task requests to handlers registered per skill id.
+// The skill id is read from the "skill" key of the request metadata.
+type SkillRouter struct {
+	agentCard            AgentCard
+	handlers             map[string]SkillHandler
+	streamHandlers       map[string]SkillStreamHandler
+	defaultHandler       SkillHandler
+	defaultStreamHandler SkillStreamHandler
+}
+
+// NewSkillRouter creates a skill router validating the registered skills against the skills declared in the agent card
+func NewSkillRouter(agentCard AgentCard) *SkillRouter {
+	return &SkillRouter{
+		agentCard:      agentCard,
+		handlers:       make(map[string]SkillHandler),
+		streamHandlers: make(map[string]SkillStreamHandler),
+	}
+}
+
+// GetSkill returns the skill id of the task request (empty string if there is no skill)
+func GetSkill(taskRequest TaskRequest) string {
+	skill, ok := taskRequest.Params.MetaData["skill"].(string)
+	if !ok {
+		return ""
+	}
+	return skill
+}
+
+// HasSkill returns true if the skill id is declared in the agent card
+func (router *SkillRouter) HasSkill(skillID string) bool {
+	for _, skill := range router.agentCard.Skills {
+		if id, ok := skill["id"].(string); ok && id == skillID {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle registers the handler of a skill.
+// It returns an error if the skill is not declared in the agent card.
+func (router *SkillRouter) Handle(skillID string, handler SkillHandler) error {
+	if !router.HasSkill(skillID) {
+		return fmt.Errorf("skill %s is not declared in the agent card", skillID)
+	}
+	router.handlers[skillID] = handler
+	return nil
+}
+
+// HandleStream registers the streaming handler of a skill.
+// It returns an error if the skill is not declared in the agent card.
+func (router *SkillRouter) HandleStream(skillID string, handler SkillStreamHandler) error {
+	if !router.HasSkill(skillID) {
+		return fmt.Errorf("skill %s is not declared in the agent card", skillID)
+	}
+	router.streamHandlers[skillID] = handler
+	return nil
+}
+
+// HandleDefault registers the handler used when no handler matches the skill of the request
+func (router *SkillRouter) HandleDefault(handler SkillHandler) {
+	router.defaultHandler = handler
+}
+
+// HandleDefaultStream registers the streaming handler used when no streaming handler matches the skill of the request
+func (router *SkillRouter) HandleDefaultStream(handler SkillStreamHandler) {
+	router.defaultStreamHandler = handler
+}
+
+// Dispatch calls the handler of the request skill.
+// Without a matching handler nor a default handler, it returns a "failed" task response explaining the skill is unknown.
+func (router *SkillRouter) Dispatch(taskRequest TaskRequest) (TaskResponse, error) {
+	skillID := GetSkill(taskRequest)
+	if handler, ok := router.handlers[skillID]; ok {
+		return handler(taskRequest)
+	}
+	if router.defaultHandler != nil {
+		return router.defaultHandler(taskRequest)
+	}
+	return unknownSkillResponse(taskRequest, skillID), nil
+}
+
+// DispatchStream calls the streaming handler of the request skill.
+// Without a matching handler nor a default handler, it returns an error explaining the skill is unknown.
+func (router *SkillRouter) DispatchStream(taskRequest TaskRequest, streamFunc func(content string) error) error {
+	skillID := GetSkill(taskRequest)
+	if handler, ok := router.streamHandlers[skillID]; ok {
+		return handler(taskRequest, streamFunc)
+	}
+	if router.defaultStreamHandler != nil {
+		return router.defaultStreamHandler(taskRequest, streamFunc)
+	}
+	return errors.New(unknownSkillMessage(skillID))
+}
+
+// unknownSkillMessage builds the message returned for an unknown skill
+func unknownSkillMessage(skillID string) string {
+	if skillID == "" {
+		return "no skill provided in the request metadata"
+	}
+	return fmt.Sprintf("unknown skill: %s", skillID)
+}
+
+// unknownSkillResponse builds a failed task response for an unknown skill
+func unknownSkillResponse(taskRequest TaskRequest, skillID string) TaskResponse {
+	return TaskResponse{
+		ID:             taskRequest.ID,
+		JSONRpcVersion: "2.0",
+		Result: Result{
+			Status: TaskStatus{
+				State: "failed",
+			},
+			History: []AgentMessage{
+				{
+					Role:  "assistant",
+					Parts: []Part{NewTextPart(unknownSkillMessage(skillID))},
+				},
+			},
+			Kind:     "task",
+			Metadata: map[string]any{},
+		},
+	}
+}
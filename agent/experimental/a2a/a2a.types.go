@@ -7,6 +7,8 @@
 // IMPORTANT: This is a work in progress and may not cover all aspects of the A2A protocol.
 package a2a
 
+import "encoding/json"
+
 // AgentCard represents the metadata for this agent
 type AgentCard struct {
 	Name         string           `json:"name"`
@@ -33,19 +35,28 @@ type TaskRequest struct {
 
 // Message represents a message structure
 type AgentMessage struct {
-	Role      string     `json:"role,omitempty"`
-	Parts     []TextPart `json:"parts"`
-	MessageID string     `json:"messageId,omitempty"` // Optional, for storing message ID
-	TaskID    string     `json:"taskId,omitempty"`    // Optional, for storing task ID
-	ContextID string     `json:"contextId,omitempty"` // Optional, for storing context ID
+	Role      string `json:"role,omitempty"`
+	Parts     []Part `json:"parts"`
+	MessageID string `json:"messageId,omitempty"` // Optional, for storing message ID
+	TaskID    string `json:"taskId,omitempty"`    // Optional, for storing task ID
+	ContextID string `json:"contextId,omitempty"` // Optional, for storing context ID
 }
 
-// TextPart represents a text part of a message
-type TextPart struct {
-	Text string `json:"text"`
-	Type string `json:"type"` // Should be "text" for text parts
+const (
+	TextPartType string = "text"
+	DataPartType string = "data"
+)
+
+// Part represents a part of a message: a text part ("text") or a structured data part ("data")
+type Part struct {
+	Text string          `json:"text,omitempty"`
+	Data json.RawMessage `json:"data,omitempty"` // Arbitrary JSON payload for data parts
+	Type string          `json:"type"`           // "text" for text parts, "data" for data parts
 }
 
+// TextPart represents a text part of a message (kept for backward compatibility, see Part)
+type TextPart = Part
+
 // TaskStatus represents the status of a task
 type TaskStatus struct {
 	State string `json:"state"`
@@ -55,9 +66,9 @@ type TaskStatus struct {
 // REF: https://google-a2a.github.io/A2A/specification/#92-basic-execution-synchronous-polling-style
 
 type Artifact struct {
-	ArtifactID string     `json:"artifactId"`
-	Name       string     `json:"name"`
-	Parts      []TextPart `json:"parts"` // Parts of the artifact, e.g., text, images, etc.
+	ArtifactID string `json:"artifactId"`
+	Name       string `json:"name"`
+	Parts      []Part `json:"parts"` // Parts of the artifact, e.g., text, images, etc.
 }
 
 type Result struct {
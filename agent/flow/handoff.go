@@ -0,0 +1,322 @@
+package flow
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// handoffToolPrefix is the prefix of the handoff tools: transfer_to_<agent name>
+const handoffToolPrefix = "transfer_to_"
+
+// toolsAgent is implemented by the agents whose tools can be changed (mu.BasicAgent)
+type toolsAgent interface {
+	GetTools() []openai.ChatCompletionToolUnionParam
+	SetTools(tools []openai.ChatCompletionToolUnionParam)
+}
+
+// HandoffMember is an agent of a swarm: it answers the user until it hands off the conversation to another member
+type HandoffMember struct {
+	// Agent answers the user, its tools must be settable (mu.BasicAgent): the handoff tools are added to its own tools
+	Agent mu.Agent
+	// Instructions are the system message of the member
+	Instructions string
+	// Description tells the other members when to hand off to this one (the description of the agent if empty)
+	Description string
+	// Execute runs the calls of the own tools of the agent (optional)
+	Execute func(functionName string, arguments string) (string, error)
+	// Targets are the members this one can hand off to (all the others if empty)
+	Targets []string
+}
+
+// Handoff is the switch of the conversation from an agent to another one
+type Handoff struct {
+	From   string
+	To     string
+	Reason string // context given by the agent handing off
+	// Messages are the messages of the conversation carried to the new agent
+	Messages []openai.ChatCompletionMessageParamUnion
+}
+
+// HandoffFilter selects the messages of the conversation carried to the new agent
+type HandoffFilter func(from, to string, messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion
+
+// CarryAll carries the whole conversation (default)
+func CarryAll() HandoffFilter {
+	return func(from, to string, messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+		return messages
+	}
+}
+
+// CarryLastUserMessages carries the last n user messages only, without the tool exchanges and the answers
+func CarryLastUserMessages(n int) HandoffFilter {
+	return func(from, to string, messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+		carried := []openai.ChatCompletionMessageParamUnion{}
+		for idx := len(messages) - 1; idx >= 0 && len(carried) < n; idx-- {
+			if messages[idx].OfUser != nil {
+				carried = append([]openai.ChatCompletionMessageParamUnion{messages[idx]}, carried...)
+			}
+		}
+		return carried
+	}
+}
+
+// SwarmResult is the result of a swarm run
+type SwarmResult struct {
+	// Answer is the answer of the last active agent
+	Answer string
+	// Agent is the name of the agent active at the end of the run
+	Agent string
+	// Messages is the conversation (without system message) to continue with the next run
+	Messages []openai.ChatCompletionMessageParamUnion
+	// Handoffs are the handoffs of the run
+	Handoffs []Handoff
+}
+
+// SwarmOption is a functional option for configuring Swarm instances
+type SwarmOption func(*Swarm)
+
+// Swarm runs a conversation where the active agent can hand it off to another agent with a
+// transfer_to_<name> tool call: the runtime switches the active agent mid-conversation.
+type Swarm struct {
+	members     map[string]HandoffMember
+	order       []string
+	ownTools    map[string][]openai.ChatCompletionToolUnionParam
+	active      string
+	maxHandoffs int
+	filter      HandoffFilter
+	onHandoff   func(handoff Handoff)
+}
+
+// NewSwarm creates a swarm, the first member is the active agent (see WithActiveAgent)
+//
+// Example usage:
+//
+//	swarm, err := flow.NewSwarm(
+//	  flow.WithMembers(
+//	    flow.HandoffMember{Agent: triage, Instructions: "Route the user to the right agent."},
+//	    flow.HandoffMember{Agent: billing, Instructions: "You handle the invoices.", Description: "invoices and payments"},
+//	  ),
+//	  flow.WithOnHandoff(func(handoff flow.Handoff) { fmt.Println(handoff.From, "→", handoff.To) }),
+//	)
+//	result, err := swarm.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage("I was charged twice")})
+func NewSwarm(options ...SwarmOption) (*Swarm, error) {
+	swarm := &Swarm{
+		members:     map[string]HandoffMember{},
+		ownTools:    map[string][]openai.ChatCompletionToolUnionParam{},
+		maxHandoffs: 5,
+		filter:      CarryAll(),
+	}
+	for _, option := range options {
+		option(swarm)
+	}
+	if len(swarm.order) == 0 {
+		return nil, errors.New("a swarm needs at least one member")
+	}
+	for _, name := range swarm.order {
+		member := swarm.members[name]
+		agent, ok := member.Agent.(toolsAgent)
+		if !ok {
+			return nil, fmt.Errorf("the tools of the agent %s can't be set", name)
+		}
+		swarm.ownTools[name] = agent.GetTools()
+		for _, target := range member.Targets {
+			if _, exists := swarm.members[target]; !exists || target == name {
+				return nil, fmt.Errorf("invalid handoff target %s of the agent %s", target, name)
+			}
+		}
+	}
+	if swarm.active == "" {
+		swarm.active = swarm.order[0]
+	}
+	if _, ok := swarm.members[swarm.active]; !ok {
+		return nil, fmt.Errorf("unknown active agent %s", swarm.active)
+	}
+	return swarm, nil
+}
+
+// WithMembers is a functional option that adds members, identified by the name of their agent
+func WithMembers(members ...HandoffMember) SwarmOption {
+	return func(swarm *Swarm) {
+		for _, member := range members {
+			name := member.Agent.GetName()
+			if _, exists := swarm.members[name]; !exists {
+				swarm.order = append(swarm.order, name)
+			}
+			swarm.members[name] = member
+		}
+	}
+}
+
+// WithActiveAgent is a functional option that sets the agent answering first
+func WithActiveAgent(name string) SwarmOption {
+	return func(swarm *Swarm) {
+		swarm.active = name
+	}
+}
+
+// WithMaxHandoffs is a functional option that limits the handoffs of a run (5 by default)
+func WithMaxHandoffs(maxHandoffs int) SwarmOption {
+	return func(swarm *Swarm) {
+		swarm.maxHandoffs = maxHandoffs
+	}
+}
+
+// WithHandoffFilter is a functional option that selects the messages carried to the new agent (CarryAll by default)
+func WithHandoffFilter(filter HandoffFilter) SwarmOption {
+	return func(swarm *Swarm) {
+		swarm.filter = filter
+	}
+}
+
+// WithOnHandoff is a functional option that sets the callback called on each handoff
+func WithOnHandoff(callback func(handoff Handoff)) SwarmOption {
+	return func(swarm *Swarm) {
+		swarm.onHandoff = callback
+	}
+}
+
+// Active returns the name of the active agent
+func (swarm *Swarm) Active() string {
+	return swarm.active
+}
+
+// SetActive switches the active agent
+func (swarm *Swarm) SetActive(name string) error {
+	if _, ok := swarm.members[name]; !ok {
+		return fmt.Errorf("unknown agent %s", name)
+	}
+	swarm.active = name
+	return nil
+}
+
+// Run answers the conversation (without system message, the instructions of the active agent are added).
+// The active agent is kept between the runs: the next run continues with the agent of the last handoff.
+//
+// Returns:
+//   - SwarmResult: The answer, the active agent, the conversation and the handoffs
+//   - error: Any error of the completions, or an error when the maximum number of handoffs is exceeded
+func (swarm *Swarm) Run(messages []openai.ChatCompletionMessageParamUnion) (SwarmResult, error) {
+	result := SwarmResult{Handoffs: []Handoff{}}
+	conversation := messages
+	reason := ""
+	previous := ""
+
+	for {
+		name := swarm.active
+		member := swarm.members[name]
+		agent := member.Agent.(toolsAgent)
+		agent.SetTools(append(slices.Clone(swarm.ownTools[name]), swarm.handoffTools(name)...))
+
+		var handoff *Handoff
+		execute := func(functionName string, arguments string) (string, error) {
+			if target, ok := strings.CutPrefix(functionName, handoffToolPrefix); ok && slices.Contains(swarm.targets(name), target) {
+				var args struct {
+					Reason string `json:"reason"`
+				}
+				_ = json.Unmarshal([]byte(arguments), &args)
+				handoff = &Handoff{From: name, To: target, Reason: args.Reason}
+				return "", &mu.ExitToolCallsLoopError{Message: "handoff to " + target}
+			}
+			if member.Execute == nil {
+				return "", fmt.Errorf("no executor for the tool %s", functionName)
+			}
+			return member.Execute(functionName, arguments)
+		}
+
+		system := openai.SystemMessage(swarm.instructions(name, previous, reason))
+		_, _, answer, err := member.Agent.DetectToolCalls(append([]openai.ChatCompletionMessageParamUnion{system}, conversation...), execute)
+		agent.SetTools(swarm.ownTools[name])
+		if err != nil {
+			result.Agent = name
+			result.Messages = conversation
+			return result, err
+		}
+		// The messages of the agent start with the system message
+		if agentMessages := member.Agent.GetMessages(); len(agentMessages) > 0 {
+			conversation = slices.Clone(agentMessages[1:])
+		}
+
+		if handoff == nil {
+			conversation = append(conversation, openai.AssistantMessage(answer))
+			result.Answer = answer
+			result.Agent = name
+			result.Messages = conversation
+			return result, nil
+		}
+
+		if len(result.Handoffs) >= swarm.maxHandoffs {
+			result.Agent = name
+			result.Messages = conversation
+			return result, fmt.Errorf("maximum number of handoffs (%d) exceeded", swarm.maxHandoffs)
+		}
+		conversation = swarm.filter(handoff.From, handoff.To, conversation)
+		handoff.Messages = conversation
+		result.Handoffs = append(result.Handoffs, *handoff)
+		if swarm.onHandoff != nil {
+			swarm.onHandoff(*handoff)
+		}
+		previous, reason = handoff.From, handoff.Reason
+		swarm.active = handoff.To
+	}
+}
+
+// targets returns the members an agent can hand off to
+func (swarm *Swarm) targets(name string) []string {
+	if targets := swarm.members[name].Targets; len(targets) > 0 {
+		return targets
+	}
+	targets := []string{}
+	for _, other := range swarm.order {
+		if other != name {
+			targets = append(targets, other)
+		}
+	}
+	return targets
+}
+
+// handoffTools returns the transfer_to_<name> tools of an agent
+func (swarm *Swarm) handoffTools(name string) []openai.ChatCompletionToolUnionParam {
+	tools := []openai.ChatCompletionToolUnionParam{}
+	for _, target := range swarm.targets(name) {
+		description := swarm.members[target].Description
+		if description == "" {
+			description = swarm.members[target].Agent.GetDescription()
+		}
+		tools = append(tools, openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        handoffToolPrefix + target,
+			Description: openai.String(fmt.Sprintf("Hand off the conversation to %s: %s", target, description)),
+			Parameters: shared.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"reason": map[string]any{
+						"type":        "string",
+						"description": "why the conversation is handed off, with the context the next agent needs",
+					},
+				},
+				"required": []string{},
+			},
+		}))
+	}
+	return tools
+}
+
+// instructions returns the system message of the active agent, with the context of the handoff
+func (swarm *Swarm) instructions(name, previous, reason string) string {
+	instructions := swarm.members[name].Instructions
+	instructions += "\n\nIf another agent is better suited to answer, hand off the conversation with the matching transfer_to_ tool."
+	if previous != "" {
+		instructions += fmt.Sprintf("\n\nThe agent %s handed off the conversation to you.", previous)
+		if reason != "" {
+			instructions += " Context: " + reason
+		}
+	}
+	return instructions
+}
@@ -0,0 +1,226 @@
+// Package flow composes agents: sequential pipelines (teams) of agents passing their output to the next one,
+// supervisors delegating the parts of a task to worker agents, and swarms of agents handing off the conversation.
+package flow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ErrorPolicy tells a pipeline what to do when a step fails
+type ErrorPolicy int
+
+const (
+	// StopOnError stops the pipeline and returns the error (default)
+	StopOnError ErrorPolicy = iota
+	// SkipOnError ignores the failed step: its input is passed unchanged to the next step
+	SkipOnError
+	// RetryOnError runs the failed step again, up to MaxRetries times, then stops the pipeline
+	RetryOnError
+)
+
+// Step is a step of a pipeline: an agent with optional transformations of its input and output
+type Step struct {
+	// Agent runs the step: the input is sent as a user message, its answer is the output of the step
+	Agent mu.Agent
+	// Name identifies the step in the results (the name of the agent if empty)
+	Name string
+	// Input builds the prompt of the agent from the output of the previous step (the output itself if nil)
+	Input func(input string) string
+	// Output transforms or validates the answer of the agent (the answer itself if nil), an error fails the step
+	Output func(output string) (string, error)
+	// OnError is the error policy of the step
+	OnError ErrorPolicy
+	// MaxRetries is the number of retries of the RetryOnError policy (1 if zero)
+	MaxRetries int
+}
+
+// StepResult is the execution of a step
+type StepResult struct {
+	Index    int
+	Name     string
+	Input    string // prompt sent to the agent
+	Output   string // output passed to the next step
+	Err      error  // error of the last attempt (nil if it succeeded)
+	Skipped  bool   // the step failed and was skipped (SkipOnError)
+	Attempts int
+	Duration time.Duration
+}
+
+// PipelineOption is a functional option for configuring Pipeline instances
+type PipelineOption func(*Pipeline)
+
+// Pipeline runs a list of agents in order, the output of each agent being the input of the next one.
+// The messages of the agents are restored after each step: a pipeline can be run several times.
+type Pipeline struct {
+	steps       []Step
+	onStepStart func(index int, name string, input string)
+	onStepEnd   func(result StepResult) error
+}
+
+// NewPipeline creates a pipeline with its steps and callbacks
+//
+// Example usage:
+//
+//	pipeline := flow.NewPipeline(
+//	  flow.WithSteps(
+//	    flow.Step{Agent: researcher},
+//	    flow.Step{Agent: writer, Input: func(notes string) string { return "Write an article from:\n" + notes }},
+//	    flow.Step{Agent: reviewer, OnError: flow.SkipOnError},
+//	  ),
+//	  flow.WithOnStepEnd(func(result flow.StepResult) error {
+//	    fmt.Println(result.Name, "done in", result.Duration)
+//	    return nil
+//	  }),
+//	)
+//	article, results, err := pipeline.Run("the history of Go")
+func NewPipeline(options ...PipelineOption) *Pipeline {
+	pipeline := &Pipeline{}
+	for _, option := range options {
+		option(pipeline)
+	}
+	return pipeline
+}
+
+// NewTeam creates a pipeline of agents without transformations: each agent works on the answer of the previous one
+func NewTeam(agents ...mu.Agent) *Pipeline {
+	pipeline := NewPipeline()
+	for _, agent := range agents {
+		pipeline.AddStep(Step{Agent: agent})
+	}
+	return pipeline
+}
+
+// WithSteps is a functional option that adds steps to a pipeline
+func WithSteps(steps ...Step) PipelineOption {
+	return func(pipeline *Pipeline) {
+		pipeline.steps = append(pipeline.steps, steps...)
+	}
+}
+
+// WithOnStepStart is a functional option that sets the callback called before each step with its prompt
+func WithOnStepStart(callback func(index int, name string, input string)) PipelineOption {
+	return func(pipeline *Pipeline) {
+		pipeline.onStepStart = callback
+	}
+}
+
+// WithOnStepEnd is a functional option that sets the callback called after each step,
+// returning an error stops the pipeline with this error
+func WithOnStepEnd(callback func(result StepResult) error) PipelineOption {
+	return func(pipeline *Pipeline) {
+		pipeline.onStepEnd = callback
+	}
+}
+
+// AddStep adds a step at the end of the pipeline
+func (pipeline *Pipeline) AddStep(step Step) *Pipeline {
+	pipeline.steps = append(pipeline.steps, step)
+	return pipeline
+}
+
+// Steps returns the steps of the pipeline
+func (pipeline *Pipeline) Steps() []Step {
+	return pipeline.steps
+}
+
+// Run executes the steps in order, starting with input.
+//
+// Returns:
+//   - string: The output of the last step
+//   - []StepResult: The results of the executed steps
+//   - error: The error of the step that stopped the pipeline (a *StepError), or of the OnStepEnd callback
+func (pipeline *Pipeline) Run(input string) (string, []StepResult, error) {
+	results := []StepResult{}
+	current := input
+
+	for index, step := range pipeline.steps {
+		if step.Agent == nil {
+			return current, results, &StepError{Index: index, Name: step.Name, Err: errors.New("the step has no agent")}
+		}
+		name := step.Name
+		if name == "" {
+			name = step.Agent.GetName()
+		}
+		prompt := current
+		if step.Input != nil {
+			prompt = step.Input(current)
+		}
+		if pipeline.onStepStart != nil {
+			pipeline.onStepStart(index, name, prompt)
+		}
+
+		result := StepResult{Index: index, Name: name, Input: prompt}
+		start := time.Now()
+		maxAttempts := 1
+		if step.OnError == RetryOnError {
+			maxAttempts += max(step.MaxRetries, 1)
+		}
+		for result.Attempts < maxAttempts {
+			result.Attempts++
+			result.Output, result.Err = runStep(step, prompt)
+			if result.Err == nil {
+				break
+			}
+		}
+		result.Duration = time.Since(start)
+
+		if result.Err != nil && step.OnError == SkipOnError {
+			result.Skipped = true
+			result.Output = current
+		}
+		results = append(results, result)
+
+		if pipeline.onStepEnd != nil {
+			if err := pipeline.onStepEnd(result); err != nil {
+				return current, results, err
+			}
+		}
+		if result.Err != nil && !result.Skipped {
+			return current, results, &StepError{Index: index, Name: name, Err: result.Err}
+		}
+		current = result.Output
+	}
+	return current, results, nil
+}
+
+// runStep sends the prompt to the agent of the step and transforms its answer
+func runStep(step Step, prompt string) (string, error) {
+	answer, err := ask(step.Agent, prompt)
+	if err != nil {
+		return "", err
+	}
+	if step.Output != nil {
+		return step.Output(answer)
+	}
+	return answer, nil
+}
+
+// ask sends a prompt to an agent and restores its messages afterwards, so the agent can be reused
+func ask(agent mu.Agent, prompt string) (string, error) {
+	saved := append([]openai.ChatCompletionMessageParamUnion{}, agent.GetMessages()...)
+	defer agent.SetMessages(saved)
+	return agent.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)})
+}
+
+// StepError is the error of the step that stopped a pipeline
+type StepError struct {
+	Index int
+	Name  string
+	Err   error
+}
+
+// Error implements the error interface for StepError
+func (e *StepError) Error() string {
+	return fmt.Sprintf("step %d (%s) failed: %v", e.Index, e.Name, e.Err)
+}
+
+// Unwrap returns the error of the step
+func (e *StepError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,291 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// delegateToolName is the name of the tool the supervisor calls to delegate a task to a worker
+const delegateToolName = "delegate"
+
+// Worker is an agent the supervisor can delegate tasks to
+type Worker struct {
+	// Name identifies the worker for the supervisor (the name of the agent if empty)
+	Name string
+	// Skills describes what the worker can do, the supervisor chooses the workers from their skills
+	Skills string
+	// Agent runs the tasks of the worker (its messages are restored after each task)
+	Agent mu.Agent
+	// Run (optional) runs the tasks instead of Agent, e.g. the Task method of another supervisor
+	Run func(task string) (string, error)
+}
+
+// Delegation is a task delegated by the supervisor to a worker
+type Delegation struct {
+	Worker string
+	Task   string
+	Result string
+	Err    error
+}
+
+// SupervisorOption is a functional option for configuring Supervisor instances
+type SupervisorOption func(*Supervisor)
+
+// Supervisor is an agent which receives a task, delegates its parts to the registered workers with
+// tool calls, and synthesizes the final answer from their results.
+type Supervisor struct {
+	agent        *mu.BasicAgent
+	agentOptions []mu.AgentOption
+	instructions string
+	workers      []Worker
+	maxDepth     int
+	onDelegate   func(worker string, task string)
+}
+
+// NewSupervisor creates a supervisor, the agent options configure its client and its completion parameters
+// (the tools are set by the supervisor).
+//
+// Example usage:
+//
+//	supervisor, err := flow.NewSupervisor(ctx, "Boss",
+//	  flow.WithSupervisorAgent(mu.WithClient(client), mu.WithParams(openai.ChatCompletionNewParams{Model: model})),
+//	  flow.WithWorkers(
+//	    flow.Worker{Name: "coder", Skills: "writes Go code", Agent: coder},
+//	    flow.Worker{Name: "tester", Skills: "writes unit tests", Agent: tester},
+//	  ),
+//	  flow.WithMaxDepth(4),
+//	)
+//	answer, delegations, err := supervisor.Run("Write a function reversing a string, with its tests")
+func NewSupervisor(ctx context.Context, name string, options ...SupervisorOption) (*Supervisor, error) {
+	supervisor := &Supervisor{
+		maxDepth: 5,
+	}
+	for _, option := range options {
+		option(supervisor)
+	}
+	if len(supervisor.workers) == 0 {
+		return nil, errors.New("a supervisor needs at least one worker")
+	}
+	names := map[string]bool{}
+	for idx, worker := range supervisor.workers {
+		if worker.Name == "" && worker.Agent != nil {
+			worker.Name = worker.Agent.GetName()
+			supervisor.workers[idx] = worker
+		}
+		if worker.Name == "" || names[worker.Name] {
+			return nil, fmt.Errorf("the worker %d has no name or a duplicated name", idx)
+		}
+		if worker.Agent == nil && worker.Run == nil {
+			return nil, fmt.Errorf("the worker %s has no agent", worker.Name)
+		}
+		names[worker.Name] = true
+	}
+	agent, err := mu.NewAgent(ctx, name, supervisor.agentOptions...)
+	if err != nil {
+		return nil, err
+	}
+	// The supervisor sets the tools in the parameters of its agent
+	supervisor.agent = agent.(*mu.BasicAgent)
+	return supervisor, nil
+}
+
+// WithSupervisorAgent is a functional option that configures the agent of the supervisor (client, parameters)
+func WithSupervisorAgent(options ...mu.AgentOption) SupervisorOption {
+	return func(supervisor *Supervisor) {
+		supervisor.agentOptions = append(supervisor.agentOptions, options...)
+	}
+}
+
+// WithWorkers is a functional option that registers workers
+func WithWorkers(workers ...Worker) SupervisorOption {
+	return func(supervisor *Supervisor) {
+		supervisor.workers = append(supervisor.workers, workers...)
+	}
+}
+
+// WithInstructions is a functional option that adds instructions to the system message of the supervisor
+func WithInstructions(instructions string) SupervisorOption {
+	return func(supervisor *Supervisor) {
+		supervisor.instructions = instructions
+	}
+}
+
+// WithMaxDepth is a functional option that sets the maximum number of delegations for a task (5 by default).
+// When it is reached, the supervisor must synthesize the answer from the results it has.
+func WithMaxDepth(maxDepth int) SupervisorOption {
+	return func(supervisor *Supervisor) {
+		supervisor.maxDepth = maxDepth
+	}
+}
+
+// WithOnDelegate is a functional option that sets the callback called before each delegation
+func WithOnDelegate(callback func(worker string, task string)) SupervisorOption {
+	return func(supervisor *Supervisor) {
+		supervisor.onDelegate = callback
+	}
+}
+
+// Workers returns the registered workers
+func (supervisor *Supervisor) Workers() []Worker {
+	return supervisor.workers
+}
+
+// Task runs a task and returns only the final answer: a supervisor can be the worker of another one
+func (supervisor *Supervisor) Task(task string) (string, error) {
+	answer, _, err := supervisor.Run(task)
+	return answer, err
+}
+
+// Run delegates the parts of the task to the workers and returns the final answer.
+//
+// Returns:
+//   - string: The final answer synthesized by the supervisor
+//   - []Delegation: The tasks delegated to the workers with their results
+//   - error: Any error of the completions of the supervisor (the errors of the workers are reported to the supervisor)
+func (supervisor *Supervisor) Run(task string) (string, []Delegation, error) {
+	delegations := []Delegation{}
+	limitReached := false
+
+	delegate := func(functionName string, arguments string) (string, error) {
+		if functionName != delegateToolName {
+			return `{"error": "unknown tool, use ` + delegateToolName + `"}`, nil
+		}
+		if len(delegations) >= supervisor.maxDepth {
+			limitReached = true
+			return "", &mu.ExitToolCallsLoopError{Message: "maximum delegation depth reached"}
+		}
+		var args struct {
+			Worker string `json:"worker"`
+			Task   string `json:"task"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		worker, ok := supervisor.worker(args.Worker)
+		if !ok {
+			return "", fmt.Errorf("unknown worker %s", args.Worker)
+		}
+		if supervisor.onDelegate != nil {
+			supervisor.onDelegate(worker.Name, args.Task)
+		}
+
+		delegation := Delegation{Worker: worker.Name, Task: args.Task}
+		if worker.Run != nil {
+			delegation.Result, delegation.Err = worker.Run(args.Task)
+		} else {
+			delegation.Result, delegation.Err = ask(worker.Agent, args.Task)
+		}
+		delegations = append(delegations, delegation)
+		if delegation.Err != nil {
+			return "", delegation.Err
+		}
+		data, err := json.Marshal(map[string]string{"worker": worker.Name, "result": delegation.Result})
+		return string(data), err
+	}
+
+	supervisor.agent.Params.Tools = []openai.ChatCompletionToolUnionParam{supervisor.delegateTool()}
+	supervisor.agent.Params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String("auto")}
+	supervisor.agent.Params.ParallelToolCalls = openai.Opt(false)
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(supervisor.systemMessage()),
+		openai.UserMessage(task),
+	}
+	finishReason, _, answer, err := supervisor.agent.DetectToolCalls(messages, delegate)
+	if err != nil {
+		return "", delegations, err
+	}
+	if finishReason == "stop" && !limitReached {
+		return answer, delegations, nil
+	}
+
+	// The delegation loop was interrupted: synthesize the answer from the results without tools
+	answer, err = supervisor.synthesize(task, delegations)
+	return answer, delegations, err
+}
+
+// worker returns a worker by name
+func (supervisor *Supervisor) worker(name string) (Worker, bool) {
+	for _, worker := range supervisor.workers {
+		if worker.Name == name {
+			return worker, true
+		}
+	}
+	return Worker{}, false
+}
+
+// systemMessage describes the role of the supervisor and its workers
+func (supervisor *Supervisor) systemMessage() string {
+	var builder strings.Builder
+	builder.WriteString("You are a supervisor. Split the task of the user into sub-tasks and delegate each of them ")
+	builder.WriteString("to the most skilled worker with the " + delegateToolName + " tool. ")
+	builder.WriteString("A worker doesn't see the conversation: each sub-task must give all the needed context. ")
+	builder.WriteString(fmt.Sprintf("You can delegate at most %d sub-tasks. ", supervisor.maxDepth))
+	builder.WriteString("When you have the results, answer the user with the final synthesis.\n\nWorkers:\n")
+	for _, worker := range supervisor.workers {
+		builder.WriteString("- " + worker.Name + ": " + worker.Skills + "\n")
+	}
+	if supervisor.instructions != "" {
+		builder.WriteString("\n" + supervisor.instructions + "\n")
+	}
+	return builder.String()
+}
+
+// delegateTool returns the definition of the delegation tool, the worker names are an enumeration
+func (supervisor *Supervisor) delegateTool() openai.ChatCompletionToolUnionParam {
+	names := make([]string, 0, len(supervisor.workers))
+	for _, worker := range supervisor.workers {
+		names = append(names, worker.Name)
+	}
+	return openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+		Name:        delegateToolName,
+		Description: openai.String("Delegate a sub-task to a worker and get its result"),
+		Parameters: shared.FunctionParameters{
+			"type": "object",
+			"properties": map[string]any{
+				"worker": map[string]any{
+					"type":        "string",
+					"enum":        names,
+					"description": "name of the worker",
+				},
+				"task": map[string]any{
+					"type":        "string",
+					"description": "the sub-task, with all the needed context",
+				},
+			},
+			"required": []string{"worker", "task"},
+		},
+	})
+}
+
+// synthesize asks the supervisor, without tools, for the final answer from the results of the workers
+func (supervisor *Supervisor) synthesize(task string, delegations []Delegation) (string, error) {
+	var builder strings.Builder
+	builder.WriteString("Task:\n" + task + "\n\nResults of the workers:\n")
+	for _, delegation := range delegations {
+		result := delegation.Result
+		if delegation.Err != nil {
+			result = "error: " + delegation.Err.Error()
+		}
+		builder.WriteString(fmt.Sprintf("\n## %s: %s\n%s\n", delegation.Worker, delegation.Task, result))
+	}
+	builder.WriteString("\nAnswer the task with the final synthesis of these results.")
+
+	params := supervisor.agent.Params
+	defer func() { supervisor.agent.Params = params }()
+	supervisor.agent.Params.Tools = nil
+	supervisor.agent.Params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{}
+	supervisor.agent.Params.ParallelToolCalls = openai.ChatCompletionNewParams{}.ParallelToolCalls
+	supervisor.agent.Params.Messages = []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You are a supervisor synthesizing the results of your workers."),
+	}
+	return supervisor.agent.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(builder.String())})
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import "sync"
+
+// CappedBuffer is an io.Writer keeping the first bytes written to it, up to its limit: the other bytes are
+// accepted and dropped, so that a command writing a large output isn't interrupted (e.g. the output
+// of exec.Cmd given to a model). It can be written by several goroutines.
+//
+// Example usage:
+//
+//	output := helpers.NewCappedBuffer(64 * 1024)
+//	cmd.Stdout = output
+//	cmd.Stderr = output
+//	err := cmd.Run()
+//	fmt.Println(output.String(), output.Truncated())
+type CappedBuffer struct {
+	mutex     sync.Mutex
+	data      []byte
+	limit     int
+	truncated bool
+}
+
+// NewCappedBuffer creates a buffer keeping at most limit bytes
+func NewCappedBuffer(limit int) *CappedBuffer {
+	return &CappedBuffer{limit: limit}
+}
+
+// Write keeps the bytes under the limit, and accepts the others
+func (b *CappedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	written := len(p)
+	if remaining := b.limit - len(b.data); remaining < len(p) {
+		b.truncated = true
+		p = p[:max(remaining, 0)]
+	}
+	b.data = append(b.data, p...)
+	return written, nil
+}
+
+// String returns the kept bytes
+func (b *CappedBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return string(b.data)
+}
+
+// Truncated reports whether bytes were dropped
+func (b *CappedBuffer) Truncated() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.truncated
+}
@@ -0,0 +1,191 @@
+package helpers
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"os"
+	"unicode/utf8"
+)
+
+// TextChunk is a block of text read by a ChunkReader
+type TextChunk struct {
+	// Index is the position of the chunk in the stream, from 0
+	Index int
+	// Offset is the position in bytes of the first byte of the chunk in the stream
+	Offset int64
+	Text   string
+}
+
+// ChunkReaderOption is a functional option for configuring ChunkReader instances
+type ChunkReaderOption func(*ChunkReader)
+
+// ChunkReader reads a stream as bounded blocks of text, so large files (logs, documentation dumps...) can be
+// chunked without being loaded in memory: only a block is kept in memory at a time.
+// The blocks never split a UTF-8 character and, by default, end at a line break when the block has one
+// in its second half.
+type ChunkReader struct {
+	reader         io.Reader
+	blockSize      int
+	overlap        int
+	lineBoundaries bool
+
+	buffer   []byte
+	repeated int // bytes at the start of the buffer already returned (the overlap)
+	offset   int64
+	index    int
+	eof      bool
+}
+
+// NewChunkReader creates a reader of the blocks of a stream
+//
+// Example usage:
+//
+//	file, err := os.Open("server.log")
+//	defer file.Close()
+//	reader := helpers.NewChunkReader(file, helpers.WithBlockSize(4096), helpers.WithBlockOverlap(256))
+//	for {
+//	  chunk, err := reader.Next()
+//	  if err == io.EOF {
+//	    break
+//	  }
+//	  ...
+//	}
+func NewChunkReader(reader io.Reader, options ...ChunkReaderOption) *ChunkReader {
+	chunkReader := &ChunkReader{reader: reader, blockSize: 64 << 10, lineBoundaries: true}
+	for _, option := range options {
+		option(chunkReader)
+	}
+	// A block must hold a UTF-8 character, and the overlap must leave room to progress
+	chunkReader.blockSize = max(chunkReader.blockSize, utf8.UTFMax)
+	chunkReader.overlap = max(min(chunkReader.overlap, chunkReader.blockSize/2-utf8.UTFMax), 0)
+	return chunkReader
+}
+
+// WithBlockSize is a functional option that sets the maximum size in bytes of the blocks (64KB by default)
+func WithBlockSize(blockSize int) ChunkReaderOption {
+	return func(r *ChunkReader) {
+		r.blockSize = blockSize
+	}
+}
+
+// WithBlockOverlap is a functional option that sets the number of bytes at the end of a block repeated at the
+// start of the next one, so a sentence split by a block boundary is whole in one of them (none by default,
+// at most the half of the block size)
+func WithBlockOverlap(overlap int) ChunkReaderOption {
+	return func(r *ChunkReader) {
+		r.overlap = overlap
+	}
+}
+
+// WithLineBoundaries is a functional option that enables or disables the cut of the blocks at the line breaks
+// (enabled by default)
+func WithLineBoundaries(enabled bool) ChunkReaderOption {
+	return func(r *ChunkReader) {
+		r.lineBoundaries = enabled
+	}
+}
+
+// Next returns the next block of the stream, or io.EOF after the last one
+func (r *ChunkReader) Next() (TextChunk, error) {
+	if !r.eof && len(r.buffer) < r.blockSize {
+		if cap(r.buffer) < r.blockSize {
+			buffer := make([]byte, len(r.buffer), r.blockSize)
+			copy(buffer, r.buffer)
+			r.buffer = buffer
+		}
+		n, err := io.ReadFull(r.reader, r.buffer[len(r.buffer):r.blockSize])
+		r.buffer = r.buffer[:len(r.buffer)+n]
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			r.eof = true
+		case err != nil:
+			return TextChunk{}, err
+		}
+	}
+	if len(r.buffer) == r.repeated {
+		// Nothing left but the overlap of the last block
+		return TextChunk{}, io.EOF
+	}
+
+	cut := len(r.buffer)
+	if !r.eof {
+		cut = r.boundary()
+	}
+	chunk := TextChunk{Index: r.index, Offset: r.offset, Text: string(r.buffer[:cut])}
+	r.index++
+	if r.eof {
+		r.buffer, r.repeated = r.buffer[:0], 0
+		return chunk, nil
+	}
+
+	// The overlap starts at the beginning of a UTF-8 character, the rest of the block is moved
+	// to the start of the buffer, which is reused
+	next := cut - r.overlap
+	for next < cut && !utf8.RuneStart(r.buffer[next]) {
+		next++
+	}
+	r.offset += int64(next)
+	r.buffer = r.buffer[:copy(r.buffer, r.buffer[next:])]
+	r.repeated = cut - next
+	return chunk, nil
+}
+
+// boundary returns the end of the current block: after the last line break of its second half,
+// else before its last incomplete UTF-8 character
+func (r *ChunkReader) boundary() int {
+	if r.lineBoundaries {
+		for i := len(r.buffer) - 1; i >= len(r.buffer)/2; i-- {
+			if r.buffer[i] == '\n' {
+				return i + 1
+			}
+		}
+	}
+	cut := len(r.buffer)
+	for i := len(r.buffer) - 1; i >= 0 && i >= len(r.buffer)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(r.buffer[i]) {
+			if !utf8.FullRune(r.buffer[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+	if cut == 0 {
+		// Not UTF-8 text: the block is returned as is
+		return len(r.buffer)
+	}
+	return cut
+}
+
+// ReadFileChunks iterates over the blocks of a file (see NewChunkReader), the file is closed at the end
+// of the iteration
+//
+// Example usage:
+//
+//	for chunk, err := range helpers.ReadFileChunks("docs.txt", helpers.WithBlockSize(1024), helpers.WithBlockOverlap(128)) {
+//	  if err != nil {
+//	    return err
+//	  }
+//	  embedding, err := embeddingAgent.GenerateEmbeddingVector(chunk.Text)
+//	  ...
+//	}
+func ReadFileChunks(path string, options ...ChunkReaderOption) iter.Seq2[TextChunk, error] {
+	return func(yield func(TextChunk, error) bool) {
+		file, err := os.Open(path)
+		if err != nil {
+			yield(TextChunk{}, err)
+			return
+		}
+		defer file.Close()
+		reader := NewChunkReader(file, options...)
+		for {
+			chunk, err := reader.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(chunk, err) || err != nil {
+				return
+			}
+		}
+	}
+}
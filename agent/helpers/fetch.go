@@ -0,0 +1,170 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrUnsupportedContentType is returned by FetchURLAsText for the binary documents (images, PDF, archives...)
+var ErrUnsupportedContentType = errors.New("unsupported content type")
+
+// FetchedText is a document downloaded by FetchURLAsText
+type FetchedText struct {
+	// URL is the final URL of the document, after the redirections
+	URL         string
+	Title       string
+	ContentType string
+	// Content is the markdown of an HTML page, or the text of the other documents
+	Content string
+	// Truncated is true when the document was larger than the size cap
+	Truncated bool
+}
+
+// FetchOption is a functional option for configuring FetchURLAsText
+type FetchOption func(*fetchSettings)
+
+// fetchSettings holds the options of FetchURLAsText
+type fetchSettings struct {
+	client    *http.Client
+	timeout   time.Duration
+	maxBytes  int64
+	userAgent string
+}
+
+// WithFetchTimeout is a functional option that sets the maximum duration of a download (30 seconds by default)
+func WithFetchTimeout(timeout time.Duration) FetchOption {
+	return func(settings *fetchSettings) {
+		settings.timeout = timeout
+	}
+}
+
+// WithFetchMaxBytes is a functional option that sets the maximum size of a downloaded document, the rest
+// is ignored (2MB by default, no limit if zero)
+func WithFetchMaxBytes(maxBytes int64) FetchOption {
+	return func(settings *fetchSettings) {
+		settings.maxBytes = maxBytes
+	}
+}
+
+// WithFetchUserAgent is a functional option that sets the User-Agent of the requests
+func WithFetchUserAgent(userAgent string) FetchOption {
+	return func(settings *fetchSettings) {
+		settings.userAgent = userAgent
+	}
+}
+
+// WithFetchClient is a functional option that sets the HTTP client of the downloads (http.DefaultClient by default)
+func WithFetchClient(client *http.Client) FetchOption {
+	return func(settings *fetchSettings) {
+		settings.client = client
+	}
+}
+
+// FetchURLAsText downloads an http or https document as text: the HTML pages are converted to markdown
+// (see HTMLToMarkdown), the text, JSON and XML documents are decoded to UTF-8 and kept as is, the other
+// content types are refused with ErrUnsupportedContentType. A response without content type is read as HTML.
+//
+// Example usage:
+//
+//	page, err := helpers.FetchURLAsText(ctx, "https://go.dev/doc/effective_go", helpers.WithFetchTimeout(10*time.Second))
+//	chunks := rag.SplitMarkdownBySections(page.Content)
+func FetchURLAsText(ctx context.Context, documentURL string, options ...FetchOption) (FetchedText, error) {
+	settings := &fetchSettings{
+		client:    http.DefaultClient,
+		timeout:   30 * time.Second,
+		maxBytes:  2 << 20,
+		userAgent: "micro-agent-go (+https://github.com/micro-agent/micro-agent-go)",
+	}
+	for _, option := range options {
+		option(settings)
+	}
+
+	parsed, err := url.Parse(strings.TrimSpace(documentURL))
+	if err != nil {
+		return FetchedText{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return FetchedText{}, fmt.Errorf("unsupported URL scheme %q (http or https)", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return FetchedText{}, fmt.Errorf("invalid URL %q: no host", documentURL)
+	}
+	if settings.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, settings.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, parsed.String(), nil)
+	if err != nil {
+		return FetchedText{}, err
+	}
+	req.Header.Set("User-Agent", settings.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,application/json;q=0.8,*/*;q=0.5")
+	resp, err := settings.client.Do(req)
+	if err != nil {
+		return FetchedText{}, fmt.Errorf("unable to fetch %s: %w", parsed, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return FetchedText{}, fmt.Errorf("unable to fetch %s: %s", parsed, resp.Status)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "" {
+		mediaType = "text/html"
+	}
+	if !textMediaType(mediaType) {
+		return FetchedText{}, fmt.Errorf("%w %s", ErrUnsupportedContentType, mediaType)
+	}
+	body := io.Reader(resp.Body)
+	if settings.maxBytes > 0 {
+		// One more byte tells if the document is truncated
+		body = io.LimitReader(body, settings.maxBytes+1)
+	}
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		return FetchedText{}, fmt.Errorf("unable to read %s: %w", parsed, err)
+	}
+	document := FetchedText{URL: resp.Request.URL.String(), ContentType: mediaType}
+	if settings.maxBytes > 0 && int64(len(raw)) > settings.maxBytes {
+		raw, document.Truncated = raw[:settings.maxBytes], true
+	}
+	// The documents are converted to UTF-8 from the charset of the header or of the <meta> element
+	decoder, err := charset.NewReader(bytes.NewReader(raw), contentType)
+	if err != nil {
+		return FetchedText{}, fmt.Errorf("unable to decode %s: %w", parsed, err)
+	}
+	data, err := io.ReadAll(decoder)
+	if err != nil {
+		return FetchedText{}, fmt.Errorf("unable to decode %s: %w", parsed, err)
+	}
+
+	document.Content = string(data)
+	if mediaType == "text/html" || mediaType == "application/xhtml+xml" {
+		document.Title, document.Content, err = HTMLToMarkdown(document.Content, document.URL)
+		if err != nil {
+			return FetchedText{}, err
+		}
+	}
+	document.Content = strings.TrimSpace(document.Content)
+	return document, nil
+}
+
+// textMediaType reports if a media type is read as text
+func textMediaType(mediaType string) bool {
+	return strings.HasPrefix(mediaType, "text/") || mediaType == "application/xhtml+xml" ||
+		mediaType == "application/json" || mediaType == "application/xml" ||
+		strings.HasSuffix(mediaType, "+json") || strings.HasSuffix(mediaType, "+xml")
+}
@@ -0,0 +1,224 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ContentFile is a file found by FindContentFiles with its content, the path is kept for provenance
+// (e.g. to cite the source of a RAG chunk)
+type ContentFile struct {
+	// Path is the path of the file (joined to the root directory)
+	Path string
+	// RelPath is the path relative to the root directory, with slashes
+	RelPath string
+	Content string
+	Size    int64
+	ModTime time.Time
+}
+
+type contentFilesConfig struct {
+	extensions     map[string]bool
+	includeGlobs   []string
+	excludeGlobs   []string
+	maxFileSize    int64
+	followSymlinks bool
+}
+
+// ContentFilesOption configures FindContentFiles
+type ContentFilesOption func(*contentFilesConfig)
+
+// WithExtensions keeps the files with one of the extensions (".md" or "md", case insensitive), all the files by default
+func WithExtensions(extensions ...string) ContentFilesOption {
+	return func(config *contentFilesConfig) {
+		for _, extension := range extensions {
+			if extension == "" || extension == ".*" {
+				continue
+			}
+			config.extensions["."+strings.TrimPrefix(strings.ToLower(extension), ".")] = true
+		}
+	}
+}
+
+// WithIncludeGlobs keeps the files matching one of the globs (see WithExcludeGlobs for the syntax)
+func WithIncludeGlobs(globs ...string) ContentFilesOption {
+	return func(config *contentFilesConfig) {
+		config.includeGlobs = append(config.includeGlobs, globs...)
+	}
+}
+
+// WithExcludeGlobs skips the files and the directories matching one of the globs. A glob with a slash is
+// matched against the path relative to the root ("docs/drafts/*.md", "**" matches any number of directories:
+// "**/testdata/**"), a glob without slash against the name ("*.tmp", "node_modules", ".git").
+func WithExcludeGlobs(globs ...string) ContentFilesOption {
+	return func(config *contentFilesConfig) {
+		config.excludeGlobs = append(config.excludeGlobs, globs...)
+	}
+}
+
+// WithMaxFileSize skips the files larger than maxBytes (no limit if 0)
+func WithMaxFileSize(maxBytes int64) ContentFilesOption {
+	return func(config *contentFilesConfig) {
+		config.maxFileSize = maxBytes
+	}
+}
+
+// WithFollowSymlinks follows the symbolic links to files and directories (each directory is walked once,
+// so the link loops are harmless), the symbolic links are skipped by default
+func WithFollowSymlinks(follow bool) ContentFilesOption {
+	return func(config *contentFilesConfig) {
+		config.followSymlinks = follow
+	}
+}
+
+// FindContentFiles walks a directory and its subdirectories and reads the files kept by the options,
+// in lexical order of their paths.
+//
+// Example usage:
+//
+//	files, err := helpers.FindContentFiles("./docs",
+//	  helpers.WithExtensions(".md", ".txt"),
+//	  helpers.WithExcludeGlobs(".git", "node_modules", "**/drafts/**"),
+//	  helpers.WithMaxFileSize(1<<20),
+//	)
+//	for _, file := range files {
+//	  chunks := rag.SplitMarkdownBySections(file.Content) // cite file.RelPath
+//	}
+func FindContentFiles(root string, options ...ContentFilesOption) ([]ContentFile, error) {
+	config := &contentFilesConfig{extensions: map[string]bool{}}
+	for _, option := range options {
+		option(config)
+	}
+	for _, glob := range append(append([]string{}, config.includeGlobs...), config.excludeGlobs...) {
+		if _, err := path.Match(strings.ReplaceAll(glob, "**", "*"), ""); err != nil {
+			return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+		}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", root)
+	}
+	walker := &contentFilesWalker{config: config, visited: map[string]bool{}, files: []ContentFile{}}
+	if err := walker.walk(root, ""); err != nil {
+		return nil, err
+	}
+	return walker.files, nil
+}
+
+type contentFilesWalker struct {
+	config  *contentFilesConfig
+	visited map[string]bool // the real paths of the walked directories
+	files   []ContentFile
+}
+
+// walk reads the kept files of a directory and walks its subdirectories
+func (walker *contentFilesWalker) walk(dir, relDir string) error {
+	if realDir, err := filepath.EvalSymlinks(dir); err == nil {
+		if walker.visited[realDir] {
+			return nil
+		}
+		walker.visited[realDir] = true
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		filePath, relPath := filepath.Join(dir, entry.Name()), path.Join(relDir, entry.Name())
+		if walker.matches(walker.config.excludeGlobs, relPath) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			if !walker.config.followSymlinks {
+				continue
+			}
+			if info, err = os.Stat(filePath); err != nil {
+				// A broken link is skipped
+				continue
+			}
+		}
+		if info.IsDir() {
+			if err := walker.walk(filePath, relPath); err != nil {
+				return err
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() || !walker.keeps(relPath, info) {
+			continue
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		walker.files = append(walker.files, ContentFile{
+			Path:    filePath,
+			RelPath: relPath,
+			Content: string(data),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	return nil
+}
+
+// keeps reports whether a file is kept by the extensions, the include globs and the size limit
+func (walker *contentFilesWalker) keeps(relPath string, info os.FileInfo) bool {
+	config := walker.config
+	if len(config.extensions) > 0 && !config.extensions[strings.ToLower(path.Ext(relPath))] {
+		return false
+	}
+	if len(config.includeGlobs) > 0 && !walker.matches(config.includeGlobs, relPath) {
+		return false
+	}
+	return config.maxFileSize <= 0 || info.Size() <= config.maxFileSize
+}
+
+// matches reports whether a relative path matches one of the globs
+func (walker *contentFilesWalker) matches(globs []string, relPath string) bool {
+	for _, glob := range globs {
+		if !strings.Contains(glob, "/") {
+			if matched, _ := path.Match(glob, path.Base(relPath)); matched {
+				return true
+			}
+			continue
+		}
+		if matchGlobSegments(strings.Split(strings.Trim(glob, "/"), "/"), strings.Split(relPath, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlobSegments matches path segments against glob segments, "**" matching zero or more segments
+func matchGlobSegments(globs, segments []string) bool {
+	for len(globs) > 0 {
+		if globs[0] == "**" {
+			for skipped := 0; skipped <= len(segments); skipped++ {
+				if matchGlobSegments(globs[1:], segments[skipped:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(globs[0], segments[0]); !matched {
+			return false
+		}
+		globs, segments = globs[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
@@ -84,6 +84,7 @@ The function returns a slice of file paths that match the given extension and an
 
 
 // GetContentFiles searches for files with a specific extension in the given directory and its subdirectories.
+// Use FindContentFiles to filter the files by globs or size and to get the paths with the contents.
 //
 // Parameters:
 // - dirPath: The directory path to start the search from.
@@ -113,6 +114,7 @@ func GetContentFiles(dirPath string, ext string) ([]string, error) {
 
 
 // ReadTextFile reads the contents of a text file at the given path and returns the contents as a string.
+// The whole file is loaded in memory, use ReadFileChunks to iterate over the blocks of a large file.
 //
 // Parameters:
 // - path: the path to the text file.
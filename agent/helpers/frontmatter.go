@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseFrontmatter splits a markdown document into the metadata of its YAML frontmatter (the block between
+// the "---" lines at the top of the document) and its body. The dates are returned as strings ("2006-01-02",
+// or RFC 3339 with a time) so the metadata is unchanged by a JSON round trip. A document without frontmatter
+// is returned unchanged with nil metadata; on invalid YAML the error is returned with the unchanged document.
+//
+// Example usage:
+//
+//	metadata, body, err := helpers.ParseFrontmatter("---\ntitle: Deployment\ntags: [kubernetes, helm]\n---\n# Deploy\n...")
+//	// metadata: map[tags:[kubernetes helm] title:Deployment], body: "# Deploy\n..."
+func ParseFrontmatter(content string) (map[string]any, string, error) {
+	text := strings.TrimPrefix(content, "\ufeff")
+	firstLine, rest, found := strings.Cut(text, "\n")
+	if !found || strings.TrimRight(firstLine, " \t\r") != "---" {
+		return nil, content, nil
+	}
+
+	// The frontmatter ends at the next "---" (or "...") line
+	offset := 0
+	for offset <= len(rest) {
+		line, _, hasNext := strings.Cut(rest[offset:], "\n")
+		if trimmed := strings.TrimRight(line, " \t\r"); trimmed == "---" || trimmed == "..." {
+			metadata := map[string]any{}
+			if err := yaml.Unmarshal([]byte(rest[:offset]), &metadata); err != nil {
+				return nil, content, fmt.Errorf("invalid frontmatter: %w", err)
+			}
+			body := ""
+			if hasNext {
+				body = rest[offset+len(line)+1:]
+			}
+			return normalizeFrontmatter(metadata).(map[string]any), strings.TrimLeft(body, "\r\n"), nil
+		}
+		if !hasNext {
+			break
+		}
+		offset += len(line) + 1
+	}
+	return nil, content, nil
+}
+
+// normalizeFrontmatter converts the dates to strings and the maps with non-string keys to string maps
+func normalizeFrontmatter(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeFrontmatter(item)
+		}
+		return v
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for key, item := range v {
+			converted[fmt.Sprint(key)] = normalizeFrontmatter(item)
+		}
+		return converted
+	case []any:
+		for idx, item := range v {
+			v[idx] = normalizeFrontmatter(item)
+		}
+		return v
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly)
+		}
+		return v.Format(time.RFC3339)
+	}
+	return value
+}
@@ -1,9 +1,10 @@
 package helpers
 
 import (
-	"fmt"
 	"os"
 	"strconv"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
 )
 
 func GetEnvOrDefault(key, defaultValue string) string {
@@ -16,7 +17,7 @@ func GetEnvOrDefault(key, defaultValue string) string {
 func StringToInt(str string) int {
 	num, err := strconv.Atoi(str)
 	if err != nil {
-		fmt.Println("Cannot convert to int:", err)
+		logging.Default().Warn("cannot convert to int", "value", str, "error", err)
 		return 0
 	}
 	return num
@@ -25,7 +26,7 @@ func StringToInt(str string) int {
 func StringToFloat(str string) float64 {
 	num, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		fmt.Println("Cannot convert to float:", err)
+		logging.Default().Warn("cannot convert to float", "value", str, "error", err)
 		return 0.0
 	}
 	return num
@@ -0,0 +1,381 @@
+package helpers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Placeholders protecting the indentation of the lists and the content of the code blocks
+// from the normalization of the blank characters, replaced at the end of the conversion
+const (
+	mdSpace     = "\x00"
+	mdTab       = "\x01"
+	mdEmptyLine = "\x02"
+)
+
+// HTMLToMarkdown converts an HTML document to markdown for the models: the headings, paragraphs, lists,
+// links, code blocks, quotes and tables are kept, the scripts, styles, forms and hidden elements are removed.
+// Only the <article> or the <main> element is converted when the page has one. The relative links are resolved
+// against baseURL (kept as is if empty). It returns the title of the page and its content.
+//
+// Example usage:
+//
+//	title, markdown, err := helpers.HTMLToMarkdown(page, "https://go.dev/doc/")
+func HTMLToMarkdown(document string, baseURL string) (string, string, error) {
+	root, err := html.Parse(strings.NewReader(document))
+	if err != nil {
+		return "", "", fmt.Errorf("invalid HTML: %w", err)
+	}
+	converter := &markdownConverter{}
+	if baseURL != "" {
+		if converter.base, err = url.Parse(baseURL); err != nil {
+			return "", "", fmt.Errorf("invalid base URL: %w", err)
+		}
+	}
+
+	title := ""
+	if node := findElement(root, atom.Title); node != nil {
+		title = strings.Join(strings.Fields(textContent(node)), " ")
+	}
+	content := findElement(root, atom.Article)
+	if content == nil {
+		content = findElement(root, atom.Main)
+	}
+	if content == nil {
+		content = findElement(root, atom.Body)
+	}
+	if content == nil {
+		content = root
+	}
+	if title == "" {
+		if node := findElement(content, atom.H1); node != nil {
+			title = strings.Join(strings.Fields(textContent(node)), " ")
+		}
+	}
+
+	markdown := normalizeMarkdown(converter.node(content, false))
+	markdown = strings.NewReplacer(mdSpace, " ", mdTab, "\t", mdEmptyLine, "").Replace(markdown)
+	return title, markdown, nil
+}
+
+// markdownConverter converts the nodes of a document
+type markdownConverter struct {
+	base *url.URL
+}
+
+// children converts the children of a node
+func (c *markdownConverter) children(node *html.Node, pre bool) string {
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(c.node(child, pre))
+	}
+	return builder.String()
+}
+
+// node converts a node, pre is true inside a <pre> element
+func (c *markdownConverter) node(node *html.Node, pre bool) string {
+	switch node.Type {
+	case html.TextNode:
+		if pre {
+			return protectCode(node.Data)
+		}
+		return surround(strings.Join(strings.FieldsFunc(node.Data, isHTMLSpace), " "), node.Data)
+	case html.ElementNode:
+	case html.DocumentNode:
+		return c.children(node, pre)
+	default:
+		return ""
+	}
+	if hidden(node) {
+		return ""
+	}
+
+	switch node.DataAtom {
+	case atom.Script, atom.Style, atom.Noscript, atom.Template, atom.Svg, atom.Iframe, atom.Head, atom.Title,
+		atom.Button, atom.Input, atom.Select, atom.Textarea, atom.Object, atom.Canvas:
+		return ""
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		text := inline(c.children(node, pre))
+		if text == "" {
+			return ""
+		}
+		level := int(node.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + text + "\n\n"
+	case atom.Br:
+		return "\n"
+	case atom.Hr:
+		return "\n\n---\n\n"
+	case atom.A:
+		children := c.children(node, pre)
+		text := inline(children)
+		href := c.resolve(attribute(node, "href"))
+		if text == "" || href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+			return children
+		}
+		return surround("["+text+"]("+href+")", children)
+	case atom.Img:
+		alt := inline(attribute(node, "alt"))
+		src := c.resolve(attribute(node, "src"))
+		if alt == "" || src == "" || strings.HasPrefix(src, "data:") {
+			return ""
+		}
+		return "![" + alt + "](" + src + ")"
+	case atom.Strong, atom.B:
+		return emphasis(c.children(node, pre), "**")
+	case atom.Em, atom.I:
+		return emphasis(c.children(node, pre), "_")
+	case atom.Code, atom.Kbd, atom.Samp:
+		if pre {
+			return c.children(node, pre)
+		}
+		return emphasis(c.children(node, pre), "`")
+	case atom.Pre:
+		code := strings.Trim(c.children(node, true), "\n")
+		return "\n\n```" + codeLanguage(node) + "\n" + code + "\n```\n\n"
+	case atom.Blockquote:
+		lines := strings.Split(normalizeMarkdown(c.children(node, pre)), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight("> "+line, " ")
+		}
+		return "\n\n" + strings.Join(lines, "\n") + "\n\n"
+	case atom.Ul, atom.Ol:
+		return "\n\n" + c.list(node, pre) + "\n\n"
+	case atom.Li:
+		// An item outside a list
+		return "\n\n- " + inline(c.children(node, pre)) + "\n\n"
+	case atom.Table:
+		return "\n\n" + c.table(node) + "\n\n"
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer, atom.Nav, atom.Aside,
+		atom.Figure, atom.Figcaption, atom.Dl, atom.Dt, atom.Dd, atom.Address, atom.Details, atom.Summary, atom.Form,
+		atom.Fieldset, atom.Caption:
+		return "\n\n" + c.children(node, pre) + "\n\n"
+	}
+	return c.children(node, pre)
+}
+
+// list converts the items of a list, the lines of an item are indented under its marker
+func (c *markdownConverter) list(node *html.Node, pre bool) string {
+	ordered := node.DataAtom == atom.Ol
+	items := []string{}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.DataAtom != atom.Li || hidden(child) {
+			continue
+		}
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", len(items)+1)
+		}
+		lines := strings.Split(normalizeMarkdown(c.children(child, pre)), "\n")
+		indent := strings.Repeat(mdSpace, len(marker))
+		for i := range lines {
+			if i == 0 {
+				lines[i] = marker + lines[i]
+			} else if lines[i] != "" {
+				lines[i] = indent + lines[i]
+			}
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+// table converts a table, its first row is the header
+func (c *markdownConverter) table(node *html.Node) string {
+	rows := [][]string{}
+	var walk func(node *html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.DataAtom {
+			case atom.Thead, atom.Tbody, atom.Tfoot:
+				walk(child)
+			case atom.Tr:
+				cells := []string{}
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.DataAtom == atom.Td || cell.DataAtom == atom.Th) {
+						text := inline(strings.NewReplacer(mdSpace, " ", mdTab, " ", mdEmptyLine, " ").Replace(c.children(cell, false)))
+						cells = append(cells, strings.ReplaceAll(text, "|", `\|`))
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			}
+		}
+	}
+	walk(node)
+	if len(rows) == 0 {
+		return ""
+	}
+	columns := 0
+	for _, row := range rows {
+		columns = max(columns, len(row))
+	}
+	lines := []string{}
+	for i, row := range rows {
+		for len(row) < columns {
+			row = append(row, "")
+		}
+		lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolve returns the absolute URL of a link
+func (c *markdownConverter) resolve(link string) string {
+	link = strings.TrimSpace(link)
+	if link == "" || c.base == nil {
+		return link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return link
+	}
+	return c.base.ResolveReference(parsed).String()
+}
+
+// normalizeMarkdown trims the lines and keeps at most one blank line between the blocks
+func normalizeMarkdown(markdown string) string {
+	lines := []string{}
+	blank := true
+	for _, line := range strings.Split(markdown, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// protectCode protects the blank characters of a code block from the normalization
+func protectCode(code string) string {
+	lines := strings.Split(strings.NewReplacer(" ", mdSpace, "\t", mdTab, "\r", "").Replace(code), "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = mdEmptyLine
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline returns a text on a single line
+func inline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// emphasis surrounds a text with a markdown marker (nothing if the text is empty)
+func emphasis(text string, marker string) string {
+	trimmed := inline(text)
+	if trimmed == "" {
+		return text
+	}
+	return surround(marker+trimmed+marker, text)
+}
+
+// surround keeps the spaces around an original text (a single space for a blank text)
+func surround(converted string, original string) string {
+	if original == "" {
+		return converted
+	}
+	if converted == "" {
+		return " "
+	}
+	if isHTMLSpace(rune(original[0])) {
+		converted = " " + converted
+	}
+	if isHTMLSpace(rune(original[len(original)-1])) {
+		converted += " "
+	}
+	return converted
+}
+
+// isHTMLSpace reports if a character is a blank character in HTML
+func isHTMLSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r' || r == '\f'
+}
+
+// hidden reports if an element is not displayed
+func hidden(node *html.Node) bool {
+	for _, attr := range node.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "hidden":
+			return true
+		case "aria-hidden":
+			if attr.Val == "true" {
+				return true
+			}
+		case "style":
+			style := strings.ReplaceAll(strings.ToLower(attr.Val), " ", "")
+			if strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// codeLanguage returns the language of a code block from its class ("language-go" or "lang-go")
+func codeLanguage(node *html.Node) string {
+	classes := attribute(node, "class")
+	if code := findElement(node, atom.Code); code != nil {
+		classes += " " + attribute(code, "class")
+	}
+	for _, class := range strings.Fields(classes) {
+		for _, prefix := range []string{"language-", "lang-"} {
+			if language, ok := strings.CutPrefix(class, prefix); ok {
+				return language
+			}
+		}
+	}
+	return ""
+}
+
+// attribute returns the value of an attribute of an element
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// findElement returns the first element of a type in a tree (depth-first)
+func findElement(node *html.Node, element atom.Atom) *html.Node {
+	if node.Type == html.ElementNode && node.DataAtom == element {
+		return node
+	}
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if found := findElement(child, element); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// textContent returns the text of a tree
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var builder strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		builder.WriteString(textContent(child))
+	}
+	return builder.String()
+}
@@ -0,0 +1,73 @@
+package helpers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// DefaultMaxImageSize is the size limit of LoadImageFile when none is given (the limit of the OpenAI API)
+const DefaultMaxImageSize = 20 << 20
+
+// ErrUnsupportedImageFormat is returned by LoadImageFile for the files which aren't PNG, JPEG or WebP images
+var ErrUnsupportedImageFormat = errors.New("unsupported image format, expected png, jpeg or webp")
+
+// imageMIMETypes are the supported formats, detected from the content of the files
+var imageMIMETypes = map[string]bool{"image/png": true, "image/jpeg": true, "image/webp": true}
+
+// ImageFile is an image loaded by LoadImageFile
+type ImageFile struct {
+	Path string
+	// MIMEType is image/png, image/jpeg or image/webp
+	MIMEType string
+	Data     []byte
+}
+
+// DataURL returns the image as a base64 data URL ("data:image/png;base64,..."), the form of the images
+// sent inline to the vision models
+func (image ImageFile) DataURL() string {
+	return "data:" + image.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(image.Data)
+}
+
+// LoadImageFile reads a PNG, JPEG or WebP image (the format is detected from the content, not from the
+// extension) and checks its size: maxBytes is the limit, DefaultMaxImageSize if 0.
+//
+// Example usage:
+//
+//	image, err := helpers.LoadImageFile("./screenshot.png", 5<<20)
+//	part := openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: image.DataURL()})
+func LoadImageFile(path string, maxBytes int64) (ImageFile, error) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxImageSize
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ImageFile{}, err
+	}
+	if info.IsDir() {
+		return ImageFile{}, fmt.Errorf("%s is a directory", path)
+	}
+	if info.Size() > maxBytes {
+		return ImageFile{}, fmt.Errorf("the image %s is too large: %d bytes (max %d)", path, info.Size(), maxBytes)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ImageFile{}, err
+	}
+	mimeType := http.DetectContentType(data)
+	if !imageMIMETypes[mimeType] {
+		return ImageFile{}, fmt.Errorf("%s (%s): %w", path, mimeType, ErrUnsupportedImageFormat)
+	}
+	return ImageFile{Path: path, MIMEType: mimeType, Data: data}, nil
+}
+
+// ImageFileToDataURL loads an image with the default size limit and returns its data URL (see LoadImageFile)
+func ImageFileToDataURL(path string) (string, error) {
+	image, err := LoadImageFile(path, 0)
+	if err != nil {
+		return "", err
+	}
+	return image.DataURL(), nil
+}
@@ -0,0 +1,368 @@
+package helpers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ErrNoJSON is returned by ExtractJSON when a text has no JSON object or array
+var ErrNoJSON = errors.New("no JSON object or array found")
+
+// ExtractedJSON is the JSON value found in a text by ExtractJSON
+type ExtractedJSON struct {
+	// JSON is the valid JSON object or array
+	JSON json.RawMessage
+	// Repairs describes the fixes applied to the text to get valid JSON, empty if it was valid
+	Repairs []string
+}
+
+// Unmarshal decodes the extracted JSON into v
+func (extracted ExtractedJSON) Unmarshal(v any) error {
+	return json.Unmarshal(extracted.JSON, v)
+}
+
+// ExtractJSON finds the first JSON object or array of a model reply: the markdown code fences and the text
+// around the JSON are ignored. When the JSON is invalid, the common mistakes of the models are repaired:
+// single or typographic quotes, unquoted keys, trailing commas, comments, Python literals (True, None),
+// raw line breaks in the strings, and a reply truncated by the token limit (the open strings, objects and
+// arrays are closed). The repairs are listed in the result so the caller can decide to trust it or not.
+//
+// Example usage:
+//
+//	extracted, err := helpers.ExtractJSON("Here you are:\n```json\n{'name': 'Bob', 'tags': ['bot',],}\n```")
+//	// extracted.JSON is {"name": "Bob", "tags": ["bot"]}
+//	var person Person
+//	err = extracted.Unmarshal(&person)
+func ExtractJSON(text string) (ExtractedJSON, error) {
+	repairs := []string{}
+	candidate := text
+	if fenced, ok := fencedBlock(text); ok {
+		candidate = fenced
+		repairs = append(repairs, "removed the markdown code fence")
+	}
+
+	// A valid JSON value is used as is
+	for start := 0; start < len(candidate); start++ {
+		if candidate[start] != '{' && candidate[start] != '[' {
+			continue
+		}
+		if end := balancedEnd(candidate, start); end > 0 && json.Valid([]byte(candidate[start:end])) {
+			if start > 0 || strings.TrimSpace(candidate[end:]) != "" {
+				repairs = append(repairs, "removed the text around the JSON")
+			}
+			return ExtractedJSON{JSON: json.RawMessage(candidate[start:end]), Repairs: repairs}, nil
+		}
+	}
+
+	start := strings.IndexAny(candidate, "{[")
+	if start < 0 {
+		return ExtractedJSON{Repairs: repairs}, ErrNoJSON
+	}
+	if start > 0 {
+		repairs = append(repairs, "removed the text around the JSON")
+	}
+	repaired, fixes := repairJSON(candidate[start:])
+	repairs = append(repairs, fixes...)
+	if !json.Valid([]byte(repaired)) {
+		return ExtractedJSON{Repairs: repairs}, fmt.Errorf("unable to repair the JSON: %q", repaired)
+	}
+	return ExtractedJSON{JSON: json.RawMessage(repaired), Repairs: repairs}, nil
+}
+
+// fencedBlock returns the content of the first markdown code block containing an object or an array,
+// until the end of the text when the block isn't closed
+func fencedBlock(text string) (string, bool) {
+	rest := text
+	for {
+		open := strings.Index(rest, "```")
+		if open < 0 {
+			return "", false
+		}
+		rest = rest[open+3:]
+		// The language of the block (json, JSON...)
+		newline := strings.IndexByte(rest, '\n')
+		if newline < 0 {
+			return "", false
+		}
+		body := rest[newline+1:]
+		end := strings.Index(body, "```")
+		if end >= 0 {
+			body = body[:end]
+		}
+		if strings.ContainsAny(body, "{[") {
+			return body, true
+		}
+		if end < 0 {
+			return "", false
+		}
+		rest = rest[newline+1+end+3:]
+	}
+}
+
+// balancedEnd returns the end of the object or array starting at start, or -1 if it isn't closed
+func balancedEnd(text string, start int) int {
+	depth, inString := 0, false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch c {
+			case '\\':
+				i++
+			case '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// jsonRepairer rewrites an almost-JSON text as JSON
+type jsonRepairer struct {
+	input   []rune
+	pos     int
+	out     strings.Builder
+	stack   []rune // the closing brackets of the open objects and arrays
+	fixes   []string
+	noted   map[string]bool
+	keyOpen bool // a key was written without its colon
+}
+
+// repairJSON repairs the object or array at the start of a text, the text after it is ignored
+func repairJSON(text string) (string, []string) {
+	r := &jsonRepairer{input: []rune(text), noted: map[string]bool{}}
+	r.run()
+	return r.out.String(), r.fixes
+}
+
+// note records a repair once
+func (r *jsonRepairer) note(fix string) {
+	if !r.noted[fix] {
+		r.noted[fix] = true
+		r.fixes = append(r.fixes, fix)
+	}
+}
+
+// expectsKey reports if the next token is a key: after the opening brace or a comma of an object
+func (r *jsonRepairer) expectsKey() bool {
+	if len(r.stack) == 0 || r.stack[len(r.stack)-1] != '}' {
+		return false
+	}
+	written := strings.TrimRightFunc(r.out.String(), unicode.IsSpace)
+	return strings.HasSuffix(written, "{") || strings.HasSuffix(written, ",")
+}
+
+// trimTrailingComma removes a comma written before a closing bracket
+func (r *jsonRepairer) trimTrailingComma() {
+	written := strings.TrimRightFunc(r.out.String(), unicode.IsSpace)
+	if strings.HasSuffix(written, ",") {
+		r.out.Reset()
+		r.out.WriteString(strings.TrimSuffix(written, ","))
+		r.note("removed a trailing comma")
+	}
+}
+
+// run writes the repaired object or array, then closes what a truncated reply left open
+func (r *jsonRepairer) run() {
+	for r.pos < len(r.input) {
+		c := r.input[r.pos]
+		switch {
+		case c == '"' || c == '\'' || c == '“' || c == '‘':
+			key := r.expectsKey()
+			r.readString()
+			r.keyOpen = key
+		case c == '{' || c == '[':
+			r.stack = append(r.stack, map[rune]rune{'{': '}', '[': ']'}[c])
+			r.out.WriteRune(c)
+			r.pos++
+		case c == '}' || c == ']':
+			r.pos++
+			if len(r.stack) == 0 {
+				return
+			}
+			r.closeKey()
+			r.trimTrailingComma()
+			closing := r.stack[len(r.stack)-1]
+			r.stack = r.stack[:len(r.stack)-1]
+			if closing != c {
+				r.note("fixed a mismatched bracket")
+			}
+			r.out.WriteRune(closing)
+			if len(r.stack) == 0 {
+				return
+			}
+		case c == ':':
+			r.keyOpen = false
+			r.out.WriteRune(c)
+			r.pos++
+		case c == '/' && r.pos+1 < len(r.input) && (r.input[r.pos+1] == '/' || r.input[r.pos+1] == '*'):
+			r.skipComment()
+		case c == '-' || (c >= '0' && c <= '9'):
+			r.readNumber()
+		case unicode.IsLetter(c) || c == '_' || c == '$':
+			r.readWord()
+		default:
+			r.out.WriteRune(c)
+			r.pos++
+		}
+	}
+
+	// Truncated reply: the open key, object and arrays are closed
+	written := strings.TrimRightFunc(r.out.String(), unicode.IsSpace)
+	r.out.Reset()
+	r.out.WriteString(written)
+	switch {
+	case strings.HasSuffix(written, ":"):
+		r.out.WriteString("null")
+		r.note("completed a truncated value")
+	case strings.HasSuffix(written, "-") || strings.HasSuffix(written, "."):
+		r.out.WriteString("0")
+		r.note("completed a truncated number")
+	}
+	r.closeKey()
+	r.trimTrailingComma()
+	if len(r.stack) > 0 {
+		r.note("closed the objects and arrays of a truncated reply")
+	}
+	for i := len(r.stack) - 1; i >= 0; i-- {
+		r.out.WriteRune(r.stack[i])
+	}
+}
+
+// closeKey gives a null value to a key written without its value
+func (r *jsonRepairer) closeKey() {
+	if r.keyOpen {
+		r.out.WriteString(": null")
+		r.keyOpen = false
+		r.note("completed a truncated value")
+	}
+}
+
+// readString writes a string delimited by double, single or typographic quotes as a JSON string
+func (r *jsonRepairer) readString() {
+	open := r.input[r.pos]
+	closing := map[rune]rune{'"': '"', '\'': '\'', '“': '”', '‘': '’'}[open]
+	if open != '"' {
+		r.note("replaced the single or typographic quotes by double quotes")
+	}
+	r.out.WriteByte('"')
+	r.pos++
+	for r.pos < len(r.input) {
+		c := r.input[r.pos]
+		r.pos++
+		switch {
+		case c == closing:
+			r.out.WriteByte('"')
+			return
+		case c == '\\' && r.pos < len(r.input):
+			escaped := r.input[r.pos]
+			r.pos++
+			if escaped == '\'' {
+				// \' isn't a JSON escape
+				r.out.WriteRune('\'')
+			} else {
+				r.out.WriteRune('\\')
+				r.out.WriteRune(escaped)
+			}
+		case c == '"':
+			r.out.WriteString(`\"`)
+		case c == '\n':
+			r.out.WriteString(`\n`)
+			r.note("escaped the line breaks of the strings")
+		case c == '\r':
+			r.out.WriteString(`\r`)
+			r.note("escaped the line breaks of the strings")
+		case c == '\t':
+			r.out.WriteString(`\t`)
+			r.note("escaped the line breaks of the strings")
+		default:
+			r.out.WriteRune(c)
+		}
+	}
+	// The reply ends in the string
+	r.out.WriteByte('"')
+	r.note("closed a truncated string")
+}
+
+// skipComment skips a // or /* */ comment
+func (r *jsonRepairer) skipComment() {
+	r.note("removed the comments")
+	if r.input[r.pos+1] == '/' {
+		for r.pos < len(r.input) && r.input[r.pos] != '\n' {
+			r.pos++
+		}
+		return
+	}
+	r.pos += 2
+	for r.pos < len(r.input) && !(r.input[r.pos] == '*' && r.pos+1 < len(r.input) && r.input[r.pos+1] == '/') {
+		r.pos++
+	}
+	r.pos += 2
+}
+
+// readNumber writes a number as is
+func (r *jsonRepairer) readNumber() {
+	for r.pos < len(r.input) && strings.ContainsRune("0123456789.eE+-", r.input[r.pos]) {
+		r.out.WriteRune(r.input[r.pos])
+		r.pos++
+	}
+}
+
+// readWord writes an unquoted word: a key is quoted, the Python and JavaScript literals are converted
+func (r *jsonRepairer) readWord() {
+	start := r.pos
+	for r.pos < len(r.input) && (unicode.IsLetter(r.input[r.pos]) || unicode.IsDigit(r.input[r.pos]) ||
+		r.input[r.pos] == '_' || r.input[r.pos] == '$' || r.input[r.pos] == '-') {
+		r.pos++
+	}
+	word := string(r.input[start:r.pos])
+	if r.expectsKey() {
+		r.out.WriteString(`"` + word + `"`)
+		r.keyOpen = true
+		r.note("quoted the keys")
+		return
+	}
+	switch word {
+	case "true", "false", "null":
+		r.out.WriteString(word)
+	case "True", "TRUE":
+		r.out.WriteString("true")
+		r.note("converted the Python or JavaScript literals")
+	case "False", "FALSE":
+		r.out.WriteString("false")
+		r.note("converted the Python or JavaScript literals")
+	case "None", "undefined", "NaN", "Infinity":
+		r.out.WriteString("null")
+		r.note("converted the Python or JavaScript literals")
+	default:
+		// A truncated literal (tr, fals...) or a bare word
+		switch {
+		case r.pos == len(r.input) && strings.HasPrefix("true", word):
+			r.out.WriteString("true")
+			r.note("completed a truncated value")
+		case r.pos == len(r.input) && strings.HasPrefix("false", word):
+			r.out.WriteString("false")
+			r.note("completed a truncated value")
+		case r.pos == len(r.input) && strings.HasPrefix("null", word):
+			r.out.WriteString("null")
+			r.note("completed a truncated value")
+		default:
+			r.out.WriteString(`"` + word + `"`)
+			r.note("quoted the bare words")
+		}
+	}
+}
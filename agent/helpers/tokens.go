@@ -0,0 +1,150 @@
+package helpers
+
+import (
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Tokenizer counts the exact tokens of a text, e.g. a BPE tokenizer registered with RegisterTokenizer
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenizerFunc adapts a function to the Tokenizer interface
+type TokenizerFunc func(text string) int
+
+// CountTokens calls the function
+func (f TokenizerFunc) CountTokens(text string) int {
+	return f(text)
+}
+
+// tokenHeuristic describes the tokenizer of a model family
+type tokenHeuristic struct {
+	// charsPerToken is the mean number of characters of a token of English text or code
+	charsPerToken float64
+	// tokensPerIdeograph is the mean number of tokens of a Chinese, Japanese or Korean character
+	tokensPerIdeograph float64
+}
+
+// defaultTokenHeuristic is used for the unknown models
+var defaultTokenHeuristic = tokenHeuristic{charsPerToken: 4.0, tokensPerIdeograph: 1.0}
+
+// tokenHeuristics gives the heuristics of well-known model families, matched by substring
+// (the longest match wins, e.g. "llama3" before "llama"): the models with a large vocabulary
+// (o200k for the recent OpenAI models, 128k-150k for Llama 3 and Qwen) need fewer tokens
+var tokenHeuristics = map[string]tokenHeuristic{
+	"gpt-4o":   {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"gpt-4.1":  {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"gpt-5":    {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"o1":       {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"o3":       {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"o4-mini":  {charsPerToken: 4.2, tokensPerIdeograph: 0.8},
+	"gpt-4":    {charsPerToken: 4.0, tokensPerIdeograph: 1.1},
+	"gpt-3.5":  {charsPerToken: 4.0, tokensPerIdeograph: 1.1},
+	"claude":   {charsPerToken: 3.5, tokensPerIdeograph: 1.2},
+	"gemini":   {charsPerToken: 4.0, tokensPerIdeograph: 0.8},
+	"gemma":    {charsPerToken: 4.0, tokensPerIdeograph: 0.8},
+	"llama3":   {charsPerToken: 4.1, tokensPerIdeograph: 1.0},
+	"llama-3":  {charsPerToken: 4.1, tokensPerIdeograph: 1.0},
+	"llama":    {charsPerToken: 3.5, tokensPerIdeograph: 1.5},
+	"mistral":  {charsPerToken: 3.6, tokensPerIdeograph: 1.4},
+	"mixtral":  {charsPerToken: 3.5, tokensPerIdeograph: 1.5},
+	"qwen":     {charsPerToken: 4.0, tokensPerIdeograph: 0.7},
+	"deepseek": {charsPerToken: 3.8, tokensPerIdeograph: 0.7},
+	"phi":      {charsPerToken: 3.6, tokensPerIdeograph: 1.3},
+	"smollm":   {charsPerToken: 3.7, tokensPerIdeograph: 1.5},
+}
+
+var (
+	tokenizersMutex sync.RWMutex
+	tokenizers      = map[string]Tokenizer{}
+)
+
+// RegisterTokenizer registers the exact tokenizer of the models whose name contains family (the longest
+// match wins, an empty family matches all the models, a nil tokenizer unregisters the family):
+// EstimateTokens then counts their tokens with it instead of the heuristics. It keeps the heavyweight
+// tokenizers (BPE vocabularies) out of the dependencies of the applications without them.
+//
+// Example usage:
+//
+//	encoding, _ := tiktoken.GetEncoding("o200k_base")
+//	helpers.RegisterTokenizer("gpt-4o", helpers.TokenizerFunc(func(text string) int {
+//	  return len(encoding.Encode(text, nil, nil))
+//	}))
+func RegisterTokenizer(family string, tokenizer Tokenizer) {
+	tokenizersMutex.Lock()
+	defer tokenizersMutex.Unlock()
+	family = strings.ToLower(family)
+	if tokenizer == nil {
+		delete(tokenizers, family)
+		return
+	}
+	tokenizers[family] = tokenizer
+}
+
+// EstimateTokens estimates the number of tokens of a text for a model: with the tokenizer registered for
+// the model if any, else with the character and word heuristics of the model family. The ideographs of
+// Chinese, Japanese and Korean are counted apart, and a text is never estimated below one token per word.
+// The model can be empty (4 characters per token).
+//
+// Example usage:
+//
+//	if helpers.EstimateTokens(chunk, "ai/qwen2.5:1.5B-F16") > 512 {
+//	  chunks = rag.ChunkText(chunk, 1024, 128)
+//	}
+func EstimateTokens(text string, model string) int {
+	if text == "" {
+		return 0
+	}
+	model = strings.ToLower(model)
+	if tokenizer := registeredTokenizer(model); tokenizer != nil {
+		return tokenizer.CountTokens(text)
+	}
+	heuristic := defaultTokenHeuristic
+	bestMatch := ""
+	for family, familyHeuristic := range tokenHeuristics {
+		if strings.Contains(model, family) && len(family) > len(bestMatch) {
+			bestMatch, heuristic = family, familyHeuristic
+		}
+	}
+
+	// The other non-ASCII characters (accents, Cyrillic, emojis...) weigh as 2 ASCII characters
+	characters, ideographs, words := 0.0, 0.0, 0
+	inWord := false
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			ideographs++
+			inWord = false
+			continue
+		case r <= unicode.MaxASCII:
+			characters++
+		default:
+			characters += 2
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if !inWord {
+				words++
+			}
+			inWord = true
+		} else {
+			inWord = false
+		}
+	}
+	estimate := int(characters/heuristic.charsPerToken + ideographs*heuristic.tokensPerIdeograph + 0.5)
+	return max(estimate, words, 1)
+}
+
+// registeredTokenizer returns the registered tokenizer of a model, nil if none
+func registeredTokenizer(model string) Tokenizer {
+	tokenizersMutex.RLock()
+	defer tokenizersMutex.RUnlock()
+	tokenizer, bestMatch := tokenizers[""], ""
+	for family, familyTokenizer := range tokenizers {
+		if strings.Contains(model, family) && len(family) > len(bestMatch) {
+			bestMatch, tokenizer = family, familyTokenizer
+		}
+	}
+	return tokenizer
+}
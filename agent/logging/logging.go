@@ -0,0 +1,51 @@
+// Package logging is the logging of the library: the agents, the tools and the servers log through a Logger
+// given with their WithLogger option, or through the default logger (slog.Default() unless SetDefault is called).
+package logging
+
+import (
+	"log/slog"
+	"sync"
+)
+
+// Logger is the logging interface of the library, *slog.Logger and *ui.Logger implement it
+type Logger interface {
+	Debug(msg string, args ...any)
+	Info(msg string, args ...any)
+	Warn(msg string, args ...any)
+	Error(msg string, args ...any)
+}
+
+var (
+	defaultLogger      Logger
+	defaultLoggerMutex sync.RWMutex
+)
+
+// Default returns the logger used when none is given (slog.Default() unless SetDefault has been called)
+func Default() Logger {
+	defaultLoggerMutex.RLock()
+	defer defaultLoggerMutex.RUnlock()
+	if defaultLogger == nil {
+		return slog.Default()
+	}
+	return defaultLogger
+}
+
+// SetDefault sets the logger used when none is given, nil restores slog.Default()
+func SetDefault(logger Logger) {
+	defaultLoggerMutex.Lock()
+	defer defaultLoggerMutex.Unlock()
+	defaultLogger = logger
+}
+
+// Discard returns a logger which writes nothing
+func Discard() Logger {
+	return slog.New(slog.DiscardHandler)
+}
+
+// OrDefault returns the logger, or the default logger if it is nil
+func OrDefault(logger Logger) Logger {
+	if logger == nil {
+		return Default()
+	}
+	return logger
+}
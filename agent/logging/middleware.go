@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// maxLoggedString is the length above which the strings of the logged payloads are truncated
+const maxLoggedString = 500
+
+// levelEnabler is implemented by the loggers telling if a level is enabled (*slog.Logger, *ui.Logger)
+type levelEnabler interface {
+	Enabled(ctx context.Context, level slog.Level) bool
+}
+
+// Middleware returns a middleware of the OpenAI client logging the requests and their responses at the debug level,
+// with the secrets masked (the streamed responses are logged without their body)
+//
+// Example usage:
+//
+//	client := openai.NewClient(option.WithMiddleware(logging.Middleware(logger)))
+func Middleware(logger Logger) option.Middleware {
+	logger = OrDefault(logger)
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		// Nothing to read nor redact if the debug logs are discarded
+		if enabler, ok := logger.(levelEnabler); ok && !enabler.Enabled(req.Context(), slog.LevelDebug) {
+			return next(req)
+		}
+		var body []byte
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			body = data
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		logger.Debug("request", "method", req.Method, "url", req.URL.String(),
+			"headers", RedactHeaders(req.Header), "body", RedactJSON(body, maxLoggedString))
+
+		start := time.Now()
+		resp, err := next(req)
+		duration := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			logger.Debug("request failed", "method", req.Method, "url", req.URL.String(), "duration", duration, "error", err)
+			return resp, err
+		}
+		if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+			logger.Debug("response", "status", resp.StatusCode, "duration", duration, "stream", true)
+			return resp, nil
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		logger.Debug("response", "status", resp.StatusCode, "duration", duration, "body", RedactJSON(data, maxLoggedString))
+		return resp, nil
+	}
+}
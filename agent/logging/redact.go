@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// sensitiveKeys are the JSON keys and headers masked in the logs
+var sensitiveKeys = map[string]bool{
+	"authorization": true,
+	"api-key":       true,
+	"x-api-key":     true,
+	"api_key":       true,
+	"apikey":        true,
+	"password":      true,
+	"secret":        true,
+	"access_token":  true,
+	"refresh_token": true,
+	"token":         true,
+}
+
+// IsSensitiveKey returns true if the values of the JSON key or header are masked in the logs
+func IsSensitiveKey(name string) bool {
+	return sensitiveKeys[strings.ToLower(name)]
+}
+
+// RedactJSON masks the sensitive values of a JSON payload and truncates its strings longer than maxString
+// (and its long arrays of numbers, e.g. the embedding vectors). A payload which isn't JSON is only truncated.
+func RedactJSON(data []byte, maxString int) string {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return Truncate(string(data), maxString)
+	}
+	redacted, _ := json.Marshal(redactValue(value, maxString))
+	return string(redacted)
+}
+
+func redactValue(value any, maxString int) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if IsSensitiveKey(key) {
+				v[key] = "***"
+			} else {
+				v[key] = redactValue(item, maxString)
+			}
+		}
+		return v
+	case []any:
+		if len(v) > 20 {
+			if _, isNumber := v[0].(float64); isNumber {
+				return fmt.Sprintf("[%d numbers]", len(v))
+			}
+		}
+		for idx, item := range v {
+			v[idx] = redactValue(item, maxString)
+		}
+		return v
+	case string:
+		return Truncate(v, maxString)
+	}
+	return value
+}
+
+// RedactHeaders returns the headers with their sensitive values masked, sorted by name
+// (the X-Stainless-* headers of the OpenAI SDK are skipped)
+func RedactHeaders(header http.Header) []string {
+	names := []string{}
+	for name := range header {
+		if !strings.HasPrefix(name, "X-Stainless-") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		value := header.Get(name)
+		if IsSensitiveKey(name) {
+			value = "***"
+		}
+		lines = append(lines, name+": "+value)
+	}
+	return lines
+}
+
+// Truncate shortens a text to max characters (no limit if max <= 0)
+func Truncate(text string, max int) string {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + fmt.Sprintf("…(%d chars)", len(runes))
+}
@@ -0,0 +1,250 @@
+// Package memory gives memories to the agents: a blackboard shared by the agents of a team,
+// and a long-term memory of the facts learned across the conversations.
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// Names of the blackboard tools
+const (
+	ToolMemoryGet    = "memory_get"
+	ToolMemorySet    = "memory_set"
+	ToolMemorySearch = "memory_search"
+)
+
+// Entry is a value of the blackboard
+type Entry struct {
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	Author    string    `json:"author,omitempty"` // name of the agent which wrote the value
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BlackboardOption is a functional option for configuring Blackboard instances
+type BlackboardOption func(*Blackboard)
+
+// Blackboard is a thread-safe key-value store shared by several agents, through Go or through its tools
+// (memory_get, memory_set, memory_search). It is optionally persisted to a JSON file.
+type Blackboard struct {
+	mutex   sync.RWMutex
+	entries map[string]Entry
+	path    string
+}
+
+// NewBlackboard creates a blackboard, loading its persistence file if it exists
+//
+// Example usage:
+//
+//	board, err := memory.NewBlackboard(memory.WithPersistence("team.json"))
+//	// The agents share the board through their tools
+//	params.Tools = board.OpenAITools()
+//	agent.DetectToolCalls(messages, board.ToolCallback("writer"))
+func NewBlackboard(options ...BlackboardOption) (*Blackboard, error) {
+	board := &Blackboard{entries: map[string]Entry{}}
+	for _, option := range options {
+		option(board)
+	}
+	if board.path != "" {
+		data, err := os.ReadFile(board.path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			if err := json.Unmarshal(data, &board.entries); err != nil {
+				return nil, fmt.Errorf("invalid blackboard file %s: %w", board.path, err)
+			}
+		}
+	}
+	return board, nil
+}
+
+// WithPersistence is a functional option that persists the blackboard to a JSON file after each change
+func WithPersistence(path string) BlackboardOption {
+	return func(board *Blackboard) {
+		board.path = path
+	}
+}
+
+// Get returns the entry of a key
+func (board *Blackboard) Get(key string) (Entry, bool) {
+	board.mutex.RLock()
+	defer board.mutex.RUnlock()
+	entry, ok := board.entries[key]
+	return entry, ok
+}
+
+// Set writes the value of a key, author is the name of the writer (optional)
+func (board *Blackboard) Set(key, value, author string) error {
+	if key == "" {
+		return errors.New("the key is empty")
+	}
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+	board.entries[key] = Entry{Key: key, Value: value, Author: author, UpdatedAt: time.Now()}
+	return board.persist()
+}
+
+// Delete removes a key
+func (board *Blackboard) Delete(key string) error {
+	board.mutex.Lock()
+	defer board.mutex.Unlock()
+	delete(board.entries, key)
+	return board.persist()
+}
+
+// Entries returns all the entries sorted by key
+func (board *Blackboard) Entries() []Entry {
+	board.mutex.RLock()
+	defer board.mutex.RUnlock()
+	entries := make([]Entry, 0, len(board.entries))
+	for _, entry := range board.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+// Search returns the entries containing the most words of the query (in their key or value), at most limit entries
+func (board *Blackboard) Search(query string, limit int) []Entry {
+	terms := strings.Fields(strings.ToLower(query))
+	type scoredEntry struct {
+		entry Entry
+		score int
+	}
+	scored := []scoredEntry{}
+	for _, entry := range board.Entries() {
+		text := strings.ToLower(entry.Key + " " + entry.Value)
+		score := 0
+		for _, term := range terms {
+			if strings.Contains(text, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			scored = append(scored, scoredEntry{entry, score})
+		}
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+
+	results := []Entry{}
+	for _, item := range scored {
+		if limit > 0 && len(results) >= limit {
+			break
+		}
+		results = append(results, item.entry)
+	}
+	return results
+}
+
+// persist writes the entries to the persistence file (the lock is held by the caller)
+func (board *Blackboard) persist() error {
+	if board.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(board.entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(board.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	// Write then rename, so a crash never leaves a truncated file
+	tmpPath := board.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, board.path)
+}
+
+// OpenAITools returns the definitions of the blackboard tools
+func (board *Blackboard) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	stringProperty := func(description string) map[string]any {
+		return map[string]any{"type": "string", "description": description}
+	}
+	tool := func(name, description string, properties map[string]any, required ...string) openai.ChatCompletionToolUnionParam {
+		return openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        name,
+			Description: openai.String(description),
+			Parameters: shared.FunctionParameters{
+				"type":       "object",
+				"properties": properties,
+				"required":   required,
+			},
+		})
+	}
+	return []openai.ChatCompletionToolUnionParam{
+		tool(ToolMemoryGet, "Read a value of the memory shared with the other agents",
+			map[string]any{"key": stringProperty("key of the value")}, "key"),
+		tool(ToolMemorySet, "Write a value to the memory shared with the other agents",
+			map[string]any{"key": stringProperty("key of the value"), "value": stringProperty("the value")}, "key", "value"),
+		tool(ToolMemorySearch, "Search the memory shared with the other agents by words",
+			map[string]any{"query": stringProperty("words to search")}, "query"),
+	}
+}
+
+// IsTool returns true if the function is a blackboard tool
+func (board *Blackboard) IsTool(functionName string) bool {
+	return functionName == ToolMemoryGet || functionName == ToolMemorySet || functionName == ToolMemorySearch
+}
+
+// CallTool executes a blackboard tool for an agent (the author of the writes) and returns its JSON result
+func (board *Blackboard) CallTool(author, functionName, arguments string) (string, error) {
+	var args struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+
+	var result any
+	switch functionName {
+	case ToolMemoryGet:
+		entry, ok := board.Get(args.Key)
+		if !ok {
+			result = map[string]any{"found": false}
+		} else {
+			result = map[string]any{"found": true, "value": entry.Value, "author": entry.Author}
+		}
+	case ToolMemorySet:
+		if err := board.Set(args.Key, args.Value, author); err != nil {
+			return "", err
+		}
+		result = map[string]any{"saved": true}
+	case ToolMemorySearch:
+		result = map[string]any{"entries": board.Search(args.Query, 10)}
+	default:
+		return "", fmt.Errorf("unknown blackboard tool %s", functionName)
+	}
+	data, err := json.Marshal(result)
+	return string(data), err
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls executing the blackboard tools for an agent,
+// the other tools are executed by next (an error if nil)
+func (board *Blackboard) ToolCallback(author string, next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if board.IsTool(functionName) {
+			return board.CallTool(author, functionName, arguments)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
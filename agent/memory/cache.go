@@ -0,0 +1,303 @@
+package memory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
+
+	"github.com/google/uuid"
+)
+
+// CacheOption is a functional option for configuring SemanticCache instances
+type CacheOption func(*SemanticCache)
+
+// SemanticCache returns the answers previously generated for similar prompts: the prompts are embedded
+// and stored in a vector store by scope (e.g. an agent or a model), a new prompt whose similarity with
+// a cached prompt of its scope exceeds the threshold gets the cached answer.
+type SemanticCache struct {
+	mutex      sync.Mutex
+	embedder   mu.Agent
+	stores     map[string]*rag.MemoryVectorStore
+	entries    map[string]*cacheEntry
+	similarity float64
+	ttl        time.Duration
+	maxEntries int
+	path       string
+	hits       int
+	misses     int
+}
+
+// cacheEntry is a cached answer, the embedding of its prompt is in the store of its scope
+type cacheEntry struct {
+	ID        string    `json:"id"`
+	Scope     string    `json:"scope"`
+	Prompt    string    `json:"prompt"`
+	Answer    string    `json:"answer"`
+	Created   time.Time `json:"created"`
+	Embedding []float64 `json:"embedding"`
+}
+
+// CacheHit is an answer found in the cache
+type CacheHit struct {
+	Answer string
+	// Prompt is the cached prompt similar to the new one
+	Prompt     string
+	Similarity float64
+	Created    time.Time
+}
+
+// CacheStats are the statistics of a cache since its creation
+type CacheStats struct {
+	Entries int
+	Hits    int
+	Misses  int
+}
+
+// NewSemanticCache creates a semantic cache, the embedder agent computes the embeddings of the prompts
+//
+// Example usage:
+//
+//	cache, err := memory.NewSemanticCache(embeddingAgent, memory.WithCacheTTL(24*time.Hour))
+//	answer, hit, err := cache.Answer(agent.GetName(), prompt, func() (string, error) {
+//	  return agent.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)})
+//	})
+func NewSemanticCache(embedder mu.Agent, options ...CacheOption) (*SemanticCache, error) {
+	cache := &SemanticCache{
+		embedder:   embedder,
+		stores:     map[string]*rag.MemoryVectorStore{},
+		entries:    map[string]*cacheEntry{},
+		similarity: 0.95,
+		maxEntries: 10000,
+	}
+	for _, option := range options {
+		option(cache)
+	}
+	if cache.path != "" {
+		if err := cache.load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("invalid cache file %s: %w", cache.path, err)
+		}
+	}
+	return cache, nil
+}
+
+// WithCacheSimilarity is a functional option that sets the minimum cosine similarity of a cached prompt
+// with a new prompt to return its answer (0.95 by default: the same question in other words)
+func WithCacheSimilarity(similarity float64) CacheOption {
+	return func(cache *SemanticCache) {
+		cache.similarity = similarity
+	}
+}
+
+// WithCacheTTL is a functional option that sets the lifetime of the cached answers (no expiration by default)
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(cache *SemanticCache) {
+		cache.ttl = ttl
+	}
+}
+
+// WithCacheMaxEntries is a functional option that sets the maximum number of cached answers,
+// the oldest are removed first (10000 by default)
+func WithCacheMaxEntries(maxEntries int) CacheOption {
+	return func(cache *SemanticCache) {
+		cache.maxEntries = maxEntries
+	}
+}
+
+// WithCacheFile is a functional option that persists the cache to a JSON file
+func WithCacheFile(path string) CacheOption {
+	return func(cache *SemanticCache) {
+		cache.path = path
+	}
+}
+
+// Get returns the cached answer of the prompt the most similar to the prompt in the scope
+func (cache *SemanticCache) Get(scope, prompt string) (CacheHit, bool, error) {
+	embedding, err := cache.embedder.GenerateEmbeddingVector(prompt)
+	if err != nil {
+		return CacheHit{}, false, err
+	}
+	hit, ok := cache.lookup(scope, embedding)
+	return hit, ok, nil
+}
+
+// Put caches the answer of a prompt in the scope
+func (cache *SemanticCache) Put(scope, prompt, answer string) error {
+	embedding, err := cache.embedder.GenerateEmbeddingVector(prompt)
+	if err != nil {
+		return err
+	}
+	return cache.put(scope, prompt, answer, embedding)
+}
+
+// Answer returns the cached answer of a similar prompt in the scope, or generates the answer and caches it.
+// The prompt is embedded once, the failed generations are not cached.
+func (cache *SemanticCache) Answer(scope, prompt string, generate func() (string, error)) (string, bool, error) {
+	embedding, err := cache.embedder.GenerateEmbeddingVector(prompt)
+	if err != nil {
+		return "", false, err
+	}
+	if hit, ok := cache.lookup(scope, embedding); ok {
+		return hit.Answer, true, nil
+	}
+	answer, err := generate()
+	if err != nil {
+		return answer, false, err
+	}
+	return answer, false, cache.put(scope, prompt, answer, embedding)
+}
+
+// lookup searches the store of the scope, the expired entries found are removed
+func (cache *SemanticCache) lookup(scope string, embedding []float64) (CacheHit, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	store, ok := cache.stores[scope]
+	if !ok {
+		cache.misses++
+		return CacheHit{}, false
+	}
+	records, _ := store.SearchTopNSimilarities(rag.VectorRecord{Embedding: embedding}, cache.similarity, 5)
+	for _, record := range records {
+		entry := cache.entries[record.Id]
+		if entry == nil {
+			continue
+		}
+		if cache.expired(entry) {
+			cache.remove(entry)
+			continue
+		}
+		cache.hits++
+		return CacheHit{Answer: entry.Answer, Prompt: entry.Prompt, Similarity: record.CosineSimilarity, Created: entry.Created}, true
+	}
+	cache.misses++
+	return CacheHit{}, false
+}
+
+// put adds an entry and persists the cache
+func (cache *SemanticCache) put(scope, prompt, answer string, embedding []float64) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	cache.add(&cacheEntry{
+		ID:        uuid.New().String(),
+		Scope:     scope,
+		Prompt:    prompt,
+		Answer:    answer,
+		Created:   time.Now(),
+		Embedding: embedding,
+	})
+	cache.evict()
+	return cache.persist()
+}
+
+// add stores an entry (the lock is held by the caller)
+func (cache *SemanticCache) add(entry *cacheEntry) {
+	store, ok := cache.stores[entry.Scope]
+	if !ok {
+		store = &rag.MemoryVectorStore{Records: map[string]rag.VectorRecord{}}
+		cache.stores[entry.Scope] = store
+	}
+	store.Save(rag.VectorRecord{Id: entry.ID, Prompt: entry.Prompt, Embedding: entry.Embedding})
+	cache.entries[entry.ID] = entry
+}
+
+// remove deletes an entry (the lock is held by the caller)
+func (cache *SemanticCache) remove(entry *cacheEntry) {
+	delete(cache.entries, entry.ID)
+	if store, ok := cache.stores[entry.Scope]; ok {
+		delete(store.Records, entry.ID)
+		if len(store.Records) == 0 {
+			delete(cache.stores, entry.Scope)
+		}
+	}
+}
+
+// expired reports if an entry has outlived the TTL
+func (cache *SemanticCache) expired(entry *cacheEntry) bool {
+	return cache.ttl > 0 && time.Since(entry.Created) > cache.ttl
+}
+
+// evict removes the expired entries, then the oldest above the maximum (the lock is held by the caller)
+func (cache *SemanticCache) evict() {
+	for _, entry := range cache.entries {
+		if cache.expired(entry) {
+			cache.remove(entry)
+		}
+	}
+	if cache.maxEntries <= 0 || len(cache.entries) <= cache.maxEntries {
+		return
+	}
+	for _, entry := range cache.sorted()[:len(cache.entries)-cache.maxEntries] {
+		cache.remove(entry)
+	}
+}
+
+// sorted returns the entries, the oldest first (the lock is held by the caller)
+func (cache *SemanticCache) sorted() []*cacheEntry {
+	entries := make([]*cacheEntry, 0, len(cache.entries))
+	for _, entry := range cache.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Created.Before(entries[j].Created)
+	})
+	return entries
+}
+
+// Invalidate removes the cached answers of a scope (all the scopes if empty)
+func (cache *SemanticCache) Invalidate(scope string) error {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	for _, entry := range cache.entries {
+		if scope == "" || entry.Scope == scope {
+			cache.remove(entry)
+		}
+	}
+	return cache.persist()
+}
+
+// Stats returns the statistics of the cache
+func (cache *SemanticCache) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	return CacheStats{Entries: len(cache.entries), Hits: cache.hits, Misses: cache.misses}
+}
+
+// load reads the cache file
+func (cache *SemanticCache) load() error {
+	data, err := os.ReadFile(cache.path)
+	if err != nil {
+		return err
+	}
+	var entries []*cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !cache.expired(entry) {
+			cache.add(entry)
+		}
+	}
+	return nil
+}
+
+// persist writes the cache to the cache file (the lock is held by the caller)
+func (cache *SemanticCache) persist() error {
+	if cache.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(cache.sorted())
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(cache.path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(cache.path, data, 0644)
+}
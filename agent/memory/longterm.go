@@ -0,0 +1,234 @@
+package memory
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// extractionPrompt asks the extractor agent for the facts worth remembering
+const extractionPrompt = `Extract the salient facts about the user (identity, preferences, projects, decisions) from this exchange,
+only those useful in future conversations. Answer only with a JSON array of short standalone sentences, [] if there is none.
+
+<user>
+%s
+</user>
+<assistant>
+%s
+</assistant>`
+
+// LongTermOption is a functional option for configuring LongTermMemory instances
+type LongTermOption func(*LongTermMemory)
+
+// LongTermMemory remembers facts across conversations: after each exchange, an extractor agent extracts
+// the salient facts, they are embedded and stored, then the facts relevant to a new prompt are recalled
+// into the system message.
+type LongTermMemory struct {
+	mutex               sync.Mutex
+	extractor           mu.Agent
+	embedder            mu.Agent
+	store               *rag.MemoryVectorStore
+	path                string
+	topN                int
+	similarity          float64
+	duplicateSimilarity float64
+}
+
+// NewLongTermMemory creates a long-term memory, the extractor agent extracts the facts
+// and the embedder agent computes their embeddings
+//
+// Example usage:
+//
+//	longTerm, err := memory.NewLongTermMemory(chatAgent, embeddingAgent, memory.WithMemoryFile("memories.json"))
+//	system, _ := longTerm.Augment("You are a helpful assistant.", prompt)
+//	answer, _ := chatAgent.Run([]openai.ChatCompletionMessageParamUnion{openai.SystemMessage(system), openai.UserMessage(prompt)})
+//	longTerm.Remember(prompt, answer)
+func NewLongTermMemory(extractor, embedder mu.Agent, options ...LongTermOption) (*LongTermMemory, error) {
+	memory := &LongTermMemory{
+		extractor:           extractor,
+		embedder:            embedder,
+		store:               &rag.MemoryVectorStore{Records: map[string]rag.VectorRecord{}},
+		topN:                5,
+		similarity:          0.6,
+		duplicateSimilarity: 0.95,
+	}
+	for _, option := range options {
+		option(memory)
+	}
+	if memory.path != "" {
+		if err := memory.store.Load(memory.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("invalid memory file %s: %w", memory.path, err)
+		}
+		if memory.store.Records == nil {
+			memory.store.Records = map[string]rag.VectorRecord{}
+		}
+	}
+	return memory, nil
+}
+
+// WithMemoryFile is a functional option that persists the memories to a JSON file
+func WithMemoryFile(path string) LongTermOption {
+	return func(memory *LongTermMemory) {
+		memory.path = path
+	}
+}
+
+// WithRecall is a functional option that sets the maximum number of recalled facts (5 by default)
+// and their minimum cosine similarity with the prompt (0.6 by default)
+func WithRecall(topN int, similarity float64) LongTermOption {
+	return func(memory *LongTermMemory) {
+		memory.topN = topN
+		memory.similarity = similarity
+	}
+}
+
+// WithDuplicateSimilarity is a functional option that sets the similarity above which a new fact
+// is considered as already known and is not stored (0.95 by default)
+func WithDuplicateSimilarity(similarity float64) LongTermOption {
+	return func(memory *LongTermMemory) {
+		memory.duplicateSimilarity = similarity
+	}
+}
+
+// Remember extracts the facts of an exchange and stores the new ones, it returns the stored facts
+func (memory *LongTermMemory) Remember(userMessage, answer string) ([]string, error) {
+	facts, err := memory.extract(userMessage, answer)
+	if err != nil {
+		return nil, err
+	}
+
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	stored := []string{}
+	for _, fact := range facts {
+		embedding, err := memory.embedder.GenerateEmbeddingVector(fact)
+		if err != nil {
+			return stored, err
+		}
+		known, err := memory.store.SearchTopNSimilarities(rag.VectorRecord{Embedding: embedding}, memory.duplicateSimilarity, 1)
+		if err == nil && len(known) > 0 {
+			continue
+		}
+		if _, err := memory.store.Save(rag.VectorRecord{Prompt: fact, Embedding: embedding}); err != nil {
+			return stored, err
+		}
+		stored = append(stored, fact)
+	}
+	if len(stored) > 0 {
+		return stored, memory.persist()
+	}
+	return stored, nil
+}
+
+// extract asks the extractor agent for the facts of an exchange (its messages are restored afterwards)
+func (memory *LongTermMemory) extract(userMessage, answer string) ([]string, error) {
+	saved := append([]openai.ChatCompletionMessageParamUnion{}, memory.extractor.GetMessages()...)
+	defer memory.extractor.SetMessages(saved)
+	memory.extractor.SetMessages(nil)
+
+	response, err := memory.extractor.Run([]openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage(fmt.Sprintf(extractionPrompt, userMessage, answer)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	extracted, err := helpers.ExtractJSON(response)
+	if err != nil {
+		return nil, fmt.Errorf("the extractor didn't answer a JSON array: %q", response)
+	}
+	var facts []string
+	if err := extracted.Unmarshal(&facts); err != nil {
+		return nil, fmt.Errorf("the extractor didn't answer a JSON array of strings: %w", err)
+	}
+	cleaned := []string{}
+	for _, fact := range facts {
+		if fact = strings.TrimSpace(fact); fact != "" {
+			cleaned = append(cleaned, fact)
+		}
+	}
+	return cleaned, nil
+}
+
+// Recall returns the facts the most similar to the query
+func (memory *LongTermMemory) Recall(query string) ([]string, error) {
+	memory.mutex.Lock()
+	empty := len(memory.store.Records) == 0
+	memory.mutex.Unlock()
+	if empty {
+		return []string{}, nil
+	}
+
+	embedding, err := memory.embedder.GenerateEmbeddingVector(query)
+	if err != nil {
+		return nil, err
+	}
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	records, err := memory.store.SearchTopNSimilarities(rag.VectorRecord{Embedding: embedding}, memory.similarity, memory.topN)
+	if err != nil {
+		return nil, err
+	}
+	facts := make([]string, 0, len(records))
+	for _, record := range records {
+		facts = append(facts, record.Prompt)
+	}
+	return facts, nil
+}
+
+// Augment adds the facts relevant to the query to the system message (unchanged if there is none)
+func (memory *LongTermMemory) Augment(systemMessage, query string) (string, error) {
+	facts, err := memory.Recall(query)
+	if err != nil || len(facts) == 0 {
+		return systemMessage, err
+	}
+	var builder strings.Builder
+	builder.WriteString(systemMessage)
+	builder.WriteString("\n\nWhat you remember from the previous conversations with the user:\n")
+	for _, fact := range facts {
+		builder.WriteString("- " + fact + "\n")
+	}
+	return builder.String(), nil
+}
+
+// Facts returns all the stored facts, sorted
+func (memory *LongTermMemory) Facts() []string {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	facts := make([]string, 0, len(memory.store.Records))
+	for _, record := range memory.store.Records {
+		facts = append(facts, record.Prompt)
+	}
+	sort.Strings(facts)
+	return facts
+}
+
+// Forget removes all the stored facts
+func (memory *LongTermMemory) Forget() error {
+	memory.mutex.Lock()
+	defer memory.mutex.Unlock()
+	if err := memory.store.ResetMemory(); err != nil {
+		return err
+	}
+	return memory.persist()
+}
+
+// persist writes the memories to the memory file (the lock is held by the caller)
+func (memory *LongTermMemory) persist() error {
+	if memory.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(memory.path), 0755); err != nil {
+		return err
+	}
+	return memory.store.Persist(memory.path)
+}
@@ -0,0 +1,212 @@
+package msg
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Role returns the role of a message: system, developer, user, assistant, tool or function
+func Role(message openai.ChatCompletionMessageParamUnion) string {
+	// The role fields are constants, only set when the message is marshaled
+	switch {
+	case message.OfSystem != nil:
+		return "system"
+	case message.OfDeveloper != nil:
+		return "developer"
+	case message.OfUser != nil:
+		return "user"
+	case message.OfAssistant != nil:
+		return "assistant"
+	case message.OfTool != nil:
+		return "tool"
+	case message.OfFunction != nil:
+		return "function"
+	}
+	return ""
+}
+
+// Content returns the text of a message whatever its form: a string, or the text parts joined by newlines
+// (the refusal of an assistant message when it has no text, the result of a tool message).
+// The image, audio and file parts are ignored.
+func Content(message openai.ChatCompletionMessageParamUnion) string {
+	switch {
+	case message.OfSystem != nil:
+		return textOf(message.OfSystem.Content.OfString.Value, message.OfSystem.Content.OfArrayOfContentParts)
+	case message.OfDeveloper != nil:
+		return textOf(message.OfDeveloper.Content.OfString.Value, message.OfDeveloper.Content.OfArrayOfContentParts)
+	case message.OfUser != nil:
+		texts := []string{}
+		for _, part := range message.OfUser.Content.OfArrayOfContentParts {
+			if part.OfText != nil {
+				texts = append(texts, part.OfText.Text)
+			}
+		}
+		return joinTexts(message.OfUser.Content.OfString.Value, texts)
+	case message.OfAssistant != nil:
+		texts, refusals := []string{}, []string{}
+		for _, part := range message.OfAssistant.Content.OfArrayOfContentParts {
+			switch {
+			case part.OfText != nil:
+				texts = append(texts, part.OfText.Text)
+			case part.OfRefusal != nil:
+				refusals = append(refusals, part.OfRefusal.Refusal)
+			}
+		}
+		if content := joinTexts(message.OfAssistant.Content.OfString.Value, texts); content != "" {
+			return content
+		}
+		return joinTexts(message.OfAssistant.Refusal.Value, refusals)
+	case message.OfTool != nil:
+		return textOf(message.OfTool.Content.OfString.Value, message.OfTool.Content.OfArrayOfContentParts)
+	case message.OfFunction != nil:
+		return message.OfFunction.Content.Value
+	}
+	return ""
+}
+
+// textOf returns a string content, or the text parts joined by newlines
+func textOf(content string, parts []openai.ChatCompletionContentPartTextParam) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		texts = append(texts, part.Text)
+	}
+	return joinTexts(content, texts)
+}
+
+// joinTexts returns a string content, or the texts of its parts joined by newlines
+func joinTexts(content string, texts []string) string {
+	if content != "" {
+		return content
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ToolCallID returns the ID of the tool call answered by a tool message, an empty string for the other messages
+func ToolCallID(message openai.ChatCompletionMessageParamUnion) string {
+	if message.OfTool != nil {
+		return message.OfTool.ToolCallID
+	}
+	return ""
+}
+
+// ToOpenAIJSON encodes messages as the JSON array of the messages of the OpenAI Chat Completions API
+func ToOpenAIJSON(messages []openai.ChatCompletionMessageParamUnion) ([]byte, error) {
+	if messages == nil {
+		messages = []openai.ChatCompletionMessageParamUnion{}
+	}
+	return json.Marshal(messages)
+}
+
+// FromOpenAIJSON decodes a JSON array of messages of the OpenAI Chat Completions API (e.g. a request payload
+// or a conversation saved by ToOpenAIJSON), each message is decoded according to its role
+func FromOpenAIJSON(data []byte) ([]openai.ChatCompletionMessageParamUnion, error) {
+	raws := []json.RawMessage{}
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, fmt.Errorf("invalid messages: %w", err)
+	}
+	messages := make([]openai.ChatCompletionMessageParamUnion, 0, len(raws))
+	for i, raw := range raws {
+		message, err := decodeMessage(raw)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+// decodeMessage decodes a message into the variant of its role
+func decodeMessage(raw json.RawMessage) (openai.ChatCompletionMessageParamUnion, error) {
+	header := struct {
+		Role string `json:"role"`
+	}{}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return openai.ChatCompletionMessageParamUnion{}, err
+	}
+	message := openai.ChatCompletionMessageParamUnion{}
+	var target any
+	switch header.Role {
+	case "system":
+		message.OfSystem = &openai.ChatCompletionSystemMessageParam{}
+		target = message.OfSystem
+	case "developer":
+		message.OfDeveloper = &openai.ChatCompletionDeveloperMessageParam{}
+		target = message.OfDeveloper
+	case "user":
+		message.OfUser = &openai.ChatCompletionUserMessageParam{}
+		target = message.OfUser
+	case "assistant":
+		message.OfAssistant = &openai.ChatCompletionAssistantMessageParam{}
+		target = message.OfAssistant
+	case "tool":
+		message.OfTool = &openai.ChatCompletionToolMessageParam{}
+		target = message.OfTool
+	case "function":
+		message.OfFunction = &openai.ChatCompletionFunctionMessageParam{}
+		target = message.OfFunction
+	case "":
+		return message, fmt.Errorf("missing role")
+	default:
+		return message, fmt.Errorf("unknown role %q", header.Role)
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return message, err
+	}
+	return message, nil
+}
+
+// ToMarkdownTranscript renders messages as a markdown transcript: a section per message, the tool calls
+// of the assistant messages with their JSON arguments, and the tool results
+func ToMarkdownTranscript(messages []openai.ChatCompletionMessageParamUnion) string {
+	var builder strings.Builder
+	toolNames := map[string]string{}
+	for _, message := range messages {
+		role := Role(message)
+		switch role {
+		case "tool":
+			name := toolNames[ToolCallID(message)]
+			if name == "" {
+				name = ToolCallID(message)
+			}
+			fmt.Fprintf(&builder, "### Tool result: `%s`\n\n%s\n\n", name, codeBlock(Content(message)))
+			continue
+		case "function":
+			fmt.Fprintf(&builder, "### Function result: `%s`\n\n%s\n\n", message.OfFunction.Name, codeBlock(Content(message)))
+			continue
+		}
+
+		fmt.Fprintf(&builder, "## %s\n\n", roleHeading(role))
+		if content := strings.TrimSpace(Content(message)); content != "" {
+			builder.WriteString(content + "\n\n")
+		}
+		for _, toolCall := range MessageToolCalls(message) {
+			toolNames[toolCall.ID] = toolCall.Name
+			fmt.Fprintf(&builder, "### Tool call: `%s`\n\n%s\n\n", toolCall.Name, codeBlock(toolCall.Arguments))
+		}
+	}
+	return builder.String()
+}
+
+// roleHeading returns the heading of the messages of a role
+func roleHeading(role string) string {
+	if role == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(role[:1]) + role[1:]
+}
+
+// codeBlock returns a fenced code block, indented JSON when the text is JSON
+func codeBlock(text string) string {
+	language := ""
+	if json.Valid([]byte(text)) {
+		text, language = PrettyJSON(text), "json"
+	}
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + language + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
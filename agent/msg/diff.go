@@ -0,0 +1,182 @@
+package msg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ChangeKind is the kind of a change between two message histories
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeEdited  ChangeKind = "edited"
+)
+
+// MessageChange is a turn added, removed or edited between two message histories
+type MessageChange struct {
+	Kind ChangeKind
+	// OldIndex is the position of the message in the first history (-1 if added),
+	// NewIndex its position in the second one (-1 if removed)
+	OldIndex int
+	NewIndex int
+	// Old and New are the messages before and after the change (zero if added or removed)
+	Old openai.ChatCompletionMessageParamUnion
+	New openai.ChatCompletionMessageParamUnion
+}
+
+// String describes the change on a line, e.g. "~ #3 assistant: Paris is the capital..."
+func (change MessageChange) String() string {
+	switch change.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ #%d %s", change.NewIndex, summarizeMessage(change.New))
+	case ChangeRemoved:
+		return fmt.Sprintf("- #%d %s", change.OldIndex, summarizeMessage(change.Old))
+	}
+	return fmt.Sprintf("~ #%d %s", change.NewIndex, summarizeMessage(change.New))
+}
+
+// summarizeMessage returns the role and the beginning of the content (or of the tool calls) of a message
+func summarizeMessage(message openai.ChatCompletionMessageParamUnion) string {
+	text := Content(message)
+	if text == "" {
+		names := []string{}
+		for _, toolCall := range MessageToolCalls(message) {
+			names = append(names, toolCall.Name+"()")
+		}
+		text = strings.Join(names, ", ")
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > 60 {
+		text = string(runes[:60]) + "..."
+	}
+	return Role(message) + ": " + text
+}
+
+// Diff compares two message histories (e.g. a conversation and a branch cloned from it) and returns the
+// changes turning a into b, in order: the messages are compared by their JSON, a removed message
+// followed by an added message of the same role is reported as edited. No change means the histories are equal.
+//
+// Example usage:
+//
+//	branch := session.Clone()
+//	branch.Messages = append(branch.Messages, openai.UserMessage("Shorter please"))
+//	for _, change := range msg.Diff(session.Messages, branch.Messages) {
+//	  fmt.Println(change) // + #5 user: Shorter please
+//	}
+func Diff(a, b []openai.ChatCompletionMessageParamUnion) []MessageChange {
+	oldKeys, newKeys := messageKeys(a), messageKeys(b)
+	changes := []MessageChange{}
+	removed, added := []int{}, []int{}
+	// flush reports the removed and added messages between two common messages, pairing them by role as edits
+	flush := func() {
+		for len(removed) > 0 || len(added) > 0 {
+			switch {
+			case len(removed) > 0 && len(added) > 0 && Role(a[removed[0]]) == Role(b[added[0]]):
+				changes = append(changes, MessageChange{Kind: ChangeEdited, OldIndex: removed[0], NewIndex: added[0], Old: a[removed[0]], New: b[added[0]]})
+				removed, added = removed[1:], added[1:]
+			case len(removed) > 0:
+				changes = append(changes, MessageChange{Kind: ChangeRemoved, OldIndex: removed[0], NewIndex: -1, Old: a[removed[0]]})
+				removed = removed[1:]
+			default:
+				changes = append(changes, MessageChange{Kind: ChangeAdded, OldIndex: -1, NewIndex: added[0], New: b[added[0]]})
+				added = added[1:]
+			}
+		}
+	}
+	oldIdx, newIdx := 0, 0
+	for _, common := range longestCommonSubsequence(oldKeys, newKeys) {
+		for ; oldIdx < common[0]; oldIdx++ {
+			removed = append(removed, oldIdx)
+		}
+		for ; newIdx < common[1]; newIdx++ {
+			added = append(added, newIdx)
+		}
+		flush()
+		oldIdx, newIdx = common[0]+1, common[1]+1
+	}
+	for ; oldIdx < len(a); oldIdx++ {
+		removed = append(removed, oldIdx)
+	}
+	for ; newIdx < len(b); newIdx++ {
+		added = append(added, newIdx)
+	}
+	flush()
+	return changes
+}
+
+// Merge combines a history and a branch of it: the messages of the branch after their common prefix
+// (the fork point) are appended to base, except the ones base already has after the fork point.
+// When base didn't move since the fork, the result is the branch; base and branch are left unchanged.
+//
+// Example usage:
+//
+//	merged := msg.Merge(agent.GetMessages(), branch.GetMessages())
+//	agent.SetMessages(merged)
+func Merge(base, branch []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	baseKeys, branchKeys := messageKeys(base), messageKeys(branch)
+	fork := 0
+	for fork < len(baseKeys) && fork < len(branchKeys) && baseKeys[fork] == branchKeys[fork] {
+		fork++
+	}
+
+	merged := append([]openai.ChatCompletionMessageParamUnion{}, base...)
+	known := map[int]bool{}
+	for _, common := range longestCommonSubsequence(baseKeys[fork:], branchKeys[fork:]) {
+		known[fork+common[1]] = true
+	}
+	for idx := fork; idx < len(branch); idx++ {
+		if !known[idx] {
+			merged = append(merged, branch[idx])
+		}
+	}
+	return merged
+}
+
+// messageKeys returns the JSON of the messages, to compare them
+func messageKeys(messages []openai.ChatCompletionMessageParamUnion) []string {
+	keys := make([]string, len(messages))
+	for idx, message := range messages {
+		data, err := message.MarshalJSON()
+		if err != nil {
+			// A message which can't be encoded is different from all the others
+			keys[idx] = fmt.Sprintf("\x00%p", &messages[idx])
+			continue
+		}
+		keys[idx] = string(data)
+	}
+	return keys
+}
+
+// longestCommonSubsequence returns the pairs of positions of the common elements of a and b, in order
+func longestCommonSubsequence(a, b []string) [][2]int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+	pairs := [][2]int{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			pairs = append(pairs, [2]int{i, j})
+			i, j = i+1, j+1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
@@ -0,0 +1,41 @@
+package msg
+
+import (
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ImagePart returns the content part of an image file, inlined as a data URL (see helpers.LoadImageFile):
+// detail is the resolution of the image for the model, "low", "high" or "auto" (the default if empty)
+func ImagePart(path string, detail string) (openai.ChatCompletionContentPartUnionParam, error) {
+	image, err := helpers.LoadImageFile(path, 0)
+	if err != nil {
+		return openai.ChatCompletionContentPartUnionParam{}, err
+	}
+	return openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+		URL:    image.DataURL(),
+		Detail: detail,
+	}), nil
+}
+
+// UserMessageWithImages returns a user message made of a text and of image files (the text is omitted if empty)
+//
+// Example usage:
+//
+//	message, err := msg.UserMessageWithImages("What is on this picture?", "./cat.jpg")
+//	answer, err := agent.Run([]openai.ChatCompletionMessageParamUnion{message})
+func UserMessageWithImages(text string, paths ...string) (openai.ChatCompletionMessageParamUnion, error) {
+	parts := []openai.ChatCompletionContentPartUnionParam{}
+	if text != "" {
+		parts = append(parts, openai.TextContentPart(text))
+	}
+	for _, path := range paths {
+		part, err := ImagePart(path, "")
+		if err != nil {
+			return openai.ChatCompletionMessageParamUnion{}, err
+		}
+		parts = append(parts, part)
+	}
+	return openai.UserMessage(parts), nil
+}
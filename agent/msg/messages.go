@@ -9,7 +9,9 @@ import (
 	"github.com/openai/openai-go/v2"
 )
 
-// MessageToMap converts an OpenAI chat message to a map with string keys and values
+// MessageToMap converts an OpenAI chat message to a map with string keys and values: the string fields
+// of the message (role, name, tool_call_id...), its text content even when made of parts, and the tool calls
+// of an assistant message as a JSON array
 func MessageToMap(message openai.ChatCompletionMessageParamUnion) (map[string]string, error) {
 	jsonData, err := message.MarshalJSON()
 	if err != nil {
@@ -27,6 +29,16 @@ func MessageToMap(message openai.ChatCompletionMessageParamUnion) (map[string]st
 			stringMap[key] = str
 		}
 	}
+	if content := Content(message); content != "" {
+		stringMap["content"] = content
+	}
+	if toolCalls, ok := result["tool_calls"]; ok {
+		toolCallsJSON, err := json.Marshal(toolCalls)
+		if err != nil {
+			return nil, err
+		}
+		stringMap["tool_calls"] = string(toolCallsJSON)
+	}
 
 	return stringMap, nil
 }
@@ -0,0 +1,177 @@
+package msg
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// RedactionPattern is a kind of sensitive value masked by Redact
+type RedactionPattern struct {
+	Name   string
+	Regexp *regexp.Regexp
+	// Replacement replaces the matches, "[REDACTED:<name>]" if empty
+	Replacement string
+}
+
+// Built-in redaction patterns, see DefaultRedactionPatterns
+var (
+	// APIKeyPattern matches the API keys of the well-known providers (OpenAI and Anthropic sk-, GitHub, AWS,
+	// Google, Slack, Hugging Face)
+	APIKeyPattern = RedactionPattern{Name: "api_key", Regexp: regexp.MustCompile(
+		`\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}|\bgh[pousr]_[A-Za-z0-9]{30,}|\bgithub_pat_[A-Za-z0-9_]{22,}` +
+			`|\b(?:AKIA|ASIA)[0-9A-Z]{16}\b|\bAIza[0-9A-Za-z_-]{35}|\bxox[abprs]-[A-Za-z0-9-]{10,}|\bhf_[A-Za-z0-9]{30,}`)}
+	// BearerTokenPattern matches the bearer tokens of the Authorization headers
+	BearerTokenPattern = RedactionPattern{Name: "bearer_token", Regexp: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`)}
+	// JWTPattern matches the JSON Web Tokens
+	JWTPattern = RedactionPattern{Name: "jwt", Regexp: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)}
+	// PrivateKeyPattern matches the PEM private keys
+	PrivateKeyPattern = RedactionPattern{Name: "private_key", Regexp: regexp.MustCompile(
+		`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)}
+	// EmailPattern matches the email addresses
+	EmailPattern = RedactionPattern{Name: "email", Regexp: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)}
+)
+
+// DefaultRedactionPatterns returns the built-in patterns: API keys, bearer tokens, JWTs, private keys and emails
+func DefaultRedactionPatterns() []RedactionPattern {
+	return []RedactionPattern{APIKeyPattern, BearerTokenPattern, JWTPattern, PrivateKeyPattern, EmailPattern}
+}
+
+// NewRedactionPattern compiles a custom redaction pattern
+//
+// Example usage:
+//
+//	customerID, err := msg.NewRedactionPattern("customer_id", `\bCUS-\d{6}\b`)
+//	redacted := msg.Redact(messages, append(msg.DefaultRedactionPatterns(), customerID))
+func NewRedactionPattern(name, expression string) (RedactionPattern, error) {
+	re, err := regexp.Compile(expression)
+	if err != nil {
+		return RedactionPattern{}, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+	}
+	return RedactionPattern{Name: name, Regexp: re}, nil
+}
+
+// RedactText masks the matches of the patterns in a text (the default patterns if nil)
+func RedactText(text string, patterns []RedactionPattern) string {
+	if patterns == nil {
+		patterns = DefaultRedactionPatterns()
+	}
+	for _, pattern := range patterns {
+		if pattern.Regexp == nil {
+			continue
+		}
+		replacement := pattern.Replacement
+		if replacement == "" {
+			replacement = "[REDACTED:" + pattern.Name + "]"
+		}
+		text = pattern.Regexp.ReplaceAllLiteralString(text, replacement)
+	}
+	return text
+}
+
+// Redact returns a copy of the messages with the matches of the patterns masked (the default patterns if nil):
+// in the contents and text parts, the refusals, and the tool call arguments, where the values of the
+// sensitive keys (password, token, api_key... see logging.IsSensitiveKey) are masked too. The messages
+// are left unchanged, the copy can be logged, persisted or exported.
+//
+// Example usage:
+//
+//	transcript := msg.ToMarkdownTranscript(msg.Redact(agent.GetMessages(), nil))
+func Redact(messages []openai.ChatCompletionMessageParamUnion, patterns []RedactionPattern) []openai.ChatCompletionMessageParamUnion {
+	if patterns == nil {
+		patterns = DefaultRedactionPatterns()
+	}
+	redacted := make([]openai.ChatCompletionMessageParamUnion, 0, len(messages))
+	for _, message := range messages {
+		redacted = append(redacted, redactMessage(message, patterns))
+	}
+	return redacted
+}
+
+// redactMessage redacts a copy of a message, made through its JSON
+func redactMessage(message openai.ChatCompletionMessageParamUnion, patterns []RedactionPattern) openai.ChatCompletionMessageParamUnion {
+	data, err := message.MarshalJSON()
+	if err != nil {
+		return message
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return message
+	}
+	data, err = json.Marshal(redactJSONValue("", value, patterns))
+	if err != nil {
+		return message
+	}
+	copied, err := decodeMessage(data)
+	if err != nil {
+		return message
+	}
+	return copied
+}
+
+// redactJSONValue masks the strings of a JSON message, except the identifiers and the data URLs
+func redactJSONValue(key string, value any, patterns []RedactionPattern) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for itemKey, item := range v {
+			v[itemKey] = redactJSONValue(itemKey, item, patterns)
+		}
+		return v
+	case []any:
+		for idx, item := range v {
+			v[idx] = redactJSONValue(key, item, patterns)
+		}
+		return v
+	case string:
+		switch {
+		case key == "role" || key == "type" || key == "id" || key == "tool_call_id" || key == "name":
+			return v
+		case strings.HasPrefix(v, "data:"):
+			return v
+		case key == "arguments" || key == "input":
+			return redactArguments(v, patterns)
+		}
+		return RedactText(v, patterns)
+	}
+	return value
+}
+
+// redactArguments masks the tool call arguments: the values of the sensitive keys and the matches of the patterns
+func redactArguments(arguments string, patterns []RedactionPattern) string {
+	var value any
+	if err := json.Unmarshal([]byte(arguments), &value); err != nil {
+		return RedactText(arguments, patterns)
+	}
+	data, err := json.Marshal(redactArgumentValue(value, patterns))
+	if err != nil {
+		return RedactText(arguments, patterns)
+	}
+	return string(data)
+}
+
+func redactArgumentValue(value any, patterns []RedactionPattern) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			if logging.IsSensitiveKey(key) {
+				v[key] = "[REDACTED]"
+			} else {
+				v[key] = redactArgumentValue(item, patterns)
+			}
+		}
+		return v
+	case []any:
+		for idx, item := range v {
+			v[idx] = redactArgumentValue(item, patterns)
+		}
+		return v
+	case string:
+		return RedactText(v, patterns)
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package msg
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ToolCall is a tool call requested by an assistant message
+type ToolCall struct {
+	ID   string
+	Name string
+	// Arguments is the JSON of the arguments (the raw input of a custom tool)
+	Arguments string
+}
+
+// ToolExchange is a tool call paired with its result
+type ToolExchange struct {
+	ToolCall
+	Result string
+	// Answered is false while no tool message answers the call
+	Answered bool
+	// CallIndex and ResultIndex are the positions of the assistant and tool messages (ResultIndex is -1 if not answered)
+	CallIndex   int
+	ResultIndex int
+}
+
+// MessageToolCalls returns the tool calls of an assistant message, nil for the other messages
+func MessageToolCalls(message openai.ChatCompletionMessageParamUnion) []ToolCall {
+	if message.OfAssistant == nil {
+		return nil
+	}
+	toolCalls := []ToolCall{}
+	for _, toolCall := range message.OfAssistant.ToolCalls {
+		switch {
+		case toolCall.OfFunction != nil:
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        toolCall.OfFunction.ID,
+				Name:      toolCall.OfFunction.Function.Name,
+				Arguments: toolCall.OfFunction.Function.Arguments,
+			})
+		case toolCall.OfCustom != nil:
+			toolCalls = append(toolCalls, ToolCall{
+				ID:        toolCall.OfCustom.ID,
+				Name:      toolCall.OfCustom.Custom.Name,
+				Arguments: toolCall.OfCustom.Custom.Input,
+			})
+		}
+	}
+	return toolCalls
+}
+
+// ToolExchanges returns the tool calls of the messages in order, each with the result of the tool message
+// answering it (matched by call ID)
+//
+// Example usage:
+//
+//	for _, exchange := range msg.ToolExchanges(agent.GetMessages()) {
+//	  fmt.Printf("🛠️ %s(%s) → %s\n", exchange.Name, exchange.PrettyArguments(), exchange.Result)
+//	}
+func ToolExchanges(messages []openai.ChatCompletionMessageParamUnion) []ToolExchange {
+	exchanges := []ToolExchange{}
+	pending := map[string]int{} // call ID -> index of the exchange
+	for idx, message := range messages {
+		if message.OfTool != nil {
+			if exchangeIdx, ok := pending[message.OfTool.ToolCallID]; ok {
+				exchange := &exchanges[exchangeIdx]
+				exchange.Result, exchange.Answered, exchange.ResultIndex = Content(message), true, idx
+				delete(pending, message.OfTool.ToolCallID)
+			}
+			continue
+		}
+		for _, toolCall := range MessageToolCalls(message) {
+			pending[toolCall.ID] = len(exchanges)
+			exchanges = append(exchanges, ToolExchange{ToolCall: toolCall, CallIndex: idx, ResultIndex: -1})
+		}
+	}
+	return exchanges
+}
+
+// UnansweredToolCalls returns the tool calls without tool message answering them
+// (a conversation sent with an unanswered call is refused by the API)
+func UnansweredToolCalls(messages []openai.ChatCompletionMessageParamUnion) []ToolCall {
+	unanswered := []ToolCall{}
+	for _, exchange := range ToolExchanges(messages) {
+		if !exchange.Answered {
+			unanswered = append(unanswered, exchange.ToolCall)
+		}
+	}
+	return unanswered
+}
+
+// PrettyArguments returns the arguments indented (see PrettyJSON)
+func (toolCall ToolCall) PrettyArguments() string {
+	return PrettyJSON(toolCall.Arguments)
+}
+
+// PrettyJSON indents a JSON text, it is returned unchanged if it isn't valid JSON
+func PrettyJSON(text string) string {
+	var buffer bytes.Buffer
+	if err := json.Indent(&buffer, []byte(text), "", "  "); err != nil {
+		return text
+	}
+	return buffer.String()
+}
@@ -0,0 +1,142 @@
+package msg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// TrimStrategy tells TrimToBudget which turns to keep and what becomes of the dropped ones
+type TrimStrategy struct {
+	// KeepTurns is the number of recent turns (a user message and what follows it) always kept, 1 if 0
+	KeepTurns int
+	// Model is the model of the token estimates (see helpers.EstimateTokens)
+	Model string
+	// Summarize summarizes the dropped messages, which are replaced by a system message with the summary
+	// right after the system messages; they are only dropped if nil
+	Summarize func(dropped []openai.ChatCompletionMessageParamUnion) (string, error)
+}
+
+// DropMiddleTurns is the strategy dropping the oldest turns after the system messages, keeping the last turn
+var DropMiddleTurns = TrimStrategy{}
+
+// SummaryPrefix starts the system message replacing the turns summarized by TrimToBudget (as mu.SummaryMemory)
+const SummaryPrefix = mu.SummaryPrefix
+
+// EstimateTokens estimates the number of tokens of messages for a model (their JSON, see helpers.EstimateTokens)
+func EstimateTokens(messages []openai.ChatCompletionMessageParamUnion, model string) int {
+	tokens := 0
+	for _, message := range messages {
+		tokens += estimateMessageTokens(message, model)
+	}
+	return tokens
+}
+
+func estimateMessageTokens(message openai.ChatCompletionMessageParamUnion, model string) int {
+	data, err := message.MarshalJSON()
+	if err != nil {
+		return helpers.EstimateTokens(Content(message), model)
+	}
+	return helpers.EstimateTokens(string(data), model)
+}
+
+// TrimToBudget returns the messages reduced to maxTokens (estimated, see EstimateTokens) by dropping, or
+// summarizing, the oldest turns in the middle of the conversation. The leading system messages and the recent
+// turns are always kept, and an assistant message with tool calls is kept or dropped with the tool messages
+// answering it (the API refuses a tool call without result and a result without call): the result can exceed
+// maxTokens when the kept messages alone do. The messages are returned unchanged when they fit.
+//
+// Example usage:
+//
+//	trimmed, err := msg.TrimToBudget(agent.GetMessages(), 8000, msg.TrimStrategy{
+//	  KeepTurns: 2,
+//	  Summarize: func(dropped []openai.ChatCompletionMessageParamUnion) (string, error) {
+//	    return summarizer.Run(append(dropped, openai.UserMessage("Summarize this conversation")))
+//	  },
+//	})
+//	agent.SetMessages(trimmed)
+func TrimToBudget(messages []openai.ChatCompletionMessageParamUnion, maxTokens int, strategy TrimStrategy) ([]openai.ChatCompletionMessageParamUnion, error) {
+	if EstimateTokens(messages, strategy.Model) <= maxTokens {
+		return messages, nil
+	}
+	keepTurns := strategy.KeepTurns
+	if keepTurns <= 0 {
+		keepTurns = 1
+	}
+
+	// The leading system messages, the middle messages by droppable units, and the recent turns
+	prefix := 0
+	for prefix < len(messages) && (messages[prefix].OfSystem != nil || messages[prefix].OfDeveloper != nil) {
+		prefix++
+	}
+	recent := len(messages)
+	for turns := 0; recent > prefix && turns < keepTurns; {
+		recent--
+		if messages[recent].OfUser != nil {
+			turns++
+		}
+	}
+	units := trimUnits(messages[prefix:recent])
+
+	budget := maxTokens - EstimateTokens(messages[:prefix], strategy.Model) - EstimateTokens(messages[recent:], strategy.Model)
+	middleTokens := 0
+	for _, unit := range units {
+		middleTokens += EstimateTokens(unit, strategy.Model)
+	}
+	dropped := 0
+	for dropped < len(units) && middleTokens > budget {
+		middleTokens -= EstimateTokens(units[dropped], strategy.Model)
+		dropped++
+	}
+
+	var summary []openai.ChatCompletionMessageParamUnion
+	if strategy.Summarize != nil && dropped > 0 {
+		droppedMessages := []openai.ChatCompletionMessageParamUnion{}
+		for _, unit := range units[:dropped] {
+			droppedMessages = append(droppedMessages, unit...)
+		}
+		text, err := strategy.Summarize(droppedMessages)
+		if err != nil {
+			return messages, fmt.Errorf("failed to summarize the conversation: %w", err)
+		}
+		if text = strings.TrimSpace(text); text != "" {
+			summary = []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(SummaryPrefix + text)}
+			// The summary takes the room of more turns, they are dropped without summary
+			middleTokens += EstimateTokens(summary, strategy.Model)
+			for dropped < len(units) && middleTokens > budget {
+				middleTokens -= EstimateTokens(units[dropped], strategy.Model)
+				dropped++
+			}
+		}
+	}
+
+	trimmed := append([]openai.ChatCompletionMessageParamUnion{}, messages[:prefix]...)
+	trimmed = append(trimmed, summary...)
+	for _, unit := range units[dropped:] {
+		trimmed = append(trimmed, unit...)
+	}
+	return append(trimmed, messages[recent:]...), nil
+}
+
+// trimUnits groups the messages which must be dropped together: an assistant message with the tool
+// messages answering its tool calls, each other message alone
+func trimUnits(messages []openai.ChatCompletionMessageParamUnion) [][]openai.ChatCompletionMessageParamUnion {
+	units := [][]openai.ChatCompletionMessageParamUnion{}
+	for idx := 0; idx < len(messages); idx++ {
+		unit := []openai.ChatCompletionMessageParamUnion{messages[idx]}
+		callIDs := map[string]bool{}
+		for _, toolCall := range MessageToolCalls(messages[idx]) {
+			callIDs[toolCall.ID] = true
+		}
+		for len(callIDs) > 0 && idx+1 < len(messages) && callIDs[ToolCallID(messages[idx+1])] {
+			idx++
+			unit = append(unit, messages[idx])
+		}
+		units = append(units, unit)
+	}
+	return units
+}
@@ -2,7 +2,12 @@ package mu
 
 import (
 	"context"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+
 	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/shared"
 )
 
@@ -46,11 +51,28 @@ type BasicAgent struct {
 	Client          openai.Client
 	Params          openai.ChatCompletionNewParams
 	EmbeddingParams openai.EmbeddingNewParams
+	ImageParams     openai.ImageGenerateParams
 	Name            string
 	Avatar          string
 	Color           string // used for UI display
 	Description     string
 	MetaData        any
+	logger          logging.Logger
+	checkpoints     CheckpointStore
+	// maxToolIterations limits the completions of the tool calls loops, unlimited if zero
+	maxToolIterations int
+	// usage and lastUsage are the cumulative usage and the one of the last completion (see UsageReporter)
+	usage         Usage
+	lastUsage     Usage
+	usageCallback func(call Usage, total Usage)
+	// retryPolicy retries the transient failures of the requests (see WithRetryPolicy)
+	retryPolicy RetryPolicy
+	middlewares []Middleware
+	memory      MemoryStrategy
+	// parallelToolCalls is the maximum number of tool calls executed at a time (see WithParallelToolExecution)
+	parallelToolCalls int
+	// toolTimeout limits the duration of each tool call, unlimited if zero (see WithToolTimeout)
+	toolTimeout time.Duration
 }
 
 // AgentOption is a functional option for configuring BasicAgent instances
@@ -167,6 +189,36 @@ func WithEmbeddingParams(embeddingParams openai.EmbeddingNewParams) AgentOption
 	}
 }
 
+// WithImageParams sets the image generation parameters of the agent (model, size, quality...) used by GenerateImage
+func WithImageParams(imageParams openai.ImageGenerateParams) AgentOption {
+	return func(a *BasicAgent) {
+		a.ImageParams = imageParams
+	}
+}
+
+// WithLogger sets the logger of the agent (logging.Default() otherwise).
+// The requests to the model and their responses are logged at the debug level, with the secrets masked.
+func WithLogger(logger logging.Logger) AgentOption {
+	return func(a *BasicAgent) {
+		a.logger = logger
+	}
+}
+
+// log returns the logger of the agent
+func (agent *BasicAgent) log() logging.Logger {
+	return logging.OrDefault(agent.logger)
+}
+
+// requestOptions returns the options of the requests of the agent: the debug logging of the requests,
+// without the retries of the client when the agent has a retry policy
+func (agent *BasicAgent) requestOptions() []option.RequestOption {
+	options := []option.RequestOption{option.WithMiddleware(logging.Middleware(agent.log()))}
+	if agent.retryPolicy.MaxRetries > 0 {
+		options = append(options, option.WithMaxRetries(0))
+	}
+	return options
+}
+
 
 // GetResponseFormat returns the response format from the agent's parameters
 func (agent *BasicAgent) GetResponseFormat() openai.ChatCompletionNewParamsResponseFormatUnion {
@@ -178,6 +230,16 @@ func (agent *BasicAgent) SetResponseFormat(format openai.ChatCompletionNewParams
 	agent.Params.ResponseFormat = format
 }
 
+// GetTools returns the tools from the agent's parameters
+func (agent *BasicAgent) GetTools() []openai.ChatCompletionToolUnionParam {
+	return agent.Params.Tools
+}
+
+// SetTools sets the tools in the agent's parameters
+func (agent *BasicAgent) SetTools(tools []openai.ChatCompletionToolUnionParam) {
+	agent.Params.Tools = tools
+}
+
 // GetName returns the name of the agent
 func (agent *BasicAgent) GetName() string {
 	return agent.Name
@@ -0,0 +1,303 @@
+package mu
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ErrCheckpointNotFound is returned by the checkpoint stores when a run has no checkpoint
+var ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+// CheckpointStatus is the status of a checkpointed run
+type CheckpointStatus string
+
+const (
+	CheckpointRunning   CheckpointStatus = "running"
+	CheckpointCompleted CheckpointStatus = "completed"
+)
+
+// PendingToolCall is a tool call requested by the model and not executed yet
+type PendingToolCall struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// Checkpoint is the state of a tool calls loop after a step: a completion of the model or the execution of a tool call
+type Checkpoint struct {
+	RunID  string           `json:"run_id"`
+	Agent  string           `json:"agent"`
+	Status CheckpointStatus `json:"status"`
+	// Step is the number of steps saved
+	Step     int                                      `json:"step"`
+	Messages []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	// PendingToolCalls are the tool calls of the last completion still to execute, in order
+	PendingToolCalls     []PendingToolCall `json:"pending_tool_calls,omitempty"`
+	Results              []string          `json:"results"`
+	FinishReason         string            `json:"finish_reason,omitempty"`
+	LastAssistantMessage string            `json:"last_assistant_message,omitempty"`
+	UpdatedAt            time.Time         `json:"updated_at"`
+}
+
+// Clone returns a copy of the checkpoint
+func (checkpoint *Checkpoint) Clone() *Checkpoint {
+	clone := *checkpoint
+	clone.Messages = append([]openai.ChatCompletionMessageParamUnion(nil), checkpoint.Messages...)
+	clone.PendingToolCalls = append([]PendingToolCall(nil), checkpoint.PendingToolCalls...)
+	clone.Results = append([]string{}, checkpoint.Results...)
+	return &clone
+}
+
+// CheckpointStore keeps the checkpoints of the runs
+type CheckpointStore interface {
+	// Load returns the checkpoint of a run, ErrCheckpointNotFound if it doesn't exist
+	Load(runID string) (*Checkpoint, error)
+	// Save creates or replaces the checkpoint of a run
+	Save(checkpoint *Checkpoint) error
+	// Delete removes the checkpoint of a run (no error if it doesn't exist)
+	Delete(runID string) error
+	// List returns all the checkpoints, the most recently updated first (e.g. to resume the running ones after a restart)
+	List() ([]*Checkpoint, error)
+}
+
+// Checkpointer is implemented by the agents able to checkpoint their tool calls loop (BasicAgent with a checkpoint store)
+type Checkpointer interface {
+	DetectToolCallsWithCheckpoint(runID string, messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error)
+	Resume(runID string, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error)
+}
+
+// WithCheckpointStore sets the store of the checkpoints of DetectToolCallsWithCheckpoint and Resume
+func WithCheckpointStore(store CheckpointStore) AgentOption {
+	return func(a *BasicAgent) {
+		a.checkpoints = store
+	}
+}
+
+// DetectToolCallsWithCheckpoint works like DetectToolCalls, and saves the state of the loop in the checkpoint store
+// of the agent after each step (completion or tool call), under the run ID. If the program stops during the run,
+// Resume continues it from its last checkpoint: a tool call interrupted before its checkpoint is executed again.
+// The checkpoint of a completed run is kept (with the completed status) until it is deleted from the store.
+//
+// Example usage:
+//
+//	agent, _ := mu.NewAgent(ctx, "Bob", mu.WithClient(client), mu.WithParams(params),
+//	  mu.WithCheckpointStore(mu.NewFileCheckpointStore(".checkpoints")))
+//	checkpointer := agent.(mu.Checkpointer)
+//	finishReason, results, answer, err := checkpointer.Resume("report-42", executeFn)
+//	if errors.Is(err, mu.ErrCheckpointNotFound) {
+//	  finishReason, results, answer, err = checkpointer.DetectToolCallsWithCheckpoint("report-42", messages, executeFn)
+//	}
+func (agent *BasicAgent) DetectToolCallsWithCheckpoint(runID string, messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error) {
+	if agent.checkpoints == nil {
+		return "", nil, "", errors.New("the agent has no checkpoint store")
+	}
+	state := &Checkpoint{
+		RunID:    runID,
+		Agent:    agent.Name,
+		Status:   CheckpointRunning,
+		Messages: messages,
+		Results:  []string{},
+	}
+	if err := agent.saveCheckpoint(state); err != nil {
+		return "", state.Results, "", err
+	}
+	return agent.detectToolCalls(agent.ctx, state, withoutToolContext(toolCallBack), agent.saveCheckpoint)
+}
+
+// Resume continues a run of DetectToolCallsWithCheckpoint from its last checkpoint: the pending tool calls are
+// executed, then the loop goes on. The result of a completed run is returned without calling the model.
+func (agent *BasicAgent) Resume(runID string, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error) {
+	if agent.checkpoints == nil {
+		return "", nil, "", errors.New("the agent has no checkpoint store")
+	}
+	state, err := agent.checkpoints.Load(runID)
+	if err != nil {
+		return "", nil, "", err
+	}
+	if state.Results == nil {
+		state.Results = []string{}
+	}
+	agent.log().Debug("resuming run", "agent", agent.Name, "run", runID, "step", state.Step, "pending_tool_calls", len(state.PendingToolCalls))
+	return agent.detectToolCalls(agent.ctx, state, withoutToolContext(toolCallBack), agent.saveCheckpoint)
+}
+
+// saveCheckpoint saves a step of a run in the checkpoint store
+func (agent *BasicAgent) saveCheckpoint(state *Checkpoint) error {
+	state.UpdatedAt = time.Now()
+	if err := agent.checkpoints.Save(state); err != nil {
+		return fmt.Errorf("checkpoint of the run %s: %w", state.RunID, err)
+	}
+	return nil
+}
+
+// validateRunID returns an error if the run ID is empty or can't be used as a file name
+func validateRunID(runID string) error {
+	if runID == "" {
+		return errors.New("the run ID is empty")
+	}
+	if runID == "." || runID == ".." || strings.ContainsAny(runID, `/\`) || strings.ContainsRune(runID, 0) {
+		return fmt.Errorf("invalid run ID %q", runID)
+	}
+	return nil
+}
+
+// sortCheckpoints sorts the checkpoints, the most recently updated first
+func sortCheckpoints(checkpoints []*Checkpoint) {
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		return checkpoints[i].UpdatedAt.After(checkpoints[j].UpdatedAt)
+	})
+}
+
+// MemoryCheckpointStore keeps the checkpoints in memory (they survive the errors of a run, not the program)
+type MemoryCheckpointStore struct {
+	mutex       sync.RWMutex
+	checkpoints map[string]*Checkpoint
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{checkpoints: map[string]*Checkpoint{}}
+}
+
+// Load returns a copy of the checkpoint of a run
+func (store *MemoryCheckpointStore) Load(runID string) (*Checkpoint, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	checkpoint, ok := store.checkpoints[runID]
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+	return checkpoint.Clone(), nil
+}
+
+// Save keeps a copy of the checkpoint
+func (store *MemoryCheckpointStore) Save(checkpoint *Checkpoint) error {
+	if err := validateRunID(checkpoint.RunID); err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.checkpoints[checkpoint.RunID] = checkpoint.Clone()
+	return nil
+}
+
+// Delete removes the checkpoint of a run
+func (store *MemoryCheckpointStore) Delete(runID string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.checkpoints, runID)
+	return nil
+}
+
+// List returns copies of the checkpoints, the most recently updated first
+func (store *MemoryCheckpointStore) List() ([]*Checkpoint, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	checkpoints := make([]*Checkpoint, 0, len(store.checkpoints))
+	for _, checkpoint := range store.checkpoints {
+		checkpoints = append(checkpoints, checkpoint.Clone())
+	}
+	sortCheckpoints(checkpoints)
+	return checkpoints, nil
+}
+
+// FileCheckpointStore keeps the checkpoint of each run in a JSON file <directory>/<run ID>.json
+type FileCheckpointStore struct {
+	directory string
+}
+
+// NewFileCheckpointStore creates a checkpoint store of JSON files, the directory is created on the first save
+func NewFileCheckpointStore(directory string) *FileCheckpointStore {
+	return &FileCheckpointStore{directory: directory}
+}
+
+// path returns the file of the checkpoint of a run
+func (store *FileCheckpointStore) path(runID string) string {
+	return filepath.Join(store.directory, runID+".json")
+}
+
+// Load reads the checkpoint file of a run
+func (store *FileCheckpointStore) Load(runID string) (*Checkpoint, error) {
+	if err := validateRunID(runID); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(store.path(runID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCheckpointNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := &Checkpoint{}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint file %s: %w", store.path(runID), err)
+	}
+	if checkpoint.RunID == "" {
+		checkpoint.RunID = runID
+	}
+	return checkpoint, nil
+}
+
+// Save writes the checkpoint file of a run
+func (store *FileCheckpointStore) Save(checkpoint *Checkpoint) error {
+	if err := validateRunID(checkpoint.RunID); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(store.directory, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash never leaves a truncated checkpoint
+	tmpPath := store.path(checkpoint.RunID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path(checkpoint.RunID))
+}
+
+// Delete removes the checkpoint file of a run
+func (store *FileCheckpointStore) Delete(runID string) error {
+	if err := validateRunID(runID); err != nil {
+		return err
+	}
+	if err := os.Remove(store.path(runID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List reads all the checkpoint files of the directory (the invalid files are skipped)
+func (store *FileCheckpointStore) List() ([]*Checkpoint, error) {
+	entries, err := os.ReadDir(store.directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Checkpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := []*Checkpoint{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		checkpoint, err := store.Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	sortCheckpoints(checkpoints)
+	return checkpoints, nil
+}
@@ -0,0 +1,34 @@
+package mu
+
+import (
+	"context"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ContextRunner is implemented by the agents whose calls take their own context (BasicAgent): the methods of
+// Agent use the context given to NewAgent, the Context variants a context per call, so a completion, a stream
+// or a tool calls loop can be cancelled (or given a deadline) without recreating the agent.
+// A cancelled call returns the error of the context (context.Canceled or context.DeadlineExceeded, test them
+// with errors.Is), with what was received or executed so far.
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+//	defer cancel()
+//	answer, err := agent.(mu.ContextRunner).RunStreamContext(ctx, messages, func(content string) error {
+//	  fmt.Print(content)
+//	  return nil
+//	})
+//	if errors.Is(err, context.DeadlineExceeded) {
+//	  fmt.Println("\n⏱️ interrupted:", answer)
+//	}
+type ContextRunner interface {
+	RunContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion) (string, error)
+	RunStreamContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion, callBack func(content string) error) (string, error)
+	RunWithReasoningContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion) (string, string, error)
+	RunStreamWithReasoningContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion, contentCallback func(content string) error, reasoningCallback func(reasoning string) error) (string, string, error)
+	DetectToolCallsContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error)
+	DetectToolCallsStreamContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error), streamCallback func(content string) error) (string, []string, string, error)
+	GenerateEmbeddingVectorContext(ctx context.Context, content string) ([]float64, error)
+}
@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -23,119 +24,179 @@ import (
 //   - lastAssistantMessage: The final message from the assistant when conversation ends normally
 //   - error: Any error that occurred during processing
 func (agent *BasicAgent) DetectToolCalls(messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error) {
+	return agent.DetectToolCallsContext(agent.ctx, messages, toolCallBack)
+}
 
-	stopped := false
-	results := []string{}
-	lastAssistantMessage := ""
-	finishReason := ""
+// DetectToolCallsContext is DetectToolCalls with the context of the call: it is checked before each completion
+// and each tool call, a cancelled loop returns the results so far with the error of the context (see ContextRunner)
+func (agent *BasicAgent) DetectToolCallsContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error) {
+	return agent.DetectToolCallsWithToolContext(ctx, messages, withoutToolContext(toolCallBack))
+}
 
-	for !stopped {
-		// TOOL: Make a function call request
-		//fmt.Println("⏳ Making function call request...")
+// DetectToolCallsWithToolContext is DetectToolCallsContext with a tool callback receiving the context of
+// each tool call, cancelled with the context of the loop or at the tool timeout (see ToolContextRunner)
+func (agent *BasicAgent) DetectToolCallsWithToolContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallBack ToolCallbackContext) (string, []string, string, error) {
+	state := &Checkpoint{Messages: messages, Results: []string{}}
+	return agent.detectToolCalls(ctx, state, toolCallBack, nil)
+}
 
-		agent.Params.Messages = messages
+// detectToolCalls runs the tool calls loop from a state, save (if not nil) is called with the state after each step:
+// a completion, whose tool calls become pending, or the execution of a pending tool call
+func (agent *BasicAgent) detectToolCalls(ctx context.Context, state *Checkpoint, toolCallBack ToolCallbackContext, save func(state *Checkpoint) error) (string, []string, string, error) {
 
-		completion, err := agent.Client.Chat.Completions.New(agent.ctx, agent.Params)
-		if err != nil {
-			return "", results, "", err
-			//return nil, errors.New("error making function call request [completion]")
+	checkpoint := func() error {
+		if save == nil {
+			return nil
 		}
+		state.Step++
+		return save(state)
+	}
+	stopped := state.Status == CheckpointCompleted
+	maxIterations := agent.maxIterations(ctx)
+	iterations := 0
 
-		finishReason = completion.Choices[0].FinishReason
+	for !stopped {
+		if len(state.PendingToolCalls) == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", state.Results, "", err
+			}
+			if maxIterations > 0 && iterations >= maxIterations {
+				// The tool exchanges are kept for the caller to recover
+				agent.Params.Messages = state.Messages
+				agent.log().Warn("too many tool calls iterations", "agent", agent.Name, "max_iterations", maxIterations)
+				return "max_iterations", state.Results, "", &MaxIterationsExceededError{MaxIterations: maxIterations}
+			}
+			iterations++
+			agent.Params.Messages = state.Messages
 
-		// Extract reasoning_content from RawJSON
-		// completion.Choices[0].Message.RawJSON()
+			completion, err := agent.complete(ctx)
+			if err != nil {
+				return "", state.Results, "", err
+				//return nil, errors.New("error making function call request [completion]")
+			}
 
-		switch finishReason {
-		case "tool_calls":
-			detectedToolCalls := completion.Choices[0].Message.ToolCalls
+			state.FinishReason = completion.Choices[0].FinishReason
 
-			if len(detectedToolCalls) > 0 {
+			// Extract reasoning_content from RawJSON
+			// completion.Choices[0].Message.RawJSON()
 
-				toolCallParams := make([]openai.ChatCompletionMessageToolCallUnionParam, len(detectedToolCalls))
-				for i, toolCall := range detectedToolCalls {
-					toolCallParams[i] = openai.ChatCompletionMessageToolCallUnionParam{
-						OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
-							ID:   toolCall.ID,
-							Type: constant.Function("function"),
-							Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
-								Name:      toolCall.Function.Name,
-								Arguments: toolCall.Function.Arguments,
+			switch state.FinishReason {
+			case "tool_calls":
+				detectedToolCalls := completion.Choices[0].Message.ToolCalls
+
+				if len(detectedToolCalls) > 0 {
+
+					toolCallParams := make([]openai.ChatCompletionMessageToolCallUnionParam, len(detectedToolCalls))
+					for i, toolCall := range detectedToolCalls {
+						toolCallParams[i] = openai.ChatCompletionMessageToolCallUnionParam{
+							OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+								ID:   toolCall.ID,
+								Type: constant.Function("function"),
+								Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+									Name:      toolCall.Function.Name,
+									Arguments: toolCall.Function.Arguments,
+								},
 							},
+						}
+						state.PendingToolCalls = append(state.PendingToolCalls, PendingToolCall{
+							ID:        toolCall.ID,
+							Name:      toolCall.Function.Name,
+							Arguments: toolCall.Function.Arguments,
+						})
+					}
+
+					// Create assistant message with tool calls using proper union type
+					assistantMessage := openai.ChatCompletionMessageParamUnion{
+						OfAssistant: &openai.ChatCompletionAssistantMessageParam{
+							ToolCalls: toolCallParams,
 						},
 					}
-				}
 
-				// Create assistant message with tool calls using proper union type
-				assistantMessage := openai.ChatCompletionMessageParamUnion{
-					OfAssistant: &openai.ChatCompletionAssistantMessageParam{
-						ToolCalls: toolCallParams,
-					},
-				}
+					// Add the assistant message with tool calls to the conversation history
+					state.Messages = append(state.Messages, assistantMessage)
 
-				// Add the assistant message with tool calls to the conversation history
-				messages = append(messages, assistantMessage)
+				} else {
+					agent.log().Warn("no tool calls found in the response", "agent", agent.Name)
+				}
 
-				// TOOL: Process each detected tool call
-				//fmt.Println("🚀 Processing tool calls...")
+			case "stop":
+				stopped = true
+				state.LastAssistantMessage = completion.Choices[0].Message.Content
 
-				for _, toolCall := range detectedToolCalls {
-					functionName := toolCall.Function.Name
-					functionArgs := toolCall.Function.Arguments
-					//callID := toolCall.ID
+				// Add final assistant message to conversation history
+				state.Messages = append(state.Messages, openai.AssistantMessage(state.LastAssistantMessage))
 
-					// TOOL: Execute the function with the provided arguments
-					//fmt.Printf("▶️ Executing function: %s with args: %s\n", functionName, functionArgs)
+			default:
+				agent.log().Debug("unexpected finish reason", "agent", agent.Name, "finish_reason", state.FinishReason)
+				stopped = true
+			}
 
-					resultContent, errExec := toolCallBack(functionName, functionArgs)
+			if stopped {
+				state.Status = CheckpointCompleted
+			}
+			if err := checkpoint(); err != nil {
+				return "", state.Results, "", err
+			}
+		}
 
-					if errExec != nil {
-						//fmt.Printf("🔴 Error executing function %s: %s\n", functionName, errExec.Error())
-						var exitErr *ExitToolCallsLoopError
-						if errors.As(errExec, &exitErr) {
-							// If the error is an ExitLoopError, we stop processing further tool calls
-							stopped = true
-							finishReason = "exit_loop"
-						} else {
-							resultContent = fmt.Sprintf(`{"error": "Function execution failed: %s"}`, errExec.Error())
-						}
-					}
-					if resultContent == "" {
-						resultContent = `{"error": "Function execution returned empty result"}`
-					}
-					results = append(results, resultContent)
-
-					//fmt.Printf("Function result: %s with CallID: %s\n\n", resultContent, callID)
-
-					// Add the tool call result to the conversation history
-					messages = append(
-						messages,
-						openai.ToolMessage(
-							resultContent,
-							toolCall.ID,
-						),
-					)
-				}
+		// The parallel tool calls are all executed first, their results are added one by one
+		var outcomes []toolCallOutcome
+		if agent.parallelToolExecution(state.PendingToolCalls) {
+			outcomes = agent.executeToolCallsInParallel(ctx, state.PendingToolCalls, toolCallBack)
+		}
 
+		for idx := 0; len(state.PendingToolCalls) > 0; idx++ {
+			// The pending tool calls stay in the state (a checkpointed run resumes with them)
+			if err := ctx.Err(); err != nil && (outcomes == nil || !outcomes[idx].started) {
+				return "", state.Results, "", err
+			}
+			toolCall := state.PendingToolCalls[0]
+			functionName := toolCall.Name
+
+			// TOOL: Execute the function with the provided arguments
+			var resultContent string
+			var errExec error
+			if outcomes != nil {
+				resultContent, errExec = outcomes[idx].result, outcomes[idx].err
 			} else {
-				// TODO: Handle case where no tool calls were detected
-				fmt.Println("😢 No tool calls found in response")
+				resultContent, errExec = agent.executeToolCall(ctx, toolCall, toolCallBack)
 			}
 
-		case "stop":
-			//fmt.Println("🟥 Stopping due to 'stop' finish reason.")
-			stopped = true
-			lastAssistantMessage = completion.Choices[0].Message.Content
-			//fmt.Printf("🤖 %s\n", lastAssistantMessage)
-
-			// Add final assistant message to conversation history
-			messages = append(messages, openai.AssistantMessage(lastAssistantMessage))
-
-		default:
-			//fmt.Printf("🔴 Unexpected response: %s\n", finishReason)
-			stopped = true
+			if errExec != nil {
+				agent.log().Debug("tool call failed", "agent", agent.Name, "function", functionName, "error", errExec)
+				var exitErr *ExitToolCallsLoopError
+				if errors.As(errExec, &exitErr) {
+					// If the error is an ExitLoopError, we stop processing further tool calls
+					state.FinishReason = "exit_loop"
+				} else {
+					resultContent = fmt.Sprintf(`{"error": "Function execution failed: %s"}`, errExec.Error())
+				}
+			}
+			if resultContent == "" {
+				resultContent = `{"error": "Function execution returned empty result"}`
+			}
+			state.Results = append(state.Results, resultContent)
+
+			// Add the tool call result to the conversation history
+			state.Messages = append(
+				state.Messages,
+				openai.ToolMessage(
+					resultContent,
+					toolCall.ID,
+				),
+			)
+			state.PendingToolCalls = state.PendingToolCalls[1:]
+
+			// The remaining tool calls of the completion are executed before leaving the loop
+			if len(state.PendingToolCalls) == 0 && state.FinishReason == "exit_loop" {
+				stopped = true
+				state.Status = CheckpointCompleted
+			}
+			if err := checkpoint(); err != nil {
+				return "", state.Results, "", err
+			}
 		}
 
 	}
-	return finishReason, results, lastAssistantMessage, nil
+	return state.FinishReason, state.Results, state.LastAssistantMessage, nil
 }
@@ -0,0 +1,45 @@
+package mu
+
+import "context"
+
+// maxToolIterationsKey is the key of the per-call maximum number of iterations in a context
+type maxToolIterationsKey struct{}
+
+// WithMaxToolIterations limits the number of completions of the tool calls loops (DetectToolCalls,
+// DetectToolCallsStream and their variants): when the model still calls tools after maxIterations completions,
+// the loop stops with the "max_iterations" finish reason and a MaxIterationsExceededError.
+// The loops are unlimited if maxIterations is zero (the default).
+//
+// Example usage:
+//
+//	agent, err := mu.NewAgent(ctx, "Bob", mu.WithClient(client), mu.WithParams(params), mu.WithMaxToolIterations(10))
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, executeFn)
+//	var maxErr *mu.MaxIterationsExceededError
+//	if errors.As(err, &maxErr) {
+//	  answer, err = agent.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage("Answer with what you have")})
+//	}
+func WithMaxToolIterations(maxIterations int) AgentOption {
+	return func(a *BasicAgent) {
+		a.maxToolIterations = maxIterations
+	}
+}
+
+// ContextWithMaxToolIterations overrides the maximum number of iterations of the agent for the calls
+// taking the returned context (see ContextRunner), unlimited if maxIterations is zero
+//
+// Example usage:
+//
+//	ctx := mu.ContextWithMaxToolIterations(ctx, 3)
+//	finishReason, results, answer, err := agent.(mu.ContextRunner).DetectToolCallsContext(ctx, messages, executeFn)
+func ContextWithMaxToolIterations(ctx context.Context, maxIterations int) context.Context {
+	return context.WithValue(ctx, maxToolIterationsKey{}, maxIterations)
+}
+
+// maxIterations returns the maximum number of iterations of a tool calls loop: the one of the context if any,
+// the one of the agent otherwise
+func (agent *BasicAgent) maxIterations(ctx context.Context) int {
+	if maxIterations, ok := ctx.Value(maxToolIterationsKey{}).(int); ok {
+		return maxIterations
+	}
+	return agent.maxToolIterations
+}
@@ -0,0 +1,63 @@
+package mu
+
+import (
+	"context"
+	"sync"
+)
+
+// WithParallelToolExecution executes the tool calls of a completion concurrently in the tool calls loops
+// (DetectToolCalls, DetectToolCallsStream and their variants), with at most maxConcurrent calls at a time.
+// The results are added to the messages in the order of the calls, whatever the order they end in.
+// The tool callback, and the OnToolCall hooks of the middlewares, must be safe for concurrent use.
+// The calls are sequential if maxConcurrent is 0 or 1 (the default).
+//
+// Example usage:
+//
+//	agent, err := mu.NewAgent(ctx, "Bob",
+//	  mu.WithClient(client),
+//	  mu.WithParams(params),
+//	  mu.WithParallelToolExecution(4),
+//	)
+func WithParallelToolExecution(maxConcurrent int) AgentOption {
+	return func(a *BasicAgent) {
+		a.parallelToolCalls = maxConcurrent
+	}
+}
+
+// toolCallOutcome is the outcome of a tool call executed in parallel
+type toolCallOutcome struct {
+	result  string
+	err     error
+	started bool
+}
+
+// parallelToolExecution reports whether the tool calls of a completion are executed in parallel
+func (agent *BasicAgent) parallelToolExecution(toolCalls []PendingToolCall) bool {
+	return agent.parallelToolCalls > 1 && len(toolCalls) > 1
+}
+
+// executeToolCallsInParallel executes tool calls with at most parallelToolCalls at a time, the outcomes are
+// in the order of the calls; the calls not started when the context is cancelled are left unstarted
+func (agent *BasicAgent) executeToolCallsInParallel(ctx context.Context, toolCalls []PendingToolCall, toolCallBack ToolCallbackContext) []toolCallOutcome {
+	outcomes := make([]toolCallOutcome, len(toolCalls))
+	slots := make(chan struct{}, agent.parallelToolCalls)
+	var wg sync.WaitGroup
+	for idx, toolCall := range toolCalls {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			result, err := agent.executeToolCall(ctx, toolCall, toolCallBack)
+			outcomes[idx] = toolCallOutcome{result: result, err: err, started: true}
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
@@ -0,0 +1,70 @@
+package mu
+
+import (
+	"fmt"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// toolCallsAccumulator rebuilds the content, the tool calls and the finish reason of a completion from
+// the deltas of its stream
+type toolCallsAccumulator struct {
+	content      string
+	toolCalls    []PendingToolCall
+	finishReason string
+	// positions gives the position in toolCalls of the tool call of a delta index
+	positions map[int64]int
+}
+
+func newToolCallsAccumulator() *toolCallsAccumulator {
+	return &toolCallsAccumulator{positions: map[int64]int{}}
+}
+
+// addChoice adds the delta of a chunk
+func (accumulator *toolCallsAccumulator) addChoice(choice openai.ChatCompletionChunkChoice) {
+	accumulator.content += choice.Delta.Content
+	for _, delta := range choice.Delta.ToolCalls {
+		accumulator.addToolCall(delta)
+	}
+	// The last chunks can have no finish reason (e.g. the usage chunk)
+	if choice.FinishReason != "" {
+		accumulator.finishReason = choice.FinishReason
+	}
+}
+
+// addToolCall adds a tool call delta: the first delta of a tool call has its ID and name, the next ones
+// the following parts of its arguments
+func (accumulator *toolCallsAccumulator) addToolCall(delta openai.ChatCompletionChunkChoiceDeltaToolCall) {
+	position, found := accumulator.positions[delta.Index]
+	// Some servers send each complete tool call with the same index, a new ID starts a new tool call
+	if !found || (delta.ID != "" && accumulator.toolCalls[position].ID != "" && accumulator.toolCalls[position].ID != delta.ID) {
+		accumulator.toolCalls = append(accumulator.toolCalls, PendingToolCall{})
+		position = len(accumulator.toolCalls) - 1
+		accumulator.positions[delta.Index] = position
+	}
+	toolCall := &accumulator.toolCalls[position]
+	if delta.ID != "" {
+		toolCall.ID = delta.ID
+	}
+	toolCall.Name += delta.Function.Name
+	toolCall.Arguments += delta.Function.Arguments
+}
+
+// finish completes the tool calls and returns the finish reason of the completion: "tool_calls" when
+// the stream has tool calls, whatever the finish reason sent by the server (some send "stop")
+func (accumulator *toolCallsAccumulator) finish() string {
+	for idx := range accumulator.toolCalls {
+		toolCall := &accumulator.toolCalls[idx]
+		// The tool messages answer the tool calls by ID
+		if toolCall.ID == "" {
+			toolCall.ID = fmt.Sprintf("call_%d", idx)
+		}
+		if toolCall.Arguments == "" {
+			toolCall.Arguments = "{}"
+		}
+	}
+	if len(accumulator.toolCalls) > 0 && (accumulator.finishReason == "stop" || accumulator.finishReason == "") {
+		return "tool_calls"
+	}
+	return accumulator.finishReason
+}
@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -11,6 +12,7 @@ import (
 // DetectToolCallsStream processes a conversation with tool calls support using streaming.
 // It handles the complete tool calling workflow with real-time streaming of assistant responses,
 // detecting tool calls, executing them via callback, and managing the conversation history until completion.
+// Each iteration makes a single streaming request: the tool calls are accumulated from the deltas of the stream.
 //
 // Parameters:
 //   - messages: Initial conversation messages to start with
@@ -24,16 +26,42 @@ import (
 //   - lastAssistantMessage: The final message from the assistant when conversation ends normally
 //   - error: Any error that occurred during processing
 func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error), streamCallback func(content string) error) (string, []string, string, error) {
+	return agent.DetectToolCallsStreamContext(agent.ctx, messages, toolCallback, streamCallback)
+}
+
+// DetectToolCallsStreamContext is DetectToolCallsStream with the context of the call: its cancellation stops
+// the current stream, and it is checked before each completion and each tool call; a cancelled loop returns
+// the results so far with the error of the context (see ContextRunner)
+func (agent *BasicAgent) DetectToolCallsStreamContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error), streamCallback func(content string) error) (string, []string, string, error) {
+	return agent.DetectToolCallsStreamWithToolContext(ctx, messages, withoutToolContext(toolCallback), streamCallback)
+}
+
+// DetectToolCallsStreamWithToolContext is DetectToolCallsStreamContext with a tool callback receiving the context
+// of each tool call, cancelled with the context of the loop or at the tool timeout (see ToolContextRunner)
+func (agent *BasicAgent) DetectToolCallsStreamWithToolContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallback ToolCallbackContext, streamCallback func(content string) error) (string, []string, string, error) {
 	stopped := false
 	results := []string{}
 	lastAssistantMessage := ""
 	finishReason := ""
+	maxIterations := agent.maxIterations(ctx)
 
-	for !stopped {
+	for iterations := 0; !stopped; iterations++ {
+		if err := ctx.Err(); err != nil {
+			return "", results, "", err
+		}
+		if maxIterations > 0 && iterations >= maxIterations {
+			// The tool exchanges are kept for the caller to recover
+			agent.Params.Messages = messages
+			agent.log().Warn("too many tool calls iterations", "agent", agent.Name, "max_iterations", maxIterations)
+			return "max_iterations", results, "", &MaxIterationsExceededError{MaxIterations: maxIterations}
+		}
 		agent.Params.Messages = messages
 
-		stream := agent.Client.Chat.Completions.NewStreaming(agent.ctx, agent.Params)
-		var response string
+		stream, err := agent.newStream(ctx)
+		if err != nil {
+			return "", results, "", err
+		}
+		// The content is streamed, the tool calls are accumulated by the stream from its deltas
 		var cbkRes error
 
 		for stream.Next() {
@@ -41,13 +69,8 @@ func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionM
 			// Stream each chunk as it arrives
 			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
 				cbkRes = streamCallback(chunk.Choices[0].Delta.Content)
-				response += chunk.Choices[0].Delta.Content
 			}
 
-			// if cbkRes != nil {
-			// 	break
-			// }
-
 			if cbkRes != nil {
 				var exitErr *ExitStreamCompletionError
 				if errors.As(cbkRes, &exitErr) {
@@ -58,26 +81,27 @@ func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionM
 		}
 
 		if cbkRes != nil {
+			stream.Close()
 			return "", results, "", cbkRes
 		}
-		if err := stream.Err(); err != nil {
+		if err := ctx.Err(); err != nil {
+			stream.Close()
 			return "", results, "", err
 		}
-		if err := stream.Close(); err != nil {
+		if err := stream.Err(); err != nil {
+			stream.Close()
 			return "", results, "", err
 		}
-
-		// Make a non-streaming call to get tool calls (streaming doesn't provide tool calls properly)
-		completion, err := agent.Client.Chat.Completions.New(agent.ctx, agent.Params)
-		if err != nil {
+		if err := stream.Close(); err != nil {
 			return "", results, "", err
 		}
 
-		finishReason = completion.Choices[0].FinishReason
+		response := stream.accumulator.content
+		finishReason = stream.accumulator.finish()
 
 		switch finishReason {
 		case "tool_calls":
-			detectedToolCalls := completion.Choices[0].Message.ToolCalls
+			detectedToolCalls := stream.accumulator.toolCalls
 
 			if len(detectedToolCalls) > 0 {
 				toolCallParams := make([]openai.ChatCompletionMessageToolCallUnionParam, len(detectedToolCalls))
@@ -87,31 +111,46 @@ func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionM
 							ID:   toolCall.ID,
 							Type: constant.Function("function"),
 							Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
-								Name:      toolCall.Function.Name,
-								Arguments: toolCall.Function.Arguments,
+								Name:      toolCall.Name,
+								Arguments: toolCall.Arguments,
 							},
 						},
 					}
 				}
 
-				// Create assistant message with tool calls
+				// Create assistant message with tool calls, and the text streamed before them
 				assistantMessage := openai.ChatCompletionMessageParamUnion{
 					OfAssistant: &openai.ChatCompletionAssistantMessageParam{
 						ToolCalls: toolCallParams,
 					},
 				}
+				if response != "" {
+					assistantMessage.OfAssistant.Content.OfString = openai.String(response)
+				}
 
 				messages = append(messages, assistantMessage)
 
-				// Execute each tool call
-				for _, toolCall := range detectedToolCalls {
-					functionName := toolCall.Function.Name
-					functionArgs := toolCall.Function.Arguments
-
-					resultContent, errExec := toolCallback(functionName, functionArgs)
+				// Execute each tool call, all at once with the parallel tool execution
+				var outcomes []toolCallOutcome
+				if agent.parallelToolExecution(detectedToolCalls) {
+					outcomes = agent.executeToolCallsInParallel(ctx, detectedToolCalls, toolCallback)
+				}
+				for idx, toolCall := range detectedToolCalls {
+					if err := ctx.Err(); err != nil && (outcomes == nil || !outcomes[idx].started) {
+						return "", results, "", err
+					}
+					functionName := toolCall.Name
+
+					var resultContent string
+					var errExec error
+					if outcomes != nil {
+						resultContent, errExec = outcomes[idx].result, outcomes[idx].err
+					} else {
+						resultContent, errExec = agent.executeToolCall(ctx, toolCall, toolCallback)
+					}
 
 					if errExec != nil {
-						//fmt.Printf("🔴 Error executing function %s: %s\n", functionName, errExec.Error())
+						agent.log().Debug("tool call failed", "agent", agent.Name, "function", functionName, "error", errExec)
 						var exitErr *ExitToolCallsLoopError
 						if errors.As(errExec, &exitErr) {
 							// If the error is an ExitLoopError, we stop processing further tool calls
@@ -138,7 +177,7 @@ func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionM
 				}
 
 			} else {
-				fmt.Println("😢 No tool calls found in response")
+				agent.log().Warn("no tool calls found in the response", "agent", agent.Name)
 			}
 
 		case "stop":
@@ -149,6 +188,7 @@ func (agent *BasicAgent) DetectToolCallsStream(messages []openai.ChatCompletionM
 			messages = append(messages, openai.AssistantMessage(lastAssistantMessage))
 
 		default:
+			agent.log().Debug("unexpected finish reason", "agent", agent.Name, "finish_reason", finishReason)
 			stopped = true
 		}
 	}
@@ -0,0 +1,98 @@
+package mu
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ToolCallbackContext is a tool callback taking the context of the tool call: it is cancelled when the
+// tool calls loop is cancelled, or when the tool timeout (see WithToolTimeout) expires, so the tool can
+// stop its work (e.g. pass it to http.NewRequestWithContext or exec.CommandContext)
+type ToolCallbackContext func(ctx context.Context, functionName string, arguments string) (string, error)
+
+// ToolContextRunner is implemented by the agents whose tool calls loops give a context to the tool callback
+// (BasicAgent), the tools can then be cancelled instead of being abandoned in the background.
+//
+// Example usage:
+//
+//	finishReason, results, answer, err := agent.(mu.ToolContextRunner).DetectToolCallsWithToolContext(ctx, messages,
+//	  func(ctx context.Context, functionName string, arguments string) (string, error) {
+//	    return registry.CallToolContext(ctx, functionName, arguments)
+//	  },
+//	)
+type ToolContextRunner interface {
+	DetectToolCallsWithToolContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallBack ToolCallbackContext) (string, []string, string, error)
+	DetectToolCallsStreamWithToolContext(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion, toolCallback ToolCallbackContext, streamCallback func(content string) error) (string, []string, string, error)
+}
+
+var _ ToolContextRunner = (*BasicAgent)(nil)
+
+// WithToolTimeout limits the duration of each tool call of the tool calls loops (DetectToolCalls,
+// DetectToolCallsStream and their variants): each tool callback runs under a context with a deadline,
+// and a call which doesn't return within timeout gets a ToolTimeoutError result, sent to the model so it
+// can answer without it or try again. The callbacks of ToolContextRunner receive this context and are
+// cancelled; the callbacks without context can't be, they keep running in the background until they return.
+// The calls aren't limited if timeout is zero.
+//
+// Example usage:
+//
+//	agent, err := mu.NewAgent(ctx, "Bob",
+//	  mu.WithClient(client),
+//	  mu.WithParams(params),
+//	  mu.WithToolTimeout(30*time.Second),
+//	)
+func WithToolTimeout(timeout time.Duration) AgentOption {
+	return func(a *BasicAgent) {
+		a.toolTimeout = timeout
+	}
+}
+
+// withoutToolContext adapts a tool callback without context to ToolCallbackContext
+func withoutToolContext(toolCallBack func(functionName string, arguments string) (string, error)) ToolCallbackContext {
+	return func(_ context.Context, functionName string, arguments string) (string, error) {
+		return toolCallBack(functionName, arguments)
+	}
+}
+
+// callToolWithTimeout calls the tool callback under a context cancelled with ctx or at the tool timeout if any.
+// The call returns as soon as the context is done, even if the callback ignores it.
+func (agent *BasicAgent) callToolWithTimeout(ctx context.Context, toolCall PendingToolCall, toolCallBack ToolCallbackContext) (string, error) {
+	if agent.toolTimeout <= 0 {
+		return toolCallBack(ctx, toolCall.Name, toolCall.Arguments)
+	}
+	callCtx, cancel := context.WithTimeout(ctx, agent.toolTimeout)
+	defer cancel()
+
+	type toolCallReturn struct {
+		result string
+		err    error
+	}
+	returned := make(chan toolCallReturn, 1)
+	go func() {
+		result, err := toolCallBack(callCtx, toolCall.Name, toolCall.Arguments)
+		returned <- toolCallReturn{result: result, err: err}
+	}()
+
+	select {
+	case r := <-returned:
+		// A tool returning the error of its cancelled context timed out too
+		if r.err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return "", agent.toolTimeoutError(toolCall)
+		}
+		return r.result, r.err
+	case <-callCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		return "", agent.toolTimeoutError(toolCall)
+	}
+}
+
+// toolTimeoutError logs and returns the error of a tool call which timed out
+func (agent *BasicAgent) toolTimeoutError(toolCall PendingToolCall) error {
+	agent.log().Warn("tool call timed out", "agent", agent.Name, "function", toolCall.Name, "timeout", agent.toolTimeout)
+	return &ToolTimeoutError{Name: toolCall.Name, Timeout: agent.toolTimeout}
+}
@@ -1,9 +1,20 @@
 package mu
 
-import "github.com/openai/openai-go/v2"
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
 
 // GenerateEmbeddingVector creates a vector embedding for the given text content using the agent's embedding model
 func (agent *BasicAgent) GenerateEmbeddingVector(content string) ([]float64, error) {
+	return agent.GenerateEmbeddingVectorContext(agent.ctx, content)
+}
+
+// GenerateEmbeddingVectorContext is GenerateEmbeddingVector with the context of the call (see ContextRunner)
+func (agent *BasicAgent) GenerateEmbeddingVectorContext(ctx context.Context, content string) ([]float64, error) {
 	// Create embedding parameters using the agent's embedding parameters
 	// params := openai.EmbeddingNewParams{
 	// 	Model: agent.EmbeddingParams.Model,
@@ -15,11 +26,38 @@ func (agent *BasicAgent) GenerateEmbeddingVector(content string) ([]float64, err
 	agent.EmbeddingParams.Input = openai.EmbeddingNewParamsInputUnion{
 		OfString: openai.String(content),
 	}
+	params := agent.EmbeddingParams
+	request, err := agent.beforeCompletion(ctx, &CompletionRequest{Kind: CompletionEmbedding, EmbeddingParams: &params})
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
 	// Use the client to create embeddings
-	embeddingResponse, err := agent.Client.Embeddings.New(agent.ctx, agent.EmbeddingParams)
+	var embeddingResponse *openai.CreateEmbeddingResponse
+	err = agent.withRetries(ctx, func() (err error) {
+		embeddingResponse, err = agent.Client.Embeddings.New(ctx, *request.EmbeddingParams, agent.requestOptions()...)
+		return err
+	})
+	if err == nil && len(embeddingResponse.Data) == 0 {
+		err = errors.New("no embedding returned")
+	}
+	response := &CompletionResponse{Duration: time.Since(started), Err: err}
+	if err == nil {
+		response.Embedding = embeddingResponse.Data[0].Embedding
+		response.Usage = Usage{
+			PromptTokens: embeddingResponse.Usage.PromptTokens,
+			TotalTokens:  embeddingResponse.Usage.TotalTokens,
+			Requests:     1,
+		}
+		agent.recordUsage(openai.CompletionUsage{
+			PromptTokens: embeddingResponse.Usage.PromptTokens,
+			TotalTokens:  embeddingResponse.Usage.TotalTokens,
+		})
+	}
+	agent.afterCompletion(ctx, request, response)
 	if err != nil {
 		return nil, err
 	}
 
-	return  embeddingResponse.Data[0].Embedding, nil
+	return response.Embedding, nil
 }
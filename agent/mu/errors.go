@@ -1,6 +1,9 @@
 package mu
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // ExitToolCallsLoopError signals early termination of tool call processing loops
 type ExitToolCallsLoopError struct {
@@ -21,3 +24,47 @@ type ExitStreamCompletionError struct {
 func (e *ExitStreamCompletionError) Error() string {
 	return fmt.Sprintf("Message: %s", e.Message)
 }
+
+// MaxIterationsExceededError is returned by the tool calls loops when the model still calls tools after the
+// maximum number of completions (see WithMaxToolIterations). The tool calls executed so far are kept in the
+// messages of the agent, a caller can recover by asking for an answer without tools.
+type MaxIterationsExceededError struct {
+	MaxIterations int
+}
+
+// Error implements the error interface for MaxIterationsExceededError
+func (e *MaxIterationsExceededError) Error() string {
+	return fmt.Sprintf("the tool calls loop exceeded %d iterations", e.MaxIterations)
+}
+
+// StructuredOutputError is returned by RunStructured when the model doesn't answer with a JSON value of
+// the expected type, after the retries
+type StructuredOutputError struct {
+	// Attempts is the number of completions
+	Attempts int
+	// Response is the last answer of the model
+	Response string
+	Err      error
+}
+
+// Error implements the error interface for StructuredOutputError
+func (e *StructuredOutputError) Error() string {
+	return fmt.Sprintf("invalid structured output after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last answer
+func (e *StructuredOutputError) Unwrap() error {
+	return e.Err
+}
+
+// ToolTimeoutError is the error of a tool call which didn't return within the tool timeout (see
+// WithToolTimeout), the tool calls loops send it to the model as the result of the call
+type ToolTimeoutError struct {
+	Name    string
+	Timeout time.Duration
+}
+
+// Error implements the error interface for ToolTimeoutError
+func (e *ToolTimeoutError) Error() string {
+	return fmt.Sprintf("the tool %s didn't return within %s", e.Name, e.Timeout)
+}
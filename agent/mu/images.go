@@ -0,0 +1,117 @@
+package mu
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Image is a generated image: its data, or its URL when the model answers with URLs (dall-e response_format url)
+type Image struct {
+	Data []byte
+	URL  string
+	// Format is the format of the data: png, jpeg or webp
+	Format string
+	// RevisedPrompt is the prompt rewritten by the model (dall-e-3)
+	RevisedPrompt string
+}
+
+// ImageGenerator is implemented by the agents generating images (BasicAgent)
+type ImageGenerator interface {
+	GenerateImage(prompt string, options ...ImageOption) (Image, error)
+}
+
+// ImageOption is a functional option overriding the image parameters of the agent for a generation
+type ImageOption func(*openai.ImageGenerateParams)
+
+// WithImageModel sets the image model of a generation (e.g. gpt-image-1, dall-e-3)
+func WithImageModel(model string) ImageOption {
+	return func(params *openai.ImageGenerateParams) {
+		params.Model = model
+	}
+}
+
+// WithImageSize sets the size of the image of a generation (e.g. 1024x1024, 1536x1024)
+func WithImageSize(size string) ImageOption {
+	return func(params *openai.ImageGenerateParams) {
+		params.Size = openai.ImageGenerateParamsSize(size)
+	}
+}
+
+// WithImageQuality sets the quality of the image of a generation (e.g. low, medium, high, hd)
+func WithImageQuality(quality string) ImageOption {
+	return func(params *openai.ImageGenerateParams) {
+		params.Quality = openai.ImageGenerateParamsQuality(quality)
+	}
+}
+
+// GenerateImage generates an image from a prompt with the image parameters of the agent (WithImageParams)
+// and the options of the generation
+//
+// Example usage:
+//
+//	image, err := agent.(mu.ImageGenerator).GenerateImage("A watercolor of a lighthouse", mu.WithImageSize("1024x1024"))
+//	err = image.Save("lighthouse.png")
+func (agent *BasicAgent) GenerateImage(prompt string, options ...ImageOption) (Image, error) {
+	params := agent.ImageParams
+	params.Prompt = prompt
+	for _, option := range options {
+		option(&params)
+	}
+	response, err := agent.Client.Images.Generate(agent.ctx, params, agent.requestOptions()...)
+	if err != nil {
+		return Image{}, err
+	}
+	if len(response.Data) == 0 {
+		return Image{}, errors.New("no image generated")
+	}
+
+	generated := response.Data[0]
+	image := Image{URL: generated.URL, RevisedPrompt: generated.RevisedPrompt, Format: string(response.OutputFormat)}
+	if generated.B64JSON != "" {
+		image.Data, err = base64.StdEncoding.DecodeString(generated.B64JSON)
+		if err != nil {
+			return Image{}, fmt.Errorf("invalid image data: %w", err)
+		}
+	}
+	if image.Format == "" {
+		image.Format = string(params.OutputFormat)
+	}
+	if image.Format == "" {
+		image.Format = "png"
+	}
+	return image, nil
+}
+
+// Save writes the image to a file, the image of a URL is downloaded
+func (image Image) Save(path string) error {
+	data := image.Data
+	if len(data) == 0 {
+		if image.URL == "" {
+			return errors.New("the image has neither data nor URL")
+		}
+		resp, err := http.Get(image.URL)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("failed to download the image: %s", resp.Status)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return err
+		}
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,207 @@
+package mu
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// SummaryPrefix starts the system message of the summary of the earlier conversation (see SummaryMemory)
+const SummaryPrefix = "Summary of the earlier conversation:\n"
+
+// defaultSummaryPrompt is the instruction of the summaries of SummaryMemory
+const defaultSummaryPrompt = "Summarize the conversation above in a few sentences for your own memory: " +
+	"keep the facts, the names, the decisions and the open questions. Answer with the summary only."
+
+// MemoryStrategy reduces the messages of an agent before each completion (see WithMemoryStrategy)
+type MemoryStrategy interface {
+	// Apply returns the messages to keep, the leading system messages should be kept
+	Apply(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) ([]openai.ChatCompletionMessageParamUnion, error)
+}
+
+// WithMemoryStrategy applies a memory strategy to the messages of the agent before each completion (and each
+// streamed completion): the messages of Run and RunStream are reduced for good, the messages of a tool calls
+// loop for its requests (the loop returns all its messages).
+//
+// Example usage:
+//
+//	chatAgent, err := mu.NewAgent(ctx, "Bob",
+//	  mu.WithClient(client),
+//	  mu.WithParams(params),
+//	  mu.WithMemoryStrategy(mu.NewSummaryMemory(summarizer, 20, 6)),
+//	)
+func WithMemoryStrategy(strategy MemoryStrategy) AgentOption {
+	return func(a *BasicAgent) {
+		a.memory = strategy
+	}
+}
+
+// applyMemory reduces the messages of the agent with its memory strategy
+func (agent *BasicAgent) applyMemory(ctx context.Context) error {
+	if agent.memory == nil {
+		return nil
+	}
+	messages, err := agent.memory.Apply(ctx, agent.Params.Messages)
+	if err != nil {
+		return err
+	}
+	agent.Params.Messages = messages
+	return nil
+}
+
+// SlidingWindowMemory keeps the leading system messages and the last MaxMessages messages, from a user message
+// (the window starts with a turn, tool results are never kept without the tool calls they answer)
+type SlidingWindowMemory struct {
+	MaxMessages int
+}
+
+// NewSlidingWindowMemory creates a memory keeping the last maxMessages messages after the system messages
+func NewSlidingWindowMemory(maxMessages int) *SlidingWindowMemory {
+	return &SlidingWindowMemory{MaxMessages: maxMessages}
+}
+
+// Apply keeps the leading system messages and the last messages
+func (memory *SlidingWindowMemory) Apply(_ context.Context, messages []openai.ChatCompletionMessageParamUnion) ([]openai.ChatCompletionMessageParamUnion, error) {
+	prefix := leadingSystemMessages(messages)
+	if memory.MaxMessages <= 0 || len(messages)-prefix <= memory.MaxMessages {
+		return messages, nil
+	}
+	start := windowStart(messages, prefix, len(messages)-memory.MaxMessages)
+	return append(append([]openai.ChatCompletionMessageParamUnion{}, messages[:prefix]...), messages[start:]...), nil
+}
+
+// SummaryMemory replaces the oldest messages by a summary written by another agent when the conversation
+// exceeds MaxMessages messages after the system messages: the last KeepMessages messages (from a user
+// message) are kept as is, the ones before them (and the previous summary) are summarized into a system
+// message starting with SummaryPrefix, right after the system messages. The last summary is reused while
+// the summarized messages don't change (e.g. the iterations of a tool calls loop).
+type SummaryMemory struct {
+	// Summarizer is the agent writing the summaries, its messages are replaced at each summary
+	Summarizer Agent
+	// MaxMessages is the number of messages after the system messages above which the conversation is summarized
+	MaxMessages int
+	// KeepMessages is the number of recent messages kept as is (MaxMessages / 2 if zero)
+	KeepMessages int
+	// Prompt is the instruction of the summaries (a default one if empty)
+	Prompt string
+
+	lastKey     [sha256.Size]byte
+	lastSummary string
+}
+
+// NewSummaryMemory creates a memory summarizing the conversation with the summarizer agent when it exceeds
+// maxMessages messages, keeping the last keepMessages messages as is
+func NewSummaryMemory(summarizer Agent, maxMessages int, keepMessages int) *SummaryMemory {
+	return &SummaryMemory{Summarizer: summarizer, MaxMessages: maxMessages, KeepMessages: keepMessages}
+}
+
+// Apply summarizes the oldest messages when there are too many
+func (memory *SummaryMemory) Apply(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) ([]openai.ChatCompletionMessageParamUnion, error) {
+	prefix := leadingSystemMessages(messages)
+	if memory.MaxMessages <= 0 || len(messages)-prefix <= memory.MaxMessages {
+		return messages, nil
+	}
+	if memory.Summarizer == nil {
+		return messages, errors.New("the summary memory has no summarizer agent")
+	}
+	keep := memory.KeepMessages
+	if keep <= 0 {
+		keep = memory.MaxMessages / 2
+	}
+	start := windowStart(messages, prefix, len(messages)-keep)
+	if start <= prefix {
+		return messages, nil
+	}
+
+	// The previous summary is summarized again with the dropped messages
+	system := []openai.ChatCompletionMessageParamUnion{}
+	toSummarize := []openai.ChatCompletionMessageParamUnion{}
+	for _, message := range messages[:prefix] {
+		if strings.HasPrefix(systemText(message), SummaryPrefix) {
+			toSummarize = append(toSummarize, message)
+		} else {
+			system = append(system, message)
+		}
+	}
+	toSummarize = append(toSummarize, messages[prefix:start]...)
+
+	summary, err := memory.summarize(ctx, toSummarize)
+	if err != nil {
+		return messages, err
+	}
+	reduced := append(system, openai.SystemMessage(SummaryPrefix+summary))
+	return append(reduced, messages[start:]...), nil
+}
+
+// summarize returns the summary of messages, the last one if they were already summarized
+func (memory *SummaryMemory) summarize(ctx context.Context, messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return "", err
+	}
+	key := sha256.Sum256(data)
+	if key == memory.lastKey && memory.lastSummary != "" {
+		return memory.lastSummary, nil
+	}
+
+	prompt := memory.Prompt
+	if prompt == "" {
+		prompt = defaultSummaryPrompt
+	}
+	memory.Summarizer.SetMessages([]openai.ChatCompletionMessageParamUnion{})
+	request := append(append([]openai.ChatCompletionMessageParamUnion{}, messages...), openai.UserMessage(prompt))
+	var summary string
+	if runner, ok := memory.Summarizer.(ContextRunner); ok {
+		summary, err = runner.RunContext(ctx, request)
+	} else {
+		summary, err = memory.Summarizer.Run(request)
+	}
+	if err != nil {
+		return "", err
+	}
+	memory.lastKey, memory.lastSummary = key, strings.TrimSpace(summary)
+	return memory.lastSummary, nil
+}
+
+// leadingSystemMessages returns the number of system (or developer) messages at the start of the messages
+func leadingSystemMessages(messages []openai.ChatCompletionMessageParamUnion) int {
+	prefix := 0
+	for prefix < len(messages) && (messages[prefix].OfSystem != nil || messages[prefix].OfDeveloper != nil) {
+		prefix++
+	}
+	return prefix
+}
+
+// windowStart returns the start of the kept messages from start (at least prefix): the first user message
+// from there, to keep whole turns, or else the assistant message of the tool calls answered at start
+func windowStart(messages []openai.ChatCompletionMessageParamUnion, prefix int, start int) int {
+	start = max(start, prefix)
+	for idx := start; idx < len(messages); idx++ {
+		if messages[idx].OfUser != nil {
+			return idx
+		}
+	}
+	for start > prefix && start < len(messages) && messages[start].OfTool != nil {
+		start--
+	}
+	return start
+}
+
+// systemText returns the text of a system message
+func systemText(message openai.ChatCompletionMessageParamUnion) string {
+	if message.OfSystem == nil {
+		return ""
+	}
+	if message.OfSystem.Content.OfString.Valid() {
+		return message.OfSystem.Content.OfString.Value
+	}
+	parts := []string{}
+	for _, part := range message.OfSystem.Content.OfArrayOfContentParts {
+		parts = append(parts, part.Text)
+	}
+	return strings.Join(parts, "")
+}
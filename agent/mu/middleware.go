@@ -0,0 +1,171 @@
+package mu
+
+import (
+	"context"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// CompletionKind is the kind of a request of an agent to the model
+type CompletionKind string
+
+const (
+	CompletionChat      CompletionKind = "chat"      // a completion (Run, RunWithReasoning, DetectToolCalls)
+	CompletionStream    CompletionKind = "stream"    // a streamed completion (RunStream, RunStreamWithReasoning, DetectToolCallsStream)
+	CompletionEmbedding CompletionKind = "embedding" // an embedding (GenerateEmbeddingVector)
+)
+
+// CompletionRequest is a request of an agent to the model. Params (EmbeddingParams for an embedding) are
+// a copy of the parameters of the agent for this request: a middleware can change them (e.g. rewrite the
+// prompt) without changing the messages of the agent.
+type CompletionRequest struct {
+	Agent           string
+	Kind            CompletionKind
+	Params          *openai.ChatCompletionNewParams
+	EmbeddingParams *openai.EmbeddingNewParams
+}
+
+// CompletionResponse is the outcome of a request, Err is set if it failed (the other fields can be partial)
+type CompletionResponse struct {
+	Content      string
+	ToolCalls    []PendingToolCall
+	FinishReason string
+	Embedding    []float64
+	// Usage is the token usage reported by the provider (zero if not reported)
+	Usage    Usage
+	Duration time.Duration
+	Err      error
+}
+
+// ToolCallEvent is the execution of a tool call by a tool calls loop
+type ToolCallEvent struct {
+	Agent     string
+	ID        string
+	Name      string
+	Arguments string
+	Result    string
+	Err       error
+	Duration  time.Duration
+}
+
+// Middleware observes, or changes, the requests of an agent (see WithMiddleware). Embed BaseMiddleware to
+// implement only some of the hooks.
+type Middleware interface {
+	// BeforeCompletion is called before each request (its retries excluded), an error cancels the request
+	// and is returned by the method of the agent
+	BeforeCompletion(ctx context.Context, request *CompletionRequest) error
+	// AfterCompletion is called after each request, succeeded or failed; a stream is complete at its end
+	AfterCompletion(ctx context.Context, request *CompletionRequest, response *CompletionResponse)
+	// OnToolCall is called after the execution of each tool call of the tool calls loops
+	OnToolCall(ctx context.Context, event ToolCallEvent)
+	// OnStreamChunk is called with each chunk of the streamed completions, before the callbacks of the agent
+	OnStreamChunk(ctx context.Context, request *CompletionRequest, chunk openai.ChatCompletionChunk)
+}
+
+// BaseMiddleware is a middleware doing nothing, to embed in the middlewares implementing some of the hooks
+type BaseMiddleware struct{}
+
+// BeforeCompletion does nothing
+func (BaseMiddleware) BeforeCompletion(context.Context, *CompletionRequest) error { return nil }
+
+// AfterCompletion does nothing
+func (BaseMiddleware) AfterCompletion(context.Context, *CompletionRequest, *CompletionResponse) {}
+
+// OnToolCall does nothing
+func (BaseMiddleware) OnToolCall(context.Context, ToolCallEvent) {}
+
+// OnStreamChunk does nothing
+func (BaseMiddleware) OnStreamChunk(context.Context, *CompletionRequest, openai.ChatCompletionChunk) {
+}
+
+// WithMiddleware adds middlewares to the agent, their hooks are called in the order of the middlewares
+//
+// Example usage:
+//
+//	type timing struct{ mu.BaseMiddleware }
+//
+//	func (timing) AfterCompletion(ctx context.Context, request *mu.CompletionRequest, response *mu.CompletionResponse) {
+//	  fmt.Printf("⏱️ %s %s: %s, %d tokens\n", request.Agent, request.Kind, response.Duration, response.Usage.TotalTokens)
+//	}
+//
+//	agent, err := mu.NewAgent(ctx, "Bob", mu.WithClient(client), mu.WithParams(params), mu.WithMiddleware(timing{}))
+func WithMiddleware(middlewares ...Middleware) AgentOption {
+	return func(a *BasicAgent) {
+		a.middlewares = append(a.middlewares, middlewares...)
+	}
+}
+
+// beforeCompletion calls the BeforeCompletion hooks with a request, whose parameters are a copy of the ones
+// of the agent, and returns it
+func (agent *BasicAgent) beforeCompletion(ctx context.Context, request *CompletionRequest) (*CompletionRequest, error) {
+	request.Agent = agent.Name
+	for _, middleware := range agent.middlewares {
+		if err := middleware.BeforeCompletion(ctx, request); err != nil {
+			return request, err
+		}
+	}
+	return request, nil
+}
+
+// afterCompletion calls the AfterCompletion hooks
+func (agent *BasicAgent) afterCompletion(ctx context.Context, request *CompletionRequest, response *CompletionResponse) {
+	for _, middleware := range agent.middlewares {
+		middleware.AfterCompletion(ctx, request, response)
+	}
+}
+
+// complete makes a completion with the parameters of the agent, with the memory strategy, the hooks and the retries
+func (agent *BasicAgent) complete(ctx context.Context) (*openai.ChatCompletion, error) {
+	if err := agent.applyMemory(ctx); err != nil {
+		return nil, err
+	}
+	params := agent.Params
+	request, err := agent.beforeCompletion(ctx, &CompletionRequest{Kind: CompletionChat, Params: &params})
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	var completion *openai.ChatCompletion
+	err = agent.withRetries(ctx, func() (err error) {
+		completion, err = agent.Client.Chat.Completions.New(ctx, *request.Params, agent.requestOptions()...)
+		return err
+	})
+	response := &CompletionResponse{Duration: time.Since(started), Err: err}
+	if err == nil {
+		agent.recordUsage(completion.Usage)
+		response.Usage = usageOf(completion.Usage)
+		if len(completion.Choices) > 0 {
+			choice := completion.Choices[0]
+			response.Content = choice.Message.Content
+			response.FinishReason = choice.FinishReason
+			for _, toolCall := range choice.Message.ToolCalls {
+				response.ToolCalls = append(response.ToolCalls, PendingToolCall{ID: toolCall.ID, Name: toolCall.Function.Name, Arguments: toolCall.Function.Arguments})
+			}
+		}
+	}
+	agent.afterCompletion(ctx, request, response)
+	return completion, err
+}
+
+// executeToolCall executes a tool call of a tool calls loop with the callback, and calls the OnToolCall hooks
+func (agent *BasicAgent) executeToolCall(ctx context.Context, toolCall PendingToolCall, toolCallBack ToolCallbackContext) (string, error) {
+	agent.log().Debug("tool call", "agent", agent.Name, "function", toolCall.Name)
+	started := time.Now()
+	result, err := agent.callToolWithTimeout(ctx, toolCall, toolCallBack)
+	if len(agent.middlewares) > 0 {
+		event := ToolCallEvent{
+			Agent:     agent.Name,
+			ID:        toolCall.ID,
+			Name:      toolCall.Name,
+			Arguments: toolCall.Arguments,
+			Result:    result,
+			Err:       err,
+			Duration:  time.Since(started),
+		}
+		for _, middleware := range agent.middlewares {
+			middleware.OnToolCall(ctx, event)
+		}
+	}
+	return result, err
+}
@@ -0,0 +1,130 @@
+package mu
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// maxRetryDelay caps the delay between two attempts
+const maxRetryDelay = 30 * time.Second
+
+// RetryPolicy retries the requests to the model failing with a transient error (see IsTransientError)
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled at each retry (up to 30 seconds)
+	BaseDelay time.Duration
+	// Jitter is the maximum random delay added to each delay, so the clients don't retry together
+	Jitter time.Duration
+	// OnRetry (optional) is called before each retry with its number (from 1), its delay and the error
+	OnRetry func(retry int, delay time.Duration, err error)
+}
+
+// WithRetryPolicy retries the completions (Run, RunStream, DetectToolCalls... and their variants) and the
+// embeddings failing with a transient error: a 408, 409, 429 or 5xx response, or a network error such as a
+// reset connection. The delay starts at baseDelay and is doubled after each retry, plus a random jitter
+// (a longer Retry-After of the response wins). A stream is retried when it fails before its first chunk, not
+// once its content has been delivered to the callback. The retries of the OpenAI client are disabled for the
+// requests of the agent, the policy replaces them.
+//
+// Example usage:
+//
+//	agent, err := mu.NewAgent(ctx, "Bob",
+//	  mu.WithClient(client),
+//	  mu.WithParams(params),
+//	  mu.WithRetryPolicy(4, 500*time.Millisecond, 250*time.Millisecond),
+//	  mu.WithRetryHook(func(retry int, delay time.Duration, err error) {
+//	    fmt.Printf("🔁 retry #%d in %s: %v\n", retry, delay, err)
+//	  }),
+//	)
+func WithRetryPolicy(maxRetries int, baseDelay time.Duration, jitter time.Duration) AgentOption {
+	return func(a *BasicAgent) {
+		a.retryPolicy.MaxRetries = maxRetries
+		a.retryPolicy.BaseDelay = baseDelay
+		a.retryPolicy.Jitter = jitter
+	}
+}
+
+// WithRetryHook sets a function called before each retry of the retry policy (see WithRetryPolicy)
+func WithRetryHook(onRetry func(retry int, delay time.Duration, err error)) AgentOption {
+	return func(a *BasicAgent) {
+		a.retryPolicy.OnRetry = onRetry
+	}
+}
+
+// IsTransientError reports whether a request failing with err can succeed if sent again: a 408, 409, 429
+// or 5xx response, a closed, reset or refused connection, a network timeout or a response cut before its end.
+// The cancellation of the context isn't transient.
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+			return true
+		}
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	// A connection closed by the server before the end of the response
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// delay returns the delay before a retry (from 1)
+func (policy RetryPolicy) delay(retry int, err error) time.Duration {
+	delay := policy.BaseDelay << (retry - 1)
+	if delay > maxRetryDelay || delay < policy.BaseDelay {
+		delay = maxRetryDelay
+	}
+	if policy.Jitter > 0 {
+		delay += rand.N(policy.Jitter)
+	}
+	// The server tells how long to wait (429, 503)
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.Response != nil {
+		if seconds, parseErr := strconv.Atoi(apiErr.Response.Header.Get("Retry-After")); parseErr == nil {
+			if retryAfter := time.Duration(seconds) * time.Second; retryAfter > delay {
+				delay = min(retryAfter, maxRetryDelay)
+			}
+		}
+	}
+	return delay
+}
+
+// withRetries calls attempt until it succeeds, fails with an error which isn't transient, or the retries
+// of the policy are exhausted; the last error is returned (the error of the context if cancelled during a delay)
+func (agent *BasicAgent) withRetries(ctx context.Context, attempt func() error) error {
+	policy := agent.retryPolicy
+	for retry := 1; ; retry++ {
+		err := attempt()
+		if err == nil || retry > policy.MaxRetries || !IsTransientError(err) {
+			return err
+		}
+		delay := policy.delay(retry, err)
+		agent.log().Debug("retrying the request", "agent", agent.Name, "retry", retry, "delay", delay, "error", err)
+		if policy.OnRetry != nil {
+			policy.OnRetry(retry, delay, err)
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"errors"
 
 	"github.com/openai/openai-go/v2"
@@ -20,6 +21,11 @@ import (
 // completion request. It returns an error if the completion fails or if the response
 // contains no choices.
 func (agent *BasicAgent) Run(Messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+	return agent.RunContext(agent.ctx, Messages)
+}
+
+// RunContext is Run with the context of the call, which cancels the completion request (see ContextRunner)
+func (agent *BasicAgent) RunContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion) (string, error) {
 	// Preserve existing system messages from agent.Params
 	// existingSystemMessages := []openai.ChatCompletionMessageParamUnion{}
 	// for _, msg := range agent.Params.Messages {
@@ -30,7 +36,7 @@ func (agent *BasicAgent) Run(Messages []openai.ChatCompletionMessageParamUnion)
 
 	// Combine existing system messages with new messages
 	agent.Params.Messages = append(agent.Params.Messages, Messages...)
-	completion, err := agent.Client.Chat.Completions.New(agent.ctx, agent.Params)
+	completion, err := agent.complete(ctx)
 
 	if err != nil {
 		return "", err
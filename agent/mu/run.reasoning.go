@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -24,6 +25,11 @@ import (
 // completion request. It returns an error if the completion fails or if the response
 // contains no choices.
 func (agent *BasicAgent) RunWithReasoning(Messages []openai.ChatCompletionMessageParamUnion) (string, string, error) {
+	return agent.RunWithReasoningContext(agent.ctx, Messages)
+}
+
+// RunWithReasoningContext is RunWithReasoning with the context of the call (see ContextRunner)
+func (agent *BasicAgent) RunWithReasoningContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion) (string, string, error) {
 	// Preserve existing system messages from agent.Params
 	// existingSystemMessages := []openai.ChatCompletionMessageParamUnion{}
 	// for _, msg := range agent.Params.Messages {
@@ -34,7 +40,7 @@ func (agent *BasicAgent) RunWithReasoning(Messages []openai.ChatCompletionMessag
 
 	// Combine existing system messages with new messages
 	agent.Params.Messages = append(agent.Params.Messages, Messages...)
-	completion, err := agent.Client.Chat.Completions.New(agent.ctx, agent.Params)
+	completion, err := agent.complete(ctx)
 
 	if err != nil {
 		return "", "", err
@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"errors"
 
 	"github.com/openai/openai-go/v2"
@@ -23,6 +24,12 @@ import (
 //   - A stream error occurs
 //   - Stream closing fails
 func (agent *BasicAgent) RunStream(Messages []openai.ChatCompletionMessageParamUnion, callBack func(content string) error) (string, error) {
+	return agent.RunStreamContext(agent.ctx, Messages, callBack)
+}
+
+// RunStreamContext is RunStream with the context of the call: its cancellation stops the stream, the content
+// received so far is returned with the error of the context (see ContextRunner)
+func (agent *BasicAgent) RunStreamContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion, callBack func(content string) error) (string, error) {
 	// Preserve existing system messages from agent.Params
 	// existingSystemMessages := []openai.ChatCompletionMessageParamUnion{}
 	// for _, msg := range agent.Params.Messages {
@@ -33,7 +40,11 @@ func (agent *BasicAgent) RunStream(Messages []openai.ChatCompletionMessageParamU
 
 	// Combine existing system messages with new messages
 	agent.Params.Messages = append(agent.Params.Messages, Messages...)
-	stream := agent.Client.Chat.Completions.NewStreaming(agent.ctx, agent.Params)
+	stream, err := agent.newStream(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer stream.Close()
 	var response string
 	var cbkRes error
 
@@ -60,6 +71,10 @@ func (agent *BasicAgent) RunStream(Messages []openai.ChatCompletionMessageParamU
 	if cbkRes != nil {
 		return response, cbkRes
 	}
+	if err := ctx.Err(); err != nil {
+		stream.Close()
+		return response, err
+	}
 	if err := stream.Err(); err != nil {
 		return response, err
 	}
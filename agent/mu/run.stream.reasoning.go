@@ -1,6 +1,7 @@
 package mu
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
@@ -28,6 +29,12 @@ import (
 //   - A stream error occurs
 //   - Stream closing fails
 func (agent *BasicAgent) RunStreamWithReasoning(Messages []openai.ChatCompletionMessageParamUnion, contentCallback func(content string) error, reasoningCallback func(reasoning string) error) (string, string, error) {
+	return agent.RunStreamWithReasoningContext(agent.ctx, Messages, contentCallback, reasoningCallback)
+}
+
+// RunStreamWithReasoningContext is RunStreamWithReasoning with the context of the call: its cancellation stops
+// the stream, the content and reasoning received so far are returned with the error of the context (see ContextRunner)
+func (agent *BasicAgent) RunStreamWithReasoningContext(ctx context.Context, Messages []openai.ChatCompletionMessageParamUnion, contentCallback func(content string) error, reasoningCallback func(reasoning string) error) (string, string, error) {
 	// Preserve existing system messages from agent.Params
 	// existingSystemMessages := []openai.ChatCompletionMessageParamUnion{}
 	// for _, msg := range agent.Params.Messages {
@@ -38,7 +45,11 @@ func (agent *BasicAgent) RunStreamWithReasoning(Messages []openai.ChatCompletion
 
 	// Combine existing system messages with new messages
 	agent.Params.Messages = append(agent.Params.Messages, Messages...)
-	stream := agent.Client.Chat.Completions.NewStreaming(agent.ctx, agent.Params)
+	stream, err := agent.newStream(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer stream.Close()
 	var response string
 	var reasoning string
 	var cbkRes error
@@ -86,6 +97,10 @@ func (agent *BasicAgent) RunStreamWithReasoning(Messages []openai.ChatCompletion
 	if cbkRes != nil {
 		return response, reasoning, cbkRes
 	}
+	if err := ctx.Err(); err != nil {
+		stream.Close()
+		return response, reasoning, err
+	}
 	if err := stream.Err(); err != nil {
 		return response, reasoning, err
 	}
@@ -0,0 +1,223 @@
+package mu
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// JSONSchemaFor returns the JSON schema of the Go type T (see JSONSchemaOfType)
+//
+// Example usage:
+//
+//	type Country struct {
+//	  Name      string   `json:"name" description:"The name of the country"`
+//	  Capital   string   `json:"capital"`
+//	  Languages []string `json:"languages"`
+//	}
+//	schema := mu.JSONSchemaFor[Country]()
+func JSONSchemaFor[T any]() map[string]any {
+	return JSONSchemaOfType(reflect.TypeFor[T]())
+}
+
+// JSONSchemaOfType returns the JSON schema of a Go type, from its fields and their tags:
+//   - the `json` tag gives the name of a property, "-" skips the field, and omitempty (or a pointer) makes it optional
+//   - the `description` tag describes the property
+//   - the `enum` tag lists the allowed values, separated by commas
+//
+// Structs are objects without additional properties, embedded structs are flattened, slices are arrays,
+// maps with string keys are objects, time.Time is a date-time string.
+func JSONSchemaOfType(t reflect.Type) map[string]any {
+	return schemaOfType(t, map[reflect.Type]bool{})
+}
+
+// schemaOfType returns the schema of a type, visiting are the structs being described (a recursive type
+// is described once, its nested occurrences are any objects)
+func schemaOfType(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if t == reflect.TypeFor[json.RawMessage]() {
+		return map[string]any{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte is encoded in base64
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": schemaOfType(t.Elem(), visiting)}
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "object", "additionalProperties": schemaOfType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := map[string]any{}
+		required := []string{}
+		addStructFields(t, properties, &required, visiting)
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	}
+	// Interfaces (any) accept any value
+	return map[string]any{}
+}
+
+// addStructFields adds the properties of the exported fields of a struct
+func addStructFields(t reflect.Type, properties map[string]any, required *[]string, visiting map[reflect.Type]bool) {
+	for idx := 0; idx < t.NumField(); idx++ {
+		field := t.Field(idx)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addStructFields(fieldType, properties, required, visiting)
+				continue
+			}
+			if !field.IsExported() {
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := schemaOfType(fieldType, visiting)
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			values := []any{}
+			for _, value := range strings.Split(enum, ",") {
+				values = append(values, strings.TrimSpace(value))
+			}
+			property["enum"] = values
+		}
+		properties[name] = property
+		if !slices.Contains(strings.Split(options, ","), "omitempty") && fieldType.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// validateJSONSchema checks a decoded JSON value against a schema of JSONSchemaOfType: the types, the
+// required properties, the additional properties and the enums
+func validateJSONSchema(schema map[string]any, value any, path string) error {
+	if path == "" {
+		path = "$"
+	}
+	if value == nil {
+		// null is the zero value of the optional properties
+		return nil
+	}
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected a string, got %s", path, jsonType(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %s", path, jsonType(value))
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != float64(int64(number)) {
+			return fmt.Errorf("%s: expected an integer, got %s", path, jsonType(value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected a number, got %s", path, jsonType(value))
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %s", path, jsonType(value))
+		}
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for idx, item := range items {
+				if err := validateJSONSchema(itemSchema, item, fmt.Sprintf("%s[%d]", path, idx)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %s", path, jsonType(value))
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]string)
+		for _, name := range required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: missing property %q", path, name)
+			}
+		}
+		for name, property := range object {
+			propertySchema, known := properties[name].(map[string]any)
+			if !known {
+				if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+					propertySchema, known = additional, true
+				} else if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+			}
+			if known {
+				if err := validateJSONSchema(propertySchema, property, path+"."+name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return fmt.Errorf("%s: %v is not one of %v", path, value, enum)
+	}
+	return nil
+}
+
+// jsonType returns the JSON type of a decoded value
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "a string"
+	case bool:
+		return "a boolean"
+	case float64:
+		return "a number"
+	case []any:
+		return "an array"
+	case map[string]any:
+		return "an object"
+	}
+	return "null"
+}
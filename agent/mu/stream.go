@@ -0,0 +1,106 @@
+package mu
+
+import (
+	"context"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/packages/ssestream"
+)
+
+// chunkStream is a streamed completion of an agent. Its first chunk is read when it is opened, so that
+// a stream failing before its first chunk can be retried; the content, the tool calls and the usage of
+// its chunks are accumulated, and the hooks of the agent are called with its chunks and at its end.
+type chunkStream struct {
+	*ssestream.Stream[openai.ChatCompletionChunk]
+	ctx         context.Context
+	agent       *BasicAgent
+	request     *CompletionRequest
+	accumulator *toolCallsAccumulator
+	// usage is the usage of the stream, in its last chunk (without choices)
+	usage    openai.CompletionUsage
+	started  time.Time
+	first    bool
+	finished bool
+}
+
+// newStream opens a streamed completion with the parameters of the agent, after its memory strategy and the
+// BeforeCompletion hooks, retried with the retry policy until its first chunk; the error of the last attempt
+// is returned by Err
+func (agent *BasicAgent) newStream(ctx context.Context) (*chunkStream, error) {
+	if err := agent.applyMemory(ctx); err != nil {
+		return nil, err
+	}
+	params := agent.streamParams()
+	request, err := agent.beforeCompletion(ctx, &CompletionRequest{Kind: CompletionStream, Params: &params})
+	if err != nil {
+		return nil, err
+	}
+	stream := &chunkStream{ctx: ctx, agent: agent, request: request, started: time.Now()}
+	agent.withRetries(ctx, func() error {
+		opened := agent.Client.Chat.Completions.NewStreaming(ctx, *request.Params, agent.requestOptions()...)
+		stream.Stream, stream.accumulator = opened, newToolCallsAccumulator()
+		stream.first = opened.Next()
+		if err := opened.Err(); err != nil {
+			opened.Close()
+			return err
+		}
+		return nil
+	})
+	return stream, nil
+}
+
+// Next advances to the next chunk (the first one is already read)
+func (stream *chunkStream) Next() bool {
+	next := stream.first
+	stream.first = false
+	if !next {
+		next = stream.Stream.Next()
+	}
+	if !next {
+		stream.finish()
+		return false
+	}
+	chunk := stream.Current()
+	if chunk.Usage.PromptTokens+chunk.Usage.CompletionTokens > 0 {
+		stream.usage = chunk.Usage
+	}
+	if len(chunk.Choices) > 0 {
+		stream.accumulator.addChoice(chunk.Choices[0])
+	}
+	for _, middleware := range stream.agent.middlewares {
+		middleware.OnStreamChunk(stream.ctx, stream.request, chunk)
+	}
+	return true
+}
+
+// Close closes the stream, at its end or before
+func (stream *chunkStream) Close() error {
+	stream.finish()
+	return stream.Stream.Close()
+}
+
+// finish records the usage of the stream and calls the AfterCompletion hooks, once
+func (stream *chunkStream) finish() {
+	if stream.finished {
+		return
+	}
+	stream.finished = true
+	stream.agent.recordUsage(stream.usage)
+	err := stream.Err()
+	if err == nil {
+		err = stream.ctx.Err()
+	}
+	if len(stream.agent.middlewares) == 0 {
+		return
+	}
+	response := &CompletionResponse{
+		Content:      stream.accumulator.content,
+		ToolCalls:    stream.accumulator.toolCalls,
+		FinishReason: stream.accumulator.finishReason,
+		Usage:        usageOf(stream.usage),
+		Duration:     time.Since(stream.started),
+		Err:          err,
+	}
+	stream.agent.afterCompletion(stream.ctx, stream.request, response)
+}
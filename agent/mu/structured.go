@@ -0,0 +1,121 @@
+package mu
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/openai/openai-go/v2"
+)
+
+// maxStructuredRetries is the number of times RunStructured asks the model again for a valid answer
+const maxStructuredRetries = 2
+
+// structuredValue is the property wrapping the values which aren't objects (the response formats are objects)
+const structuredValue = "value"
+
+// invalidSchemaName matches the characters not allowed in the name of a response format
+var invalidSchemaName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// RunStructured runs a completion answering with a JSON value of type T: the JSON schema of T (see
+// JSONSchemaOfType) is set as the response format for the completion, the answer is checked against the
+// schema and decoded into T. When the answer isn't valid, the model is asked again with the error (twice),
+// then a StructuredOutputError is returned. The messages (and the answers) are kept like with Run.
+//
+// Example usage:
+//
+//	type Country struct {
+//	  Name      string   `json:"name"`
+//	  Capital   string   `json:"capital"`
+//	  Languages []string `json:"languages" description:"The official languages"`
+//	}
+//	country, err := mu.RunStructured[Country](agent, []openai.ChatCompletionMessageParamUnion{
+//	  openai.UserMessage("Tell me about Canada."),
+//	})
+//	fmt.Println(country.Capital)
+func RunStructured[T any](agent Agent, messages []openai.ChatCompletionMessageParamUnion) (T, error) {
+	return runStructured[T](agent, messages, agent.Run)
+}
+
+// RunStructuredContext is RunStructured with the context of the call, if the agent is a ContextRunner
+func RunStructuredContext[T any](ctx context.Context, agent Agent, messages []openai.ChatCompletionMessageParamUnion) (T, error) {
+	run := agent.Run
+	if runner, ok := agent.(ContextRunner); ok {
+		run = func(messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+			return runner.RunContext(ctx, messages)
+		}
+	}
+	return runStructured[T](agent, messages, run)
+}
+
+// runStructured sets the response format of T, runs the completions until the answer is valid, and
+// restores the response format of the agent
+func runStructured[T any](agent Agent, messages []openai.ChatCompletionMessageParamUnion, run func([]openai.ChatCompletionMessageParamUnion) (string, error)) (T, error) {
+	var result T
+	t := reflect.TypeFor[T]()
+	schema := JSONSchemaOfType(t)
+	wrapped := schema["type"] != "object"
+	if wrapped {
+		schema = map[string]any{
+			"type":                 "object",
+			"properties":           map[string]any{structuredValue: schema},
+			"required":             []string{structuredValue},
+			"additionalProperties": false,
+		}
+	}
+	name := invalidSchemaName.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		name = "response"
+	}
+
+	previousFormat := agent.GetResponseFormat()
+	defer agent.SetResponseFormat(previousFormat)
+	agent.SetResponseFormat(openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+			JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{Name: name, Schema: schema},
+		},
+	})
+
+	for attempt := 1; ; attempt++ {
+		response, err := run(messages)
+		if err != nil {
+			return result, err
+		}
+		err = decodeStructured(response, schema, wrapped, &result)
+		if err == nil {
+			return result, nil
+		}
+		if attempt > maxStructuredRetries {
+			return result, &StructuredOutputError{Attempts: attempt, Response: response, Err: err}
+		}
+		messages = []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(fmt.Sprintf("Your answer isn't valid (%v). Answer again with the JSON only, matching the schema.", err)),
+		}
+	}
+}
+
+// decodeStructured checks the JSON of an answer against the schema and decodes it into result
+func decodeStructured(response string, schema map[string]any, wrapped bool, result any) error {
+	extracted, err := helpers.ExtractJSON(response)
+	if err != nil {
+		return err
+	}
+	var value any
+	if err := extracted.Unmarshal(&value); err != nil {
+		return err
+	}
+	if err := validateJSONSchema(schema, value, ""); err != nil {
+		return err
+	}
+	data := []byte(extracted.JSON)
+	if wrapped {
+		data, err = json.Marshal(value.(map[string]any)[structuredValue])
+		if err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, result)
+}
@@ -0,0 +1,112 @@
+package mu
+
+import "github.com/openai/openai-go/v2"
+
+// Usage is the token usage of completions, as reported by the provider
+type Usage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	// Requests is the number of completions counted
+	Requests int `json:"requests"`
+}
+
+// Add returns the sum of two usages
+func (usage Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     usage.PromptTokens + other.PromptTokens,
+		CompletionTokens: usage.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      usage.TotalTokens + other.TotalTokens,
+		Requests:         usage.Requests + other.Requests,
+	}
+}
+
+// UsageReporter is implemented by the agents counting the tokens of their completions (BasicAgent).
+// The usage is read from the completion responses and from the last chunk of the streams (the streamed
+// completions request it with stream_options.include_usage, unless the stream options of the agent are set);
+// the embedding requests are counted like completions (prompt tokens only);
+// a completion without usage (some local servers don't report it) isn't counted.
+//
+// Example usage:
+//
+//	usage := agent.(mu.UsageReporter).GetUsage()
+//	fmt.Printf("%d requests, %d prompt tokens, %d completion tokens\n", usage.Requests, usage.PromptTokens, usage.CompletionTokens)
+type UsageReporter interface {
+	// GetUsage returns the cumulative usage of the agent since its creation or ResetUsage
+	GetUsage() Usage
+	// GetLastUsage returns the usage of the last completion
+	GetLastUsage() Usage
+	// ResetUsage sets the cumulative usage to zero
+	ResetUsage()
+}
+
+// WithUsageCallback sets a function called after each completion reporting its usage, with the usage
+// of the completion and the cumulative usage of the agent
+//
+// Example usage:
+//
+//	agent, err := mu.NewAgent(ctx, "Bob", mu.WithClient(client), mu.WithParams(params),
+//	  mu.WithUsageCallback(func(call, total mu.Usage) {
+//	    fmt.Printf("🪙 %d tokens (%d in total)\n", call.TotalTokens, total.TotalTokens)
+//	  }),
+//	)
+func WithUsageCallback(callback func(call Usage, total Usage)) AgentOption {
+	return func(a *BasicAgent) {
+		a.usageCallback = callback
+	}
+}
+
+// GetUsage returns the cumulative usage of the agent since its creation or ResetUsage
+func (agent *BasicAgent) GetUsage() Usage {
+	return agent.usage
+}
+
+// GetLastUsage returns the usage of the last completion
+func (agent *BasicAgent) GetLastUsage() Usage {
+	return agent.lastUsage
+}
+
+// ResetUsage sets the cumulative usage to zero
+func (agent *BasicAgent) ResetUsage() {
+	agent.usage = Usage{}
+}
+
+// recordUsage counts the usage of a completion, if reported
+func (agent *BasicAgent) recordUsage(completionUsage openai.CompletionUsage) {
+	usage := usageOf(completionUsage)
+	if usage.Requests == 0 {
+		return
+	}
+	agent.lastUsage = usage
+	agent.usage = agent.usage.Add(agent.lastUsage)
+	if agent.usageCallback != nil {
+		agent.usageCallback(agent.lastUsage, agent.usage)
+	}
+}
+
+// usageOf converts the usage of a completion, zero if not reported
+func usageOf(completionUsage openai.CompletionUsage) Usage {
+	if completionUsage.TotalTokens == 0 && completionUsage.PromptTokens == 0 && completionUsage.CompletionTokens == 0 {
+		return Usage{}
+	}
+	usage := Usage{
+		PromptTokens:     completionUsage.PromptTokens,
+		CompletionTokens: completionUsage.CompletionTokens,
+		TotalTokens:      completionUsage.TotalTokens,
+		Requests:         1,
+	}
+	if usage.TotalTokens == 0 {
+		usage.TotalTokens = completionUsage.PromptTokens + completionUsage.CompletionTokens
+	}
+	return usage
+}
+
+// streamParams returns the parameters of a streamed completion: the usage of the stream is requested
+// unless the stream options are set
+func (agent *BasicAgent) streamParams() openai.ChatCompletionNewParams {
+	params := agent.Params
+	if !params.StreamOptions.IncludeUsage.Valid() && !params.StreamOptions.IncludeObfuscation.Valid() {
+		params.StreamOptions.IncludeUsage = openai.Bool(true)
+	}
+	return params
+}
@@ -0,0 +1,138 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultScope is the scope of the Entra ID tokens of Azure OpenAI
+const DefaultScope = "https://cognitiveservices.azure.com/.default"
+
+// DefaultAuthorityHost is the Microsoft Entra ID authority of the public cloud
+const DefaultAuthorityHost = "https://login.microsoftonline.com"
+
+// CredentialOption is a functional option for configuring the client secret credential
+type CredentialOption func(*clientSecretCredential)
+
+// clientSecretCredential gets the tokens of a service principal with the client credentials flow and caches them
+type clientSecretCredential struct {
+	tenantID, clientID, clientSecret string
+	authorityHost                    string
+	scope                            string
+	httpClient                       *http.Client
+
+	mutex     sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// WithAuthorityHost is a functional option that sets the Entra ID authority (e.g. of a sovereign cloud)
+func WithAuthorityHost(authorityHost string) CredentialOption {
+	return func(c *clientSecretCredential) {
+		c.authorityHost = strings.TrimSuffix(authorityHost, "/")
+	}
+}
+
+// WithScope is a functional option that sets the scope of the tokens (DefaultScope by default)
+func WithScope(scope string) CredentialOption {
+	return func(c *clientSecretCredential) {
+		c.scope = scope
+	}
+}
+
+// WithTokenHTTPClient is a functional option that sets the HTTP client of the token requests
+func WithTokenHTTPClient(httpClient *http.Client) CredentialOption {
+	return func(c *clientSecretCredential) {
+		c.httpClient = httpClient
+	}
+}
+
+// NewClientSecretCredential returns a token provider of the service principal (tenant, client id and secret),
+// the tokens are cached until one minute before their expiry.
+//
+// Example usage:
+//
+//	tokenProvider := azure.NewClientSecretCredential(tenantID, clientID, clientSecret)
+//	client, err := azure.NewClient(endpoint, []azure.Option{azure.WithTokenProvider(tokenProvider)})
+func NewClientSecretCredential(tenantID, clientID, clientSecret string, options ...CredentialOption) TokenProvider {
+	credential := &clientSecretCredential{
+		tenantID:      tenantID,
+		clientID:      clientID,
+		clientSecret:  clientSecret,
+		authorityHost: DefaultAuthorityHost,
+		scope:         DefaultScope,
+		httpClient:    http.DefaultClient,
+	}
+	for _, option := range options {
+		option(credential)
+	}
+	return credential.getToken
+}
+
+// NewEnvironmentCredential returns the token provider of the service principal of the AZURE_TENANT_ID,
+// AZURE_CLIENT_ID and AZURE_CLIENT_SECRET environment variables, false if one of them is missing
+func NewEnvironmentCredential(options ...CredentialOption) (TokenProvider, bool) {
+	tenantID, clientID, clientSecret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if tenantID == "" || clientID == "" || clientSecret == "" {
+		return nil, false
+	}
+	if authorityHost := os.Getenv("AZURE_AUTHORITY_HOST"); authorityHost != "" {
+		options = append([]CredentialOption{WithAuthorityHost(authorityHost)}, options...)
+	}
+	return NewClientSecretCredential(tenantID, clientID, clientSecret, options...), true
+}
+
+// getToken returns the cached token, or requests a new one when it expires
+func (c *clientSecretCredential) getToken(ctx context.Context) (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"scope":         {c.scope},
+	}
+	endpoint := fmt.Sprintf("%s/%s/oauth2/v2.0/token", c.authorityHost, url.PathEscape(c.tenantID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var payload struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return "", fmt.Errorf("invalid token response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode >= 400 || payload.AccessToken == "" {
+		return "", fmt.Errorf("token request failed (%s): %s %s", resp.Status, payload.Error, payload.ErrorDescription)
+	}
+	c.token = payload.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(payload.ExpiresIn)*time.Second - time.Minute)
+	return c.token, nil
+}
@@ -0,0 +1,198 @@
+// Package azure configures the OpenAI client for Azure OpenAI: the requests are routed to the deployment of their model,
+// the api-version query parameter is added, and they are authenticated with an API key or a Microsoft Entra ID (Azure AD) token.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// DefaultAPIVersion is the api-version used without WithAPIVersion
+const DefaultAPIVersion = "2024-10-21"
+
+// TokenProvider returns a Microsoft Entra ID access token for the Cognitive Services scope
+type TokenProvider func(ctx context.Context) (string, error)
+
+// Option is a functional option for configuring the Azure OpenAI client
+type Option func(*config)
+
+// config is the configuration of the Azure OpenAI client
+type config struct {
+	apiKey        string
+	apiVersion    string
+	tokenProvider TokenProvider
+	deployments   map[string]string
+	deployment    string
+}
+
+// WithAPIKey is a functional option that authenticates the requests with the api-key header
+func WithAPIKey(apiKey string) Option {
+	return func(c *config) {
+		c.apiKey = apiKey
+	}
+}
+
+// WithTokenProvider is a functional option that authenticates the requests with an Entra ID bearer token
+// (e.g. NewClientSecretCredential, or the GetToken method of an azidentity credential)
+func WithTokenProvider(tokenProvider TokenProvider) Option {
+	return func(c *config) {
+		c.tokenProvider = tokenProvider
+	}
+}
+
+// WithAPIVersion is a functional option that sets the api-version query parameter (DefaultAPIVersion by default)
+func WithAPIVersion(apiVersion string) Option {
+	return func(c *config) {
+		c.apiVersion = apiVersion
+	}
+}
+
+// WithDeployments is a functional option that maps the models of the requests to deployment names
+// (the model is the deployment name when it is not mapped)
+func WithDeployments(deployments map[string]string) Option {
+	return func(c *config) {
+		c.deployments = deployments
+	}
+}
+
+// WithDeployment is a functional option that routes all the requests to a deployment, whatever their model
+func WithDeployment(deployment string) Option {
+	return func(c *config) {
+		c.deployment = deployment
+	}
+}
+
+// RequestOptions returns the options of an OpenAI client for the Azure OpenAI resource at endpoint
+// (e.g. https://my-resource.openai.azure.com, a trailing /openai path is accepted).
+// Without WithAPIKey nor WithTokenProvider, the requests are authenticated with the AZURE_OPENAI_API_KEY environment variable.
+//
+// Example usage:
+//
+//	options, err := azure.RequestOptions("https://my-resource.openai.azure.com",
+//		azure.WithAPIKey(os.Getenv("AZURE_OPENAI_API_KEY")),
+//	)
+//	client := openai.NewClient(options...)
+func RequestOptions(endpoint string, options ...Option) ([]option.RequestOption, error) {
+	cfg := &config{apiVersion: DefaultAPIVersion}
+	for _, option := range options {
+		option(cfg)
+	}
+	baseURL, err := normalizeEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.apiKey != "" && cfg.tokenProvider != nil {
+		return nil, errors.New("azure: WithAPIKey and WithTokenProvider are exclusive")
+	}
+	if cfg.apiKey == "" && cfg.tokenProvider == nil {
+		cfg.apiKey = os.Getenv("AZURE_OPENAI_API_KEY")
+	}
+
+	requestOptions := []option.RequestOption{
+		option.WithBaseURL(baseURL),
+		option.WithQuery("api-version", cfg.apiVersion),
+		option.WithMiddleware(cfg.deploymentRouting),
+	}
+	if cfg.tokenProvider != nil {
+		requestOptions = append(requestOptions, option.WithMiddleware(cfg.bearerToken))
+	} else {
+		// The Authorization header of OPENAI_API_KEY is not sent to Azure
+		requestOptions = append(requestOptions, option.WithHeaderDel("Authorization"), option.WithHeader("api-key", cfg.apiKey))
+	}
+	return requestOptions, nil
+}
+
+// NewClient creates an OpenAI client for the Azure OpenAI resource at endpoint, the extra options are applied first
+//
+// Example usage:
+//
+//	client, err := azure.NewClient("https://my-resource.openai.azure.com", []azure.Option{
+//		azure.WithTokenProvider(azure.NewClientSecretCredential(tenantID, clientID, clientSecret)),
+//	})
+//	agent, err := mu.NewAgent(ctx, "Bob", mu.WithClient(client),
+//		mu.WithParams(openai.ChatCompletionNewParams{Model: "gpt-4o-deployment"}),
+//	)
+func NewClient(endpoint string, options []Option, extra ...option.RequestOption) (openai.Client, error) {
+	requestOptions, err := RequestOptions(endpoint, options...)
+	if err != nil {
+		return openai.Client{}, err
+	}
+	return openai.NewClient(append(extra, requestOptions...)...), nil
+}
+
+// normalizeEndpoint returns the base URL of the OpenAI routes of the resource endpoint
+func normalizeEndpoint(endpoint string) (string, error) {
+	if endpoint == "" {
+		return "", errors.New("azure: the endpoint of the Azure OpenAI resource is required")
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return "", fmt.Errorf("azure: invalid endpoint %q", endpoint)
+	}
+	path := strings.TrimSuffix(parsed.Path, "/")
+	if idx := strings.Index(path, "/openai"); idx >= 0 {
+		path = path[:idx]
+	}
+	parsed.Path = path + "/openai/"
+	parsed.RawQuery = ""
+	return parsed.String(), nil
+}
+
+// deploymentName returns the deployment of a model
+func (c *config) deploymentName(model string) string {
+	if c.deployment != "" {
+		return c.deployment
+	}
+	if deployment, ok := c.deployments[model]; ok {
+		return deployment
+	}
+	return model
+}
+
+// deploymentRouting rewrites /openai/<route> into /openai/deployments/<deployment>/<route>
+func (c *config) deploymentRouting(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	if !strings.Contains(req.URL.Path, "/openai/") || strings.Contains(req.URL.Path, "/openai/deployments/") {
+		return next(req)
+	}
+	model := c.deployment
+	if model == "" && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		var payload struct {
+			Model string `json:"model"`
+		}
+		if json.Unmarshal(body, &payload) == nil {
+			model = payload.Model
+		}
+	}
+	if deployment := c.deploymentName(model); deployment != "" {
+		req.URL.Path = strings.Replace(req.URL.Path, "/openai/", "/openai/deployments/"+url.PathEscape(deployment)+"/", 1)
+		req.URL.RawPath = ""
+	}
+	return next(req)
+}
+
+// bearerToken authenticates a request with a token of the provider
+func (c *config) bearerToken(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	token, err := c.tokenProvider(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("azure: failed to get the access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Del("api-key")
+	return next(req)
+}
@@ -0,0 +1,232 @@
+// Package dmr provides the helpers of Docker Model Runner: the detection of its endpoint (Docker Desktop,
+// a container, the MODEL_RUNNER_BASE_URL variable), the management of the models (list, pull with progress, delete)
+// and the OpenAI client of its OpenAI-compatible API.
+package dmr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Endpoints of Docker Model Runner
+const (
+	// DefaultBaseURL is the TCP endpoint of Docker Desktop and Docker Engine
+	DefaultBaseURL = "http://localhost:12434"
+	// ContainerBaseURL is the endpoint from the containers of Docker Desktop
+	ContainerBaseURL = "http://model-runner.docker.internal"
+	// DefaultEngine is the inference engine of the OpenAI-compatible API
+	DefaultEngine = "llama.cpp"
+)
+
+// ClientOption is a functional option for configuring Client instances
+type ClientOption func(*Client)
+
+// Client connects to Docker Model Runner: it manages its models and creates the OpenAI clients of its API
+type Client struct {
+	baseURL    string
+	engine     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client of Docker Model Runner at baseURL (DefaultBaseURL if empty).
+// The path of the OpenAI-compatible API (/engines/llama.cpp/v1) is removed.
+//
+// Example usage:
+//
+//	dmrClient := dmr.NewClient("")
+//	agent, err := mu.NewAgent(ctx, "Bob",
+//		mu.WithClient(dmrClient.OpenAIClient()),
+//		mu.WithParams(openai.ChatCompletionNewParams{Model: "ai/qwen2.5:latest"}),
+//	)
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	client := &Client{
+		baseURL:    rootURL(baseURL),
+		engine:     DefaultEngine,
+		httpClient: http.DefaultClient,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// WithHTTPClient is a functional option that sets the HTTP client of the model management requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithEngine is a functional option that sets the inference engine of the OpenAI-compatible API (llama.cpp by default)
+func WithEngine(engine string) ClientOption {
+	return func(c *Client) {
+		c.engine = engine
+	}
+}
+
+// rootURL removes the path of the OpenAI-compatible API from a URL
+func rootURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if idx := strings.Index(baseURL, "/engines"); idx >= 0 {
+		baseURL = baseURL[:idx]
+	}
+	return baseURL
+}
+
+// Detect returns a client of the first Docker Model Runner endpoint that answers: MODEL_RUNNER_BASE_URL if set,
+// then DefaultBaseURL and ContainerBaseURL. Each endpoint is probed for two seconds at most.
+//
+// Example usage:
+//
+//	dmrClient, err := dmr.Detect(ctx)
+//	if err != nil {
+//		log.Fatal("Docker Model Runner is not available: ", err)
+//	}
+func Detect(ctx context.Context, options ...ClientOption) (*Client, error) {
+	candidates := []string{DefaultBaseURL, ContainerBaseURL}
+	if baseURL := os.Getenv("MODEL_RUNNER_BASE_URL"); baseURL != "" {
+		candidates = append([]string{baseURL}, candidates...)
+	}
+	errs := []error{}
+	for _, candidate := range candidates {
+		client := NewClient(candidate, options...)
+		probeCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+		_, err := client.List(probeCtx)
+		cancel()
+		if err == nil {
+			return client, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("docker model runner not found: %w", errors.Join(errs...))
+}
+
+// BaseURL returns the URL of Docker Model Runner
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// OpenAIBaseURL returns the URL of the OpenAI-compatible API (e.g. http://localhost:12434/engines/llama.cpp/v1/)
+func (c *Client) OpenAIBaseURL() string {
+	return c.baseURL + "/engines/" + c.engine + "/v1/"
+}
+
+// RequestOptions returns the options of an OpenAI client of the API (Docker Model Runner requires no API key)
+func (c *Client) RequestOptions() []option.RequestOption {
+	return []option.RequestOption{
+		option.WithBaseURL(c.OpenAIBaseURL()),
+		option.WithAPIKey("dmr"),
+	}
+}
+
+// OpenAIClient returns an OpenAI client of the API, the options are applied after the options of the client
+func (c *Client) OpenAIClient(options ...option.RequestOption) openai.Client {
+	return openai.NewClient(append(c.RequestOptions(), options...)...)
+}
+
+// NewOpenAIClient detects Docker Model Runner, pulls the missing models and returns an OpenAI client of its API:
+// the one-line setup of the examples.
+//
+// Example usage:
+//
+//	client, err := dmr.NewOpenAIClient(ctx, "ai/qwen2.5:latest", "ai/mxbai-embed-large")
+func NewOpenAIClient(ctx context.Context, models ...string) (openai.Client, error) {
+	client, err := Detect(ctx)
+	if err != nil {
+		return openai.Client{}, err
+	}
+	for _, model := range models {
+		if err := client.EnsureModel(ctx, model, nil); err != nil {
+			return openai.Client{}, err
+		}
+	}
+	return client.OpenAIClient(), nil
+}
+
+// readError reads and closes the body of an error response
+func readError(resp *http.Response) string {
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var payload struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil {
+		if payload.Message != "" {
+			return payload.Message
+		}
+		if payload.Error != "" {
+			return payload.Error
+		}
+	}
+	if message := strings.TrimSpace(string(data)); message != "" {
+		return message
+	}
+	return resp.Status
+}
+
+// do sends a request to the model management API and decodes its JSON response into result (if not nil)
+func (c *Client) do(ctx context.Context, method, endpoint string, payload any, result any) error {
+	resp, err := c.send(ctx, method, endpoint, payload)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return &StatusError{Method: method, Endpoint: endpoint, StatusCode: resp.StatusCode, Message: readError(resp)}
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// send sends a request to the model management API
+func (c *Client) send(ctx context.Context, method, endpoint string, payload any) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.httpClient.Do(req)
+}
+
+// StatusError is an error response of the model management API
+type StatusError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Message    string
+}
+
+// Error implements the error interface for StatusError
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("docker model runner %s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, e.Message)
+}
@@ -0,0 +1,144 @@
+package dmr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Model is a model of Docker Model Runner, its tags are the names of the model (e.g. ai/smollm2:latest)
+type Model struct {
+	ID      string      `json:"id"`
+	Tags    []string    `json:"tags"`
+	Created int64       `json:"created"`
+	Config  ModelConfig `json:"config"`
+}
+
+// ModelConfig describes the format and the size of a model
+type ModelConfig struct {
+	Format       string `json:"format"`
+	Quantization string `json:"quantization"`
+	Parameters   string `json:"parameters"`
+	Architecture string `json:"architecture"`
+	Size         string `json:"size"`
+}
+
+// Name returns the first tag of the model, or its id
+func (m Model) Name() string {
+	if len(m.Tags) > 0 {
+		return m.Tags[0]
+	}
+	return m.ID
+}
+
+// PullProgress is a progress update of a pull: type is progress, success or error
+type PullProgress struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Total   int64  `json:"total,omitempty"`
+	Pulled  int64  `json:"pulled,omitempty"`
+	Layer   *struct {
+		ID      string `json:"id"`
+		Size    int64  `json:"size"`
+		Current int64  `json:"current"`
+	} `json:"layer,omitempty"`
+}
+
+// Percent returns the progress of the download (0 if the size is unknown)
+func (p PullProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Pulled) / float64(p.Total)
+}
+
+// List returns the models of Docker Model Runner
+func (c *Client) List(ctx context.Context) ([]Model, error) {
+	models := []Model{}
+	if err := c.do(ctx, http.MethodGet, "/models", nil, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+// Has reports whether a model is available (the latest tag is used without tag)
+func (c *Client) Has(ctx context.Context, model string) (bool, error) {
+	err := c.do(ctx, http.MethodGet, "/models/"+model, nil, nil)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Pull downloads a model, onProgress (optional) receives the progress updates
+//
+// Example usage:
+//
+//	err := dmrClient.Pull(ctx, "ai/qwen2.5:latest", func(progress dmr.PullProgress) {
+//		fmt.Printf("\r%s %.0f%%", progress.Message, progress.Percent())
+//	})
+func (c *Client) Pull(ctx context.Context, model string, onProgress func(PullProgress)) error {
+	resp, err := c.send(ctx, http.MethodPost, "/models/create", map[string]any{"from": model})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to pull %s: %s", model, readError(resp))
+	}
+
+	// The progress is a stream of JSON lines, older versions answer with plain text lines
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var progress PullProgress
+		if err := json.Unmarshal(line, &progress); err != nil {
+			progress = PullProgress{Type: "progress", Message: string(line)}
+		}
+		switch progress.Type {
+		case "error":
+			return fmt.Errorf("failed to pull %s: %s", model, progress.Message)
+		case "success":
+			if onProgress != nil {
+				onProgress(progress)
+			}
+			return nil
+		}
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	// Without final status, the pull succeeded when the model is available
+	if ok, err := c.Has(ctx, model); err != nil || !ok {
+		return fmt.Errorf("the pull of %s ended without success", model)
+	}
+	return nil
+}
+
+// EnsureModel pulls a model when it is not available, onProgress (optional) receives the progress of the pull
+func (c *Client) EnsureModel(ctx context.Context, model string, onProgress func(PullProgress)) error {
+	ok, err := c.Has(ctx, model)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	return c.Pull(ctx, model, onProgress)
+}
+
+// Delete removes a model from Docker Model Runner
+func (c *Client) Delete(ctx context.Context, model string) error {
+	return c.do(ctx, http.MethodDelete, "/models/"+model, nil, nil)
+}
@@ -0,0 +1,228 @@
+package gemini
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Content is a message of the Gemini API: its role is user or model
+type Content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []Part `json:"parts"`
+}
+
+// Part is a part of a content: a text (a thought with Thought), an image, a function call or a function response
+type Part struct {
+	Text             string            `json:"text,omitempty"`
+	Thought          bool              `json:"thought,omitempty"`
+	ThoughtSignature string            `json:"thoughtSignature,omitempty"`
+	InlineData       *Blob             `json:"inlineData,omitempty"`
+	FileData         *FileData         `json:"fileData,omitempty"`
+	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+}
+
+// Blob is an inline media (base64 data)
+type Blob struct {
+	MimeType string `json:"mimeType"`
+	Data     string `json:"data"`
+}
+
+// FileData is a media referenced by its URI
+type FileData struct {
+	MimeType string `json:"mimeType,omitempty"`
+	FileURI  string `json:"fileUri"`
+}
+
+// FunctionCall is a call of a function by the model
+type FunctionCall struct {
+	ID   string         `json:"id,omitempty"`
+	Name string         `json:"name"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// FunctionResponse is the result of a function call
+type FunctionResponse struct {
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Response map[string]any `json:"response"`
+}
+
+// Tool gathers the function declarations given to the model
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations"`
+}
+
+// FunctionDeclaration describes a function, its parameters are a JSON schema
+type FunctionDeclaration struct {
+	Name                 string `json:"name"`
+	Description          string `json:"description,omitempty"`
+	ParametersJSONSchema any    `json:"parametersJsonSchema,omitempty"`
+}
+
+// ToolConfig configures the function calling
+type ToolConfig struct {
+	FunctionCallingConfig FunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+// FunctionCallingConfig sets the function calling mode: AUTO, ANY or NONE
+type FunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GenerationConfig are the generation parameters
+type GenerationConfig struct {
+	Temperature        *float64        `json:"temperature,omitempty"`
+	TopP               *float64        `json:"topP,omitempty"`
+	MaxOutputTokens    *int64          `json:"maxOutputTokens,omitempty"`
+	StopSequences      []string        `json:"stopSequences,omitempty"`
+	Seed               *int64          `json:"seed,omitempty"`
+	PresencePenalty    *float64        `json:"presencePenalty,omitempty"`
+	FrequencyPenalty   *float64        `json:"frequencyPenalty,omitempty"`
+	ResponseMimeType   string          `json:"responseMimeType,omitempty"`
+	ResponseJSONSchema json.RawMessage `json:"responseJsonSchema,omitempty"`
+	ThinkingConfig     *ThinkingConfig `json:"thinkingConfig,omitempty"`
+}
+
+// ThinkingConfig configures the thinking of the model
+type ThinkingConfig struct {
+	IncludeThoughts bool   `json:"includeThoughts,omitempty"`
+	ThinkingBudget  *int64 `json:"thinkingBudget,omitempty"`
+}
+
+// GenerateContentRequest is the request of generateContent and streamGenerateContent
+type GenerateContentRequest struct {
+	Contents          []Content         `json:"contents"`
+	SystemInstruction *Content          `json:"systemInstruction,omitempty"`
+	Tools             []Tool            `json:"tools,omitempty"`
+	ToolConfig        *ToolConfig       `json:"toolConfig,omitempty"`
+	GenerationConfig  *GenerationConfig `json:"generationConfig,omitempty"`
+}
+
+// Candidate is a generated answer
+type Candidate struct {
+	Content      Content `json:"content"`
+	FinishReason string  `json:"finishReason"`
+}
+
+// UsageMetadata is the token usage of a request
+type UsageMetadata struct {
+	PromptTokenCount     int64 `json:"promptTokenCount"`
+	CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+	ThoughtsTokenCount   int64 `json:"thoughtsTokenCount"`
+	TotalTokenCount      int64 `json:"totalTokenCount"`
+}
+
+// GenerateContentResponse is the response (or a streamed chunk) of the generation
+type GenerateContentResponse struct {
+	Candidates     []Candidate   `json:"candidates"`
+	UsageMetadata  UsageMetadata `json:"usageMetadata"`
+	PromptFeedback *struct {
+		BlockReason string `json:"blockReason"`
+	} `json:"promptFeedback,omitempty"`
+}
+
+// APIError is an error returned by the Gemini API
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+}
+
+// Error implements the error interface for APIError
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gemini API error %d %s: %s", e.StatusCode, e.Status, e.Message)
+}
+
+// post sends a request to a method of a model (e.g. generateContent)
+func (agent *Agent) post(ctx context.Context, model, method string, query url.Values, payload any) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:%s", agent.baseURL, strings.TrimPrefix(model, "models/"), method)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-goog-api-key", agent.apiKey)
+	agent.log().Debug("gemini request", "model", model, "method", method)
+	resp, err := agent.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 400 {
+		defer resp.Body.Close()
+		return nil, readAPIError(resp)
+	}
+	return resp, nil
+}
+
+// readAPIError reads the error of a response
+func readAPIError(resp *http.Response) error {
+	data, _ := io.ReadAll(resp.Body)
+	var payload struct {
+		Error struct {
+			Message string `json:"message"`
+			Status  string `json:"status"`
+		} `json:"error"`
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode, Status: resp.Status, Message: strings.TrimSpace(string(data))}
+	if json.Unmarshal(data, &payload) == nil && payload.Error.Message != "" {
+		apiErr.Status = payload.Error.Status
+		apiErr.Message = payload.Error.Message
+	}
+	return apiErr
+}
+
+// generateContent generates a complete answer
+func (agent *Agent) generateContent(request GenerateContentRequest) (*GenerateContentResponse, error) {
+	resp, err := agent.post(agent.ctx, agent.Params.Model, "generateContent", nil, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	response := &GenerateContentResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return nil, fmt.Errorf("invalid response of the Gemini API: %w", err)
+	}
+	return response, nil
+}
+
+// streamGenerateContent generates an answer as server-sent events, onChunk is called for each chunk
+// and stops the stream when it returns an error
+func (agent *Agent) streamGenerateContent(request GenerateContentRequest, onChunk func(chunk *GenerateContentResponse) error) error {
+	resp, err := agent.post(agent.ctx, agent.Params.Model, "streamGenerateContent", url.Values{"alt": {"sse"}}, request)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data, found := strings.CutPrefix(scanner.Text(), "data:")
+		if !found {
+			continue
+		}
+		chunk := &GenerateContentResponse{}
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), chunk); err != nil {
+			return fmt.Errorf("invalid stream of the Gemini API: %w", err)
+		}
+		if err := onChunk(chunk); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
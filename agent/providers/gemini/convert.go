@@ -0,0 +1,352 @@
+package gemini
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// openAIMessage is the JSON of an OpenAI message
+type openAIMessage struct {
+	Role       string          `json:"role"`
+	Content    json.RawMessage `json:"content"`
+	ToolCallID string          `json:"tool_call_id"`
+	ToolCalls  []struct {
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	} `json:"tool_calls"`
+}
+
+// openAIContentPart is the JSON of a part of a multimodal OpenAI content
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// openAIParams is the JSON of the OpenAI completion parameters converted to the generation config
+type openAIParams struct {
+	Temperature         *float64        `json:"temperature"`
+	TopP                *float64        `json:"top_p"`
+	MaxTokens           *int64          `json:"max_tokens"`
+	MaxCompletionTokens *int64          `json:"max_completion_tokens"`
+	Seed                *int64          `json:"seed"`
+	PresencePenalty     *float64        `json:"presence_penalty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty"`
+	Stop                json.RawMessage `json:"stop"`
+	ToolChoice          json.RawMessage `json:"tool_choice"`
+	ResponseFormat      *struct {
+		Type       string `json:"type"`
+		JSONSchema *struct {
+			Schema json.RawMessage `json:"schema"`
+		} `json:"json_schema"`
+	} `json:"response_format"`
+}
+
+// ToContents converts OpenAI messages to the system instruction (nil without system message) and the contents of Gemini.
+// The consecutive messages of the same role are merged: e.g. the results of parallel tool calls.
+//
+// Example usage:
+//
+//	system, contents, err := gemini.ToContents(agent.GetMessages())
+func ToContents(messages []openai.ChatCompletionMessageParamUnion) (*Content, []Content, error) {
+	return toContents(messages, nil)
+}
+
+// toContents converts OpenAI messages, the thought signatures of the function calls are restored by tool call id
+func toContents(messages []openai.ChatCompletionMessageParamUnion, signatures map[string]string) (*Content, []Content, error) {
+	var system *Content
+	contents := []Content{}
+	// The function responses are identified by the name of the function instead of the id of the call
+	functionNames := map[string]string{}
+	add := func(role string, parts ...Part) {
+		if len(parts) == 0 {
+			return
+		}
+		if last := len(contents) - 1; last >= 0 && contents[last].Role == role {
+			contents[last].Parts = append(contents[last].Parts, parts...)
+			return
+		}
+		contents = append(contents, Content{Role: role, Parts: parts})
+	}
+
+	for idx, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, nil, err
+		}
+		var msg openAIMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return nil, nil, err
+		}
+		parts, err := contentParts(msg.Content)
+		if err != nil {
+			return nil, nil, fmt.Errorf("message %d: %w", idx, err)
+		}
+		switch msg.Role {
+		case "system", "developer":
+			if system == nil {
+				system = &Content{}
+			}
+			system.Parts = append(system.Parts, parts...)
+		case "user":
+			add("user", parts...)
+		case "assistant":
+			for _, call := range msg.ToolCalls {
+				functionNames[call.ID] = call.Function.Name
+				args := map[string]any{}
+				if call.Function.Arguments != "" {
+					if err := json.Unmarshal([]byte(call.Function.Arguments), &args); err != nil {
+						return nil, nil, fmt.Errorf("message %d: invalid arguments of %s: %w", idx, call.Function.Name, err)
+					}
+				}
+				parts = append(parts, Part{
+					FunctionCall:     &FunctionCall{Name: call.Function.Name, Args: args},
+					ThoughtSignature: signatures[call.ID],
+				})
+			}
+			add("model", parts...)
+		case "tool":
+			text := ""
+			for _, part := range parts {
+				text += part.Text
+			}
+			add("user", Part{FunctionResponse: &FunctionResponse{
+				Name:     functionNames[msg.ToolCallID],
+				Response: functionResponse(text),
+			}})
+		default:
+			return nil, nil, fmt.Errorf("message %d: unsupported role %q", idx, msg.Role)
+		}
+	}
+	return system, contents, nil
+}
+
+// functionResponse returns the result of a tool as a JSON object (the other values are wrapped in a result field)
+func functionResponse(result string) map[string]any {
+	var object map[string]any
+	if json.Unmarshal([]byte(result), &object) == nil && object != nil {
+		return object
+	}
+	var value any
+	if json.Unmarshal([]byte(result), &value) == nil {
+		return map[string]any{"result": value}
+	}
+	return map[string]any{"result": result}
+}
+
+// contentParts converts an OpenAI content (a string or an array of parts) to Gemini parts
+func contentParts(content json.RawMessage) ([]Part, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return nil, nil
+	}
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		if text == "" {
+			return nil, nil
+		}
+		return []Part{{Text: text}}, nil
+	}
+	var openAIParts []openAIContentPart
+	if err := json.Unmarshal(content, &openAIParts); err != nil {
+		return nil, fmt.Errorf("invalid content: %w", err)
+	}
+	parts := []Part{}
+	for _, part := range openAIParts {
+		switch part.Type {
+		case "text":
+			parts = append(parts, Part{Text: part.Text})
+		case "image_url":
+			parts = append(parts, imagePart(part.ImageURL.URL))
+		}
+	}
+	return parts, nil
+}
+
+// imagePart converts an image URL: the data URLs are inline data, the other URLs are file data
+func imagePart(url string) Part {
+	if header, data, found := strings.Cut(url, ";base64,"); found && strings.HasPrefix(header, "data:") {
+		return Part{InlineData: &Blob{MimeType: strings.TrimPrefix(header, "data:"), Data: data}}
+	}
+	return Part{FileData: &FileData{MimeType: mime.TypeByExtension(path.Ext(url)), FileURI: url}}
+}
+
+// ToTools converts the OpenAI function tools to Gemini function declarations
+func ToTools(tools []openai.ChatCompletionToolUnionParam) ([]Tool, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+	declarations := []FunctionDeclaration{}
+	for _, tool := range tools {
+		function := tool.GetFunction()
+		if function == nil {
+			return nil, errors.New("only the function tools are supported by Gemini")
+		}
+		declaration := FunctionDeclaration{Name: function.Name, Description: function.Description.Value}
+		if len(function.Parameters) > 0 {
+			declaration.ParametersJSONSchema = function.Parameters
+		}
+		declarations = append(declarations, declaration)
+	}
+	return []Tool{{FunctionDeclarations: declarations}}, nil
+}
+
+// toolConfig converts the OpenAI tool choice (auto, none, required or a function)
+func toolConfig(toolChoice json.RawMessage) *ToolConfig {
+	if len(toolChoice) == 0 || string(toolChoice) == "null" {
+		return nil
+	}
+	var choice string
+	if json.Unmarshal(toolChoice, &choice) == nil {
+		switch choice {
+		case "none":
+			return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "NONE"}}
+		case "required":
+			return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "ANY"}}
+		}
+		return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "AUTO"}}
+	}
+	var function struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if json.Unmarshal(toolChoice, &function) == nil && function.Function.Name != "" {
+		return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "ANY", AllowedFunctionNames: []string{function.Function.Name}}}
+	}
+	return nil
+}
+
+// ToRequest converts OpenAI completion parameters and their messages to a Gemini generation request
+func ToRequest(params openai.ChatCompletionNewParams) (GenerateContentRequest, error) {
+	return toRequest(params, nil)
+}
+
+// toRequest converts the completion parameters, the thought signatures are restored by tool call id
+func toRequest(params openai.ChatCompletionNewParams, signatures map[string]string) (GenerateContentRequest, error) {
+	request := GenerateContentRequest{}
+	system, contents, err := toContents(params.Messages, signatures)
+	if err != nil {
+		return request, err
+	}
+	request.SystemInstruction, request.Contents = system, contents
+	if request.Tools, err = ToTools(params.Tools); err != nil {
+		return request, err
+	}
+
+	// The other parameters are read from their JSON, which resolves the optional and union values
+	params.Messages, params.Tools = nil, nil
+	data, err := json.Marshal(params)
+	if err != nil {
+		return request, err
+	}
+	var openAI openAIParams
+	if err := json.Unmarshal(data, &openAI); err != nil {
+		return request, err
+	}
+	request.ToolConfig = toolConfig(openAI.ToolChoice)
+	config := &GenerationConfig{
+		Temperature:      openAI.Temperature,
+		TopP:             openAI.TopP,
+		MaxOutputTokens:  openAI.MaxTokens,
+		Seed:             openAI.Seed,
+		PresencePenalty:  openAI.PresencePenalty,
+		FrequencyPenalty: openAI.FrequencyPenalty,
+	}
+	if openAI.MaxCompletionTokens != nil {
+		config.MaxOutputTokens = openAI.MaxCompletionTokens
+	}
+	if len(openAI.Stop) > 0 && string(openAI.Stop) != "null" {
+		var stop string
+		if json.Unmarshal(openAI.Stop, &stop) == nil {
+			config.StopSequences = []string{stop}
+		} else if err := json.Unmarshal(openAI.Stop, &config.StopSequences); err != nil {
+			return request, fmt.Errorf("invalid stop sequences: %w", err)
+		}
+	}
+	if format := openAI.ResponseFormat; format != nil {
+		switch format.Type {
+		case "json_object":
+			config.ResponseMimeType = "application/json"
+		case "json_schema":
+			config.ResponseMimeType = "application/json"
+			if format.JSONSchema != nil {
+				config.ResponseJSONSchema = format.JSONSchema.Schema
+			}
+		}
+	}
+	request.GenerationConfig = config
+	return request, nil
+}
+
+// ToAssistantMessage converts a content generated by the model to an OpenAI assistant message
+// (the thoughts are left out, the function calls are tool calls)
+func ToAssistantMessage(content Content) openai.ChatCompletionMessageParamUnion {
+	text, _, calls := splitParts(content.Parts)
+	return generation{text: text, calls: calls}.assistantMessage()
+}
+
+// toolCall is a function call of the model with its OpenAI id and JSON arguments
+type toolCall struct {
+	id, name, arguments, signature string
+}
+
+// splitParts returns the text, the thoughts and the function calls of the parts
+func splitParts(parts []Part) (string, string, []toolCall) {
+	var text, thoughts strings.Builder
+	calls := []toolCall{}
+	for _, part := range parts {
+		switch {
+		case part.FunctionCall != nil:
+			arguments, _ := json.Marshal(part.FunctionCall.Args)
+			if part.FunctionCall.Args == nil {
+				arguments = []byte("{}")
+			}
+			id := part.FunctionCall.ID
+			if id == "" {
+				id = "call_" + randomID()
+			}
+			calls = append(calls, toolCall{id: id, name: part.FunctionCall.Name, arguments: string(arguments), signature: part.ThoughtSignature})
+		case part.Thought:
+			thoughts.WriteString(part.Text)
+		default:
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String(), thoughts.String(), calls
+}
+
+// finishReason returns the OpenAI finish reason of a candidate
+func finishReason(candidate Candidate, hasToolCalls bool) string {
+	switch {
+	case hasToolCalls:
+		return "tool_calls"
+	case candidate.FinishReason == "STOP" || candidate.FinishReason == "":
+		return "stop"
+	case candidate.FinishReason == "MAX_TOKENS":
+		return "length"
+	case candidate.FinishReason == "SAFETY", candidate.FinishReason == "RECITATION", candidate.FinishReason == "BLOCKLIST",
+		candidate.FinishReason == "PROHIBITED_CONTENT", candidate.FinishReason == "SPII":
+		return "content_filter"
+	}
+	return strings.ToLower(candidate.FinishReason)
+}
+
+// randomID returns a random identifier of the tool calls
+func randomID() string {
+	data := make([]byte, 12)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
@@ -0,0 +1,258 @@
+// Package gemini implements the Agent interface with the Google Gemini API (content generation, function calling,
+// streaming, embeddings). The agent keeps its messages and parameters as OpenAI types, so the tools, the RAG helpers
+// and the UI of the library are used unchanged; the conversion helpers translate them to the Gemini contents.
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// DefaultBaseURL is the URL of the Gemini API
+const DefaultBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+
+// Agent is an agent using a Gemini model
+type Agent struct {
+	ctx             context.Context
+	apiKey          string
+	baseURL         string
+	httpClient      *http.Client
+	Params          openai.ChatCompletionNewParams
+	EmbeddingParams openai.EmbeddingNewParams
+	Name            string
+	Description     string
+	MetaData        any
+	logger          logging.Logger
+	// signatures are the thought signatures of the function calls by tool call id, sent back with the calls
+	signatures map[string]string
+}
+
+// Agent implements the agent interface of the library
+var _ mu.Agent = (*Agent)(nil)
+
+// AgentOption is a functional option for configuring Gemini agents
+type AgentOption func(*Agent)
+
+// NewAgent creates a Gemini agent, the API key is GEMINI_API_KEY unless WithAPIKey is given.
+// The model, the tools and the generation parameters are set with OpenAI parameters.
+//
+// Example usage:
+//
+//	agent, err := gemini.NewAgent(ctx, "Bob",
+//		gemini.WithParams(openai.ChatCompletionNewParams{
+//			Model:       "gemini-2.5-flash",
+//			Temperature: openai.Opt(0.0),
+//			Tools:       mcpClient.OpenAITools(),
+//		}),
+//	)
+func NewAgent(ctx context.Context, name string, options ...AgentOption) (mu.Agent, error) {
+	agent := &Agent{
+		ctx:        ctx,
+		Name:       name,
+		apiKey:     os.Getenv("GEMINI_API_KEY"),
+		baseURL:    DefaultBaseURL,
+		httpClient: http.DefaultClient,
+		signatures: map[string]string{},
+	}
+	for _, option := range options {
+		option(agent)
+	}
+	return agent, nil
+}
+
+// WithAPIKey is a functional option that sets the API key
+func WithAPIKey(apiKey string) AgentOption {
+	return func(agent *Agent) {
+		agent.apiKey = apiKey
+	}
+}
+
+// WithBaseURL is a functional option that sets the URL of the API (e.g. a proxy)
+func WithBaseURL(baseURL string) AgentOption {
+	return func(agent *Agent) {
+		agent.baseURL = strings.TrimSuffix(baseURL, "/")
+	}
+}
+
+// WithHTTPClient is a functional option that sets the HTTP client of the requests
+func WithHTTPClient(httpClient *http.Client) AgentOption {
+	return func(agent *Agent) {
+		agent.httpClient = httpClient
+	}
+}
+
+// WithParams is a functional option that sets the model, the tools and the generation parameters
+func WithParams(params openai.ChatCompletionNewParams) AgentOption {
+	return func(agent *Agent) {
+		agent.Params = params
+	}
+}
+
+// WithEmbeddingParams is a functional option that sets the embedding model (e.g. gemini-embedding-001)
+// and the dimensions of the embeddings
+func WithEmbeddingParams(embeddingParams openai.EmbeddingNewParams) AgentOption {
+	return func(agent *Agent) {
+		agent.EmbeddingParams = embeddingParams
+	}
+}
+
+// WithDescription is a functional option that sets the description of the agent
+func WithDescription(description string) AgentOption {
+	return func(agent *Agent) {
+		agent.Description = description
+	}
+}
+
+// WithLogger is a functional option that sets the logger of the agent
+func WithLogger(logger logging.Logger) AgentOption {
+	return func(agent *Agent) {
+		agent.logger = logger
+	}
+}
+
+// log returns the logger of the agent
+func (agent *Agent) log() logging.Logger {
+	return logging.OrDefault(agent.logger)
+}
+
+// GetMessages returns the messages of the agent
+func (agent *Agent) GetMessages() []openai.ChatCompletionMessageParamUnion {
+	return agent.Params.Messages
+}
+
+// GetFirstNMessages returns the first n messages of the agent
+func (agent *Agent) GetFirstNMessages(n int) []openai.ChatCompletionMessageParamUnion {
+	if n <= 0 {
+		return []openai.ChatCompletionMessageParamUnion{}
+	}
+	return agent.Params.Messages[:min(n, len(agent.Params.Messages))]
+}
+
+// GetLastNMessages returns the last n messages of the agent
+func (agent *Agent) GetLastNMessages(n int) []openai.ChatCompletionMessageParamUnion {
+	if n <= 0 {
+		return []openai.ChatCompletionMessageParamUnion{}
+	}
+	return agent.Params.Messages[max(len(agent.Params.Messages)-n, 0):]
+}
+
+// GetLastMessage returns the last message, false if there is none
+func (agent *Agent) GetLastMessage() (openai.ChatCompletionMessageParamUnion, bool) {
+	if len(agent.Params.Messages) == 0 {
+		var zero openai.ChatCompletionMessageParamUnion
+		return zero, false
+	}
+	return agent.Params.Messages[len(agent.Params.Messages)-1], true
+}
+
+// SetMessages replaces the messages of the agent
+func (agent *Agent) SetMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	agent.Params.Messages = messages
+}
+
+// AddMessage adds a message at the end of the messages
+func (agent *Agent) AddMessage(message openai.ChatCompletionMessageParamUnion) {
+	agent.Params.Messages = append(agent.Params.Messages, message)
+}
+
+// AddMessages adds messages at the end of the messages
+func (agent *Agent) AddMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	agent.Params.Messages = append(agent.Params.Messages, messages...)
+}
+
+// PrependMessage adds a message at the beginning of the messages
+func (agent *Agent) PrependMessage(message openai.ChatCompletionMessageParamUnion) {
+	agent.Params.Messages = append([]openai.ChatCompletionMessageParamUnion{message}, agent.Params.Messages...)
+}
+
+// PrependMessages adds messages at the beginning of the messages
+func (agent *Agent) PrependMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	agent.Params.Messages = append(messages, agent.Params.Messages...)
+}
+
+// ResetMessages clears the messages
+func (agent *Agent) ResetMessages() {
+	agent.Params.Messages = nil
+}
+
+// RemoveLastMessage removes the last message
+func (agent *Agent) RemoveLastMessage() {
+	agent.RemoveLastNMessages(1)
+}
+
+// RemoveLastNMessages removes the last n messages
+func (agent *Agent) RemoveLastNMessages(n int) {
+	if n <= 0 {
+		return
+	}
+	if n >= len(agent.Params.Messages) {
+		agent.Params.Messages = nil
+		return
+	}
+	agent.Params.Messages = agent.Params.Messages[:len(agent.Params.Messages)-n]
+}
+
+// RemoveFirstMessage removes the first message
+func (agent *Agent) RemoveFirstMessage() {
+	if len(agent.Params.Messages) > 0 {
+		agent.Params.Messages = agent.Params.Messages[1:]
+	}
+}
+
+// GetResponseFormat returns the response format (text, JSON object or JSON schema)
+func (agent *Agent) GetResponseFormat() openai.ChatCompletionNewParamsResponseFormatUnion {
+	return agent.Params.ResponseFormat
+}
+
+// SetResponseFormat sets the response format (text, JSON object or JSON schema)
+func (agent *Agent) SetResponseFormat(format openai.ChatCompletionNewParamsResponseFormatUnion) {
+	agent.Params.ResponseFormat = format
+}
+
+// GetName returns the name of the agent
+func (agent *Agent) GetName() string {
+	return agent.Name
+}
+
+// SetName sets the name of the agent
+func (agent *Agent) SetName(name string) {
+	agent.Name = name
+}
+
+// GetModel returns the Gemini model
+func (agent *Agent) GetModel() shared.ChatModel {
+	return agent.Params.Model
+}
+
+// SetModel sets the Gemini model
+func (agent *Agent) SetModel(model shared.ChatModel) {
+	agent.Params.Model = model
+}
+
+// GetDescription returns the description of the agent
+func (agent *Agent) GetDescription() string {
+	return agent.Description
+}
+
+// SetDescription sets the description of the agent
+func (agent *Agent) SetDescription(description string) {
+	agent.Description = description
+}
+
+// GetMetaData returns the metadata of the agent
+func (agent *Agent) GetMetaData() any {
+	return agent.MetaData
+}
+
+// SetMetaData sets the metadata of the agent
+func (agent *Agent) SetMetaData(metaData any) {
+	agent.MetaData = metaData
+}
@@ -0,0 +1,273 @@
+package gemini
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared/constant"
+)
+
+// generation is an answer of the model
+type generation struct {
+	text, thoughts string
+	calls          []toolCall
+	finishReason   string
+}
+
+// assistantMessage returns the OpenAI assistant message of the answer, with its tool calls
+func (g generation) assistantMessage() openai.ChatCompletionMessageParamUnion {
+	if len(g.calls) == 0 {
+		return openai.AssistantMessage(g.text)
+	}
+	assistant := &openai.ChatCompletionAssistantMessageParam{}
+	if g.text != "" {
+		assistant.Content.OfString = openai.String(g.text)
+	}
+	for _, call := range g.calls {
+		assistant.ToolCalls = append(assistant.ToolCalls, openai.ChatCompletionMessageToolCallUnionParam{
+			OfFunction: &openai.ChatCompletionMessageFunctionToolCallParam{
+				ID:   call.id,
+				Type: constant.Function("function"),
+				Function: openai.ChatCompletionMessageFunctionToolCallFunctionParam{
+					Name:      call.name,
+					Arguments: call.arguments,
+				},
+			},
+		})
+	}
+	return openai.ChatCompletionMessageParamUnion{OfAssistant: assistant}
+}
+
+// request converts the parameters and the messages of the agent, includeThoughts asks for the thoughts of the model
+func (agent *Agent) request(includeThoughts bool) (GenerateContentRequest, error) {
+	request, err := toRequest(agent.Params, agent.signatures)
+	if err != nil {
+		return request, err
+	}
+	if includeThoughts {
+		request.GenerationConfig.ThinkingConfig = &ThinkingConfig{IncludeThoughts: true}
+	}
+	return request, nil
+}
+
+// recordSignatures keeps the thought signatures of the function calls, they are sent back with the calls
+func (agent *Agent) recordSignatures(calls []toolCall) {
+	for _, call := range calls {
+		if call.signature != "" {
+			agent.signatures[call.id] = call.signature
+		}
+	}
+}
+
+// blockedError returns the error of a response without candidate
+func blockedError(response *GenerateContentResponse) error {
+	if response.PromptFeedback != nil && response.PromptFeedback.BlockReason != "" {
+		return fmt.Errorf("the prompt was blocked by Gemini: %s", response.PromptFeedback.BlockReason)
+	}
+	return errors.New("no candidates found")
+}
+
+// generate generates a complete answer with the messages of the agent
+func (agent *Agent) generate(includeThoughts bool) (generation, error) {
+	request, err := agent.request(includeThoughts)
+	if err != nil {
+		return generation{}, err
+	}
+	response, err := agent.generateContent(request)
+	if err != nil {
+		return generation{}, err
+	}
+	if len(response.Candidates) == 0 {
+		return generation{}, blockedError(response)
+	}
+	candidate := response.Candidates[0]
+	text, thoughts, calls := splitParts(candidate.Content.Parts)
+	agent.recordSignatures(calls)
+	return generation{text: text, thoughts: thoughts, calls: calls, finishReason: finishReason(candidate, len(calls) > 0)}, nil
+}
+
+// generateStream streams an answer with the messages of the agent, the callbacks (optional) receive the text and the thoughts.
+// The partial answer is returned with the error of a callback.
+func (agent *Agent) generateStream(includeThoughts bool, onContent, onThought func(string) error) (generation, error) {
+	request, err := agent.request(includeThoughts)
+	if err != nil {
+		return generation{}, err
+	}
+	var text, thoughts strings.Builder
+	calls := []toolCall{}
+	var last Candidate
+	received := false
+	err = agent.streamGenerateContent(request, func(chunk *GenerateContentResponse) error {
+		if len(chunk.Candidates) == 0 {
+			if !received {
+				return blockedError(chunk)
+			}
+			return nil
+		}
+		received = true
+		last = chunk.Candidates[0]
+		chunkText, chunkThoughts, chunkCalls := splitParts(last.Content.Parts)
+		calls = append(calls, chunkCalls...)
+		if chunkThoughts != "" {
+			thoughts.WriteString(chunkThoughts)
+			if onThought != nil {
+				if err := onThought(chunkThoughts); err != nil {
+					return err
+				}
+			}
+		}
+		if chunkText != "" {
+			text.WriteString(chunkText)
+			if onContent != nil {
+				if err := onContent(chunkText); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	agent.recordSignatures(calls)
+	result := generation{text: text.String(), thoughts: thoughts.String(), calls: calls, finishReason: finishReason(last, len(calls) > 0)}
+	if err == nil && !received {
+		err = errors.New("no candidates found")
+	}
+	return result, err
+}
+
+// Run generates an answer to the messages added to the conversation of the agent
+func (agent *Agent) Run(Messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+	agent.Params.Messages = append(agent.Params.Messages, Messages...)
+	result, err := agent.generate(false)
+	if err != nil {
+		return "", err
+	}
+	agent.Params.Messages = append(agent.Params.Messages, openai.AssistantMessage(result.text))
+	return result.text, nil
+}
+
+// RunWithReasoning generates an answer and returns the thoughts of the model with it
+func (agent *Agent) RunWithReasoning(Messages []openai.ChatCompletionMessageParamUnion) (string, string, error) {
+	agent.Params.Messages = append(agent.Params.Messages, Messages...)
+	result, err := agent.generate(true)
+	if err != nil {
+		return "", "", err
+	}
+	agent.Params.Messages = append(agent.Params.Messages, openai.AssistantMessage(result.text))
+	return result.text, result.thoughts, nil
+}
+
+// RunStream streams an answer to the callback, an *mu.ExitStreamCompletionError stops the stream
+func (agent *Agent) RunStream(Messages []openai.ChatCompletionMessageParamUnion, callBack func(content string) error) (string, error) {
+	agent.Params.Messages = append(agent.Params.Messages, Messages...)
+	result, err := agent.generateStream(false, callBack, nil)
+	if err != nil {
+		return result.text, err
+	}
+	agent.Params.Messages = append(agent.Params.Messages, openai.AssistantMessage(result.text))
+	return result.text, nil
+}
+
+// RunStreamWithReasoning streams an answer and the thoughts of the model to the callbacks
+func (agent *Agent) RunStreamWithReasoning(Messages []openai.ChatCompletionMessageParamUnion, contentCallback func(content string) error, reasoningCallback func(reasoning string) error) (string, string, error) {
+	agent.Params.Messages = append(agent.Params.Messages, Messages...)
+	result, err := agent.generateStream(true, contentCallback, reasoningCallback)
+	if err != nil {
+		return result.text, result.thoughts, err
+	}
+	agent.Params.Messages = append(agent.Params.Messages, openai.AssistantMessage(result.text))
+	return result.text, result.thoughts, nil
+}
+
+// DetectToolCalls runs the function calling loop: the calls of the model are executed by the callback and their results
+// sent back until the model answers. It returns the finish reason, the results of the calls and the final answer.
+func (agent *Agent) DetectToolCalls(messages []openai.ChatCompletionMessageParamUnion, toolCallBack func(functionName string, arguments string) (string, error)) (string, []string, string, error) {
+	return agent.detectToolCalls(messages, toolCallBack, func() (generation, error) {
+		return agent.generate(false)
+	})
+}
+
+// DetectToolCallsStream runs the function calling loop and streams the text of the answers to the stream callback
+func (agent *Agent) DetectToolCallsStream(messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error), streamCallback func(content string) error) (string, []string, string, error) {
+	return agent.detectToolCalls(messages, toolCallback, func() (generation, error) {
+		return agent.generateStream(false, streamCallback, nil)
+	})
+}
+
+// detectToolCalls runs the function calling loop with a generation function
+func (agent *Agent) detectToolCalls(messages []openai.ChatCompletionMessageParamUnion, toolCallback func(functionName string, arguments string) (string, error), generate func() (generation, error)) (string, []string, string, error) {
+	results := []string{}
+	for {
+		agent.Params.Messages = messages
+		result, err := generate()
+		if err != nil {
+			return "", results, "", err
+		}
+		messages = append(messages, result.assistantMessage())
+		agent.Params.Messages = messages
+
+		switch result.finishReason {
+		case "tool_calls":
+			finishReason := result.finishReason
+			for _, call := range result.calls {
+				agent.log().Debug("tool call", "agent", agent.Name, "function", call.name)
+				resultContent, errExec := toolCallback(call.name, call.arguments)
+				if errExec != nil {
+					agent.log().Debug("tool call failed", "agent", agent.Name, "function", call.name, "error", errExec)
+					var exitErr *mu.ExitToolCallsLoopError
+					if errors.As(errExec, &exitErr) {
+						finishReason = "exit_loop"
+					} else {
+						data, _ := json.Marshal(map[string]string{"error": "Function execution failed: " + errExec.Error()})
+						resultContent = string(data)
+					}
+				}
+				if resultContent == "" {
+					resultContent = `{"error": "Function execution returned empty result"}`
+				}
+				results = append(results, resultContent)
+				messages = append(messages, openai.ToolMessage(resultContent, call.id))
+			}
+			agent.Params.Messages = messages
+			if finishReason == "exit_loop" {
+				return finishReason, results, "", nil
+			}
+		case "stop":
+			return result.finishReason, results, result.text, nil
+		default:
+			agent.log().Debug("unexpected finish reason", "agent", agent.Name, "finish_reason", result.finishReason)
+			return result.finishReason, results, "", nil
+		}
+	}
+}
+
+// GenerateEmbeddingVector computes the embedding of a content with the embedding model
+func (agent *Agent) GenerateEmbeddingVector(content string) ([]float64, error) {
+	payload := map[string]any{
+		"content": Content{Parts: []Part{{Text: content}}},
+	}
+	if agent.EmbeddingParams.Dimensions.Valid() {
+		payload["outputDimensionality"] = agent.EmbeddingParams.Dimensions.Value
+	}
+	resp, err := agent.post(agent.ctx, agent.EmbeddingParams.Model, "embedContent", nil, payload)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var response struct {
+		Embedding struct {
+			Values []float64 `json:"values"`
+		} `json:"embedding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("invalid embedding response of the Gemini API: %w", err)
+	}
+	if len(response.Embedding.Values) == 0 {
+		return nil, errors.New("no embedding returned")
+	}
+	return response.Embedding.Values, nil
+}
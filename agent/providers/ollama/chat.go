@@ -0,0 +1,553 @@
+package ollama
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// --- OpenAI payloads ---
+
+// openAIChatRequest is the part of the OpenAI chat completion request supported by the native API
+type openAIChatRequest struct {
+	Model         string            `json:"model"`
+	Messages      []openAIMessage   `json:"messages"`
+	Tools         []json.RawMessage `json:"tools"`
+	Stream        bool              `json:"stream"`
+	StreamOptions *struct {
+		IncludeUsage bool `json:"include_usage"`
+	} `json:"stream_options"`
+	Temperature         *float64        `json:"temperature"`
+	TopP                *float64        `json:"top_p"`
+	Seed                *int64          `json:"seed"`
+	MaxTokens           *int64          `json:"max_tokens"`
+	MaxCompletionTokens *int64          `json:"max_completion_tokens"`
+	Stop                json.RawMessage `json:"stop"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty"`
+	PresencePenalty     *float64        `json:"presence_penalty"`
+	ReasoningEffort     string          `json:"reasoning_effort"`
+	ResponseFormat      *struct {
+		Type       string `json:"type"`
+		JSONSchema *struct {
+			Schema json.RawMessage `json:"schema"`
+		} `json:"json_schema"`
+	} `json:"response_format"`
+}
+
+// openAIMessage is a message of the OpenAI chat completions
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    json.RawMessage  `json:"content,omitempty"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	Reasoning  string           `json:"reasoning_content,omitempty"`
+}
+
+// openAIToolCall is a tool call of the OpenAI chat completions (Index is set in the streamed chunks)
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+// openAIContentPart is a part of a multimodal content
+type openAIContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	ImageURL struct {
+		URL string `json:"url"`
+	} `json:"image_url"`
+}
+
+// openAIUsage is the token usage of the OpenAI responses
+type openAIUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// --- Native payloads ---
+
+// chatRequest is the request of /api/chat
+type chatRequest struct {
+	Model     string            `json:"model"`
+	Messages  []message         `json:"messages"`
+	Tools     []json.RawMessage `json:"tools,omitempty"`
+	Stream    bool              `json:"stream"`
+	Format    json.RawMessage   `json:"format,omitempty"`
+	Options   map[string]any    `json:"options,omitempty"`
+	KeepAlive string            `json:"keep_alive,omitempty"`
+	Think     *bool             `json:"think,omitempty"`
+}
+
+// message is a message of /api/chat
+type message struct {
+	Role      string     `json:"role"`
+	Content   string     `json:"content"`
+	Thinking  string     `json:"thinking,omitempty"`
+	Images    []string   `json:"images,omitempty"`
+	ToolCalls []toolCall `json:"tool_calls,omitempty"`
+	ToolName  string     `json:"tool_name,omitempty"`
+}
+
+// toolCall is a tool call of /api/chat, the arguments are a JSON object
+type toolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+// chatResponse is the response (or a streamed chunk) of /api/chat
+type chatResponse struct {
+	Model           string    `json:"model"`
+	CreatedAt       time.Time `json:"created_at"`
+	Message         message   `json:"message"`
+	Done            bool      `json:"done"`
+	DoneReason      string    `json:"done_reason"`
+	PromptEvalCount int64     `json:"prompt_eval_count"`
+	EvalCount       int64     `json:"eval_count"`
+	Error           string    `json:"error"`
+}
+
+// --- Chat completions ---
+
+// chatCompletion translates a chat completion to /api/chat
+func (c *Client) chatCompletion(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var request openAIChatRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid chat completion request: %w", err)
+	}
+	native, err := c.toChatRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.send(req, "/api/chat", request.Model, native, next)
+	if err != nil {
+		return nil, err
+	}
+	if !request.Stream || resp.StatusCode >= 400 {
+		return convertResponse(resp, func(data []byte) (any, error) {
+			var response chatResponse
+			if err := json.Unmarshal(data, &response); err != nil {
+				return nil, err
+			}
+			return toChatCompletion(response), nil
+		})
+	}
+	includeUsage := request.StreamOptions != nil && request.StreamOptions.IncludeUsage
+	reader, writer := io.Pipe()
+	go convertStream(resp.Body, writer, includeUsage)
+	resp.Body = &streamBody{PipeReader: reader, source: resp.Body}
+	resp.ContentLength = -1
+	resp.Header.Del("Content-Length")
+	resp.Header.Set("Content-Type", "text/event-stream")
+	return resp, nil
+}
+
+// toChatRequest converts an OpenAI chat completion request
+func (c *Client) toChatRequest(request openAIChatRequest) (chatRequest, error) {
+	native := chatRequest{
+		Model:     request.Model,
+		Tools:     request.Tools,
+		Stream:    request.Stream,
+		KeepAlive: c.keepAlive,
+		Options:   map[string]any{},
+	}
+	for key, value := range c.modelOptions {
+		native.Options[key] = value
+	}
+	if request.Temperature != nil {
+		native.Options["temperature"] = *request.Temperature
+	}
+	if request.TopP != nil {
+		native.Options["top_p"] = *request.TopP
+	}
+	if request.Seed != nil {
+		native.Options["seed"] = *request.Seed
+	}
+	if request.MaxTokens != nil {
+		native.Options["num_predict"] = *request.MaxTokens
+	}
+	if request.MaxCompletionTokens != nil {
+		native.Options["num_predict"] = *request.MaxCompletionTokens
+	}
+	if request.FrequencyPenalty != nil {
+		native.Options["frequency_penalty"] = *request.FrequencyPenalty
+	}
+	if request.PresencePenalty != nil {
+		native.Options["presence_penalty"] = *request.PresencePenalty
+	}
+	if len(request.Stop) > 0 && string(request.Stop) != "null" {
+		var stop []string
+		var single string
+		if json.Unmarshal(request.Stop, &single) == nil {
+			stop = []string{single}
+		} else if err := json.Unmarshal(request.Stop, &stop); err != nil {
+			return native, fmt.Errorf("invalid stop sequences: %w", err)
+		}
+		native.Options["stop"] = stop
+	}
+	if request.ReasoningEffort != "" {
+		think := request.ReasoningEffort != "none"
+		native.Think = &think
+	}
+	if request.ResponseFormat != nil {
+		switch request.ResponseFormat.Type {
+		case "json_object":
+			native.Format = json.RawMessage(`"json"`)
+		case "json_schema":
+			if request.ResponseFormat.JSONSchema != nil {
+				native.Format = request.ResponseFormat.JSONSchema.Schema
+			}
+		}
+	}
+
+	// The tool results are identified by the name of the tool instead of the id of the call
+	toolNames := map[string]string{}
+	for _, openAIMsg := range request.Messages {
+		msg := message{Role: openAIMsg.Role, Thinking: openAIMsg.Reasoning}
+		if msg.Role == "developer" {
+			msg.Role = "system"
+		}
+		content, images, err := contentParts(openAIMsg.Content)
+		if err != nil {
+			return native, err
+		}
+		msg.Content, msg.Images = content, images
+		for _, call := range openAIMsg.ToolCalls {
+			toolNames[call.ID] = call.Function.Name
+			var nativeCall toolCall
+			nativeCall.Function.Name = call.Function.Name
+			nativeCall.Function.Arguments = json.RawMessage(call.Function.Arguments)
+			if !json.Valid(nativeCall.Function.Arguments) {
+				nativeCall.Function.Arguments = json.RawMessage(`{}`)
+			}
+			msg.ToolCalls = append(msg.ToolCalls, nativeCall)
+		}
+		if msg.Role == "tool" {
+			msg.ToolName = toolNames[openAIMsg.ToolCallID]
+		}
+		native.Messages = append(native.Messages, msg)
+	}
+	return native, nil
+}
+
+// contentParts returns the text and the base64 images of an OpenAI content (a string or an array of parts)
+func contentParts(content json.RawMessage) (string, []string, error) {
+	if len(content) == 0 || string(content) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if json.Unmarshal(content, &text) == nil {
+		return text, nil, nil
+	}
+	var parts []openAIContentPart
+	if err := json.Unmarshal(content, &parts); err != nil {
+		return "", nil, fmt.Errorf("invalid message content: %w", err)
+	}
+	texts, images := []string{}, []string{}
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "image_url":
+			// The native API only accepts the images as base64 data (data URLs)
+			_, data, found := strings.Cut(part.ImageURL.URL, ";base64,")
+			if !found {
+				return "", nil, errors.New("the images must be base64 data URLs with the Ollama native API")
+			}
+			images = append(images, data)
+		}
+	}
+	return strings.Join(texts, "\n"), images, nil
+}
+
+// toChatCompletion converts a response of /api/chat
+func toChatCompletion(response chatResponse) map[string]any {
+	assistant := map[string]any{
+		"role":    "assistant",
+		"content": response.Message.Content,
+	}
+	if response.Message.Thinking != "" {
+		assistant["reasoning_content"] = response.Message.Thinking
+	}
+	if len(response.Message.ToolCalls) > 0 {
+		assistant["tool_calls"] = toOpenAIToolCalls(response.Message.ToolCalls, nil)
+	}
+	return map[string]any{
+		"id":      "chatcmpl-" + randomID(),
+		"object":  "chat.completion",
+		"created": createdAt(response),
+		"model":   response.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"message":       assistant,
+			"finish_reason": finishReason(response),
+		}},
+		"usage": usage(response),
+	}
+}
+
+// toOpenAIToolCalls converts native tool calls, index numbers the calls of a stream
+func toOpenAIToolCalls(calls []toolCall, index *int) []openAIToolCall {
+	openAICalls := make([]openAIToolCall, 0, len(calls))
+	for _, call := range calls {
+		openAICall := openAIToolCall{ID: "call_" + randomID(), Type: "function"}
+		openAICall.Function.Name = call.Function.Name
+		openAICall.Function.Arguments = string(call.Function.Arguments)
+		if openAICall.Function.Arguments == "" || openAICall.Function.Arguments == "null" {
+			openAICall.Function.Arguments = "{}"
+		}
+		if index != nil {
+			callIndex := *index
+			openAICall.Index = &callIndex
+			*index++
+		}
+		openAICalls = append(openAICalls, openAICall)
+	}
+	return openAICalls
+}
+
+// finishReason returns the OpenAI finish reason of the last response
+func finishReason(response chatResponse) string {
+	switch {
+	case len(response.Message.ToolCalls) > 0:
+		return "tool_calls"
+	case response.DoneReason == "length":
+		return "length"
+	}
+	return "stop"
+}
+
+// usage returns the OpenAI usage of the last response
+func usage(response chatResponse) openAIUsage {
+	return openAIUsage{
+		PromptTokens:     response.PromptEvalCount,
+		CompletionTokens: response.EvalCount,
+		TotalTokens:      response.PromptEvalCount + response.EvalCount,
+	}
+}
+
+// createdAt returns the creation time of a response as a Unix timestamp
+func createdAt(response chatResponse) int64 {
+	if response.CreatedAt.IsZero() {
+		return time.Now().Unix()
+	}
+	return response.CreatedAt.Unix()
+}
+
+// randomID returns a random identifier of the completions and of the tool calls
+func randomID() string {
+	data := make([]byte, 12)
+	_, _ = rand.Read(data)
+	return hex.EncodeToString(data)
+}
+
+// --- Streaming ---
+
+// streamBody is the converted stream, closing it closes the native stream
+type streamBody struct {
+	*io.PipeReader
+	source io.Closer
+}
+
+// Close closes the converted and the native streams
+func (s *streamBody) Close() error {
+	s.PipeReader.Close()
+	return s.source.Close()
+}
+
+// convertStream converts the NDJSON stream of /api/chat to the server-sent events of the OpenAI chat completion chunks
+func convertStream(source io.ReadCloser, writer *io.PipeWriter, includeUsage bool) {
+	defer source.Close()
+	id := "chatcmpl-" + randomID()
+	toolIndex := 0
+	first := true
+	write := func(payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(writer, "data: %s\n\n", data)
+		return err
+	}
+	chunk := func(response chatResponse, delta map[string]any, finishReason any) map[string]any {
+		return map[string]any{
+			"id":      id,
+			"object":  "chat.completion.chunk",
+			"created": createdAt(response),
+			"model":   response.Model,
+			"choices": []map[string]any{{"index": 0, "delta": delta, "finish_reason": finishReason}},
+		}
+	}
+
+	reader := bufio.NewReader(source)
+	hasToolCalls := false
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(strings.TrimSpace(string(line))) > 0 {
+			var response chatResponse
+			if jsonErr := json.Unmarshal(line, &response); jsonErr != nil {
+				writer.CloseWithError(fmt.Errorf("invalid stream of the Ollama server: %w", jsonErr))
+				return
+			}
+			if response.Error != "" {
+				_ = write(map[string]any{"error": map[string]any{"message": response.Error, "type": "ollama_error"}})
+				writer.Close()
+				return
+			}
+			delta := map[string]any{}
+			if first {
+				delta["role"] = "assistant"
+				first = false
+			}
+			if response.Message.Content != "" {
+				delta["content"] = response.Message.Content
+			}
+			if response.Message.Thinking != "" {
+				delta["reasoning_content"] = response.Message.Thinking
+			}
+			if len(response.Message.ToolCalls) > 0 {
+				delta["tool_calls"] = toOpenAIToolCalls(response.Message.ToolCalls, &toolIndex)
+				hasToolCalls = true
+			}
+			if len(delta) > 0 {
+				if err := write(chunk(response, delta, nil)); err != nil {
+					return
+				}
+			}
+			if response.Done {
+				reason := finishReason(response)
+				if hasToolCalls {
+					reason = "tool_calls"
+				}
+				if err := write(chunk(response, map[string]any{}, reason)); err != nil {
+					return
+				}
+				if includeUsage {
+					final := chunk(response, nil, nil)
+					final["choices"] = []any{}
+					final["usage"] = usage(response)
+					if err := write(final); err != nil {
+						return
+					}
+				}
+				_, _ = io.WriteString(writer, "data: [DONE]\n\n")
+				writer.Close()
+				return
+			}
+		}
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			writer.CloseWithError(err)
+			return
+		}
+	}
+}
+
+// --- Embeddings ---
+
+// embeddings translates an embeddings request to /api/embed
+func (c *Client) embeddings(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		Model      string          `json:"model"`
+		Input      json.RawMessage `json:"input"`
+		Dimensions int64           `json:"dimensions,omitempty"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return nil, fmt.Errorf("invalid embeddings request: %w", err)
+	}
+	native := map[string]any{
+		"model": request.Model,
+		"input": request.Input,
+	}
+	if request.Dimensions > 0 {
+		native["dimensions"] = request.Dimensions
+	}
+	if c.keepAlive != "" {
+		native["keep_alive"] = c.keepAlive
+	}
+	if len(c.modelOptions) > 0 {
+		native["options"] = c.modelOptions
+	}
+	resp, err := c.send(req, "/api/embed", request.Model, native, next)
+	if err != nil {
+		return nil, err
+	}
+	return convertResponse(resp, func(data []byte) (any, error) {
+		var response struct {
+			Model           string      `json:"model"`
+			Embeddings      [][]float64 `json:"embeddings"`
+			PromptEvalCount int64       `json:"prompt_eval_count"`
+		}
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		embeddings := make([]map[string]any, 0, len(response.Embeddings))
+		for idx, embedding := range response.Embeddings {
+			embeddings = append(embeddings, map[string]any{"object": "embedding", "index": idx, "embedding": embedding})
+		}
+		return map[string]any{
+			"object": "list",
+			"model":  response.Model,
+			"data":   embeddings,
+			"usage":  map[string]any{"prompt_tokens": response.PromptEvalCount, "total_tokens": response.PromptEvalCount},
+		}, nil
+	})
+}
+
+// --- Models ---
+
+// listModels translates the list of the models to /api/tags
+func (c *Client) listModels(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	native := req.Clone(req.Context())
+	var err error
+	if native.URL, err = native.URL.Parse(c.baseURL + "/api/tags"); err != nil {
+		return nil, err
+	}
+	native.Host = native.URL.Host
+	resp, err := next(native)
+	if err != nil {
+		return nil, err
+	}
+	return convertResponse(resp, func(data []byte) (any, error) {
+		var response listResponse
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, err
+		}
+		models := make([]map[string]any, 0, len(response.Models))
+		for _, model := range response.Models {
+			models = append(models, map[string]any{
+				"id":       model.Name,
+				"object":   "model",
+				"created":  model.ModifiedAt.Unix(),
+				"owned_by": "ollama",
+			})
+		}
+		return map[string]any{"object": "list", "data": models}, nil
+	})
+}
@@ -0,0 +1,138 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Model is a model of the Ollama server
+type Model struct {
+	Name       string       `json:"name"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Size       int64        `json:"size"`
+	Digest     string       `json:"digest"`
+	Details    ModelDetails `json:"details"`
+}
+
+// ModelDetails describes the format and the size of a model
+type ModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// RunningModel is a model loaded in memory
+type RunningModel struct {
+	Name      string    `json:"name"`
+	Model     string    `json:"model"`
+	Size      int64     `json:"size"`
+	SizeVRAM  int64     `json:"size_vram"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PullProgress is a progress update of a pull
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// Percent returns the progress of the current download (0 if the size is unknown)
+func (p PullProgress) Percent() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	return 100 * float64(p.Completed) / float64(p.Total)
+}
+
+// listResponse is the response of /api/tags
+type listResponse struct {
+	Models []Model `json:"models"`
+}
+
+// List returns the models of the server
+func (c *Client) List(ctx context.Context) ([]Model, error) {
+	var response listResponse
+	if err := c.do(ctx, http.MethodGet, "/api/tags", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// Running returns the models loaded in memory
+func (c *Client) Running(ctx context.Context) ([]RunningModel, error) {
+	var response struct {
+		Models []RunningModel `json:"models"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/ps", nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Models, nil
+}
+
+// Pull downloads a model, onProgress (optional) receives the progress updates
+//
+// Example usage:
+//
+//	err := ollamaClient.Pull(ctx, "qwen2.5:1.5b", func(progress ollama.PullProgress) {
+//		fmt.Printf("\r%s %.0f%%", progress.Status, progress.Percent())
+//	})
+func (c *Client) Pull(ctx context.Context, model string, onProgress func(PullProgress)) error {
+	data, err := json.Marshal(map[string]any{"model": model, "stream": true})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/pull", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("failed to pull %s: %s", model, readError(resp))
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var progress struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &progress); err != nil {
+			return fmt.Errorf("invalid progress of the pull of %s: %w", model, err)
+		}
+		if progress.Error != "" {
+			return fmt.Errorf("failed to pull %s: %s", model, progress.Error)
+		}
+		if onProgress != nil {
+			onProgress(progress.PullProgress)
+		}
+		if progress.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return fmt.Errorf("the pull of %s ended without success", model)
+}
+
+// Delete removes a model from the server
+func (c *Client) Delete(ctx context.Context, model string) error {
+	return c.do(ctx, http.MethodDelete, "/api/delete", map[string]any{"model": model}, nil)
+}
@@ -0,0 +1,264 @@
+// Package ollama is the adapter of the native Ollama API (/api/chat, /api/embed) for the OpenAI client used by the agents:
+// the chat completions (with tool calling and streaming) and the embeddings are translated to the native endpoints,
+// which support keep_alive, the model options (num_ctx...) and the pulling of the missing models.
+// The package also provides the model management helpers (list, pull with progress, delete).
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// DefaultBaseURL is the URL of a local Ollama server
+const DefaultBaseURL = "http://localhost:11434"
+
+// ClientOption is a functional option for configuring Client instances
+type ClientOption func(*Client)
+
+// Client connects to an Ollama server: it adapts the requests of the OpenAI client to the native API
+// and manages the models of the server
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	keepAlive    string
+	modelOptions map[string]any
+	autoPull     bool
+	onPull       func(model string, progress PullProgress)
+}
+
+// NewClient creates a client of the Ollama server at baseURL (DefaultBaseURL if empty).
+// The /v1 suffix of the OpenAI compatibility layer is removed.
+//
+// Example usage:
+//
+//	ollamaClient := ollama.NewClient("", ollama.WithKeepAlive(30*time.Minute))
+//	agent, err := mu.NewAgent(ctx, "Bob",
+//		mu.WithClient(ollamaClient.OpenAIClient()),
+//		mu.WithParams(openai.ChatCompletionNewParams{Model: "qwen2.5:1.5b"}),
+//	)
+func NewClient(baseURL string, options ...ClientOption) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	baseURL = strings.TrimSuffix(strings.TrimSuffix(baseURL, "/"), "/v1")
+	client := &Client{
+		baseURL:    baseURL,
+		httpClient: http.DefaultClient,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
+}
+
+// WithHTTPClient is a functional option that sets the HTTP client of the model management requests
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithKeepAlive is a functional option that sets how long the models stay loaded after a request
+// (a negative duration keeps them loaded, zero unloads them immediately)
+func WithKeepAlive(keepAlive time.Duration) ClientOption {
+	return func(c *Client) {
+		c.keepAlive = keepAlive.String()
+	}
+}
+
+// WithModelOptions is a functional option that sets the model options of the requests (e.g. num_ctx, num_gpu),
+// the completion parameters (temperature, max tokens...) take precedence
+func WithModelOptions(modelOptions map[string]any) ClientOption {
+	return func(c *Client) {
+		c.modelOptions = modelOptions
+	}
+}
+
+// WithAutoPull is a functional option that pulls the missing models before the requests are retried,
+// onProgress (optional) receives the progress of the pulls
+func WithAutoPull(onProgress func(model string, progress PullProgress)) ClientOption {
+	return func(c *Client) {
+		c.autoPull = true
+		c.onPull = onProgress
+	}
+}
+
+// BaseURL returns the URL of the Ollama server
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// RequestOptions returns the options of an OpenAI client using the native API of the server.
+// They must be the last options of the client, so the other middlewares see OpenAI requests and responses.
+func (c *Client) RequestOptions() []option.RequestOption {
+	return []option.RequestOption{
+		// The routes without native adapter use the OpenAI compatibility layer
+		option.WithBaseURL(c.baseURL + "/v1/"),
+		option.WithAPIKey("ollama"),
+		option.WithMiddleware(c.Middleware()),
+	}
+}
+
+// OpenAIClient returns an OpenAI client using the native API of the server
+func (c *Client) OpenAIClient(options ...option.RequestOption) openai.Client {
+	return openai.NewClient(append(options, c.RequestOptions()...)...)
+}
+
+// Middleware returns the middleware translating the chat completions, the embeddings and the list of the models
+// of the OpenAI client to the native API
+func (c *Client) Middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		path := req.URL.Path
+		switch {
+		case req.Method == http.MethodPost && strings.HasSuffix(path, "/chat/completions"):
+			return c.chatCompletion(req, next)
+		case req.Method == http.MethodPost && strings.HasSuffix(path, "/embeddings"):
+			return c.embeddings(req, next)
+		case req.Method == http.MethodGet && strings.HasSuffix(path, "/models"):
+			return c.listModels(req, next)
+		}
+		return next(req)
+	}
+}
+
+// nativeRequest creates the request of a native endpoint with the payload
+func (c *Client) nativeRequest(req *http.Request, endpoint string, payload any) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	native := req.Clone(req.Context())
+	native.URL, err = native.URL.Parse(c.baseURL + endpoint)
+	if err != nil {
+		return nil, err
+	}
+	native.Host = native.URL.Host
+	native.Body = io.NopCloser(bytes.NewReader(body))
+	native.ContentLength = int64(len(body))
+	native.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	native.Header.Set("Content-Type", "application/json")
+	return native, nil
+}
+
+// send sends a native request, the missing model is pulled and the request retried with WithAutoPull
+func (c *Client) send(req *http.Request, endpoint, model string, payload any, next option.MiddlewareNext) (*http.Response, error) {
+	native, err := c.nativeRequest(req, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := next(native)
+	if err != nil || resp.StatusCode != http.StatusNotFound || !c.autoPull {
+		return resp, err
+	}
+	message := readError(resp)
+	if !strings.Contains(message, "not found") {
+		return errorResponse(resp, message), nil
+	}
+	var onProgress func(PullProgress)
+	if c.onPull != nil {
+		onProgress = func(progress PullProgress) {
+			c.onPull(model, progress)
+		}
+	}
+	if err := c.Pull(req.Context(), model, onProgress); err != nil {
+		return errorResponse(resp, err.Error()), nil
+	}
+	if native, err = c.nativeRequest(req, endpoint, payload); err != nil {
+		return nil, err
+	}
+	return next(native)
+}
+
+// readError reads and closes the body of an error response of the native API
+func readError(resp *http.Response) string {
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &payload) == nil && payload.Error != "" {
+		return payload.Error
+	}
+	if message := strings.TrimSpace(string(data)); message != "" {
+		return message
+	}
+	return resp.Status
+}
+
+// errorResponse replaces the body of a response with an OpenAI error
+func errorResponse(resp *http.Response, message string) *http.Response {
+	data, _ := json.Marshal(map[string]any{
+		"error": map[string]any{"message": message, "type": "ollama_error"},
+	})
+	return jsonResponse(resp, data)
+}
+
+// jsonResponse replaces the body of a response with a JSON payload
+func jsonResponse(resp *http.Response, data []byte) *http.Response {
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	resp.ContentLength = int64(len(data))
+	resp.Header.Set("Content-Type", "application/json")
+	resp.Header.Del("Content-Length")
+	return resp
+}
+
+// convertResponse converts the response of a native endpoint with convert, or its error
+func convertResponse(resp *http.Response, convert func(data []byte) (any, error)) (*http.Response, error) {
+	if resp.StatusCode >= 400 {
+		return errorResponse(resp, readError(resp)), nil
+	}
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	converted, err := convert(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid response of the Ollama server: %w", err)
+	}
+	data, err = json.Marshal(converted)
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(resp, data), nil
+}
+
+// do sends a request to the native API and decodes its JSON response into result (if not nil)
+func (c *Client) do(ctx context.Context, method, endpoint string, payload any, result any) error {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("ollama %s %s: %s", method, endpoint, readError(resp))
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
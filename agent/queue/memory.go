@@ -0,0 +1,140 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by the operations of a closed queue
+var ErrClosed = errors.New("the queue is closed")
+
+// MemoryQueue is an in-process queue: the workers and the clients of a same program share it
+type MemoryQueue struct {
+	mutex         sync.Mutex
+	subscriptions []*memorySubscription
+	// next is the index of the next subscriber of each group (round robin)
+	next   map[string]int
+	closed bool
+}
+
+// memorySubscription is a subscription of a MemoryQueue
+type memorySubscription struct {
+	subject  string
+	group    string
+	messages chan Message
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewMemoryQueue creates an in-memory queue
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{next: map[string]int{}}
+}
+
+// Publish implements the Queue interface for MemoryQueue, it waits for the subscribers to receive the message
+func (q *MemoryQueue) Publish(ctx context.Context, message Message) error {
+	q.mutex.Lock()
+	if q.closed {
+		q.mutex.Unlock()
+		return ErrClosed
+	}
+	// a subscriber of each group, and all the subscribers without group
+	var targets []*memorySubscription
+	groups := map[string][]*memorySubscription{}
+	groupNames := []string{}
+	for _, subscription := range q.subscriptions {
+		if !MatchSubject(subscription.subject, message.Subject) {
+			continue
+		}
+		if subscription.group == "" {
+			targets = append(targets, subscription)
+			continue
+		}
+		key := subscription.subject + " " + subscription.group
+		if _, ok := groups[key]; !ok {
+			groupNames = append(groupNames, key)
+		}
+		groups[key] = append(groups[key], subscription)
+	}
+	for _, key := range groupNames {
+		members := groups[key]
+		targets = append(targets, members[q.next[key]%len(members)])
+		q.next[key]++
+	}
+	q.mutex.Unlock()
+
+	for _, subscription := range targets {
+		select {
+		case subscription.messages <- message:
+		case <-subscription.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe implements the Queue interface for MemoryQueue
+func (q *MemoryQueue) Subscribe(ctx context.Context, subject, group string) (<-chan Message, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return nil, ErrClosed
+	}
+	subscription := &memorySubscription{
+		subject:  subject,
+		group:    group,
+		messages: make(chan Message),
+		done:     make(chan struct{}),
+	}
+	q.subscriptions = append(q.subscriptions, subscription)
+
+	// the channel of the subscriber is closed by its own goroutine
+	out := make(chan Message, 64)
+	go func() {
+		defer close(out)
+		defer q.unsubscribe(subscription)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-subscription.done:
+				return
+			case message := <-subscription.messages:
+				select {
+				case out <- message:
+				case <-ctx.Done():
+					return
+				case <-subscription.done:
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// unsubscribe removes a subscription
+func (q *MemoryQueue) unsubscribe(subscription *memorySubscription) {
+	subscription.once.Do(func() { close(subscription.done) })
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	for idx, s := range q.subscriptions {
+		if s == subscription {
+			q.subscriptions = append(q.subscriptions[:idx], q.subscriptions[idx+1:]...)
+			return
+		}
+	}
+}
+
+// Close implements the Queue interface for MemoryQueue
+func (q *MemoryQueue) Close() error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	q.closed = true
+	for _, subscription := range q.subscriptions {
+		subscription.once.Do(func() { close(subscription.done) })
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Package queue lets agents work asynchronously from a message queue: workers consume the task messages
+// of a subject (shared by the workers of a group, so that they scale horizontally), process them through
+// the tool calls loop of an agent and publish the results. The queues are adapters of the Queue interface:
+// NATS (with the official client, github.com/nats-io/nats.go) and an in-memory queue for a single process and the tests.
+package queue
+
+import (
+	"context"
+	"strings"
+)
+
+// Message is a message of a queue
+type Message struct {
+	Subject string
+	// Reply is the subject of the answer to the message (request-reply), if any
+	Reply string
+	Data  []byte
+}
+
+// Queue publishes and delivers messages by subject
+type Queue interface {
+	// Publish sends a message to the subscribers of its subject
+	Publish(ctx context.Context, message Message) error
+	// Subscribe delivers the messages of a subject (the wildcards * and > are supported) until the context
+	// is cancelled or the queue is closed, then the channel is closed. The subscribers of a same group
+	// share the messages: each message is delivered to one of them (no group: to all the subscribers).
+	Subscribe(ctx context.Context, subject, group string) (<-chan Message, error)
+	// Close closes the queue and the channels of its subscriptions
+	Close() error
+}
+
+// MatchSubject reports if a subject matches a subscription pattern: the tokens are separated by dots,
+// * matches a token and > matches one or more tokens at the end (e.g. "agent.tasks.*", "agent.>")
+func MatchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for idx, token := range patternTokens {
+		if token == ">" {
+			return idx == len(patternTokens)-1 && len(subjectTokens) > idx
+		}
+		if idx >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[idx] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
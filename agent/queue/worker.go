@@ -0,0 +1,333 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+)
+
+// Default subjects and group of the workers
+const (
+	DefaultTaskSubject   = "agent.tasks"
+	DefaultResultSubject = "agent.results"
+	DefaultGroup         = "agent-workers"
+)
+
+// Task is a task message: a prompt for the agent of a worker.
+// A message which is not a JSON object is a task whose prompt is the message.
+type Task struct {
+	ID     string `json:"id,omitempty"`
+	Prompt string `json:"prompt"`
+	// System replaces the system message of the agent
+	System string `json:"system,omitempty"`
+	// Metadata is returned with the result (e.g. the user or the conversation of the task)
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// TaskResult is the result message of a task
+type TaskResult struct {
+	ID           string            `json:"id,omitempty"`
+	Worker       string            `json:"worker"`
+	Output       string            `json:"output,omitempty"`
+	FinishReason string            `json:"finish_reason,omitempty"`
+	ToolCalls    []string          `json:"tool_calls,omitempty"`
+	Error        string            `json:"error,omitempty"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	Start        time.Time         `json:"start"`
+	End          time.Time         `json:"end"`
+}
+
+// Err returns the error of the task, if it failed
+func (result TaskResult) Err() error {
+	if result.Error == "" {
+		return nil
+	}
+	return errors.New(result.Error)
+}
+
+// WorkerOption is a functional option for configuring Worker instances
+type WorkerOption func(*Worker)
+
+// Worker consumes the task messages of a queue and publishes their results: to the reply subject of
+// the message (Request) or to the result subject. An agent is created for each task, the tasks are
+// processed concurrently up to the concurrency of the worker.
+type Worker struct {
+	queue         Queue
+	newAgent      func(ctx context.Context) (mu.Agent, error)
+	name          string
+	taskSubject   string
+	resultSubject string
+	group         string
+	concurrency   int
+	toolCallback  func(functionName string, arguments string) (string, error)
+	onResult      func(task Task, result TaskResult)
+	logger        logging.Logger
+}
+
+// NewWorker creates a worker of the queue, newAgent creates the agent of each task (with its tools)
+//
+// Example usage:
+//
+//	worker := queue.NewWorker(q, func(ctx context.Context) (mu.Agent, error) {
+//	  return mu.NewAgent(ctx, "Worker", mu.WithClient(client), mu.WithParams(params))
+//	}, queue.WithToolCallback(executeFn), queue.WithConcurrency(4))
+//	err := worker.Run(ctx) // until ctx is cancelled
+func NewWorker(queue Queue, newAgent func(ctx context.Context) (mu.Agent, error), options ...WorkerOption) *Worker {
+	hostname, _ := os.Hostname()
+	worker := &Worker{
+		queue:         queue,
+		newAgent:      newAgent,
+		name:          fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		taskSubject:   DefaultTaskSubject,
+		resultSubject: DefaultResultSubject,
+		group:         DefaultGroup,
+		concurrency:   1,
+	}
+	for _, option := range options {
+		option(worker)
+	}
+	return worker
+}
+
+// WithWorkerName sets the name of the worker in the results (<hostname>-<pid> by default)
+func WithWorkerName(name string) WorkerOption {
+	return func(w *Worker) {
+		w.name = name
+	}
+}
+
+// WithTaskSubject sets the subject of the tasks (DefaultTaskSubject by default, wildcards are allowed)
+func WithTaskSubject(subject string) WorkerOption {
+	return func(w *Worker) {
+		w.taskSubject = subject
+	}
+}
+
+// WithResultSubject sets the subject of the results of the tasks without reply subject
+// (DefaultResultSubject by default, no publication if empty)
+func WithResultSubject(subject string) WorkerOption {
+	return func(w *Worker) {
+		w.resultSubject = subject
+	}
+}
+
+// WithGroup sets the group of the worker: the workers of a group share the tasks (DefaultGroup by default)
+func WithGroup(group string) WorkerOption {
+	return func(w *Worker) {
+		w.group = group
+	}
+}
+
+// WithConcurrency sets the number of tasks processed at the same time (1 by default)
+func WithConcurrency(concurrency int) WorkerOption {
+	return func(w *Worker) {
+		w.concurrency = max(concurrency, 1)
+	}
+}
+
+// WithToolCallback sets the execution of the tool calls: with it the tasks go through the tool calls loop
+func WithToolCallback(toolCallback func(functionName string, arguments string) (string, error)) WorkerOption {
+	return func(w *Worker) {
+		w.toolCallback = toolCallback
+	}
+}
+
+// WithOnResult sets a callback called with each processed task and its result
+func WithOnResult(onResult func(task Task, result TaskResult)) WorkerOption {
+	return func(w *Worker) {
+		w.onResult = onResult
+	}
+}
+
+// WithWorkerLogger sets the logger of the worker (logging.Default() otherwise)
+func WithWorkerLogger(logger logging.Logger) WorkerOption {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// log returns the logger of the worker
+func (w *Worker) log() logging.Logger {
+	return logging.OrDefault(w.logger)
+}
+
+// Run processes the tasks until the context is cancelled (then the tasks in progress are completed
+// and it returns the error of the context) or the subscription is closed by the queue
+func (w *Worker) Run(ctx context.Context) error {
+	messages, err := w.queue.Subscribe(ctx, w.taskSubject, w.group)
+	if err != nil {
+		return err
+	}
+	w.log().Info("worker started", "worker", w.name, "subject", w.taskSubject, "group", w.group)
+
+	var running sync.WaitGroup
+	defer running.Wait()
+	slots := make(chan struct{}, w.concurrency)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case message, ok := <-messages:
+			if !ok {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				return errors.New("the subscription of the tasks is closed")
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				defer func() { <-slots }()
+				w.handle(message)
+			}()
+		}
+	}
+}
+
+// handle processes a task message and publishes its result
+func (w *Worker) handle(message Message) {
+	task := ParseTask(message.Data)
+	result := w.Process(context.Background(), task)
+	if w.onResult != nil {
+		w.onResult(task, result)
+	}
+
+	subject := message.Reply
+	if subject == "" {
+		subject = w.resultSubject
+	}
+	if subject == "" {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		w.log().Error("invalid result", "task", task.ID, "error", err)
+		return
+	}
+	if err := w.queue.Publish(context.Background(), Message{Subject: subject, Data: data}); err != nil {
+		w.log().Error("failed to publish the result", "task", task.ID, "subject", subject, "error", err)
+	}
+}
+
+// ParseTask decodes a task message, a message which is not a JSON task is the prompt of the task
+func ParseTask(data []byte) Task {
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil || task.Prompt == "" {
+		return Task{Prompt: string(data)}
+	}
+	return task
+}
+
+// Process runs a task with a new agent (through the tool calls loop with a tool callback)
+func (w *Worker) Process(ctx context.Context, task Task) TaskResult {
+	result := TaskResult{ID: task.ID, Worker: w.name, Metadata: task.Metadata, Start: time.Now()}
+	w.log().Info("task received", "worker", w.name, "task", task.ID)
+	err := func() error {
+		agent, err := w.newAgent(ctx)
+		if err != nil {
+			return err
+		}
+		if task.System != "" {
+			agent.SetMessages(nil)
+			agent.AddMessage(openai.SystemMessage(task.System))
+		}
+		messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(task.Prompt)}
+		if w.toolCallback == nil {
+			result.Output, err = agent.Run(messages)
+			result.FinishReason = "stop"
+			return err
+		}
+		result.FinishReason, result.ToolCalls, result.Output, err = agent.DetectToolCalls(messages, w.toolCallback)
+		return err
+	}()
+	result.End = time.Now()
+	if err != nil {
+		result.Error = err.Error()
+		w.log().Error("task failed", "worker", w.name, "task", task.ID, "error", err)
+	} else {
+		w.log().Info("task completed", "worker", w.name, "task", task.ID, "duration", result.End.Sub(result.Start))
+	}
+	return result
+}
+
+// Submit publishes a task without waiting for its result (an ID is given to the task if it has none),
+// the result is published to the result subject of the workers
+func Submit(ctx context.Context, queue Queue, subject string, task Task) (string, error) {
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return "", err
+	}
+	return task.ID, queue.Publish(ctx, Message{Subject: subject, Data: data})
+}
+
+// flusher is implemented by the queues whose operations are asynchronous (NATSQueue)
+type flusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Request publishes a task and waits for its result (until the context is done)
+//
+// Example usage:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 2*time.Minute)
+//	defer cancel()
+//	result, err := queue.Request(ctx, q, queue.DefaultTaskSubject, queue.Task{Prompt: "Summarize the logs"})
+func Request(ctx context.Context, queue Queue, subject string, task Task) (TaskResult, error) {
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	}
+	data, err := json.Marshal(task)
+	if err != nil {
+		return TaskResult{}, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	inbox := "_INBOX." + uuid.New().String()
+	replies, err := queue.Subscribe(ctx, inbox, "")
+	if err != nil {
+		return TaskResult{}, err
+	}
+	// the inbox must be subscribed before the task is published
+	if flushing, ok := queue.(flusher); ok {
+		if err := flushing.Flush(ctx); err != nil {
+			return TaskResult{}, err
+		}
+	}
+	if err := queue.Publish(ctx, Message{Subject: subject, Reply: inbox, Data: data}); err != nil {
+		return TaskResult{}, err
+	}
+	select {
+	case <-ctx.Done():
+		return TaskResult{}, ctx.Err()
+	case reply, ok := <-replies:
+		if !ok {
+			if ctx.Err() != nil {
+				return TaskResult{}, ctx.Err()
+			}
+			return TaskResult{}, errors.New("the subscription of the result is closed")
+		}
+		var result TaskResult
+		if err := json.Unmarshal(reply.Data, &result); err != nil {
+			return TaskResult{}, fmt.Errorf("invalid result: %w", err)
+		}
+		return result, result.Err()
+	}
+}
@@ -3,6 +3,8 @@ package rag
 import (
 	"regexp"
 	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
 )
 
 // ChunkText takes a text string and divides it into chunks of a specified size with a given overlap.
@@ -42,7 +44,9 @@ func SplitTextWithDelimiter(text string, delimiter string) []string {
 
 
 // SplitMarkdownBySections splits markdown content into sections at header boundaries
+// (the YAML frontmatter is left out, see MarkdownRecords to keep its metadata)
 func SplitMarkdownBySections(markdown string) []string {
+	_, markdown, _ = helpers.ParseFrontmatter(markdown)
 	if markdown == "" {
 		return []string{}
 	}
@@ -3,6 +3,8 @@ package rag
 import (
 	"regexp"
 	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
 )
 
 // MarkdownChunk represents a parsed markdown section with hierarchical context
@@ -35,7 +37,9 @@ type MarkdownChunk struct {
 //     its hierarchical lineage.
 
 //  ParseMarkdownHierarchy parses the given markdown content and returns a slice of MarkdownChunk structs preserving the hierarchical context
+//  The metadata of the YAML frontmatter of the content (see helpers.ParseFrontmatter) is set on all the chunks
 func ParseMarkdownHierarchy(content string) []MarkdownChunk {
+	metadata, content, _ := helpers.ParseFrontmatter(content)
 	lines := strings.Split(content, "\n")
 	var chunks []MarkdownChunk
 	var stack []MarkdownChunk
@@ -81,6 +85,7 @@ func ParseMarkdownHierarchy(content string) []MarkdownChunk {
 				ParentLevel:  parent.Level,
 				ParentHeader: parent.Header,
 				Hierarchy:      hierarchy,
+				Metadata:     metadata,
 			}
 			//if chunk.Content != "" {
 			chunks = append(chunks, chunk)
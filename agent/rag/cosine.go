@@ -44,3 +44,8 @@ func cosineSimilarity(v1, v2 []float64) float64 {
 	}
 	return product / (norm1 * norm2)
 }
+
+// CosineSimilarity returns the cosine similarity of two embeddings of the same length
+func CosineSimilarity(v1, v2 []float64) float64 {
+	return cosineSimilarity(v1, v2)
+}
@@ -0,0 +1,72 @@
+package rag
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+)
+
+// LoadURL downloads a web page or a text document (see helpers.FetchURLAsText) and splits it into chunks:
+// the HTML pages (converted to markdown) and the markdown documents by sections, the other documents
+// into chunks of chunkSize characters overlapping by overlap characters.
+//
+// Example usage:
+//
+//	chunks, err := rag.LoadURL(ctx, "https://go.dev/doc/effective_go", 1024, 128)
+//	for _, chunk := range chunks {
+//	  embedding, err := embeddingAgent.GenerateEmbeddingVector(chunk)
+//	  store.Save(rag.VectorRecord{Prompt: chunk, Embedding: embedding})
+//	}
+func LoadURL(ctx context.Context, documentURL string, chunkSize, overlap int, options ...helpers.FetchOption) ([]string, error) {
+	if chunkSize <= 0 || overlap < 0 || overlap >= chunkSize {
+		return nil, fmt.Errorf("invalid chunk size %d and overlap %d", chunkSize, overlap)
+	}
+	document, err := helpers.FetchURLAsText(ctx, documentURL, options...)
+	if err != nil {
+		return nil, err
+	}
+	var chunks []string
+	switch {
+	case document.ContentType == "text/html" || document.ContentType == "application/xhtml+xml" ||
+		document.ContentType == "text/markdown" || strings.HasSuffix(strings.ToLower(document.URL), ".md"):
+		chunks = SplitMarkdownBySections(document.Content)
+	default:
+		chunks = ChunkText(document.Content, chunkSize, overlap)
+	}
+
+	nonEmpty := []string{}
+	for _, chunk := range chunks {
+		if strings.TrimSpace(chunk) != "" {
+			nonEmpty = append(nonEmpty, chunk)
+		}
+	}
+	return nonEmpty, nil
+}
+
+// MarkdownRecords splits a markdown document by sections into records (without embedding) carrying the
+// metadata of its YAML frontmatter (see helpers.ParseFrontmatter): the titles, tags and dates of a knowledge
+// base are kept with the chunks to be searched and cited. The metadata is shared by the records.
+//
+// Example usage:
+//
+//	records, err := rag.MarkdownRecords(content)
+//	for _, record := range records {
+//	  record.Embedding, err = embeddingAgent.GenerateEmbeddingVector(record.Prompt)
+//	  store.Save(record)
+//	}
+func MarkdownRecords(markdown string) ([]VectorRecord, error) {
+	metadata, body, err := helpers.ParseFrontmatter(markdown)
+	if err != nil {
+		return nil, err
+	}
+	records := []VectorRecord{}
+	for _, section := range SplitMarkdownBySections(body) {
+		if strings.TrimSpace(section) == "" {
+			continue
+		}
+		records = append(records, VectorRecord{Prompt: section, Metadata: metadata})
+	}
+	return records, nil
+}
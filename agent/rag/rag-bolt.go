@@ -0,0 +1,164 @@
+package rag
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltVectorStore implements VectorStore with a BoltDB file: the records are stored in JSON in a bucket,
+// keyed by their id. The file is the persistence of an application without a database server; the
+// similarities are computed by the application, after reading the records, so the store suits the corpora
+// that fit in memory. A file can be opened by one process at a time.
+//
+// Example usage:
+//
+//	store, err := rag.NewBoltVectorStore("rag.db", rag.WithBoltBucket("docs"))
+//	if err != nil {
+//	  return err
+//	}
+//	defer store.Close()
+//	_, err = store.Save(rag.VectorRecord{Prompt: chunk, Embedding: embedding})
+type BoltVectorStore struct {
+	db      *bolt.DB
+	bucket  []byte
+	timeout time.Duration
+}
+
+// BoltVectorStoreOption configures a BoltVectorStore
+type BoltVectorStoreOption func(*BoltVectorStore)
+
+// WithBoltBucket sets the bucket of the records ("rag_records" by default), to keep several stores in a file
+func WithBoltBucket(bucket string) BoltVectorStoreOption {
+	return func(s *BoltVectorStore) {
+		s.bucket = []byte(bucket)
+	}
+}
+
+// WithBoltTimeout sets how long NewBoltVectorStore waits for the lock of a file opened by another process
+// (5 seconds by default, 0 waits indefinitely)
+func WithBoltTimeout(timeout time.Duration) BoltVectorStoreOption {
+	return func(s *BoltVectorStore) {
+		s.timeout = timeout
+	}
+}
+
+var _ FilteredVectorStore = (*BoltVectorStore)(nil)
+
+// NewBoltVectorStore opens (or creates) the BoltDB file of path and its bucket
+func NewBoltVectorStore(path string, options ...BoltVectorStoreOption) (*BoltVectorStore, error) {
+	store := &BoltVectorStore{
+		bucket:  []byte("rag_records"),
+		timeout: 5 * time.Second,
+	}
+	for _, option := range options {
+		option(store)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: store.timeout})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the BoltDB file %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(store.bucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create the bucket %s: %w", store.bucket, err)
+	}
+	store.db = db
+	return store, nil
+}
+
+// GetAll returns all vector records of the bucket
+func (s *BoltVectorStore) GetAll() ([]VectorRecord, error) {
+	records := []VectorRecord{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).ForEach(func(_, data []byte) error {
+			var record VectorRecord
+			if err := json.Unmarshal(data, &record); err != nil {
+				return err
+			}
+			records = append(records, record)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// Save saves a vector record in the bucket, a new UUID is given to a record without ID.
+// A record with the ID of an existing one replaces it.
+func (s *BoltVectorStore) Save(vectorRecord VectorRecord) (VectorRecord, error) {
+	if vectorRecord.Id == "" {
+		vectorRecord.Id = uuid.New().String()
+	}
+	data, err := json.Marshal(vectorRecord)
+	if err != nil {
+		return vectorRecord, err
+	}
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(vectorRecord.Id), data)
+	})
+	return vectorRecord, err
+}
+
+// SearchSimilarities returns the records with a cosine similarity greater than or equal to limit
+func (s *BoltVectorStore) SearchSimilarities(embeddingFromQuestion VectorRecord, limit float64) ([]VectorRecord, error) {
+	all, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var records []VectorRecord
+	for _, v := range all {
+		distance := cosineSimilarity(embeddingFromQuestion.Embedding, v.Embedding)
+		if distance >= limit {
+			v.CosineSimilarity = distance
+			records = append(records, v)
+		}
+	}
+	return records, nil
+}
+
+// SearchTopNSimilarities returns at most max records with a cosine similarity greater than or equal to limit,
+// the most similar first
+func (s *BoltVectorStore) SearchTopNSimilarities(embeddingFromQuestion VectorRecord, limit float64, max int) ([]VectorRecord, error) {
+	records, err := s.SearchSimilarities(embeddingFromQuestion, limit)
+	if err != nil {
+		return nil, err
+	}
+	return getTopNVectorRecords(records, max), nil
+}
+
+// SearchTopNSimilaritiesWithFilter is SearchTopNSimilarities for the records whose metadata matches the filter
+func (s *BoltVectorStore) SearchTopNSimilaritiesWithFilter(embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error) {
+	return SearchTopNSimilaritiesMatching(s, embeddingFromQuestion, limit, max, filter.matchRecord)
+}
+
+// Delete removes a vector record from the bucket
+func (s *BoltVectorStore) Delete(id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Delete([]byte(id))
+	})
+}
+
+// Reset removes all the vector records of the bucket (the other buckets of the file are kept)
+func (s *BoltVectorStore) Reset() error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(s.bucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(s.bucket)
+		return err
+	})
+}
+
+// Close closes the BoltDB file
+func (s *BoltVectorStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,118 @@
+package rag
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// MetadataFilter scopes a search to the records whose metadata has all the values of the filter:
+// a metadata value matches the value of the filter if it is equal to it, or if it is a list containing it
+// (e.g. the filter {"tag": "go"} matches the metadata {"tag": ["go", "ai"]}).
+// The values are strings, numbers or booleans.
+type MetadataFilter map[string]any
+
+// FilteredVectorStore is implemented by the vector stores which can filter the records of a search
+// with their metadata (MemoryVectorStore, RedisVectorStore, BoltVectorStore, PgVectorStore and QdrantStore)
+type FilteredVectorStore interface {
+	VectorStore
+	SearchTopNSimilaritiesWithFilter(embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error)
+}
+
+// SearchTopNSimilaritiesWithFilter searches the top N similar records of any vector store whose metadata
+// matches the filter: the stores implementing FilteredVectorStore filter the records themselves,
+// the records of the other stores are filtered after SearchSimilarities.
+//
+// Example usage:
+//
+//	similarities, err := rag.SearchTopNSimilaritiesWithFilter(store, question, 0.6, 5,
+//	  rag.MetadataFilter{"source": "install.md", "tag": "linux"},
+//	)
+func SearchTopNSimilaritiesWithFilter(store VectorStore, embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error) {
+	if filteredStore, ok := store.(FilteredVectorStore); ok {
+		return filteredStore.SearchTopNSimilaritiesWithFilter(embeddingFromQuestion, limit, max, filter)
+	}
+	return SearchTopNSimilaritiesMatching(store, embeddingFromQuestion, limit, max, filter.matchRecord)
+}
+
+// SearchTopNSimilaritiesMatching searches the top N similar records of a vector store for which match returns true,
+// for the conditions a MetadataFilter can't express (e.g. a range of dates). The records are filtered
+// after SearchSimilarities, by the application.
+//
+// Example usage:
+//
+//	similarities, err := rag.SearchTopNSimilaritiesMatching(store, question, 0.6, 5, func(record rag.VectorRecord) bool {
+//	  date, _ := record.Metadata["date"].(string)
+//	  return date >= "2025-01-01"
+//	})
+func SearchTopNSimilaritiesMatching(store VectorStore, embeddingFromQuestion VectorRecord, limit float64, max int, match func(record VectorRecord) bool) ([]VectorRecord, error) {
+	records, err := store.SearchSimilarities(embeddingFromQuestion, limit)
+	if err != nil {
+		return nil, err
+	}
+	matching := []VectorRecord{}
+	for _, record := range records {
+		if match(record) {
+			matching = append(matching, record)
+		}
+	}
+	return getTopNVectorRecords(matching, max), nil
+}
+
+// Matches reports whether metadata has all the values of the filter
+func (f MetadataFilter) Matches(metadata map[string]any) bool {
+	for key, expected := range f {
+		value, ok := metadata[key]
+		if !ok {
+			return false
+		}
+		expected, value = normalizeJSONValue(expected), normalizeJSONValue(value)
+		if reflect.DeepEqual(value, expected) {
+			continue
+		}
+		list, isList := value.([]any)
+		if !isList || !containsJSONValue(list, expected) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchRecord reports whether the metadata of a record has all the values of the filter
+func (f MetadataFilter) matchRecord(record VectorRecord) bool {
+	return f.Matches(record.Metadata)
+}
+
+// keys returns the keys of the filter, sorted
+func (f MetadataFilter) keys() []string {
+	keys := make([]string, 0, len(f))
+	for key := range f {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// containsJSONValue reports whether a list contains a value
+func containsJSONValue(list []any, value any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeJSONValue returns a value as decoded from JSON (e.g. the numbers are float64), to compare
+// the values of a filter with the metadata of the records, decoded from JSON by most stores
+func normalizeJSONValue(value any) any {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -9,9 +9,10 @@ import (
 
 // VectorRecord represents a stored vector with metadata and similarity score
 type VectorRecord struct {
-	Id               string    `json:"id"`
-	Prompt           string    `json:"prompt"`
-	Embedding        []float64 `json:"embedding"`
+	Id               string         `json:"id"`
+	Prompt           string         `json:"prompt"`
+	Embedding        []float64      `json:"embedding"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
 	CosineSimilarity float64
 }
 
@@ -28,6 +29,8 @@ type MemoryVectorStore struct {
 	Records map[string]VectorRecord
 }
 
+var _ FilteredVectorStore = (*MemoryVectorStore)(nil)
+
 // GetAll returns all vector records stored in the MemoryVectorStore
 func (mvs *MemoryVectorStore) GetAll() ([]VectorRecord, error) {
 	var records []VectorRecord
@@ -84,6 +87,11 @@ func (mvs *MemoryVectorStore) SearchTopNSimilarities(embeddingFromQuestion Vecto
 	return getTopNVectorRecords(records, max), nil
 }
 
+// SearchTopNSimilaritiesWithFilter is SearchTopNSimilarities for the records whose metadata matches the filter
+func (mvs *MemoryVectorStore) SearchTopNSimilaritiesWithFilter(embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error) {
+	return SearchTopNSimilaritiesMatching(mvs, embeddingFromQuestion, limit, max, filter.matchRecord)
+}
+
 // Load reads vector records from a JSON file and populates the MemoryVectorStore
 func (mvs *MemoryVectorStore) Load(storeFilePath string) error {
 	// Check if the store file exists
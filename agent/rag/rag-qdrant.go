@@ -0,0 +1,370 @@
+package rag
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QdrantStore implements VectorStore with a Qdrant collection, through the HTTP API of Qdrant: the similarity
+// searches are run by Qdrant. The collection is created with the cosine distance on the first save,
+// with the size of the embedding of the record.
+// The ids of the points of Qdrant are UUIDs: a record id which isn't a UUID is kept in the payload.
+//
+// Example usage:
+//
+//	store := rag.NewQdrantStore("http://localhost:6333", "docs", rag.WithQdrantAPIKey(apiKey))
+//	err := store.SaveAll(records)
+//	if err != nil {
+//	  return err
+//	}
+//	similarities, err := store.SearchTopNSimilarities(question, 0.6, 5)
+type QdrantStore struct {
+	baseURL    string
+	collection string
+	apiKey     string
+	batchSize  int
+	httpClient *http.Client
+
+	mutex   sync.Mutex
+	created bool
+}
+
+// QdrantStoreOption configures a QdrantStore
+type QdrantStoreOption func(*QdrantStore)
+
+// WithQdrantAPIKey sets the API key sent to Qdrant
+func WithQdrantAPIKey(apiKey string) QdrantStoreOption {
+	return func(s *QdrantStore) {
+		s.apiKey = apiKey
+	}
+}
+
+// WithQdrantBatchSize sets the number of points sent by upsert request by SaveAll (100 by default)
+func WithQdrantBatchSize(batchSize int) QdrantStoreOption {
+	return func(s *QdrantStore) {
+		s.batchSize = batchSize
+	}
+}
+
+// WithQdrantHTTPClient sets the HTTP client of the requests (a client with a 30 seconds timeout by default)
+func WithQdrantHTTPClient(httpClient *http.Client) QdrantStoreOption {
+	return func(s *QdrantStore) {
+		s.httpClient = httpClient
+	}
+}
+
+var _ FilteredVectorStore = (*QdrantStore)(nil)
+
+// qdrantScrollSize is the number of points read by scroll request
+const qdrantScrollSize = 256
+
+// NewQdrantStore creates a store for a collection of a Qdrant server (baseURL is e.g. http://localhost:6333)
+func NewQdrantStore(baseURL string, collection string, options ...QdrantStoreOption) *QdrantStore {
+	store := &QdrantStore{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		collection: collection,
+		batchSize:  100,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+	for _, option := range options {
+		option(store)
+	}
+	return store
+}
+
+// qdrantPoint is a point of Qdrant
+type qdrantPoint struct {
+	Id      string        `json:"id"`
+	Vector  []float64     `json:"vector"`
+	Payload qdrantPayload `json:"payload"`
+	Score   float64       `json:"score,omitempty"`
+}
+
+// qdrantPayload is the payload of the point of a record
+type qdrantPayload struct {
+	Id       string         `json:"id"`
+	Prompt   string         `json:"prompt"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+}
+
+// record returns the vector record of a point
+func (p qdrantPoint) record() VectorRecord {
+	return VectorRecord{
+		Id:               p.Payload.Id,
+		Prompt:           p.Payload.Prompt,
+		Embedding:        p.Vector,
+		Metadata:         p.Payload.Metadata,
+		CosineSimilarity: p.Score,
+	}
+}
+
+// GetAll returns all vector records of the collection
+func (s *QdrantStore) GetAll() ([]VectorRecord, error) {
+	records := []VectorRecord{}
+	var offset any
+	for {
+		var result struct {
+			Points         []qdrantPoint `json:"points"`
+			NextPageOffset any           `json:"next_page_offset"`
+		}
+		err := s.request(http.MethodPost, "/points/scroll", map[string]any{
+			"limit":        qdrantScrollSize,
+			"offset":       offset,
+			"with_payload": true,
+			"with_vector":  true,
+		}, &result)
+		if isQdrantNotFound(err) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range result.Points {
+			records = append(records, point.record())
+		}
+		if result.NextPageOffset == nil {
+			return records, nil
+		}
+		offset = result.NextPageOffset
+	}
+}
+
+// Save saves a vector record in the collection, a new UUID is given to a record without ID.
+// A record with the ID of an existing one replaces it.
+func (s *QdrantStore) Save(vectorRecord VectorRecord) (VectorRecord, error) {
+	records, err := s.SaveAll([]VectorRecord{vectorRecord})
+	if err != nil {
+		return vectorRecord, err
+	}
+	return records[0], nil
+}
+
+// SaveAll saves vector records in the collection with batch upserts (see WithQdrantBatchSize),
+// it returns the records with their IDs
+func (s *QdrantStore) SaveAll(vectorRecords []VectorRecord) ([]VectorRecord, error) {
+	records := make([]VectorRecord, len(vectorRecords))
+	points := make([]qdrantPoint, len(vectorRecords))
+	for i, record := range vectorRecords {
+		if record.Id == "" {
+			record.Id = uuid.New().String()
+		}
+		records[i] = record
+		points[i] = qdrantPoint{
+			Id:      qdrantPointId(record.Id),
+			Vector:  record.Embedding,
+			Payload: qdrantPayload{Id: record.Id, Prompt: record.Prompt, Metadata: record.Metadata},
+		}
+	}
+	if len(points) == 0 {
+		return records, nil
+	}
+	if err := s.createCollection(len(points[0].Vector)); err != nil {
+		return nil, err
+	}
+	batchSize := max(s.batchSize, 1)
+	for start := 0; start < len(points); start += batchSize {
+		end := min(start+batchSize, len(points))
+		err := s.request(http.MethodPut, "/points?wait=true", map[string]any{"points": points[start:end]}, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// SearchSimilarities returns the records with a cosine similarity greater than or equal to limit
+func (s *QdrantStore) SearchSimilarities(embeddingFromQuestion VectorRecord, limit float64) ([]VectorRecord, error) {
+	var info struct {
+		PointsCount int `json:"points_count"`
+	}
+	err := s.request(http.MethodGet, "", nil, &info)
+	if isQdrantNotFound(err) {
+		return []VectorRecord{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.SearchTopNSimilarities(embeddingFromQuestion, limit, info.PointsCount)
+}
+
+// SearchTopNSimilarities returns at most max records with a cosine similarity greater than or equal to limit,
+// the most similar first
+func (s *QdrantStore) SearchTopNSimilarities(embeddingFromQuestion VectorRecord, limit float64, max int) ([]VectorRecord, error) {
+	return s.SearchTopNSimilaritiesWithFilter(embeddingFromQuestion, limit, max, nil)
+}
+
+// SearchTopNSimilaritiesWithFilter is SearchTopNSimilarities for the records whose metadata matches the filter
+// (the filter is a filter of the search of Qdrant)
+func (s *QdrantStore) SearchTopNSimilaritiesWithFilter(embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error) {
+	records := []VectorRecord{}
+	if max <= 0 {
+		return records, nil
+	}
+	search := map[string]any{
+		"vector":          embeddingFromQuestion.Embedding,
+		"limit":           max,
+		"score_threshold": limit,
+		"with_payload":    true,
+		"with_vector":     true,
+	}
+	if len(filter) > 0 {
+		search["filter"] = qdrantFilter(filter)
+	}
+	var points []qdrantPoint
+	err := s.request(http.MethodPost, "/points/search", search, &points)
+	if isQdrantNotFound(err) {
+		return records, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, point := range points {
+		records = append(records, point.record())
+	}
+	return records, nil
+}
+
+// Delete removes a vector record from the collection
+func (s *QdrantStore) Delete(id string) error {
+	err := s.request(http.MethodPost, "/points/delete?wait=true", map[string]any{"points": []string{qdrantPointId(id)}}, nil)
+	if isQdrantNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// Reset deletes the collection, it is created again by the next save
+func (s *QdrantStore) Reset() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	err := s.request(http.MethodDelete, "", nil, nil)
+	if err != nil && !isQdrantNotFound(err) {
+		return err
+	}
+	s.created = false
+	return nil
+}
+
+// createCollection creates the collection if it doesn't exist, for vectors of a size
+func (s *QdrantStore) createCollection(size int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.created {
+		return nil
+	}
+	err := s.request(http.MethodGet, "", nil, nil)
+	if isQdrantNotFound(err) {
+		err = s.request(http.MethodPut, "", map[string]any{
+			"vectors": map[string]any{"size": size, "distance": "Cosine"},
+		}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create the Qdrant collection %s: %w", s.collection, err)
+	}
+	s.created = true
+	return nil
+}
+
+// QdrantError is an error answered by Qdrant
+type QdrantError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *QdrantError) Error() string {
+	return fmt.Sprintf("qdrant: %d: %s", e.StatusCode, e.Message)
+}
+
+// isQdrantNotFound reports whether an error is a missing collection
+func isQdrantNotFound(err error) bool {
+	var qdrantErr *QdrantError
+	return errors.As(err, &qdrantErr) && qdrantErr.StatusCode == http.StatusNotFound
+}
+
+// request sends a request about the collection (path is relative to /collections/<collection>)
+// and decodes the result of the answer in result (if not nil)
+func (s *QdrantStore) request(method string, path string, body any, result any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	request, err := http.NewRequest(method, s.baseURL+"/collections/"+url.PathEscape(s.collection)+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		request.Header.Set("api-key", s.apiKey)
+	}
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	var answer struct {
+		Result json.RawMessage `json:"result"`
+		Status any             `json:"status"`
+	}
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		message := strings.TrimSpace(string(data))
+		// The errors are {"status": {"error": "..."}}
+		if json.Unmarshal(data, &answer) == nil {
+			if status, ok := answer.Status.(map[string]any); ok && status["error"] != nil {
+				message = fmt.Sprint(status["error"])
+			}
+		}
+		return &QdrantError{StatusCode: response.StatusCode, Message: message}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, &answer); err != nil {
+		return fmt.Errorf("qdrant: invalid answer: %w", err)
+	}
+	return json.Unmarshal(answer.Result, result)
+}
+
+// qdrantPointId returns the point id of a record id: the id itself if it is a UUID, a UUID derived from it otherwise
+func qdrantPointId(id string) string {
+	if _, err := uuid.Parse(id); err == nil {
+		return id
+	}
+	return uuid.NewSHA1(uuid.NameSpaceURL, []byte(id)).String()
+}
+
+// qdrantFilter returns the Qdrant filter of a metadata filter: a match condition on a list matches
+// any of its values, the numbers with a fractional part are matched with a range
+func qdrantFilter(filter MetadataFilter) map[string]any {
+	must := []map[string]any{}
+	for _, key := range filter.keys() {
+		condition := map[string]any{"key": "metadata." + key}
+		value := normalizeJSONValue(filter[key])
+		if number, ok := value.(float64); ok && number != math.Trunc(number) {
+			condition["range"] = map[string]any{"gte": number, "lte": number}
+		} else {
+			condition["match"] = map[string]any{"value": value}
+		}
+		must = append(must, condition)
+	}
+	return map[string]any{"must": must}
+}
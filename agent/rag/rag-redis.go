@@ -0,0 +1,232 @@
+package rag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisVectorStore implements VectorStore with a Redis server: each record is stored in JSON under
+// <prefix>:record:<id>, and the ids in the set <prefix>:ids.
+//
+// The store works with any Redis server (it doesn't need the RediSearch module), but the similarities are
+// computed by the client: each search reads all the records of the store, so its cost grows linearly with
+// the corpus (network transfer and memory of the application). It suits the corpora of a few thousand
+// records; for larger ones use PgVectorStore or QdrantStore, whose searches run in the database.
+//
+// Example usage:
+//
+//	store, err := rag.NewRedisVectorStore("redis://localhost:6379/0", rag.WithRedisPrefix("docs"))
+//	if err != nil {
+//	  return err
+//	}
+//	defer store.Close()
+//	_, err = store.Save(rag.VectorRecord{Prompt: chunk, Embedding: embedding})
+type RedisVectorStore struct {
+	client  *redis.Client
+	prefix  string
+	timeout time.Duration
+}
+
+// RedisVectorStoreOption configures a RedisVectorStore
+type RedisVectorStoreOption func(*RedisVectorStore)
+
+// WithRedisPrefix sets the prefix of the keys of the store ("rag" by default), to keep several stores in a database
+func WithRedisPrefix(prefix string) RedisVectorStoreOption {
+	return func(s *RedisVectorStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithRedisTimeout sets the timeout of the connection and of each command (5 seconds by default)
+func WithRedisTimeout(timeout time.Duration) RedisVectorStoreOption {
+	return func(s *RedisVectorStore) {
+		s.timeout = timeout
+	}
+}
+
+// redisBatchSize is the number of records read by MGET
+const redisBatchSize = 500
+
+var _ FilteredVectorStore = (*RedisVectorStore)(nil)
+
+// NewRedisVectorStore connects to a Redis server, redisURL is redis://[user:password@]host[:port][/database]
+// (rediss:// with TLS)
+func NewRedisVectorStore(redisURL string, options ...RedisVectorStoreOption) (*RedisVectorStore, error) {
+	redisOptions, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	store := &RedisVectorStore{
+		prefix:  "rag",
+		timeout: 5 * time.Second,
+	}
+	for _, option := range options {
+		option(store)
+	}
+	if store.timeout > 0 {
+		redisOptions.DialTimeout = store.timeout
+		redisOptions.ReadTimeout = store.timeout
+		redisOptions.WriteTimeout = store.timeout
+	}
+	store.client = redis.NewClient(redisOptions)
+
+	ctx, cancel := store.context()
+	defer cancel()
+	if err := store.client.Ping(ctx).Err(); err != nil {
+		store.client.Close()
+		return nil, fmt.Errorf("unable to connect to Redis at %s: %w", redisOptions.Addr, err)
+	}
+	return store, nil
+}
+
+// GetAll returns all vector records stored in Redis
+func (s *RedisVectorStore) GetAll() ([]VectorRecord, error) {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return nil, err
+	}
+	records := []VectorRecord{}
+	for start := 0; start < len(ids); start += redisBatchSize {
+		values, err := s.client.MGet(ctx, s.recordKeys(ids[start:min(start+redisBatchSize, len(ids))])...).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, value := range values {
+			// A record deleted since SMEMBERS is nil
+			data, ok := value.(string)
+			if !ok {
+				continue
+			}
+			var record VectorRecord
+			if err := json.Unmarshal([]byte(data), &record); err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+	}
+	return records, nil
+}
+
+// Save saves a vector record in Redis, a new UUID is given to a record without ID.
+// A record with the ID of an existing one replaces it.
+func (s *RedisVectorStore) Save(vectorRecord VectorRecord) (VectorRecord, error) {
+	if vectorRecord.Id == "" {
+		vectorRecord.Id = uuid.New().String()
+	}
+	data, err := json.Marshal(vectorRecord)
+	if err != nil {
+		return vectorRecord, err
+	}
+
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.recordKey(vectorRecord.Id), data, 0)
+		pipe.SAdd(ctx, s.idsKey(), vectorRecord.Id)
+		return nil
+	})
+	return vectorRecord, err
+}
+
+// SearchSimilarities returns the records with a cosine similarity greater than or equal to limit
+// (all the records are read, see RedisVectorStore)
+func (s *RedisVectorStore) SearchSimilarities(embeddingFromQuestion VectorRecord, limit float64) ([]VectorRecord, error) {
+	all, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var records []VectorRecord
+	for _, v := range all {
+		distance := cosineSimilarity(embeddingFromQuestion.Embedding, v.Embedding)
+		if distance >= limit {
+			v.CosineSimilarity = distance
+			records = append(records, v)
+		}
+	}
+	return records, nil
+}
+
+// SearchTopNSimilarities returns at most max records with a cosine similarity greater than or equal to limit,
+// the most similar first
+func (s *RedisVectorStore) SearchTopNSimilarities(embeddingFromQuestion VectorRecord, limit float64, max int) ([]VectorRecord, error) {
+	records, err := s.SearchSimilarities(embeddingFromQuestion, limit)
+	if err != nil {
+		return nil, err
+	}
+	return getTopNVectorRecords(records, max), nil
+}
+
+// SearchTopNSimilaritiesWithFilter is SearchTopNSimilarities for the records whose metadata matches the filter
+// (the records are filtered by the client)
+func (s *RedisVectorStore) SearchTopNSimilaritiesWithFilter(embeddingFromQuestion VectorRecord, limit float64, max int, filter MetadataFilter) ([]VectorRecord, error) {
+	return SearchTopNSimilaritiesMatching(s, embeddingFromQuestion, limit, max, filter.matchRecord)
+}
+
+// Delete removes a vector record from Redis
+func (s *RedisVectorStore) Delete(id string) error {
+	ctx, cancel := s.context()
+	defer cancel()
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, s.recordKey(id))
+		pipe.SRem(ctx, s.idsKey(), id)
+		return nil
+	})
+	return err
+}
+
+// Reset removes all the vector records of the store (the other keys of the database are kept)
+func (s *RedisVectorStore) Reset() error {
+	ctx, cancel := s.context()
+	defer cancel()
+
+	ids, err := s.client.SMembers(ctx, s.idsKey()).Result()
+	if err != nil {
+		return err
+	}
+	for start := 0; start < len(ids); start += redisBatchSize {
+		if err := s.client.Del(ctx, s.recordKeys(ids[start:min(start+redisBatchSize, len(ids))])...).Err(); err != nil {
+			return err
+		}
+	}
+	return s.client.Del(ctx, s.idsKey()).Err()
+}
+
+// Close closes the connections to Redis
+func (s *RedisVectorStore) Close() error {
+	return s.client.Close()
+}
+
+// recordKey returns the key of a record
+func (s *RedisVectorStore) recordKey(id string) string {
+	return s.prefix + ":record:" + id
+}
+
+// recordKeys returns the keys of records
+func (s *RedisVectorStore) recordKeys(ids []string) []string {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = s.recordKey(id)
+	}
+	return keys
+}
+
+// idsKey returns the key of the set of the ids
+func (s *RedisVectorStore) idsKey() string {
+	return s.prefix + ":ids"
+}
+
+// context returns the context of a command, with the timeout of the store
+func (s *RedisVectorStore) context() (context.Context, context.CancelFunc) {
+	if s.timeout > 0 {
+		return context.WithTimeout(context.Background(), s.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
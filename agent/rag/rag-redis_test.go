@@ -0,0 +1,55 @@
+package rag
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestRedisVectorStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	store, err := NewRedisVectorStore("redis://:secret@"+server.Addr()+"/0", WithRedisPrefix("docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	// The keys of other applications are kept by Reset
+	server.Set("other", "value")
+	testVectorStore(t, store)
+	if !server.Exists("other") {
+		t.Fatal("Reset should only remove the keys of the store")
+	}
+
+	if _, err := store.Save(VectorRecord{Id: "a", Embedding: []float64{1, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if !server.Exists("docs:record:a") {
+		t.Fatal("the record should be stored under the prefix")
+	}
+	if members, _ := server.Members("docs:ids"); len(members) != 1 || members[0] != "a" {
+		t.Fatalf("the id should be in the set of the ids, got %v", members)
+	}
+
+	// A server restart doesn't break the store
+	server.Restart()
+	all, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 {
+		t.Fatalf("1 record expected, got %d", len(all))
+	}
+}
+
+func TestNewRedisVectorStoreErrors(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireAuth("secret")
+	if _, err := NewRedisVectorStore("redis://:wrong@" + server.Addr()); err == nil {
+		t.Fatal("a wrong password should be an error")
+	}
+	if _, err := NewRedisVectorStore("http://" + server.Addr()); err == nil {
+		t.Fatal("a URL without the redis scheme should be an error")
+	}
+}
@@ -0,0 +1,181 @@
+package rag
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// SQLiteVectorStore implements VectorStore with SQLite and the sqlite-vec extension: the similarity searches
+// are cosine distance queries (vec_distance_cosine) run by SQLite, the embeddings are stored as float32 vectors.
+//
+// The store uses database/sql, the application registers the driver and loads sqlite-vec, e.g. with
+// github.com/mattn/go-sqlite3 and github.com/asg017/sqlite-vec-go-bindings/cgo (sqlite_vec.Auto loads
+// the extension in the new connections).
+//
+// Example usage:
+//
+//	import (
+//	  sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
+//	  _ "github.com/mattn/go-sqlite3"
+//	)
+//
+//	sqlite_vec.Auto()
+//	store, err := rag.NewSQLiteVectorStore("sqlite3", "rag.db", rag.WithSQLiteTable("docs"))
+//	if err != nil {
+//	  return err
+//	}
+//	defer store.Close()
+//	similarities, err := store.SearchTopNSimilarities(question, 0.6, 5)
+type SQLiteVectorStore struct {
+	db    *sql.DB
+	table string
+}
+
+// SQLiteVectorStoreOption configures a SQLiteVectorStore
+type SQLiteVectorStoreOption func(*SQLiteVectorStore)
+
+// WithSQLiteTable sets the table of the records ("rag_records" by default)
+func WithSQLiteTable(table string) SQLiteVectorStoreOption {
+	return func(s *SQLiteVectorStore) {
+		s.table = table
+	}
+}
+
+var _ VectorStore = (*SQLiteVectorStore)(nil)
+
+// NewSQLiteVectorStore opens the SQLite database of dataSourceName with the database/sql driver of driverName
+// (e.g. "sqlite3"), checks that sqlite-vec is loaded and creates the table if it doesn't exist
+func NewSQLiteVectorStore(driverName string, dataSourceName string, options ...SQLiteVectorStoreOption) (*SQLiteVectorStore, error) {
+	store := &SQLiteVectorStore{table: "rag_records"}
+	for _, option := range options {
+		option(store)
+	}
+	db, err := sql.Open(driverName, dataSourceName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the SQLite database %s: %w", dataSourceName, err)
+	}
+	var version string
+	if err := db.QueryRow(`SELECT vec_version()`).Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite-vec isn't loaded by the driver %s: %w", driverName, err)
+	}
+	_, err = db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (id TEXT PRIMARY KEY, prompt TEXT NOT NULL, embedding BLOB NOT NULL, metadata TEXT)`,
+		store.quotedTable()))
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("unable to create the table %s: %w", store.table, err)
+	}
+	store.db = db
+	return store, nil
+}
+
+// GetAll returns all vector records of the table
+func (s *SQLiteVectorStore) GetAll() ([]VectorRecord, error) {
+	return s.query(fmt.Sprintf(`SELECT id, prompt, vec_to_json(embedding), metadata, 0.0 FROM %s`, s.quotedTable()))
+}
+
+// Save saves a vector record in the table, a new UUID is given to a record without ID.
+// A record with the ID of an existing one replaces it.
+func (s *SQLiteVectorStore) Save(vectorRecord VectorRecord) (VectorRecord, error) {
+	if vectorRecord.Id == "" {
+		vectorRecord.Id = uuid.New().String()
+	}
+	embedding, err := json.Marshal(vectorRecord.Embedding)
+	if err != nil {
+		return vectorRecord, err
+	}
+	var metadata *string
+	if vectorRecord.Metadata != nil {
+		data, err := json.Marshal(vectorRecord.Metadata)
+		if err != nil {
+			return vectorRecord, err
+		}
+		text := string(data)
+		metadata = &text
+	}
+	_, err = s.db.Exec(fmt.Sprintf(
+		`INSERT INTO %s (id, prompt, embedding, metadata) VALUES (?, ?, vec_f32(?), ?)
+		ON CONFLICT (id) DO UPDATE SET prompt = excluded.prompt, embedding = excluded.embedding, metadata = excluded.metadata`,
+		s.quotedTable()), vectorRecord.Id, vectorRecord.Prompt, string(embedding), metadata)
+	return vectorRecord, err
+}
+
+// SearchSimilarities returns the records with a cosine similarity greater than or equal to limit,
+// the most similar first
+func (s *SQLiteVectorStore) SearchSimilarities(embeddingFromQuestion VectorRecord, limit float64) ([]VectorRecord, error) {
+	return s.search(embeddingFromQuestion, limit, -1)
+}
+
+// SearchTopNSimilarities returns at most max records with a cosine similarity greater than or equal to limit,
+// the most similar first
+func (s *SQLiteVectorStore) SearchTopNSimilarities(embeddingFromQuestion VectorRecord, limit float64, max int) ([]VectorRecord, error) {
+	if max <= 0 {
+		return []VectorRecord{}, nil
+	}
+	return s.search(embeddingFromQuestion, limit, max)
+}
+
+// Delete removes a vector record from the table
+func (s *SQLiteVectorStore) Delete(id string) error {
+	_, err := s.db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, s.quotedTable()), id)
+	return err
+}
+
+// Reset removes all the vector records of the table
+func (s *SQLiteVectorStore) Reset() error {
+	_, err := s.db.Exec(fmt.Sprintf(`DELETE FROM %s`, s.quotedTable()))
+	return err
+}
+
+// Close closes the SQLite database
+func (s *SQLiteVectorStore) Close() error {
+	return s.db.Close()
+}
+
+// search runs a cosine distance query, max is the number of records (-1 for all)
+func (s *SQLiteVectorStore) search(embeddingFromQuestion VectorRecord, limit float64, max int) ([]VectorRecord, error) {
+	embedding, err := json.Marshal(embeddingFromQuestion.Embedding)
+	if err != nil {
+		return nil, err
+	}
+	return s.query(fmt.Sprintf(`SELECT id, prompt, vec_to_json(embedding), metadata, similarity FROM (
+			SELECT *, 1 - vec_distance_cosine(embedding, vec_f32(?)) AS similarity FROM %s
+		) WHERE similarity >= ? ORDER BY similarity DESC LIMIT ?`, s.quotedTable()), string(embedding), limit, max)
+}
+
+// query runs a query of the rows id, prompt, embedding, metadata, similarity
+func (s *SQLiteVectorStore) query(query string, args ...any) ([]VectorRecord, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	records := []VectorRecord{}
+	for rows.Next() {
+		var record VectorRecord
+		var embedding string
+		var metadata sql.NullString
+		if err := rows.Scan(&record.Id, &record.Prompt, &embedding, &metadata, &record.CosineSimilarity); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(embedding), &record.Embedding); err != nil {
+			return nil, fmt.Errorf("sqlite: invalid vector: %w", err)
+		}
+		if metadata.Valid {
+			if err := json.Unmarshal([]byte(metadata.String), &record.Metadata); err != nil {
+				return nil, fmt.Errorf("sqlite: invalid metadata: %w", err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
+
+// quotedTable returns the quoted name of the table
+func (s *SQLiteVectorStore) quotedTable() string {
+	return `"` + strings.ReplaceAll(s.table, `"`, `""`) + `"`
+}
@@ -0,0 +1,42 @@
+//go:build cgo
+
+package rag
+
+import (
+	"path/filepath"
+	"testing"
+
+	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestSQLiteVectorStore(t *testing.T) {
+	sqlite_vec.Auto()
+	path := filepath.Join(t.TempDir(), "rag.db")
+	store, err := NewSQLiteVectorStore("sqlite3", path, WithSQLiteTable("docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testVectorStore(t, store)
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	vectorStore, err := NewVectorStore("sqlite:" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqliteStore, ok := vectorStore.(*SQLiteVectorStore)
+	if !ok {
+		t.Fatalf("a SQLiteVectorStore expected, got %T", vectorStore)
+	}
+	sqliteStore.Close()
+}
+
+func TestSQLiteVectorStoreWithoutSqliteVec(t *testing.T) {
+	sqlite_vec.Cancel()
+	defer sqlite_vec.Auto()
+	if _, err := NewSQLiteVectorStore("sqlite3", ":memory:"); err == nil {
+		t.Fatal("a database without sqlite-vec should be an error")
+	}
+}
@@ -0,0 +1,174 @@
+package rag
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// testedVectorStore is a vector store whose records can be deleted
+type testedVectorStore interface {
+	VectorStore
+	Delete(id string) error
+	Reset() error
+}
+
+// testVectorStore checks the behavior shared by the vector stores
+func testVectorStore(t *testing.T, store testedVectorStore) {
+	t.Helper()
+	records := []VectorRecord{
+		{Id: "a", Prompt: "a", Embedding: []float64{1, 0}, Metadata: map[string]any{"tag": []any{"go", "ai"}}},
+		{Id: "b", Prompt: "b", Embedding: []float64{0.8, 0.6}, Metadata: map[string]any{"tag": "rust"}},
+		{Id: "c", Prompt: "c", Embedding: []float64{0, 1}},
+	}
+	for _, record := range records {
+		if _, err := store.Save(record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	saved, err := store.Save(VectorRecord{Prompt: "d", Embedding: []float64{-1, 0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Id == "" {
+		t.Fatal("an id should be given to a record without id")
+	}
+	// A record with an existing id replaces it
+	if _, err := store.Save(VectorRecord{Id: "c", Prompt: "c2", Embedding: []float64{0, 1}}); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 4 {
+		t.Fatalf("4 records expected, got %d", len(all))
+	}
+	for _, record := range all {
+		if record.Id == "c" && record.Prompt != "c2" {
+			t.Fatalf("the record c should be replaced, got %q", record.Prompt)
+		}
+		if record.Id == "a" && (len(record.Embedding) != 2 || record.Metadata["tag"] == nil) {
+			t.Fatalf("the embedding and the metadata of a should be kept, got %+v", record)
+		}
+	}
+
+	question := VectorRecord{Embedding: []float64{1, 0}}
+	similarities, err := store.SearchSimilarities(question, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similarities) != 2 {
+		t.Fatalf("2 records expected above 0.5, got %v", similarities)
+	}
+	top, err := store.SearchTopNSimilarities(question, -1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 2 || top[0].Id != "a" || top[1].Id != "b" {
+		t.Fatalf("the records a and b expected, got %v", top)
+	}
+	if top[0].CosineSimilarity < 0.999 || top[1].CosineSimilarity < 0.799 || top[1].CosineSimilarity > 0.801 {
+		t.Fatalf("unexpected similarities %v and %v", top[0].CosineSimilarity, top[1].CosineSimilarity)
+	}
+
+	filtered, err := SearchTopNSimilaritiesWithFilter(store, question, -1, 5, MetadataFilter{"tag": "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filtered) != 1 || filtered[0].Id != "a" {
+		t.Fatalf("the record tagged go expected, got %v", filtered)
+	}
+
+	if err := store.Delete("a"); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ = store.GetAll(); len(all) != 3 {
+		t.Fatalf("3 records expected after the deletion, got %d", len(all))
+	}
+	if err := store.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if all, _ = store.GetAll(); len(all) != 0 {
+		t.Fatalf("no records expected after the reset, got %d", len(all))
+	}
+}
+
+func TestBoltVectorStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rag.db")
+	store, err := NewBoltVectorStore(path, WithBoltBucket("docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testVectorStore(t, store)
+
+	// The records are persisted in the file
+	if _, err := store.Save(VectorRecord{Id: "kept", Embedding: []float64{1}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewBoltVectorStore(path, WithBoltBucket("docs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	all, err := store.GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Id != "kept" {
+		t.Fatalf("the saved record should be read again, got %v", all)
+	}
+}
+
+func TestStorePath(t *testing.T) {
+	tests := []struct {
+		url     string
+		path    string
+		wantErr bool
+	}{
+		{url: "bolt:rag.db", path: "rag.db"},
+		{url: "bolt:data/rag.db", path: "data/rag.db"},
+		{url: "sqlite:///var/lib/rag.db", path: "/var/lib/rag.db"},
+		{url: "sqlite://rag.db", wantErr: true},
+		{url: "bolt:", wantErr: true},
+	}
+	for _, test := range tests {
+		path, err := storePath(test.url)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: an error was expected", test.url)
+			}
+			continue
+		}
+		if err != nil || path != test.path {
+			t.Errorf("%s: got %q, %v, want %q", test.url, path, err, test.path)
+		}
+	}
+}
+
+func TestNewVectorStore(t *testing.T) {
+	store, err := NewVectorStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.(*MemoryVectorStore); !ok {
+		t.Fatalf("a MemoryVectorStore expected, got %T", store)
+	}
+
+	store, err = NewVectorStore("bolt:" + filepath.Join(t.TempDir(), "rag.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	boltStore, ok := store.(*BoltVectorStore)
+	if !ok {
+		t.Fatalf("a BoltVectorStore expected, got %T", store)
+	}
+	boltStore.Close()
+
+	if _, err := NewVectorStore("mongodb://localhost"); err == nil {
+		t.Fatal("an unsupported store should be an error")
+	}
+}
@@ -0,0 +1,117 @@
+// Package replay records the interactions of the agents with a provider (the requests and the responses,
+// streamed or not) to fixture files, and serves them back without network, so the tests of the applications
+// built on mu run offline, fast and deterministically.
+//
+// Example usage:
+//
+//	// Record once against a real provider
+//	recorder := replay.NewRecorder("testdata/weather.json")
+//	client := openai.NewClient(option.WithBaseURL(baseURL), option.WithMiddleware(recorder.Middleware()))
+//	// ... run the agents with the client, then
+//	err := recorder.Save()
+//
+//	// Replay in the tests
+//	agent, err := replay.NewReplayAgent(ctx, "Bob", "testdata/weather.json", mu.WithParams(params))
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Fixture is the recorded interactions of a scenario, in order
+type Fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a request to the provider and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded request, without its headers (they hold the API keys)
+type Request struct {
+	Method string `json:"method"`
+	// Path is the path of the request relative to the API (e.g. /chat/completions)
+	Path string          `json:"path"`
+	Body json.RawMessage `json:"body,omitempty"`
+}
+
+// Response is a recorded response: Body for a JSON response, Text for another one,
+// Chunks for a stream of server-sent events (the JSON of their data, [DONE] excluded)
+type Response struct {
+	Status      int               `json:"status"`
+	ContentType string            `json:"content_type,omitempty"`
+	Body        json.RawMessage   `json:"body,omitempty"`
+	Text        string            `json:"text,omitempty"`
+	Chunks      []json.RawMessage `json:"chunks,omitempty"`
+}
+
+// Streamed reports whether the response is a stream of server-sent events
+func (response Response) Streamed() bool {
+	return strings.HasPrefix(response.ContentType, "text/event-stream")
+}
+
+// content returns the body of the response as it was sent
+func (response Response) content() []byte {
+	if !response.Streamed() {
+		if len(response.Body) > 0 {
+			return response.Body
+		}
+		return []byte(response.Text)
+	}
+	var buffer bytes.Buffer
+	for _, chunk := range response.Chunks {
+		// A data line can't span several lines, the chunks of a saved fixture are indented
+		buffer.WriteString("data: ")
+		buffer.Write(canonicalJSON(chunk))
+		buffer.WriteString("\n\n")
+	}
+	buffer.WriteString("data: [DONE]\n\n")
+	return buffer.Bytes()
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Save writes the fixture to a file, its directory is created if needed
+func (fixture *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// canonicalJSON returns a JSON document with sorted keys and without spaces, the data itself if it isn't JSON
+func canonicalJSON(data []byte) []byte {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return data
+	}
+	canonical, err := json.Marshal(value)
+	if err != nil {
+		return data
+	}
+	return canonical
+}
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// ErrFixtureExhausted is returned when a request is sent after the last recorded interaction
+var ErrFixtureExhausted = errors.New("no recorded interaction left")
+
+// ErrRequestMismatch is returned when a request differs from the recorded one (see WithStrictRequests)
+var ErrRequestMismatch = errors.New("the request differs from the recorded one")
+
+// replayBaseURL is the base URL of the replay clients, no request leaves the process
+const replayBaseURL = "http://replay.invalid/v1/"
+
+// PlayerOption is a functional option for configuring Player instances
+type PlayerOption func(*Player)
+
+// WithStrictRequests makes the player compare the body of each request with the recorded one (as JSON,
+// the order of the keys doesn't matter): a prompt or a parameter changed since the recording is reported
+// as ErrRequestMismatch instead of being answered with the recorded response
+func WithStrictRequests() PlayerOption {
+	return func(player *Player) {
+		player.strict = true
+	}
+}
+
+// Player serves the interactions of a fixture in order, in place of the provider
+type Player struct {
+	fixture *Fixture
+	strict  bool
+	mutex   sync.Mutex
+	next    int
+}
+
+// NewPlayer creates a player of a fixture
+func NewPlayer(fixture *Fixture, options ...PlayerOption) *Player {
+	player := &Player{fixture: fixture}
+	for _, option := range options {
+		option(player)
+	}
+	return player
+}
+
+// LoadPlayer creates a player of a fixture file
+func LoadPlayer(path string, options ...PlayerOption) (*Player, error) {
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPlayer(fixture, options...), nil
+}
+
+// Middleware returns the middleware of the OpenAI client answering the requests with the recorded
+// responses, without sending them
+func (player *Player) Middleware() option.Middleware {
+	return func(req *http.Request, _ option.MiddlewareNext) (*http.Response, error) {
+		var body []byte
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			req.Body.Close()
+			body = data
+		}
+		interaction, err := player.take(req.Method, apiPath(req), body)
+		if err != nil {
+			return nil, err
+		}
+		contentType := interaction.Response.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.Status, http.StatusText(interaction.Response.Status)),
+			StatusCode:    interaction.Response.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{contentType}},
+			Body:          io.NopCloser(bytes.NewReader(interaction.Response.content())),
+			ContentLength: -1,
+			Request:       req,
+		}, nil
+	}
+}
+
+// take returns the next interaction, checking that it answers the request
+func (player *Player) take(method, path string, body []byte) (Interaction, error) {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	if player.next >= len(player.fixture.Interactions) {
+		return Interaction{}, fmt.Errorf("%s %s: %w (%d interactions)", method, path, ErrFixtureExhausted, len(player.fixture.Interactions))
+	}
+	interaction := player.fixture.Interactions[player.next]
+	recorded := interaction.Request
+	if recorded.Method != method || !(strings.HasSuffix(recorded.Path, path) || strings.HasSuffix(path, recorded.Path)) {
+		return Interaction{}, fmt.Errorf("interaction %d: %w: %s %s instead of %s %s",
+			player.next, ErrRequestMismatch, method, path, recorded.Method, recorded.Path)
+	}
+	if player.strict && !bytes.Equal(canonicalJSON(body), canonicalJSON(recorded.Body)) {
+		return Interaction{}, fmt.Errorf("interaction %d: %w: %s", player.next, ErrRequestMismatch, canonicalJSON(body))
+	}
+	player.next++
+	return interaction, nil
+}
+
+// Remaining returns the number of interactions not served yet, a test can check that all of them were used
+func (player *Player) Remaining() int {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	return len(player.fixture.Interactions) - player.next
+}
+
+// Rewind serves the interactions again from the first one
+func (player *Player) Rewind() {
+	player.mutex.Lock()
+	defer player.mutex.Unlock()
+	player.next = 0
+}
+
+// Client returns an OpenAI client answered by the player (the options are applied before the replay
+// ones, without retries: a recorded error is returned as is)
+func (player *Player) Client(options ...option.RequestOption) openai.Client {
+	options = append(options,
+		option.WithBaseURL(replayBaseURL),
+		option.WithAPIKey("replay"),
+		option.WithMaxRetries(0),
+		option.WithMiddleware(player.Middleware()),
+	)
+	return openai.NewClient(options...)
+}
+
+// NewReplayAgent creates an agent answered by the interactions of a fixture file, the options configure
+// the agent as in the recording (mu.WithParams...); the client of the options is replaced by the replay one
+//
+// Example usage:
+//
+//	agent, err := replay.NewReplayAgent(ctx, "Bob", "testdata/weather.json",
+//	  mu.WithParams(openai.ChatCompletionNewParams{Model: "ai/qwen2.5:latest", Tools: tools}),
+//	)
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, executeFunction)
+func NewReplayAgent(ctx context.Context, name, fixturePath string, options ...mu.AgentOption) (mu.Agent, error) {
+	player, err := LoadPlayer(fixturePath)
+	if err != nil {
+		return nil, err
+	}
+	options = append(options, mu.WithClient(player.Client()))
+	return mu.NewAgent(ctx, name, options...)
+}
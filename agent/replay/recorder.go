@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Recorder records the interactions of an OpenAI client with the provider, see Middleware
+type Recorder struct {
+	path    string
+	mutex   sync.Mutex
+	fixture Fixture
+}
+
+// NewRecorder creates a recorder saving its fixture to path
+func NewRecorder(path string) *Recorder {
+	return &Recorder{path: path, fixture: Fixture{Interactions: []Interaction{}}}
+}
+
+// Middleware returns the middleware of the OpenAI client recording its requests and their responses.
+// The interactions are kept in the order of the requests; a streamed response is recorded when
+// it has been read to the end or closed.
+//
+// Example usage:
+//
+//	client := openai.NewClient(option.WithMiddleware(recorder.Middleware()))
+func (recorder *Recorder) Middleware() option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		request := Request{Method: req.Method, Path: apiPath(req)}
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			req.Body.Close()
+			req.Body = io.NopCloser(bytes.NewReader(data))
+			if len(data) > 0 {
+				request.Body = json.RawMessage(canonicalJSON(data))
+			}
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return resp, err
+		}
+		// The slot of the interaction is reserved now, to keep the order of the requests
+		recorder.mutex.Lock()
+		index := len(recorder.fixture.Interactions)
+		recorder.fixture.Interactions = append(recorder.fixture.Interactions, Interaction{Request: request})
+		recorder.mutex.Unlock()
+
+		response := Response{Status: resp.StatusCode, ContentType: resp.Header.Get("Content-Type")}
+		if response.Streamed() {
+			resp.Body = &recordingBody{body: resp.Body, done: func(data []byte) {
+				response.Chunks = parseEvents(data)
+				recorder.record(index, response)
+			}}
+			return resp, nil
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		if json.Valid(data) {
+			response.Body = json.RawMessage(data)
+		} else {
+			response.Text = string(data)
+		}
+		recorder.record(index, response)
+		return resp, nil
+	}
+}
+
+// record sets the response of an interaction
+func (recorder *Recorder) record(index int, response Response) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.fixture.Interactions[index].Response = response
+}
+
+// Fixture returns a copy of the interactions recorded so far
+func (recorder *Recorder) Fixture() *Fixture {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return &Fixture{Interactions: append([]Interaction{}, recorder.fixture.Interactions...)}
+}
+
+// Save writes the interactions recorded so far to the fixture file
+func (recorder *Recorder) Save() error {
+	return recorder.Fixture().Save(recorder.path)
+}
+
+// recordingBody copies a streamed body while it is read, done is called once at the end of the stream or on close
+type recordingBody struct {
+	body   io.ReadCloser
+	buffer bytes.Buffer
+	once   sync.Once
+	done   func(data []byte)
+}
+
+func (body *recordingBody) Read(p []byte) (int, error) {
+	n, err := body.body.Read(p)
+	body.buffer.Write(p[:n])
+	if err == io.EOF {
+		body.once.Do(func() { body.done(body.buffer.Bytes()) })
+	}
+	return n, err
+}
+
+func (body *recordingBody) Close() error {
+	body.once.Do(func() { body.done(body.buffer.Bytes()) })
+	return body.body.Close()
+}
+
+// parseEvents returns the JSON data of the server-sent events of a stream
+func parseEvents(data []byte) []json.RawMessage {
+	chunks := []json.RawMessage{}
+	for _, event := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n\n") {
+		payload := []string{}
+		for _, line := range strings.Split(event, "\n") {
+			if value, found := strings.CutPrefix(line, "data:"); found {
+				payload = append(payload, strings.TrimPrefix(value, " "))
+			}
+		}
+		content := strings.Join(payload, "\n")
+		if content != "" && content != "[DONE]" && json.Valid([]byte(content)) {
+			chunks = append(chunks, json.RawMessage(canonicalJSON([]byte(content))))
+		}
+	}
+	return chunks
+}
+
+// apiPath returns the path of a request relative to the version of the API (e.g. /chat/completions)
+func apiPath(req *http.Request) string {
+	path := req.URL.Path
+	if idx := strings.LastIndex(path, "/v1/"); idx >= 0 {
+		return path[idx+len("/v1"):]
+	}
+	return path
+}
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule gives the times of the runs of a task
+type Schedule interface {
+	// Next returns the first run time strictly after t (the zero time if there is none)
+	Next(t time.Time) time.Time
+}
+
+// descriptors are the predefined schedules
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron parses a standard 5-field cron expression: minute hour day-of-month month day-of-week.
+// The fields accept *, values, ranges (1-5), lists (1,15), steps (*/10, 8-18/2) and the names of the months
+// and of the days (jan, mon). The descriptors @yearly, @monthly, @weekly, @daily, @hourly and @every <duration>
+// (e.g. @every 90m) are supported too.
+//
+// Example usage:
+//
+//	schedule, err := scheduler.ParseCron("30 8 * * mon-fri") // at 8:30 on weekdays
+//	next := schedule.Next(time.Now())
+func ParseCron(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	if rest, ok := strings.CutPrefix(expression, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedule %q: %w", expression, err)
+		}
+		if interval < time.Second {
+			return nil, fmt.Errorf("invalid schedule %q: the interval must be at least one second", expression)
+		}
+		return every(interval), nil
+	}
+	if descriptor, ok := descriptors[strings.ToLower(expression)]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("invalid schedule %q: 5 fields expected, got %d", expression, len(fields))
+	}
+	schedule := &cronSchedule{}
+	var err error
+	if schedule.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("invalid minute of %q: %w", expression, err)
+	}
+	if schedule.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("invalid hour of %q: %w", expression, err)
+	}
+	if schedule.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("invalid day of month of %q: %w", expression, err)
+	}
+	if schedule.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("invalid month of %q: %w", expression, err)
+	}
+	if schedule.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("invalid day of week of %q: %w", expression, err)
+	}
+	// 7 is sunday too
+	if schedule.dow&(1<<7) != 0 {
+		schedule.dow |= 1
+	}
+	// A field starting with * (e.g. */2) isn't a restriction of the days, as in cron
+	schedule.domStar = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[2], "?")
+	schedule.dowStar = strings.HasPrefix(fields[4], "*") || strings.HasPrefix(fields[4], "?")
+	return schedule, nil
+}
+
+// MustParseCron is like ParseCron but panics if the expression is invalid
+func MustParseCron(expression string) Schedule {
+	schedule, err := ParseCron(expression)
+	if err != nil {
+		panic(err)
+	}
+	return schedule
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// parseField parses a field of a cron expression into a bit set of its values
+func parseField(field string, min, max int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", stepPart)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			lowPart, highPart, _ := strings.Cut(rangePart, "-")
+			var err error
+			if low, err = parseValue(lowPart, min, max, names); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highPart, min, max, names); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range %q", rangePart)
+			}
+		default:
+			value, err := parseValue(rangePart, min, max, names)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			if !hasStep {
+				high = value
+			}
+		}
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue parses a value (or a name) of a field
+func parseValue(value string, min, max int, names map[string]int) (int, error) {
+	if number, ok := names[strings.ToLower(value)]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+	if number < min || number > max {
+		return 0, fmt.Errorf("value %d out of range [%d-%d]", number, min, max)
+	}
+	return number, nil
+}
+
+// cronSchedule is a parsed cron expression, each field is a bit set of its values
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar are true when the days of month or of week are not restricted:
+	// when both are restricted, a day matches if it matches one of them (as cron does)
+	domStar, dowStar bool
+}
+
+// Next implements the Schedule interface, the times are computed in the location of t
+func (schedule *cronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if schedule.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !schedule.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if schedule.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if schedule.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches checks the day of month and the day of week of t
+func (schedule *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := schedule.dom&(1<<uint(t.Day())) != 0
+	dowMatch := schedule.dow&(1<<uint(t.Weekday())) != 0
+	if schedule.domStar || schedule.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// every is the schedule of @every: a fixed interval since the previous run
+type every time.Duration
+
+// Next implements the Schedule interface
+func (interval every) Next(t time.Time) time.Time {
+	return t.Truncate(time.Second).Add(time.Duration(interval))
+}
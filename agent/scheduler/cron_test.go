@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronDayOfMonthAndDayOfWeek(t *testing.T) {
+	// A wednesday
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+	}{
+		{"both restricted: either day", "0 0 1 * mon", time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)},
+		{"both restricted: the 13th or a friday", "0 0 13 * fri", time.Date(2025, time.January, 3, 0, 0, 0, 0, time.UTC)},
+		{"day of month step: both days", "0 0 */2 * mon", time.Date(2025, time.January, 13, 0, 0, 0, 0, time.UTC)},
+		{"day of week step: both days", "0 0 1,15 * */3", time.Date(2025, time.January, 15, 0, 0, 0, 0, time.UTC)},
+		{"day of week step only", "0 0 * * */2", time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)},
+		{"question mark", "0 0 ? * mon", time.Date(2025, time.January, 6, 0, 0, 0, 0, time.UTC)},
+		{"day of week only", "30 8 * * mon-fri", time.Date(2025, time.January, 1, 8, 30, 0, 0, time.UTC)},
+		{"day of month only", "0 12 15 * *", time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2025, time.January, 5, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCron(test.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := schedule.Next(start); !got.Equal(test.want) {
+				t.Fatalf("%s: got %s, want %s", test.expression, got, test.want)
+			}
+		})
+	}
+}
+
+func TestParseCronErrors(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "@every 10ms"} {
+		if _, err := ParseCron(expression); err == nil {
+			t.Errorf("%q should be invalid", expression)
+		}
+	}
+}
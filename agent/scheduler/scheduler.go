@@ -0,0 +1,527 @@
+// Package scheduler runs agent tasks on cron schedules for recurring automation (daily summaries, reports, watches):
+// each run renders the prompt template of its task, lets the agent answer (with its tools), and writes the answer
+// to the sinks of the task (files, webhooks...). A task never overlaps itself, and the runs are kept in a history.
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ErrRunning is returned by RunNow when the task is already running
+var ErrRunning = errors.New("the task is already running")
+
+// Task is an agent task run on a schedule
+type Task struct {
+	Name string
+	// Schedule is a cron expression (see ParseCron)
+	Schedule string
+	// Agent answers the prompt, its messages are restored after each run
+	Agent mu.Agent
+	// Prompt is a text/template of the prompt, with the fields of PromptData, e.g.
+	// "Summarize the news of {{.Now.Format \"Monday 2 January\"}}, the previous summary was:\n{{.LastOutput}}"
+	Prompt string
+	// Tools are set on the agent during the runs (its tools must be settable: mu.BasicAgent),
+	// their calls are executed by ToolCallback
+	Tools []openai.ChatCompletionToolUnionParam
+	// ToolCallback executes the tool calls of the agent: when it is set the runs detect the tool calls
+	ToolCallback func(functionName string, arguments string) (string, error)
+	// Sinks receive the results of the runs (the failed runs too)
+	Sinks []Sink
+}
+
+// PromptData is the data of the prompt templates
+type PromptData struct {
+	Task string
+	// Now is the start time of the run
+	Now time.Time
+	// Date is the date of the run (2006-01-02)
+	Date string
+	// Run is the number of the run, from 1
+	Run int
+	// LastOutput is the output of the last successful run (empty at the first run)
+	LastOutput string
+}
+
+// Result is a run of a task
+type Result struct {
+	Task string `json:"task"`
+	Run  int    `json:"run"`
+	// Scheduled is the time the run was due (the start time of the runs triggered by RunNow)
+	Scheduled time.Time `json:"scheduled"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Prompt    string    `json:"prompt,omitempty"`
+	Output    string    `json:"output,omitempty"`
+	// ToolCalls are the results of the tool calls of the agent
+	ToolCalls []string `json:"tool_calls,omitempty"`
+	// Err is the error of the run or of its sinks
+	Err error `json:"-"`
+	// Skipped is true when the run was due while the previous run was still running
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+// Duration returns the duration of the run
+func (result Result) Duration() time.Duration {
+	return result.End.Sub(result.Start)
+}
+
+// MarshalJSON adds the error message to the JSON of the result
+func (result Result) MarshalJSON() ([]byte, error) {
+	type plain Result
+	errorMessage := ""
+	if result.Err != nil {
+		errorMessage = result.Err.Error()
+	}
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain(result), errorMessage})
+}
+
+// SchedulerOption is a functional option for configuring Scheduler instances
+type SchedulerOption func(*Scheduler)
+
+// Scheduler runs tasks on their schedules
+type Scheduler struct {
+	mutex       sync.Mutex
+	entries     []*entry
+	agentLocks  map[mu.Agent]*sync.Mutex
+	historySize int
+	historyFile string
+	fileMutex   sync.Mutex
+	location    *time.Location
+	onRun       func(result Result)
+	logger      logging.Logger
+	wake        chan struct{}
+	running     sync.WaitGroup
+}
+
+// entry is a task with its schedule and its state
+type entry struct {
+	task       Task
+	schedule   Schedule
+	prompt     *template.Template
+	next       time.Time
+	running    bool
+	runs       int
+	lastOutput string
+	history    []Result
+}
+
+// NewScheduler creates a scheduler, the tasks are added with AddTask and run by Start
+//
+// Example usage:
+//
+//	s := scheduler.NewScheduler(scheduler.WithHistoryFile("runs.jsonl"))
+//	err := s.AddTask(scheduler.Task{
+//	  Name:     "daily-summary",
+//	  Schedule: "0 18 * * mon-fri",
+//	  Agent:    agent,
+//	  Prompt:   "Summarize the changes of {{.Date}}",
+//	  Sinks:    []scheduler.Sink{scheduler.FileSink{Path: "summaries/{{.Date}}.md"}},
+//	})
+//	err = s.Start(ctx) // until ctx is cancelled
+func NewScheduler(options ...SchedulerOption) *Scheduler {
+	scheduler := &Scheduler{
+		agentLocks:  map[mu.Agent]*sync.Mutex{},
+		historySize: 50,
+		location:    time.Local,
+		wake:        make(chan struct{}, 1),
+	}
+	for _, option := range options {
+		option(scheduler)
+	}
+	return scheduler
+}
+
+// WithHistorySize sets the number of runs kept in memory by task (50 by default)
+func WithHistorySize(size int) SchedulerOption {
+	return func(s *Scheduler) {
+		s.historySize = size
+	}
+}
+
+// WithHistoryFile appends the results of the runs to a JSON lines file
+func WithHistoryFile(path string) SchedulerOption {
+	return func(s *Scheduler) {
+		s.historyFile = path
+	}
+}
+
+// WithLocation sets the time zone of the schedules (the local time zone by default)
+func WithLocation(location *time.Location) SchedulerOption {
+	return func(s *Scheduler) {
+		s.location = location
+	}
+}
+
+// WithOnRun sets a callback called with the result of each run (and of each skipped run)
+func WithOnRun(onRun func(result Result)) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onRun = onRun
+	}
+}
+
+// WithLogger sets the logger of the scheduler (logging.Default() otherwise)
+func WithLogger(logger logging.Logger) SchedulerOption {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// log returns the logger of the scheduler
+func (s *Scheduler) log() logging.Logger {
+	return logging.OrDefault(s.logger)
+}
+
+// AddTask adds a task, it can be added while the scheduler is started
+func (s *Scheduler) AddTask(task Task) error {
+	if task.Name == "" {
+		return errors.New("the task has no name")
+	}
+	if task.Agent == nil {
+		return fmt.Errorf("the task %s has no agent", task.Name)
+	}
+	if len(task.Tools) > 0 {
+		if _, ok := task.Agent.(toolsAgent); !ok {
+			return fmt.Errorf("the tools of the agent of the task %s cannot be set", task.Name)
+		}
+	}
+	schedule, err := ParseCron(task.Schedule)
+	if err != nil {
+		return fmt.Errorf("task %s: %w", task.Name, err)
+	}
+	prompt, err := template.New(task.Name).Parse(task.Prompt)
+	if err != nil {
+		return fmt.Errorf("invalid prompt of the task %s: %w", task.Name, err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, e := range s.entries {
+		if e.task.Name == task.Name {
+			return fmt.Errorf("the task %s already exists", task.Name)
+		}
+	}
+	s.entries = append(s.entries, &entry{
+		task:     task,
+		schedule: schedule,
+		prompt:   prompt,
+		next:     schedule.Next(time.Now().In(s.location)),
+	})
+	if _, ok := s.agentLocks[task.Agent]; !ok {
+		s.agentLocks[task.Agent] = &sync.Mutex{}
+	}
+	s.notify()
+	return nil
+}
+
+// RemoveTask removes a task, a run in progress is completed
+func (s *Scheduler) RemoveTask(name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for idx, e := range s.entries {
+		if e.task.Name == name {
+			s.entries = append(s.entries[:idx], s.entries[idx+1:]...)
+			s.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// notify wakes the loop of Start up to recompute the next run
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// TaskInfo describes a task and its next run
+type TaskInfo struct {
+	Name     string
+	Schedule string
+	Next     time.Time
+	Running  bool
+	Runs     int
+}
+
+// Tasks returns the tasks with their next run
+func (s *Scheduler) Tasks() []TaskInfo {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	infos := make([]TaskInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		infos = append(infos, TaskInfo{Name: e.task.Name, Schedule: e.task.Schedule, Next: e.next, Running: e.running, Runs: e.runs})
+	}
+	return infos
+}
+
+// History returns the last runs of a task, the oldest first
+func (s *Scheduler) History(name string) []Result {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if e := s.find(name); e != nil {
+		return append([]Result{}, e.history...)
+	}
+	return nil
+}
+
+// find returns the entry of a task (the mutex must be held)
+func (s *Scheduler) find(name string) *entry {
+	for _, e := range s.entries {
+		if e.task.Name == name {
+			return e
+		}
+	}
+	return nil
+}
+
+// Start runs the tasks on their schedules until the context is cancelled,
+// then it waits for the runs in progress and returns the error of the context
+func (s *Scheduler) Start(ctx context.Context) error {
+	defer s.running.Wait()
+	for {
+		now := time.Now().In(s.location)
+		s.mutex.Lock()
+		var next time.Time
+		var skipped []Result
+		for _, e := range s.entries {
+			if e.next.IsZero() {
+				continue
+			}
+			if !e.next.After(now) {
+				if result, ok := s.trigger(e, e.next); !ok {
+					skipped = append(skipped, result)
+				}
+				e.next = e.schedule.Next(now)
+			}
+			if !e.next.IsZero() && (next.IsZero() || e.next.Before(next)) {
+				next = e.next
+			}
+		}
+		s.mutex.Unlock()
+		for _, result := range skipped {
+			s.publish(result)
+		}
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = time.Until(next)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// trigger starts a scheduled run, or records it as skipped if the task is running (the mutex must be held):
+// the skipped run is returned with false, to be published once the mutex is released
+func (s *Scheduler) trigger(e *entry, scheduled time.Time) (Result, bool) {
+	if e.running {
+		s.log().Warn("scheduled run skipped, the previous run is not completed", "task", e.task.Name, "scheduled", scheduled)
+		now := time.Now()
+		result := Result{Task: e.task.Name, Run: e.runs, Scheduled: scheduled, Start: now, End: now, Skipped: true}
+		s.record(e, result)
+		return result, false
+	}
+	data := s.begin(e, scheduled)
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(e, data, scheduled)
+	}()
+	return Result{}, true
+}
+
+// RunNow runs a task immediately and returns its result, ErrRunning if the task is running
+func (s *Scheduler) RunNow(name string) (Result, error) {
+	s.mutex.Lock()
+	e := s.find(name)
+	if e == nil {
+		s.mutex.Unlock()
+		return Result{}, fmt.Errorf("unknown task %s", name)
+	}
+	if e.running {
+		s.mutex.Unlock()
+		return Result{}, ErrRunning
+	}
+	now := time.Now().In(s.location)
+	data := s.begin(e, now)
+	s.mutex.Unlock()
+
+	result := s.run(e, data, now)
+	return result, result.Err
+}
+
+// begin marks a task as running and returns the data of its prompt (the mutex must be held)
+func (s *Scheduler) begin(e *entry, scheduled time.Time) PromptData {
+	e.running = true
+	e.runs++
+	return PromptData{
+		Task:       e.task.Name,
+		Now:        scheduled,
+		Date:       scheduled.Format("2006-01-02"),
+		Run:        e.runs,
+		LastOutput: e.lastOutput,
+	}
+}
+
+// run executes a run of a task, writes its result to the sinks and records it
+func (s *Scheduler) run(e *entry, data PromptData, scheduled time.Time) Result {
+	result := Result{Task: e.task.Name, Run: data.Run, Scheduled: scheduled, Start: time.Now()}
+	s.log().Info("task started", "task", e.task.Name, "run", data.Run)
+
+	var prompt bytes.Buffer
+	if result.Err = e.prompt.Execute(&prompt, data); result.Err == nil {
+		result.Prompt = prompt.String()
+		result.Output, result.ToolCalls, result.Err = s.answer(e.task, result.Prompt)
+	}
+	result.End = time.Now()
+
+	var sinkErrors []error
+	for _, sink := range e.task.Sinks {
+		if err := sink.Write(context.Background(), result); err != nil {
+			sinkErrors = append(sinkErrors, err)
+		}
+	}
+	if len(sinkErrors) > 0 {
+		result.Err = errors.Join(append([]error{result.Err}, sinkErrors...)...)
+	}
+	if result.Err != nil {
+		s.log().Error("task failed", "task", e.task.Name, "run", data.Run, "error", result.Err)
+	} else {
+		s.log().Info("task completed", "task", e.task.Name, "run", data.Run, "duration", result.Duration())
+	}
+
+	s.mutex.Lock()
+	e.running = false
+	if result.Err == nil {
+		e.lastOutput = result.Output
+	}
+	s.record(e, result)
+	s.mutex.Unlock()
+	s.publish(result)
+	return result
+}
+
+// toolsAgent is implemented by the agents whose tools can be changed (mu.BasicAgent)
+type toolsAgent interface {
+	GetTools() []openai.ChatCompletionToolUnionParam
+	SetTools(tools []openai.ChatCompletionToolUnionParam)
+}
+
+// answer asks the agent of the task, its messages (and its tools) are restored after the run.
+// The tasks sharing an agent run one at a time.
+func (s *Scheduler) answer(task Task, prompt string) (string, []string, error) {
+	s.mutex.Lock()
+	lock := s.agentLocks[task.Agent]
+	s.mutex.Unlock()
+	lock.Lock()
+	defer lock.Unlock()
+
+	agent := task.Agent
+	saved := append([]openai.ChatCompletionMessageParamUnion{}, agent.GetMessages()...)
+	defer agent.SetMessages(saved)
+	if len(task.Tools) > 0 {
+		settable := agent.(toolsAgent)
+		savedTools := settable.GetTools()
+		settable.SetTools(task.Tools)
+		defer settable.SetTools(savedTools)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)}
+	if task.ToolCallback == nil {
+		output, err := agent.Run(messages)
+		return output, nil, err
+	}
+	_, toolCalls, output, err := agent.DetectToolCalls(messages, task.ToolCallback)
+	return output, toolCalls, err
+}
+
+// record adds a copy of a result to the history of its task (the mutex must be held)
+func (s *Scheduler) record(e *entry, result Result) {
+	result.ToolCalls = slices.Clone(result.ToolCalls)
+	e.history = append(e.history, result)
+	if s.historySize > 0 && len(e.history) > s.historySize {
+		e.history = e.history[len(e.history)-s.historySize:]
+	}
+}
+
+// publish appends a result to the history file and calls the WithOnRun callback (the mutex must not be held:
+// the callback can call the methods of the scheduler, and a slow file doesn't block the other tasks)
+func (s *Scheduler) publish(result Result) {
+	if s.historyFile != "" {
+		s.fileMutex.Lock()
+		err := appendJSONLine(s.historyFile, result)
+		s.fileMutex.Unlock()
+		if err != nil {
+			s.log().Error("failed to save the run history", "file", s.historyFile, "error", err)
+		}
+	}
+	if s.onRun != nil {
+		s.onRun(result)
+	}
+}
+
+// appendJSONLine appends a value to a JSON lines file
+func appendJSONLine(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// LoadHistory reads the results saved by WithHistoryFile (the errors are kept as messages)
+func LoadHistory(path string) ([]Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []Result
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		var record struct {
+			Result
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("invalid history line: %w", err)
+		}
+		if record.Error != "" {
+			record.Result.Err = errors.New(record.Error)
+		}
+		results = append(results, record.Result)
+	}
+	return results, nil
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// fakeAgent answers the prompts with a fixed output, after the release of its gate if it has one
+type fakeAgent struct {
+	mu.Agent
+	gate     chan struct{}
+	messages []openai.ChatCompletionMessageParamUnion
+}
+
+func (a *fakeAgent) Run(messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+	if a.gate != nil {
+		<-a.gate
+	}
+	return "done", nil
+}
+
+func (a *fakeAgent) GetMessages() []openai.ChatCompletionMessageParamUnion {
+	return a.messages
+}
+
+func (a *fakeAgent) SetMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	a.messages = messages
+}
+
+func TestOnRunCanCallTheScheduler(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "runs.jsonl")
+	var s *Scheduler
+	var history []Result
+	s = NewScheduler(WithLogger(logging.Discard()), WithHistoryFile(historyFile), WithOnRun(func(result Result) {
+		history = s.History(result.Task)
+	}))
+	if err := s.AddTask(Task{Name: "report", Schedule: "0 0 1 1 *", Agent: &fakeAgent{}, Prompt: "Report"}); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.RunNow("report")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the callback of WithOnRun is blocked by the scheduler")
+	}
+	if len(history) != 1 || history[0].Output != "done" {
+		t.Fatalf("the callback should see the run in the history, got %+v", history)
+	}
+	data, err := os.ReadFile(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Fatalf("1 line expected in the history file, got %d", lines)
+	}
+}
+
+func TestTriggerReturnsTheSkippedRun(t *testing.T) {
+	agent := &fakeAgent{gate: make(chan struct{})}
+	s := NewScheduler(WithLogger(logging.Discard()))
+	if err := s.AddTask(Task{Name: "report", Schedule: "0 0 1 1 *", Agent: agent, Prompt: "Report"}); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.RunNow("report")
+	}()
+	for {
+		s.mutex.Lock()
+		running := s.find("report").running
+		s.mutex.Unlock()
+		if running {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.mutex.Lock()
+	result, started := s.trigger(s.find("report"), time.Now())
+	s.mutex.Unlock()
+	close(agent.gate)
+	<-done
+	if started || !result.Skipped {
+		t.Fatalf("the run should be skipped and returned, got %v %+v", started, result)
+	}
+	if history := s.History("report"); len(history) != 2 || !history[0].Skipped {
+		t.Fatalf("the skipped run should be in the history, got %+v", history)
+	}
+}
@@ -0,0 +1,111 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// Sink receives the results of the runs of a task
+type Sink interface {
+	Write(ctx context.Context, result Result) error
+}
+
+// SinkFunc is a function used as a Sink
+type SinkFunc func(ctx context.Context, result Result) error
+
+// Write implements the Sink interface for SinkFunc
+func (sink SinkFunc) Write(ctx context.Context, result Result) error {
+	return sink(ctx, result)
+}
+
+// FileSink writes the outputs of the successful runs to files
+type FileSink struct {
+	// Path is a text/template of the path with the fields of Result and .Date, e.g. "summaries/{{.Task}}-{{.Date}}.md"
+	Path string
+	// Append appends the outputs to the file with a markdown heading, instead of replacing the file
+	Append bool
+}
+
+// Write implements the Sink interface for FileSink
+func (sink FileSink) Write(ctx context.Context, result Result) error {
+	if result.Err != nil {
+		return nil
+	}
+	pathTemplate, err := template.New("path").Parse(sink.Path)
+	if err != nil {
+		return fmt.Errorf("invalid path of the file sink: %w", err)
+	}
+	var path bytes.Buffer
+	data := struct {
+		Result
+		Date string
+	}{result, result.Scheduled.Format("2006-01-02")}
+	if err := pathTemplate.Execute(&path, data); err != nil {
+		return fmt.Errorf("invalid path of the file sink: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path.String()), 0755); err != nil {
+		return err
+	}
+	if !sink.Append {
+		return os.WriteFile(path.String(), []byte(result.Output+"\n"), 0644)
+	}
+	file, err := os.OpenFile(path.String(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintf(file, "## %s - %s\n\n%s\n\n", result.Task, result.Scheduled.Format("2006-01-02 15:04"), result.Output)
+	return err
+}
+
+// WebhookSink posts the results of the runs as JSON to a URL (Result with an "error" field for the failed runs)
+type WebhookSink struct {
+	URL string
+	// Headers are added to the requests (e.g. Authorization)
+	Headers map[string]string
+	// Client sends the requests (an http.Client with a 30 seconds timeout if nil)
+	Client *http.Client
+	// FailedOnly posts only the failed runs (alerts)
+	FailedOnly bool
+}
+
+// Write implements the Sink interface for WebhookSink
+func (sink WebhookSink) Write(ctx context.Context, result Result) error {
+	if sink.FailedOnly && result.Err == nil {
+		return nil
+	}
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range sink.Headers {
+		req.Header.Set(name, value)
+	}
+	client := sink.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", sink.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook %s: %s: %s", sink.URL, resp.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
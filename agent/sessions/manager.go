@@ -0,0 +1,136 @@
+package sessions
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+)
+
+// ManagerOption is a functional option for configuring Manager instances
+type ManagerOption func(*Manager)
+
+// Manager creates, updates and lists the sessions of a store.
+// Its updates (Append, AddArtifact) are serialized, so concurrent requests don't lose messages.
+type Manager struct {
+	mutex sync.Mutex
+	store Store
+	newID func() string
+}
+
+// NewManager creates a session manager using a store
+//
+// Example usage:
+//
+//	manager := sessions.NewManager(sessions.NewFileStore("sessions"))
+//	session, err := manager.Open(contextID) // loaded, or created if it doesn't exist
+//	answer, err := agent.Run(append(session.Messages, openai.UserMessage(prompt)))
+//	manager.Append(session.ID, openai.UserMessage(prompt), openai.AssistantMessage(answer))
+func NewManager(store Store, options ...ManagerOption) *Manager {
+	manager := &Manager{
+		store: store,
+		newID: func() string { return uuid.New().String() },
+	}
+	for _, option := range options {
+		option(manager)
+	}
+	return manager
+}
+
+// WithIDGenerator is a functional option that sets the generator of the session IDs (UUIDs by default)
+func WithIDGenerator(newID func() string) ManagerOption {
+	return func(manager *Manager) {
+		manager.newID = newID
+	}
+}
+
+// Store returns the store of the manager
+func (manager *Manager) Store() Store {
+	return manager.store
+}
+
+// Create creates and saves a new session with a generated ID
+func (manager *Manager) Create(metadata map[string]string) (*Session, error) {
+	session := New(manager.newID())
+	for key, value := range metadata {
+		session.Metadata[key] = value
+	}
+	if err := manager.store.Save(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Get returns a session, ErrNotFound if it doesn't exist
+func (manager *Manager) Get(id string) (*Session, error) {
+	return manager.store.Load(id)
+}
+
+// Open returns the session with the given ID, a new session is created (and saved) if it doesn't exist.
+// A new ID is generated when id is empty.
+func (manager *Manager) Open(id string) (*Session, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	return manager.open(id)
+}
+
+// open loads or creates a session (the lock is held by the caller)
+func (manager *Manager) open(id string) (*Session, error) {
+	if id == "" {
+		id = manager.newID()
+	}
+	session, err := manager.store.Load(id)
+	if errors.Is(err, ErrNotFound) {
+		session = New(id)
+		return session, manager.store.Save(session)
+	}
+	return session, err
+}
+
+// Save updates the time of the session and saves it
+func (manager *Manager) Save(session *Session) error {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	session.UpdatedAt = time.Now()
+	return manager.store.Save(session)
+}
+
+// Append adds messages to a session (created if it doesn't exist) and saves it
+func (manager *Manager) Append(id string, messages ...openai.ChatCompletionMessageParamUnion) (*Session, error) {
+	return manager.update(id, func(session *Session) {
+		session.AppendMessages(messages...)
+	})
+}
+
+// AddArtifact adds an artifact to a session (created if it doesn't exist) and saves it
+func (manager *Manager) AddArtifact(id string, artifact Artifact) (Artifact, error) {
+	_, err := manager.update(id, func(session *Session) {
+		artifact = session.AddArtifact(artifact)
+	})
+	return artifact, err
+}
+
+// update loads a session, changes it and saves it atomically
+func (manager *Manager) update(id string, change func(session *Session)) (*Session, error) {
+	manager.mutex.Lock()
+	defer manager.mutex.Unlock()
+	session, err := manager.open(id)
+	if err != nil {
+		return nil, err
+	}
+	change(session)
+	session.UpdatedAt = time.Now()
+	return session, manager.store.Save(session)
+}
+
+// Delete removes a session
+func (manager *Manager) Delete(id string) error {
+	return manager.store.Delete(id)
+}
+
+// List returns the sessions, the most recently updated first
+func (manager *Manager) List() ([]*Session, error) {
+	return manager.store.List()
+}
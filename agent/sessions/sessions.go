@@ -0,0 +1,114 @@
+// Package sessions keeps the conversations of the agents: a session has an ID, metadata, the message history
+// and the artifacts produced during the conversation. The sessions are kept by a Store: in memory, in JSON files
+// or in a SQLite database.
+package sessions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ErrNotFound is returned by the stores when a session doesn't exist
+var ErrNotFound = errors.New("session not found")
+
+// Artifact is a piece of content produced during a session (a file, a report, the result of an A2A task...)
+type Artifact struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	MimeType  string    `json:"mime_type,omitempty"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Session is a conversation: its messages (with their timestamps), metadata and artifacts
+type Session struct {
+	ID        string                                   `json:"id"`
+	CreatedAt time.Time                                `json:"created_at"`
+	UpdatedAt time.Time                                `json:"updated_at"`
+	Metadata  map[string]string                        `json:"metadata,omitempty"`
+	Messages  []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	// Times are the timestamps of the messages (zero when unknown)
+	Times     []time.Time `json:"times,omitempty"`
+	Artifacts []Artifact  `json:"artifacts,omitempty"`
+}
+
+// New creates an empty session
+func New(id string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:        id,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Metadata:  map[string]string{},
+		Messages:  []openai.ChatCompletionMessageParamUnion{},
+	}
+}
+
+// SetMessages replaces the messages of the session, the timestamps of the new messages are set to now
+func (session *Session) SetMessages(messages []openai.ChatCompletionMessageParamUnion) {
+	known := min(len(session.Messages), len(messages))
+	if len(session.Times) > known {
+		session.Times = session.Times[:known]
+	}
+	for len(session.Times) < known {
+		session.Times = append(session.Times, time.Time{})
+	}
+	now := time.Now()
+	for len(session.Times) < len(messages) {
+		session.Times = append(session.Times, now)
+	}
+	session.Messages = messages
+}
+
+// AppendMessages adds messages to the history, timestamped now
+func (session *Session) AppendMessages(messages ...openai.ChatCompletionMessageParamUnion) {
+	session.SetMessages(append(session.Messages, messages...))
+}
+
+// MessageTime returns the timestamp of a message, zero if unknown
+func (session *Session) MessageTime(index int) time.Time {
+	if index < len(session.Times) {
+		return session.Times[index]
+	}
+	return time.Time{}
+}
+
+// AddArtifact adds an artifact to the session, its ID and creation time are set if empty
+func (session *Session) AddArtifact(artifact Artifact) Artifact {
+	if artifact.ID == "" {
+		artifact.ID = fmt.Sprintf("artifact-%d", len(session.Artifacts)+1)
+	}
+	if artifact.CreatedAt.IsZero() {
+		artifact.CreatedAt = time.Now()
+	}
+	session.Artifacts = append(session.Artifacts, artifact)
+	return artifact
+}
+
+// Clone returns a copy of the session, the stores keep copies so the callers can't change them by mistake
+func (session *Session) Clone() *Session {
+	clone := *session
+	clone.Metadata = make(map[string]string, len(session.Metadata))
+	for key, value := range session.Metadata {
+		clone.Metadata[key] = value
+	}
+	clone.Messages = append([]openai.ChatCompletionMessageParamUnion{}, session.Messages...)
+	clone.Times = append([]time.Time(nil), session.Times...)
+	clone.Artifacts = append([]Artifact(nil), session.Artifacts...)
+	return &clone
+}
+
+// ValidateID returns an error if the ID is empty or can't be used as a file name
+func ValidateID(id string) error {
+	if id == "" {
+		return errors.New("the session ID is empty")
+	}
+	if id == "." || id == ".." || strings.ContainsAny(id, `/\`) || strings.ContainsRune(id, 0) {
+		return fmt.Errorf("invalid session ID %q", id)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package sessions
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore keeps each session in a JSON file <directory>/<ID>.json
+type FileStore struct {
+	directory string
+}
+
+// NewFileStore creates a store of JSON files, the directory is created on the first save
+func NewFileStore(directory string) *FileStore {
+	return &FileStore{directory: directory}
+}
+
+// Directory returns the directory of the session files
+func (store *FileStore) Directory() string {
+	return store.directory
+}
+
+// path returns the file of a session
+func (store *FileStore) path(id string) string {
+	return filepath.Join(store.directory, id+".json")
+}
+
+// Load reads the file of a session. The files without ID get the file name as ID.
+func (store *FileStore) Load(id string) (*Session, error) {
+	if err := ValidateID(id); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(store.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &Session{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, fmt.Errorf("invalid session file %s: %w", store.path(id), err)
+	}
+	if session.ID == "" {
+		session.ID = id
+	}
+	if session.Metadata == nil {
+		session.Metadata = map[string]string{}
+	}
+	return session, nil
+}
+
+// Save writes the file of a session
+func (store *FileStore) Save(session *Session) error {
+	if err := ValidateID(session.ID); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(store.directory, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write then rename, so a crash never leaves a truncated file
+	tmpPath := store.path(session.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, store.path(session.ID))
+}
+
+// Delete removes the file of a session
+func (store *FileStore) Delete(id string) error {
+	if err := ValidateID(id); err != nil {
+		return err
+	}
+	if err := os.Remove(store.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List reads all the session files of the directory (the invalid files are skipped)
+func (store *FileStore) List() ([]*Session, error) {
+	entries, err := os.ReadDir(store.directory)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*Session{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sessions := []*Session{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		session, err := store.Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sortByUpdate(sessions)
+	return sessions, nil
+}
@@ -0,0 +1,78 @@
+package sessions
+
+import (
+	"sort"
+	"sync"
+)
+
+// Store keeps the sessions
+type Store interface {
+	// Load returns the session with the given ID, ErrNotFound if it doesn't exist
+	Load(id string) (*Session, error)
+	// Save creates or replaces a session
+	Save(session *Session) error
+	// Delete removes a session (no error if it doesn't exist)
+	Delete(id string) error
+	// List returns all the sessions, the most recently updated first
+	List() ([]*Session, error)
+}
+
+// MemoryStore keeps the sessions in memory (they are lost when the program stops)
+type MemoryStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]*Session
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: map[string]*Session{}}
+}
+
+// Load returns a copy of the session
+func (store *MemoryStore) Load(id string) (*Session, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	session, ok := store.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return session.Clone(), nil
+}
+
+// Save keeps a copy of the session
+func (store *MemoryStore) Save(session *Session) error {
+	if err := ValidateID(session.ID); err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	store.sessions[session.ID] = session.Clone()
+	return nil
+}
+
+// Delete removes a session
+func (store *MemoryStore) Delete(id string) error {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	delete(store.sessions, id)
+	return nil
+}
+
+// List returns copies of the sessions, the most recently updated first
+func (store *MemoryStore) List() ([]*Session, error) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	sessions := make([]*Session, 0, len(store.sessions))
+	for _, session := range store.sessions {
+		sessions = append(sessions, session.Clone())
+	}
+	sortByUpdate(sessions)
+	return sessions, nil
+}
+
+// sortByUpdate sorts the sessions, the most recently updated first
+func sortByUpdate(sessions []*Session) {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].UpdatedAt.After(sessions[j].UpdatedAt)
+	})
+}
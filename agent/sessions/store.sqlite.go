@@ -0,0 +1,124 @@
+package sessions
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// tableNamePattern restricts the table names to plain identifiers (they can't be query parameters)
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// SQLiteStore keeps the sessions in a table of a SQLite database, each session is a JSON document.
+// The store only uses database/sql: the program imports the SQLite driver of its choice.
+type SQLiteStore struct {
+	db    *sql.DB
+	table string
+}
+
+// SQLiteOption is a functional option for configuring SQLiteStore instances
+type SQLiteOption func(*SQLiteStore)
+
+// WithTableName is a functional option that sets the table of the sessions ("sessions" by default)
+func WithTableName(table string) SQLiteOption {
+	return func(store *SQLiteStore) {
+		store.table = table
+	}
+}
+
+// NewSQLiteStore creates a store using an open SQLite database, the table is created if needed
+//
+// Example usage:
+//
+//	import _ "github.com/mattn/go-sqlite3"
+//
+//	db, err := sql.Open("sqlite3", "sessions.db")
+//	store, err := sessions.NewSQLiteStore(db)
+func NewSQLiteStore(db *sql.DB, options ...SQLiteOption) (*SQLiteStore, error) {
+	store := &SQLiteStore{db: db, table: "sessions"}
+	for _, option := range options {
+		option(store)
+	}
+	if !tableNamePattern.MatchString(store.table) {
+		return nil, fmt.Errorf("invalid table name %q", store.table)
+	}
+	_, err := db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		updated_at INTEGER NOT NULL,
+		data TEXT NOT NULL
+	)`, store.table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the table %s: %w", store.table, err)
+	}
+	return store, nil
+}
+
+// Load reads a session
+func (store *SQLiteStore) Load(id string) (*Session, error) {
+	var data string
+	err := store.db.QueryRow(fmt.Sprintf(`SELECT data FROM %s WHERE id = ?`, store.table), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeSession(data)
+}
+
+// Save inserts or replaces a session
+func (store *SQLiteStore) Save(session *Session) error {
+	if err := ValidateID(session.ID); err != nil {
+		return err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	_, err = store.db.Exec(fmt.Sprintf(`INSERT INTO %s (id, updated_at, data) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET updated_at = excluded.updated_at, data = excluded.data`, store.table),
+		session.ID, session.UpdatedAt.UnixNano(), string(data))
+	return err
+}
+
+// Delete removes a session
+func (store *SQLiteStore) Delete(id string) error {
+	_, err := store.db.Exec(fmt.Sprintf(`DELETE FROM %s WHERE id = ?`, store.table), id)
+	return err
+}
+
+// List reads all the sessions, the most recently updated first
+func (store *SQLiteStore) List() ([]*Session, error) {
+	rows, err := store.db.Query(fmt.Sprintf(`SELECT data FROM %s ORDER BY updated_at DESC`, store.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := []*Session{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		session, err := decodeSession(data)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// decodeSession reads a session stored as JSON
+func decodeSession(data string) (*Session, error) {
+	session := &Session{}
+	if err := json.Unmarshal([]byte(data), session); err != nil {
+		return nil, fmt.Errorf("invalid session: %w", err)
+	}
+	if session.Metadata == nil {
+		session.Metadata = map[string]string{}
+	}
+	return session, nil
+}
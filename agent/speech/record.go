@@ -0,0 +1,116 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+// Recorder records speech from the microphone
+type Recorder interface {
+	// Record records until the context is done or the recorder stops by itself (silence, maximum duration),
+	// the audio recorded until then is returned
+	Record(ctx context.Context) (Audio, error)
+}
+
+// CommandRecorder records with a command-line recorder writing an audio file: the {file} argument is replaced
+// by the path of the file (appended without {file}). The recorder is interrupted with SIGINT to finalize the file.
+type CommandRecorder struct {
+	format string
+	name   string
+	args   []string
+}
+
+// NewCommandRecorder creates a recorder running a command-line recorder producing audio in format
+//
+// Example usage:
+//
+//	recorder := speech.NewCommandRecorder("wav", "arecord", "-q", "-f", "S16_LE", "-c", "1", "-r", "16000", "-d", "30", "{file}")
+func NewCommandRecorder(format, name string, args ...string) *CommandRecorder {
+	return &CommandRecorder{format: format, name: name, args: args}
+}
+
+// DefaultRecorder returns the recorder of the first command-line recorder found in the PATH, recording
+// a 16 kHz mono wav of maxDuration at most: rec (SoX, which also stops after two seconds of silence),
+// arecord or ffmpeg
+func DefaultRecorder(maxDuration time.Duration) (*CommandRecorder, error) {
+	seconds := strconv.Itoa(max(int(maxDuration.Seconds()), 1))
+	if _, err := exec.LookPath("rec"); err == nil {
+		return NewCommandRecorder("wav", "rec", "-q", "-c", "1", "-r", "16000", "-b", "16", "{file}",
+			"silence", "1", "0.1", "1%", "1", "2.0", "1%", "trim", "0", seconds), nil
+	}
+	if _, err := exec.LookPath("arecord"); err == nil {
+		return NewCommandRecorder("wav", "arecord", "-q", "-f", "S16_LE", "-c", "1", "-r", "16000", "-d", seconds, "{file}"), nil
+	}
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		input := []string{"-f", "pulse", "-i", "default"}
+		if runtime.GOOS == "darwin" {
+			input = []string{"-f", "avfoundation", "-i", ":0"}
+		}
+		args := append([]string{"-loglevel", "quiet", "-y"}, input...)
+		return NewCommandRecorder("wav", "ffmpeg", append(args, "-t", seconds, "-ac", "1", "-ar", "16000", "{file}")...), nil
+	}
+	return nil, errors.New("no audio recorder found (rec, arecord or ffmpeg)")
+}
+
+// Record implements the Recorder interface for CommandRecorder
+func (r *CommandRecorder) Record(ctx context.Context) (Audio, error) {
+	file, err := os.CreateTemp("", "speech-*."+r.format)
+	if err != nil {
+		return Audio{}, err
+	}
+	path := file.Name()
+	file.Close()
+	defer os.Remove(path)
+
+	args, replaced := []string{}, false
+	for _, arg := range r.args {
+		if arg == "{file}" {
+			arg, replaced = path, true
+		}
+		args = append(args, arg)
+	}
+	if !replaced {
+		args = append(args, path)
+	}
+	cmd := exec.Command(r.name, args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return Audio{}, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// The recorders finalize the file on SIGINT, they are killed if they don't stop
+		_ = cmd.Process.Signal(os.Interrupt)
+		select {
+		case err = <-done:
+		case <-time.After(3 * time.Second):
+			_ = cmd.Process.Kill()
+			err = <-done
+		}
+	}
+	if err != nil && ctx.Err() == nil {
+		return Audio{}, fmt.Errorf("%s failed: %w: %s", r.name, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Audio{}, err
+	}
+	if len(data) == 0 {
+		return Audio{}, errors.New("no audio recorded")
+	}
+	return Audio{Data: data, Format: r.format}, nil
+}
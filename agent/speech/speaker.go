@@ -0,0 +1,319 @@
+package speech
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+)
+
+// SentenceSplitter cuts a streamed text into sentences: Write returns the sentences completed by a chunk
+// and Flush the remaining text. The fenced code blocks are skipped, they are not meant to be read aloud.
+type SentenceSplitter struct {
+	// MinLength is the minimum length of a sentence, the shorter ones are merged with the next one
+	MinLength int
+	buffer    strings.Builder
+	inCode    bool
+}
+
+// Write adds a chunk of the text and returns the completed sentences
+func (s *SentenceSplitter) Write(chunk string) []string {
+	s.buffer.WriteString(chunk)
+	text := s.buffer.String()
+	sentences := []string{}
+	start := 0
+	for idx := 0; idx < len(text); idx++ {
+		// A fence toggles the code blocks, the code is dropped
+		if strings.HasPrefix(text[idx:], "```") && (idx == 0 || text[idx-1] == '\n') {
+			end := strings.IndexByte(text[idx:], '\n')
+			if end < 0 {
+				break
+			}
+			if !s.inCode {
+				sentences = s.appendSentence(sentences, text[start:idx])
+			}
+			s.inCode = !s.inCode
+			idx += end
+			start = idx + 1
+			continue
+		}
+		if s.inCode {
+			if text[idx] == '\n' {
+				start = idx + 1
+			}
+			continue
+		}
+		if !isSentenceEnd(text, idx) {
+			continue
+		}
+		sentence := text[start : idx+1]
+		if len(strings.TrimSpace(sentence)) < s.MinLength && text[idx] != '\n' {
+			continue
+		}
+		sentences = s.appendSentence(sentences, sentence)
+		start = idx + 1
+	}
+	s.buffer.Reset()
+	s.buffer.WriteString(text[start:])
+	return sentences
+}
+
+// Flush returns the remaining text (empty within a code block) and resets the splitter
+func (s *SentenceSplitter) Flush() []string {
+	text := s.buffer.String()
+	inCode := s.inCode
+	s.buffer.Reset()
+	s.inCode = false
+	if inCode {
+		return nil
+	}
+	return s.appendSentence(nil, text)
+}
+
+// appendSentence appends the spoken text of a sentence, if any
+func (s *SentenceSplitter) appendSentence(sentences []string, sentence string) []string {
+	if spoken := CleanMarkdown(sentence); spoken != "" {
+		return append(sentences, spoken)
+	}
+	return sentences
+}
+
+// isSentenceEnd reports whether the character at idx ends a sentence: a punctuation followed by a space,
+// or a line break (the items of the lists and the titles are sentences)
+func isSentenceEnd(text string, idx int) bool {
+	switch text[idx] {
+	case '\n':
+		return true
+	case '.', '!', '?', ':', ';':
+		return idx+1 < len(text) && unicode.IsSpace(rune(text[idx+1]))
+	}
+	return false
+}
+
+// markdownPatterns are the markdown syntaxes removed from the spoken text
+var markdownPatterns = []struct {
+	re          *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`), "$1"}, // links and images: their text
+	{regexp.MustCompile("`([^`]*)`"), "$1"},               // inline code
+	{regexp.MustCompile(`(?m)^\s{0,3}(#{1,6}|>|[-*+]|\d+[.)])\s+`), ""},
+	{regexp.MustCompile(`(\*\*|__|\*|~~)`), ""},
+	{regexp.MustCompile(`https?://\S+`), "link"},
+	{regexp.MustCompile(`\|`), " "},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+// CleanMarkdown returns the text of a markdown snippet to read aloud: without emphasis, titles, list markers,
+// link targets and URLs
+func CleanMarkdown(text string) string {
+	for _, pattern := range markdownPatterns {
+		text = pattern.re.ReplaceAllString(text, pattern.replacement)
+	}
+	text = strings.TrimSpace(text)
+	// A text without letter nor digit (e.g. a table separator) is not spoken
+	if strings.IndexFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return ""
+	}
+	return text
+}
+
+// SpeakerOption is a functional option for configuring Speaker instances
+type SpeakerOption func(*Speaker)
+
+// WithMinSentenceLength is a functional option that sets the minimum length of the spoken sentences (20 by default)
+func WithMinSentenceLength(length int) SpeakerOption {
+	return func(s *Speaker) {
+		s.splitter.MinLength = length
+	}
+}
+
+// WithLogger is a functional option that sets the logger of the synthesis and playback errors
+func WithLogger(logger logging.Logger) SpeakerOption {
+	return func(s *Speaker) {
+		s.logger = logger
+	}
+}
+
+// utterance is a sentence to speak, cancelled with the context of its answer
+type utterance struct {
+	ctx   context.Context
+	text  string
+	audio Audio
+}
+
+// Speaker speaks the streamed answers sentence by sentence: the next sentence is synthesized
+// while the current one is played
+type Speaker struct {
+	synthesizer Synthesizer
+	player      Player
+	splitter    SentenceSplitter
+	logger      logging.Logger
+
+	mutex   sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	pending sync.WaitGroup
+
+	// errMutex guards err apart from mutex, which is held while the sentences are queued
+	errMutex sync.Mutex
+	err      error
+
+	sentences chan utterance
+	clips     chan utterance
+	done      chan struct{}
+}
+
+// NewSpeaker creates a speaker, Close stops it
+//
+// Example usage:
+//
+//	player, err := speech.DefaultPlayer()
+//	speaker := speech.NewSpeaker(speech.NewOpenAISynthesizer(client), player)
+//	defer speaker.Close()
+//	answer, err := agent.RunStream(messages, speaker.StreamCallback(func(content string) error {
+//		fmt.Print(content)
+//		return nil
+//	}))
+//	speaker.Wait()
+func NewSpeaker(synthesizer Synthesizer, player Player, options ...SpeakerOption) *Speaker {
+	s := &Speaker{
+		synthesizer: synthesizer,
+		player:      player,
+		splitter:    SentenceSplitter{MinLength: 20},
+		sentences:   make(chan utterance, 256),
+		clips:       make(chan utterance, 2),
+		done:        make(chan struct{}),
+	}
+	for _, option := range options {
+		option(s)
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	go s.synthesize()
+	go s.play()
+	return s
+}
+
+// Write adds a chunk of a streamed answer, its completed sentences are spoken
+func (s *Speaker) Write(chunk string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enqueue(s.splitter.Write(chunk))
+}
+
+// Say speaks a complete text
+func (s *Speaker) Say(text string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enqueue(s.splitter.Write(text))
+	s.enqueue(s.splitter.Flush())
+}
+
+// Flush speaks the end of the current answer
+func (s *Speaker) Flush() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.enqueue(s.splitter.Flush())
+}
+
+// enqueue queues sentences with the context of the current answer (the mutex is held)
+func (s *Speaker) enqueue(sentences []string) {
+	for _, sentence := range sentences {
+		select {
+		case <-s.done:
+			return
+		default:
+		}
+		s.pending.Add(1)
+		s.sentences <- utterance{ctx: s.ctx, text: sentence}
+	}
+}
+
+// StreamCallback returns a stream callback speaking the content before calling next (optional)
+func (s *Speaker) StreamCallback(next func(content string) error) func(content string) error {
+	return func(content string) error {
+		s.Write(content)
+		if next != nil {
+			return next(content)
+		}
+		return nil
+	}
+}
+
+// Wait flushes the current answer and waits until it is spoken, it returns the last synthesis or playback error
+func (s *Speaker) Wait() error {
+	s.Flush()
+	s.pending.Wait()
+	s.errMutex.Lock()
+	defer s.errMutex.Unlock()
+	err := s.err
+	s.err = nil
+	return err
+}
+
+// Stop interrupts the speech: the current sentence is stopped and the queued ones are dropped
+func (s *Speaker) Stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cancel()
+	s.splitter.Flush()
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+// Close stops the speech and the speaker
+func (s *Speaker) Close() {
+	s.Stop()
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+		close(s.sentences)
+	}
+}
+
+// fail records an error of a sentence
+func (s *Speaker) fail(u utterance, err error) {
+	if u.ctx.Err() != nil {
+		return
+	}
+	logging.OrDefault(s.logger).Warn("speech failed", "sentence", u.text, "error", err)
+	s.errMutex.Lock()
+	s.err = err
+	s.errMutex.Unlock()
+}
+
+// synthesize converts the queued sentences to audio clips
+func (s *Speaker) synthesize() {
+	defer close(s.clips)
+	for u := range s.sentences {
+		if u.ctx.Err() != nil {
+			s.pending.Done()
+			continue
+		}
+		audio, err := s.synthesizer.Synthesize(u.ctx, u.text)
+		if err != nil {
+			s.fail(u, err)
+			s.pending.Done()
+			continue
+		}
+		u.audio = audio
+		s.clips <- u
+	}
+}
+
+// play plays the clips in order
+func (s *Speaker) play() {
+	for u := range s.clips {
+		if u.ctx.Err() == nil {
+			if err := s.player.Play(u.ctx, u.audio); err != nil {
+				s.fail(u, err)
+			}
+		}
+		s.pending.Done()
+	}
+}
@@ -0,0 +1,224 @@
+// Package speech gives a voice and ears to the agents: text-to-speech engines (the OpenAI audio/speech API and the local
+// command-line engines such as piper or espeak-ng), audio players, a speaker that speaks the streamed answers
+// sentence by sentence while they are generated, and the transcription of recorded or audio file prompts
+// with the whisper-compatible audio/transcriptions API.
+package speech
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Audio is a synthesized audio clip
+type Audio struct {
+	// Data is the encoded audio
+	Data []byte
+	// Format is the encoding of the data: wav, mp3, opus, flac, aac or pcm
+	Format string
+}
+
+// Synthesizer converts a text to speech
+type Synthesizer interface {
+	Synthesize(ctx context.Context, text string) (Audio, error)
+}
+
+// Player plays audio clips, Play returns when the clip is played or the context is cancelled
+type Player interface {
+	Play(ctx context.Context, audio Audio) error
+}
+
+// OpenAISynthesizer synthesizes speech with the audio/speech endpoint of the OpenAI API
+// (or of a compatible server such as Kokoro-FastAPI)
+type OpenAISynthesizer struct {
+	client       openai.Client
+	model        string
+	voice        string
+	format       string
+	instructions string
+	speed        float64
+}
+
+// OpenAIOption is a functional option for configuring OpenAISynthesizer instances
+type OpenAIOption func(*OpenAISynthesizer)
+
+// NewOpenAISynthesizer creates a synthesizer of the OpenAI API, by default with the gpt-4o-mini-tts model,
+// the alloy voice and the wav format (playable by all the players)
+//
+// Example usage:
+//
+//	synthesizer := speech.NewOpenAISynthesizer(client, speech.WithVoice("nova"))
+//	audio, err := synthesizer.Synthesize(ctx, "Hello, I am Bob.")
+func NewOpenAISynthesizer(client openai.Client, options ...OpenAIOption) *OpenAISynthesizer {
+	synthesizer := &OpenAISynthesizer{
+		client: client,
+		model:  openai.SpeechModelGPT4oMiniTTS,
+		voice:  "alloy",
+		format: "wav",
+	}
+	for _, option := range options {
+		option(synthesizer)
+	}
+	return synthesizer
+}
+
+// WithModel is a functional option that sets the speech model (e.g. tts-1)
+func WithModel(model string) OpenAIOption {
+	return func(s *OpenAISynthesizer) {
+		s.model = model
+	}
+}
+
+// WithVoice is a functional option that sets the voice (e.g. alloy, nova, shimmer)
+func WithVoice(voice string) OpenAIOption {
+	return func(s *OpenAISynthesizer) {
+		s.voice = voice
+	}
+}
+
+// WithFormat is a functional option that sets the audio format: wav, mp3, opus, flac, aac or pcm
+func WithFormat(format string) OpenAIOption {
+	return func(s *OpenAISynthesizer) {
+		s.format = format
+	}
+}
+
+// WithInstructions is a functional option that sets the instructions on the tone of the voice (gpt-4o-mini-tts)
+func WithInstructions(instructions string) OpenAIOption {
+	return func(s *OpenAISynthesizer) {
+		s.instructions = instructions
+	}
+}
+
+// WithSpeed is a functional option that sets the speed of the speech, from 0.25 to 4.0 (1.0 by default)
+func WithSpeed(speed float64) OpenAIOption {
+	return func(s *OpenAISynthesizer) {
+		s.speed = speed
+	}
+}
+
+// Synthesize implements the Synthesizer interface for OpenAISynthesizer
+func (s *OpenAISynthesizer) Synthesize(ctx context.Context, text string) (Audio, error) {
+	params := openai.AudioSpeechNewParams{
+		Model:          s.model,
+		Voice:          openai.AudioSpeechNewParamsVoice(s.voice),
+		Input:          text,
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormat(s.format),
+	}
+	if s.instructions != "" {
+		params.Instructions = openai.String(s.instructions)
+	}
+	if s.speed > 0 {
+		params.Speed = openai.Float(s.speed)
+	}
+	resp, err := s.client.Audio.Speech.New(ctx, params)
+	if err != nil {
+		return Audio{}, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Audio{}, err
+	}
+	return Audio{Data: data, Format: s.format}, nil
+}
+
+// CommandSynthesizer synthesizes speech with a local engine: the command reads the text on its standard input
+// and writes the audio on its standard output
+type CommandSynthesizer struct {
+	format string
+	name   string
+	args   []string
+}
+
+// NewCommandSynthesizer creates a synthesizer running a local engine producing audio in format
+//
+// Example usage:
+//
+//	espeak := speech.NewCommandSynthesizer("wav", "espeak-ng", "--stdout")
+//	piper := speech.NewCommandSynthesizer("wav", "piper", "--model", "en_US-lessac-medium.onnx", "--output_file", "-")
+func NewCommandSynthesizer(format, name string, args ...string) *CommandSynthesizer {
+	return &CommandSynthesizer{format: format, name: name, args: args}
+}
+
+// Synthesize implements the Synthesizer interface for CommandSynthesizer
+func (s *CommandSynthesizer) Synthesize(ctx context.Context, text string) (Audio, error) {
+	cmd := exec.CommandContext(ctx, s.name, s.args...)
+	cmd.Stdin = bytes.NewBufferString(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		return Audio{}, fmt.Errorf("%s failed: %w: %s", s.name, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	if stdout.Len() == 0 {
+		return Audio{}, fmt.Errorf("%s produced no audio", s.name)
+	}
+	return Audio{Data: stdout.Bytes(), Format: s.format}, nil
+}
+
+// CommandPlayer plays the audio clips with a command-line player, the path of the clip is its last argument
+type CommandPlayer struct {
+	name string
+	args []string
+}
+
+// NewCommandPlayer creates a player running a command-line player
+//
+// Example usage:
+//
+//	player := speech.NewCommandPlayer("ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet")
+func NewCommandPlayer(name string, args ...string) *CommandPlayer {
+	return &CommandPlayer{name: name, args: args}
+}
+
+// defaultPlayers are the command-line players looked for by DefaultPlayer, in order
+var defaultPlayers = [][]string{
+	{"afplay"},
+	{"ffplay", "-nodisp", "-autoexit", "-loglevel", "quiet"},
+	{"mpv", "--no-video", "--really-quiet"},
+	{"paplay"},
+	{"aplay", "-q"},
+}
+
+// DefaultPlayer returns the player of the first command-line player found in the PATH:
+// afplay (macOS), ffplay, mpv, paplay or aplay (the last two play only wav)
+func DefaultPlayer() (*CommandPlayer, error) {
+	for _, player := range defaultPlayers {
+		if _, err := exec.LookPath(player[0]); err == nil {
+			return NewCommandPlayer(player[0], player[1:]...), nil
+		}
+	}
+	return nil, errors.New("no audio player found (afplay, ffplay, mpv, paplay or aplay)")
+}
+
+// Play implements the Player interface for CommandPlayer
+func (p *CommandPlayer) Play(ctx context.Context, audio Audio) error {
+	file, err := os.CreateTemp("", "speech-*."+audio.Format)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(audio.Data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, p.name, append(append([]string{}, p.args...), file.Name())...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("%s failed: %w: %s", p.name, err, bytes.TrimSpace(stderr.Bytes()))
+	}
+	return nil
+}
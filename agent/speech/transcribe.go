@@ -0,0 +1,115 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Transcriber converts speech to text
+type Transcriber interface {
+	Transcribe(ctx context.Context, audio Audio) (string, error)
+}
+
+// ReadAudioFile reads an audio file, its format is its extension (e.g. wav, mp3, m4a)
+func ReadAudioFile(path string) (Audio, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Audio{}, err
+	}
+	return Audio{Data: data, Format: strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")}, nil
+}
+
+// OpenAITranscriber transcribes speech with the audio/transcriptions endpoint of the OpenAI API
+// (or of a whisper-compatible server such as faster-whisper-server or whisper.cpp)
+type OpenAITranscriber struct {
+	client      openai.Client
+	model       string
+	language    string
+	prompt      string
+	temperature *float64
+}
+
+// TranscriberOption is a functional option for configuring OpenAITranscriber instances
+type TranscriberOption func(*OpenAITranscriber)
+
+// NewOpenAITranscriber creates a transcriber of the OpenAI API, by default with the whisper-1 model
+//
+// Example usage:
+//
+//	transcriber := speech.NewOpenAITranscriber(client, speech.WithLanguage("en"))
+//	audio, err := speech.ReadAudioFile("question.m4a")
+//	text, err := transcriber.Transcribe(ctx, audio)
+func NewOpenAITranscriber(client openai.Client, options ...TranscriberOption) *OpenAITranscriber {
+	transcriber := &OpenAITranscriber{
+		client: client,
+		model:  openai.AudioModelWhisper1,
+	}
+	for _, option := range options {
+		option(transcriber)
+	}
+	return transcriber
+}
+
+// WithTranscriptionModel is a functional option that sets the transcription model (e.g. gpt-4o-mini-transcribe)
+func WithTranscriptionModel(model string) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		t.model = model
+	}
+}
+
+// WithLanguage is a functional option that sets the language of the speech (ISO-639-1, e.g. en), detected if empty
+func WithLanguage(language string) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		t.language = language
+	}
+}
+
+// WithPrompt is a functional option that sets a text guiding the transcription (e.g. the spelling of names)
+func WithPrompt(prompt string) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		t.prompt = prompt
+	}
+}
+
+// WithTemperature is a functional option that sets the sampling temperature of the transcription
+func WithTemperature(temperature float64) TranscriberOption {
+	return func(t *OpenAITranscriber) {
+		t.temperature = &temperature
+	}
+}
+
+// Transcribe implements the Transcriber interface for OpenAITranscriber
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, audio Audio) (string, error) {
+	if len(audio.Data) == 0 {
+		return "", fmt.Errorf("no audio to transcribe")
+	}
+	format := audio.Format
+	if format == "" {
+		format = "wav"
+	}
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.File(bytes.NewReader(audio.Data), "audio."+format, mime.TypeByExtension("."+format)),
+		Model: t.model,
+	}
+	if t.language != "" {
+		params.Language = openai.String(t.language)
+	}
+	if t.prompt != "" {
+		params.Prompt = openai.String(t.prompt)
+	}
+	if t.temperature != nil {
+		params.Temperature = openai.Float(*t.temperature)
+	}
+	transcription, err := t.client.Audio.Transcriptions.New(ctx, params)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(transcription.Text), nil
+}
@@ -0,0 +1,332 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// ToolExecuteCode is the name of the code execution tool
+const ToolExecuteCode = "execute_code"
+
+// CodeLanguage is a language of the code execution tool: the program is written to File in the container,
+// then Command is run with the path of the file as last argument
+type CodeLanguage struct {
+	Image   string
+	File    string
+	Command []string
+}
+
+// DefaultCodeLanguages are the languages of the code execution tool
+var DefaultCodeLanguages = map[string]CodeLanguage{
+	"python":     {Image: "python:3.12-slim", File: "main.py", Command: []string{"python"}},
+	"go":         {Image: "golang:1.24-alpine", File: "main.go", Command: []string{"go", "run"}},
+	"javascript": {Image: "node:22-alpine", File: "main.js", Command: []string{"node"}},
+}
+
+// CodeResult is the result of the code execution tool
+type CodeResult struct {
+	ExitCode  int    `json:"exit_code"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr"`
+	Truncated bool   `json:"truncated,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+	Duration  string `json:"duration"`
+}
+
+// CodeTool lets the tool-calling agents run the programs they write (data analysis, computations...):
+// each program runs in a short-lived container with CPU, memory and time limits, without network by default
+type CodeTool struct {
+	runtime   string
+	languages map[string]CodeLanguage
+	cpus      float64
+	memory    string
+	pids      int
+	timeout   time.Duration
+	network   bool
+	workDir   string
+	maxOutput int
+}
+
+// CodeToolOption is a functional option for configuring CodeTool instances
+type CodeToolOption func(*CodeTool)
+
+// NewCodeTool creates the code execution tool, the containers are run with Docker (see WithCodeRuntime).
+// The images of the languages are pulled by the first executions: pull them beforehand so the first
+// programs don't time out.
+//
+// Example usage:
+//
+//	codeTool := tools.NewCodeTool(tools.WithCodeTimeout(time.Minute), tools.WithCodeWorkDir("data"))
+//	params.Tools = append(params.Tools, codeTool.OpenAITools()...)
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, codeTool.ToolCallback(mcpCallback))
+func NewCodeTool(options ...CodeToolOption) *CodeTool {
+	tool := &CodeTool{
+		runtime:   "docker",
+		languages: map[string]CodeLanguage{},
+		cpus:      1,
+		memory:    "512m",
+		pids:      128,
+		timeout:   30 * time.Second,
+		maxOutput: 16 * 1024,
+	}
+	for name, language := range DefaultCodeLanguages {
+		tool.languages[name] = language
+	}
+	for _, option := range options {
+		option(tool)
+	}
+	return tool
+}
+
+// WithCodeRuntime is a functional option that sets the container command ("docker" by default, or "podman")
+func WithCodeRuntime(runtime string) CodeToolOption {
+	return func(t *CodeTool) {
+		t.runtime = runtime
+	}
+}
+
+// WithCodeLanguage is a functional option that adds or replaces a language (e.g. a Python image with pandas)
+func WithCodeLanguage(name string, language CodeLanguage) CodeToolOption {
+	return func(t *CodeTool) {
+		t.languages[name] = language
+	}
+}
+
+// WithCodeLimits is a functional option that sets the CPUs and the memory of a container
+// (1 CPU and "512m" by default, no limit if zero or empty)
+func WithCodeLimits(cpus float64, memory string) CodeToolOption {
+	return func(t *CodeTool) {
+		t.cpus = cpus
+		t.memory = memory
+	}
+}
+
+// WithCodeTimeout is a functional option that sets the maximum duration of a program (30 seconds by default),
+// the container is killed after it
+func WithCodeTimeout(timeout time.Duration) CodeToolOption {
+	return func(t *CodeTool) {
+		t.timeout = timeout
+	}
+}
+
+// WithCodeNetwork is a functional option that gives the network to the containers (no network by default)
+func WithCodeNetwork(enabled bool) CodeToolOption {
+	return func(t *CodeTool) {
+		t.network = enabled
+	}
+}
+
+// WithCodeWorkDir is a functional option that mounts a directory of the host in the containers at /workspace,
+// the working directory of the programs: they can read its data files and write their outputs to it
+func WithCodeWorkDir(dir string) CodeToolOption {
+	return func(t *CodeTool) {
+		t.workDir = dir
+	}
+}
+
+// WithCodeMaxOutput is a functional option that sets the maximum size in bytes of the standard output
+// and of the standard error returned to the model (16KB by default)
+func WithCodeMaxOutput(maxOutput int) CodeToolOption {
+	return func(t *CodeTool) {
+		t.maxOutput = maxOutput
+	}
+}
+
+// Languages returns the names of the languages, sorted
+func (t *CodeTool) Languages() []string {
+	names := make([]string, 0, len(t.languages))
+	for name := range t.languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenAITools returns the definition of the code execution tool
+func (t *CodeTool) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	description := "Execute a program in an isolated container and return its exit code, standard output and standard error. " +
+		"Print the results you need."
+	if t.network {
+		description += " The program has network access."
+	} else {
+		description += " The program has no network access: only the standard library and the installed packages are available."
+	}
+	if t.workDir != "" {
+		description += " The working directory /workspace contains the data files, the files written to it are kept."
+	}
+	return []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        ToolExecuteCode,
+			Description: openai.String(description),
+			Parameters: shared.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"language": map[string]any{"type": "string", "enum": t.Languages(), "description": "language of the program"},
+					"code":     map[string]any{"type": "string", "description": "complete source code of the program"},
+				},
+				"required": []string{"language", "code"},
+			},
+		}),
+	}
+}
+
+// IsTool returns true if the function is the code execution tool
+func (t *CodeTool) IsTool(functionName string) bool {
+	return functionName == ToolExecuteCode
+}
+
+// CallTool runs a program, it returns the JSON CodeResult
+func (t *CodeTool) CallTool(arguments string) (string, error) {
+	var args struct {
+		Language string `json:"language"`
+		Code     string `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	result, err := t.Execute(context.Background(), strings.ToLower(args.Language), args.Code)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(result)
+	return string(data), err
+}
+
+// Execute runs a program in a new container. A program which fails or times out is not an error:
+// its exit code and outputs are in the result.
+func (t *CodeTool) Execute(ctx context.Context, language string, code string) (CodeResult, error) {
+	spec, ok := t.languages[language]
+	if !ok {
+		return CodeResult{}, fmt.Errorf("unsupported language %q (supported: %s)", language, strings.Join(t.Languages(), ", "))
+	}
+	if strings.TrimSpace(code) == "" {
+		return CodeResult{}, errors.New("the code is empty")
+	}
+
+	name := "execute-code-" + uuid.New().String()[:8]
+	containerArgs, err := t.runArgs(name, spec)
+	if err != nil {
+		return CodeResult{}, err
+	}
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, t.runtime, containerArgs...)
+	// The program is given on the standard input, no file of the host is needed
+	cmd.Stdin = strings.NewReader(code)
+	stdout := helpers.NewCappedBuffer(t.maxOutput)
+	stderr := helpers.NewCappedBuffer(t.maxOutput)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = 5 * time.Second
+
+	start := time.Now()
+	err = cmd.Run()
+	result := CodeResult{
+		Stdout:    stdout.String(),
+		Stderr:    stderr.String(),
+		Truncated: stdout.Truncated() || stderr.Truncated(),
+		Duration:  time.Since(start).Round(time.Millisecond).String(),
+	}
+	if ctx.Err() != nil {
+		// Killing the client doesn't stop the container
+		t.remove(name)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.TimedOut = true
+			result.ExitCode = -1
+			return result, nil
+		}
+		return result, ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	if err != nil {
+		return CodeResult{}, fmt.Errorf("unable to run the container with %s: %w", t.runtime, err)
+	}
+	return result, nil
+}
+
+// runArgs returns the arguments of the run command of a container
+func (t *CodeTool) runArgs(name string, spec CodeLanguage) ([]string, error) {
+	args := []string{"run", "--rm", "-i", "--name", name, "--label", "micro-agent.tool=" + ToolExecuteCode}
+	if !t.network {
+		args = append(args, "--network", "none")
+	}
+	if t.cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(t.cpus, 'f', -1, 64))
+	}
+	if t.memory != "" {
+		// Without swap the memory limit is a hard limit
+		args = append(args, "--memory", t.memory, "--memory-swap", t.memory)
+	}
+	if t.pids > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(t.pids))
+	}
+	if t.workDir != "" {
+		workDir, err := filepath.Abs(t.workDir)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, "-v", workDir+":/workspace", "-w", "/workspace")
+	} else {
+		args = append(args, "-w", "/tmp")
+	}
+	// The shell writes the program to its file, then replaces itself with the command
+	file := shellQuote("/tmp/code/" + spec.File)
+	command := make([]string, 0, len(spec.Command)+1)
+	for _, word := range spec.Command {
+		command = append(command, shellQuote(word))
+	}
+	command = append(command, file)
+	script := fmt.Sprintf("mkdir -p /tmp/code && cat > %s && exec %s", file, strings.Join(command, " "))
+	return append(args, spec.Image, "sh", "-c", script), nil
+}
+
+// remove kills and removes a container
+func (t *CodeTool) remove(name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	exec.CommandContext(ctx, t.runtime, "rm", "-f", name).Run()
+}
+
+// shellQuote quotes a word for sh
+func shellQuote(word string) string {
+	if word != "" && strings.IndexFunc(word, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./=:", r))
+	}) < 0 {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls executing the code execution tool,
+// the other tools are executed by next (an error if nil)
+func (t *CodeTool) ToolCallback(next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if t.IsTool(functionName) {
+			return t.CallTool(arguments)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
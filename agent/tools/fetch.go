@@ -0,0 +1,304 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// ToolFetchURL is the name of the web fetch tool
+const ToolFetchURL = "fetch_url"
+
+// ErrDisallowedByRobots is returned when the robots.txt file of a site disallows a page
+var ErrDisallowedByRobots = errors.New("the page is disallowed by the robots.txt file of the site")
+
+// WebPage is a page downloaded by the web fetch tool
+type WebPage struct {
+	// URL is the final URL of the page, after the redirections
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	ContentType string `json:"content_type"`
+	// Content is the markdown of an HTML page, or the text of the other pages
+	Content string `json:"content"`
+	// Truncated is true when the content was cut to the token budget or the page to the size cap
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// FetchTool lets the agents ground their answers in live web content: it downloads a page, converts
+// the HTML to markdown and truncates it to a token budget. The robots.txt files are respected and,
+// by default, the private network addresses are refused so the model can't reach the internal services.
+type FetchTool struct {
+	client          *http.Client
+	timeout         time.Duration
+	maxTokens       int
+	maxBytes        int64
+	userAgent       string
+	robots          bool
+	privateNetworks bool
+	robotsCache     *robotsCache
+}
+
+// FetchToolOption is a functional option for configuring FetchTool instances
+type FetchToolOption func(*FetchTool)
+
+// NewFetchTool creates the web fetch tool
+//
+// Example usage:
+//
+//	fetchTool := tools.NewFetchTool(tools.WithFetchMaxTokens(2000))
+//	params.Tools = append(params.Tools, fetchTool.OpenAITools()...)
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, fetchTool.ToolCallback(mcpCallback))
+func NewFetchTool(options ...FetchToolOption) *FetchTool {
+	tool := &FetchTool{
+		timeout:     20 * time.Second,
+		maxTokens:   4000,
+		maxBytes:    2 << 20,
+		userAgent:   "micro-agent-go (+https://github.com/micro-agent/micro-agent-go)",
+		robots:      true,
+		robotsCache: &robotsCache{rules: map[string]robotsRules{}, times: map[string]time.Time{}},
+	}
+	for _, option := range options {
+		option(tool)
+	}
+	if tool.client == nil {
+		tool.client = tool.defaultClient()
+	}
+	return tool
+}
+
+// WithFetchTimeout is a functional option that sets the maximum duration of a download (20 seconds by default)
+func WithFetchTimeout(timeout time.Duration) FetchToolOption {
+	return func(t *FetchTool) {
+		t.timeout = timeout
+	}
+}
+
+// WithFetchMaxTokens is a functional option that sets the token budget of the content (4000 by default,
+// estimated at 4 characters per token, no limit if zero)
+func WithFetchMaxTokens(maxTokens int) FetchToolOption {
+	return func(t *FetchTool) {
+		t.maxTokens = maxTokens
+	}
+}
+
+// WithFetchMaxBytes is a functional option that sets the maximum size of a downloaded page (2MB by default)
+func WithFetchMaxBytes(maxBytes int64) FetchToolOption {
+	return func(t *FetchTool) {
+		t.maxBytes = maxBytes
+	}
+}
+
+// WithFetchUserAgent is a functional option that sets the User-Agent of the requests, also used to
+// select the rules of the robots.txt files
+func WithFetchUserAgent(userAgent string) FetchToolOption {
+	return func(t *FetchTool) {
+		t.userAgent = userAgent
+	}
+}
+
+// WithFetchRobots is a functional option that enables or disables the robots.txt checks (enabled by default)
+func WithFetchRobots(enabled bool) FetchToolOption {
+	return func(t *FetchTool) {
+		t.robots = enabled
+	}
+}
+
+// WithFetchPrivateNetworks is a functional option that allows the loopback, private and link-local
+// addresses (refused by default)
+func WithFetchPrivateNetworks(allowed bool) FetchToolOption {
+	return func(t *FetchTool) {
+		t.privateNetworks = allowed
+	}
+}
+
+// WithFetchClient is a functional option that sets the HTTP client of the downloads (e.g. with a proxy),
+// the private networks are then not checked by the tool
+func WithFetchClient(client *http.Client) FetchToolOption {
+	return func(t *FetchTool) {
+		t.client = client
+	}
+}
+
+// defaultClient returns a client refusing the private addresses unless they are allowed, checked
+// on each connection so a redirection or a DNS answer can't bypass the check
+func (t *FetchTool) defaultClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !t.privateNetworks {
+		dialer.Control = func(network, address string, conn syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+				return fmt.Errorf("the address %s is in a private network", host)
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return errors.New("too many redirections")
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("redirection to an unsupported scheme %s", req.URL.Scheme)
+			}
+			return nil
+		},
+	}
+}
+
+// privateIP reports if an address is not a public address
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsUnspecified() || ip.IsMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// FetchURL downloads a page with a new web fetch tool
+//
+// Example usage:
+//
+//	page, err := tools.FetchURL(ctx, "https://go.dev/doc/effective_go", tools.WithFetchMaxTokens(8000))
+//	messages = append(messages, openai.UserMessage("Answer with this page:\n"+page.Content))
+func FetchURL(ctx context.Context, pageURL string, options ...FetchToolOption) (WebPage, error) {
+	return NewFetchTool(options...).Fetch(ctx, pageURL)
+}
+
+// Fetch downloads a page: the HTML pages are converted to markdown, the text, JSON and XML pages are kept
+// as is, the other types are refused. The content is truncated to the token budget.
+func (t *FetchTool) Fetch(ctx context.Context, pageURL string) (WebPage, error) {
+	parsed, err := url.Parse(strings.TrimSpace(pageURL))
+	if err != nil {
+		return WebPage{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return WebPage{}, fmt.Errorf("unsupported URL scheme %q (http or https)", parsed.Scheme)
+	}
+	if parsed.Host == "" {
+		return WebPage{}, fmt.Errorf("invalid URL %q: no host", pageURL)
+	}
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	if t.robots {
+		allowed, err := t.allowedByRobots(ctx, parsed)
+		if err != nil {
+			return WebPage{}, err
+		}
+		if !allowed {
+			return WebPage{}, fmt.Errorf("%s: %w", parsed, ErrDisallowedByRobots)
+		}
+	}
+
+	document, err := helpers.FetchURLAsText(ctx, parsed.String(),
+		helpers.WithFetchClient(t.client),
+		helpers.WithFetchTimeout(0), // the timeout of the tool also covers the robots.txt file
+		helpers.WithFetchMaxBytes(t.maxBytes),
+		helpers.WithFetchUserAgent(t.userAgent),
+	)
+	if err != nil {
+		return WebPage{}, err
+	}
+	page := WebPage{URL: document.URL, Title: document.Title, ContentType: document.ContentType}
+	page.Content, page.Truncated = truncateToTokens(document.Content, t.maxTokens)
+	page.Truncated = page.Truncated || document.Truncated
+	return page, nil
+}
+
+// truncateToTokens cuts a content to a token budget (4 characters per token), at the end of a paragraph
+// or of a line when possible
+func truncateToTokens(content string, maxTokens int) (string, bool) {
+	limit := maxTokens * 4
+	if maxTokens <= 0 || len(content) <= limit {
+		return content, false
+	}
+	cut := content[:limit]
+	// Not in the middle of a UTF-8 character
+	for len(cut) > 0 && !utf8Start(content[len(cut)]) {
+		cut = cut[:len(cut)-1]
+	}
+	for _, separator := range []string{"\n\n", "\n"} {
+		if index := strings.LastIndex(cut, separator); index > limit/2 {
+			cut = cut[:index]
+			break
+		}
+	}
+	return strings.TrimSpace(cut) + "\n\n[...truncated]", true
+}
+
+// utf8Start reports if a byte starts a UTF-8 character
+func utf8Start(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// OpenAITools returns the definition of the web fetch tool
+func (t *FetchTool) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	return []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name: ToolFetchURL,
+			Description: openai.String("Download a web page and return its title and its content as markdown. " +
+				"Use it to read the current content of a page before answering."),
+			Parameters: shared.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"url": map[string]any{"type": "string", "description": "absolute http or https URL of the page"},
+				},
+				"required": []string{"url"},
+			},
+		}),
+	}
+}
+
+// IsTool returns true if the function is the web fetch tool
+func (t *FetchTool) IsTool(functionName string) bool {
+	return functionName == ToolFetchURL
+}
+
+// CallTool downloads a page, it returns the JSON WebPage
+func (t *FetchTool) CallTool(arguments string) (string, error) {
+	var args struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	page, err := t.Fetch(context.Background(), args.URL)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(page)
+	return string(data), err
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls executing the web fetch tool,
+// the other tools are executed by next (an error if nil)
+func (t *FetchTool) ToolCallback(next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if t.IsTool(functionName) {
+			return t.CallTool(arguments)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
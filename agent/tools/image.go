@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// ToolGenerateImage is the name of the image generation tool
+const ToolGenerateImage = "generate_image"
+
+// ImageTool lets the tool-calling agents generate images: the images are saved to a directory
+// and the tool result gives their path
+type ImageTool struct {
+	generator mu.ImageGenerator
+	outputDir string
+	sizes     []string
+	onImage   func(path string, image mu.Image)
+}
+
+// ImageToolOption is a functional option for configuring ImageTool instances
+type ImageToolOption func(*ImageTool)
+
+// NewImageTool creates the image generation tool of a generator (e.g. an agent with WithImageParams)
+//
+// Example usage:
+//
+//	imageTool := tools.NewImageTool(imageAgent.(mu.ImageGenerator), tools.WithImageOutputDir("images"))
+//	params.Tools = append(params.Tools, imageTool.OpenAITools()...)
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, imageTool.ToolCallback(mcpCallback))
+func NewImageTool(generator mu.ImageGenerator, options ...ImageToolOption) *ImageTool {
+	tool := &ImageTool{
+		generator: generator,
+		outputDir: ".",
+		sizes:     []string{"1024x1024", "1536x1024", "1024x1536"},
+	}
+	for _, option := range options {
+		option(tool)
+	}
+	return tool
+}
+
+// WithImageOutputDir is a functional option that sets the directory of the generated images (the current directory by default)
+func WithImageOutputDir(dir string) ImageToolOption {
+	return func(t *ImageTool) {
+		t.outputDir = dir
+	}
+}
+
+// WithImageSizes is a functional option that sets the sizes the model can choose (those of gpt-image-1 by default)
+func WithImageSizes(sizes ...string) ImageToolOption {
+	return func(t *ImageTool) {
+		t.sizes = sizes
+	}
+}
+
+// WithOnImage is a functional option that sets a callback called with each saved image (e.g. to display it)
+func WithOnImage(onImage func(path string, image mu.Image)) ImageToolOption {
+	return func(t *ImageTool) {
+		t.onImage = onImage
+	}
+}
+
+// OpenAITools returns the definition of the image generation tool
+func (t *ImageTool) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	return []openai.ChatCompletionToolUnionParam{
+		openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        ToolGenerateImage,
+			Description: openai.String("Generate an image from a detailed description and save it to a file"),
+			Parameters: shared.FunctionParameters{
+				"type": "object",
+				"properties": map[string]any{
+					"prompt": map[string]any{"type": "string", "description": "detailed description of the image"},
+					"size":   map[string]any{"type": "string", "enum": t.sizes, "description": "size of the image"},
+					"name":   map[string]any{"type": "string", "description": "short file name of the image, without extension"},
+				},
+				"required": []string{"prompt"},
+			},
+		}),
+	}
+}
+
+// IsTool returns true if the function is the image generation tool
+func (t *ImageTool) IsTool(functionName string) bool {
+	return functionName == ToolGenerateImage
+}
+
+// unsafeFileName matches the characters replaced in the file names given by the model
+var unsafeFileName = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// CallTool generates and saves an image, it returns the JSON result of the tool
+func (t *ImageTool) CallTool(arguments string) (string, error) {
+	var args struct {
+		Prompt string `json:"prompt"`
+		Size   string `json:"size"`
+		Name   string `json:"name"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if strings.TrimSpace(args.Prompt) == "" {
+		return "", fmt.Errorf("the prompt of the image is required")
+	}
+	options := []mu.ImageOption{}
+	if args.Size != "" {
+		options = append(options, mu.WithImageSize(args.Size))
+	}
+	image, err := t.generator.GenerateImage(args.Prompt, options...)
+	if err != nil {
+		return "", err
+	}
+
+	name := strings.Trim(unsafeFileName.ReplaceAllString(args.Name, "-"), "-")
+	if name == "" {
+		name = "image-" + time.Now().Format("20060102-150405")
+	}
+	path := filepath.Join(t.outputDir, name+"."+image.Format)
+	if err := image.Save(path); err != nil {
+		return "", fmt.Errorf("failed to save the image: %w", err)
+	}
+	if t.onImage != nil {
+		t.onImage(path, image)
+	}
+	result := map[string]any{"path": path}
+	if image.RevisedPrompt != "" {
+		result["revised_prompt"] = image.RevisedPrompt
+	}
+	data, err := json.Marshal(result)
+	return string(data), err
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls executing the image generation tool,
+// the other tools are executed by next (an error if nil)
+func (t *ImageTool) ToolCallback(next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if t.IsTool(functionName) {
+			return t.CallTool(arguments)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
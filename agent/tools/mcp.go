@@ -3,10 +3,12 @@ package tools
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/client"
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/logging"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/shared"
 	//"github.com/openai/openai-go/v2/shared/constant"
@@ -16,10 +18,31 @@ import (
 type MCPClient struct {
 	mcpclient   *client.Client
 	ToolsResult *mcp.ListToolsResult
+	logger      logging.Logger
+
+	// The SSE connections are reopened when they are lost (see NewSSEMCPClient)
+	mutex     sync.Mutex
+	lost      chan struct{}
+	closed    bool
+	reconnect func(ctx context.Context) error
+}
+
+// MCPClientOption is a functional option for configuring MCPClient instances
+type MCPClientOption func(*MCPClient)
+
+// WithLogger sets the logger of the client (logging.Default() otherwise), the tool calls are logged at the debug level
+func WithLogger(logger logging.Logger) MCPClientOption {
+	return func(c *MCPClient) {
+		c.logger = logger
+	}
 }
 
 // NewStreamableHttpMCPClient creates and initializes a new MCP client over HTTP
-func NewStreamableHttpMCPClient(ctx context.Context, mcpHostURL string) (*MCPClient, error) {
+func NewStreamableHttpMCPClient(ctx context.Context, mcpHostURL string, options ...MCPClientOption) (*MCPClient, error) {
+	c := &MCPClient{}
+	for _, option := range options {
+		option(c)
+	}
 	mcpClient, err := client.NewStreamableHttpClient(
 		mcpHostURL, // Use environment variable for MCP host
 	)
@@ -32,30 +55,43 @@ func NewStreamableHttpMCPClient(ctx context.Context, mcpHostURL string) (*MCPCli
 	if err != nil {
 		return nil, err
 	}
+	return c.initialize(ctx, mcpClient, mcpHostURL)
+}
 
-	initRequest := mcp.InitializeRequest{}
-	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
-	initRequest.Params.ClientInfo = mcp.Implementation{
-		Name:    "micro agent",
-		Version: "0.0.0",
-	}
-	_, err = mcpClient.Initialize(ctx, initRequest)
+// initialize initializes the MCP session of a started client and lists the tools of the server
+func (c *MCPClient) initialize(ctx context.Context, mcpClient *client.Client, server string) (*MCPClient, error) {
+	_, err := mcpClient.Initialize(ctx, initializeRequest())
 	if err != nil {
+		mcpClient.Close()
 		return nil, err
 	}
-	//fmt.Println("Streamable HTTP client connected & initialized with server!", result)
-	//ui.Println(ui.Yellow, "Streamable HTTP client connected & initialized with server!")
 
 	toolsRequest := mcp.ListToolsRequest{}
 	mcpTools, err := mcpClient.ListTools(ctx, toolsRequest)
 	if err != nil {
+		mcpClient.Close()
 		return nil, err
 	}
+	c.mcpclient = mcpClient
+	c.ToolsResult = mcpTools
+	c.log().Debug("MCP client connected", "server", server, "tools", len(mcpTools.Tools))
+	return c, nil
+}
+
+// initializeRequest returns the request initializing an MCP session
+func initializeRequest() mcp.InitializeRequest {
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{
+		Name:    "micro agent",
+		Version: "0.0.0",
+	}
+	return initRequest
+}
 
-	return &MCPClient{
-		mcpclient:   mcpClient,
-		ToolsResult: mcpTools,
-	}, nil
+// log returns the logger of the client
+func (c *MCPClient) log() logging.Logger {
+	return logging.OrDefault(c.logger)
 }
 
 // OpenAITools converts the MCP client's tools to OpenAI-compatible format
@@ -70,6 +106,9 @@ func (c *MCPClient) OpenAIToolsWithFilter(toolsFilter []string) []openai.ChatCom
 
 // Close safely closes the MCP client connection
 func (c *MCPClient) Close() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.closed = true
 	if c.mcpclient != nil {
 		return c.mcpclient.Close()
 	}
@@ -90,8 +129,10 @@ func (c *MCPClient) CallTool(ctx context.Context, functionName string, arguments
 	request.Params.Arguments = args
 
 	// NOTE: Call the tool using the MCP client
-	toolResponse, err := c.mcpclient.CallTool(ctx, request)
+	c.log().Debug("MCP tool call", "function", functionName, "arguments", logging.RedactJSON([]byte(arguments), 500))
+	toolResponse, err := c.callTool(ctx, request)
 	if err != nil {
+		c.log().Debug("MCP tool call failed", "function", functionName, "error", err)
 		return nil, fmt.Errorf("error calling tool %s: %w", functionName, err)
 	}
 	if toolResponse == nil || len(toolResponse.Content) == 0 {
@@ -146,3 +187,19 @@ func ConvertMCPToolsToOpenAIToolsWithFilter(tools *mcp.ListToolsResult, toolsFil
 	}
 	return openAITools
 }
+
+// transportLogger logs the messages of the stdio and SSE transports at the debug level (e.g. the end of
+// the output of the process, or of the stream, when the client is closed)
+type transportLogger struct {
+	logger logging.Logger
+}
+
+// Infof logs an information of the transport
+func (l transportLogger) Infof(format string, v ...any) {
+	l.logger.Debug("MCP transport", "message", fmt.Sprintf(format, v...))
+}
+
+// Errorf logs an error of the transport
+func (l transportLogger) Errorf(format string, v ...any) {
+	l.logger.Debug("MCP transport error", "error", fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/openai/openai-go/v2"
+)
+
+// MCPRouter gathers the tools of several MCP servers behind a single tool list: a tool call is routed to
+// the server of the tool. The tools of a server can be prefixed to avoid the collisions between servers
+// (e.g. "github_" gives "github_search" for the "search" tool); without prefix, the first server added
+// keeps a name used by several servers.
+//
+// Example usage:
+//
+//	router := tools.NewMCPRouter()
+//	defer router.Close()
+//	err := router.Connect(ctx, "files", "http://localhost:9011", "")
+//	err = router.Connect(ctx, "github", "http://localhost:9012", "github_")
+//	agent.SetTools(router.OpenAITools())
+//	result, err := router.CallTool(ctx, "github_search", `{"query": "micro-agent"}`)
+type MCPRouter struct {
+	servers []mcpRoutedServer
+	routes  map[string]mcpRoute
+	logger  logging.Logger
+}
+
+// mcpRoutedServer is a server of the router
+type mcpRoutedServer struct {
+	name   string
+	prefix string
+	client *MCPClient
+}
+
+// mcpRoute is the server, and the name on the server, of a tool of the router
+type mcpRoute struct {
+	server string
+	client *MCPClient
+	tool   mcp.Tool
+}
+
+// MCPRouterOption is a functional option for configuring MCPRouter instances
+type MCPRouterOption func(*MCPRouter)
+
+// WithRouterLogger sets the logger of the router (logging.Default() otherwise)
+func WithRouterLogger(logger logging.Logger) MCPRouterOption {
+	return func(r *MCPRouter) {
+		r.logger = logger
+	}
+}
+
+// NewMCPRouter creates a router without server
+func NewMCPRouter(options ...MCPRouterOption) *MCPRouter {
+	r := &MCPRouter{routes: map[string]mcpRoute{}}
+	for _, option := range options {
+		option(r)
+	}
+	return r
+}
+
+// log returns the logger of the router
+func (r *MCPRouter) log() logging.Logger {
+	return logging.OrDefault(r.logger)
+}
+
+// Connect connects to the MCP server at mcpHostURL (over streamable HTTP) and adds it to the router
+func (r *MCPRouter) Connect(ctx context.Context, name string, mcpHostURL string, prefix string) error {
+	client, err := NewStreamableHttpMCPClient(ctx, mcpHostURL, WithLogger(r.logger))
+	if err != nil {
+		return fmt.Errorf("MCP server %s: %w", name, err)
+	}
+	if err := r.Add(name, client, prefix); err != nil {
+		client.Close()
+		return err
+	}
+	return nil
+}
+
+// Add adds the tools of a connected client under the name of its server, prefixed with prefix; the client
+// is closed by Close
+func (r *MCPRouter) Add(name string, client *MCPClient, prefix string) error {
+	for _, server := range r.servers {
+		if server.name == name {
+			return fmt.Errorf("MCP server %s already added", name)
+		}
+	}
+	r.servers = append(r.servers, mcpRoutedServer{name: name, prefix: prefix, client: client})
+	if client.ToolsResult == nil {
+		return nil
+	}
+	for _, tool := range client.ToolsResult.Tools {
+		routedName := prefix + tool.Name
+		if existing, exists := r.routes[routedName]; exists {
+			r.log().Warn("MCP tool ignored, its name is used by another server", "tool", routedName, "server", name, "kept", existing.server)
+			continue
+		}
+		r.routes[routedName] = mcpRoute{server: name, client: client, tool: tool}
+	}
+	r.log().Debug("MCP server added to the router", "server", name, "tools", len(client.ToolsResult.Tools))
+	return nil
+}
+
+// Servers returns the names of the servers, in the order they were added
+func (r *MCPRouter) Servers() []string {
+	names := []string{}
+	for _, server := range r.servers {
+		names = append(names, server.name)
+	}
+	return names
+}
+
+// ToolServer returns the server of a tool of the router
+func (r *MCPRouter) ToolServer(functionName string) (string, bool) {
+	route, ok := r.routes[functionName]
+	return route.server, ok
+}
+
+// ToolsResult returns the tools of all the servers, with their names in the router
+func (r *MCPRouter) ToolsResult() *mcp.ListToolsResult {
+	result := &mcp.ListToolsResult{Tools: []mcp.Tool{}}
+	for _, server := range r.servers {
+		if server.client.ToolsResult == nil {
+			continue
+		}
+		for _, tool := range server.client.ToolsResult.Tools {
+			routedName := server.prefix + tool.Name
+			// The tools ignored because of a collision aren't listed
+			if route := r.routes[routedName]; route.server == server.name {
+				tool.Name = routedName
+				result.Tools = append(result.Tools, tool)
+			}
+		}
+	}
+	return result
+}
+
+// OpenAITools converts the tools of all the servers to OpenAI-compatible format
+func (r *MCPRouter) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	return ConvertMCPToolsToOpenAITools(r.ToolsResult())
+}
+
+// OpenAIToolsWithFilter converts only the filtered tools (by their names in the router) to OpenAI-compatible format
+func (r *MCPRouter) OpenAIToolsWithFilter(toolsFilter []string) []openai.ChatCompletionToolUnionParam {
+	return ConvertMCPToolsToOpenAIToolsWithFilter(r.ToolsResult(), toolsFilter)
+}
+
+// CallTool executes a tool call on the server of the tool, with the name of the tool on this server
+func (r *MCPRouter) CallTool(ctx context.Context, functionName string, arguments string) (*mcp.CallToolResult, error) {
+	route, ok := r.routes[functionName]
+	if !ok {
+		return nil, fmt.Errorf("no MCP server provides the tool %s", functionName)
+	}
+	r.log().Debug("MCP tool call routed", "function", functionName, "server", route.server)
+	return route.client.CallTool(ctx, route.tool.Name, arguments)
+}
+
+// Close closes the connections to all the servers
+func (r *MCPRouter) Close() error {
+	errs := []error{}
+	for _, server := range r.servers {
+		if err := server.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("MCP server %s: %w", server.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// NewSSEMCPClient creates and initializes a new MCP client over the legacy HTTP+SSE transport, for the
+// servers without streamable HTTP endpoint (sseURL is the SSE endpoint, e.g. http://localhost:8080/sse).
+// When the SSE stream is dropped (server restart, proxy timeout...), the connection is reopened with a new
+// session before the next tool call; a tool call in progress fails, it isn't sent again.
+//
+// Example usage:
+//
+//	mcpClient, err := tools.NewSSEMCPClient(ctx, "http://localhost:8080/sse")
+//	if err != nil {
+//	  return err
+//	}
+//	defer mcpClient.Close()
+//	agent.SetTools(mcpClient.OpenAITools())
+func NewSSEMCPClient(ctx context.Context, sseURL string, options ...MCPClientOption) (*MCPClient, error) {
+	c := &MCPClient{}
+	for _, option := range options {
+		option(c)
+	}
+	mcpClient, lost, err := c.connectSSE(ctx, sseURL)
+	if err != nil {
+		return nil, err
+	}
+	c.lost = lost
+	c.reconnect = func(ctx context.Context) error {
+		mcpClient, lost, err := c.connectSSE(ctx, sseURL)
+		if err != nil {
+			return err
+		}
+		if _, err := mcpClient.Initialize(ctx, initializeRequest()); err != nil {
+			mcpClient.Close()
+			return err
+		}
+		c.mcpclient, c.lost = mcpClient, lost
+		c.log().Debug("MCP SSE connection reopened", "url", sseURL)
+		return nil
+	}
+	return c.initialize(ctx, mcpClient, sseURL)
+}
+
+// connectSSE opens the SSE stream of a server, lost is closed when the stream ends
+func (c *MCPClient) connectSSE(ctx context.Context, sseURL string) (*client.Client, chan struct{}, error) {
+	lost := make(chan struct{})
+	var once sync.Once
+	httpClient := &http.Client{Transport: &sseWatcher{
+		base:   http.DefaultTransport,
+		onLost: func() { once.Do(func() { close(lost) }) },
+	}}
+	mcpClient, err := client.NewSSEMCPClient(sseURL, transport.WithHTTPClient(httpClient), transport.WithSSELogger(transportLogger{c.log()}))
+	if err != nil {
+		return nil, nil, err
+	}
+	// The stream outlives ctx, it is closed by Close
+	if err := mcpClient.Start(context.WithoutCancel(ctx)); err != nil {
+		mcpClient.Close()
+		return nil, nil, err
+	}
+	return mcpClient, lost, nil
+}
+
+// callTool calls a tool with the current connection, an SSE connection is reopened first if it was lost
+func (c *MCPClient) callTool(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	c.mutex.Lock()
+	if c.closed {
+		c.mutex.Unlock()
+		return nil, errors.New("the MCP client is closed")
+	}
+	if c.reconnect != nil && isClosed(c.lost) {
+		c.log().Debug("MCP SSE connection lost, reconnecting")
+		c.mcpclient.Close()
+		if err := c.reconnect(ctx); err != nil {
+			c.mutex.Unlock()
+			return nil, fmt.Errorf("the MCP SSE connection was lost and can't be reopened: %w", err)
+		}
+	}
+	mcpClient, lost := c.mcpclient, c.lost
+	c.mutex.Unlock()
+
+	if lost == nil {
+		return mcpClient.CallTool(ctx, request)
+	}
+	// The answer of the server comes on the stream: the call fails when the stream is lost
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-lost:
+			cancel()
+		case <-callCtx.Done():
+		}
+	}()
+	result, err := mcpClient.CallTool(callCtx, request)
+	if err != nil && ctx.Err() == nil && isClosed(lost) {
+		return nil, fmt.Errorf("the MCP SSE connection was lost during the call: %w", err)
+	}
+	return result, err
+}
+
+// isClosed reports whether a channel is closed
+func isClosed(channel chan struct{}) bool {
+	select {
+	case <-channel:
+		return true
+	default:
+		return false
+	}
+}
+
+// sseWatcher is an HTTP transport calling onLost when the body of an SSE stream ends
+type sseWatcher struct {
+	base   http.RoundTripper
+	onLost func()
+}
+
+// RoundTrip sends the request, and watches the body of the SSE responses
+func (w *sseWatcher) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := w.base.RoundTrip(request)
+	if err != nil || !strings.HasPrefix(response.Header.Get("Content-Type"), "text/event-stream") {
+		return response, err
+	}
+	response.Body = &sseBody{ReadCloser: response.Body, onEnd: w.onLost}
+	return response, nil
+}
+
+// sseBody is the body of an SSE stream calling onEnd when it ends (EOF, network error, or Close)
+type sseBody struct {
+	io.ReadCloser
+	onEnd func()
+}
+
+// Read reads the stream
+func (b *sseBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.onEnd()
+	}
+	return n, err
+}
+
+// Close closes the stream
+func (b *sseBody) Close() error {
+	b.onEnd()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+)
+
+// NewStdioMCPClient starts an MCP server process and creates a client speaking MCP over its standard input
+// and output; env adds "KEY=value" variables to the environment of the current process. The standard error of the
+// server is logged at the debug level. Close stops the process.
+//
+// Example usage:
+//
+//	mcpClient, err := tools.NewStdioMCPClient(ctx, "npx", []string{"-y", "@modelcontextprotocol/server-filesystem", "."}, nil)
+//	if err != nil {
+//	  return err
+//	}
+//	defer mcpClient.Close()
+//	agent.SetTools(mcpClient.OpenAITools())
+func NewStdioMCPClient(ctx context.Context, command string, args []string, env []string, options ...MCPClientOption) (*MCPClient, error) {
+	c := &MCPClient{}
+	for _, option := range options {
+		option(c)
+	}
+	// The process isn't bound to ctx, it runs until Close
+	mcpClient, err := client.NewStdioMCPClientWithOptions(command, env, args, transport.WithCommandLogger(transportLogger{c.log()}))
+	if err != nil {
+		return nil, err
+	}
+	if stderr, ok := client.GetStderr(mcpClient); ok {
+		go func() {
+			scanner := bufio.NewScanner(stderr)
+			for scanner.Scan() {
+				c.log().Debug("MCP server stderr", "command", command, "line", scanner.Text())
+			}
+		}()
+	}
+	return c.initialize(ctx, mcpClient, strings.Join(append([]string{command}, args...), " "))
+}
@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// Registry gathers Go functions exposed as tools: the JSON schema of the parameters of a tool is generated
+// from the struct of the arguments of its function (see mu.JSONSchemaOfType for the tags).
+//
+// Example usage:
+//
+//	type SumArgs struct {
+//	  A float64 `json:"a" description:"The first number"`
+//	  B float64 `json:"b" description:"The second number"`
+//	}
+//	registry := tools.NewRegistry()
+//	err := registry.Register("calculate_sum", "Calculate the sum of two numbers", func(args SumArgs) (float64, error) {
+//	  return args.A + args.B, nil
+//	})
+//	agent.SetTools(registry.OpenAITools())
+//	finishReason, results, answer, err := agent.DetectToolCalls(messages, registry.ToolCallback())
+type Registry struct {
+	tools map[string]registeredTool
+	order []string
+}
+
+// registeredTool is a function of the registry
+type registeredTool struct {
+	definition openai.ChatCompletionToolUnionParam
+	function   reflect.Value
+	arguments  reflect.Type
+	withCtx    bool
+}
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
+)
+
+// NewRegistry creates an empty tool registry
+func NewRegistry() *Registry {
+	return &Registry{tools: map[string]registeredTool{}}
+}
+
+// Register adds a function as a tool, it replaces the tool with the same name. The function takes the
+// struct of the arguments (or a pointer to it), optionally after a context.Context, and returns a result
+// and an error: func(args A) (R, error) or func(ctx context.Context, args A) (R, error).
+// A string result is returned as is to the model, the other results are encoded in JSON.
+func (r *Registry) Register(name string, description string, function any) error {
+	value := reflect.ValueOf(function)
+	t := value.Type()
+	if t.Kind() != reflect.Func {
+		return fmt.Errorf("tool %s: %s is not a function", name, t)
+	}
+	withCtx := t.NumIn() == 2 && t.In(0) == contextType
+	if t.NumIn() != 1 && !withCtx {
+		return fmt.Errorf("tool %s: the function must take the arguments struct, optionally after a context", name)
+	}
+	arguments := t.In(t.NumIn() - 1)
+	structType := arguments
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return fmt.Errorf("tool %s: the arguments must be a struct, not %s", name, arguments)
+	}
+	if t.NumOut() != 2 || t.Out(1) != errorType {
+		return fmt.Errorf("tool %s: the function must return a result and an error", name)
+	}
+
+	if _, exists := r.tools[name]; !exists {
+		r.order = append(r.order, name)
+	}
+	r.tools[name] = registeredTool{
+		definition: openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+			Name:        name,
+			Description: openai.String(description),
+			Parameters:  shared.FunctionParameters(mu.JSONSchemaOfType(structType)),
+		}),
+		function:  value,
+		arguments: arguments,
+		withCtx:   withCtx,
+	}
+	return nil
+}
+
+// OpenAITools returns the definitions of the tools, in the order of their registration
+func (r *Registry) OpenAITools() []openai.ChatCompletionToolUnionParam {
+	definitions := []openai.ChatCompletionToolUnionParam{}
+	for _, name := range r.order {
+		definitions = append(definitions, r.tools[name].definition)
+	}
+	return definitions
+}
+
+// IsTool returns true if the function is a tool of the registry
+func (r *Registry) IsTool(functionName string) bool {
+	_, ok := r.tools[functionName]
+	return ok
+}
+
+// CallTool decodes the JSON arguments and calls the function of a tool
+func (r *Registry) CallTool(functionName string, arguments string) (string, error) {
+	return r.CallToolContext(context.Background(), functionName, arguments)
+}
+
+// CallToolContext is CallTool with the context given to the functions taking one
+func (r *Registry) CallToolContext(ctx context.Context, functionName string, arguments string) (string, error) {
+	tool, ok := r.tools[functionName]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+	structType := tool.arguments
+	if structType.Kind() == reflect.Pointer {
+		structType = structType.Elem()
+	}
+	args := reflect.New(structType)
+	if arguments != "" {
+		if err := json.Unmarshal([]byte(arguments), args.Interface()); err != nil {
+			return "", fmt.Errorf("invalid arguments for %s: %w", functionName, err)
+		}
+	}
+	if tool.arguments.Kind() != reflect.Pointer {
+		args = args.Elem()
+	}
+
+	in := []reflect.Value{args}
+	if tool.withCtx {
+		in = []reflect.Value{reflect.ValueOf(ctx), args}
+	}
+	out := tool.function.Call(in)
+	result := out[0].Interface()
+	err, _ := out[1].Interface().(error)
+	// A tool can end the tool calls loop with its result
+	var exit *mu.ExitToolCallsLoopError
+	if err != nil && !errors.As(err, &exit) {
+		return "", err
+	}
+	if text, ok := result.(string); ok {
+		return text, err
+	}
+	data, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	return string(data), err
+}
+
+// ToolCallback returns a tool callback for DetectToolCalls executing the tools of the registry,
+// the other tools are executed by next (an error if nil)
+func (r *Registry) ToolCallback(next ...func(functionName string, arguments string) (string, error)) func(functionName string, arguments string) (string, error) {
+	return func(functionName string, arguments string) (string, error) {
+		if r.IsTool(functionName) {
+			return r.CallTool(functionName, arguments)
+		}
+		if len(next) > 0 && next[0] != nil {
+			return next[0](functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
+
+// ToolCallbackContext is ToolCallback for the loops of mu.ToolContextRunner: the functions taking a context
+// receive the context of the tool call, cancelled with the loop or at the tool timeout
+//
+// Example usage:
+//
+//	finishReason, results, answer, err := agent.(mu.ToolContextRunner).DetectToolCallsWithToolContext(ctx, messages,
+//	  registry.ToolCallbackContext(nil),
+//	)
+func (r *Registry) ToolCallbackContext(next mu.ToolCallbackContext) mu.ToolCallbackContext {
+	return func(ctx context.Context, functionName string, arguments string) (string, error) {
+		if r.IsTool(functionName) {
+			return r.CallToolContext(ctx, functionName, arguments)
+		}
+		if next != nil {
+			return next(ctx, functionName, arguments)
+		}
+		return "", fmt.Errorf("unknown tool %s", functionName)
+	}
+}
@@ -0,0 +1,177 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// robotsRule is an Allow or Disallow line of a robots.txt file
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// robotsRules are the rules of a site for the user agent of the tool
+type robotsRules []robotsRule
+
+// robotsCache keeps the rules of the sites for an hour
+type robotsCache struct {
+	mutex sync.Mutex
+	rules map[string]robotsRules
+	times map[string]time.Time
+}
+
+// allowedByRobots reports if the robots.txt file of the site allows the page
+func (t *FetchTool) allowedByRobots(ctx context.Context, page *url.URL) (bool, error) {
+	site := page.Scheme + "://" + page.Host
+	t.robotsCache.mutex.Lock()
+	rules, ok := t.robotsCache.rules[site]
+	if ok && time.Since(t.robotsCache.times[site]) > time.Hour {
+		ok = false
+	}
+	t.robotsCache.mutex.Unlock()
+
+	if !ok {
+		var err error
+		if rules, err = t.fetchRobots(ctx, site); err != nil {
+			return false, err
+		}
+		t.robotsCache.mutex.Lock()
+		t.robotsCache.rules[site] = rules
+		t.robotsCache.times[site] = time.Now()
+		t.robotsCache.mutex.Unlock()
+	}
+
+	path := page.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if page.RawQuery != "" {
+		path += "?" + page.RawQuery
+	}
+	return rules.allowed(path), nil
+}
+
+// fetchRobots downloads and parses the robots.txt file of a site (RFC 9309): a missing file allows
+// everything, a server error disallows everything
+func (t *FetchTool) fetchRobots(ctx context.Context, site string) (robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, site+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", t.userAgent)
+	resp, err := t.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		// The site is unreachable, the download of the page will fail
+		return robotsRules{}, nil
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 500:
+		return robotsRules{{pattern: "/", allow: false}}, nil
+	case resp.StatusCode >= 400:
+		return robotsRules{}, nil
+	}
+	return parseRobots(io.LimitReader(resp.Body, 512*1024), t.userAgent), nil
+}
+
+// parseRobots returns the rules of the group of the user agent, or of the "*" group
+func parseRobots(reader io.Reader, userAgent string) robotsRules {
+	// The product token of the user agent: "micro-agent-go" for "micro-agent-go/1.0 (...)"
+	token := ""
+	if fields := strings.FieldsFunc(userAgent, func(r rune) bool { return r == '/' || r == ' ' }); len(fields) > 0 {
+		token = strings.ToLower(fields[0])
+	}
+
+	var specific, generic robotsRules
+	foundSpecific := false
+	agents := []string{}
+	inRules := false
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		switch name {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				agents = agents[:0]
+				inRules = false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// An empty Disallow allows everything
+				continue
+			}
+			rule := robotsRule{pattern: value, allow: name == "allow"}
+			for _, agent := range agents {
+				switch {
+				case agent == "*":
+					generic = append(generic, rule)
+				case agent != "" && agent == token:
+					specific = append(specific, rule)
+					foundSpecific = true
+				}
+			}
+		}
+	}
+	if foundSpecific {
+		return specific
+	}
+	return generic
+}
+
+// allowed reports if a path is allowed: the longest matching rule wins, Allow wins a tie
+func (rules robotsRules) allowed(path string) bool {
+	allowed := true
+	longest := -1
+	for _, rule := range rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if length := len(rule.pattern); length > longest || length == longest && rule.allow {
+			longest = length
+			allowed = rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch reports if a path matches a pattern of a rule ("*" matches any sequence, "$" ends the path)
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	position := len(parts[0])
+	for i, part := range parts[1:] {
+		last := i == len(parts)-2
+		if last && anchored {
+			return strings.HasSuffix(path[position:], part)
+		}
+		index := strings.Index(path[position:], part)
+		if index < 0 {
+			return false
+		}
+		position += index + len(part)
+	}
+	return !anchored || position == len(path)
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CodeBlockStyle is the chroma style used to highlight the code blocks
+var CodeBlockStyle = "monokai"
+
+// CodeBlock represents a fenced code block extracted from a markdown content
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+var codeBlockRegex = regexp.MustCompile("(?ms)^\\s*```([\\w+#.-]*)[^\\n]*\\n(.*?)^\\s*```\\s*$")
+
+// ExtractCodeBlocks returns the fenced code blocks (```lang ... ```) of a markdown content
+func ExtractCodeBlocks(markdown string) []CodeBlock {
+	var blocks []CodeBlock
+	for _, match := range codeBlockRegex.FindAllStringSubmatch(markdown, -1) {
+		blocks = append(blocks, CodeBlock{
+			Language: match[1],
+			Code:     strings.TrimRight(match[2], "\n"),
+		})
+	}
+	return blocks
+}
+
+// RenderCodeBlock highlights the code with chroma for a 256 colors terminal.
+// If the language is empty or unknown, the lexer is guessed from the code.
+func RenderCodeBlock(code string, language string, showLineNumbers bool) (string, error) {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	style := styles.Get(CodeBlockStyle)
+	if style == nil {
+		style = styles.Fallback
+	}
+
+	iterator, err := lexer.Tokenise(nil, code)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if err := formatters.TTY256.Format(&builder, style, iterator); err != nil {
+		return "", err
+	}
+	rendered := strings.TrimRight(builder.String(), "\n")
+
+	if !showLineNumbers {
+		return rendered, nil
+	}
+
+	lines := strings.Split(rendered, "\n")
+	width := len(fmt.Sprint(len(lines)))
+	lineNumberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(Gray))
+	for i, line := range lines {
+		lines[i] = lineNumberStyle.Render(fmt.Sprintf("%*d │ ", width, i+1)) + line
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// PrintCodeBlock prints the highlighted code with automatic fallback to plain text
+func PrintCodeBlock(code string, language string, showLineNumbers bool) {
+	rendered, err := RenderCodeBlock(code, language, showLineNumbers)
+	if err != nil {
+		// Fallback to plain text if highlighting fails
+		fmt.Println(code)
+		return
+	}
+	fmt.Println(rendered)
+}
+
+type copyKeyModel struct {
+	copy bool
+}
+
+// Init initializes the model and returns the initial command
+func (m copyKeyModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model state
+func (m copyKeyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.copy = keyMsg.String() == "c"
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View renders the model as a string for display
+func (m copyKeyModel) View() string {
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(Gray)).Render("📋 press c to copy, any other key to continue") + "\n"
+}
+
+// PrintCodeBlockWithCopy prints the highlighted code block and offers to copy it to the clipboard
+// ("press c to copy"). It returns true if the code has been copied.
+func PrintCodeBlockWithCopy(block CodeBlock, showLineNumbers bool) (bool, error) {
+	PrintCodeBlock(block.Code, block.Language, showLineNumbers)
+
+	m, err := tea.NewProgram(copyKeyModel{}).Run()
+	if err != nil {
+		return false, err
+	}
+	if m, ok := m.(copyKeyModel); ok && m.copy {
+		if err := CopyToClipboard(block.Code); err != nil {
+			return false, err
+		}
+		Println(Green, "✅ copied to clipboard")
+		return true, nil
+	}
+	return false, nil
+}
+
+// PrintCodeBlocksWithCopy prints every code block of a markdown content and offers to copy each of them
+func PrintCodeBlocksWithCopy(markdown string, showLineNumbers bool) error {
+	for _, block := range ExtractCodeBlocks(markdown) {
+		if _, err := PrintCodeBlockWithCopy(block, showLineNumbers); err != nil {
+			return err
+		}
+	}
+	return nil
+}
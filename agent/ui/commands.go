@@ -0,0 +1,124 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownCommand is returned by CommandRegistry.Execute when the slash command is not registered
+var ErrUnknownCommand = errors.New("unknown command")
+
+// ErrExitCommand can be returned by a command handler to ask the caller to leave the interactive loop (e.g. /bye)
+var ErrExitCommand = errors.New("exit")
+
+// SlashCommand is an in-chat command (e.g. "/model <id>") handled by the application instead of being sent to the LLM
+type SlashCommand struct {
+	Name        string // with the leading "/"
+	Usage       string // e.g. "/model <id>", the name by default
+	Description string
+	Handler     func(args string) error // args is the text after the command name (trimmed)
+}
+
+// CommandRegistry holds the slash commands of an interactive application
+type CommandRegistry struct {
+	commands map[string]SlashCommand
+}
+
+// NewCommandRegistry creates an empty command registry
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		commands: map[string]SlashCommand{},
+	}
+}
+
+// Register adds (or replaces) a command, the "/" prefix is added to the name if missing
+func (r *CommandRegistry) Register(command SlashCommand) {
+	if !strings.HasPrefix(command.Name, "/") {
+		command.Name = "/" + command.Name
+	}
+	if command.Usage == "" {
+		command.Usage = command.Name
+	}
+	r.commands[command.Name] = command
+}
+
+// Get returns the command with the given name
+func (r *CommandRegistry) Get(name string) (SlashCommand, bool) {
+	command, ok := r.commands[name]
+	return command, ok
+}
+
+// Commands returns the registered commands sorted by name
+func (r *CommandRegistry) Commands() []SlashCommand {
+	commands := make([]SlashCommand, 0, len(r.commands))
+	for _, command := range r.commands {
+		commands = append(commands, command)
+	}
+	sort.Slice(commands, func(i, j int) bool {
+		return commands[i].Name < commands[j].Name
+	})
+	return commands
+}
+
+// Names returns the sorted names of the commands (e.g. for SlashCommandCompletion)
+func (r *CommandRegistry) Names() []string {
+	names := []string{}
+	for _, command := range r.Commands() {
+		names = append(names, command.Name)
+	}
+	return names
+}
+
+// IsCommand returns true if the input looks like a slash command ("/name ...")
+func IsCommand(input string) bool {
+	input = strings.TrimSpace(input)
+	return len(input) > 1 && strings.HasPrefix(input, "/") && !strings.HasPrefix(input, "//")
+}
+
+// ParseCommand splits a slash command input into its name and its arguments
+func ParseCommand(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	name, args, _ := strings.Cut(input, " ")
+	return name, strings.TrimSpace(args)
+}
+
+// Execute runs the command of the input. It returns handled=false if the input is not a slash command
+// (the input must be sent to the LLM), and ErrUnknownCommand if the command is not registered.
+func (r *CommandRegistry) Execute(input string) (bool, error) {
+	if !IsCommand(input) {
+		return false, nil
+	}
+	name, args := ParseCommand(input)
+	command, ok := r.commands[name]
+	if !ok {
+		return true, fmt.Errorf("%w: %s (type /help to list the commands)", ErrUnknownCommand, name)
+	}
+	return true, command.Handler(args)
+}
+
+// HelpText returns the list of the commands with their usage and description
+func (r *CommandRegistry) HelpText() string {
+	width := 0
+	for _, command := range r.commands {
+		width = max(width, len(command.Usage))
+	}
+	var builder strings.Builder
+	for _, command := range r.Commands() {
+		builder.WriteString(fmt.Sprintf("  %-*s  %s\n", width, command.Usage, command.Description))
+	}
+	return builder.String()
+}
+
+// RegisterHelpCommand registers a /help command printing the help text with the given color
+func (r *CommandRegistry) RegisterHelpCommand(color string) {
+	r.Register(SlashCommand{
+		Name:        "/help",
+		Description: "Show the available commands",
+		Handler: func(string) error {
+			Printf(color, "%s", r.HelpText())
+			return nil
+		},
+	})
+}
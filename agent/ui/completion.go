@@ -0,0 +1,112 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// CompletionSource returns the candidates completing the word being typed (the last word of the input)
+type CompletionSource func(word string) []string
+
+// StaticCompletion completes the words with the candidates starting with them (at least 2 typed characters)
+func StaticCompletion(candidates ...string) CompletionSource {
+	return func(word string) []string {
+		if len(word) < 2 {
+			return nil
+		}
+		return matchPrefix(word, candidates)
+	}
+}
+
+// SlashCommandCompletion completes the words starting with "/" with the given commands (e.g. "/bye", "/edit")
+func SlashCommandCompletion(commands ...string) CompletionSource {
+	return func(word string) []string {
+		if !strings.HasPrefix(word, "/") || strings.Contains(word[1:], "/") {
+			return nil
+		}
+		return matchPrefix(word, commands)
+	}
+}
+
+// FilePathCompletion completes the words looking like a path ("./", "../", "/" or "~/" prefix, or containing "/")
+// with the matching files and directories (directories end with "/")
+func FilePathCompletion() CompletionSource {
+	return func(word string) []string {
+		if !strings.Contains(word, "/") && !strings.HasPrefix(word, "~") {
+			return nil
+		}
+
+		path := word
+		if strings.HasPrefix(word, "~/") {
+			if homeDir, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(homeDir, word[2:])
+				if strings.HasSuffix(word, "/") {
+					path += "/"
+				}
+			}
+		}
+
+		dir, prefix := filepath.Split(path)
+		if dir == "" {
+			dir = "."
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil
+		}
+
+		// Keep the typed directory part as is
+		typedDir := word[:strings.LastIndex(word, "/")+1]
+		candidates := []string{}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+				continue
+			}
+			candidate := typedDir + name
+			if entry.IsDir() {
+				candidate += "/"
+			}
+			candidates = append(candidates, candidate)
+		}
+		sort.Strings(candidates)
+		return candidates
+	}
+}
+
+// CombineCompletions merges the candidates of several completion sources (without duplicates)
+func CombineCompletions(sources ...CompletionSource) CompletionSource {
+	return func(word string) []string {
+		seen := map[string]bool{}
+		candidates := []string{}
+		for _, source := range sources {
+			for _, candidate := range source(word) {
+				if !seen[candidate] {
+					seen[candidate] = true
+					candidates = append(candidates, candidate)
+				}
+			}
+		}
+		return candidates
+	}
+}
+
+// matchPrefix returns the candidates starting with word (case-insensitive), excluding word itself
+func matchPrefix(word string, candidates []string) []string {
+	matches := []string{}
+	lowerWord := strings.ToLower(word)
+	for _, candidate := range candidates {
+		if candidate != word && strings.HasPrefix(strings.ToLower(candidate), lowerWord) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// lastWord splits the input into the text before the last word and the last word
+func lastWord(input string) (string, string) {
+	index := strings.LastIndexAny(input, " \t\n")
+	return input[:index+1], input[index+1:]
+}
@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -159,3 +160,110 @@ func GetChoice(color string, message string, choices []string, defaultChoice str
 		fmt.Printf("Invalid choice. Please choose from: %s\n", choicesStr)
 	}
 }
+
+// confirmationTickMsg is sent every second by the countdown of a timed confirmation
+type confirmationTickMsg struct{}
+
+// timedConfirmationModel is a text input that quits by itself when the countdown reaches zero
+type timedConfirmationModel struct {
+	textInput textinput.Model
+	style     lipgloss.Style
+	prompt    string
+	remaining int
+	typing    bool // the countdown is stopped as soon as the user starts typing
+	timedOut  bool
+	cancelled bool
+}
+
+func confirmationTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return confirmationTickMsg{}
+	})
+}
+
+// Init starts the cursor blink and the countdown
+func (m timedConfirmationModel) Init() tea.Cmd {
+	return tea.Batch(textinput.Blink, confirmationTick())
+}
+
+// Update handles the key presses and the countdown ticks
+func (m timedConfirmationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case confirmationTickMsg:
+		if m.typing {
+			return m, nil
+		}
+		m.remaining--
+		if m.remaining <= 0 {
+			m.timedOut = true
+			return m, tea.Quit
+		}
+		m.textInput.Prompt = fmt.Sprintf("%s(%ds) ", m.prompt, m.remaining)
+		return m, confirmationTick()
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m, tea.Quit
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, tea.Quit
+		}
+		if !m.typing {
+			m.typing = true
+			m.textInput.Prompt = m.prompt
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// View renders the prompt with the remaining seconds
+func (m timedConfirmationModel) View() string {
+	return m.style.Render(m.textInput.View() + "\n")
+}
+
+// GetConfirmationWithTimeout prompts the user for yes/no confirmation like GetConfirmation,
+// but automatically selects the default value when the user doesn't answer before the timeout.
+// The remaining seconds are displayed after the prompt; the countdown stops as soon as the user starts typing.
+// A timeout <= 0 waits forever (same as GetConfirmation).
+func GetConfirmationWithTimeout(color string, message string, defaultYes bool, timeout time.Duration) bool {
+	if timeout <= 0 {
+		return GetConfirmation(color, message, defaultYes)
+	}
+
+	defaultText := "y"
+	if !defaultYes {
+		defaultText = "n"
+	}
+	prompt := fmt.Sprintf("%s (y/n) [%s]: ", message, defaultText)
+	seconds := int((timeout + time.Second - 1) / time.Second)
+
+	ti := textinput.New()
+	ti.Focus()
+	ti.CharLimit = 255
+	ti.Width = 80
+	ti.Prompt = fmt.Sprintf("%s(%ds) ", prompt, seconds)
+
+	p := tea.NewProgram(timedConfirmationModel{
+		textInput: ti,
+		style:     lipgloss.NewStyle().Foreground(lipgloss.Color(color)),
+		prompt:    prompt,
+		remaining: seconds,
+	})
+	m, err := p.Run()
+	if err != nil {
+		return defaultYes
+	}
+	result, ok := m.(timedConfirmationModel)
+	if !ok || result.timedOut || result.cancelled {
+		return defaultYes
+	}
+
+	input := strings.ToLower(strings.TrimSpace(result.textInput.Value()))
+	if input == "" {
+		return defaultYes
+	}
+	return input == "y" || input == "yes"
+}
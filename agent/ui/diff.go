@@ -0,0 +1,265 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// DiffOp is the kind of a diff line
+type DiffOp int
+
+const (
+	DiffEqual DiffOp = iota
+	DiffDelete
+	DiffInsert
+)
+
+// DiffLine is a line of a diff with its line numbers in the old and new texts (0 when absent)
+type DiffLine struct {
+	Op      DiffOp
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// DiffOptions configures the rendering of a diff
+type DiffOptions struct {
+	OldName    string // displayed in the header, "old" by default
+	NewName    string // displayed in the header, "new" by default
+	Context    int    // number of unchanged lines around the changes, 3 by default, -1 for the whole file
+	SideBySide bool
+	Width      int // total width of the side-by-side view, 160 by default
+}
+
+// ComputeDiff computes the line-based diff (longest common subsequence) between two texts
+func ComputeDiff(oldText, newText string) []DiffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+	n, m := len(oldLines), len(newLines)
+
+	// lcs[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var lines []DiffLine
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldLines[i] == newLines[j]:
+			lines = append(lines, DiffLine{Op: DiffEqual, Text: oldLines[i], OldLine: i + 1, NewLine: j + 1})
+			i++
+			j++
+		case j < m && (i == n || lcs[i][j+1] > lcs[i+1][j]):
+			lines = append(lines, DiffLine{Op: DiffInsert, Text: newLines[j], NewLine: j + 1})
+			j++
+		default:
+			lines = append(lines, DiffLine{Op: DiffDelete, Text: oldLines[i], OldLine: i + 1})
+			i++
+		}
+	}
+	return lines
+}
+
+// splitLines splits a text into lines, ignoring the final new line
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffHunks groups the diff lines into hunks of changes surrounded by context lines
+func diffHunks(lines []DiffLine, context int) [][]DiffLine {
+	if context < 0 {
+		return [][]DiffLine{lines}
+	}
+	var hunks [][]DiffLine
+	start, end := -1, -1
+	for idx, line := range lines {
+		if line.Op == DiffEqual {
+			continue
+		}
+		lo := max(idx-context, 0)
+		hi := min(idx+context, len(lines)-1)
+		if start >= 0 && lo <= end+1 {
+			end = max(end, hi)
+			continue
+		}
+		if start >= 0 {
+			hunks = append(hunks, lines[start:end+1])
+		}
+		start, end = lo, hi
+	}
+	if start >= 0 {
+		hunks = append(hunks, lines[start:end+1])
+	}
+	return hunks
+}
+
+// hunkHeader builds the "@@ -a,b +c,d @@" header of a hunk
+func hunkHeader(hunk []DiffLine) string {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, line := range hunk {
+		if line.OldLine > 0 {
+			if oldStart == 0 {
+				oldStart = line.OldLine
+			}
+			oldCount++
+		}
+		if line.NewLine > 0 {
+			if newStart == 0 {
+				newStart = line.NewLine
+			}
+			newCount++
+		}
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@", oldStart, oldCount, newStart, newCount)
+}
+
+// RenderDiff renders the colored diff between two texts (unified or side-by-side)
+func RenderDiff(oldText, newText string, options DiffOptions) string {
+	if options.OldName == "" {
+		options.OldName = "old"
+	}
+	if options.NewName == "" {
+		options.NewName = "new"
+	}
+	if options.Context == 0 {
+		options.Context = 3
+	}
+
+	lines := ComputeDiff(oldText, newText)
+	hunks := diffHunks(lines, options.Context)
+	if len(hunks) == 0 || (options.Context < 0 && !hasChanges(lines)) {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color(Gray)).Render("(no changes)") + "\n"
+	}
+
+	if options.SideBySide {
+		return renderSideBySide(hunks, options)
+	}
+	return renderUnified(hunks, options)
+}
+
+// hasChanges returns true if at least one line is inserted or deleted
+func hasChanges(lines []DiffLine) bool {
+	for _, line := range lines {
+		if line.Op != DiffEqual {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	diffHeaderStyle = lipgloss.NewStyle().Bold(true)
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color(Cyan))
+	diffDeleteStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(Red))
+	diffInsertStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(Green))
+	diffEqualStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color(Gray))
+)
+
+// renderUnified renders the hunks in the unified diff format
+func renderUnified(hunks [][]DiffLine, options DiffOptions) string {
+	var builder strings.Builder
+	builder.WriteString(diffHeaderStyle.Render("--- "+options.OldName) + "\n")
+	builder.WriteString(diffHeaderStyle.Render("+++ "+options.NewName) + "\n")
+	for _, hunk := range hunks {
+		builder.WriteString(diffHunkStyle.Render(hunkHeader(hunk)) + "\n")
+		for _, line := range hunk {
+			switch line.Op {
+			case DiffDelete:
+				builder.WriteString(diffDeleteStyle.Render("-"+line.Text) + "\n")
+			case DiffInsert:
+				builder.WriteString(diffInsertStyle.Render("+"+line.Text) + "\n")
+			default:
+				builder.WriteString(diffEqualStyle.Render(" "+line.Text) + "\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// renderSideBySide renders the hunks with the old text on the left and the new text on the right
+func renderSideBySide(hunks [][]DiffLine, options DiffOptions) string {
+	width := options.Width
+	if width <= 0 {
+		width = 160
+	}
+	columnWidth := (width - 3) / 2
+
+	cell := func(style lipgloss.Style, lineNumber int, text string) string {
+		content := ""
+		if lineNumber > 0 {
+			content = fmt.Sprintf("%4d %s", lineNumber, text)
+		}
+		content = ansi.Truncate(content, columnWidth, "…")
+		return style.Render(content) + strings.Repeat(" ", max(columnWidth-ansi.StringWidth(content), 0))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(diffHeaderStyle.Render(padRight(options.OldName, columnWidth)) +
+		" │ " + diffHeaderStyle.Render(options.NewName) + "\n")
+
+	for _, hunk := range hunks {
+		builder.WriteString(diffHunkStyle.Render(hunkHeader(hunk)) + "\n")
+		for idx := 0; idx < len(hunk); {
+			if hunk[idx].Op == DiffEqual {
+				line := hunk[idx]
+				builder.WriteString(cell(diffEqualStyle, line.OldLine, line.Text) + " │ " + cell(diffEqualStyle, line.NewLine, line.Text) + "\n")
+				idx++
+				continue
+			}
+			// Pair the deleted lines with the inserted lines of the same block
+			var deleted, inserted []DiffLine
+			for idx < len(hunk) && hunk[idx].Op != DiffEqual {
+				if hunk[idx].Op == DiffDelete {
+					deleted = append(deleted, hunk[idx])
+				} else {
+					inserted = append(inserted, hunk[idx])
+				}
+				idx++
+			}
+			for row := 0; row < max(len(deleted), len(inserted)); row++ {
+				left, right := cell(diffDeleteStyle, 0, ""), cell(diffInsertStyle, 0, "")
+				if row < len(deleted) {
+					left = cell(diffDeleteStyle, deleted[row].OldLine, deleted[row].Text)
+				}
+				if row < len(inserted) {
+					right = cell(diffInsertStyle, inserted[row].NewLine, inserted[row].Text)
+				}
+				builder.WriteString(left + " │ " + right + "\n")
+			}
+		}
+	}
+	return builder.String()
+}
+
+// padRight pads (or truncates) a text to the given width
+func padRight(text string, width int) string {
+	text = ansi.Truncate(text, width, "…")
+	return text + strings.Repeat(" ", max(width-ansi.StringWidth(text), 0))
+}
+
+// PrintDiff prints the colored unified diff between two texts
+func PrintDiff(oldText, newText string) {
+	fmt.Print(RenderDiff(oldText, newText, DiffOptions{}))
+}
+
+// PrintDiffWithOptions prints the colored diff between two texts with the given options (e.g. side-by-side)
+func PrintDiffWithOptions(oldText, newText string, options DiffOptions) {
+	fmt.Print(RenderDiff(oldText, newText, options))
+}
@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditorCommand returns the command used to edit a file: $VISUAL, then $EDITOR,
+// then "notepad" on Windows and "vi" elsewhere
+func EditorCommand() string {
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	if runtime.GOOS == "windows" {
+		return "notepad"
+	}
+	return "vi"
+}
+
+// EditInEditor opens the external editor (see EditorCommand) on a temporary markdown file
+// containing initialContent, waits for the editor to exit and returns the saved content.
+// The editor command can contain arguments (e.g. EDITOR="code --wait").
+func EditInEditor(initialContent string) (string, error) {
+	file, err := os.CreateTemp("", "micro-agent-prompt-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.WriteString(initialContent); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		return "", err
+	}
+
+	parts := strings.Fields(EditorCommand())
+	cmd := exec.Command(parts[0], append(parts[1:], file.Name())...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// EditorPrompt opens the external editor and returns the saved content as a parsed UserCommand
+func EditorPrompt(initialContent string) (*UserCommand, error) {
+	content, err := EditInEditor(initialContent)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUserCommand(content), nil
+}
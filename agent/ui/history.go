@@ -0,0 +1,378 @@
+package ui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// History stores the previously typed prompts, optionally persisted to a file (one entry per line)
+type History struct {
+	filePath   string
+	maxEntries int
+	entries    []string
+}
+
+// NewHistory creates a prompt history and loads the existing entries from filePath.
+// An empty filePath keeps the history in memory only. maxEntries <= 0 means no limit.
+func NewHistory(filePath string, maxEntries int) (*History, error) {
+	history := &History{
+		filePath:   filePath,
+		maxEntries: maxEntries,
+	}
+	if filePath == "" {
+		return history, nil
+	}
+
+	file, err := os.Open(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			history.entries = append(history.entries, decodeHistoryEntry(line))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	history.truncate()
+	return history, nil
+}
+
+// Entries returns the history entries, from the oldest to the most recent
+func (h *History) Entries() []string {
+	return h.entries
+}
+
+// Add appends an entry to the history (empty entries and consecutive duplicates are ignored)
+// and persists the history file if any
+func (h *History) Add(entry string) error {
+	entry = strings.TrimSpace(entry)
+	if entry == "" {
+		return nil
+	}
+	if len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry {
+		return nil
+	}
+	h.entries = append(h.entries, entry)
+	h.truncate()
+	return h.Save()
+}
+
+// Save writes the history entries to the history file
+func (h *History) Save() error {
+	if h.filePath == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(h.filePath), 0755); err != nil {
+		return err
+	}
+	var builder strings.Builder
+	for _, entry := range h.entries {
+		builder.WriteString(encodeHistoryEntry(entry))
+		builder.WriteString("\n")
+	}
+	return os.WriteFile(h.filePath, []byte(builder.String()), 0600)
+}
+
+// Search returns the index of the most recent entry containing query, starting at index from (included)
+// and going backwards. It returns -1 if there is no match.
+func (h *History) Search(query string, from int) int {
+	if from >= len(h.entries) {
+		from = len(h.entries) - 1
+	}
+	for i := from; i >= 0; i-- {
+		if strings.Contains(h.entries[i], query) {
+			return i
+		}
+	}
+	return -1
+}
+
+// truncate keeps only the maxEntries most recent entries
+func (h *History) truncate() {
+	if h.maxEntries > 0 && len(h.entries) > h.maxEntries {
+		h.entries = h.entries[len(h.entries)-h.maxEntries:]
+	}
+}
+
+// encodeHistoryEntry escapes the new lines so a multiline entry is stored on a single line
+func encodeHistoryEntry(entry string) string {
+	entry = strings.ReplaceAll(entry, `\`, `\\`)
+	return strings.ReplaceAll(entry, "\n", `\n`)
+}
+
+// decodeHistoryEntry restores the new lines of an entry read from the history file
+func decodeHistoryEntry(line string) string {
+	var builder strings.Builder
+	escaped := false
+	for _, r := range line {
+		switch {
+		case escaped && r == 'n':
+			builder.WriteRune('\n')
+			escaped = false
+		case escaped:
+			builder.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		default:
+			builder.WriteRune(r)
+		}
+	}
+	return builder.String()
+}
+
+type historyModel struct {
+	textInput   textinput.Model
+	history     *History
+	index       int    // position in the history, len(entries) means the current (new) input
+	draft       string // the input typed before navigating in the history
+	searching   bool
+	searchQuery string
+	searchIndex int
+	cancelled   bool
+
+	completion     CompletionSource
+	candidates     []string // candidates completing the last word of the input
+	candidateIndex int      // next candidate inserted by Tab
+	completing     bool     // Tab has been pressed, the next Tab inserts the next candidate
+}
+
+// InputOption is a functional option for configuring the interactive inputs
+type InputOption func(*historyModel)
+
+// WithCompletion enables the Tab completion of the last word of the input with the given source
+// (the candidates are displayed below the input while typing)
+func WithCompletion(source CompletionSource) InputOption {
+	return func(m *historyModel) {
+		m.completion = source
+	}
+}
+
+// initialHistoryModel creates a new text input model with history navigation
+func initialHistoryModel(prompt string, history *History, options ...InputOption) historyModel {
+	ti := textinput.New()
+	ti.Placeholder = ""
+	ti.Focus()
+	ti.CharLimit = 0
+	ti.Width = 80
+	ti.Prompt = prompt
+
+	m := historyModel{
+		textInput: ti,
+		history:   history,
+		index:     len(history.Entries()),
+	}
+	// Apply all options
+	for _, option := range options {
+		option(&m)
+	}
+	return m
+}
+
+// Init initializes the model and returns the initial command
+func (m historyModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages and updates the model state
+func (m historyModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey {
+		var cmd tea.Cmd
+		m.textInput, cmd = m.textInput.Update(msg)
+		return m, cmd
+	}
+
+	entries := m.history.Entries()
+
+	if m.searching {
+		switch keyMsg.Type {
+		case tea.KeyCtrlR:
+			// Look for an older match
+			if m.searchIndex > 0 {
+				if found := m.history.Search(m.searchQuery, m.searchIndex-1); found >= 0 {
+					m.searchIndex = found
+				}
+			}
+			return m, nil
+		case tea.KeyBackspace:
+			if len(m.searchQuery) > 0 {
+				runes := []rune(m.searchQuery)
+				m.searchQuery = string(runes[:len(runes)-1])
+				m.searchIndex = m.history.Search(m.searchQuery, len(entries)-1)
+			}
+			return m, nil
+		case tea.KeyRunes, tea.KeySpace:
+			m.searchQuery += string(keyMsg.Runes)
+			m.searchIndex = m.history.Search(m.searchQuery, len(entries)-1)
+			return m, nil
+		case tea.KeyCtrlC, tea.KeyCtrlG:
+			// Leave the search mode without changing the input
+			m.searching = false
+			return m, nil
+		default:
+			// Accept the match and go on with the key (Enter submits, Esc/arrows edit)
+			m.searching = false
+			if m.searchIndex >= 0 && m.searchIndex < len(entries) {
+				m.index = m.searchIndex
+				m.textInput.SetValue(entries[m.searchIndex])
+				m.textInput.CursorEnd()
+			}
+			if keyMsg.Type == tea.KeyEsc {
+				return m, nil
+			}
+		}
+	}
+
+	if keyMsg.Type == tea.KeyTab && m.completion != nil {
+		m.complete()
+		return m, nil
+	}
+
+	switch keyMsg.Type {
+	case tea.KeyEnter:
+		return m, tea.Quit
+	case tea.KeyCtrlC, tea.KeyEsc:
+		m.cancelled = true
+		return m, tea.Quit
+	case tea.KeyCtrlR:
+		m.searching = true
+		m.searchQuery = ""
+		m.searchIndex = len(entries) - 1
+		return m, nil
+	case tea.KeyUp:
+		if m.index > 0 {
+			if m.index == len(entries) {
+				m.draft = m.textInput.Value()
+			}
+			m.index--
+			m.textInput.SetValue(entries[m.index])
+			m.textInput.CursorEnd()
+			m.updateCandidates()
+		}
+		return m, nil
+	case tea.KeyDown:
+		if m.index < len(entries) {
+			m.index++
+			if m.index == len(entries) {
+				m.textInput.SetValue(m.draft)
+			} else {
+				m.textInput.SetValue(entries[m.index])
+			}
+			m.textInput.CursorEnd()
+			m.updateCandidates()
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	m.updateCandidates()
+	return m, cmd
+}
+
+// updateCandidates computes the completion candidates of the last word of the input
+func (m *historyModel) updateCandidates() {
+	m.completing = false
+	m.candidates = nil
+	if m.completion == nil {
+		return
+	}
+	if _, word := lastWord(m.textInput.Value()); word != "" {
+		m.candidates = m.completion(word)
+	}
+}
+
+// complete replaces the last word of the input with the next completion candidate
+func (m *historyModel) complete() {
+	before, word := lastWord(m.textInput.Value())
+	if !m.completing {
+		if word == "" {
+			return
+		}
+		m.candidates = m.completion(word)
+		if len(m.candidates) == 0 {
+			return
+		}
+		m.completing = true
+		m.candidateIndex = 0
+	}
+	// Tab again cycles through the candidates
+	candidate := m.candidates[m.candidateIndex%len(m.candidates)]
+	m.candidateIndex++
+	m.textInput.SetValue(before + candidate)
+	m.textInput.CursorEnd()
+}
+
+// View renders the model as a string for display
+func (m historyModel) View() string {
+	if m.searching {
+		match := ""
+		if m.searchIndex >= 0 && m.searchIndex < len(m.history.Entries()) {
+			match = strings.ReplaceAll(m.history.Entries()[m.searchIndex], "\n", " ")
+		}
+		return promptStyle.Render(fmt.Sprintf("(reverse-i-search)`%s': %s", m.searchQuery, match) + "\n")
+	}
+	view := promptStyle.Render(m.textInput.View() + "\n")
+	if len(m.candidates) > 0 {
+		// Display the first candidates below the input
+		shown := m.candidates
+		more := ""
+		if len(shown) > 8 {
+			shown = shown[:8]
+			more = fmt.Sprintf(" (+%d)", len(m.candidates)-8)
+		}
+		view += lipgloss.NewStyle().Foreground(lipgloss.Color(Gray)).Render("  ⇥ "+strings.Join(shown, "  ")+more) + "\n"
+	}
+	return view
+}
+
+// InputWithHistory creates a colored text input prompt with readline-style history:
+// Up/Down recall the previous entries and Ctrl+R searches the history.
+// The submitted input is added to the history. Ctrl+C or Esc cancels (ErrInputCancelled).
+// Tab completes the last word when a completion source is set with WithCompletion.
+func InputWithHistory(color, prompt string, history *History, options ...InputOption) (string, error) {
+	promptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	p := tea.NewProgram(initialHistoryModel(prompt, history, options...))
+	m, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+	if m, ok := m.(historyModel); ok {
+		if m.cancelled {
+			return "", ErrInputCancelled
+		}
+		input := strings.TrimSpace(m.textInput.Value())
+		if err := history.Add(input); err != nil {
+			return input, err
+		}
+		return input, nil
+	}
+	return "", errors.New("😡 unable to get input")
+}
+
+// PromptWithHistory creates an interactive prompt with history and returns a parsed UserCommand
+func PromptWithHistory(promptTitle string, history *History, options ...InputOption) (*UserCommand, error) {
+	input, err := InputWithHistory(White, promptTitle+" ", history, options...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUserCommand(input), nil
+}
@@ -0,0 +1,116 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// JSON syntax colors
+var (
+	JSONKeyColor     = Cyan
+	JSONStringColor  = Green
+	JSONNumberColor  = Yellow
+	JSONLiteralColor = Magenta // true, false, null
+)
+
+// RenderJSON returns the indented and colorized JSON representation of v.
+// A string, a []byte or a json.RawMessage containing JSON is indented as is
+// (e.g. the arguments of a tool call or a structured-output response);
+// a string that is not valid JSON is rendered as a JSON string.
+func RenderJSON(v any) (string, error) {
+	var raw []byte
+	switch value := v.(type) {
+	case json.RawMessage:
+		raw = value
+	case []byte:
+		raw = value
+	case string:
+		raw = []byte(value)
+	}
+
+	var indented bytes.Buffer
+	if raw != nil && json.Valid(raw) {
+		if err := json.Indent(&indented, raw, "", "  "); err != nil {
+			return "", err
+		}
+	} else {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		indented.Write(data)
+	}
+	return colorizeJSON(indented.String()), nil
+}
+
+// PrintJSON prints the indented and colorized JSON representation of v (see RenderJSON)
+func PrintJSON(v any) {
+	rendered, err := RenderJSON(v)
+	if err != nil {
+		fmt.Printf("%v\n", v)
+		return
+	}
+	fmt.Println(rendered)
+}
+
+// colorizeJSON colors the keys, strings, numbers and literals of a valid JSON text
+func colorizeJSON(text string) string {
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(JSONKeyColor))
+	stringStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(JSONStringColor))
+	numberStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(JSONNumberColor))
+	literalStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(JSONLiteralColor))
+
+	var builder strings.Builder
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '"':
+			// Find the closing quote (skip the escaped characters)
+			end := i + 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			end = min(end+1, len(text))
+			token := text[i:end]
+
+			// A string followed by ":" is a key
+			next := end
+			for next < len(text) && (text[next] == ' ' || text[next] == '\n' || text[next] == '\t' || text[next] == '\r') {
+				next++
+			}
+			if next < len(text) && text[next] == ':' {
+				builder.WriteString(keyStyle.Render(token))
+			} else {
+				builder.WriteString(stringStyle.Render(token))
+			}
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(text) && strings.IndexByte("0123456789.eE+-", text[end]) >= 0 {
+				end++
+			}
+			builder.WriteString(numberStyle.Render(text[i:end]))
+			i = end
+		case strings.HasPrefix(text[i:], "true"):
+			builder.WriteString(literalStyle.Render("true"))
+			i += 4
+		case strings.HasPrefix(text[i:], "false"):
+			builder.WriteString(literalStyle.Render("false"))
+			i += 5
+		case strings.HasPrefix(text[i:], "null"):
+			builder.WriteString(literalStyle.Render("null"))
+			i += 4
+		default:
+			builder.WriteByte(c)
+			i++
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Logger writes leveled debug/diagnostic logs, separated from the user-facing output of Println/Printf.
+// The logs are written (colored) to the standard error and can be mirrored to a file,
+// or only written to the file to keep the terminal clean.
+type Logger struct {
+	level        slog.Level
+	output       io.Writer // terminal output, nil to log only to the file
+	filePath     string
+	file         *os.File
+	fileLevel    slog.Level
+	fileLevelSet bool
+	jsonFile     bool
+	slogger      *slog.Logger
+}
+
+// LoggerOption is a functional option for configuring Logger instances
+type LoggerOption func(*Logger)
+
+// WithLogLevel sets the minimum level of the terminal logs (slog.LevelInfo by default)
+func WithLogLevel(level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.level = level
+	}
+}
+
+// WithLogOutput sets the writer of the terminal logs (os.Stderr by default)
+func WithLogOutput(output io.Writer) LoggerOption {
+	return func(l *Logger) {
+		l.output = output
+	}
+}
+
+// WithoutTerminalLogs disables the terminal logs, only the log file (if any) is written
+func WithoutTerminalLogs() LoggerOption {
+	return func(l *Logger) {
+		l.output = nil
+	}
+}
+
+// WithLogFile mirrors the logs to a file (appended, created with its parent directories if needed)
+func WithLogFile(filePath string) LoggerOption {
+	return func(l *Logger) {
+		l.filePath = filePath
+	}
+}
+
+// WithLogFileLevel sets the minimum level of the file logs (the terminal level by default)
+func WithLogFileLevel(level slog.Level) LoggerOption {
+	return func(l *Logger) {
+		l.fileLevel = level
+		l.fileLevelSet = true
+	}
+}
+
+// WithJSONLogFile writes the log file in JSON (one object per line) instead of the slog text format
+func WithJSONLogFile() LoggerOption {
+	return func(l *Logger) {
+		l.jsonFile = true
+	}
+}
+
+// NewLogger creates a logger backed by log/slog
+func NewLogger(options ...LoggerOption) (*Logger, error) {
+	logger := &Logger{
+		level:  slog.LevelInfo,
+		output: os.Stderr,
+	}
+	// Apply all options
+	for _, option := range options {
+		option(logger)
+	}
+	if !logger.fileLevelSet {
+		logger.fileLevel = logger.level
+	}
+
+	handlers := []slog.Handler{}
+	if logger.output != nil {
+		handlers = append(handlers, &terminalLogHandler{
+			level:  logger.level,
+			output: logger.output,
+			mutex:  &sync.Mutex{},
+		})
+	}
+	if logger.filePath != "" {
+		if err := os.MkdirAll(filepath.Dir(logger.filePath), 0755); err != nil {
+			return nil, err
+		}
+		file, err := os.OpenFile(logger.filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		logger.file = file
+		handlerOptions := &slog.HandlerOptions{Level: logger.fileLevel}
+		if logger.jsonFile {
+			handlers = append(handlers, slog.NewJSONHandler(file, handlerOptions))
+		} else {
+			handlers = append(handlers, slog.NewTextHandler(file, handlerOptions))
+		}
+	}
+	logger.slogger = slog.New(multiLogHandler(handlers))
+	return logger, nil
+}
+
+// Slog returns the underlying slog logger
+func (l *Logger) Slog() *slog.Logger {
+	return l.slogger
+}
+
+// With returns a logger that adds the given attributes (key/value pairs) to every record
+func (l *Logger) With(args ...any) *Logger {
+	clone := *l
+	clone.slogger = l.slogger.With(args...)
+	return &clone
+}
+
+// Enabled returns true if the records of the level are logged (to the terminal or to the file)
+func (l *Logger) Enabled(ctx context.Context, level slog.Level) bool {
+	return l.slogger.Enabled(ctx, level)
+}
+
+// Debug logs a message at the debug level with optional key/value pairs
+func (l *Logger) Debug(msg string, args ...any) {
+	l.slogger.Debug(msg, args...)
+}
+
+// Info logs a message at the info level with optional key/value pairs
+func (l *Logger) Info(msg string, args ...any) {
+	l.slogger.Info(msg, args...)
+}
+
+// Warn logs a message at the warning level with optional key/value pairs
+func (l *Logger) Warn(msg string, args ...any) {
+	l.slogger.Warn(msg, args...)
+}
+
+// Error logs a message at the error level with optional key/value pairs
+func (l *Logger) Error(msg string, args ...any) {
+	l.slogger.Error(msg, args...)
+}
+
+// Close closes the log file (if any)
+func (l *Logger) Close() error {
+	if l.file != nil {
+		return l.file.Close()
+	}
+	return nil
+}
+
+var (
+	defaultLogger      *Logger
+	defaultLoggerMutex sync.RWMutex
+)
+
+// GetLogger returns the default logger (terminal logs at the info level unless SetLogger has been called)
+func GetLogger() *Logger {
+	defaultLoggerMutex.RLock()
+	logger := defaultLogger
+	defaultLoggerMutex.RUnlock()
+	if logger != nil {
+		return logger
+	}
+
+	defaultLoggerMutex.Lock()
+	defer defaultLoggerMutex.Unlock()
+	if defaultLogger == nil {
+		// Cannot fail without a log file
+		defaultLogger, _ = NewLogger()
+	}
+	return defaultLogger
+}
+
+// SetLogger replaces the default logger
+func SetLogger(logger *Logger) {
+	defaultLoggerMutex.Lock()
+	defer defaultLoggerMutex.Unlock()
+	defaultLogger = logger
+}
+
+// terminalLogHandler is a slog handler writing compact colored lines: "LEVEL message key=value ..."
+type terminalLogHandler struct {
+	level  slog.Level
+	output io.Writer
+	attrs  []slog.Attr
+	groups []string
+	mutex  *sync.Mutex
+}
+
+func (h *terminalLogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level
+}
+
+func (h *terminalLogHandler) Handle(_ context.Context, record slog.Record) error {
+	theme := GetTheme()
+	color := theme.Reasoning
+	switch {
+	case record.Level >= slog.LevelError:
+		color = theme.Error
+	case record.Level >= slog.LevelWarn:
+		color = theme.Warning
+	case record.Level >= slog.LevelInfo:
+		color = theme.Info
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%-5s %s", record.Level.String(), record.Message))
+	prefix := strings.Join(h.groups, ".")
+	writeAttr := func(attr slog.Attr) {
+		key := attr.Key
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		builder.WriteString(fmt.Sprintf(" %s=%v", key, attr.Value.Resolve()))
+	}
+	for _, attr := range h.attrs {
+		writeAttr(attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		writeAttr(attr)
+		return true
+	})
+
+	line := lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(builder.String())
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	_, err := fmt.Fprintln(h.output, line)
+	return err
+}
+
+func (h *terminalLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr{}, h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *terminalLogHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(append([]string{}, h.groups...), name)
+	return &clone
+}
+
+// multiLogHandler sends every record to several handlers (terminal and file)
+type multiLogHandler []slog.Handler
+
+func (m multiLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range m {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, handler := range m {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m multiLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiLogHandler, len(m))
+	for i, handler := range m {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return handlers
+}
+
+func (m multiLogHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiLogHandler, len(m))
+	for i, handler := range m {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return handlers
+}
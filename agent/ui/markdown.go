@@ -12,14 +12,20 @@ var markdownRenderer *glamour.TermRenderer
 // InitMarkdownRenderer initializes the global markdown renderer with terminal-optimized settings
 func InitMarkdownRenderer() error {
 	var err error
+	styleOption := glamour.WithAutoStyle()
+	if !ColorEnabled() {
+		// Plain text rendering, without ANSI codes
+		styleOption = glamour.WithStandardStyle("notty")
+	}
 	markdownRenderer, err = glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		styleOption,
 		glamour.WithWordWrap(200), // Increased width to prevent aggressive line breaking
 	)
 	return err
 }
 
 // RenderMarkdown converts markdown content to styled terminal output and prints it
+// (through the pager if it is enabled with EnablePager and the output is long)
 func RenderMarkdown(content string) error {
 	if markdownRenderer == nil {
 		if err := InitMarkdownRenderer(); err != nil {
@@ -32,6 +38,12 @@ func RenderMarkdown(content string) error {
 		return err
 	}
 
+	if shouldPage(rendered) {
+		if err := Page(rendered); err == nil {
+			return nil
+		}
+	}
+
 	fmt.Print(rendered)
 	return nil
 }
@@ -0,0 +1,97 @@
+package ui
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ErrInputCancelled is returned when the user cancels an input with Ctrl+C
+var ErrInputCancelled = errors.New("input cancelled")
+
+type multilineModel struct {
+	title     string
+	textArea  textarea.Model
+	cancelled bool
+}
+
+// initialMultilineModel creates a new text area model with soft-wrapping and unlimited length
+func initialMultilineModel(title, placeHolder string) multilineModel {
+	ta := textarea.New()
+	ta.Placeholder = placeHolder
+	ta.ShowLineNumbers = false
+	ta.CharLimit = 0 // no limit, to allow pasting large code blocks
+	ta.MaxHeight = 0
+	ta.SetWidth(80)
+	ta.SetHeight(6)
+	ta.Focus()
+
+	return multilineModel{
+		title:    title,
+		textArea: ta,
+	}
+}
+
+// Init initializes the model and returns the initial command
+func (m multilineModel) Init() tea.Cmd {
+	return textarea.Blink
+}
+
+// Update handles messages and updates the model state
+func (m multilineModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Soft-wrap the text at the terminal width
+		m.textArea.SetWidth(msg.Width - 2)
+	case tea.KeyMsg:
+		switch msg.Type {
+		case tea.KeyCtrlD, tea.KeyEsc:
+			return m, tea.Quit
+		case tea.KeyCtrlC:
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.textArea, cmd = m.textArea.Update(msg)
+	return m, cmd
+}
+
+// View renders the model as a string for display
+func (m multilineModel) View() string {
+	return promptStyle.Render(m.title) + "\n" +
+		m.textArea.View() + "\n" +
+		lipgloss.NewStyle().Foreground(lipgloss.Color(Gray)).Render("(Ctrl+D or Esc to submit, Ctrl+C to cancel)") + "\n"
+}
+
+// MultilineInput creates a colored multiline text area and returns the user's input.
+// Enter inserts a new line, Ctrl+D or Esc submits, Ctrl+C cancels (ErrInputCancelled).
+// Pasted content (code blocks, several paragraphs) is kept as is.
+func MultilineInput(color, title, placeHolder string) (string, error) {
+	promptStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+	p := tea.NewProgram(initialMultilineModel(title, placeHolder))
+	m, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+	if m, ok := m.(multilineModel); ok {
+		if m.cancelled {
+			return "", ErrInputCancelled
+		}
+		return strings.TrimSpace(m.textArea.Value()), nil
+	}
+	return "", errors.New("😡 unable to get input")
+}
+
+// MultilinePrompt creates an interactive multiline prompt and returns a parsed UserCommand
+func MultilinePrompt(promptTitle, placeHolder string) (*UserCommand, error) {
+	input, err := MultilineInput(White, promptTitle, placeHolder)
+	if err != nil {
+		return nil, err
+	}
+	return ParseUserCommand(input), nil
+}
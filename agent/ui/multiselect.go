@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/huh"
+)
+
+// MultiSelect displays an interactive checkbox list and returns the values of the selected options.
+// Space (or x) toggles an option, Ctrl+A selects/deselects all, / filters, Enter submits.
+// The options whose value is in preSelected are checked at start.
+// It returns ErrInputCancelled if the user presses Ctrl+C or Esc.
+func MultiSelect(title string, options []SelectOption, preSelected []string) ([]string, error) {
+	if len(options) == 0 {
+		return []string{}, nil
+	}
+
+	checked := make(map[string]bool)
+	for _, value := range preSelected {
+		checked[value] = true
+	}
+
+	huhOptions := make([]huh.Option[string], len(options))
+	for i, option := range options {
+		label := option.Label
+		if option.Description != "" {
+			label += " - " + option.Description
+		}
+		huhOptions[i] = huh.NewOption(label, option.Value).Selected(checked[option.Value])
+	}
+
+	var selected []string
+
+	height := len(options) + 2
+	if height > 20 {
+		height = 20
+	}
+
+	form := huh.NewForm(
+		huh.NewGroup(
+			huh.NewMultiSelect[string]().
+				Title(title).
+				Options(huhOptions...).
+				Height(height).
+				Value(&selected),
+		),
+	)
+
+	// Run the form
+	if err := form.Run(); err != nil {
+		if errors.Is(err, huh.ErrUserAborted) {
+			return nil, ErrInputCancelled
+		}
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+// MultiSelectStrings displays an interactive checkbox list for a list of strings and returns the selected subset
+func MultiSelectStrings(title string, choices []string, preSelected []string) ([]string, error) {
+	options := make([]SelectOption, len(choices))
+	for i, choice := range choices {
+		options[i] = SelectOption{Label: choice, Value: choice}
+	}
+	return MultiSelect(title, options, preSelected)
+}
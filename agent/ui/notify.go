@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// NotifyLevel is the level of a notification
+type NotifyLevel int
+
+const (
+	NotifyInfo NotifyLevel = iota
+	NotifySuccess
+	NotifyWarning
+	NotifyError
+)
+
+// notifyStyle returns the icon and the color of a notification level
+func notifyStyle(level NotifyLevel) (string, string) {
+	theme := GetTheme()
+	switch level {
+	case NotifySuccess:
+		return "✅", theme.Assistant
+	case NotifyWarning:
+		return "⚠️ ", theme.Warning
+	case NotifyError:
+		return "❌", theme.Error
+	default:
+		return "ℹ️ ", theme.Info
+	}
+}
+
+// renderNotification renders a notification as a colored badge followed by the message
+func renderNotification(level NotifyLevel, message string) string {
+	icon, color := notifyStyle(level)
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color(color)).
+		Bold(true).
+		Render(icon + " " + message)
+}
+
+// Notify prints a notification message with the icon and the color of its level
+func Notify(level NotifyLevel, message string) {
+	fmt.Println(renderNotification(level, message))
+}
+
+// Toast displays a transient notification on the current line and erases it after the duration
+// (it blocks until the notification disappears)
+func Toast(level NotifyLevel, message string, duration time.Duration) {
+	rendered := renderNotification(level, message)
+	fmt.Print("\r" + rendered)
+	time.Sleep(duration)
+	fmt.Print("\r" + strings.Repeat(" ", lipgloss.Width(rendered)) + "\r")
+}
+
+// Bell rings the terminal bell
+func Bell() {
+	fmt.Fprint(os.Stdout, "\a")
+}
+
+// DesktopNotify sends a desktop notification with notify-send (Linux), osascript (macOS) or PowerShell (Windows)
+func DesktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		script := fmt.Sprintf(
+			"[reflection.assembly]::loadwithpartialname('System.Windows.Forms') | Out-Null; "+
+				"$n = New-Object System.Windows.Forms.NotifyIcon; $n.Icon = [System.Drawing.SystemIcons]::Information; "+
+				"$n.Visible = $true; $n.ShowBalloonTip(5000, '%s', '%s', 'Info'); Start-Sleep -Seconds 5; $n.Dispose()",
+			strings.ReplaceAll(title, "'", "''"), strings.ReplaceAll(message, "'", "''"))
+		// The balloon must stay visible for a few seconds: don't wait for PowerShell
+		return exec.Command("powershell", "-NoProfile", "-Command", script).Start()
+	default:
+		cmd = exec.Command("notify-send", title, message)
+	}
+	return cmd.Run()
+}
+
+// CompletionNotifier tells the user that a long-running operation (generation, ingestion...) is finished,
+// with the terminal bell and/or a desktop notification, only if the operation lasted at least MinDuration
+type CompletionNotifier struct {
+	Bell        bool
+	Desktop     bool
+	MinDuration time.Duration
+}
+
+// Notify sends the notification if the elapsed time of the operation is at least MinDuration
+func (n CompletionNotifier) Notify(title, message string, elapsed time.Duration) {
+	if elapsed < n.MinDuration {
+		return
+	}
+	if n.Bell {
+		Bell()
+	}
+	if n.Desktop {
+		// The desktop notification is best effort (the notification tool may be missing)
+		_ = DesktopNotify(title, message)
+	}
+}
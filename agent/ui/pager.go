@@ -0,0 +1,123 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+)
+
+var (
+	pagerMutex     sync.RWMutex
+	pagerEnabled   bool
+	pagerThreshold int
+)
+
+// EnablePager routes the long outputs of PrintMarkdown through the built-in pager.
+// The pager is used when the rendered output has more lines than threshold;
+// a threshold <= 0 uses the height of the terminal.
+// The pager is never used when the standard output is not a terminal.
+func EnablePager(threshold int) {
+	pagerMutex.Lock()
+	defer pagerMutex.Unlock()
+	pagerEnabled = true
+	pagerThreshold = threshold
+}
+
+// DisablePager prints every output directly (default)
+func DisablePager() {
+	pagerMutex.Lock()
+	defer pagerMutex.Unlock()
+	pagerEnabled = false
+}
+
+// shouldPage returns true if the content must be displayed with the pager
+func shouldPage(content string) bool {
+	pagerMutex.RLock()
+	enabled, threshold := pagerEnabled, pagerThreshold
+	pagerMutex.RUnlock()
+	if !enabled || !term.IsTerminal(os.Stdout.Fd()) {
+		return false
+	}
+	if threshold <= 0 {
+		_, height, err := term.GetSize(os.Stdout.Fd())
+		if err != nil {
+			return false
+		}
+		threshold = height
+	}
+	return strings.Count(content, "\n") > threshold
+}
+
+var (
+	pagerStatusStyle = lipgloss.NewStyle().Foreground(lipgloss.Color(Gray))
+	pagerHelp        = "↑/↓ j/k scroll • space/b page • g/G top/bottom • q quit"
+)
+
+// pagerModel is a scrollable viewport over the content
+type pagerModel struct {
+	content  string
+	viewport viewport.Model
+	ready    bool
+}
+
+// Init initializes the model and returns the initial command
+func (m pagerModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles the scrolling keys and the window resizing
+func (m pagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		// Keep one line for the status bar
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, msg.Height-1)
+			m.viewport.SetContent(m.content)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = msg.Height - 1
+		}
+		return m, nil
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "g", "home":
+			m.viewport.GotoTop()
+			return m, nil
+		case "G", "end":
+			m.viewport.GotoBottom()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the visible part of the content and the status bar
+func (m pagerModel) View() string {
+	if !m.ready {
+		return ""
+	}
+	status := fmt.Sprintf("%3.0f%% • %s", m.viewport.ScrollPercent()*100, pagerHelp)
+	return m.viewport.View() + "\n" + pagerStatusStyle.Render(status)
+}
+
+// Page displays the content in a less-like scrollable viewport (alternate screen) until the user quits with q or Esc
+func Page(content string) error {
+	p := tea.NewProgram(
+		pagerModel{content: content},
+		tea.WithAltScreen(),
+		tea.WithMouseCellMotion(),
+	)
+	_, err := p.Run()
+	return err
+}
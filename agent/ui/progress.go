@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ProgressBar displays the progress of a long operation (RAG ingestion, embedding batches, MCP progress notifications...).
+// It is determinate when the total is known, with a percentage and an ETA, and indeterminate otherwise.
+type ProgressBar struct {
+	mutex     sync.Mutex
+	color     string
+	label     string
+	current   int
+	total     int
+	startTime time.Time
+	bar       progress.Model
+	width     int
+	stopChan  chan struct{}
+	doneChan  chan struct{}
+	running   bool
+	animated  bool // false when the terminal can't be animated: a plain line is printed every 10%
+	lastStep  int
+}
+
+// NewProgressBar creates a progress bar with the specified color and label.
+// A total <= 0 creates an indeterminate progress bar (the total can be set later with Update).
+func NewProgressBar(color string, label string, total int) *ProgressBar {
+	width := 40
+	return &ProgressBar{
+		color: color,
+		label: label,
+		total: total,
+		width: width,
+		bar: progress.New(
+			progress.WithSolidFill(color),
+			progress.WithWidth(width),
+			progress.WithoutPercentage(),
+		),
+	}
+}
+
+// Start begins rendering the progress bar on the current line
+func (pb *ProgressBar) Start() {
+	pb.mutex.Lock()
+	if pb.running {
+		pb.mutex.Unlock()
+		return
+	}
+	pb.running = true
+	pb.startTime = time.Now()
+	pb.animated = AnimationEnabled()
+	if !pb.animated {
+		pb.lastStep = -1
+		pb.printPlain()
+		pb.mutex.Unlock()
+		return
+	}
+	pb.stopChan = make(chan struct{})
+	pb.doneChan = make(chan struct{})
+	pb.mutex.Unlock()
+
+	go func() {
+		defer close(pb.doneChan)
+
+		ticker := time.NewTicker(100 * time.Millisecond)
+		defer ticker.Stop()
+
+		frame := 0
+		for {
+			select {
+			case <-pb.stopChan:
+				return
+			case <-ticker.C:
+				pb.mutex.Lock()
+				line := pb.render(frame)
+				pb.mutex.Unlock()
+				fmt.Print("\r" + line)
+				frame++
+			}
+		}
+	}()
+}
+
+// Update sets the current progress and the total (a total <= 0 makes the progress bar indeterminate)
+func (pb *ProgressBar) Update(current, total int) {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	pb.current = current
+	pb.total = total
+	pb.printPlain()
+}
+
+// Increment adds one to the current progress
+func (pb *ProgressBar) Increment() {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	pb.current++
+	pb.printPlain()
+}
+
+// SetLabel updates the label displayed before the progress bar
+func (pb *ProgressBar) SetLabel(label string) {
+	pb.mutex.Lock()
+	defer pb.mutex.Unlock()
+	pb.label = label
+}
+
+// Done stops the rendering, prints the final state of the progress bar and moves to the next line
+func (pb *ProgressBar) Done() {
+	pb.mutex.Lock()
+	if !pb.running {
+		pb.mutex.Unlock()
+		return
+	}
+	if !pb.animated {
+		if pb.total > 0 {
+			pb.current = pb.total
+		} else {
+			// The indeterminate progress is printed at the start and at the end
+			pb.lastStep = -1
+		}
+		pb.printPlain()
+		pb.running = false
+		pb.mutex.Unlock()
+		return
+	}
+	pb.running = false
+	close(pb.stopChan)
+	pb.mutex.Unlock()
+	<-pb.doneChan
+
+	pb.mutex.Lock()
+	if pb.total > 0 {
+		pb.current = pb.total
+	}
+	line := pb.render(0)
+	pb.mutex.Unlock()
+	fmt.Println("\r" + line)
+}
+
+// printPlain prints the progress on a new line without animation when it has progressed by 10% since the last print,
+// must be called with the mutex held
+func (pb *ProgressBar) printPlain() {
+	if pb.animated || !pb.running {
+		return
+	}
+	elapsed := formatDuration(time.Since(pb.startTime))
+	if pb.total <= 0 {
+		if pb.lastStep < 0 {
+			pb.lastStep = 0
+			fmt.Printf("%s %d (%s)\n", pb.label, pb.current, elapsed)
+		}
+		return
+	}
+	percent := min(float64(pb.current)/float64(pb.total), 1)
+	step := int(percent * 10)
+	if step <= pb.lastStep {
+		return
+	}
+	pb.lastStep = step
+	fmt.Printf("%s %3.0f%% %d/%d (%s)\n", pb.label, percent*100, pb.current, pb.total, elapsed)
+}
+
+// render builds the progress bar line, must be called with the mutex held
+func (pb *ProgressBar) render(frame int) string {
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(pb.color))
+	elapsed := time.Since(pb.startTime)
+
+	if pb.total <= 0 {
+		// Indeterminate: a block bouncing from one side to the other
+		blockWidth := pb.width / 5
+		span := pb.width - blockWidth
+		position := frame % (2 * span)
+		if position > span {
+			position = 2*span - position
+		}
+		bar := strings.Repeat("░", position) + strings.Repeat("█", blockWidth) + strings.Repeat("░", span-position)
+		return textStyle.Render(fmt.Sprintf("%s %s %d (%s)", pb.label, bar, pb.current, formatDuration(elapsed))) + "\033[K"
+	}
+
+	percent := float64(pb.current) / float64(pb.total)
+	if percent > 1 {
+		percent = 1
+	}
+
+	eta := "--"
+	if pb.current > 0 && pb.current < pb.total {
+		remaining := time.Duration(float64(elapsed) / float64(pb.current) * float64(pb.total-pb.current))
+		eta = formatDuration(remaining)
+	} else if pb.current >= pb.total {
+		eta = "0s"
+	}
+
+	return textStyle.Render(pb.label) + " " + pb.bar.ViewAs(percent) +
+		textStyle.Render(fmt.Sprintf(" %3.0f%% %d/%d ETA %s", percent*100, pb.current, pb.total, eta)) + "\033[K"
+}
+
+// formatDuration formats a duration rounded to the second (or tenth of a second under one second)
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(100 * time.Millisecond).String()
+	}
+	return d.Round(time.Second).String()
+}
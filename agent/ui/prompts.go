@@ -16,30 +16,6 @@ type UserCommand struct {
 // SimplePrompt creates an interactive text input prompt and returns a parsed UserCommand
 func SimplePrompt(promptTitle, placeHolder string) (*UserCommand, error) {
 
-	parseCommand := func(input string) *UserCommand {
-		// Trim whitespace
-		input = strings.TrimSpace(input)
-		// Check for empty input
-		if input == "" {
-			return &UserCommand{
-				Input:      "",
-				SkipTools:  false,
-				ShouldExit: false,
-			}
-		}
-		// Check for /bye command
-		if input == "/bye" {
-			return &UserCommand{
-				Input:      input,
-				ShouldExit: true,
-			}
-		}
-
-		return &UserCommand{
-			Input:      input,
-			ShouldExit: false,
-		}
-	}
 	// Create a new form with a single text input field
 	var userInput string
 
@@ -59,5 +35,31 @@ func SimplePrompt(promptTitle, placeHolder string) (*UserCommand, error) {
 	}
 
 	// Parse the command
-	return parseCommand(userInput), nil
+	return ParseUserCommand(userInput), nil
+}
+
+// ParseUserCommand parses the raw user input into a UserCommand
+func ParseUserCommand(input string) *UserCommand {
+	// Trim whitespace
+	input = strings.TrimSpace(input)
+	// Check for empty input
+	if input == "" {
+		return &UserCommand{
+			Input:      "",
+			SkipTools:  false,
+			ShouldExit: false,
+		}
+	}
+	// Check for /bye command
+	if input == "/bye" {
+		return &UserCommand{
+			Input:      input,
+			ShouldExit: true,
+		}
+	}
+
+	return &UserCommand{
+		Input:      input,
+		ShouldExit: false,
+	}
 }
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// secretModel is a text input that masks the typed characters
+type secretModel struct {
+	textInput textinput.Model
+	style     lipgloss.Style
+	cancelled bool
+}
+
+// Init initializes the model and returns the initial command
+func (m secretModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update handles messages and updates the model state
+func (m secretModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyEnter:
+			return m, tea.Quit
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+// View renders the prompt with a mask character for each typed character
+func (m secretModel) View() string {
+	return m.style.Render(m.textInput.View() + "\n")
+}
+
+// SecretInput prompts the user for a secret (API key, bearer token, password...) without echoing it.
+// Each typed character is displayed as "•" and the value is never added to the prompt history.
+// It returns ErrInputCancelled if the user presses Ctrl+C or Esc.
+func SecretInput(color, prompt string) (string, error) {
+	ti := textinput.New()
+	ti.Focus()
+	ti.Prompt = prompt
+	ti.EchoMode = textinput.EchoPassword
+	ti.EchoCharacter = '•'
+	ti.CharLimit = 0 // tokens can be long
+	ti.Width = 80
+
+	p := tea.NewProgram(secretModel{
+		textInput: ti,
+		style:     lipgloss.NewStyle().Foreground(lipgloss.Color(color)),
+	})
+	m, err := p.Run()
+	if err != nil {
+		return "", err
+	}
+	result, ok := m.(secretModel)
+	if !ok {
+		return "", fmt.Errorf("😡 unable to get input")
+	}
+	if result.cancelled {
+		return "", ErrInputCancelled
+	}
+	return result.textInput.Value(), nil
+}
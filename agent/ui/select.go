@@ -0,0 +1,135 @@
+package ui
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SelectOption represents an option of a selection menu
+type SelectOption struct {
+	Label       string
+	Description string
+	Value       string
+}
+
+// selectItem wraps a SelectOption to implement list.DefaultItem
+type selectItem struct {
+	option SelectOption
+}
+
+func (i selectItem) Title() string       { return i.option.Label }
+func (i selectItem) Description() string { return i.option.Description }
+func (i selectItem) FilterValue() string { return i.option.Label }
+
+type selectModel struct {
+	list      list.Model
+	selected  *SelectOption
+	cancelled bool
+}
+
+// Init initializes the model and returns the initial command
+func (m selectModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages and updates the model state
+func (m selectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetWidth(msg.Width)
+	case tea.KeyMsg:
+		// Do not intercept the keys while the user is typing a filter
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		switch msg.Type {
+		case tea.KeyEnter:
+			if item, ok := m.list.SelectedItem().(selectItem); ok {
+				m.selected = &item.option
+			}
+			return m, tea.Quit
+		case tea.KeyCtrlC, tea.KeyEsc:
+			m.cancelled = true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// View renders the model as a string for display
+func (m selectModel) View() string {
+	return m.list.View()
+}
+
+// Select displays an interactive selection menu (arrow keys to move, / to filter, enter to select)
+// and returns the value of the selected option. The cursor starts on defaultValue if it matches an option.
+// It returns ErrInputCancelled if the user presses Ctrl+C or Esc.
+func Select(color string, title string, options []SelectOption, defaultValue string) (string, error) {
+	if len(options) == 0 {
+		return "", errors.New("no options to select from")
+	}
+
+	items := make([]list.Item, len(options))
+	defaultIndex := 0
+	withDescription := false
+	for i, option := range options {
+		items[i] = selectItem{option: option}
+		if option.Value == defaultValue {
+			defaultIndex = i
+		}
+		if option.Description != "" {
+			withDescription = true
+		}
+	}
+
+	delegate := list.NewDefaultDelegate()
+	delegate.ShowDescription = withDescription
+	if !withDescription {
+		delegate.SetSpacing(0)
+	}
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(lipgloss.Color(color)).
+		BorderForeground(lipgloss.Color(color))
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(lipgloss.Color(color)).
+		BorderForeground(lipgloss.Color(color))
+
+	height := len(options)*(delegate.Height()+delegate.Spacing()) + 6
+	if height > 20 {
+		height = 20
+	}
+
+	l := list.New(items, delegate, 80, height)
+	l.Title = title
+	l.Styles.Title = lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Bold(true)
+	l.SetShowStatusBar(false)
+	l.DisableQuitKeybindings()
+	l.Select(defaultIndex)
+
+	m, err := tea.NewProgram(selectModel{list: l}).Run()
+	if err != nil {
+		return "", err
+	}
+	if m, ok := m.(selectModel); ok {
+		if m.cancelled || m.selected == nil {
+			return "", ErrInputCancelled
+		}
+		return m.selected.Value, nil
+	}
+	return "", errors.New("😡 unable to get selection")
+}
+
+// SelectString displays an interactive selection menu for a list of strings and returns the selected one
+func SelectString(color string, title string, choices []string, defaultChoice string) (string, error) {
+	options := make([]SelectOption, len(choices))
+	for i, choice := range choices {
+		options[i] = SelectOption{Label: choice, Value: choice}
+	}
+	return Select(color, title, options, defaultChoice)
+}
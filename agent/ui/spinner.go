@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Spinner describes the frames of a spinner animation and the delay between two frames
+type Spinner struct {
+	Frames   []string
+	Interval time.Duration
+}
+
+// Spinner presets
+var (
+	SpinnerBraille = Spinner{
+		Frames:   []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"},
+		Interval: 100 * time.Millisecond,
+	}
+	SpinnerDots = Spinner{
+		Frames:   []string{"⣾", "⣽", "⣻", "⢿", "⡿", "⣟", "⣯", "⣷"},
+		Interval: 100 * time.Millisecond,
+	}
+	SpinnerLine = Spinner{
+		Frames:   []string{"-", "\\", "|", "/"},
+		Interval: 100 * time.Millisecond,
+	}
+	SpinnerCircle = Spinner{
+		Frames:   []string{"◐", "◓", "◑", "◒"},
+		Interval: 120 * time.Millisecond,
+	}
+	SpinnerArrow = Spinner{
+		Frames:   []string{"←", "↖", "↑", "↗", "→", "↘", "↓", "↙"},
+		Interval: 100 * time.Millisecond,
+	}
+	SpinnerMoon = Spinner{
+		Frames:   []string{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"},
+		Interval: 120 * time.Millisecond,
+	}
+	SpinnerBouncingBar = Spinner{
+		Frames:   []string{"[    ]", "[=   ]", "[==  ]", "[=== ]", "[ ===]", "[  ==]", "[   =]", "[    ]", "[   =]", "[  ==]", "[ ===]", "[====]", "[=== ]", "[==  ]", "[=   ]"},
+		Interval: 80 * time.Millisecond,
+	}
+)
+
+// SpinnerPresets gives access to the spinner presets by name
+var SpinnerPresets = map[string]Spinner{
+	"braille":     SpinnerBraille,
+	"dots":        SpinnerDots,
+	"line":        SpinnerLine,
+	"circle":      SpinnerCircle,
+	"arrow":       SpinnerArrow,
+	"moon":        SpinnerMoon,
+	"bouncingbar": SpinnerBouncingBar,
+}
+
+// NewSpinner creates a custom spinner with the given frames and interval between frames
+func NewSpinner(frames []string, interval time.Duration) Spinner {
+	return Spinner{
+		Frames:   frames,
+		Interval: interval,
+	}
+}
+
+// formatElapsed formats an elapsed duration as "(1.2s)"
+func formatElapsed(elapsed time.Duration) string {
+	return fmt.Sprintf("(%.1fs)", elapsed.Seconds())
+}
+
+// spinnerLine is a labeled line of a MultiSpinner
+type spinnerLine struct {
+	id        string
+	color     string
+	message   string
+	startTime time.Time
+	done      bool
+	doneMark  string
+	endTime   time.Time
+}
+
+// MultiSpinner displays several labeled spinner lines at once, one per concurrent operation
+// (e.g. a tool call and a stream completion)
+type MultiSpinner struct {
+	mutex       sync.Mutex
+	spinner     Spinner
+	showElapsed bool
+	lines       []*spinnerLine
+	renderedLen int // number of lines printed by the previous render
+	stopChan    chan struct{}
+	doneChan    chan struct{}
+	running     bool
+	animated    bool // false when the terminal can't be animated: each change is printed on a new line
+}
+
+// NewMultiSpinner creates a multi-spinner manager using the given spinner for every line
+func NewMultiSpinner(spinner Spinner, showElapsed bool) *MultiSpinner {
+	if len(spinner.Frames) == 0 {
+		spinner = defaultSpinner()
+	}
+	if spinner.Interval <= 0 {
+		spinner.Interval = 100 * time.Millisecond
+	}
+	return &MultiSpinner{
+		spinner:     spinner,
+		showElapsed: showElapsed,
+	}
+}
+
+// Add adds (or restarts) a labeled spinner line identified by id
+func (ms *MultiSpinner) Add(id string, color string, message string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, line := range ms.lines {
+		if line.id == id {
+			line.color = color
+			line.message = message
+			line.startTime = time.Now()
+			line.done = false
+			return
+		}
+	}
+	line := &spinnerLine{
+		id:        id,
+		color:     color,
+		message:   message,
+		startTime: time.Now(),
+	}
+	ms.lines = append(ms.lines, line)
+	if ms.running && !ms.animated {
+		ms.printLine(line)
+	}
+}
+
+// UpdateMessage updates the message of the line identified by id
+func (ms *MultiSpinner) UpdateMessage(id string, message string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, line := range ms.lines {
+		if line.id == id {
+			line.message = message
+			return
+		}
+	}
+}
+
+// Done stops the animation of the line identified by id and displays the mark (e.g. "✅") with the final message
+func (ms *MultiSpinner) Done(id string, mark string, message string) {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+	for _, line := range ms.lines {
+		if line.id == id {
+			line.done = true
+			line.doneMark = mark
+			line.message = message
+			line.endTime = time.Now()
+			if ms.running && !ms.animated {
+				ms.printLine(line)
+			}
+			return
+		}
+	}
+}
+
+// Start begins rendering the spinner lines
+func (ms *MultiSpinner) Start() {
+	ms.mutex.Lock()
+	if ms.running {
+		ms.mutex.Unlock()
+		return
+	}
+	ms.running = true
+	ms.animated = AnimationEnabled()
+	if !ms.animated {
+		for _, line := range ms.lines {
+			ms.printLine(line)
+		}
+		ms.mutex.Unlock()
+		return
+	}
+	ms.stopChan = make(chan struct{})
+	ms.doneChan = make(chan struct{})
+	ms.mutex.Unlock()
+
+	go func() {
+		defer close(ms.doneChan)
+
+		ticker := time.NewTicker(ms.spinner.Interval)
+		defer ticker.Stop()
+
+		frame := 0
+		for {
+			select {
+			case <-ms.stopChan:
+				return
+			case <-ticker.C:
+				ms.mutex.Lock()
+				ms.render(frame)
+				ms.mutex.Unlock()
+				frame++
+			}
+		}
+	}()
+}
+
+// Stop stops the rendering and leaves the last state of the lines on the terminal
+func (ms *MultiSpinner) Stop() {
+	ms.mutex.Lock()
+	if !ms.running {
+		ms.mutex.Unlock()
+		return
+	}
+	ms.running = false
+	if !ms.animated {
+		ms.mutex.Unlock()
+		return
+	}
+	close(ms.stopChan)
+	ms.mutex.Unlock()
+	<-ms.doneChan
+
+	ms.mutex.Lock()
+	ms.render(0)
+	ms.renderedLen = 0
+	ms.mutex.Unlock()
+}
+
+// printLine prints the current state of a line without animation, must be called with the mutex held
+func (ms *MultiSpinner) printLine(line *spinnerLine) {
+	textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(line.color))
+	text := "- " + line.message
+	if line.done {
+		text = line.doneMark + " " + line.message
+		if ms.showElapsed {
+			text += " " + formatElapsed(line.endTime.Sub(line.startTime))
+		}
+	}
+	fmt.Println(textStyle.Render(text))
+}
+
+// render redraws every line, must be called with the mutex held
+func (ms *MultiSpinner) render(frame int) {
+	var builder strings.Builder
+	// Move the cursor back to the first line of the previous render
+	if ms.renderedLen > 0 {
+		builder.WriteString(fmt.Sprintf("\033[%dA", ms.renderedLen))
+	}
+	for _, line := range ms.lines {
+		textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(line.color))
+		mark := ms.spinner.Frames[frame%len(ms.spinner.Frames)]
+		elapsed := time.Since(line.startTime)
+		if line.done {
+			mark = line.doneMark
+			elapsed = line.endTime.Sub(line.startTime)
+		}
+		text := mark + " " + line.message
+		if ms.showElapsed {
+			text += " " + formatElapsed(elapsed)
+		}
+		builder.WriteString("\r" + textStyle.Render(text) + "\033[K\n")
+	}
+	ms.renderedLen = len(ms.lines)
+	fmt.Print(builder.String())
+}
@@ -0,0 +1,187 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
+)
+
+// StatusEvent updates one or several fields of a StatusBar, the zero values are ignored
+type StatusEvent struct {
+	Model        string
+	UsedTokens   int     // tokens of the current context
+	ContextSize  int     // maximum number of tokens of the model context
+	Cost         float64 // added to the session cost
+	MCPConnected *bool
+}
+
+// StatusBar is a persistent line rendered at the bottom of the terminal during an interactive session.
+// It shows the current model, the context usage, the session cost and the MCP connection state.
+type StatusBar struct {
+	mutex        sync.Mutex
+	color        string
+	model        string
+	usedTokens   int
+	contextSize  int
+	cost         float64
+	mcpConnected *bool
+	visible      bool
+	height       int // height of the terminal when the scrolling region was set
+}
+
+// NewStatusBar creates a status bar rendered with the specified color
+func NewStatusBar(color string) *StatusBar {
+	return &StatusBar{color: color}
+}
+
+// SetModel sets the model displayed in the status bar
+func (sb *StatusBar) SetModel(model string) {
+	sb.Apply(StatusEvent{Model: model})
+}
+
+// SetTokenUsage sets the number of tokens of the current context and the size of the model context
+func (sb *StatusBar) SetTokenUsage(usedTokens, contextSize int) {
+	sb.mutex.Lock()
+	sb.usedTokens = usedTokens
+	sb.contextSize = contextSize
+	sb.mutex.Unlock()
+	sb.Refresh()
+}
+
+// AddCost adds the cost of a completion to the session cost
+func (sb *StatusBar) AddCost(cost float64) {
+	sb.Apply(StatusEvent{Cost: cost})
+}
+
+// SetMCPConnected sets the MCP connection state
+func (sb *StatusBar) SetMCPConnected(connected bool) {
+	sb.Apply(StatusEvent{MCPConnected: &connected})
+}
+
+// Apply updates the status bar with an event and redraws it if it is visible
+func (sb *StatusBar) Apply(event StatusEvent) {
+	sb.mutex.Lock()
+	if event.Model != "" {
+		sb.model = event.Model
+	}
+	if event.UsedTokens > 0 {
+		sb.usedTokens = event.UsedTokens
+	}
+	if event.ContextSize > 0 {
+		sb.contextSize = event.ContextSize
+	}
+	sb.cost += event.Cost
+	if event.MCPConnected != nil {
+		connected := *event.MCPConnected
+		sb.mcpConnected = &connected
+	}
+	sb.mutex.Unlock()
+	sb.Refresh()
+}
+
+// Listen applies the events received on the channel until it is closed (run it in a goroutine)
+func (sb *StatusBar) Listen(events <-chan StatusEvent) {
+	for event := range events {
+		sb.Apply(event)
+	}
+}
+
+// Render returns the content of the status bar truncated to the given width
+func (sb *StatusBar) Render(width int) string {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	return sb.render(width)
+}
+
+// render builds the status line, must be called with the mutex held
+func (sb *StatusBar) render(width int) string {
+	parts := []string{}
+
+	model := sb.model
+	if model == "" {
+		model = "--"
+	}
+	parts = append(parts, "🧠 "+model)
+
+	if sb.contextSize > 0 {
+		percent := float64(sb.usedTokens) / float64(sb.contextSize) * 100
+		parts = append(parts, fmt.Sprintf("ctx %d/%d (%.0f%%)", sb.usedTokens, sb.contextSize, percent))
+	} else if sb.usedTokens > 0 {
+		parts = append(parts, fmt.Sprintf("ctx %d tokens", sb.usedTokens))
+	} else {
+		parts = append(parts, "ctx --")
+	}
+
+	parts = append(parts, fmt.Sprintf("$%.4f", sb.cost))
+
+	switch {
+	case sb.mcpConnected == nil:
+		parts = append(parts, "MCP --")
+	case *sb.mcpConnected:
+		parts = append(parts, "MCP 🟢")
+	default:
+		parts = append(parts, "MCP 🔴")
+	}
+
+	line := strings.Join(parts, " │ ")
+	if width > 0 {
+		line = ansi.Truncate(line, width, "…")
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(sb.color)).Reverse(true).Render(line)
+}
+
+// Show reserves the last line of the terminal (the scrolling region stops above it) and draws the status bar.
+// It does nothing when the standard output is not a terminal.
+func (sb *StatusBar) Show() {
+	fd := os.Stdout.Fd()
+	if !term.IsTerminal(fd) {
+		return
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || height < 2 {
+		return
+	}
+	sb.mutex.Lock()
+	sb.visible = true
+	sb.height = height
+	// Keep the cursor inside the new scrolling region
+	fmt.Printf("\n\033[1;%dr\033[%d;1H", height-1, height-1)
+	sb.mutex.Unlock()
+	sb.Refresh()
+}
+
+// Refresh redraws the status bar (if it is visible) without moving the cursor
+func (sb *StatusBar) Refresh() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	if !sb.visible {
+		return
+	}
+	width, height, err := term.GetSize(os.Stdout.Fd())
+	if err != nil {
+		return
+	}
+	if height != sb.height {
+		// The terminal has been resized: move the scrolling region
+		sb.height = height
+		fmt.Printf("\0337\033[1;%dr\0338", height-1)
+	}
+	// Save the cursor, draw on the last line, restore the cursor
+	fmt.Printf("\0337\033[%d;1H\033[2K%s\0338", height, sb.render(width))
+}
+
+// Hide removes the status bar and restores the full scrolling region
+func (sb *StatusBar) Hide() {
+	sb.mutex.Lock()
+	defer sb.mutex.Unlock()
+	if !sb.visible {
+		return
+	}
+	sb.visible = false
+	fmt.Printf("\0337\033[r\033[%d;1H\033[2K\0338", sb.height)
+}
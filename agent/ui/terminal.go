@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// TerminalCapabilities describes what the terminal can display
+type TerminalCapabilities struct {
+	ANSI      bool // cursor moves and line clearing (escape sequences)
+	Unicode   bool // braille, emoji and box-drawing characters
+	Animation bool // spinners and progress bars redrawn in place
+}
+
+var (
+	terminalCapabilities TerminalCapabilities
+	terminalMutex        sync.RWMutex
+)
+
+// DetectTerminalCapabilities detects the capabilities of the terminal attached to the standard output.
+// On Windows, it enables the virtual terminal processing of the console; legacy consoles that don't support it
+// get plain text output without animation.
+// UI_ANIMATION=false disables the animations and UI_ANIMATION=true forces them.
+func DetectTerminalCapabilities() TerminalCapabilities {
+	fd := os.Stdout.Fd()
+	isTerminal := isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+
+	capabilities := TerminalCapabilities{
+		ANSI:    isTerminal && os.Getenv("TERM") != "dumb",
+		Unicode: true,
+	}
+
+	if runtime.GOOS == "windows" {
+		if isTerminal {
+			if _, err := termenv.EnableVirtualTerminalProcessing(termenv.NewOutput(os.Stdout)); err != nil {
+				capabilities.ANSI = false
+			}
+		}
+		// The legacy console fonts don't have the braille and emoji glyphs,
+		// Windows Terminal and VS Code do
+		capabilities.Unicode = os.Getenv("WT_SESSION") != "" || os.Getenv("TERM_PROGRAM") != ""
+	}
+
+	capabilities.Animation = capabilities.ANSI
+	switch strings.ToLower(os.Getenv("UI_ANIMATION")) {
+	case "false", "0", "off":
+		capabilities.Animation = false
+	case "true", "1", "on":
+		capabilities.Animation = true
+	}
+	return capabilities
+}
+
+// GetTerminalCapabilities returns the detected (or set) terminal capabilities
+func GetTerminalCapabilities() TerminalCapabilities {
+	terminalMutex.RLock()
+	defer terminalMutex.RUnlock()
+	return terminalCapabilities
+}
+
+// SetTerminalCapabilities overrides the detected terminal capabilities
+func SetTerminalCapabilities(capabilities TerminalCapabilities) {
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+	terminalCapabilities = capabilities
+}
+
+// AnimationEnabled returns true if the spinners and progress bars can be animated,
+// otherwise they fall back to plain text lines
+func AnimationEnabled() bool {
+	return GetTerminalCapabilities().Animation
+}
+
+// defaultSpinner returns SpinnerBraille, or SpinnerLine if the terminal can't display unicode characters
+func defaultSpinner() Spinner {
+	if !GetTerminalCapabilities().Unicode {
+		return SpinnerLine
+	}
+	return SpinnerBraille
+}
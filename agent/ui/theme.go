@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-isatty"
+	"github.com/muesli/termenv"
+)
+
+// Theme holds the colors used for each role of the conversation
+type Theme struct {
+	User      string
+	Assistant string
+	Reasoning string
+	Tool      string
+	Error     string
+	Warning   string
+	Info      string
+}
+
+// Theme presets
+var (
+	DarkTheme = Theme{
+		User:      Cyan,
+		Assistant: Green,
+		Reasoning: Gray,
+		Tool:      Magenta,
+		Error:     Red,
+		Warning:   Orange,
+		Info:      Blue,
+	}
+	LightTheme = Theme{
+		User:      "#005F87",
+		Assistant: "#005F00",
+		Reasoning: "#6C6C6C",
+		Tool:      "#870087",
+		Error:     "#AF0000",
+		Warning:   "#AF5F00",
+		Info:      "#0000AF",
+	}
+)
+
+// ThemePresets gives access to the theme presets by name
+var ThemePresets = map[string]Theme{
+	"dark":  DarkTheme,
+	"light": LightTheme,
+}
+
+var (
+	currentTheme = DarkTheme
+	themeMutex   sync.RWMutex
+	colorEnabled = true
+)
+
+func init() {
+	LoadThemeFromEnv()
+	SetTerminalCapabilities(DetectTerminalCapabilities())
+	if !DetectColorSupport() {
+		DisableColors()
+	}
+}
+
+// GetTheme returns the current theme
+func GetTheme() Theme {
+	themeMutex.RLock()
+	defer themeMutex.RUnlock()
+	return currentTheme
+}
+
+// SetTheme sets the current theme, the empty colors of the theme are taken from DarkTheme
+func SetTheme(theme Theme) {
+	themeMutex.Lock()
+	defer themeMutex.Unlock()
+	currentTheme = mergeTheme(DarkTheme, theme)
+}
+
+// LoadThemeFromEnv sets the current theme from the environment:
+//   - UI_THEME: name of a theme preset ("dark" or "light")
+//   - UI_COLOR_USER, UI_COLOR_ASSISTANT, UI_COLOR_REASONING, UI_COLOR_TOOL,
+//     UI_COLOR_ERROR, UI_COLOR_WARNING, UI_COLOR_INFO: override the color of a role (e.g. "#FF00FF")
+func LoadThemeFromEnv() {
+	theme := DarkTheme
+	if preset, ok := ThemePresets[strings.ToLower(os.Getenv("UI_THEME"))]; ok {
+		theme = preset
+	}
+	theme = mergeTheme(theme, Theme{
+		User:      os.Getenv("UI_COLOR_USER"),
+		Assistant: os.Getenv("UI_COLOR_ASSISTANT"),
+		Reasoning: os.Getenv("UI_COLOR_REASONING"),
+		Tool:      os.Getenv("UI_COLOR_TOOL"),
+		Error:     os.Getenv("UI_COLOR_ERROR"),
+		Warning:   os.Getenv("UI_COLOR_WARNING"),
+		Info:      os.Getenv("UI_COLOR_INFO"),
+	})
+	SetTheme(theme)
+}
+
+// mergeTheme returns base with the non-empty colors of override
+func mergeTheme(base, override Theme) Theme {
+	pick := func(baseColor, overrideColor string) string {
+		if overrideColor != "" {
+			return overrideColor
+		}
+		return baseColor
+	}
+	return Theme{
+		User:      pick(base.User, override.User),
+		Assistant: pick(base.Assistant, override.Assistant),
+		Reasoning: pick(base.Reasoning, override.Reasoning),
+		Tool:      pick(base.Tool, override.Tool),
+		Error:     pick(base.Error, override.Error),
+		Warning:   pick(base.Warning, override.Warning),
+		Info:      pick(base.Info, override.Info),
+	}
+}
+
+// DetectColorSupport returns false if the NO_COLOR environment variable is set (https://no-color.org),
+// if the standard output is not a terminal (piped output), unless FORCE_COLOR is set,
+// or if the terminal doesn't support the ANSI escape sequences (legacy Windows console).
+func DetectColorSupport() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if os.Getenv("FORCE_COLOR") != "" {
+		return true
+	}
+	fd := os.Stdout.Fd()
+	if !isatty.IsTerminal(fd) && !isatty.IsCygwinTerminal(fd) {
+		return false
+	}
+	return GetTerminalCapabilities().ANSI
+}
+
+// ColorEnabled returns true if the colors (ANSI codes) are enabled
+func ColorEnabled() bool {
+	themeMutex.RLock()
+	defer themeMutex.RUnlock()
+	return colorEnabled
+}
+
+// DisableColors removes the ANSI color codes from every styled output
+func DisableColors() {
+	themeMutex.Lock()
+	defer themeMutex.Unlock()
+	colorEnabled = false
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
+// EnableColors restores the colors with the color profile detected from the terminal
+func EnableColors() {
+	themeMutex.Lock()
+	defer themeMutex.Unlock()
+	colorEnabled = true
+	profile := termenv.NewOutput(os.Stdout).EnvColorProfile()
+	if profile == termenv.Ascii {
+		profile = termenv.ANSI256
+	}
+	lipgloss.SetColorProfile(profile)
+}
@@ -9,152 +9,201 @@ import (
 	"github.com/charmbracelet/lipgloss"
 )
 
-// ThinkingController manages the thinking animation
+// thinkingState is the state of the thinking animation
+type thinkingState int
+
+const (
+	thinkingIdle    thinkingState = iota // never started
+	thinkingRunning                      // the animation is displayed
+	thinkingPaused                       // the animation is suspended (e.g. while asking a confirmation)
+	thinkingStopped                      // the animation is over, it can be started again
+)
+
+// ThinkingController manages the thinking animation.
+// Its state is protected by a mutex: Start, Pause, Resume, UpdateMessage and Stop can be called
+// from any goroutine, in any order and any number of times.
 type ThinkingController struct {
-	stopChan  chan bool
-	pauseChan chan bool
-	doneChan  chan bool
-	stopped   bool
-	paused    bool
-	message   string
-	color     string
-	mutex     sync.RWMutex
+	mutex    sync.Mutex
+	state    thinkingState
+	message  string
+	color    string
+	stopChan chan struct{}
+	doneChan chan struct{}
+	// length of the last displayed line, to clear it
+	lastLineLen int
+
+	spinner     Spinner
+	showElapsed bool
+	startTime   time.Time
 }
 
-// NewThinkingController creates a new thinking animation controller with initialized channels
-func NewThinkingController() *ThinkingController {
-	return &ThinkingController{
-		stopChan:  make(chan bool),
-		pauseChan: make(chan bool),
-		doneChan:  make(chan bool),
+// ThinkingOption is a functional option for configuring ThinkingController instances
+type ThinkingOption func(*ThinkingController)
+
+// WithSpinner sets the spinner used by the thinking animation (SpinnerBraille by default)
+func WithSpinner(spinner Spinner) ThinkingOption {
+	return func(tc *ThinkingController) {
+		tc.spinner = spinner
 	}
 }
 
-// Start begins the thinking animation with the specified color and message
+// WithElapsedTime displays the elapsed time since the start of the animation after the message
+func WithElapsedTime() ThinkingOption {
+	return func(tc *ThinkingController) {
+		tc.showElapsed = true
+	}
+}
+
+// NewThinkingController creates a new thinking animation controller
+func NewThinkingController(options ...ThinkingOption) *ThinkingController {
+	tc := &ThinkingController{
+		state:   thinkingIdle,
+		spinner: defaultSpinner(),
+	}
+	// Apply all options
+	for _, option := range options {
+		option(tc)
+	}
+	if len(tc.spinner.Frames) == 0 {
+		tc.spinner = defaultSpinner()
+	}
+	if tc.spinner.Interval <= 0 {
+		tc.spinner.Interval = 100 * time.Millisecond
+	}
+	return tc
+}
+
+// Start begins the thinking animation with the specified color and message.
+// If the animation is already started, only the color and the message are updated.
+// When the terminal can't be animated (see AnimationEnabled), the message is printed once.
 func (tc *ThinkingController) Start(color string, message string) {
 	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+
 	tc.message = message
 	tc.color = color
-	tc.stopped = false
-	tc.paused = false
-	tc.mutex.Unlock()
-	
-	go func() {
-		defer close(tc.doneChan)
+	if tc.state == thinkingRunning || tc.state == thinkingPaused {
+		return
+	}
 
-		animationChars := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-		index := 0
+	tc.state = thinkingRunning
+	tc.startTime = time.Now()
+	if !AnimationEnabled() {
+		tc.stopChan = nil
+		tc.doneChan = nil
+		fmt.Println(lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render(message))
+		return
+	}
+	tc.stopChan = make(chan struct{})
+	tc.doneChan = make(chan struct{})
 
-		ticker := time.NewTicker(100 * time.Millisecond)
-		defer ticker.Stop()
+	go tc.animate(tc.stopChan, tc.doneChan)
+}
 
-		textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(color))
+// animate renders a new frame at each tick until stopChan is closed
+func (tc *ThinkingController) animate(stopChan <-chan struct{}, doneChan chan<- struct{}) {
+	defer close(doneChan)
 
-	resumeAnimation:
-		for {
-			select {
-			case <-tc.stopChan:
-				// Clear the current line
-				tc.mutex.RLock()
-				currentMessage := tc.message
-				tc.mutex.RUnlock()
-				fmt.Print("\r" + strings.Repeat(" ", len(currentMessage)+5) + "\r")
-				return
-			case <-tc.pauseChan:
-				// Animation is paused, wait for resume or stop
-				tc.mutex.Lock()
-				tc.paused = true
-				tc.mutex.Unlock()
-				for {
-					select {
-					case <-tc.stopChan:
-						tc.mutex.RLock()
-						currentMessage := tc.message
-						tc.mutex.RUnlock()
-						fmt.Print("\r" + strings.Repeat(" ", len(currentMessage)+5) + "\r")
-						return
-					case <-tc.pauseChan:
-						// Resume animation
-						tc.mutex.Lock()
-						tc.paused = false
-						tc.mutex.Unlock()
-						goto resumeAnimation
-					}
-				}
-			case <-ticker.C:
-				tc.mutex.RLock()
-				isPaused := tc.paused
+	ticker := time.NewTicker(tc.spinner.Interval)
+	defer ticker.Stop()
+
+	index := 0
+	for {
+		select {
+		case <-stopChan:
+			return
+		case <-ticker.C:
+			tc.mutex.Lock()
+			// The state can change between the tick and the lock, and a Stop followed by a Start
+			// can replace this animation by a new one before it ends
+			if tc.state == thinkingRunning && tc.stopChan == stopChan {
 				currentMessage := tc.message
-				tc.mutex.RUnlock()
-				
-				if !isPaused {
-					// Clear current line and print new animation frame
-					animatedMessage := fmt.Sprintf("\r%s %s", animationChars[index], currentMessage)
-					fmt.Print(textStyle.Render(animatedMessage))
-					index = (index + 1) % len(animationChars)
+				if tc.showElapsed {
+					currentMessage += " " + formatElapsed(time.Since(tc.startTime))
 				}
+				textStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(tc.color))
+				frame := tc.spinner.Frames[index%len(tc.spinner.Frames)]
+				animatedMessage := fmt.Sprintf("%s %s", frame, currentMessage)
+				tc.clearLine()
+				fmt.Print("\r" + textStyle.Render(animatedMessage))
+				tc.lastLineLen = lipgloss.Width(animatedMessage)
+				index++
 			}
+			tc.mutex.Unlock()
 		}
-	}()
+	}
+}
+
+// clearLine erases the last displayed frame, must be called with the mutex held
+func (tc *ThinkingController) clearLine() {
+	if tc.lastLineLen > 0 {
+		fmt.Print("\r" + strings.Repeat(" ", tc.lastLineLen) + "\r")
+		tc.lastLineLen = 0
+	}
 }
 
 // UpdateMessage safely updates the message displayed in the thinking animation
 func (tc *ThinkingController) UpdateMessage(message string) {
 	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
 	tc.message = message
-	tc.mutex.Unlock()
 }
 
-// Pause pauses the thinking animation if it's currently running
+// Pause pauses the thinking animation and clears the line if it's currently running
 func (tc *ThinkingController) Pause() {
-	tc.mutex.RLock()
-	if !tc.stopped && !tc.paused {
-		tc.mutex.RUnlock()
-		select {
-		case tc.pauseChan <- true:
-		default:
-		}
-	} else {
-		tc.mutex.RUnlock()
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	if tc.state == thinkingRunning {
+		tc.state = thinkingPaused
+		tc.clearLine()
 	}
 }
 
 // Resume resumes the thinking animation if it's currently paused
 func (tc *ThinkingController) Resume() {
-	tc.mutex.RLock()
-	if !tc.stopped && tc.paused {
-		tc.mutex.RUnlock()
-		select {
-		case tc.pauseChan <- true:
-		default:
-		}
-	} else {
-		tc.mutex.RUnlock()
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	if tc.state == thinkingPaused {
+		tc.state = thinkingRunning
 	}
 }
 
 // IsPaused returns true if the thinking animation is currently paused
 func (tc *ThinkingController) IsPaused() bool {
-	tc.mutex.RLock()
-	defer tc.mutex.RUnlock()
-	return tc.paused
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	return tc.state == thinkingPaused
 }
 
-// Stop stops the thinking animation, clears the line, and waits for the goroutine to finish
+// Stop stops the thinking animation, clears the line, and waits for the goroutine to finish.
+// Calling Stop on a stopped (or never started) controller does nothing.
 func (tc *ThinkingController) Stop() {
-	if !tc.stopped {
-		tc.stopped = true
-		close(tc.stopChan)
-		<-tc.doneChan
+	tc.mutex.Lock()
+	if tc.state != thinkingRunning && tc.state != thinkingPaused {
+		tc.mutex.Unlock()
+		return
+	}
+	tc.state = thinkingStopped
+	if tc.stopChan == nil {
+		// Not animated
+		tc.mutex.Unlock()
+		return
 	}
+	// The line is cleared with the lock held: the animation doesn't render once stopped,
+	// and the line of an animation started after the lock is released is kept
+	tc.clearLine()
+	close(tc.stopChan)
+	doneChan := tc.doneChan
+	tc.stopChan, tc.doneChan = nil, nil
+	tc.mutex.Unlock()
+
+	// Wait for the animation goroutine outside of the lock (it needs the lock to render)
+	<-doneChan
 }
 
-// IsStarted returns true if the thinking animation is currently running
+// IsStarted returns true if the thinking animation is currently running or paused
 func (tc *ThinkingController) IsStarted() bool {
-	select {
-	case <-tc.doneChan:
-		return false
-	default:
-		return true
-	}
-}
\ No newline at end of file
+	tc.mutex.Lock()
+	defer tc.mutex.Unlock()
+	return tc.state == thinkingRunning || tc.state == thinkingPaused
+}
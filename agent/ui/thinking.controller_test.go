@@ -0,0 +1,245 @@
+package ui
+
+import (
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The tests are meant to be run with -race: go test -race ./agent/ui
+
+const testInterval = 2 * time.Millisecond
+
+// captureStdout redirects the standard output until the end of the test and returns a function
+// reading what was printed so far
+func captureStdout(t *testing.T) func() string {
+	t.Helper()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+
+	var mutex sync.Mutex
+	var output strings.Builder
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		buffer := make([]byte, 4096)
+		for {
+			n, err := reader.Read(buffer)
+			mutex.Lock()
+			output.Write(buffer[:n])
+			mutex.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}()
+	t.Cleanup(func() {
+		os.Stdout = stdout
+		writer.Close()
+		<-done
+		reader.Close()
+	})
+	return func() string {
+		// Let the reader catch up
+		time.Sleep(5 * testInterval)
+		mutex.Lock()
+		defer mutex.Unlock()
+		return output.String()
+	}
+}
+
+// withAnimation enables the animations until the end of the test
+func withAnimation(t *testing.T, enabled bool) {
+	t.Helper()
+	capabilities := GetTerminalCapabilities()
+	SetTerminalCapabilities(TerminalCapabilities{ANSI: enabled, Unicode: true, Animation: enabled})
+	t.Cleanup(func() { SetTerminalCapabilities(capabilities) })
+}
+
+func newTestThinkingController() *ThinkingController {
+	return NewThinkingController(WithSpinner(Spinner{Frames: []string{"-", "+"}, Interval: testInterval}))
+}
+
+// lastLine returns what a terminal displays on the last line of an output made of carriage returns
+func lastLine(output string) string {
+	lines := strings.Split(output, "\n")
+	line := lines[len(lines)-1]
+	// A line is redrawn from its start after each carriage return
+	screen := []rune{}
+	position := 0
+	for _, r := range line {
+		if r == '\r' {
+			position = 0
+			continue
+		}
+		if position < len(screen) {
+			screen[position] = r
+		} else {
+			screen = append(screen, r)
+		}
+		position++
+	}
+	return strings.TrimSpace(string(screen))
+}
+
+func TestThinkingControllerStartStop(t *testing.T) {
+	withAnimation(t, true)
+	output := captureStdout(t)
+	tc := newTestThinkingController()
+
+	tc.Start("", "thinking")
+	if !tc.IsStarted() {
+		t.Fatal("the animation should be started")
+	}
+	time.Sleep(5 * testInterval)
+	if line := lastLine(output()); !strings.HasSuffix(line, "thinking") {
+		t.Fatalf("the animation should be displayed, got %q", line)
+	}
+
+	tc.Stop()
+	if tc.IsStarted() {
+		t.Fatal("the animation should be stopped")
+	}
+	if line := lastLine(output()); line != "" {
+		t.Fatalf("the line should be cleared, got %q", line)
+	}
+	// Stop is idempotent
+	tc.Stop()
+}
+
+func TestThinkingControllerPauseResume(t *testing.T) {
+	withAnimation(t, true)
+	output := captureStdout(t)
+	tc := newTestThinkingController()
+
+	// Pause and Resume do nothing before Start
+	tc.Pause()
+	tc.Resume()
+	if tc.IsStarted() || tc.IsPaused() {
+		t.Fatal("the animation shouldn't be started")
+	}
+
+	tc.Start("", "thinking")
+	time.Sleep(5 * testInterval)
+	tc.Pause()
+	if !tc.IsPaused() || !tc.IsStarted() {
+		t.Fatal("the animation should be paused")
+	}
+	if line := lastLine(output()); line != "" {
+		t.Fatalf("the line should be cleared while paused, got %q", line)
+	}
+
+	tc.Resume()
+	tc.UpdateMessage("still thinking")
+	time.Sleep(5 * testInterval)
+	if tc.IsPaused() {
+		t.Fatal("the animation should be resumed")
+	}
+	if line := lastLine(output()); !strings.HasSuffix(line, "still thinking") {
+		t.Fatalf("the animation should be displayed again, got %q", line)
+	}
+
+	// A paused animation can be stopped
+	tc.Pause()
+	tc.Stop()
+	if tc.IsStarted() || tc.IsPaused() {
+		t.Fatal("the animation should be stopped")
+	}
+}
+
+func TestThinkingControllerWithoutAnimation(t *testing.T) {
+	withAnimation(t, false)
+	output := captureStdout(t)
+	tc := newTestThinkingController()
+
+	tc.Start("", "thinking")
+	tc.Start("", "thinking again")
+	tc.Pause()
+	tc.Resume()
+	tc.Stop()
+	if got := output(); got != "thinking\n" {
+		t.Fatalf("the message should be printed once, got %q", got)
+	}
+}
+
+// A Start right after a Stop keeps its line: the Stop doesn't clear it, and the previous
+// animation doesn't render anymore
+func TestThinkingControllerStopThenStart(t *testing.T) {
+	withAnimation(t, true)
+	output := captureStdout(t)
+	tc := newTestThinkingController()
+
+	for i := 0; i < 50; i++ {
+		tc.Start("", "first")
+		time.Sleep(testInterval)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			tc.Stop()
+		}()
+		go func() {
+			defer wg.Done()
+			tc.Start("", "second")
+		}()
+		wg.Wait()
+		// The Start can come first (then Stop stops it) or after the Stop
+		tc.Start("", "second")
+		time.Sleep(5 * testInterval)
+		if line := lastLine(output()); !strings.HasSuffix(line, "second") {
+			t.Fatalf("iteration %d: the animation of the last Start should be displayed, got %q", i, line)
+		}
+		tc.Stop()
+	}
+}
+
+func TestThinkingControllerConcurrentCalls(t *testing.T) {
+	withAnimation(t, true)
+	captureStdout(t)
+	tc := newTestThinkingController()
+
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			random := rand.New(rand.NewSource(seed))
+			for i := 0; i < 200; i++ {
+				switch random.Intn(6) {
+				case 0:
+					tc.Start("", "thinking")
+				case 1:
+					tc.Pause()
+				case 2:
+					tc.Resume()
+				case 3:
+					tc.Stop()
+				case 4:
+					tc.UpdateMessage("message")
+				case 5:
+					tc.IsPaused()
+					tc.IsStarted()
+				}
+				if random.Intn(10) == 0 {
+					time.Sleep(testInterval)
+				}
+			}
+		}(int64(worker))
+	}
+	wg.Wait()
+
+	tc.Stop()
+	if tc.IsStarted() {
+		t.Fatal("the animation should be stopped")
+	}
+	// The controller can be started again after the concurrent calls
+	tc.Start("", "again")
+	tc.Stop()
+}
@@ -0,0 +1,70 @@
+package workflow
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Contains is true if the output contains the substring (case insensitive)
+func Contains(substring string) Condition {
+	substring = strings.ToLower(substring)
+	return func(output string, state *State) (bool, error) {
+		return strings.Contains(strings.ToLower(output), substring), nil
+	}
+}
+
+// Matches is true if the output matches the regular expression (it panics if the expression is invalid)
+func Matches(expression string) Condition {
+	re := regexp.MustCompile(expression)
+	return func(output string, state *State) (bool, error) {
+		return re.MatchString(output), nil
+	}
+}
+
+// Not negates a condition
+func Not(condition Condition) Condition {
+	return func(output string, state *State) (bool, error) {
+		ok, err := condition(output, state)
+		return !ok && err == nil, err
+	}
+}
+
+// VisitsBelow is true while the node has been executed less than n times
+func VisitsBelow(node string, n int) Condition {
+	return func(output string, state *State) (bool, error) {
+		return state.Visits[node] < n, nil
+	}
+}
+
+// LLMCondition asks a judge agent a yes/no question about the output, the condition is true on "yes".
+// The messages of the judge are restored after each evaluation.
+//
+// Example usage:
+//
+//	workflow.Edge{From: "writer", To: workflow.End, Condition: workflow.LLMCondition(judge, "Is the text polite?")}
+func LLMCondition(judge mu.Agent, question string) Condition {
+	return func(output string, state *State) (bool, error) {
+		prompt := fmt.Sprintf("%s\nAnswer only with yes or no.\n\n<text>\n%s\n</text>", question, output)
+
+		saved := append([]openai.ChatCompletionMessageParamUnion{}, judge.GetMessages()...)
+		defer judge.SetMessages(saved)
+		answer, err := judge.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)})
+		if err != nil {
+			return false, err
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		answer = strings.TrimLeft(answer, "*\"' ")
+		switch {
+		case strings.HasPrefix(answer, "yes"):
+			return true, nil
+		case strings.HasPrefix(answer, "no"):
+			return false, nil
+		}
+		return false, fmt.Errorf("the judge %s didn't answer yes or no: %q", judge.GetName(), answer)
+	}
+}
@@ -0,0 +1,273 @@
+// Package workflow runs graphs of nodes (agents or Go functions) connected by conditional edges,
+// supporting branches and loops with limits, with a state passed between the nodes and an execution trace.
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// End is the name of the virtual node ending the workflow
+const End = "__end__"
+
+// State is passed between the nodes of a workflow
+type State struct {
+	// Input is the input of the workflow
+	Input string
+	// Last is the output of the last executed node (the input before the first node)
+	Last string
+	// Outputs are the last outputs of the executed nodes by name
+	Outputs map[string]string
+	// Values are free values the function nodes can share
+	Values map[string]any
+	// Visits counts the executions of each node
+	Visits map[string]int
+}
+
+// newState creates the initial state of a workflow
+func newState(input string) *State {
+	return &State{
+		Input:   input,
+		Last:    input,
+		Outputs: map[string]string{},
+		Values:  map[string]any{},
+		Visits:  map[string]int{},
+	}
+}
+
+// Node is a step of a workflow: an agent or a Go function
+type Node struct {
+	Name string
+	// Agent answers the prompt of the node (its messages are restored after each execution)
+	Agent mu.Agent
+	// Prompt builds the prompt of the agent from the state (the last output if nil)
+	Prompt func(state *State) string
+	// Func is executed instead of an agent
+	Func func(state *State) (string, error)
+}
+
+// Condition decides if an edge is followed from the output of its source node
+type Condition func(output string, state *State) (bool, error)
+
+// Edge connects two nodes, it is followed if its condition is true (always if nil)
+type Edge struct {
+	From      string
+	To        string
+	Label     string
+	Condition Condition
+	// MaxTraversals limits the traversals of the edge (unlimited if zero), e.g. the iterations of a loop.
+	// When it is reached, the edge is ignored and the next edges are evaluated.
+	MaxTraversals int
+}
+
+// TraceEvent is the execution of a node
+type TraceEvent struct {
+	Step     int
+	Node     string
+	Input    string // prompt of an agent node
+	Output   string
+	Err      error
+	Duration time.Duration
+	Next     string // the node executed next (End at the end)
+	Edge     string // label of the followed edge
+}
+
+// GraphOption is a functional option for configuring Graph instances
+type GraphOption func(*Graph)
+
+// Graph is a workflow: the nodes are executed from the start node, following the first edge
+// (in the order they were added) whose condition is true. The workflow ends on End or when no edge matches.
+type Graph struct {
+	nodes    map[string]Node
+	edges    map[string][]Edge
+	start    string
+	maxSteps int
+	onTrace  func(event TraceEvent)
+}
+
+// NewGraph creates an empty workflow
+//
+// Example usage:
+//
+//	graph := workflow.NewGraph(workflow.WithMaxSteps(20))
+//	graph.AddAgentNode("writer", writer, nil)
+//	graph.AddAgentNode("reviewer", reviewer, func(state *workflow.State) string {
+//	  return "Review this text, answer APPROVED if it is good:\n" + state.Last
+//	})
+//	graph.AddEdge(workflow.Edge{From: "writer", To: "reviewer"})
+//	graph.AddEdge(workflow.Edge{From: "reviewer", To: workflow.End, Condition: workflow.Contains("APPROVED")})
+//	graph.AddEdge(workflow.Edge{From: "reviewer", To: "writer", MaxTraversals: 3})
+//	graph.SetStart("writer")
+//	state, trace, err := graph.Run("Write a haiku about Go")
+func NewGraph(options ...GraphOption) *Graph {
+	graph := &Graph{
+		nodes:    map[string]Node{},
+		edges:    map[string][]Edge{},
+		maxSteps: 100,
+	}
+	for _, option := range options {
+		option(graph)
+	}
+	return graph
+}
+
+// WithMaxSteps is a functional option that limits the number of executed nodes (100 by default)
+func WithMaxSteps(maxSteps int) GraphOption {
+	return func(graph *Graph) {
+		graph.maxSteps = maxSteps
+	}
+}
+
+// WithTracer is a functional option that sets the callback called after the execution of each node
+func WithTracer(onTrace func(event TraceEvent)) GraphOption {
+	return func(graph *Graph) {
+		graph.onTrace = onTrace
+	}
+}
+
+// AddNode adds a node, the first added node is the start node (see SetStart)
+func (graph *Graph) AddNode(node Node) *Graph {
+	graph.nodes[node.Name] = node
+	if graph.start == "" {
+		graph.start = node.Name
+	}
+	return graph
+}
+
+// AddAgentNode adds a node executed by an agent, prompt builds its prompt from the state (the last output if nil)
+func (graph *Graph) AddAgentNode(name string, agent mu.Agent, prompt func(state *State) string) *Graph {
+	return graph.AddNode(Node{Name: name, Agent: agent, Prompt: prompt})
+}
+
+// AddFuncNode adds a node executed by a Go function
+func (graph *Graph) AddFuncNode(name string, fn func(state *State) (string, error)) *Graph {
+	return graph.AddNode(Node{Name: name, Func: fn})
+}
+
+// AddEdge adds an edge, the edges of a node are evaluated in the order they were added
+func (graph *Graph) AddEdge(edge Edge) *Graph {
+	graph.edges[edge.From] = append(graph.edges[edge.From], edge)
+	return graph
+}
+
+// SetStart sets the start node
+func (graph *Graph) SetStart(name string) *Graph {
+	graph.start = name
+	return graph
+}
+
+// Validate checks that the start node and the nodes of the edges exist, and that each node can run
+func (graph *Graph) Validate() error {
+	if _, ok := graph.nodes[graph.start]; !ok {
+		return fmt.Errorf("unknown start node %q", graph.start)
+	}
+	for name, node := range graph.nodes {
+		if name == End {
+			return fmt.Errorf("%q is reserved", End)
+		}
+		if node.Agent == nil && node.Func == nil {
+			return fmt.Errorf("the node %q has no agent and no function", name)
+		}
+	}
+	for from, edges := range graph.edges {
+		if _, ok := graph.nodes[from]; !ok {
+			return fmt.Errorf("the edge from %q starts from an unknown node", from)
+		}
+		for _, edge := range edges {
+			if _, ok := graph.nodes[edge.To]; !ok && edge.To != End {
+				return fmt.Errorf("the edge from %q goes to an unknown node %q", from, edge.To)
+			}
+		}
+	}
+	return nil
+}
+
+// ErrMaxSteps is returned when a workflow executes more nodes than its maximum number of steps
+var ErrMaxSteps = errors.New("maximum number of steps reached")
+
+// Run executes the workflow from the start node.
+//
+// Returns:
+//   - *State: The final state, the output of the workflow is state.Last
+//   - []TraceEvent: The executed nodes in order
+//   - error: A validation error, the error of a node or of a condition, or ErrMaxSteps
+func (graph *Graph) Run(input string) (*State, []TraceEvent, error) {
+	state := newState(input)
+	trace := []TraceEvent{}
+	if err := graph.Validate(); err != nil {
+		return state, trace, err
+	}
+
+	traversals := map[*Edge]int{}
+	current := graph.start
+	for step := 1; current != End; step++ {
+		if step > graph.maxSteps {
+			return state, trace, ErrMaxSteps
+		}
+		node := graph.nodes[current]
+		event := TraceEvent{Step: step, Node: current}
+		start := time.Now()
+		event.Input, event.Output, event.Err = graph.execute(node, state)
+		event.Duration = time.Since(start)
+
+		if event.Err == nil {
+			state.Visits[current]++
+			state.Outputs[current] = event.Output
+			state.Last = event.Output
+			event.Next, event.Edge, event.Err = graph.next(current, event.Output, state, traversals)
+		}
+		trace = append(trace, event)
+		if graph.onTrace != nil {
+			graph.onTrace(event)
+		}
+		if event.Err != nil {
+			return state, trace, fmt.Errorf("node %s: %w", current, event.Err)
+		}
+		current = event.Next
+	}
+	return state, trace, nil
+}
+
+// execute runs a node and returns its prompt (agent nodes) and its output
+func (graph *Graph) execute(node Node, state *State) (string, string, error) {
+	if node.Func != nil {
+		output, err := node.Func(state)
+		return "", output, err
+	}
+	prompt := state.Last
+	if node.Prompt != nil {
+		prompt = node.Prompt(state)
+	}
+	saved := append([]openai.ChatCompletionMessageParamUnion{}, node.Agent.GetMessages()...)
+	defer node.Agent.SetMessages(saved)
+	output, err := node.Agent.Run([]openai.ChatCompletionMessageParamUnion{openai.UserMessage(prompt)})
+	return prompt, output, err
+}
+
+// next returns the node following current: the target of the first edge whose condition is true
+func (graph *Graph) next(current, output string, state *State, traversals map[*Edge]int) (string, string, error) {
+	edges := graph.edges[current]
+	for idx := range edges {
+		edge := &edges[idx]
+		if edge.MaxTraversals > 0 && traversals[edge] >= edge.MaxTraversals {
+			continue
+		}
+		if edge.Condition != nil {
+			ok, err := edge.Condition(output, state)
+			if err != nil {
+				return "", edge.Label, fmt.Errorf("condition of the edge to %s: %w", edge.To, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		traversals[edge]++
+		return edge.To, edge.Label, nil
+	}
+	return End, "", nil
+}
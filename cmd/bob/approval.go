@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// toolApprover decides if a tool call is executed, from the persisted policies of the configuration,
+// then from the answer of the user in the terminal (which remembers the tools allowed for the current session)
+type toolApprover struct {
+	config   *Config
+	terminal *approval.Terminal
+}
+
+// newToolApprover creates an approver using the policies of the configuration
+func newToolApprover(config *Config) *toolApprover {
+	terminal := approval.NewTerminal()
+	// executeFunction displays the tool calls with the preview of their changes
+	terminal.Display = func(approval.ToolCall) {}
+	// "always" and "never" are saved in the configuration
+	terminal.Remember = func(toolName string, action approval.Action) error {
+		if err := config.setToolPolicy(toolName, string(action)); err != nil {
+			ui.GetLogger().Error("failed to save the tool policy", "tool", toolName, "error", err)
+			return err
+		}
+		ui.Println(ui.GetTheme().Info, "Policy saved:", toolName, "→", action, "("+config.path+")")
+		return nil
+	}
+	return &toolApprover{
+		config:   config,
+		terminal: terminal,
+	}
+}
+
+// Approve applies the policy of the tool, asking the user when the policy is "ask".
+// It returns an ExitToolCallsLoopError if the user aborts.
+func (a *toolApprover) Approve(ctx context.Context, call approval.ToolCall) (approval.Decision, error) {
+	switch a.config.toolPolicy(call.Name) {
+	case toolPolicyAllow:
+		ui.Println(ui.GetTheme().Info, "✔ auto-approved (policy: allow)")
+		return approval.Decision{Action: approval.ActionAllow}, nil
+	case toolPolicyDeny:
+		ui.Println(ui.GetTheme().Warning, "✖ denied (policy: deny)")
+		return approval.Decision{Action: approval.ActionDeny, Reason: "denied by the policy"}, nil
+	}
+	return a.terminal.Approve(ctx, call)
+}
+
+// approvedUnattended returns true if a tool can be executed when there is nobody to ask (non-interactive
+// and server modes): always with the "allow" policy, never with "deny", and with "ask" only if approveTools is true
+func approvedUnattended(config *Config, functionName string, approveTools bool) bool {
+	policy := config.toolPolicies()
+	if approveTools {
+		policy.Ask = approval.ApproverFunc(func(context.Context, approval.ToolCall) (approval.Decision, error) {
+			return approval.Decision{Action: approval.ActionAllow}, nil
+		})
+	}
+	decision, err := policy.Approve(context.Background(), approval.ToolCall{Name: functionName})
+	return err == nil && decision.Action == approval.ActionAllow
+}
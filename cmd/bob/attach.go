@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/msg"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+	"github.com/openai/openai-go/v2"
+)
+
+// attachments are the images attached with /attach, sent with the next prompt
+type attachments struct {
+	paths []string
+}
+
+// userMessage returns the user message of a prompt with the attached images, which are then cleared
+func (pending *attachments) userMessage(text string) (openai.ChatCompletionMessageParamUnion, error) {
+	if len(pending.paths) == 0 {
+		return openai.UserMessage(text), nil
+	}
+	message, err := msg.UserMessageWithImages(text, pending.paths...)
+	if err != nil {
+		return message, err
+	}
+	pending.paths = nil
+	return message, nil
+}
+
+// registerAttachCommand registers /attach, which attaches images (png, jpeg or webp) to the next prompt
+func registerAttachCommand(registry *ui.CommandRegistry, pending *attachments) {
+	registry.Register(ui.SlashCommand{
+		Name:        "/attach",
+		Usage:       "/attach [image|clear]",
+		Description: "Attach an image to the next prompt (list the attached images without argument)",
+		Handler: func(args string) error {
+			switch args {
+			case "":
+				if len(pending.paths) == 0 {
+					ui.Println(ui.GetTheme().Info, "No attached image")
+				}
+				for _, path := range pending.paths {
+					ui.Println(ui.GetTheme().Info, "📎", path)
+				}
+				return nil
+			case "clear":
+				pending.paths = nil
+				ui.Println(ui.GetTheme().Info, "📎 Attachments cleared")
+				return nil
+			}
+			path := strings.Trim(args, `"'`)
+			image, err := helpers.LoadImageFile(path, 0)
+			if err != nil {
+				return fmt.Errorf("unable to attach the image: %w", err)
+			}
+			pending.paths = append(pending.paths, path)
+			ui.Printf(ui.GetTheme().Info, "📎 %s attached (%s, %d KB), it will be sent with the next prompt\n",
+				filepath.Base(path), image.MIMEType, (len(image.Data)+1023)/1024)
+			return nil
+		},
+	})
+}
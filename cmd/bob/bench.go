@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/bench"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// runBench runs `bob bench`: the prompts of a JSON file against the models of the profile, then prints
+// the comparison table
+func runBench(ctx context.Context, args []string, client openai.Client, defaultModel string) int {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	models := flags.String("models", "", "comma-separated models to compare (the model of the profile by default)")
+	runs := flags.Int("runs", 1, "number of runs of each prompt")
+	timeout := flags.Duration("timeout", 2*time.Minute, "maximum duration of a completion")
+	output := flags.String("output", "", "write the full report to a JSON file")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() != 1 || *runs < 1 {
+		fmt.Fprintln(os.Stderr, "usage: bob bench [-models a,b] [-runs n] [-timeout d] [-output report.json] <prompts.json>")
+		return exitUsageError
+	}
+	prompts, err := bench.LoadPrompts(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsageError
+	}
+
+	targets := []bench.Target{}
+	for _, model := range strings.Split(*models, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			targets = append(targets, bench.Target{Client: client, Model: model})
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, bench.Target{Client: client, Model: defaultModel})
+	}
+
+	benchmark := bench.NewBenchmark(
+		bench.WithTargets(targets...),
+		bench.WithPrompts(prompts...),
+		bench.WithRuns(*runs),
+		bench.WithTimeout(*timeout),
+		bench.WithOnResult(func(result bench.Result) {
+			status := fmt.Sprintf("%s, %.1f tokens/s", result.TimeToFirstToken.Round(time.Millisecond), result.TokensPerSecond)
+			switch {
+			case result.Error != "":
+				status = "error: " + result.Error
+			case result.Reason != "":
+				status += ", " + result.Reason
+			}
+			fmt.Fprintf(os.Stderr, "[%s] %s #%d: %s\n", result.Target, result.Prompt, result.Run, status)
+		}),
+	)
+	report, err := benchmark.Run(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	fmt.Print(report.Markdown())
+	if *output != "" {
+		if err := report.Save(*output); err != nil {
+			fmt.Fprintln(os.Stderr, "failed to save the report:", err)
+			return exitFailure
+		}
+	}
+	return exitSuccess
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/msg"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// newCommandRegistry registers the in-chat commands of Bob.
+// /edit stores the content written in the editor into editedInput, to be sent as the prompt.
+func newCommandRegistry(ctx context.Context, client openai.Client, toolAgent mu.Agent, session *Session, toolsIndex []openai.ChatCompletionToolUnionParam, usage *usageTracker, delegation *delegator, approver *toolApprover, editedInput *string) *ui.CommandRegistry {
+	theme := ui.GetTheme()
+	registry := ui.NewCommandRegistry()
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/bye",
+		Description: "Exit Bob",
+		Handler: func(string) error {
+			return ui.ErrExitCommand
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/edit",
+		Description: "Write the prompt in the external editor ($VISUAL or $EDITOR)",
+		Handler: func(string) error {
+			content, err := ui.EditInEditor("")
+			if err != nil {
+				return fmt.Errorf("unable to open the editor: %w", err)
+			}
+			if content != "" {
+				ui.Println(theme.User, content)
+			}
+			*editedInput = content
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/reset",
+		Description: "Clear the conversation (the system message is kept)",
+		Handler: func(string) error {
+			session.SetMessages(session.Messages[:min(1, len(session.Messages))])
+			toolAgent.SetMessages(session.Messages)
+			ui.Println(theme.Info, "The conversation has been cleared")
+			return session.save()
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/compact",
+		Usage:       "/compact [tokens]",
+		Description: "Summarize the oldest turns of the conversation to fit in tokens (half of the context of the model by default)",
+		Handler: func(args string) error {
+			model := toolAgent.GetModel()
+			budget := modelContextSize(model) / 2
+			if args != "" {
+				var err error
+				if budget, err = strconv.Atoi(args); err != nil || budget <= 0 {
+					return fmt.Errorf("usage: /compact [tokens]")
+				}
+			}
+			if budget <= 0 {
+				return fmt.Errorf("unknown context size for %s, use /compact <tokens>", model)
+			}
+			before := estimateTokens(session.Messages, model)
+			if before <= budget {
+				ui.Printf(theme.Info, "The conversation (~%d tokens) already fits in %d tokens\n", before, budget)
+				return nil
+			}
+
+			spinner := ui.NewThinkingController()
+			spinner.Start(theme.Info, "🗜️  Summarizing the conversation...")
+			compacted, err := msg.TrimToBudget(session.Messages, budget, msg.TrimStrategy{
+				Model: model,
+				Summarize: func(dropped []openai.ChatCompletionMessageParamUnion) (string, error) {
+					return summarizeConversation(ctx, client, model, dropped)
+				},
+			})
+			spinner.Stop()
+			if err != nil {
+				return err
+			}
+			// The timestamps of the kept messages are lost
+			session.Times = nil
+			session.SetMessages(compacted)
+			toolAgent.SetMessages(session.Messages)
+			ui.Printf(theme.Info, "🗜️  The conversation has been compacted from ~%d to ~%d tokens\n", before, estimateTokens(session.Messages, model))
+			return session.save()
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/models",
+		Description: "List the models available from the provider",
+		Handler: func(string) error {
+			models, err := listModels(ctx, client)
+			if err != nil {
+				return fmt.Errorf("unable to list the models: %w", err)
+			}
+			current := toolAgent.GetModel()
+			for _, model := range models {
+				marker := "  "
+				if model == current {
+					marker = "* "
+				}
+				contextSize := ""
+				if size := modelContextSize(model); size > 0 {
+					contextSize = fmt.Sprintf(" (%d tokens)", size)
+				}
+				ui.Println(theme.Info, marker+model+contextSize)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/model",
+		Usage:       "/model [id]",
+		Description: "Show or switch the model (the conversation is kept)",
+		Handler: func(args string) error {
+			if args == "" || args == toolAgent.GetModel() {
+				ui.Println(theme.Info, "Model:", toolAgent.GetModel())
+				return nil
+			}
+
+			// The provider may not implement the models endpoint: only warn
+			if models, err := listModels(ctx, client); err == nil && !slices.Contains(models, args) {
+				ui.Println(theme.Warning, "⚠️  The model", args, "is not listed by the provider (see /models)")
+			}
+
+			previousSize := modelContextSize(toolAgent.GetModel())
+			newSize := modelContextSize(args)
+			conversationTokens := estimateTokens(session.Messages, args)
+			switch {
+			case newSize == 0:
+				ui.Println(theme.Warning, "⚠️  Unknown context size for", args, "- the conversation is ~", conversationTokens, "tokens")
+			case conversationTokens > newSize:
+				ui.Printf(theme.Warning, "⚠️  The conversation (~%d tokens) doesn't fit in the context of %s (%d tokens), use /compact or /reset\n", conversationTokens, args, newSize)
+			case previousSize > 0 && newSize < previousSize:
+				ui.Printf(theme.Warning, "⚠️  The context of %s (%d tokens) is smaller than the previous one (%d tokens)\n", args, newSize, previousSize)
+			}
+
+			toolAgent.SetModel(args)
+			session.Model = args
+			ui.Println(theme.Info, "Model:", args, "(the conversation is carried over)")
+			return session.save()
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/tools",
+		Description: "List the MCP tools",
+		Handler: func(string) error {
+			for _, tool := range toolsIndex {
+				ui.Printf(theme.Tool, "%s - %s\n", tool.GetFunction().Name, tool.GetFunction().Description)
+			}
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/system",
+		Usage:       "/system [message]",
+		Description: "Show or replace the system message",
+		Handler: func(args string) error {
+			if args == "" {
+				if len(session.Messages) > 0 && session.Messages[0].OfSystem != nil {
+					ui.Println(theme.Info, strings.TrimSpace(session.Messages[0].OfSystem.Content.OfString.Value))
+				}
+				return nil
+			}
+			if len(session.Messages) > 0 && session.Messages[0].OfSystem != nil {
+				session.Messages[0] = openai.SystemMessage(args)
+			} else {
+				session.Messages = append([]openai.ChatCompletionMessageParamUnion{openai.SystemMessage(args)}, session.Messages...)
+				session.Times = append([]time.Time{time.Now()}, session.Times...)
+			}
+			ui.Println(theme.Info, "The system message has been replaced")
+			return session.save()
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/save",
+		Usage:       "/save <file>",
+		Description: "Save the conversation to a JSON file",
+		Handler: func(args string) error {
+			if args == "" {
+				return fmt.Errorf("usage: /save <file>")
+			}
+			data, err := json.MarshalIndent(session.Messages, "", "  ")
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(args, data, 0644); err != nil {
+				return err
+			}
+			ui.Println(theme.Info, "Conversation saved to", args)
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/export",
+		Usage:       "/export [-redact] [file]",
+		Description: "Export the conversation to a markdown or HTML (.html) transcript, -redact masks the API keys, tokens and emails",
+		Handler: func(args string) error {
+			path, redact := args, false
+			if fields := strings.Fields(args); len(fields) > 0 && fields[0] == "-redact" {
+				path, redact = strings.TrimSpace(strings.TrimPrefix(args, "-redact")), true
+			}
+			if path == "" {
+				path = defaultExportPath(session)
+			}
+			if err := exportSession(session, path, redact); err != nil {
+				return err
+			}
+			ui.Println(theme.Info, "Conversation exported to", path)
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/spawn",
+		Usage:       "/spawn [agent task]",
+		Description: "List the sub-agents, or delegate a task to a sub-agent (its answer is added to the conversation)",
+		Handler: func(args string) error {
+			name, task, _ := strings.Cut(args, " ")
+			task = strings.TrimSpace(task)
+			if name == "" {
+				if len(delegation.agents) == 0 {
+					ui.Println(theme.Info, "No sub-agent, see the agents section of", configPath())
+				}
+				for _, name := range delegation.names() {
+					ui.Printf(theme.Info, "%s - %s\n", name, delegation.agents[name].Description)
+				}
+				return nil
+			}
+			if task == "" {
+				return fmt.Errorf("usage: /spawn <agent> <task>")
+			}
+
+			thinkingCtrl := ui.NewThinkingController()
+			thinkingCtrl.Start(theme.Tool, "Sub-agent "+name+" working...")
+			delegation.bind(ctx, toolAgent.GetModel(), executeFunction(delegation.toolbox, approver, thinkingCtrl))
+			answer, err := delegation.spawn(name, task)
+			thinkingCtrl.Stop()
+			if err != nil {
+				return err
+			}
+			ui.PrintMarkdown(answer)
+			fmt.Println()
+
+			// The result is returned into the main conversation
+			session.SetMessages(append(session.Messages,
+				openai.UserMessage(fmt.Sprintf("Task delegated to the sub-agent %s: %s", name, task)),
+				openai.AssistantMessage(answer),
+			))
+			toolAgent.SetMessages(session.Messages)
+			return session.save()
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/history",
+		Description: "Display the messages of the conversation",
+		Handler: func(string) error {
+			toolAgent.SetMessages(session.Messages)
+			msg.DisplayHistory(toolAgent)
+			return nil
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/usage",
+		Description: "Show the token usage and the estimated cost of the session",
+		Handler: func(string) error {
+			printUsageSummary(session, usage)
+			return nil
+		},
+	})
+
+	registry.RegisterHelpCommand(theme.Info)
+	return registry
+}
+
+// printUsageSummary prints the size of the conversation and the token usage per model with the estimated cost
+func printUsageSummary(session *Session, usage *usageTracker) {
+	toolCalls := 0
+	for _, message := range session.Messages {
+		if message.OfAssistant != nil {
+			toolCalls += len(message.OfAssistant.ToolCalls)
+		}
+	}
+	theme := ui.GetTheme()
+	ui.Printf(theme.Info, "Turns: %d, messages: %d, tool calls: %d, context: ~%d tokens\n",
+		session.countUserMessages(), len(session.Messages), toolCalls, estimateTokens(session.Messages, session.Model))
+	ui.Printf(theme.Info, "Token usage of this run:\n%s", usage.summary())
+}
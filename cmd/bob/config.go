@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Tool approval policies
+const (
+	toolPolicyAsk   = "ask"   // confirm each call (default)
+	toolPolicyAllow = "allow" // always execute
+	toolPolicyDeny  = "deny"  // never execute
+)
+
+// Config is the persistent configuration of Bob (~/.bob/config.yaml)
+type Config struct {
+	// DefaultProfile is the provider profile used without --profile
+	DefaultProfile string `yaml:"default_profile,omitempty"`
+	// Profiles are the named provider profiles
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+	// DefaultToolPolicy applies to the tools without a policy: ask, allow or deny
+	DefaultToolPolicy string `yaml:"default_tool_policy,omitempty"`
+	// ToolPolicies gives the approval policy of each tool by name
+	ToolPolicies map[string]string `yaml:"tool_policies,omitempty"`
+	// MaxToolIterations limits the completions of a turn calling tools (unlimited if zero)
+	MaxToolIterations int `yaml:"max_tool_iterations,omitempty"`
+	// Shell configures the built-in run_shell tool
+	Shell ShellConfig `yaml:"shell,omitempty"`
+	// Pricing adds or overrides the prices of the models (matched by substring) used to estimate the cost
+	Pricing map[string]ModelPricing `yaml:"pricing,omitempty"`
+	// Files configures the built-in filesystem tools
+	Files FilesConfig `yaml:"files,omitempty"`
+	// MCPServers are the MCP servers whose tools are added to the ones of MCP_HOST_URL
+	MCPServers []MCPServerConfig `yaml:"mcp_servers,omitempty"`
+	// Plugins are the runtime plugins, executables providing extra tools
+	Plugins []PluginConfig `yaml:"plugins,omitempty"`
+	// Agents are the sub-agents the tasks can be delegated to
+	Agents map[string]SubAgentConfig `yaml:"agents,omitempty"`
+	// Memory configures the long-term memory
+	Memory MemoryConfig `yaml:"memory,omitempty"`
+	// Guard scans the tool results and the documents for prompt injections
+	Guard GuardConfig `yaml:"guard,omitempty"`
+	// Speech configures the voice output of the answers
+	Speech SpeechConfig `yaml:"speech,omitempty"`
+	// Images configures the image generation
+	Images ImagesConfig `yaml:"images,omitempty"`
+	// Code configures the built-in execute_code tool
+	Code CodeConfig `yaml:"code,omitempty"`
+	// Fetch configures the built-in fetch_url tool
+	Fetch FetchConfig `yaml:"fetch,omitempty"`
+
+	path string
+}
+
+// configPath returns the configuration file: BOB_CONFIG_FILE or ~/.bob/config.yaml
+func configPath() string {
+	if path := os.Getenv("BOB_CONFIG_FILE"); path != "" {
+		return path
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".bob", "config.yaml")
+	}
+	return filepath.Join(".bob", "config.yaml")
+}
+
+// loadConfig reads the configuration file, a missing file gives an empty configuration
+func loadConfig(path string) (*Config, error) {
+	config := &Config{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return config, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(data, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// save writes the configuration file
+func (c *Config) save() error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+// toolPolicies returns the approval policies of the tools: the names of the tool policies can be patterns
+// ("github_*"), an exact name wins over the patterns
+func (c *Config) toolPolicies() *approval.Policy {
+	rules := make(map[string]approval.Action, len(c.ToolPolicies))
+	for name, policy := range c.ToolPolicies {
+		rules[name] = approval.Action(policy)
+	}
+	return &approval.Policy{Rules: rules, Default: approval.Action(c.DefaultToolPolicy)}
+}
+
+// toolPolicy returns the approval policy of a tool
+func (c *Config) toolPolicy(toolName string) string {
+	return string(c.toolPolicies().Action(toolName))
+}
+
+// setToolPolicy sets and persists the approval policy of a tool
+func (c *Config) setToolPolicy(toolName, policy string) error {
+	if c.ToolPolicies == nil {
+		c.ToolPolicies = map[string]string{}
+	}
+	c.ToolPolicies[toolName] = policy
+	return c.save()
+}
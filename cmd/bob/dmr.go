@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	"github.com/micro-agent/micro-agent-go/agent/providers/dmr"
+	"github.com/micro-agent/micro-agent-go/agent/providers/ollama"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// pullFunc returns the function pulling the models of the provider of the profile, nil if it does not pull models
+func (p Profile) pullFunc(ollamaClient *ollama.Client) func(ctx context.Context, model string) error {
+	switch {
+	case ollamaClient != nil:
+		return func(ctx context.Context, model string) error {
+			return pullModel(ctx, ollamaClient, model, false)
+		}
+	case p.Provider == providerDockerModelRunner:
+		dmrClient := dmr.NewClient(p.BaseURL)
+		return func(ctx context.Context, model string) error {
+			return dmrPull(ctx, dmrClient, model)
+		}
+	}
+	return nil
+}
+
+// dmrPull pulls a model of Docker Model Runner and displays its progress
+func dmrPull(ctx context.Context, client *dmr.Client, model string) error {
+	var bar *ui.ProgressBar
+	defer func() {
+		if bar != nil {
+			bar.Done()
+		}
+	}()
+	return client.Pull(ctx, model, func(progress dmr.PullProgress) {
+		switch {
+		case progress.Type == "success":
+			if bar != nil {
+				bar.Done()
+				bar = nil
+			}
+			ui.Println(ui.GetTheme().Info, "✅ Model", model, "pulled")
+		case progress.Total > 0:
+			if bar == nil {
+				bar = ui.NewProgressBar(ui.GetTheme().Info, "⬇️  Pulling "+model, int(progress.Total))
+				bar.Start()
+			}
+			bar.Update(int(progress.Pulled), int(progress.Total))
+		}
+	})
+}
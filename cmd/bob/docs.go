@@ -0,0 +1,197 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/guard"
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// docsIndex is the local vector store of the documents ingested with --docs
+type docsIndex struct {
+	embeddingAgent mu.Agent
+	store          *rag.MemoryVectorStore
+	topN           int
+	similarity     float64
+	guard          *guard.Guard // optional, checks the chunks for prompt injections
+}
+
+// docsExtensions are the extensions of the ingested files
+var docsExtensions = []string{".md", ".txt"}
+
+// docsCachePath returns the cache file of the embeddings of a directory for an embedding model
+func docsCachePath(dir, embeddingModel string) string {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		absDir = dir
+	}
+	hash := sha256.Sum256([]byte(absDir + "|" + embeddingModel))
+	cacheDir := filepath.Join(".bob", "cache")
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		cacheDir = filepath.Join(homeDir, ".bob", "cache")
+	}
+	return filepath.Join(cacheDir, "docs-"+hex.EncodeToString(hash[:8])+".json")
+}
+
+// chunkID identifies a chunk by its content, so the cached embeddings of the unchanged chunks are reused
+func chunkID(source, chunk string) string {
+	hash := sha256.Sum256([]byte(source + "\n" + chunk))
+	return hex.EncodeToString(hash[:])
+}
+
+// docsMetadataHeader returns the TITLE and TAGS lines of the frontmatter metadata of a document, if any
+func docsMetadataHeader(metadata map[string]any) string {
+	header := ""
+	if title, ok := metadata["title"].(string); ok && title != "" {
+		header += "TITLE: " + title + "\n"
+	}
+	switch tags := metadata["tags"].(type) {
+	case []any:
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, fmt.Sprint(tag))
+		}
+		header += "TAGS: " + strings.Join(names, ", ") + "\n"
+	case string:
+		header += "TAGS: " + tags + "\n"
+	}
+	return header
+}
+
+// ingestDocs chunks the markdown and text files of dir and computes their embeddings.
+// The embeddings are cached: only the new or modified chunks are sent to the embedding model.
+func ingestDocs(dir string, embeddingAgent mu.Agent, embeddingModel string, showProgress bool) (*docsIndex, error) {
+	cachePath := docsCachePath(dir, embeddingModel)
+	cache := &rag.MemoryVectorStore{Records: make(map[string]rag.VectorRecord)}
+	if err := cache.Load(cachePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		ui.GetLogger().Warn("invalid docs cache, the documents are ingested again", "file", cachePath, "error", err)
+		cache.Records = make(map[string]rag.VectorRecord)
+	}
+
+	type pendingChunk struct {
+		id, source, content string
+		metadata            map[string]any
+	}
+	chunks := []pendingChunk{}
+	files, err := helpers.FindContentFiles(dir, helpers.WithExtensions(docsExtensions...), helpers.WithFollowSymlinks(true))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		var fileRecords []rag.VectorRecord
+		if strings.EqualFold(filepath.Ext(file.Path), ".md") {
+			if fileRecords, err = rag.MarkdownRecords(file.Content); err != nil {
+				ui.GetLogger().Warn("the frontmatter of the document is ignored", "file", file.RelPath, "error", err)
+				fileRecords = nil
+			}
+		}
+		if fileRecords == nil {
+			for _, chunk := range rag.ChunkText(file.Content, 1024, 128) {
+				fileRecords = append(fileRecords, rag.VectorRecord{Prompt: chunk})
+			}
+		}
+		for _, record := range fileRecords {
+			if strings.TrimSpace(record.Prompt) == "" {
+				continue
+			}
+			// The title and the tags of the frontmatter are embedded with the chunk
+			chunk := docsMetadataHeader(record.Metadata) + record.Prompt
+			chunks = append(chunks, pendingChunk{id: chunkID(file.RelPath, chunk), source: file.RelPath, content: chunk, metadata: record.Metadata})
+		}
+	}
+
+	store := &rag.MemoryVectorStore{Records: make(map[string]rag.VectorRecord)}
+	var progressBar *ui.ProgressBar
+	if showProgress {
+		progressBar = ui.NewProgressBar(ui.GetTheme().Info, "📚 Ingesting the documents", len(chunks))
+		progressBar.Start()
+	}
+	embedded := 0
+	for idx, chunk := range chunks {
+		if record, ok := cache.Records[chunk.id]; ok {
+			store.Records[chunk.id] = record
+		} else {
+			// The source file is kept with the chunk to be cited in the prompt
+			prompt := "SOURCE: " + chunk.source + "\n" + chunk.content
+			embedding, err := embeddingAgent.GenerateEmbeddingVector(prompt)
+			if err != nil {
+				if progressBar != nil {
+					progressBar.Done()
+				}
+				return nil, fmt.Errorf("failed to compute the embedding of a chunk of %s: %w", chunk.source, err)
+			}
+			metadata := map[string]any{"source": chunk.source}
+			for key, value := range chunk.metadata {
+				metadata[key] = value
+			}
+			store.Records[chunk.id] = rag.VectorRecord{Id: chunk.id, Prompt: prompt, Embedding: embedding, Metadata: metadata}
+			embedded++
+		}
+		if progressBar != nil {
+			progressBar.Update(idx+1, len(chunks))
+		}
+	}
+	if progressBar != nil {
+		progressBar.Done()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err != nil {
+		return nil, err
+	}
+	if err := store.Persist(cachePath); err != nil {
+		return nil, err
+	}
+	ui.GetLogger().Info("documents ingested", "dir", dir, "chunks", len(chunks), "embedded", embedded, "cached", len(chunks)-embedded)
+
+	return &docsIndex{
+		embeddingAgent: embeddingAgent,
+		store:          store,
+		topN:           3,
+		similarity:     0.5,
+	}, nil
+}
+
+// augment adds the most similar chunks of the documents to the prompt (the prompt is unchanged if none is found)
+func (d *docsIndex) augment(prompt string) string {
+	if d == nil {
+		return prompt
+	}
+	embedding, err := d.embeddingAgent.GenerateEmbeddingVector(prompt)
+	if err != nil {
+		ui.GetLogger().Error("failed to compute the embedding of the prompt", "error", err)
+		return prompt
+	}
+	similarities, err := d.store.SearchTopNSimilarities(rag.VectorRecord{Embedding: embedding}, d.similarity, d.topN)
+	if err != nil || len(similarities) == 0 {
+		return prompt
+	}
+
+	chunks := make([]string, 0, len(similarities))
+	for _, similarity := range similarities {
+		chunks = append(chunks, similarity.Prompt)
+	}
+	if d.guard != nil {
+		chunks = d.guard.FilterChunks("docs", chunks)
+		if len(chunks) == 0 {
+			return prompt
+		}
+	}
+
+	var builder strings.Builder
+	builder.WriteString("Use the following documentation excerpts to answer if they are relevant:\n")
+	for _, chunk := range chunks {
+		builder.WriteString("<document>\n" + chunk + "\n</document>\n")
+	}
+	builder.WriteString("\n" + prompt)
+	ui.GetLogger().Debug("prompt augmented with the documents", "chunks", len(chunks))
+	return builder.String()
+}
@@ -0,0 +1,256 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/msg"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/alecthomas/chroma/v2"
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// transcriptEntry is a message of the exported transcript, the tool calls are attached to the assistant messages
+type transcriptEntry struct {
+	Role      string
+	Time      time.Time
+	Content   string
+	ToolCalls []transcriptToolCall
+}
+
+type transcriptToolCall struct {
+	Name      string
+	Arguments string
+	Result    string
+}
+
+// transcript returns the entries of the session: the tool results are attached to their calls
+func transcript(session *Session) []transcriptEntry {
+	results := map[string]string{}
+	for _, exchange := range msg.ToolExchanges(session.Messages) {
+		results[exchange.ID] = exchange.Result
+	}
+
+	entries := []transcriptEntry{}
+	for idx, message := range session.Messages {
+		if message.OfTool != nil {
+			continue
+		}
+		entry := transcriptEntry{Role: msg.Role(message), Time: session.MessageTime(idx), Content: msg.Content(message)}
+		for _, toolCall := range msg.MessageToolCalls(message) {
+			entry.ToolCalls = append(entry.ToolCalls, transcriptToolCall{
+				Name:      toolCall.Name,
+				Arguments: toolCall.PrettyArguments(),
+				Result:    msg.PrettyJSON(results[toolCall.ID]),
+			})
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// roleTitle returns the title of the messages of a role
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "🧑 User"
+	case "assistant":
+		return "🤖 Bob"
+	case "system", "developer":
+		return "⚙️ System"
+	}
+	return role
+}
+
+// entryHeading returns the heading of a message: its role and its time if known
+func entryHeading(entry transcriptEntry) string {
+	if entry.Time.IsZero() {
+		return roleTitle(entry.Role)
+	}
+	return roleTitle(entry.Role) + " · " + entry.Time.Format("2006-01-02 15:04:05")
+}
+
+// exportMarkdown renders the session as a markdown transcript, the system message and the tool calls are collapsed
+func exportMarkdown(session *Session) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "# Bob session %s\n\n", session.ID)
+	fmt.Fprintf(&builder, "_Model: %s · created %s · updated %s_\n\n",
+		session.Model, session.CreatedAt.Format("2006-01-02 15:04"), session.UpdatedAt.Format("2006-01-02 15:04"))
+
+	for _, entry := range transcript(session) {
+		if entry.Role == "system" || entry.Role == "developer" {
+			fmt.Fprintf(&builder, "<details>\n<summary>%s</summary>\n\n%s\n\n</details>\n\n", entryHeading(entry), strings.TrimSpace(entry.Content))
+			continue
+		}
+		if entry.Content == "" && len(entry.ToolCalls) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "## %s\n\n", entryHeading(entry))
+		if content := strings.TrimSpace(entry.Content); content != "" {
+			builder.WriteString(content + "\n\n")
+		}
+		for _, toolCall := range entry.ToolCalls {
+			fmt.Fprintf(&builder, "<details>\n<summary>🛠️ Tool call: <code>%s</code></summary>\n\n", toolCall.Name)
+			fmt.Fprintf(&builder, "**Arguments**\n\n```json\n%s\n```\n\n", toolCall.Arguments)
+			fmt.Fprintf(&builder, "**Result**\n\n```json\n%s\n```\n\n</details>\n\n", toolCall.Result)
+		}
+	}
+	return builder.String()
+}
+
+// exportHTMLStyle is the stylesheet of the HTML transcripts
+const exportHTMLStyle = `
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 860px; margin: 2em auto; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+.meta { color: #656d76; font-style: italic; }
+.message { border: 1px solid #d0d7de; border-radius: 8px; padding: 0.5em 1em; margin: 1em 0; }
+.message.user { background: #f6f8fa; }
+.message h2 { font-size: 1em; margin: 0.3em 0 0.6em; color: #656d76; }
+pre { padding: 0.8em; border-radius: 6px; overflow-x: auto; background: #f6f8fa; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 0.9em; }
+details { margin: 0.5em 0; }
+summary { cursor: pointer; color: #656d76; }
+`
+
+// exportHTML renders the session as a standalone HTML page, the code blocks are highlighted
+func exportHTML(session *Session) string {
+	var builder strings.Builder
+	builder.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&builder, "<title>Bob session %s</title>\n<style>%s</style>\n</head>\n<body>\n", html.EscapeString(session.ID), exportHTMLStyle)
+	fmt.Fprintf(&builder, "<h1>Bob session %s</h1>\n", html.EscapeString(session.ID))
+	fmt.Fprintf(&builder, "<p class=\"meta\">Model: %s · created %s · updated %s</p>\n",
+		html.EscapeString(session.Model), session.CreatedAt.Format("2006-01-02 15:04"), session.UpdatedAt.Format("2006-01-02 15:04"))
+
+	for _, entry := range transcript(session) {
+		if entry.Role == "system" || entry.Role == "developer" {
+			fmt.Fprintf(&builder, "<details>\n<summary>%s</summary>\n%s</details>\n", html.EscapeString(entryHeading(entry)), markdownToHTML(entry.Content))
+			continue
+		}
+		if entry.Content == "" && len(entry.ToolCalls) == 0 {
+			continue
+		}
+		fmt.Fprintf(&builder, "<div class=\"message %s\">\n<h2>%s</h2>\n", html.EscapeString(entry.Role), html.EscapeString(entryHeading(entry)))
+		builder.WriteString(markdownToHTML(entry.Content))
+		for _, toolCall := range entry.ToolCalls {
+			fmt.Fprintf(&builder, "<details>\n<summary>🛠️ Tool call: <code>%s</code></summary>\n", html.EscapeString(toolCall.Name))
+			builder.WriteString("<p><strong>Arguments</strong></p>\n" + highlightHTML(toolCall.Arguments, "json"))
+			builder.WriteString("<p><strong>Result</strong></p>\n" + highlightHTML(toolCall.Result, "json"))
+			builder.WriteString("</details>\n")
+		}
+		builder.WriteString("</div>\n")
+	}
+	builder.WriteString("</body>\n</html>\n")
+	return builder.String()
+}
+
+var (
+	inlineCodeRegexp = regexp.MustCompile("`([^`]+)`")
+	boldRegexp       = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	headingRegexp    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+)
+
+// markdownToHTML converts the markdown of a message to HTML: fenced code blocks (highlighted), headings,
+// paragraphs, inline code and bold text; the other syntax is displayed as text
+func markdownToHTML(content string) string {
+	var builder strings.Builder
+	var paragraph []string
+	flush := func() {
+		if len(paragraph) > 0 {
+			builder.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for idx := 0; idx < len(lines); idx++ {
+		line := lines[idx]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flush()
+			language := strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))
+			code := []string{}
+			for idx++; idx < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[idx]), "```"); idx++ {
+				code = append(code, lines[idx])
+			}
+			builder.WriteString(highlightHTML(strings.Join(code, "\n"), language))
+		case trimmed == "":
+			flush()
+		case headingRegexp.MatchString(trimmed):
+			flush()
+			matches := headingRegexp.FindStringSubmatch(trimmed)
+			level := min(len(matches[1])+2, 6) // h1 and h2 are used by the page
+			fmt.Fprintf(&builder, "<h%d>%s</h%d>\n", level, inlineHTML(matches[2]), level)
+		default:
+			paragraph = append(paragraph, inlineHTML(line))
+		}
+	}
+	flush()
+	return builder.String()
+}
+
+// inlineHTML escapes a line of text and converts its inline code and bold text
+func inlineHTML(text string) string {
+	text = html.EscapeString(text)
+	text = inlineCodeRegexp.ReplaceAllString(text, "<code>$1</code>")
+	return boldRegexp.ReplaceAllString(text, "<strong>$1</strong>")
+}
+
+// highlightHTML renders a code block with chroma (inline styles), escaped without highlighting on error
+func highlightHTML(code, language string) string {
+	lexer := lexers.Get(language)
+	if lexer == nil {
+		lexer = lexers.Analyse(code)
+	}
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code)
+	if err == nil {
+		var buffer bytes.Buffer
+		formatter := chromahtml.New(chromahtml.WithClasses(false), chromahtml.TabWidth(4))
+		if err = formatter.Format(&buffer, styles.Get("github"), iterator); err == nil {
+			return buffer.String() + "\n"
+		}
+	}
+	return "<pre><code>" + html.EscapeString(code) + "</code></pre>\n"
+}
+
+// exportSession writes the transcript of the session, as HTML if the file extension is .html or .htm,
+// as markdown otherwise; redact masks the API keys, tokens and emails of the messages
+func exportSession(session *Session, path string, redact bool) error {
+	if redact {
+		// The transcript is made from a redacted copy of the session
+		redacted := *session.Session
+		redacted.Messages = msg.Redact(session.Messages, nil)
+		session = &Session{Session: &redacted, Model: session.Model}
+	}
+	content := exportMarkdown(session)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		content = exportHTML(session)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	ui.GetLogger().Info("session exported", "session", session.ID, "file", path)
+	return nil
+}
+
+// defaultExportPath returns the export file of a session in the current directory
+func defaultExportPath(session *Session) string {
+	return "bob-" + session.ID + ".md"
+}
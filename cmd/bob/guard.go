@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/micro-agent/micro-agent-go/agent/guard"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// GuardConfig configures the prompt injection guard of the tool results and of the documents
+type GuardConfig struct {
+	// Action on the injections: flag, strip or block (no guard if empty or off)
+	Action string `yaml:"action,omitempty"`
+	// Patterns are extra injection patterns (regular expressions) by name
+	Patterns map[string]string `yaml:"patterns,omitempty"`
+}
+
+// newGuard creates the guard of the configuration, nil if it is disabled
+func newGuard(config GuardConfig) (*guard.Guard, error) {
+	if config.Action == "" || config.Action == "off" {
+		return nil, nil
+	}
+	action, err := guard.ParseAction(config.Action)
+	if err != nil {
+		return nil, err
+	}
+	patterns := guard.DefaultPatterns()
+	names := make([]string, 0, len(config.Patterns))
+	for name := range config.Patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(config.Patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid guard pattern %s: %w", name, err)
+		}
+		patterns = append(patterns, guard.Pattern{Name: name, Regexp: re})
+	}
+	return guard.NewGuard(
+		guard.WithAction(action),
+		guard.WithPatterns(patterns...),
+		guard.WithOnDetection(func(source string, findings []guard.Finding) {
+			ui.GetLogger().Warn("possible prompt injection", "source", source, "action", action.String(), "findings", findings)
+		}),
+	), nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// doubleInterruptDelay is the delay of the second Ctrl+C that exits Bob
+const doubleInterruptDelay = 2 * time.Second
+
+// interruptHandler turns Ctrl+C into the cancellation of the current generation:
+// the first one returns to the prompt, a second one within doubleInterruptDelay exits Bob.
+// At the prompt, the terminal is in raw mode and Ctrl+C is handled by the input (see confirmExit).
+type interruptHandler struct {
+	mu            sync.Mutex
+	signals       chan os.Signal
+	cancel        context.CancelFunc // cancels the current generation, nil at the prompt
+	last          time.Time
+	exitRequested bool
+}
+
+// newInterruptHandler catches SIGINT until stop is called
+func newInterruptHandler() *interruptHandler {
+	h := &interruptHandler{signals: make(chan os.Signal, 1)}
+	signal.Notify(h.signals, os.Interrupt)
+	go func() {
+		for range h.signals {
+			h.interrupt()
+		}
+	}()
+	return h
+}
+
+// stop restores the default behavior of SIGINT
+func (h *interruptHandler) stop() {
+	signal.Stop(h.signals)
+	close(h.signals)
+}
+
+// interrupt cancels the current generation, or requests the exit on a second Ctrl+C
+func (h *interruptHandler) interrupt() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := time.Now()
+	double := now.Sub(h.last) < doubleInterruptDelay
+	h.last = now
+	if h.cancel != nil {
+		h.cancel()
+	}
+	if double {
+		h.exitRequested = true
+		if h.cancel == nil {
+			// Nothing to wait for: the program is not in the generation loop
+			ui.Println(ui.Green, "\nGoodbye!")
+			os.Exit(130)
+		}
+		return
+	}
+	ui.Println(ui.GetTheme().Warning, "\n⏹  Interrupted (press Ctrl+C again to exit)")
+}
+
+// startGeneration returns the context of a generation, cancelled by Ctrl+C
+func (h *interruptHandler) startGeneration(ctx context.Context) context.Context {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	ctx, h.cancel = context.WithCancel(ctx)
+	return ctx
+}
+
+// endGeneration releases the context of the current generation
+func (h *interruptHandler) endGeneration() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// shouldExit reports whether a double Ctrl+C requested the exit
+func (h *interruptHandler) shouldExit() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.exitRequested
+}
+
+// confirmExit handles a Ctrl+C at the prompt: it returns true on the second one within doubleInterruptDelay
+func (h *interruptHandler) confirmExit() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if now.Sub(h.last) < doubleInterruptDelay {
+		return true
+	}
+	h.last = now
+	ui.Println(ui.GetTheme().Warning, "Press Ctrl+C again to exit (or /bye)")
+	return false
+}
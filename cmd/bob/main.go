@@ -2,60 +2,288 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+	"github.com/micro-agent/micro-agent-go/agent/memory"
 	"github.com/micro-agent/micro-agent-go/agent/mu"
-	"github.com/micro-agent/micro-agent-go/agent/tools"
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
 	"github.com/micro-agent/micro-agent-go/agent/ui"
 
-	"github.com/mark3labs/mcp-go/mcp"
-
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 )
 
 func main() {
 
+	sessionName := flag.String("session", "", "name of the session (continued if it exists)")
+	resume := flag.Bool("resume", false, "continue the most recent session")
+	listSessionsFlag := flag.Bool("sessions", false, "list the saved sessions and exit")
+	promptFlag := flag.String("p", "", "run a single prompt without interaction (the piped standard input is appended)")
+	approveTools := flag.Bool("approve-tools", false, "execute the tool calls without confirmation in non-interactive mode")
+	outputFormat := flag.String("output", "text", "output format of the non-interactive mode: text or json")
+	docsDir := flag.String("docs", "", "directory of markdown/text documents used to augment the prompts (RAG)")
+	docsTopN := flag.Int("docs-top", 3, "maximum number of document chunks added to a prompt")
+	docsSimilarity := flag.Float64("docs-similarity", 0.5, "minimum cosine similarity of the document chunks added to a prompt")
+	shellTool := flag.Bool("shell", false, "enable the built-in run_shell tool (see the shell section of the configuration)")
+	filesTools := flag.Bool("files", false, "enable the built-in filesystem tools (see the files section of the configuration)")
+	profileName := flag.String("profile", os.Getenv("BOB_PROFILE"), "name of the provider profile of the configuration (default_profile if empty)")
+	exportPath := flag.String("export", "", "export the session given by -session (the most recent otherwise) to a markdown or HTML transcript and exit")
+	redactExport := flag.Bool("redact", false, "mask the API keys, tokens and emails of the transcript exported with -export")
+	verbose := flag.Bool("verbose", false, "trace the requests to the provider, the retries and the tool calls with their timings")
+	flag.BoolVar(verbose, "v", false, "shorthand for -verbose")
+	veryVerbose := flag.Bool("vv", false, "trace the sanitized request and response payloads too")
+	memoryFlag := flag.Bool("memory", false, "remember the facts learned in the conversations across the sessions (long-term memory)")
+	imagesFlag := flag.Bool("images", false, "enable the built-in generate_image tool (see the images section of the configuration)")
+	codeFlag := flag.Bool("code", false, "enable the built-in execute_code tool, which runs programs in containers (see the code section of the configuration)")
+	fetchFlag := flag.Bool("fetch", false, "enable the built-in fetch_url tool, which reads web pages as markdown (see the fetch section of the configuration)")
+	audioFile := flag.String("audio", "", "transcribe this audio file and send it as the prompt, without interaction (appended to -p)")
+	speak := flag.Bool("speak", false, "speak the answers aloud (see the speech section of the configuration)")
+	traceFile := flag.String("trace-file", "", "write the traces to this file instead of the standard error (implies -verbose)")
+	flag.Parse()
+
+	if *listSessionsFlag {
+		saved, err := listSessions(sessionStore())
+		if err != nil {
+			panic(fmt.Errorf("failed to list the sessions: %v", err))
+		}
+		if len(saved) == 0 {
+			fmt.Println("No saved session in", sessionsDir())
+		}
+		for _, session := range saved {
+			ui.Printf(ui.GetTheme().Info, "%-20s %s  %3d turns  %s\n",
+				session.ID, session.UpdatedAt.Format("2006-01-02 15:04"), session.countUserMessages(), session.Model)
+		}
+		return
+	}
+
+	if *exportPath != "" {
+		var session *Session
+		var err error
+		if *sessionName != "" {
+			session, err = loadSession(sessionStore(), *sessionName)
+		} else {
+			session, err = latestSession(sessionStore())
+			if session == nil && err == nil {
+				err = errors.New("no session to export")
+			}
+		}
+		if err == nil {
+			err = exportSession(session, *exportPath, *redactExport)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to export the session:", err)
+			os.Exit(exitFailure)
+		}
+		fmt.Println("Session", session.ID, "exported to", *exportPath)
+		return
+	}
+
+	config, err := loadConfig(configPath())
+	if err != nil {
+		panic(fmt.Errorf("failed to load the configuration: %v", err))
+	}
+
+	// Subcommands: bob serve [flags], bob run [flags] script, bob worker [flags], bob pull model, bob bench [flags] prompts
+	command := flag.Arg(0)
+	if command != "" && command != "serve" && command != "run" && command != "worker" && command != "pull" && command != "bench" {
+		fmt.Fprintln(os.Stderr, "unknown command:", command)
+		os.Exit(exitUsageError)
+	}
+
+	// Non-interactive mode: bob -p "question" or echo "question" | bob
+	prompt, nonInteractive := "", false
+	if command == "" {
+		prompt, nonInteractive, err = readNonInteractivePrompt(*promptFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to read the standard input:", err)
+			os.Exit(exitUsageError)
+		}
+		if *audioFile != "" {
+			nonInteractive = true
+		}
+		if nonInteractive && prompt == "" && *audioFile == "" {
+			fmt.Fprintln(os.Stderr, "empty prompt")
+			os.Exit(exitUsageError)
+		}
+	}
+	// Without interaction, the errors are reported with an exit code instead of a panic
+	headless := nonInteractive || command != ""
+
 	ctx := context.Background()
 
-	baseURL := os.Getenv("PROVIDER_BASE_URL")
-	if baseURL == "" {
-		baseURL = "http://localhost:12434/engines/llama.cpp/v1"
+	// The profile bundles the provider, the models and the default parameters
+	profile, err := config.profile(*profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsageError)
 	}
+	profile = profile.resolve()
 
-	apiKey := os.Getenv("PROVIDER_API_KEY")
-	if apiKey == "" {
-		apiKey = ""
+	// Ollama is used with its native API (keep_alive, model options, pulling of the models)
+	ollamaClient, err := profile.ollamaClient(headless)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsageError)
+	}
+	if command == "pull" {
+		os.Exit(runPull(ctx, profile.pullFunc(ollamaClient), flag.Args()[1:]))
 	}
 
-	client := openai.NewClient(
-		option.WithBaseURL(baseURL),
-		option.WithAPIKey(apiKey),
-	)
+	// The token usage reported by the provider is counted per model to estimate the cost
+	usage := newUsageTracker(config.Pricing)
+	clientOptions, err := profile.clientOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsageError)
+	}
+	clientOptions = append(clientOptions, option.WithMiddleware(usage.middleware))
+
+	// Request tracing (the last middleware, before the ollama adapter, sees the requests as they are sent)
+	traceLevel := traceOff
+	switch {
+	case *veryVerbose:
+		traceLevel = tracePayloads
+	case *verbose || *traceFile != "":
+		traceLevel = traceRequests
+	}
+	var requestTracer *tracer
+	if traceLevel > traceOff {
+		requestTracer, err = newTracer(traceLevel, *traceFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to open the trace file:", err)
+			os.Exit(exitUsageError)
+		}
+		defer requestTracer.Close()
+		clientOptions = append(clientOptions, option.WithMiddleware(requestTracer.middleware))
+	}
+	if ollamaClient != nil {
+		// The native adapter is the last middleware: the usage and the traces see OpenAI payloads
+		clientOptions = append(clientOptions, ollamaClient.RequestOptions()...)
+	}
+	client := openai.NewClient(clientOptions...)
+	if command == "bench" {
+		os.Exit(runBench(ctx, flag.Args()[1:], client, profile.Model))
+	}
+
+	// Diagnostic logs: on the terminal, or only in a file to keep the terminal clean
+	loggerOptions := []ui.LoggerOption{}
+	if logFile := os.Getenv("BOB_LOG_FILE"); logFile != "" {
+		loggerOptions = append(loggerOptions, ui.WithLogFile(logFile), ui.WithoutTerminalLogs(), ui.WithLogLevel(slog.LevelDebug))
+	}
+	logger, err := ui.NewLogger(loggerOptions...)
+	if err != nil {
+		panic(fmt.Errorf("failed to create the logger: %v", err))
+	}
+	defer logger.Close()
+	ui.SetLogger(logger)
+	// The library logs too, e.g. the redacted requests to the model at the debug level of BOB_LOG_FILE
+	logging.SetDefault(logger)
 
 	mcpHostURL := os.Getenv("MCP_HOST_URL")
 	if mcpHostURL == "" {
 		mcpHostURL = "http://localhost:9011"
 	}
 
-	mcpClient, err := tools.NewStreamableHttpMCPClient(ctx, mcpHostURL)
+	// Built-in tools executed by Bob itself
+	if *shellTool {
+		config.Shell.Enabled = true
+	}
+	builtins := []builtinTool{}
+	if config.Shell.Enabled {
+		tool, err := newShellTool(config.Shell)
+		if err != nil {
+			panic(fmt.Errorf("failed to create the shell tool: %v", err))
+		}
+		builtins = append(builtins, tool)
+	}
+	if *filesTools {
+		config.Files.Enabled = true
+	}
+	if config.Files.Enabled {
+		fileTools, err := newFileTools(config.Files)
+		if err != nil {
+			panic(fmt.Errorf("failed to create the filesystem tools: %v", err))
+		}
+		builtins = append(builtins, fileTools...)
+	}
+
+	imageTool, err := newImageTool(ctx, client, config.Images)
 	if err != nil {
-		panic(fmt.Errorf("failed to create MCP client: %v", err))
+		panic(fmt.Errorf("failed to create the image tool: %v", err))
+	}
+	if *imagesFlag || config.Images.Enabled {
+		builtins = append(builtins, newImageBuiltinTool(imageTool))
+	}
+	if *codeFlag || config.Code.Enabled {
+		builtins = append(builtins, newCodeBuiltinTool(newCodeTool(config.Code)))
+	}
+	if *fetchFlag || config.Fetch.Enabled {
+		builtins = append(builtins, newFetchBuiltinTool(newFetchTool(config.Fetch)))
 	}
 
-	ui.Println(ui.Purple, "MCP Client initialized successfully")
-	toolsIndex := mcpClient.OpenAITools()
-	for _, tool := range toolsIndex {
-		ui.Printf(ui.Magenta, "Tool: %s - %s\n", tool.GetFunction().Name, tool.GetFunction().Description)
+	// Plugins: the tools registered at build time, then the runtime plugins of the configuration
+	builtins = append(builtins, newRegisteredTools()...)
+	for _, plugin := range config.Plugins {
+		pluginTools, err := newPluginTools(plugin)
+		if err != nil {
+			logger.Warn("plugin unavailable, its tools are disabled", "command", plugin.Command, "error", err)
+			continue
+		}
+		builtins = append(builtins, pluginTools...)
 	}
 
-	modelID := os.Getenv("MODEL_ID")
-	if modelID == "" {
-		modelID = "hf.co/menlo/jan-nano-gguf:q4_k_m"
+	// The MCP servers are optional when built-in tools are enabled
+	mcpRouter, err := connectMCPServers(ctx, mcpHostURL, config.MCPServers)
+	if err != nil && len(builtins) > 0 {
+		logger.Warn("MCP server unavailable, only the built-in tools are enabled", "url", mcpHostURL, "error", err)
+		mcpRouter = nil
+	} else if err != nil && headless {
+		fmt.Fprintln(os.Stderr, "failed to create MCP client:", err)
+		os.Exit(exitFailure)
+	} else if err != nil {
+		panic(fmt.Errorf("failed to create MCP client: %v", err))
+	}
+	if mcpRouter != nil {
+		defer mcpRouter.Close()
 	}
 
+	// The tool results (and the documents) are checked for prompt injections when the guard is enabled
+	injectionGuard, err := newGuard(config.Guard)
+	if err != nil && headless {
+		fmt.Fprintln(os.Stderr, "invalid guard configuration:", err)
+		os.Exit(exitFailure)
+	} else if err != nil {
+		panic(fmt.Errorf("invalid guard configuration: %v", err))
+	}
+	toolbox := newToolbox(mcpRouter)
+	toolbox.tracer = requestTracer
+	toolbox.guard = injectionGuard
+	for _, tool := range builtins {
+		toolbox.register(tool)
+	}
+	// The delegation tool is enabled when sub-agents are configured (/spawn uses them too)
+	delegation := newDelegator(ctx, client, toolbox, config.Agents, profile.Model)
+	if len(config.Agents) > 0 {
+		toolbox.register(delegation.tool())
+	}
+	toolsIndex := toolbox.openAITools()
+	if !headless {
+		for _, tool := range toolsIndex {
+			ui.Printf(ui.GetTheme().Tool, "Tool: %s - %s\n", tool.GetFunction().Name, tool.GetFunction().Description)
+		}
+	}
+
+	modelID := profile.Model
+
 	systemMessage := os.Getenv("SYSTEM_MESSAGE")
 	if systemMessage == "" {
 		systemMessage = `
@@ -63,35 +291,251 @@ func main() {
 		`
 	}
 
-	toolAgent, err := mu.NewAgent(ctx, "Bob",
-		mu.WithClient(client),
-		mu.WithParams(openai.ChatCompletionNewParams{
-			Model:       modelID,
+	// The server creates an agent per request, the interactive loop cancels its turns with Ctrl+C
+	newToolAgent := func(ctx context.Context, model string) (mu.Agent, error) {
+		params := openai.ChatCompletionNewParams{
+			Model:       model,
 			Temperature: openai.Opt(0.0),
 			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
 				OfAuto: openai.String("auto"),
 			},
 			Tools:             toolsIndex,
 			ParallelToolCalls: openai.Opt(false),
+		}
+		profile.applyParams(&params)
+		return mu.NewAgent(ctx, "Bob",
+			mu.WithClient(client),
+			mu.WithParams(params),
+			mu.WithMaxToolIterations(config.MaxToolIterations),
+		)
+	}
+	toolAgent, err := newToolAgent(ctx, modelID)
+	if err != nil {
+		panic(err)
+	}
+
+	// Documents ingested into a local vector store, the most similar chunks are added to each prompt
+	embeddingModel := profile.EmbeddingModel
+	embeddingAgent, err := mu.NewAgent(ctx, "Bob embeddings",
+		mu.WithClient(client),
+		mu.WithEmbeddingParams(openai.EmbeddingNewParams{
+			Model: embeddingModel,
 		}),
 	)
 	if err != nil {
 		panic(err)
 	}
+	var docs *docsIndex
+	if *docsDir != "" {
+		docs, err = ingestDocs(*docsDir, embeddingAgent, embeddingModel, !headless)
+		if err != nil && headless {
+			fmt.Fprintln(os.Stderr, "failed to ingest the documents:", err)
+			os.Exit(exitFailure)
+		}
+		if err != nil {
+			panic(fmt.Errorf("failed to ingest the documents: %v", err))
+		}
+		docs.topN = *docsTopN
+		docs.similarity = *docsSimilarity
+		docs.guard = injectionGuard
+	}
+
+	headlessBackend := &backend{
+		newAgent: func(model string) (mu.Agent, error) {
+			return newToolAgent(ctx, model)
+		},
+		toolbox:       toolbox,
+		delegation:    delegation,
+		config:        config,
+		docs:          docs,
+		embedder:      embeddingAgent,
+		model:         modelID,
+		systemMessage: systemMessage,
+	}
+	switch command {
+	case "serve":
+		os.Exit(runServer(flag.Args()[1:], &server{backend: headlessBackend}))
+	case "run":
+		os.Exit(runScript(flag.Args()[1:], headlessBackend))
+	case "worker":
+		os.Exit(runWorker(flag.Args()[1:], headlessBackend))
+	}
+
+	if nonInteractive {
+		if *audioFile != "" {
+			// The voice prompt is transcribed with the speech API of the configuration
+			transcription, err := newVoice(client, config.Speech).transcribeFile(ctx, *audioFile)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(exitFailure)
+			}
+			prompt = strings.TrimSpace(prompt + "\n\n" + transcription)
+		}
+		delegation.bindUnattended(ctx, config, *approveTools)
+		os.Exit(runNonInteractive(toolAgent, toolbox, config, systemMessage, docs.augment(prompt), *approveTools, *outputFormat))
+	}
+
+	historyFile := os.Getenv("BOB_HISTORY_FILE")
+	if historyFile == "" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			historyFile = filepath.Join(homeDir, ".bob", "history")
+		}
+	}
+	history, err := ui.NewHistory(historyFile, 1000)
+	if err != nil {
+		panic(fmt.Errorf("failed to load the prompt history: %v", err))
+	}
+
+	// Bell and/or desktop notification when an answer takes more than 10 seconds
+	notifier := ui.CompletionNotifier{MinDuration: 10 * time.Second}
+	switch os.Getenv("BOB_NOTIFY") {
+	case "bell":
+		notifier.Bell = true
+	case "desktop":
+		notifier.Desktop = true
+	case "both":
+		notifier.Bell = true
+		notifier.Desktop = true
+	}
+
+	// Long answers are displayed in a scrollable pager
+	if os.Getenv("BOB_PAGER") == "true" {
+		ui.EnablePager(0)
+	}
+
+	// Model, context usage, cost and MCP state at the bottom of the terminal
+	var statusBar *ui.StatusBar
+	if os.Getenv("BOB_STATUS_BAR") == "true" {
+		statusBar = ui.NewStatusBar(ui.GetTheme().Info)
+		statusBar.SetModel(modelID)
+		statusBar.SetMCPConnected(mcpRouter != nil)
+		usage.onUsage = func(model string, _ tokenUsage, cost float64) {
+			statusBar.AddCost(cost)
+		}
+		statusBar.Show()
+		defer statusBar.Hide()
+	}
+
+	// The conversation is persisted to a session file after each answer
+	var session *Session
+	switch {
+	case *sessionName != "":
+		session, err = loadSession(sessionStore(), *sessionName)
+		if errors.Is(err, sessions.ErrNotFound) {
+			session, err = newSession(sessionStore(), *sessionName, modelID), nil
+		}
+	case *resume:
+		session, err = latestSession(sessionStore())
+		if session == nil && err == nil {
+			ui.Println(ui.GetTheme().Warning, "No session to resume, starting a new one")
+		}
+	}
+	if err != nil {
+		panic(fmt.Errorf("failed to load the session: %v", err))
+	}
+	if session == nil {
+		session = newSession(sessionStore(), "", modelID)
+	}
+	if len(session.Messages) > 0 {
+		ui.Printf(ui.GetTheme().Info, "Resuming the session %s (%d turns, last update %s)\n",
+			session.ID, session.countUserMessages(), session.UpdatedAt.Format("2006-01-02 15:04"))
+	} else {
+		session.SetMessages([]openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(systemMessage),
+		})
+	}
+
+	// Tool approval from the policies of the configuration and the user answers
+	approver := newToolApprover(config)
+
+	// In-chat commands (/help, /model, /reset...), /edit sets editedInput
+	editedInput := ""
+	commands := newCommandRegistry(ctx, client, toolAgent, session, toolsIndex, usage, delegation, approver, &editedInput)
+
+	// Long-term memory: the facts of each exchange are remembered, the relevant ones are recalled in the next prompts
+	if *memoryFlag {
+		config.Memory.Enabled = true
+	}
+	var longTerm *memory.LongTermMemory
+	if config.Memory.Enabled {
+		longTerm, err = newLongTermMemory(ctx, client, modelID, embeddingAgent, config.Memory)
+		if err != nil {
+			panic(fmt.Errorf("failed to load the long-term memory: %v", err))
+		}
+		registerMemoryCommands(commands, longTerm)
+	}
+	if ollamaClient != nil {
+		registerOllamaCommands(ctx, commands, ollamaClient)
+	}
+
+	// Voice output: the answers are spoken sentence by sentence while they are streamed
+	answerVoice := newVoice(client, config.Speech)
+	defer answerVoice.close()
+	if *speak || config.Speech.Enabled {
+		if err := answerVoice.enable(); err != nil {
+			ui.Println(ui.GetTheme().Warning, "Voice output disabled:", err)
+		}
+	}
+	registerSpeechCommands(commands, answerVoice)
+	registerImageCommand(commands, imageTool)
+	pendingImages := &attachments{}
+	registerAttachCommand(commands, pendingImages)
+
+	// Ctrl+C interrupts the generation, a double Ctrl+C exits
+	interrupts := newInterruptHandler()
+	defer interrupts.stop()
+	registerListenCommand(ctx, commands, answerVoice, interrupts, &editedInput)
+
+	// Tab completion of the slash commands, the tool names and the file paths
+	toolNames := []string{}
+	for _, tool := range toolsIndex {
+		toolNames = append(toolNames, tool.GetFunction().Name)
+	}
+	completion := ui.CombineCompletions(
+		ui.SlashCommandCompletion(commands.Names()...),
+		ui.StaticCompletion(toolNames...),
+		ui.FilePathCompletion(),
+	)
 
 	for {
-		content, _ := ui.SimplePrompt("🤖 (/bye to exit)>", "Type your command here...")
+		content, err := ui.PromptWithHistory("🤖 (/help for the commands, /bye to exit)>", history, ui.WithCompletion(completion))
+		if errors.Is(err, ui.ErrInputCancelled) && !interrupts.confirmExit() {
+			continue
+		}
+		if err != nil {
+			ui.Println(ui.Green, "Goodbye!")
+			break
+		}
+		if content.Input == "" {
+			continue
+		}
 
-		if content.Input == "/bye" {
+		// The slash commands are handled by Bob, not sent to the LLM
+		editedInput = ""
+		handled, err := commands.Execute(content.Input)
+		if errors.Is(err, ui.ErrExitCommand) {
 			ui.Println(ui.Green, "Goodbye!")
 			break
 		}
+		if err != nil {
+			ui.Println(ui.GetTheme().Error, err)
+			continue
+		}
+		if handled {
+			if editedInput == "" {
+				continue
+			}
+			content = ui.ParseUserCommand(editedInput)
+		}
 
-		// Say "Exit" to stop the process
-		messages := []openai.ChatCompletionMessageParamUnion{
-			openai.SystemMessage(systemMessage),
-			openai.UserMessage(content.Input),
+		userMessage, err := pendingImages.userMessage(docs.augment(content.Input))
+		if err != nil {
+			ui.Println(ui.GetTheme().Error, err)
+			continue
 		}
+		messages := append(session.Messages, userMessage)
+		// The user message is timestamped when it is sent
+		session.SetMessages(messages)
 
 		// Stream callback for real-time content display
 		streamCallback := func(thinkingCtrl, streamingCtrl *ui.ThinkingController) func(string) error {
@@ -99,7 +543,7 @@ func main() {
 			return func(content string) error {
 				if thinkingCtrl.IsStarted() {
 					thinkingCtrl.Stop()
-					streamingCtrl.Start(ui.Green, "Streaming...")
+					streamingCtrl.Start(ui.GetTheme().Assistant, "Streaming...")
 				}
 
 				if content != "" {
@@ -116,71 +560,138 @@ func main() {
 			}
 		}
 
+		startTime := time.Now()
 		thinkingCtrl := ui.NewThinkingController()
-		thinkingCtrl.Start(ui.Blue, "Tools detection.....")
+		thinkingCtrl.Start(ui.GetTheme().Tool, "Tools detection.....")
 		streamingCtrl := ui.NewThinkingController()
 
-		// Create executeFunction with MCP client option
 		// Tool execution callback
-		executeFn := executeFunction(mcpClient, thinkingCtrl)
+		executeFn := executeFunction(toolbox, approver, thinkingCtrl)
 
-		_, _, assistantMessage, err := toolAgent.DetectToolCallsStream(messages, executeFn, streamCallback(thinkingCtrl, streamingCtrl))
-		if err != nil {
-			panic(err)
+		// The usage of the agent is counted per turn
+		if reporter, ok := toolAgent.(mu.UsageReporter); ok {
+			reporter.ResetUsage()
 		}
 
+		// Ctrl+C cancels the context of the turn and returns to the prompt
+		turnCtx := interrupts.startGeneration(ctx)
+		delegation.bind(turnCtx, toolAgent.GetModel(), executeFn)
+		requestMessages := recallMemories(longTerm, messages, content.Input)
+		_, _, assistantMessage, err := toolAgent.(mu.ContextRunner).DetectToolCallsStreamContext(turnCtx, requestMessages, executeFn, answerVoice.callback(streamCallback(thinkingCtrl, streamingCtrl)))
+		interrupted := turnCtx.Err() != nil
+		interrupts.endGeneration()
+		answerVoice.endAnswer(interrupted || err != nil)
+
 		thinkingCtrl.Stop()
+		streamingCtrl.Stop()
+
+		// A turn stopped by max_tool_iterations is kept as an interrupted one
+		var maxIterationsErr *mu.MaxIterationsExceededError
+		if errors.As(err, &maxIterationsErr) {
+			ui.Println(ui.GetTheme().Warning, "⚠️", maxIterationsErr.Error()+", ask again to go on (max_tool_iterations)")
+			interrupted = true
+		}
+
+		if interrupted {
+			// The user message and the completed tool exchanges are kept, the partial answer is dropped
+			session.SetMessages(restoreSystemMessage(toolAgent.GetMessages(), messages))
+			toolAgent.SetMessages(session.Messages)
+			if err := session.save(); err != nil {
+				ui.GetLogger().Error("failed to save the session", "session", session.ID, "error", err)
+			}
+			if interrupts.shouldExit() {
+				ui.Println(ui.Green, "Goodbye!")
+				break
+			}
+			continue
+		}
+		if err != nil {
+			panic(err)
+		}
+		notifier.Notify("Bob", "The answer is ready", time.Since(startTime))
 
 		fmt.Println()
 		fmt.Println()
 
 		ui.PrintMarkdown(assistantMessage)
 		fmt.Println()
-		streamingCtrl.Stop()
+		if reporter, ok := toolAgent.(mu.UsageReporter); ok && os.Getenv("BOB_TURN_USAGE") == "true" {
+			ui.Println(ui.GetTheme().Info, usage.turnSummary(toolAgent.GetModel(), reporter.GetUsage()))
+		}
+		memorize(longTerm, content.Input, assistantMessage)
+
+		// The agent messages contain the user message and the tool exchanges, but not the final answer
+		conversation := restoreSystemMessage(toolAgent.GetMessages(), messages)
+		if assistantMessage != "" {
+			conversation = append(conversation, openai.AssistantMessage(assistantMessage))
+		}
+		session.SetMessages(conversation)
+		toolAgent.SetMessages(conversation)
+		if err := session.save(); err != nil {
+			ui.GetLogger().Error("failed to save the session", "session", session.ID, "error", err)
+		}
+		if statusBar != nil {
+			statusBar.SetTokenUsage(estimateTokens(session.Messages, toolAgent.GetModel()), modelContextSize(toolAgent.GetModel()))
+			statusBar.Refresh()
+		}
 	}
 
+	// Usage and cost summary on exit
+	if statusBar != nil {
+		statusBar.Hide()
+	}
+	printUsageSummary(session, usage)
 }
 
-func executeFunction(mcpClient *tools.MCPClient, thinkingCtrl *ui.ThinkingController) func(string, string) (string, error) {
-
-	return func(functionName string, arguments string) (string, error) {
-
-		fmt.Printf("🟢 %s with arguments: %s\n", functionName, arguments)
+func executeFunction(toolbox *toolbox, approver *toolApprover, thinkingCtrl *ui.ThinkingController) func(string, string) (string, error) {
 
+	// The thinking animation is paused while the user answers
+	pausing := approval.ApproverFunc(func(ctx context.Context, call approval.ToolCall) (approval.Decision, error) {
 		thinkingCtrl.Pause()
-		//choice := ui.GetConfirmation(ui.Gray, "Do you want to execute this function?", true)
-		choice := ui.GetChoice(ui.Gray, "Do you want to execute this function? (y)es (n)o (a)bort", []string{"y",
-			"n", "a"}, "y")
-		thinkingCtrl.Resume()
-
-		switch choice {
-		case "n":
-			return `{"result": "Function not executed"}`, nil
-		case "a": // abort
-			return `{"result": "Function not executed"}`,
-				&mu.ExitToolCallsLoopError{Message: "Tool execution aborted by user"}
+		defer thinkingCtrl.Resume()
+		decision, err := approver.Approve(ctx, call)
+		if err == nil && decision.Action == approval.ActionModify {
+			fmt.Println("✏️  Executing with the edited arguments:")
+			ui.PrintJSON(decision.Arguments)
+		}
+		return decision, err
+	})
+	execute := approval.Wrap(context.Background(), pausing, func(functionName string, arguments string) (string, error) {
+		resultContent, err := toolbox.call(functionName, arguments)
+		if err == nil {
+			fmt.Println("✅ Tool executed successfully")
+		}
+		return resultContent, err
+	})
 
-		default:
+	return func(functionName string, arguments string) (string, error) {
 
-			// If MCP client is available, use it to execute the tool
-			if mcpClient != nil {
-				ctx := context.Background()
-				result, err := mcpClient.CallTool(ctx, functionName, arguments)
-				if err != nil {
-					return "", fmt.Errorf("MCP tool execution failed: %v", err)
-				}
-				// resultContent = toolResponse.Content[0].(mcp.TextContent).Text
-				// Convert MCP result to JSON string
-				if len(result.Content) > 0 {
-					// Take the first content item and return its text
-					resultContent := result.Content[0].(mcp.TextContent).Text
-					//fmt.Printf("✅ Tool executed successfully, result: %s\n", resultContent)
-					fmt.Println("✅ Tool executed successfully")
-					return fmt.Sprintf(`{"result": "%s"}`, resultContent), nil
-				}
-				return `{"result": "Tool executed successfully but returned no content"}`, nil
-			}
-			return `{"result": "Function not executed"}`, nil
+		// The write tools display the diff of their changes instead of their arguments
+		preview, hasPreview, err := toolbox.preview(functionName, arguments)
+		switch {
+		case err != nil:
+			return "", err
+		case hasPreview:
+			fmt.Printf("🟢 %s:\n", functionName)
+			fmt.Print(preview)
+		default:
+			fmt.Printf("🟢 %s with arguments:\n", functionName)
+			ui.PrintJSON(arguments)
 		}
+		ui.GetLogger().Debug("tool call detected", "function", functionName, "arguments", arguments)
+
+		return execute(functionName, arguments)
 	}
 }
+
+// backend gathers what the headless modes (serve, run, worker) need to create and run agents
+type backend struct {
+	newAgent      func(model string) (mu.Agent, error)
+	toolbox       *toolbox
+	delegation    *delegator
+	config        *Config
+	docs          *docsIndex
+	embedder      mu.Agent
+	model         string
+	systemMessage string
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// MCPServerConfig is an MCP server added to the one of MCP_HOST_URL, over HTTP (URL), SSE or stdio (Command)
+type MCPServerConfig struct {
+	// Name identifies the server in the logs
+	Name string `yaml:"name"`
+	// URL is the streamable HTTP endpoint of the server (its SSE endpoint with SSE)
+	URL string `yaml:"url,omitempty"`
+	// SSE connects with the legacy HTTP+SSE transport instead of streamable HTTP
+	SSE bool `yaml:"sse,omitempty"`
+	// Command starts a server speaking MCP over its standard input and output, instead of URL
+	Command string `yaml:"command,omitempty"`
+	// Args are the arguments of the command
+	Args []string `yaml:"args,omitempty"`
+	// Env are the environment variables added for the command
+	Env map[string]string `yaml:"env,omitempty"`
+	// Prefix (optional) is added to the names of the tools of the server, e.g. "github_"
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
+// connectMCPServers connects to the MCP server of MCP_HOST_URL and to the servers of the configuration;
+// the servers which can't be reached are skipped, an error is returned if none of them can be
+func connectMCPServers(ctx context.Context, mcpHostURL string, servers []MCPServerConfig) (*tools.MCPRouter, error) {
+	router := tools.NewMCPRouter(tools.WithRouterLogger(ui.GetLogger()))
+	servers = append([]MCPServerConfig{{Name: "default", URL: mcpHostURL}}, servers...)
+	errs := []error{}
+	for _, server := range servers {
+		if err := connectMCPServer(ctx, router, server); err != nil {
+			ui.GetLogger().Warn("MCP server unavailable, its tools are disabled", "server", server.Name, "url", server.URL, "command", server.Command, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		ui.GetLogger().Info("MCP Client initialized successfully", "server", server.Name, "url", server.URL, "command", server.Command)
+	}
+	if len(router.Servers()) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return router, nil
+}
+
+// connectMCPServer adds a server to the router, over stdio if it has a command, or SSE
+func connectMCPServer(ctx context.Context, router *tools.MCPRouter, server MCPServerConfig) error {
+	var client *tools.MCPClient
+	var err error
+	switch {
+	case server.Command != "":
+		env := []string{}
+		for name, value := range server.Env {
+			env = append(env, name+"="+value)
+		}
+		sort.Strings(env)
+		client, err = tools.NewStdioMCPClient(ctx, server.Command, server.Args, env, tools.WithLogger(ui.GetLogger()))
+	case server.SSE:
+		client, err = tools.NewSSEMCPClient(ctx, server.URL, tools.WithLogger(ui.GetLogger()))
+	default:
+		return router.Connect(ctx, server.Name, server.URL, server.Prefix)
+	}
+	if err != nil {
+		return err
+	}
+	if err := router.Add(server.Name, client, server.Prefix); err != nil {
+		client.Close()
+		return err
+	}
+	return nil
+}
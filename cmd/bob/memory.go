@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/micro-agent/micro-agent-go/agent/memory"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// MemoryConfig configures the long-term memory of Bob
+type MemoryConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// File stores the memories (~/.bob/memory.json if empty)
+	File string `yaml:"file,omitempty"`
+}
+
+// memoryPath returns the file of the memories
+func (c MemoryConfig) memoryPath() string {
+	if c.File != "" {
+		return c.File
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".bob", "memory.json")
+	}
+	return filepath.Join(".bob", "memory.json")
+}
+
+// newLongTermMemory creates the long-term memory: the chat model extracts the facts, the embedding model embeds them
+func newLongTermMemory(ctx context.Context, client openai.Client, model string, embeddingAgent mu.Agent, config MemoryConfig) (*memory.LongTermMemory, error) {
+	extractor, err := mu.NewAgent(ctx, "Bob memory",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       model,
+			Temperature: openai.Opt(0.0),
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return memory.NewLongTermMemory(extractor, embeddingAgent, memory.WithMemoryFile(config.memoryPath()))
+}
+
+// recallMemories returns the messages of a request with the facts relevant to the prompt added to the system message
+func recallMemories(longTerm *memory.LongTermMemory, messages []openai.ChatCompletionMessageParamUnion, prompt string) []openai.ChatCompletionMessageParamUnion {
+	if longTerm == nil || len(messages) == 0 || messages[0].OfSystem == nil {
+		return messages
+	}
+	system, err := longTerm.Augment(messages[0].OfSystem.Content.OfString.Value, prompt)
+	if err != nil {
+		ui.GetLogger().Warn("failed to recall the memories", "error", err)
+		return messages
+	}
+	request := append([]openai.ChatCompletionMessageParamUnion{openai.SystemMessage(system)}, messages[1:]...)
+	return request
+}
+
+// restoreSystemMessage replaces the system message augmented with the memories by the original one
+func restoreSystemMessage(conversation, original []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	if len(conversation) > 0 && len(original) > 0 && conversation[0].OfSystem != nil && original[0].OfSystem != nil {
+		conversation[0] = original[0]
+	}
+	return conversation
+}
+
+// memorize extracts and stores the facts of an exchange
+func memorize(longTerm *memory.LongTermMemory, prompt, answer string) {
+	if longTerm == nil || answer == "" {
+		return
+	}
+	thinkingCtrl := ui.NewThinkingController()
+	thinkingCtrl.Start(ui.GetTheme().Info, "🧠 Memorizing...")
+	facts, err := longTerm.Remember(prompt, answer)
+	thinkingCtrl.Stop()
+	if err != nil {
+		ui.GetLogger().Warn("failed to memorize the exchange", "error", err)
+		return
+	}
+	for _, fact := range facts {
+		ui.Println(ui.GetTheme().Info, "🧠 Remembered:", fact)
+	}
+}
+
+// registerMemoryCommands adds the /memory and /forget commands
+func registerMemoryCommands(registry *ui.CommandRegistry, longTerm *memory.LongTermMemory) {
+	theme := ui.GetTheme()
+	registry.Register(ui.SlashCommand{
+		Name:        "/memory",
+		Description: "List the facts remembered across the sessions",
+		Handler: func(string) error {
+			facts := longTerm.Facts()
+			if len(facts) == 0 {
+				ui.Println(theme.Info, "Nothing remembered yet")
+			}
+			for _, fact := range facts {
+				ui.Println(theme.Info, "- "+fact)
+			}
+			return nil
+		},
+	})
+	registry.Register(ui.SlashCommand{
+		Name:        "/forget",
+		Description: "Forget all the facts remembered across the sessions",
+		Handler: func(string) error {
+			if err := longTerm.Forget(); err != nil {
+				return err
+			}
+			ui.Println(theme.Info, "All the memories have been forgotten")
+			return nil
+		},
+	})
+}
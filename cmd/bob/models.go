@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/msg"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// knownContextSizes gives the context size (in tokens) of well-known model families, matched by substring
+// (the longest match wins, e.g. "gpt-4o" before "gpt-4")
+var knownContextSizes = map[string]int{
+	"gpt-4.1":     1047576,
+	"gpt-4o":      128000,
+	"gpt-4-turbo": 128000,
+	"gpt-4":       8192,
+	"gpt-3.5":     16385,
+	"gpt-5":       400000,
+	"o1":          200000,
+	"o3":          200000,
+	"o4-mini":     200000,
+	"claude":      200000,
+	"gemini":      1048576,
+	"llama3.1":    131072,
+	"llama3.2":    131072,
+	"llama3.3":    131072,
+	"qwen2.5":     32768,
+	"qwen3":       40960,
+	"mistral":     32768,
+	"gemma3":      131072,
+	"phi4":        16384,
+	"deepseek":    65536,
+}
+
+// ModelPricing is the price of a model in dollars per million tokens
+type ModelPricing struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// knownPricing gives the price of well-known hosted models, matched by substring like knownContextSizes
+// (the local models are free, the pricing section of the configuration adds or overrides prices)
+var knownPricing = map[string]ModelPricing{
+	"gpt-4.1-nano": {Input: 0.10, Output: 0.40},
+	"gpt-4.1-mini": {Input: 0.40, Output: 1.60},
+	"gpt-4.1":      {Input: 2.00, Output: 8.00},
+	"gpt-4o-mini":  {Input: 0.15, Output: 0.60},
+	"gpt-4o":       {Input: 2.50, Output: 10.00},
+	"gpt-4-turbo":  {Input: 10.00, Output: 30.00},
+	"gpt-3.5":      {Input: 0.50, Output: 1.50},
+	"gpt-5-nano":   {Input: 0.05, Output: 0.40},
+	"gpt-5-mini":   {Input: 0.25, Output: 2.00},
+	"gpt-5":        {Input: 1.25, Output: 10.00},
+	"o3-mini":      {Input: 1.10, Output: 4.40},
+	"o4-mini":      {Input: 1.10, Output: 4.40},
+	"o3":           {Input: 2.00, Output: 8.00},
+	"o1":           {Input: 15.00, Output: 60.00},
+}
+
+// modelPrice returns the price of a model from the overrides then the known prices, ok is false if it is unknown
+func modelPrice(model string, overrides map[string]ModelPricing) (ModelPricing, bool) {
+	model = strings.ToLower(model)
+	for _, prices := range []map[string]ModelPricing{overrides, knownPricing} {
+		bestMatch, found := "", false
+		var pricing ModelPricing
+		for family, familyPricing := range prices {
+			if strings.Contains(model, strings.ToLower(family)) && len(family) > len(bestMatch) {
+				bestMatch, pricing, found = family, familyPricing, true
+			}
+		}
+		if found {
+			return pricing, true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// modelContextSize returns the context size of a model, or 0 if it is unknown
+func modelContextSize(model string) int {
+	model = strings.ToLower(model)
+	bestMatch, size := "", 0
+	for family, familySize := range knownContextSizes {
+		if strings.Contains(model, family) && len(family) > len(bestMatch) {
+			bestMatch, size = family, familySize
+		}
+	}
+	return size
+}
+
+// estimateTokens roughly estimates the number of tokens of the messages for a model
+func estimateTokens(messages []openai.ChatCompletionMessageParamUnion, model string) int {
+	return msg.EstimateTokens(messages, model)
+}
+
+// summarizeConversation asks the model for a summary of messages, to replace them (see /compact)
+func summarizeConversation(ctx context.Context, client openai.Client, model string, messages []openai.ChatCompletionMessageParamUnion) (string, error) {
+	completion, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+		Model: model,
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage("Summarize the following conversation in a few paragraphs for the assistant to carry on: " +
+				"keep the facts, the decisions, the names, the file paths and the results of the tools, drop the small talk."),
+			openai.UserMessage(msg.ToMarkdownTranscript(messages)),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(completion.Choices) == 0 {
+		return "", fmt.Errorf("no summary generated")
+	}
+	return completion.Choices[0].Message.Content, nil
+}
+
+// listModels returns the sorted identifiers of the models available from the provider (models endpoint)
+func listModels(ctx context.Context, client openai.Client) ([]string, error) {
+	page, err := client.Models.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	models := []string{}
+	for _, model := range page.Data {
+		models = append(models, model.ID)
+	}
+	sort.Strings(models)
+	return models, nil
+}
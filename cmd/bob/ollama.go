@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/providers/ollama"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// ollamaClient returns the client of the native Ollama API of the profile, nil for the other providers
+func (p Profile) ollamaClient(headless bool) (*ollama.Client, error) {
+	if p.Provider != providerOllama {
+		return nil, nil
+	}
+	options := []ollama.ClientOption{}
+	if p.KeepAlive != "" {
+		keepAlive, err := time.ParseDuration(p.KeepAlive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid keep_alive %q: %w", p.KeepAlive, err)
+		}
+		options = append(options, ollama.WithKeepAlive(keepAlive))
+	}
+	if len(p.ModelOptions) > 0 {
+		options = append(options, ollama.WithModelOptions(p.ModelOptions))
+	}
+	if p.AutoPull {
+		display := &pullDisplay{headless: headless}
+		options = append(options, ollama.WithAutoPull(display.update))
+	}
+	return ollama.NewClient(p.BaseURL, options...), nil
+}
+
+// pullDisplay displays the progress of the pulls: a progress bar per layer, or log lines without terminal
+type pullDisplay struct {
+	headless bool
+	bar      *ui.ProgressBar
+	digest   string
+	status   string
+}
+
+// update displays a progress update of the pull of a model
+func (d *pullDisplay) update(model string, progress ollama.PullProgress) {
+	if d.headless {
+		if progress.Status != d.status {
+			ui.GetLogger().Info("pulling the model", "model", model, "status", progress.Status)
+		}
+		d.status = progress.Status
+		return
+	}
+	switch {
+	case progress.Status == "success":
+		d.finish()
+		ui.Println(ui.GetTheme().Info, "✅ Model", model, "pulled")
+	case progress.Total > 0:
+		// A progress bar per downloaded layer
+		if d.bar == nil || progress.Digest != d.digest {
+			d.finish()
+			d.digest = progress.Digest
+			d.bar = ui.NewProgressBar(ui.GetTheme().Info, "⬇️  Pulling "+model, int(progress.Total))
+			d.bar.Start()
+		}
+		d.bar.Update(int(progress.Completed), int(progress.Total))
+	case progress.Status != d.status && !strings.HasPrefix(progress.Status, "pulling "):
+		d.finish()
+		ui.Println(ui.GetTheme().Info, "⬇️ ", progress.Status)
+	}
+	d.status = progress.Status
+}
+
+// finish stops the current progress bar
+func (d *pullDisplay) finish() {
+	if d.bar != nil {
+		d.bar.Done()
+		d.bar = nil
+	}
+}
+
+// pullModel pulls a model of the Ollama server and displays its progress
+func pullModel(ctx context.Context, client *ollama.Client, model string, headless bool) error {
+	display := &pullDisplay{headless: headless}
+	defer display.finish()
+	return client.Pull(ctx, model, func(progress ollama.PullProgress) {
+		display.update(model, progress)
+	})
+}
+
+// registerOllamaCommands registers the model management commands of the Ollama server
+func registerOllamaCommands(ctx context.Context, registry *ui.CommandRegistry, client *ollama.Client) {
+	theme := ui.GetTheme()
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/pull",
+		Usage:       "/pull <model>",
+		Description: "Pull a model of the Ollama server",
+		Handler: func(args string) error {
+			if args == "" {
+				return fmt.Errorf("usage: /pull <model>")
+			}
+			return pullModel(ctx, client, args, false)
+		},
+	})
+
+	registry.Register(ui.SlashCommand{
+		Name:        "/ps",
+		Description: "List the models loaded by the Ollama server",
+		Handler: func(string) error {
+			models, err := client.Running(ctx)
+			if err != nil {
+				return fmt.Errorf("unable to list the loaded models: %w", err)
+			}
+			if len(models) == 0 {
+				ui.Println(theme.Info, "No model loaded")
+			}
+			for _, model := range models {
+				ui.Printf(theme.Info, "%s (%.1f GB, %.0f%% GPU, unloaded in %s)\n",
+					model.Name, float64(model.Size)/1e9, 100*float64(model.SizeVRAM)/float64(max(model.Size, 1)),
+					time.Until(model.ExpiresAt).Round(time.Second))
+			}
+			return nil
+		},
+	})
+}
+
+// runPull is the pull subcommand: bob pull model... (pull is nil when the provider does not pull models)
+func runPull(ctx context.Context, pull func(ctx context.Context, model string) error, models []string) int {
+	if pull == nil {
+		fmt.Fprintln(os.Stderr, "bob pull requires a profile with the ollama or docker-model-runner provider")
+		return exitUsageError
+	}
+	if len(models) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: bob pull model...")
+		return exitUsageError
+	}
+	for _, model := range models {
+		if err := pull(ctx, model); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+	}
+	return exitSuccess
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// Exit codes of the non-interactive mode
+const (
+	exitSuccess    = 0
+	exitFailure    = 1 // the completion or a tool call failed
+	exitUsageError = 2 // invalid arguments or empty prompt
+)
+
+// readNonInteractivePrompt returns the prompt of the non-interactive mode: the -p flag value
+// followed by the piped standard input. nonInteractive is false when Bob must start the interactive loop.
+func readNonInteractivePrompt(promptFlag string) (prompt string, nonInteractive bool, err error) {
+	prompt = strings.TrimSpace(promptFlag)
+	nonInteractive = prompt != ""
+
+	stat, err := os.Stdin.Stat()
+	if err != nil {
+		return "", false, err
+	}
+	if stat.Mode()&os.ModeCharDevice == 0 {
+		// The standard input is piped (or redirected from a file)
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", true, err
+		}
+		if input := strings.TrimSpace(string(data)); input != "" {
+			if prompt != "" {
+				prompt += "\n\n"
+			}
+			prompt += input
+		}
+		nonInteractive = true
+	}
+	return prompt, nonInteractive, nil
+}
+
+// toolCallRecord is a tool call of the non-interactive mode, reported in the JSON output
+type toolCallRecord struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Result    string `json:"result,omitempty"`
+	Executed  bool   `json:"executed"`
+	Error     string `json:"error,omitempty"`
+}
+
+// nonInteractiveOutput is the JSON output of the non-interactive mode
+type nonInteractiveOutput struct {
+	Answer       string           `json:"answer"`
+	FinishReason string           `json:"finish_reason"`
+	ToolCalls    []toolCallRecord `json:"tool_calls"`
+	Error        string           `json:"error,omitempty"`
+}
+
+// runNonInteractive runs a single completion, prints the answer as plain text or JSON and returns the exit code.
+// The tool calls are executed according to their policy: "allow" always, "deny" never,
+// "ask" (there is nobody to ask) only if approveTools is true.
+func runNonInteractive(toolAgent mu.Agent, toolbox *toolbox, config *Config, systemMessage, prompt string, approveTools bool, outputFormat string) int {
+	if outputFormat != "text" && outputFormat != "json" {
+		fmt.Fprintln(os.Stderr, "invalid output format:", outputFormat)
+		return exitUsageError
+	}
+
+	output := nonInteractiveOutput{ToolCalls: []toolCallRecord{}}
+	toolFailed := false
+
+	executeFn := func(functionName string, arguments string) (string, error) {
+		record := toolCallRecord{Name: functionName, Arguments: arguments}
+		defer func() {
+			output.ToolCalls = append(output.ToolCalls, record)
+		}()
+		if !approvedUnattended(config, functionName, approveTools) {
+			record.Result = `{"result": "Function not executed"}`
+			return record.Result, nil
+		}
+		result, err := toolbox.call(functionName, arguments)
+		record.Executed = true
+		record.Result = result
+		if err != nil {
+			record.Error = err.Error()
+			toolFailed = true
+		}
+		return result, err
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(systemMessage),
+		openai.UserMessage(prompt),
+	}
+	finishReason, _, answer, err := toolAgent.DetectToolCalls(messages, executeFn)
+	output.Answer = answer
+	output.FinishReason = finishReason
+	if err != nil {
+		output.Error = err.Error()
+	}
+
+	if outputFormat == "json" {
+		data, _ := json.MarshalIndent(output, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		if answer != "" {
+			fmt.Println(answer)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+	}
+
+	if err != nil || toolFailed {
+		return exitFailure
+	}
+	return exitSuccess
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"bob/plugins"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// Build-time plugins: add here a blank import of the packages registering their tools with plugins.Register
+//
+//	import _ "example.com/team/bobtools"
+
+// PluginConfig is a runtime plugin: an executable speaking the JSON protocol of Bob.
+//
+// For each request, Bob runs the command, writes a JSON request on its standard input
+// and reads a JSON response on its standard output:
+//
+//	{"method": "describe"}
+//	→ {"tools": [{"name": "...", "description": "...", "parameters": {JSON schema}}]}
+//	{"method": "call", "tool": "...", "arguments": {...}}
+//	→ {"result": ...} or {"error": "..."}
+type PluginConfig struct {
+	// Command is the executable of the plugin
+	Command string `yaml:"command"`
+	// Args are the arguments of the command
+	Args []string `yaml:"args,omitempty"`
+	// Timeout of a request (30s if zero)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+}
+
+// pluginRequest is a request of the plugin protocol
+type pluginRequest struct {
+	Method    string          `json:"method"`
+	Tool      string          `json:"tool,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// pluginResponse is a response of the plugin protocol
+type pluginResponse struct {
+	Tools  []pluginToolDescription `json:"tools,omitempty"`
+	Result json.RawMessage         `json:"result,omitempty"`
+	Error  string                  `json:"error,omitempty"`
+}
+
+// pluginToolDescription describes a tool of a runtime plugin
+type pluginToolDescription struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+// pluginDefinition creates the OpenAI definition of a plugin tool
+func pluginDefinition(name, description string, parameters map[string]any) openai.ChatCompletionToolUnionParam {
+	if parameters == nil {
+		parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	return openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+		Name:        name,
+		Description: openai.String(description),
+		Parameters:  shared.FunctionParameters(parameters),
+	})
+}
+
+// newRegisteredTools returns the tools registered at build time in the plugins package
+func newRegisteredTools() []builtinTool {
+	registered := plugins.Tools()
+	builtins := make([]builtinTool, 0, len(registered))
+	for _, tool := range registered {
+		run := tool.Run
+		builtins = append(builtins, builtinTool{
+			definition: pluginDefinition(tool.Name, tool.Description, tool.Parameters),
+			run: func(arguments string) (any, error) {
+				args := map[string]any{}
+				if strings.TrimSpace(arguments) != "" {
+					if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+						return nil, fmt.Errorf("invalid arguments: %w", err)
+					}
+				}
+				return run(args)
+			},
+		})
+	}
+	return builtins
+}
+
+// newPluginTools describes a runtime plugin and returns its tools
+func newPluginTools(config PluginConfig) ([]builtinTool, error) {
+	if config.Command == "" {
+		return nil, errors.New("the command of the plugin is empty")
+	}
+	response, err := config.request(pluginRequest{Method: "describe"})
+	if err != nil {
+		return nil, err
+	}
+	builtins := make([]builtinTool, 0, len(response.Tools))
+	for _, description := range response.Tools {
+		if description.Name == "" {
+			return nil, fmt.Errorf("%s describes a tool without name", config.Command)
+		}
+		name := description.Name
+		builtins = append(builtins, builtinTool{
+			definition: pluginDefinition(name, description.Description, description.Parameters),
+			run: func(arguments string) (any, error) {
+				if strings.TrimSpace(arguments) == "" {
+					arguments = "{}"
+				}
+				response, err := config.request(pluginRequest{Method: "call", Tool: name, Arguments: json.RawMessage(arguments)})
+				if err != nil {
+					return nil, err
+				}
+				return response.Result, nil
+			},
+		})
+	}
+	return builtins, nil
+}
+
+// request runs the plugin command with a request and returns its response
+func (c PluginConfig) request(request pluginRequest) (*pluginResponse, error) {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	input, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c.Command, c.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %s timed out after %s", c.Command, timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("plugin %s failed: %w: %s", c.Command, err, message)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w", c.Command, err)
+	}
+
+	response := &pluginResponse{}
+	if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+		return nil, fmt.Errorf("invalid response of the plugin %s: %w", c.Command, err)
+	}
+	if response.Error != "" {
+		return nil, errors.New(response.Error)
+	}
+	return response, nil
+}
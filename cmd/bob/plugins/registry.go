@@ -0,0 +1,58 @@
+// Package plugins is the build-time registry of the local tools of Bob.
+//
+// A package registers its tools in an init function and is imported (blank import) in
+// cmd/bob/plugins.go, so the tools are available without changing the main program:
+//
+//	func init() {
+//		plugins.Register(plugins.Tool{
+//			Name:        "current_time",
+//			Description: "Return the current time",
+//			Run: func(arguments map[string]any) (any, error) {
+//				return time.Now().Format(time.RFC3339), nil
+//			},
+//		})
+//	}
+package plugins
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tool is a local tool executed by Bob
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters is the JSON schema of the arguments object (an object without properties if nil)
+	Parameters map[string]any
+	// Run executes the tool, the result is marshalled to JSON
+	Run func(arguments map[string]any) (any, error)
+}
+
+var (
+	mu    sync.Mutex
+	tools []Tool
+	names = map[string]bool{}
+)
+
+// Register adds a tool to the registry. It panics if the tool has no name or no Run function,
+// or if a tool with the same name is already registered.
+func Register(tool Tool) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tool.Name == "" || tool.Run == nil {
+		panic("plugins: Register of a tool without name or Run function")
+	}
+	if names[tool.Name] {
+		panic(fmt.Sprintf("plugins: Register called twice for the tool %s", tool.Name))
+	}
+	names[tool.Name] = true
+	tools = append(tools, tool)
+}
+
+// Tools returns the registered tools in their registration order
+func Tools() []Tool {
+	mu.Lock()
+	defer mu.Unlock()
+	return append([]Tool(nil), tools...)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/providers/azure"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Providers of the profiles
+const (
+	providerDockerModelRunner = "docker-model-runner"
+	providerOpenAI            = "openai"
+	providerOllama            = "ollama"
+	providerAzure             = "azure"
+)
+
+// Default settings of Bob (without profile and environment variables)
+const (
+	defaultModel          = "hf.co/menlo/jan-nano-gguf:q4_k_m"
+	defaultEmbeddingModel = "ai/mxbai-embed-large"
+)
+
+// providerBaseURLs are the default base URLs of the providers (azure requires base_url)
+var providerBaseURLs = map[string]string{
+	providerDockerModelRunner: "http://localhost:12434/engines/llama.cpp/v1",
+	providerOpenAI:            "https://api.openai.com/v1",
+	providerOllama:            "http://localhost:11434",
+}
+
+// Profile bundles the connection to a provider, its models and the default completion parameters
+type Profile struct {
+	// Provider is docker-model-runner (default), openai, ollama or azure
+	Provider string `yaml:"provider,omitempty"`
+	// BaseURL of the OpenAI-compatible API, the Azure OpenAI resource endpoint for azure
+	BaseURL string `yaml:"base_url,omitempty"`
+	APIKey  string `yaml:"api_key,omitempty"`
+	// APIKeyEnv is the environment variable holding the API key (to keep the key out of the configuration)
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+	// APIVersion is the api-version query parameter of azure (2024-10-21 by default)
+	APIVersion     string   `yaml:"api_version,omitempty"`
+	Model          string   `yaml:"model,omitempty"` // the deployment name for azure
+	EmbeddingModel string   `yaml:"embedding_model,omitempty"`
+	Temperature    *float64 `yaml:"temperature,omitempty"`
+	TopP           *float64 `yaml:"top_p,omitempty"`
+	MaxTokens      int64    `yaml:"max_tokens,omitempty"`
+
+	// KeepAlive is how long ollama keeps the model loaded after a request (e.g. 30m, -1s to keep it loaded)
+	KeepAlive string `yaml:"keep_alive,omitempty"`
+	// ModelOptions are the native model options of ollama (e.g. num_ctx)
+	ModelOptions map[string]any `yaml:"model_options,omitempty"`
+	// AutoPull pulls the missing models of ollama on the first request
+	AutoPull bool `yaml:"auto_pull,omitempty"`
+}
+
+// profile returns the profile with the given name, or the default profile if name is empty.
+// The profile is empty (environment variables and defaults) if there is no default profile.
+func (c *Config) profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.DefaultProfile
+	}
+	if name == "" {
+		return Profile{}, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.profileNames(), ", "))
+	}
+	if profile.Provider == "" {
+		profile.Provider = providerDockerModelRunner
+	}
+	if _, known := providerBaseURLs[profile.Provider]; !known && profile.Provider != providerAzure {
+		return Profile{}, fmt.Errorf("profile %q: unknown provider %q", name, profile.Provider)
+	}
+	if profile.Provider == providerAzure && profile.BaseURL == "" {
+		return Profile{}, fmt.Errorf("profile %q: base_url (the Azure OpenAI endpoint) is required", name)
+	}
+	return profile, nil
+}
+
+// profileNames returns the sorted names of the profiles
+func (c *Config) profileNames() []string {
+	names := []string{}
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolve completes the settings missing from the profile with the environment variables
+// (PROVIDER_BASE_URL, PROVIDER_API_KEY, MODEL_ID, EMBEDDING_MODEL_ID) and the defaults
+func (p Profile) resolve() Profile {
+	if p.Provider == "" {
+		p.Provider = providerDockerModelRunner
+	}
+	if p.BaseURL == "" {
+		p.BaseURL = os.Getenv("PROVIDER_BASE_URL")
+	}
+	if p.BaseURL == "" {
+		p.BaseURL = providerBaseURLs[p.Provider]
+	}
+	if p.APIKey == "" && p.APIKeyEnv != "" {
+		p.APIKey = os.Getenv(p.APIKeyEnv)
+	}
+	if p.APIKey == "" {
+		p.APIKey = os.Getenv("PROVIDER_API_KEY")
+	}
+	if p.Model == "" {
+		p.Model = os.Getenv("MODEL_ID")
+	}
+	if p.Model == "" {
+		p.Model = defaultModel
+	}
+	if p.EmbeddingModel == "" {
+		p.EmbeddingModel = os.Getenv("EMBEDDING_MODEL_ID")
+	}
+	if p.EmbeddingModel == "" {
+		p.EmbeddingModel = defaultEmbeddingModel
+	}
+	if p.Provider == providerAzure && p.APIVersion == "" {
+		p.APIVersion = azure.DefaultAPIVersion
+	}
+	return p
+}
+
+// clientOptions returns the options of the OpenAI client of the profile
+// (ollama uses the options of its native adapter, which must be the last options of the client)
+func (p Profile) clientOptions() ([]option.RequestOption, error) {
+	if p.Provider == providerOllama {
+		return nil, nil
+	}
+	if p.Provider == providerAzure {
+		// The model of the request is the deployment name, without API key the service principal
+		// of the AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET variables is used
+		options := []azure.Option{azure.WithAPIVersion(p.APIVersion)}
+		if p.APIKey != "" {
+			options = append(options, azure.WithAPIKey(p.APIKey))
+		} else if tokenProvider, ok := azure.NewEnvironmentCredential(); ok {
+			options = append(options, azure.WithTokenProvider(tokenProvider))
+		}
+		return azure.RequestOptions(p.BaseURL, options...)
+	}
+	return []option.RequestOption{
+		option.WithBaseURL(p.BaseURL),
+		option.WithAPIKey(p.APIKey),
+	}, nil
+}
+
+// applyParams sets the default completion parameters of the profile
+func (p Profile) applyParams(params *openai.ChatCompletionNewParams) {
+	if p.Temperature != nil {
+		params.Temperature = openai.Opt(*p.Temperature)
+	}
+	if p.TopP != nil {
+		params.TopP = openai.Opt(*p.TopP)
+	}
+	if p.MaxTokens > 0 {
+		params.MaxTokens = openai.Opt(p.MaxTokens)
+	}
+}
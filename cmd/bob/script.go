@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a sequence of prompts run by `bob run`, defined in a YAML file or in a markdown file
+// (YAML front matter for the settings, then the prompts separated by "---" lines)
+type Script struct {
+	Model  string `yaml:"model,omitempty"`
+	System string `yaml:"system,omitempty"`
+	// DefaultToolPolicy and ToolPolicies override the policies of the configuration during the script
+	DefaultToolPolicy string            `yaml:"default_tool_policy,omitempty"`
+	ToolPolicies      map[string]string `yaml:"tool_policies,omitempty"`
+	// Output is the destination of the answers of the steps without output, the standard output if empty
+	Output string       `yaml:"output,omitempty"`
+	Steps  []ScriptStep `yaml:"steps"`
+}
+
+// ScriptStep is a prompt of a script, the steps share the conversation unless reset is set
+type ScriptStep struct {
+	Name       string `yaml:"name,omitempty"`
+	Prompt     string `yaml:"prompt,omitempty"`
+	PromptFile string `yaml:"prompt_file,omitempty"` // relative to the script directory
+	Output     string `yaml:"output,omitempty"`      // file path, "-" for the standard output
+	Append     bool   `yaml:"append,omitempty"`      // append to the output file instead of replacing it
+	Reset      bool   `yaml:"reset,omitempty"`       // start a new conversation
+}
+
+// loadScript reads a YAML (.yaml, .yml) or markdown (.md) script
+func loadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	script := &Script{}
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		script, err = parseMarkdownScript(string(data))
+	} else {
+		err = yaml.Unmarshal(data, script)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid script %s: %w", path, err)
+	}
+
+	// The prompt files are relative to the script
+	for idx := range script.Steps {
+		step := &script.Steps[idx]
+		if step.PromptFile != "" {
+			promptFile := step.PromptFile
+			if !filepath.IsAbs(promptFile) {
+				promptFile = filepath.Join(filepath.Dir(path), promptFile)
+			}
+			content, err := os.ReadFile(promptFile)
+			if err != nil {
+				return nil, fmt.Errorf("step %d: %w", idx+1, err)
+			}
+			step.Prompt = strings.TrimSpace(step.Prompt + "\n\n" + string(content))
+		}
+		if strings.TrimSpace(step.Prompt) == "" {
+			return nil, fmt.Errorf("invalid script %s: step %d has no prompt", path, idx+1)
+		}
+	}
+	if len(script.Steps) == 0 {
+		return nil, fmt.Errorf("invalid script %s: no step", path)
+	}
+	return script, nil
+}
+
+// parseMarkdownScript reads the settings of the YAML front matter, then a step per section separated by "---"
+func parseMarkdownScript(content string) (*Script, error) {
+	script := &Script{}
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if rest, found := strings.CutPrefix(content, "---\n"); found {
+		frontMatter, body, found := strings.Cut(rest, "\n---\n")
+		if !found {
+			return nil, fmt.Errorf("unterminated front matter")
+		}
+		if err := yaml.Unmarshal([]byte(frontMatter), script); err != nil {
+			return nil, err
+		}
+		content = body
+	}
+	for _, section := range strings.Split(content, "\n---\n") {
+		if prompt := strings.TrimSpace(section); prompt != "" {
+			script.Steps = append(script.Steps, ScriptStep{Prompt: prompt})
+		}
+	}
+	return script, nil
+}
+
+// runScript parses the flags of `bob run` and runs the steps of the script, it returns the exit code
+func runScript(args []string, b *backend) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	approveTools := flags.Bool("approve-tools", false, "execute the tool calls with the ask policy (nobody can confirm them)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: bob run [-approve-tools] <script.yaml|script.md>")
+		return exitUsageError
+	}
+	script, err := loadScript(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsageError
+	}
+
+	model := script.Model
+	if model == "" {
+		model = b.model
+	}
+	agent, err := b.newAgent(model)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	systemMessage := script.System
+	if systemMessage == "" {
+		systemMessage = b.systemMessage
+	}
+
+	// The tool policies of the script take precedence over the configuration
+	policies := &Config{
+		DefaultToolPolicy: b.config.DefaultToolPolicy,
+		ToolPolicies:      map[string]string{},
+	}
+	for name, policy := range b.config.ToolPolicies {
+		policies.ToolPolicies[name] = policy
+	}
+	if script.DefaultToolPolicy != "" {
+		policies.DefaultToolPolicy = script.DefaultToolPolicy
+	}
+	for name, policy := range script.ToolPolicies {
+		policies.ToolPolicies[name] = policy
+	}
+
+	toolFailed := false
+	executeFn := func(functionName string, arguments string) (string, error) {
+		if !approvedUnattended(policies, functionName, *approveTools) {
+			ui.GetLogger().Info("tool call refused", "function", functionName)
+			return `{"result": "Function not executed"}`, nil
+		}
+		ui.GetLogger().Info("tool call", "function", functionName, "arguments", arguments)
+		result, err := b.toolbox.call(functionName, arguments)
+		if err != nil {
+			toolFailed = true
+		}
+		return result, err
+	}
+
+	// The sub-agents follow the tool policies of the script
+	b.delegation.bind(b.delegation.ctx, model, executeFn)
+
+	conversation := []openai.ChatCompletionMessageParamUnion{openai.SystemMessage(systemMessage)}
+	// An output file is replaced by its first write (unless append is set), the next writes are appended
+	written := map[string]bool{}
+	for idx, step := range script.Steps {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", idx+1)
+		}
+		fmt.Fprintf(os.Stderr, "▶ [%d/%d] %s\n", idx+1, len(script.Steps), name)
+
+		if step.Reset {
+			conversation = conversation[:1]
+		}
+		messages := append(conversation, openai.UserMessage(b.docs.augment(step.Prompt)))
+		_, _, answer, err := agent.DetectToolCalls(messages, executeFn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed: %v\n", name, err)
+			return exitFailure
+		}
+		conversation = append(agent.GetMessages(), openai.AssistantMessage(answer))
+
+		output := step.Output
+		if output == "" {
+			output = script.Output
+		}
+		if err := writeScriptOutput(output, answer, step.Append || written[output]); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: unable to write the output: %v\n", name, err)
+			return exitFailure
+		}
+		written[output] = true
+	}
+
+	if toolFailed {
+		return exitFailure
+	}
+	return exitSuccess
+}
+
+// writeScriptOutput writes the answer of a step to the standard output ("" or "-") or to a file
+func writeScriptOutput(output, answer string, appendToFile bool) error {
+	if output == "" || output == "-" {
+		fmt.Println(answer)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if appendToFile {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(output, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = fmt.Fprintln(file, answer)
+	return err
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// cacheHeader tells the clients if the answer comes from the semantic cache (hit) or not (miss)
+const cacheHeader = "X-Cache"
+
+// cacheLookup is the lookup of a request in the semantic cache
+type cacheLookup struct {
+	// prompt is the question of a cacheable request (empty otherwise)
+	prompt string
+	hit    bool
+	answer string
+}
+
+// lookupCache searches the semantic cache for the answer of a single question without session, by model:
+// the conversations are not cached, their answers depend on the previous messages
+func (s *server) lookupCache(w http.ResponseWriter, session *sessions.Session, model string, messages []openai.ChatCompletionMessageParamUnion) *cacheLookup {
+	lookup := &cacheLookup{}
+	if s.cache == nil || session != nil {
+		return lookup
+	}
+	conversation := withoutSystemMessage(messages)
+	if len(conversation) != 1 || conversation[0].OfUser == nil || !conversation[0].OfUser.Content.OfString.Valid() {
+		return lookup
+	}
+	prompt := conversation[0].OfUser.Content.OfString.Value
+	hit, ok, err := s.cache.Get(model, prompt)
+	if err != nil {
+		ui.GetLogger().Warn("semantic cache unavailable", "error", err)
+		return lookup
+	}
+	lookup.prompt = prompt
+	if ok {
+		ui.GetLogger().Info("semantic cache hit", "similarity", hit.Similarity, "cached", hit.Prompt)
+		lookup.hit, lookup.answer = true, hit.Answer
+		w.Header().Set(cacheHeader, "hit")
+	} else {
+		w.Header().Set(cacheHeader, "miss")
+	}
+	return lookup
+}
+
+// storeCache caches the answer of a cacheable question
+func (s *server) storeCache(model string, lookup *cacheLookup, answer string) {
+	if lookup.prompt == "" || answer == "" {
+		return
+	}
+	if err := s.cache.Put(model, lookup.prompt, answer); err != nil {
+		ui.GetLogger().Warn("failed to cache the answer", "error", err)
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/async"
+	"github.com/micro-agent/micro-agent-go/agent/memory"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/google/uuid"
+	"github.com/openai/openai-go/v2"
+)
+
+// sessionHeader is the header of the requests continuing a session of the server
+const sessionHeader = "X-Session-ID"
+
+// server exposes the agent of Bob (with its tools executed server-side) as an OpenAI-compatible API
+type server struct {
+	*backend
+	approveTools bool
+	apiKey       string
+	sessions     *sessions.Manager
+	sessionLocks sessionLocks
+	runs         *async.Runner
+	cache        *memory.SemanticCache
+	// loopback is true when the server only accepts local connections: the Host of the requests is checked
+	loopback bool
+}
+
+// sessionLocks serializes the requests of a session (the concurrent requests with the same X-Session-ID
+// would otherwise overwrite the history of each other)
+type sessionLocks struct {
+	mutex sync.Mutex
+	locks map[string]*sessionLock
+}
+
+type sessionLock struct {
+	sync.Mutex
+	waiters int
+}
+
+// lock waits for the other requests of the session and returns the function releasing the session
+func (l *sessionLocks) lock(id string) func() {
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sessionLock{}
+	}
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &sessionLock{}
+		l.locks[id] = lock
+	}
+	lock.waiters++
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		l.mutex.Lock()
+		defer l.mutex.Unlock()
+		if lock.waiters--; lock.waiters == 0 {
+			delete(l.locks, id)
+		}
+	}
+}
+
+// chatCompletionRequest is the subset of the OpenAI chat completion request used by Bob
+// (the tools of the request are ignored: the tools of Bob are used)
+type chatCompletionRequest struct {
+	Model    string                                   `json:"model"`
+	Messages []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	Stream   bool                                     `json:"stream"`
+}
+
+type chatCompletionMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+type chatCompletionChoice struct {
+	Index        int                    `json:"index"`
+	Message      *chatCompletionMessage `json:"message,omitempty"`
+	Delta        *chatCompletionMessage `json:"delta,omitempty"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+// chatCompletionResponse is a chat completion, or a chunk of a streamed chat completion
+type chatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []chatCompletionChoice `json:"choices"`
+}
+
+// runServer parses the flags of `bob serve` and serves the API until interrupted, it returns the exit code
+func runServer(args []string, s *server) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "listen address of the server (an API key is required beyond the loopback interface)")
+	flags.BoolVar(&s.approveTools, "approve-tools", false, "execute the tool calls with the ask policy (nobody can confirm them)")
+	flags.StringVar(&s.apiKey, "api-key", os.Getenv("BOB_SERVER_API_KEY"), "API key expected in the Authorization header (no authentication if empty, on the loopback interface only)")
+	webhookURL := flags.String("webhook-url", os.Getenv("BOB_WEBHOOK_URL"), "URL receiving the events of the runs of /v1/runs (polling only if empty)")
+	webhookSecret := flags.String("webhook-secret", os.Getenv("BOB_WEBHOOK_SECRET"), "secret signing the webhook deliveries (HMAC-SHA256)")
+	webhookProgress := flags.Duration("webhook-progress", 0, "interval of the progress events of the runs (no progress events if zero)")
+	cacheEnabled := flags.Bool("cache", false, "answer the questions similar to previous ones from the semantic cache")
+	cacheSimilarity := flags.Float64("cache-similarity", 0.95, "minimum cosine similarity of a cached question")
+	cacheTTL := flags.Duration("cache-ttl", 24*time.Hour, "lifetime of the cached answers (no expiration if zero)")
+	cacheFile := flags.String("cache-file", "", "JSON file persisting the semantic cache (in memory if empty)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+	// Without API key, anybody reaching the server could run the tools of Bob
+	s.loopback = isLoopbackAddr(*addr)
+	if s.apiKey == "" && !s.loopback {
+		fmt.Fprintf(os.Stderr, "an API key (-api-key or BOB_SERVER_API_KEY) is required to listen on %s\n", *addr)
+		return exitUsageError
+	}
+	// The requests are concurrent: the sub-agents use the server policies and the default model
+	s.delegation.bindUnattended(s.delegation.ctx, s.config, s.approveTools)
+	s.sessions = sessions.NewManager(sessionStore())
+	runOptions := []async.RunnerOption{async.WithLogger(ui.GetLogger()), async.WithProgressInterval(*webhookProgress)}
+	if *webhookURL != "" {
+		runOptions = append(runOptions, async.WithWebhook(&async.Webhook{URL: *webhookURL, Secret: *webhookSecret}))
+	}
+	s.runs = async.NewRunner(runOptions...)
+	if *cacheEnabled {
+		cache, err := memory.NewSemanticCache(s.embedder,
+			memory.WithCacheSimilarity(*cacheSimilarity),
+			memory.WithCacheTTL(*cacheTTL),
+			memory.WithCacheFile(*cacheFile),
+		)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitFailure
+		}
+		s.cache = cache
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", s.authenticated(s.handleChatCompletions))
+	mux.HandleFunc("GET /v1/models", s.authenticated(s.handleModels))
+	mux.HandleFunc("POST /v1/runs", s.authenticated(s.handleSubmitRun))
+	mux.HandleFunc("GET /v1/runs/{id}", s.authenticated(s.handleGetRun))
+	mux.HandleFunc("DELETE /v1/runs/{id}", s.authenticated(s.handleCancelRun))
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	httpServer := &http.Server{Addr: *addr, Handler: s.sameOrigin(mux)}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdownCtx)
+	}()
+
+	ui.Printf(ui.GetTheme().Info, "🚀 Bob is serving %s on %s/v1/chat/completions\n", s.model, *addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintln(os.Stderr, "server error:", err)
+		return exitFailure
+	}
+	// the webhook deliveries of the runs in progress are completed
+	s.runs.Wait()
+	return exitSuccess
+}
+
+// authenticated checks the API key of the requests when the server has one
+func (s *server) authenticated(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" && subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte("Bearer "+s.apiKey)) != 1 {
+			writeAPIError(w, http.StatusUnauthorized, "invalid_api_key", "invalid API key")
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// sameOrigin rejects the requests of the web pages of other sites (a browser can send a cross-site POST
+// without preflight) and, on the loopback interface, the requests for another host (DNS rebinding)
+func (s *server) sameOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.loopback && !isLoopbackHost(hostname(r.Host)) {
+			writeAPIError(w, http.StatusForbidden, "invalid_request_error", "invalid host "+r.Host)
+			return
+		}
+		if origin := r.Header.Get("Origin"); origin != "" {
+			if u, err := url.Parse(origin); err != nil || !strings.EqualFold(u.Host, r.Host) {
+				writeAPIError(w, http.StatusForbidden, "invalid_request_error", "cross-origin requests are not allowed")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// decodeJSON decodes the JSON body of a request, it writes the error and returns false if the body isn't JSON
+func decodeJSON(w http.ResponseWriter, r *http.Request, value any) bool {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+		writeAPIError(w, http.StatusUnsupportedMediaType, "invalid_request_error", "the Content-Type must be application/json")
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(value); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "invalid request body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections
+// (an address without host listens on all the interfaces)
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && isLoopbackHost(host)
+}
+
+// isLoopbackHost reports whether a host name or address is the loopback interface
+func isLoopbackHost(host string) bool {
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// hostname returns the host of a host:port, without the brackets of an IPv6 address
+func hostname(hostport string) string {
+	if host, _, err := net.SplitHostPort(hostport); err == nil {
+		return host
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// handleModels lists the model of Bob
+func (s *server) handleModels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"data": []map[string]any{
+			{"id": s.model, "object": "model", "owned_by": "bob"},
+		},
+	})
+}
+
+// handleChatCompletions runs the tool calls loop of the agent on the conversation of the request
+func (s *server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var request chatCompletionRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if len(request.Messages) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "messages is required")
+		return
+	}
+	model := request.Model
+	if model == "" {
+		model = s.model
+	}
+	agent, err := s.newAgent(model)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	// With a session ID, the history of the session precedes the messages of the request
+	var session *sessions.Session
+	if sessionID := r.Header.Get(sessionHeader); sessionID != "" {
+		// The session is released once the answer is saved
+		defer s.sessionLocks.lock(sessionID)()
+		session, err = s.sessions.Open(sessionID)
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "invalid session: "+err.Error())
+			return
+		}
+		w.Header().Set(sessionHeader, session.ID)
+	}
+	messages := s.prepareMessages(request.Messages)
+	if session != nil && len(session.Messages) > 0 {
+		messages = append(slices.Clone(session.Messages), withoutSystemMessage(messages)...)
+	}
+	// saveSession saves the conversation with the answer to the session of the request
+	saveSession := func(answer string) {
+		if session == nil {
+			return
+		}
+		session.SetMessages(append(agent.GetMessages(), openai.AssistantMessage(answer)))
+		session.Metadata["model"] = model
+		if err := s.sessions.Save(session); err != nil {
+			ui.GetLogger().Error("failed to save the session", "session", session.ID, "error", err)
+		}
+	}
+
+	response := chatCompletionResponse{
+		ID:      "chatcmpl-" + uuid.New().String(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   model,
+	}
+	// A single question without session can be answered from the semantic cache
+	lookup := s.lookupCache(w, session, model, request.Messages)
+
+	if !request.Stream {
+		finishReason, answer := "stop", lookup.answer
+		if !lookup.hit {
+			// The generation stops when the client disconnects
+			finishReason, _, answer, err = agent.(mu.ContextRunner).DetectToolCallsContext(r.Context(), messages, s.executeTool)
+			if err != nil {
+				writeAPIError(w, http.StatusBadGateway, "server_error", err.Error())
+				return
+			}
+			s.storeCache(model, lookup, answer)
+		}
+		saveSession(answer)
+		finishReason = normalizeFinishReason(finishReason)
+		response.Choices = []chatCompletionChoice{{
+			Message:      &chatCompletionMessage{Role: "assistant", Content: answer},
+			FinishReason: &finishReason,
+		}}
+		writeJSON(w, http.StatusOK, response)
+		return
+	}
+
+	// Server-sent events: a chunk per content delta, then a chunk with the finish reason and [DONE]
+	flusher, _ := w.(http.Flusher)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	response.Object = "chat.completion.chunk"
+	sendChunk := func(delta chatCompletionMessage, finishReason *string) error {
+		response.Choices = []chatCompletionChoice{{Delta: &delta, FinishReason: finishReason}}
+		data, err := json.Marshal(response)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	}
+
+	sendChunk(chatCompletionMessage{Role: "assistant"}, nil)
+	finishReason, answer := "stop", lookup.answer
+	if lookup.hit {
+		err = sendChunk(chatCompletionMessage{Content: answer}, nil)
+	} else {
+		finishReason, _, answer, err = agent.(mu.ContextRunner).DetectToolCallsStreamContext(r.Context(), messages, s.executeTool, func(content string) error {
+			if content == "" {
+				return nil
+			}
+			return sendChunk(chatCompletionMessage{Content: content}, nil)
+		})
+	}
+	if err != nil {
+		data, _ := json.Marshal(map[string]any{"error": map[string]string{"message": err.Error(), "type": "server_error"}})
+		fmt.Fprintf(w, "data: %s\n\n", data)
+	} else {
+		if !lookup.hit {
+			s.storeCache(model, lookup, answer)
+		}
+		saveSession(answer)
+		finishReason = normalizeFinishReason(finishReason)
+		sendChunk(chatCompletionMessage{}, &finishReason)
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// executeTool executes the tool calls server-side according to their policy
+func (s *server) executeTool(functionName string, arguments string) (string, error) {
+	if !approvedUnattended(s.config, functionName, s.approveTools) {
+		ui.GetLogger().Info("tool call refused", "function", functionName)
+		return `{"result": "Function not executed"}`, nil
+	}
+	ui.GetLogger().Info("tool call", "function", functionName, "arguments", arguments)
+	return s.toolbox.call(functionName, arguments)
+}
+
+// prepareMessages adds the system message of Bob if the request has none,
+// and augments the last user message with the documents
+func (s *server) prepareMessages(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	prepared := []openai.ChatCompletionMessageParamUnion{}
+	if messages[0].OfSystem == nil && messages[0].OfDeveloper == nil {
+		prepared = append(prepared, openai.SystemMessage(s.systemMessage))
+	}
+	prepared = append(prepared, messages...)
+
+	last := len(prepared) - 1
+	if s.docs != nil && prepared[last].OfUser != nil && prepared[last].OfUser.Content.OfString.Valid() {
+		prepared[last] = openai.UserMessage(s.docs.augment(prepared[last].OfUser.Content.OfString.Value))
+	}
+	return prepared
+}
+
+// withoutSystemMessage removes the system message of a conversation (the session already has one)
+func withoutSystemMessage(messages []openai.ChatCompletionMessageParamUnion) []openai.ChatCompletionMessageParamUnion {
+	if len(messages) > 0 && (messages[0].OfSystem != nil || messages[0].OfDeveloper != nil) {
+		return messages[1:]
+	}
+	return messages
+}
+
+// normalizeFinishReason returns the finish reason reported to the clients
+func normalizeFinishReason(finishReason string) string {
+	if finishReason == "" || strings.HasPrefix(finishReason, "exit") {
+		return "stop"
+	}
+	return finishReason
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+// writeAPIError writes an error with the format of the OpenAI API
+func writeAPIError(w http.ResponseWriter, status int, errorType, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]string{"message": message, "type": errorType},
+	})
+}
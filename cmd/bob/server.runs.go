@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/micro-agent/micro-agent-go/agent/async"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// runRequest is a chat completion request run in the background, with metadata returned in the events
+type runRequest struct {
+	Model    string                                   `json:"model"`
+	Messages []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	Metadata map[string]string                        `json:"metadata"`
+}
+
+// handleSubmitRun starts the tool calls loop of the agent in the background and answers the ID of the run:
+// the events of the run are delivered to the webhook of the server, and the run can be polled
+func (s *server) handleSubmitRun(w http.ResponseWriter, r *http.Request) {
+	var request runRequest
+	if !decodeJSON(w, r, &request) {
+		return
+	}
+	if len(request.Messages) == 0 {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "messages is required")
+		return
+	}
+	model := request.Model
+	if model == "" {
+		model = s.model
+	}
+	agent, err := s.newAgent(model)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return
+	}
+	id := s.runs.Submit(async.AgentJob(agent, s.prepareMessages(request.Messages), s.executeTool), request.Metadata)
+	run, _ := s.runs.Get(id)
+	w.Header().Set("Location", "/v1/runs/"+id)
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// handleGetRun answers the status of a run, with its output once completed
+func (s *server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := s.runs.Get(r.PathValue("id"))
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "not_found_error", "unknown run")
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// handleCancelRun cancels a running run
+func (s *server) handleCancelRun(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, ok := s.runs.Get(id); !ok {
+		writeAPIError(w, http.StatusNotFound, "not_found_error", "unknown run")
+		return
+	}
+	if err := s.runs.Cancel(id); err != nil {
+		writeAPIError(w, http.StatusConflict, "invalid_request_error", err.Error())
+		return
+	}
+	run, _ := s.runs.Get(id)
+	writeJSON(w, http.StatusOK, run)
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsLoopbackAddr(t *testing.T) {
+	tests := []struct {
+		addr     string
+		loopback bool
+	}{
+		{"localhost:8080", true},
+		{"127.0.0.1:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"192.168.1.10:8080", false},
+		{"example.com:8080", false},
+		{"8080", false},
+	}
+	for _, test := range tests {
+		if got := isLoopbackAddr(test.addr); got != test.loopback {
+			t.Errorf("isLoopbackAddr(%q) = %v, want %v", test.addr, got, test.loopback)
+		}
+	}
+}
+
+func TestRunServerRequiresAPIKeyBeyondLoopback(t *testing.T) {
+	t.Setenv("BOB_SERVER_API_KEY", "")
+	if code := runServer([]string{"-addr", ":0"}, &server{}); code != exitUsageError {
+		t.Errorf("expected a usage error without API key on all the interfaces, got %d", code)
+	}
+}
+
+func TestAuthenticated(t *testing.T) {
+	s := &server{apiKey: "secret"}
+	handler := s.authenticated(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	for authorization, status := range map[string]int{
+		"Bearer secret": http.StatusNoContent,
+		"Bearer secre":  http.StatusUnauthorized,
+		"":              http.StatusUnauthorized,
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+		request.Header.Set("Authorization", authorization)
+		recorder := httptest.NewRecorder()
+		handler(recorder, request)
+		if recorder.Code != status {
+			t.Errorf("Authorization %q: got %d, want %d", authorization, recorder.Code, status)
+		}
+	}
+}
+
+func TestSameOrigin(t *testing.T) {
+	handler := func(s *server) http.Handler {
+		return s.sameOrigin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+	}
+	tests := []struct {
+		loopback bool
+		host     string
+		origin   string
+		status   int
+	}{
+		{true, "localhost:8080", "", http.StatusNoContent},
+		{true, "127.0.0.1:8080", "http://127.0.0.1:8080", http.StatusNoContent},
+		{true, "[::1]:8080", "", http.StatusNoContent},
+		{true, "localhost:8080", "https://evil.example.com", http.StatusForbidden},
+		{true, "localhost:8080", "null", http.StatusForbidden},
+		{true, "evil.example.com:8080", "http://evil.example.com:8080", http.StatusForbidden},
+		{false, "bob.example.com", "", http.StatusNoContent},
+		{false, "bob.example.com", "https://bob.example.com", http.StatusNoContent},
+		{false, "bob.example.com", "https://evil.example.com", http.StatusForbidden},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		request.Host = test.host
+		if test.origin != "" {
+			request.Header.Set("Origin", test.origin)
+		}
+		recorder := httptest.NewRecorder()
+		handler(&server{loopback: test.loopback}).ServeHTTP(recorder, request)
+		if recorder.Code != test.status {
+			t.Errorf("Host %q, Origin %q: got %d, want %d", test.host, test.origin, recorder.Code, test.status)
+		}
+	}
+}
+
+func TestChatCompletionsRequiresJSON(t *testing.T) {
+	s := &server{}
+	for contentType, status := range map[string]int{
+		"":                                  http.StatusUnsupportedMediaType,
+		"text/plain":                        http.StatusUnsupportedMediaType,
+		"application/x-www-form-urlencoded": http.StatusUnsupportedMediaType,
+		"application/json; charset=utf-8":   http.StatusBadRequest,
+	} {
+		request := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"messages": []}`))
+		if contentType != "" {
+			request.Header.Set("Content-Type", contentType)
+		}
+		recorder := httptest.NewRecorder()
+		s.handleChatCompletions(recorder, request)
+		if recorder.Code != status {
+			t.Errorf("Content-Type %q: got %d, want %d", contentType, recorder.Code, status)
+		}
+	}
+}
+
+func TestSessionLocks(t *testing.T) {
+	var locks sessionLocks
+	unlock := locks.lock("a")
+	// Another session isn't blocked
+	locks.lock("b")()
+
+	acquired, released := make(chan struct{}), make(chan struct{})
+	go func() {
+		unlock := locks.lock("a")
+		close(acquired)
+		unlock()
+		close(released)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("a request of the same session shouldn't run concurrently")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("the session should be released")
+	}
+	<-released
+	locks.mutex.Lock()
+	defer locks.mutex.Unlock()
+	if len(locks.locks) != 0 {
+		t.Errorf("the released sessions should be forgotten, got %d", len(locks.locks))
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/sessions"
+)
+
+// Session is a conversation of Bob (including the tool exchanges) persisted to the session store
+type Session struct {
+	*sessions.Session
+	// Model is the model of the conversation (saved in the "model" metadata of the session)
+	Model string
+
+	store sessions.Store
+}
+
+// sessionsDir returns the directory of the session files: BOB_SESSIONS_DIR or ~/.bob/sessions
+func sessionsDir() string {
+	if dir := os.Getenv("BOB_SESSIONS_DIR"); dir != "" {
+		return dir
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".bob", "sessions")
+	}
+	return filepath.Join(".bob", "sessions")
+}
+
+// sessionStore returns the store of the sessions: a JSON file per session in the sessions directory
+func sessionStore() sessions.Store {
+	return sessions.NewFileStore(sessionsDir())
+}
+
+// newSession creates a session named after the current time if name is empty
+func newSession(store sessions.Store, name, model string) *Session {
+	if name == "" {
+		name = time.Now().Format("20060102-150405")
+	}
+	return &Session{Session: sessions.New(name), Model: model, store: store}
+}
+
+// loadSession reads the session with the given name (sessions.ErrNotFound if it doesn't exist)
+func loadSession(store sessions.Store, name string) (*Session, error) {
+	session, err := store.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{Session: session, Model: session.Metadata["model"], store: store}, nil
+}
+
+// listSessions returns the sessions of the store, the most recently updated first
+func listSessions(store sessions.Store) ([]*Session, error) {
+	stored, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*Session, 0, len(stored))
+	for _, session := range stored {
+		list = append(list, &Session{Session: session, Model: session.Metadata["model"], store: store})
+	}
+	return list, nil
+}
+
+// latestSession returns the most recently updated session, or nil if there is no session
+func latestSession(store sessions.Store) (*Session, error) {
+	list, err := listSessions(store)
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return list[0], nil
+}
+
+// countUserMessages returns the number of user messages (turns) of the session
+func (s *Session) countUserMessages() int {
+	count := 0
+	for _, message := range s.Messages {
+		if message.OfUser != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// save writes the session to its store
+func (s *Session) save() error {
+	s.UpdatedAt = time.Now()
+	s.Metadata["model"] = s.Model
+	return s.store.Save(s.Session)
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/speech"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// SpeechConfig configures the voice output of the answers (-speak, /speak) and the voice prompts (-audio, /listen)
+type SpeechConfig struct {
+	// Enabled speaks the answers from the start
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Engine is openai (default: the audio/speech API) or command (a local engine)
+	Engine string `yaml:"engine,omitempty"`
+	// BaseURL and APIKeyEnv of the speech API, the provider of the profile if empty
+	BaseURL   string `yaml:"base_url,omitempty"`
+	APIKeyEnv string `yaml:"api_key_env,omitempty"`
+	// Model, Voice, Instructions and Speed of the openai engine
+	Model        string  `yaml:"model,omitempty"`
+	Voice        string  `yaml:"voice,omitempty"`
+	Instructions string  `yaml:"instructions,omitempty"`
+	Speed        float64 `yaml:"speed,omitempty"`
+	// Format of the audio (wav by default)
+	Format string `yaml:"format,omitempty"`
+	// Command of the local engine: it reads the text on its standard input and writes the audio on its standard output
+	Command []string `yaml:"command,omitempty"`
+	// Player is the command playing the audio files (afplay, ffplay, mpv, paplay or aplay if empty)
+	Player []string `yaml:"player,omitempty"`
+	// TranscriptionModel of the voice prompts (whisper-1 by default) and Language of the speech (detected if empty)
+	TranscriptionModel string `yaml:"transcription_model,omitempty"`
+	Language           string `yaml:"language,omitempty"`
+	// Recorder is the command recording the voice prompts, {file} is the path of the wav file (rec, arecord or ffmpeg if empty)
+	Recorder []string `yaml:"recorder,omitempty"`
+	// MaxRecording is the maximum duration of a voice prompt (30s by default)
+	MaxRecording string `yaml:"max_recording,omitempty"`
+}
+
+// voice speaks the answers of the chat while they are streamed, and transcribes the voice prompts
+type voice struct {
+	config  SpeechConfig
+	client  openai.Client
+	speaker *speech.Speaker
+	enabled bool
+}
+
+// newVoice creates the voice output, the speaker is created when it is enabled
+func newVoice(client openai.Client, config SpeechConfig) *voice {
+	return &voice{config: config, client: client}
+}
+
+// speechClient returns the client of the speech API: the client of the profile without base_url
+func (v *voice) speechClient() openai.Client {
+	if v.config.BaseURL != "" {
+		return openai.NewClient(option.WithBaseURL(v.config.BaseURL), option.WithAPIKey(os.Getenv(v.config.APIKeyEnv)))
+	}
+	return v.client
+}
+
+// synthesizer returns the speech engine of the configuration
+func (v *voice) synthesizer() (speech.Synthesizer, error) {
+	format := v.config.Format
+	if format == "" {
+		format = "wav"
+	}
+	switch v.config.Engine {
+	case "", "openai":
+		options := []speech.OpenAIOption{speech.WithFormat(format)}
+		if v.config.Model != "" {
+			options = append(options, speech.WithModel(v.config.Model))
+		}
+		if v.config.Voice != "" {
+			options = append(options, speech.WithVoice(v.config.Voice))
+		}
+		if v.config.Instructions != "" {
+			options = append(options, speech.WithInstructions(v.config.Instructions))
+		}
+		if v.config.Speed > 0 {
+			options = append(options, speech.WithSpeed(v.config.Speed))
+		}
+		return speech.NewOpenAISynthesizer(v.speechClient(), options...), nil
+	case "command":
+		if len(v.config.Command) == 0 {
+			return nil, errors.New("the command speech engine requires speech.command")
+		}
+		return speech.NewCommandSynthesizer(format, v.config.Command[0], v.config.Command[1:]...), nil
+	}
+	return nil, fmt.Errorf("unknown speech engine %q (openai or command)", v.config.Engine)
+}
+
+// enable creates the speaker on the first use and speaks the next answers
+func (v *voice) enable() error {
+	if v.speaker == nil {
+		synthesizer, err := v.synthesizer()
+		if err != nil {
+			return err
+		}
+		var player speech.Player
+		if len(v.config.Player) > 0 {
+			player = speech.NewCommandPlayer(v.config.Player[0], v.config.Player[1:]...)
+		} else if player, err = speech.DefaultPlayer(); err != nil {
+			return err
+		}
+		v.speaker = speech.NewSpeaker(synthesizer, player, speech.WithLogger(ui.GetLogger()))
+	}
+	v.enabled = true
+	return nil
+}
+
+// disable stops the speech of the current answer and of the next ones
+func (v *voice) disable() {
+	v.enabled = false
+	if v.speaker != nil {
+		v.speaker.Stop()
+	}
+}
+
+// callback returns the stream callback speaking the answer before calling next
+func (v *voice) callback(next func(string) error) func(string) error {
+	if !v.enabled {
+		return next
+	}
+	return v.speaker.StreamCallback(next)
+}
+
+// endAnswer speaks the end of the answer, or stops the speech when the answer is interrupted
+func (v *voice) endAnswer(interrupted bool) {
+	if !v.enabled {
+		return
+	}
+	if interrupted {
+		v.speaker.Stop()
+		return
+	}
+	v.speaker.Flush()
+}
+
+// close stops the speaker
+func (v *voice) close() {
+	if v.speaker != nil {
+		v.speaker.Close()
+	}
+}
+
+// transcribe converts a voice prompt to text with the transcription API
+func (v *voice) transcribe(ctx context.Context, audio speech.Audio) (string, error) {
+	options := []speech.TranscriberOption{}
+	if v.config.TranscriptionModel != "" {
+		options = append(options, speech.WithTranscriptionModel(v.config.TranscriptionModel))
+	}
+	if v.config.Language != "" {
+		options = append(options, speech.WithLanguage(v.config.Language))
+	}
+	text, err := speech.NewOpenAITranscriber(v.speechClient(), options...).Transcribe(ctx, audio)
+	if err != nil {
+		return "", fmt.Errorf("unable to transcribe the audio: %w", err)
+	}
+	if text == "" {
+		return "", errors.New("no speech recognized")
+	}
+	return text, nil
+}
+
+// transcribeFile converts an audio file to text
+func (v *voice) transcribeFile(ctx context.Context, path string) (string, error) {
+	audio, err := speech.ReadAudioFile(path)
+	if err != nil {
+		return "", err
+	}
+	return v.transcribe(ctx, audio)
+}
+
+// recorder returns the recorder of the voice prompts
+func (v *voice) recorder() (speech.Recorder, error) {
+	maxRecording := 30 * time.Second
+	if v.config.MaxRecording != "" {
+		duration, err := time.ParseDuration(v.config.MaxRecording)
+		if err != nil {
+			return nil, fmt.Errorf("invalid speech.max_recording %q: %w", v.config.MaxRecording, err)
+		}
+		maxRecording = duration
+	}
+	if len(v.config.Recorder) > 0 {
+		return speech.NewCommandRecorder("wav", v.config.Recorder[0], v.config.Recorder[1:]...), nil
+	}
+	return speech.DefaultRecorder(maxRecording)
+}
+
+// registerSpeechCommands registers /speak, which toggles the voice output
+func registerSpeechCommands(registry *ui.CommandRegistry, v *voice) {
+	registry.Register(ui.SlashCommand{
+		Name:        "/speak",
+		Usage:       "/speak [on|off]",
+		Description: "Speak the answers aloud (toggled without argument)",
+		Handler: func(args string) error {
+			enable := !v.enabled
+			switch args {
+			case "on":
+				enable = true
+			case "off":
+				enable = false
+			case "":
+			default:
+				return fmt.Errorf("usage: /speak [on|off]")
+			}
+			if !enable {
+				v.disable()
+				ui.Println(ui.GetTheme().Info, "🔇 Voice output disabled")
+				return nil
+			}
+			if err := v.enable(); err != nil {
+				return fmt.Errorf("unable to enable the voice output: %w", err)
+			}
+			ui.Println(ui.GetTheme().Info, "🔊 Voice output enabled")
+			return nil
+		},
+	})
+}
+
+// registerListenCommand registers /listen, which sends a voice prompt: the speech is recorded until a silence
+// or Ctrl+C (or read from an audio file), transcribed and stored into editedInput to be sent as the prompt
+func registerListenCommand(ctx context.Context, registry *ui.CommandRegistry, v *voice, interrupts *interruptHandler, editedInput *string) {
+	theme := ui.GetTheme()
+	registry.Register(ui.SlashCommand{
+		Name:        "/listen",
+		Usage:       "/listen [audio file]",
+		Description: "Send a voice prompt: record it (until a silence or Ctrl+C), or transcribe an audio file",
+		Handler: func(args string) error {
+			var text string
+			var err error
+			if args != "" {
+				text, err = v.transcribeFile(ctx, args)
+			} else {
+				text, err = v.listen(ctx, interrupts)
+			}
+			if err != nil {
+				return err
+			}
+			ui.Println(theme.User, "🎤", text)
+			*editedInput = text
+			return nil
+		},
+	})
+}
+
+// listen records a voice prompt until the recorder stops or Ctrl+C, and transcribes it
+func (v *voice) listen(ctx context.Context, interrupts *interruptHandler) (string, error) {
+	recorder, err := v.recorder()
+	if err != nil {
+		return "", err
+	}
+	// The voice output would be recorded with the prompt
+	if v.speaker != nil {
+		v.speaker.Stop()
+	}
+	spinner := ui.NewThinkingController()
+	spinner.Start(ui.GetTheme().Info, "🎙️  Listening... (silence or Ctrl+C to stop)")
+	recordCtx := interrupts.startGeneration(ctx)
+	audio, err := recorder.Record(recordCtx)
+	interrupts.endGeneration()
+	spinner.Stop()
+	if err != nil {
+		return "", fmt.Errorf("unable to record the voice prompt: %w", err)
+	}
+	return v.transcribe(ctx, audio)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// delegateToolName is the name of the tool delegating a task to a sub-agent
+const delegateToolName = "delegate_to_agent"
+
+// SubAgentConfig is a specialized sub-agent the tasks can be delegated to (/spawn, delegate_to_agent)
+type SubAgentConfig struct {
+	// Description tells the main agent when to delegate to this sub-agent
+	Description string `yaml:"description,omitempty"`
+	// System is the system message of the sub-agent
+	System string `yaml:"system"`
+	// Model of the sub-agent (the model of Bob if empty)
+	Model string `yaml:"model,omitempty"`
+	// Tools are the names of the tools the sub-agent can call (none if empty)
+	Tools []string `yaml:"tools,omitempty"`
+}
+
+// delegator creates the sub-agents and runs their tasks
+type delegator struct {
+	client  openai.Client
+	toolbox *toolbox
+	agents  map[string]SubAgentConfig
+
+	// Bound by each mode: the context of the generation, the model of Bob and the execution
+	// of the tool calls of the sub-agents (with the approval of the mode)
+	ctx     context.Context
+	model   string
+	execute func(functionName string, arguments string) (string, error)
+}
+
+// newDelegator creates a delegator for the sub-agents of the configuration
+func newDelegator(ctx context.Context, client openai.Client, toolbox *toolbox, agents map[string]SubAgentConfig, model string) *delegator {
+	d := &delegator{
+		client:  client,
+		toolbox: toolbox,
+		agents:  agents,
+		model:   model,
+	}
+	d.bindUnattended(ctx, &Config{}, false)
+	return d
+}
+
+// bind sets the context, the model and the tool execution used by the next sub-agents
+func (d *delegator) bind(ctx context.Context, model string, execute func(functionName string, arguments string) (string, error)) {
+	d.ctx = ctx
+	d.model = model
+	d.execute = execute
+}
+
+// bindUnattended executes the tool calls of the sub-agents according to the policies, without confirmation
+func (d *delegator) bindUnattended(ctx context.Context, config *Config, approveTools bool) {
+	d.bind(ctx, d.model, func(functionName string, arguments string) (string, error) {
+		if !approvedUnattended(config, functionName, approveTools) {
+			ui.GetLogger().Info("sub-agent tool call refused", "function", functionName)
+			return `{"result": "Function not executed"}`, nil
+		}
+		return d.toolbox.call(functionName, arguments)
+	})
+}
+
+// names returns the sorted names of the configured sub-agents
+func (d *delegator) names() []string {
+	names := make([]string, 0, len(d.agents))
+	for name := range d.agents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// run creates a sub-agent and returns its answer to the task.
+// The sub-agent only sees the task, not the conversation of Bob.
+func (d *delegator) run(name string, spec SubAgentConfig, task string) (string, error) {
+	model := spec.Model
+	if model == "" {
+		model = d.model
+	}
+	params := openai.ChatCompletionNewParams{
+		Model:       model,
+		Temperature: openai.Opt(0.0),
+	}
+	// The delegation tool is never given to a sub-agent: no recursive delegation
+	for _, tool := range d.toolbox.openAITools() {
+		toolName := tool.GetFunction().Name
+		if toolName != delegateToolName && slices.Contains(spec.Tools, toolName) {
+			params.Tools = append(params.Tools, tool)
+		}
+	}
+	if len(params.Tools) > 0 {
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionUnionParam{OfAuto: openai.String("auto")}
+		params.ParallelToolCalls = openai.Opt(false)
+	}
+
+	agent, err := mu.NewAgentWithDescription(d.ctx, name, spec.Description,
+		mu.WithClient(d.client),
+		mu.WithParams(params),
+	)
+	if err != nil {
+		return "", err
+	}
+	ui.GetLogger().Info("sub-agent spawned", "agent", name, "model", model, "tools", len(params.Tools))
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage(spec.System),
+		openai.UserMessage(task),
+	}
+	_, _, answer, err := agent.DetectToolCalls(messages, d.execute)
+	if err != nil {
+		return "", fmt.Errorf("sub-agent %s failed: %w", name, err)
+	}
+	return answer, nil
+}
+
+// spawn runs a task with a configured sub-agent
+func (d *delegator) spawn(name string, task string) (string, error) {
+	spec, ok := d.agents[name]
+	if !ok {
+		return "", fmt.Errorf("unknown sub-agent %s (available: %s)", name, strings.Join(d.names(), ", "))
+	}
+	return d.run(name, spec, task)
+}
+
+// delegateArguments are the arguments of the delegate_to_agent tool
+type delegateArguments struct {
+	Agent        string   `json:"agent"`
+	Task         string   `json:"task"`
+	SystemPrompt string   `json:"system_prompt"`
+	Tools        []string `json:"tools"`
+}
+
+// tool returns the delegate_to_agent tool: a configured sub-agent by name, or an ad-hoc one with its system prompt
+func (d *delegator) tool() builtinTool {
+	agents := []string{}
+	for _, name := range d.names() {
+		agents = append(agents, fmt.Sprintf("%s (%s)", name, d.agents[name].Description))
+	}
+	description := "Delegate a self-contained task to a specialized sub-agent and return its answer. " +
+		"The sub-agent doesn't see the conversation: the task must give all the needed context."
+	if len(agents) > 0 {
+		description += " Available sub-agents: " + strings.Join(agents, ", ") + "."
+	}
+
+	return builtinTool{
+		definition: toolDefinition(delegateToolName, description, map[string]any{
+			"agent":         stringProperty("name of the sub-agent (empty for an ad-hoc sub-agent defined by system_prompt)"),
+			"task":          stringProperty("the task to complete, with all the needed context"),
+			"system_prompt": stringProperty("system prompt of an ad-hoc sub-agent"),
+			"tools": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "names of the tools of an ad-hoc sub-agent",
+			},
+		}, "task"),
+		run: func(arguments string) (any, error) {
+			var args delegateArguments
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments: %w", err)
+			}
+			if strings.TrimSpace(args.Task) == "" {
+				return nil, errors.New("the task is empty")
+			}
+			if args.Agent != "" {
+				return d.spawn(args.Agent, args.Task)
+			}
+			if strings.TrimSpace(args.SystemPrompt) == "" {
+				return nil, errors.New("either agent or system_prompt is required")
+			}
+			return d.run("ad-hoc", SubAgentConfig{System: args.SystemPrompt, Tools: args.Tools}, args.Task)
+		},
+	}
+}
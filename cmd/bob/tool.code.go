@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+)
+
+// CodeConfig configures the built-in execute_code tool, which runs the programs of the model in containers
+type CodeConfig struct {
+	// Enabled adds the execute_code tool (also enabled by -code)
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Runtime is the container command: docker (default) or podman
+	Runtime string `yaml:"runtime,omitempty"`
+	// Timeout of a program (30s if zero)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// CPUs and Memory limit each container (1 CPU and 512m if empty)
+	CPUs   float64 `yaml:"cpus,omitempty"`
+	Memory string  `yaml:"memory,omitempty"`
+	// Network gives the network to the programs (no network by default)
+	Network bool `yaml:"network,omitempty"`
+	// WorkDir is mounted at /workspace, the working directory of the programs (nothing is mounted if empty)
+	WorkDir string `yaml:"work_dir,omitempty"`
+	// Images replace the images of the languages (python, go, javascript), e.g. a Python image with pandas
+	Images map[string]string `yaml:"images,omitempty"`
+}
+
+// newCodeTool creates the execute_code tool of the configuration
+func newCodeTool(config CodeConfig) *tools.CodeTool {
+	options := []tools.CodeToolOption{}
+	if config.Runtime != "" {
+		options = append(options, tools.WithCodeRuntime(config.Runtime))
+	}
+	if config.Timeout > 0 {
+		options = append(options, tools.WithCodeTimeout(config.Timeout))
+	}
+	if config.CPUs > 0 || config.Memory != "" {
+		cpus, memory := config.CPUs, config.Memory
+		if cpus <= 0 {
+			cpus = 1
+		}
+		if memory == "" {
+			memory = "512m"
+		}
+		options = append(options, tools.WithCodeLimits(cpus, memory))
+	}
+	if config.Network {
+		options = append(options, tools.WithCodeNetwork(true))
+	}
+	if config.WorkDir != "" {
+		options = append(options, tools.WithCodeWorkDir(config.WorkDir))
+	}
+	for name, image := range config.Images {
+		language, ok := tools.DefaultCodeLanguages[name]
+		if !ok {
+			continue
+		}
+		language.Image = image
+		options = append(options, tools.WithCodeLanguage(name, language))
+	}
+	return tools.NewCodeTool(options...)
+}
+
+// newCodeBuiltinTool returns the execute_code tool as a built-in tool of Bob
+func newCodeBuiltinTool(codeTool *tools.CodeTool) builtinTool {
+	return builtinTool{
+		definition: codeTool.OpenAITools()[0],
+		run: func(arguments string) (any, error) {
+			result, err := codeTool.CallTool(arguments)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(result), nil
+		},
+	}
+}
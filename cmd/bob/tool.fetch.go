@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+)
+
+// FetchConfig configures the built-in fetch_url tool, which downloads web pages as markdown
+type FetchConfig struct {
+	// Enabled adds the fetch_url tool (also enabled by -fetch)
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Timeout of a download (20s if zero)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// MaxTokens is the token budget of a page (4000 if zero)
+	MaxTokens int `yaml:"max_tokens,omitempty"`
+	// UserAgent of the requests, also matched by the robots.txt files
+	UserAgent string `yaml:"user_agent,omitempty"`
+	// IgnoreRobots disables the robots.txt checks
+	IgnoreRobots bool `yaml:"ignore_robots,omitempty"`
+	// PrivateNetworks allows the local and private addresses (refused by default)
+	PrivateNetworks bool `yaml:"private_networks,omitempty"`
+}
+
+// newFetchTool creates the fetch_url tool of the configuration
+func newFetchTool(config FetchConfig) *tools.FetchTool {
+	options := []tools.FetchToolOption{
+		tools.WithFetchRobots(!config.IgnoreRobots),
+		tools.WithFetchPrivateNetworks(config.PrivateNetworks),
+	}
+	if config.Timeout > 0 {
+		options = append(options, tools.WithFetchTimeout(config.Timeout))
+	}
+	if config.MaxTokens > 0 {
+		options = append(options, tools.WithFetchMaxTokens(config.MaxTokens))
+	}
+	if config.UserAgent != "" {
+		options = append(options, tools.WithFetchUserAgent(config.UserAgent))
+	}
+	return tools.NewFetchTool(options...)
+}
+
+// newFetchBuiltinTool returns the fetch_url tool as a built-in tool of Bob
+func newFetchBuiltinTool(fetchTool *tools.FetchTool) builtinTool {
+	return builtinTool{
+		definition: fetchTool.OpenAITools()[0],
+		run: func(arguments string) (any, error) {
+			result, err := fetchTool.CallTool(arguments)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(result), nil
+		},
+	}
+}
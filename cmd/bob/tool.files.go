@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+)
+
+// FilesConfig configures the built-in filesystem tools (read_file, write_file, list_dir, apply_patch)
+type FilesConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Root is the project directory, the tools can't access the files outside (the current directory if empty)
+	Root string `yaml:"root,omitempty"`
+	// MaxReadSize is the maximum size in bytes of the content returned by read_file (64KB if zero)
+	MaxReadSize int `yaml:"max_read_size,omitempty"`
+}
+
+// fileArguments are the arguments of the filesystem tools
+type fileArguments struct {
+	Path      string `json:"path"`
+	Content   string `json:"content"`
+	Patch     string `json:"patch"`
+	Recursive bool   `json:"recursive"`
+}
+
+// newFileTools creates the filesystem tools scoped to the project directory.
+// The write tools have a preview: the diff of the changes displayed before the confirmation.
+func newFileTools(config FilesConfig) ([]builtinTool, error) {
+	root, err := jailRoot(config.Root)
+	if err != nil {
+		return nil, err
+	}
+	maxReadSize := config.MaxReadSize
+	if maxReadSize <= 0 {
+		maxReadSize = 64 * 1024
+	}
+
+	// parse decodes the arguments and resolves the path inside the project directory
+	parse := func(arguments string) (fileArguments, string, error) {
+		var args fileArguments
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return args, "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		path, err := jailPath(root, root, args.Path)
+		return args, path, err
+	}
+
+	// readExisting returns the content of a file, empty if the file doesn't exist
+	readExisting := func(path string) (string, error) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return string(data), err
+	}
+
+	// diffPreview renders the changes of a write tool
+	diffPreview := func(path, oldContent, newContent string) string {
+		name, _ := filepath.Rel(root, path)
+		return ui.RenderDiff(oldContent, newContent, ui.DiffOptions{OldName: name, NewName: name})
+	}
+
+	readFile := builtinTool{
+		definition: toolDefinition("read_file",
+			"Read a text file of the project",
+			map[string]any{"path": stringProperty("Path relative to the project directory")},
+			"path"),
+		run: func(arguments string) (any, error) {
+			_, path, err := parse(arguments)
+			if err != nil {
+				return nil, err
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			if len(data) > maxReadSize {
+				return string(data[:maxReadSize]) + fmt.Sprintf("\n[truncated: %d of %d bytes]", maxReadSize, len(data)), nil
+			}
+			return string(data), nil
+		},
+	}
+
+	listDir := builtinTool{
+		definition: toolDefinition("list_dir",
+			"List the files of a directory of the project (the directories end with /)",
+			map[string]any{
+				"path":      stringProperty("Path relative to the project directory, . for the project directory"),
+				"recursive": map[string]any{"type": "boolean", "description": "List the subdirectories too"},
+			}),
+		run: func(arguments string) (any, error) {
+			args, dir, err := parse(arguments)
+			if err != nil {
+				return nil, err
+			}
+			entries := []string{}
+			err = filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if path == dir {
+					return nil
+				}
+				name, _ := filepath.Rel(dir, path)
+				if entry.IsDir() {
+					entries = append(entries, filepath.ToSlash(name)+"/")
+					if !args.Recursive || strings.HasPrefix(entry.Name(), ".") {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				entries = append(entries, filepath.ToSlash(name))
+				return nil
+			})
+			return entries, err
+		},
+	}
+
+	writeFile := builtinTool{
+		definition: toolDefinition("write_file",
+			"Create or replace a file of the project with the given content",
+			map[string]any{
+				"path":    stringProperty("Path relative to the project directory"),
+				"content": stringProperty("The complete new content of the file"),
+			},
+			"path", "content"),
+		preview: func(arguments string) (string, error) {
+			args, path, err := parse(arguments)
+			if err != nil {
+				return "", err
+			}
+			oldContent, err := readExisting(path)
+			if err != nil {
+				return "", err
+			}
+			return diffPreview(path, oldContent, args.Content), nil
+		},
+		run: func(arguments string) (any, error) {
+			args, path, err := parse(arguments)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, []byte(args.Content), 0644); err != nil {
+				return nil, err
+			}
+			return fmt.Sprintf("%s written (%d bytes)", args.Path, len(args.Content)), nil
+		},
+	}
+
+	// patched returns the content of the file with the patch applied
+	patched := func(arguments string) (fileArguments, string, string, string, error) {
+		args, path, err := parse(arguments)
+		if err != nil {
+			return args, "", "", "", err
+		}
+		oldContent, err := os.ReadFile(path)
+		if err != nil {
+			return args, "", "", "", err
+		}
+		newContent, err := applyUnifiedPatch(string(oldContent), args.Patch)
+		return args, path, string(oldContent), newContent, err
+	}
+
+	applyPatch := builtinTool{
+		definition: toolDefinition("apply_patch",
+			"Modify a file of the project with a unified diff (hunks starting with @@, lines prefixed by space, - or +)",
+			map[string]any{
+				"path":  stringProperty("Path relative to the project directory"),
+				"patch": stringProperty("The unified diff hunks to apply to the file"),
+			},
+			"path", "patch"),
+		preview: func(arguments string) (string, error) {
+			_, path, oldContent, newContent, err := patched(arguments)
+			if err != nil {
+				return "", err
+			}
+			return diffPreview(path, oldContent, newContent), nil
+		},
+		run: func(arguments string) (any, error) {
+			args, path, _, newContent, err := patched(arguments)
+			if err != nil {
+				return nil, err
+			}
+			if err := os.WriteFile(path, []byte(newContent), 0644); err != nil {
+				return nil, err
+			}
+			return args.Path + " patched", nil
+		},
+	}
+
+	return []builtinTool{readFile, listDir, writeFile, applyPatch}, nil
+}
+
+// hunkHeaderRegexp matches "@@ -12,5 +12,6 @@", the line numbers are optional
+var hunkHeaderRegexp = regexp.MustCompile(`^@@(?: -(\d+)(?:,\d+)? \+\d+(?:,\d+)?)? @@`)
+
+// applyUnifiedPatch applies the hunks of a unified diff to a text. The hunks are located by their context and
+// removed lines (the line numbers of the headers are only a hint), so a patch written by a model still applies
+// if the line numbers are wrong.
+func applyUnifiedPatch(content, patch string) (string, error) {
+	lines := strings.Split(content, "\n")
+	type hunk struct {
+		hint     int
+		old, new []string
+	}
+	hunks := []*hunk{}
+	var current *hunk
+	for _, line := range strings.Split(strings.TrimRight(patch, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "---") || strings.HasPrefix(line, "+++"):
+			// file headers
+		case strings.HasPrefix(line, "@@"):
+			matches := hunkHeaderRegexp.FindStringSubmatch(line)
+			if matches == nil {
+				return "", fmt.Errorf("invalid hunk header: %s", line)
+			}
+			current = &hunk{}
+			if matches[1] != "" {
+				current.hint, _ = strconv.Atoi(matches[1])
+			}
+			hunks = append(hunks, current)
+		case current == nil:
+			return "", errors.New("the patch must start with a hunk header (@@ ... @@)")
+		case strings.HasPrefix(line, "+"):
+			current.new = append(current.new, line[1:])
+		case strings.HasPrefix(line, "-"):
+			current.old = append(current.old, line[1:])
+		case strings.HasPrefix(line, " "):
+			current.old = append(current.old, line[1:])
+			current.new = append(current.new, line[1:])
+		case line == "":
+			current.old = append(current.old, "")
+			current.new = append(current.new, "")
+		case strings.HasPrefix(line, `\`):
+			// "\ No newline at end of file"
+		default:
+			return "", fmt.Errorf("invalid patch line: %s", line)
+		}
+	}
+	if len(hunks) == 0 {
+		return "", errors.New("the patch has no hunk")
+	}
+
+	// The hunks are applied in order, each one after the previous
+	start := 0
+	for idx, h := range hunks {
+		position := findLines(lines, h.old, start, h.hint-1)
+		if position < 0 {
+			return "", fmt.Errorf("hunk %d doesn't match the content of the file", idx+1)
+		}
+		lines = append(lines[:position], append(append([]string{}, h.new...), lines[position+len(h.old):]...)...)
+		start = position + len(h.new)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// findLines returns the position of block in lines from start, the closest to hint, or -1 if not found
+func findLines(lines, block []string, start, hint int) int {
+	best := -1
+	for position := start; position+len(block) <= len(lines); position++ {
+		match := true
+		for i, line := range block {
+			if lines[position+i] != line {
+				match = false
+				break
+			}
+		}
+		if match && (best < 0 || abs(position-hint) < abs(best-hint)) {
+			best = position
+		}
+	}
+	return best
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// ImagesConfig configures the image generation (/image and the generate_image tool)
+type ImagesConfig struct {
+	// Enabled adds the generate_image tool (also enabled by -images)
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Model of the images (gpt-image-1 by default)
+	Model string `yaml:"model,omitempty"`
+	// Size and Quality of the images, the defaults of the model if empty
+	Size    string `yaml:"size,omitempty"`
+	Quality string `yaml:"quality,omitempty"`
+	// OutputDir is the directory of the saved images (the current directory by default)
+	OutputDir string `yaml:"output_dir,omitempty"`
+}
+
+// newImageTool creates the image generation tool of the configuration, the images are saved to its output directory
+func newImageTool(ctx context.Context, client openai.Client, config ImagesConfig) (*tools.ImageTool, error) {
+	params := openai.ImageGenerateParams{Model: config.Model}
+	if params.Model == "" {
+		params.Model = openai.ImageModelGPTImage1
+	}
+	if config.Size != "" {
+		params.Size = openai.ImageGenerateParamsSize(config.Size)
+	}
+	if config.Quality != "" {
+		params.Quality = openai.ImageGenerateParamsQuality(config.Quality)
+	}
+	imageAgent, err := mu.NewAgent(ctx, "Painter", mu.WithClient(client), mu.WithImageParams(params))
+	if err != nil {
+		return nil, err
+	}
+	options := []tools.ImageToolOption{
+		tools.WithOnImage(func(path string, _ mu.Image) {
+			ui.Println(ui.GetTheme().Info, "🖼️  Image saved to", path)
+		}),
+	}
+	if config.OutputDir != "" {
+		options = append(options, tools.WithImageOutputDir(config.OutputDir))
+	}
+	return tools.NewImageTool(imageAgent.(mu.ImageGenerator), options...), nil
+}
+
+// newImageBuiltinTool returns the generate_image tool as a built-in tool of Bob
+func newImageBuiltinTool(imageTool *tools.ImageTool) builtinTool {
+	return builtinTool{
+		definition: imageTool.OpenAITools()[0],
+		run: func(arguments string) (any, error) {
+			result, err := imageTool.CallTool(arguments)
+			if err != nil {
+				return nil, err
+			}
+			return json.RawMessage(result), nil
+		},
+	}
+}
+
+// registerImageCommand registers /image, which generates an image and saves it
+func registerImageCommand(registry *ui.CommandRegistry, imageTool *tools.ImageTool) {
+	registry.Register(ui.SlashCommand{
+		Name:        "/image",
+		Usage:       "/image <description>",
+		Description: "Generate an image and save it (see the images section of the configuration)",
+		Handler: func(args string) error {
+			if strings.TrimSpace(args) == "" {
+				return fmt.Errorf("usage: /image <description>")
+			}
+			spinner := ui.NewThinkingController()
+			spinner.Start(ui.GetTheme().Info, "🎨 Generating the image...")
+			arguments, _ := json.Marshal(map[string]string{"prompt": args})
+			_, err := imageTool.CallTool(string(arguments))
+			spinner.Stop()
+			if err != nil {
+				return fmt.Errorf("unable to generate the image: %w", err)
+			}
+			return nil
+		},
+	})
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+)
+
+// ShellConfig configures the built-in run_shell tool
+type ShellConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// AllowedCommands are the executables the model can run (defaultShellCommands if empty)
+	AllowedCommands []string `yaml:"allowed_commands,omitempty"`
+	// WorkingDir is the jail of the commands (the current directory if empty)
+	WorkingDir string `yaml:"working_dir,omitempty"`
+	// Timeout of a command (30s if zero)
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// MaxOutput is the maximum size in bytes of the output returned to the model (16KB if zero)
+	MaxOutput int `yaml:"max_output,omitempty"`
+}
+
+// defaultShellCommands are the commands allowed when the configuration doesn't give an allowlist
+// (find and git aren't: their options can run other commands, they must be allowed explicitly)
+var defaultShellCommands = []string{"ls", "cat", "head", "tail", "grep", "wc", "pwd", "echo", "date", "diff"}
+
+// forbiddenShellOptions are the options of the allowed commands which run other commands, write files
+// or leave the jail; an option matches its exact name and its --name=value form
+var forbiddenShellOptions = map[string][]string{
+	"find": {"-exec", "-execdir", "-ok", "-okdir", "-delete", "-fprint", "-fprint0", "-fprintf", "-fls"},
+}
+
+// gitDiffOptions are the options of the git subcommands displaying changes (they only format the output)
+var gitDiffOptions = []string{"-p", "--patch", "-s", "--no-patch", "--stat", "--shortstat", "--numstat",
+	"--name-only", "--name-status", "-U", "--unified", "-w", "--ignore-all-space", "--word-diff",
+	"--color", "--no-color"}
+
+// gitLogOptions are the options of the git subcommands walking the history
+var gitLogOptions = append([]string{"-n", "--max-count", "--skip", "--oneline", "--format", "--pretty",
+	"--abbrev-commit", "--date", "--graph", "--decorate", "--all", "--author", "--committer", "--grep",
+	"-i", "--since", "--until", "--after", "--before", "--reverse", "--first-parent", "--merges",
+	"--no-merges", "--follow"}, gitDiffOptions...)
+
+// gitSubcommands are the read-only git subcommands run_shell accepts, with their allowed options
+// (an option matches its exact name and its --name=value form, the other options are rejected: git
+// has too many options running commands or writing files for a denylist)
+var gitSubcommands = map[string][]string{
+	"status":    {"-s", "--short", "-b", "--branch", "--porcelain", "-u", "--untracked-files", "--ignored"},
+	"log":       gitLogOptions,
+	"show":      gitLogOptions,
+	"diff":      append([]string{"--cached", "--staged"}, gitDiffOptions...),
+	"blame":     {"-L", "-w", "-e", "--show-email", "-s", "--porcelain", "--date"},
+	"shortlog":  {"-n", "--numbered", "-s", "--summary", "-e", "--email", "--since", "--until", "--all"},
+	"ls-files":  {"-c", "--cached", "-o", "--others", "-m", "--modified", "-d", "--deleted", "--exclude-standard"},
+	"rev-parse": {"--short", "--abbrev-ref", "--show-toplevel", "--show-prefix", "--verify"},
+	"describe":  {"--tags", "--always", "--abbrev", "--long", "--dirty"},
+}
+
+// gitSafetyArgs are inserted before the git subcommands: the repository configuration (writable with
+// the filesystem tools) could otherwise run a pager, a fsmonitor hook or the external diff and textconv drivers
+var gitSafetyArgs = map[string][]string{
+	"log":   {"--no-ext-diff", "--no-textconv"},
+	"show":  {"--no-ext-diff", "--no-textconv"},
+	"diff":  {"--no-ext-diff", "--no-textconv"},
+	"blame": {"--no-textconv"},
+}
+
+// shellResult is the result of run_shell returned to the model
+type shellResult struct {
+	ExitCode  int    `json:"exit_code"`
+	Output    string `json:"output"`
+	Truncated bool   `json:"truncated,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+}
+
+// newShellTool creates the run_shell tool. The commands are executed without shell (no pipes, redirections
+// or variables), only the allowed executables can be run, and the working directory and the path arguments
+// must stay inside the jail directory.
+func newShellTool(config ShellConfig) (builtinTool, error) {
+	allowed := config.AllowedCommands
+	if len(allowed) == 0 {
+		allowed = defaultShellCommands
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	maxOutput := config.MaxOutput
+	if maxOutput <= 0 {
+		maxOutput = 16 * 1024
+	}
+	root, err := jailRoot(config.WorkingDir)
+	if err != nil {
+		return builtinTool{}, err
+	}
+
+	definition := toolDefinition("run_shell",
+		fmt.Sprintf("Run a command in the project directory and return its exit code and output. "+
+			"Allowed commands: %s. Pipes, redirections and variables are not supported.", strings.Join(allowed, ", ")),
+		map[string]any{
+			"command": stringProperty("The command line, e.g. git status"),
+			"dir":     stringProperty("Working directory relative to the project directory (optional)"),
+		},
+		"command")
+
+	run := func(arguments string) (any, error) {
+		var args struct {
+			Command string `json:"command"`
+			Dir     string `json:"dir"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return nil, fmt.Errorf("invalid arguments: %w", err)
+		}
+		words, err := splitCommandLine(args.Command)
+		if err != nil {
+			return nil, err
+		}
+		if len(words) == 0 {
+			return nil, errors.New("empty command")
+		}
+		if !slices.Contains(allowed, words[0]) {
+			return nil, fmt.Errorf("the command %q is not allowed (allowed: %s)", words[0], strings.Join(allowed, ", "))
+		}
+		if err := checkShellOptions(words); err != nil {
+			return nil, err
+		}
+		dir, err := jailPath(root, root, args.Dir)
+		if err != nil {
+			return nil, err
+		}
+		// The arguments can't reach files outside the jail (directly or through a symbolic link)
+		for _, word := range words[1:] {
+			if _, err := jailPath(root, dir, argumentPath(word)); err != nil {
+				return nil, err
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+		if words[0] == "git" {
+			cmd = exec.CommandContext(ctx, "git", gitArguments(words)...)
+			// git doesn't look for a repository above the jail
+			cmd.Env = append(os.Environ(), "GIT_CEILING_DIRECTORIES="+filepath.Dir(root), "GIT_PAGER=cat")
+		}
+		cmd.Dir = dir
+		output := helpers.NewCappedBuffer(maxOutput)
+		cmd.Stdout = output
+		cmd.Stderr = output
+
+		err = cmd.Run()
+		result := shellResult{Output: output.String(), Truncated: output.Truncated()}
+		if ctx.Err() == context.DeadlineExceeded {
+			result.TimedOut = true
+			result.ExitCode = -1
+			return result, nil
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			result.ExitCode = exitErr.ExitCode()
+			return result, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	return builtinTool{definition: definition, run: run}, nil
+}
+
+// checkShellOptions rejects the forbidden options of a command (see forbiddenShellOptions and gitSubcommands)
+func checkShellOptions(words []string) error {
+	if words[0] == "git" {
+		return checkGitCommand(words)
+	}
+	forbidden := forbiddenShellOptions[words[0]]
+	for _, word := range words[1:] {
+		name, _, _ := strings.Cut(word, "=")
+		if slices.Contains(forbidden, name) {
+			return fmt.Errorf("the option %s of %s is not allowed", name, words[0])
+		}
+	}
+	return nil
+}
+
+// checkGitCommand accepts the read-only git subcommands and their allowed options only (see gitSubcommands):
+// no global option, and the values of the options are separate words or follow "="
+func checkGitCommand(words []string) error {
+	if len(words) < 2 {
+		return errors.New("a git subcommand is required")
+	}
+	allowed, ok := gitSubcommands[words[1]]
+	if !ok {
+		return fmt.Errorf("the git subcommand %s is not allowed (allowed: %s)", words[1], strings.Join(slices.Sorted(maps.Keys(gitSubcommands)), ", "))
+	}
+	for _, word := range words[2:] {
+		if word == "--" {
+			break
+		}
+		if !strings.HasPrefix(word, "-") || isCountOption(word) {
+			continue
+		}
+		name, _, _ := strings.Cut(word, "=")
+		if !slices.Contains(allowed, name) || (!strings.HasPrefix(name, "--") && name != word) {
+			return fmt.Errorf("the option %s of git %s is not allowed", word, words[1])
+		}
+	}
+	return nil
+}
+
+// isCountOption reports whether an option is a number of commits (git log -5)
+func isCountOption(word string) bool {
+	return len(word) > 1 && strings.Trim(word[1:], "0123456789") == ""
+}
+
+// gitArguments returns the arguments of a checked git command with the gitSafetyArgs
+func gitArguments(words []string) []string {
+	arguments := []string{"--no-pager", "-c", "core.fsmonitor=false", words[1]}
+	arguments = append(arguments, gitSafetyArgs[words[1]]...)
+	return append(arguments, words[2:]...)
+}
+
+// argumentPath returns the part of a command argument that may be a path (the value of --name=value options)
+func argumentPath(word string) string {
+	if strings.HasPrefix(word, "-") {
+		_, value, _ := strings.Cut(word, "=")
+		return value
+	}
+	return word
+}
+
+// splitCommandLine splits a command line into words, handling the single and double quotes and the backslash escapes
+func splitCommandLine(line string) ([]string, error) {
+	words := []string{}
+	var current strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, errors.New("unterminated quote or escape in the command")
+	}
+	if inWord {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
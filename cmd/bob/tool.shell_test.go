@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestShellToolDefaultsExcludeFindAndGit(t *testing.T) {
+	tool, err := newShellTool(ShellConfig{WorkingDir: t.TempDir()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range []string{"find . -name x", "git status"} {
+		if _, err := tool.run(shellArguments(command)); err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("%s: expected the command to be rejected, got %v", command, err)
+		}
+	}
+}
+
+func TestShellToolRejectsEscapingOptions(t *testing.T) {
+	tool, err := newShellTool(ShellConfig{WorkingDir: t.TempDir(), AllowedCommands: []string{"find", "git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	escapes := []string{
+		`find . -exec sh -c 'touch /tmp/escaped' ;`,
+		`find . -execdir sh -c id ;`,
+		`find . -ok rm {} ;`,
+		`find . -okdir rm {} ;`,
+		`find . -delete`,
+		`find . -fprint out.txt`,
+		`find . -fprintf out.txt %p`,
+		`find . -fls out.txt`,
+		`git -c alias.x='!sh -c id' x`,
+		`git -calias.x=!id x`,
+		`git --config-env=core.pager=PAGER log`,
+		`git -C .. status`,
+		`git --git-dir=.git status`,
+		`git --work-tree=. status`,
+		`git ls-remote --upload-pack='sh -c id' .`,
+		`git fetch --upload-pack=id .`,
+		`git rebase --exec id`,
+		`git --exec-path=. status`,
+		`git grep --open-files-in-pager=id x`,
+		`git grep -Oid x`,
+		`git grep -O id x`,
+		`git clone -u 'sh -c id' . out`,
+		`git difftool -x id`,
+		`git difftool --extcmd=id`,
+		`git bisect run id`,
+		`git submodule foreach id`,
+		`git log --output=out.txt`,
+		`git log --ext-diff -p`,
+		`git diff --output out.txt`,
+		`git show --show-signature`,
+		`git log -n5`,
+	}
+	for _, command := range escapes {
+		if _, err := tool.run(shellArguments(command)); err == nil || !strings.Contains(err.Error(), "is not allowed") {
+			t.Errorf("%s: expected the option to be rejected, got %v", command, err)
+		}
+	}
+}
+
+func TestShellToolRunsAllowedCommand(t *testing.T) {
+	tool, err := newShellTool(ShellConfig{WorkingDir: t.TempDir(), AllowedCommands: []string{"find"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tool.run(shellArguments("find . -name '*.go'"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shell := result.(shellResult); shell.ExitCode != 0 {
+		t.Errorf("unexpected result %+v", shell)
+	}
+}
+
+func TestShellToolRunsReadOnlyGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repository := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=bob", "-c", "user.email=bob@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repository
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, output)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(repository, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, err := newShellTool(ShellConfig{WorkingDir: repository, AllowedCommands: []string{"git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, command := range []string{"git status --short", "git log -5 --oneline", "git show --stat HEAD", "git diff --stat -- .", "git rev-parse --show-toplevel"} {
+		result, err := tool.run(shellArguments(command))
+		if err != nil {
+			t.Fatalf("%s: expected the command to run, got %v", command, err)
+		}
+		if shell := result.(shellResult); shell.ExitCode != 0 {
+			t.Errorf("%s: unexpected result %+v", command, shell)
+		}
+	}
+
+	// git doesn't use the repository of a directory above the jail
+	tool, err = newShellTool(ShellConfig{WorkingDir: filepath.Join(repository, "sub"), AllowedCommands: []string{"git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tool.run(shellArguments("git status"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shell := result.(shellResult); shell.ExitCode == 0 {
+		t.Errorf("git shouldn't find the repository above the jail, got %+v", shell)
+	}
+}
+
+func shellArguments(command string) string {
+	data, _ := json.Marshal(map[string]string{"command": command})
+	return string(data)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/guard"
+	"github.com/micro-agent/micro-agent-go/agent/tools"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// builtinTool is a tool executed by Bob itself, without MCP server
+type builtinTool struct {
+	definition openai.ChatCompletionToolUnionParam
+	run        func(arguments string) (any, error)
+	// preview (optional) describes the effect of the call before its confirmation, e.g. the diff of a write
+	preview func(arguments string) (string, error)
+}
+
+// toolDefinition creates the OpenAI definition of a built-in tool
+func toolDefinition(name, description string, properties map[string]any, required ...string) openai.ChatCompletionToolUnionParam {
+	if required == nil {
+		required = []string{}
+	}
+	return openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+		Name:        name,
+		Description: openai.String(description),
+		Parameters: shared.FunctionParameters{
+			"type":       "object",
+			"properties": properties,
+			"required":   required,
+		},
+	})
+}
+
+// stringProperty returns the JSON schema of a string parameter
+func stringProperty(description string) map[string]any {
+	return map[string]any{"type": "string", "description": description}
+}
+
+// toolbox gathers the tools of the MCP servers (optional) and the built-in tools
+type toolbox struct {
+	mcpRouter *tools.MCPRouter
+	builtins  map[string]builtinTool
+	order     []string
+	tracer    *tracer      // optional, traces the tool calls with their timings
+	guard     *guard.Guard // optional, checks the tool results for prompt injections
+}
+
+// newToolbox creates a toolbox with the tools of the MCP servers (nil if there is no MCP server)
+func newToolbox(mcpRouter *tools.MCPRouter) *toolbox {
+	return &toolbox{
+		mcpRouter: mcpRouter,
+		builtins:  map[string]builtinTool{},
+	}
+}
+
+// register adds a built-in tool, it replaces an MCP tool with the same name
+func (t *toolbox) register(tool builtinTool) {
+	name := tool.definition.GetFunction().Name
+	if _, exists := t.builtins[name]; !exists {
+		t.order = append(t.order, name)
+	}
+	t.builtins[name] = tool
+}
+
+// openAITools returns the definitions of the MCP tools and of the built-in tools
+func (t *toolbox) openAITools() []openai.ChatCompletionToolUnionParam {
+	definitions := []openai.ChatCompletionToolUnionParam{}
+	if t.mcpRouter != nil {
+		for _, tool := range t.mcpRouter.OpenAITools() {
+			if _, overridden := t.builtins[tool.GetFunction().Name]; !overridden {
+				definitions = append(definitions, tool)
+			}
+		}
+	}
+	for _, name := range t.order {
+		definitions = append(definitions, t.builtins[name].definition)
+	}
+	return definitions
+}
+
+// preview returns the preview of a tool call, ok is false if the tool has no preview
+func (t *toolbox) preview(functionName string, arguments string) (string, bool, error) {
+	tool, ok := t.builtins[functionName]
+	if !ok || tool.preview == nil {
+		return "", false, nil
+	}
+	preview, err := tool.preview(arguments)
+	return preview, true, err
+}
+
+// call executes a tool and returns its result as a JSON string
+func (t *toolbox) call(functionName string, arguments string) (string, error) {
+	start := time.Now()
+	result, err := t.execute(functionName, arguments)
+	t.tracer.toolCall(functionName, arguments, time.Since(start), err)
+	if err != nil || t.guard == nil {
+		return result, err
+	}
+	return t.guard.CheckToolResult(functionName, result), nil
+}
+
+// execute runs a built-in tool, or calls the MCP tool
+func (t *toolbox) execute(functionName string, arguments string) (string, error) {
+	if tool, ok := t.builtins[functionName]; ok {
+		result, err := tool.run(arguments)
+		if err != nil {
+			ui.GetLogger().Error("built-in tool execution failed", "function", functionName, "error", err)
+			return "", fmt.Errorf("%s execution failed: %w", functionName, err)
+		}
+		data, err := json.Marshal(map[string]any{"result": result})
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+	return callTool(t.mcpRouter, functionName, arguments)
+}
+
+// callTool executes a tool with the MCP server of the tool and returns its result as a JSON string
+func callTool(mcpRouter *tools.MCPRouter, functionName string, arguments string) (string, error) {
+	// If MCP client is available, use it to execute the tool
+	if mcpRouter == nil {
+		return `{"result": "Function not executed"}`, nil
+	}
+	ctx := context.Background()
+	result, err := mcpRouter.CallTool(ctx, functionName, arguments)
+	if err != nil {
+		ui.GetLogger().Error("MCP tool execution failed", "function", functionName, "error", err)
+		return "", fmt.Errorf("MCP tool execution failed: %v", err)
+	}
+	// Convert MCP result to JSON string
+	if len(result.Content) > 0 {
+		// Take the first content item and return its text
+		resultContent := result.Content[0].(mcp.TextContent).Text
+		return fmt.Sprintf(`{"result": "%s"}`, resultContent), nil
+	}
+	return `{"result": "Tool executed successfully but returned no content"}`, nil
+}
+
+// jailRoot returns the absolute path of the jail directory (the current directory if dir is empty)
+func jailRoot(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(root)
+}
+
+// jailPath resolves path relative to base and returns an error if the result is outside root
+func jailPath(root, base, path string) (string, error) {
+	if path == "" {
+		return base, nil
+	}
+	resolved := path
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(base, resolved)
+	}
+	resolved, err := resolveExisting(filepath.Clean(resolved))
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("the path %q is outside the project directory %s", path, root)
+	}
+	return resolved, nil
+}
+
+// resolveExisting follows the symbolic links of the deepest existing ancestor of path and joins the
+// missing components to it (a dangling symbolic link is an error: it could point anywhere once created)
+func resolveExisting(path string) (string, error) {
+	existing, missing := path, ""
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		missing = filepath.Join(filepath.Base(existing), missing)
+		existing = parent
+	}
+	real, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve %s: %w", existing, err)
+	}
+	return filepath.Join(real, missing), nil
+}
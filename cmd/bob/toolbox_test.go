@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJailPath(t *testing.T) {
+	root, err := jailRoot(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "src"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "missing"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"", true},
+		{"src", true},
+		{"src/new/file.txt", true},
+		{"new/dir/file.txt", true},
+		{"../file.txt", false},
+		{"src/../../file.txt", false},
+		{outside, false},
+		{"link", false},
+		{"link/file.txt", false},
+		{"link/sub/dir/file.txt", false},
+		{"dangling", false},
+		{"dangling/file.txt", false},
+	}
+	for _, test := range tests {
+		_, err := jailPath(root, root, test.path)
+		if allowed := err == nil; allowed != test.allowed {
+			t.Errorf("jailPath(%q): allowed = %v, want %v (%v)", test.path, allowed, test.allowed, err)
+		}
+	}
+}
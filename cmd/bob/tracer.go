@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/logging"
+
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Trace levels of --verbose and -vv
+const (
+	traceOff      = 0
+	traceRequests = 1 // requests, responses summaries, retries and tool calls with their timings
+	tracePayloads = 2 // the sanitized payloads too
+)
+
+// tracer prints the requests to the provider and the tool calls to the standard error or to a trace file,
+// to debug e.g. why a local model doesn't call the tools
+type tracer struct {
+	level     int
+	out       io.Writer
+	file      *os.File
+	mutex     sync.Mutex
+	maxString int // the longer strings of the payloads are truncated
+}
+
+// newTracer creates a tracer writing to the file if path is not empty, to the standard error otherwise
+func newTracer(level int, path string) (*tracer, error) {
+	t := &tracer{level: level, out: os.Stderr, maxString: 500}
+	if path != "" {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, err
+		}
+		t.file = file
+		t.out = file
+	}
+	return t, nil
+}
+
+// Close closes the trace file
+func (t *tracer) Close() error {
+	if t == nil || t.file == nil {
+		return nil
+	}
+	return t.file.Close()
+}
+
+// enabled returns true if the events of the level are traced (the tracer can be nil)
+func (t *tracer) enabled(level int) bool {
+	return t != nil && t.level >= level
+}
+
+func (t *tracer) printf(format string, args ...any) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	fmt.Fprintf(t.out, "[trace %s] %s\n", time.Now().Format("15:04:05.000"), fmt.Sprintf(format, args...))
+}
+
+// toolCall traces a tool execution with its duration
+func (t *tracer) toolCall(name, arguments string, duration time.Duration, err error) {
+	if !t.enabled(traceRequests) {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = "error: " + err.Error()
+	}
+	t.printf("🛠️  tool %s %s in %s", name, status, duration.Round(time.Millisecond))
+	if t.enabled(tracePayloads) {
+		t.printf("   arguments: %s", logging.RedactJSON([]byte(arguments), t.maxString))
+	}
+}
+
+// middleware traces the requests of the OpenAI client, their retries and their responses
+func (t *tracer) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		body = data
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if retry := req.Header.Get("X-Stainless-Retry-Count"); retry != "" && retry != "0" {
+		t.printf("🔁 retry #%s of %s %s", retry, req.Method, req.URL.Path)
+	}
+	var request struct {
+		Model    string            `json:"model"`
+		Stream   bool              `json:"stream"`
+		Messages []json.RawMessage `json:"messages"`
+		Tools    []json.RawMessage `json:"tools"`
+	}
+	json.Unmarshal(body, &request)
+	t.printf("→ %s %s model=%s stream=%t messages=%d tools=%d", req.Method, req.URL.Path, request.Model, request.Stream, len(request.Messages), len(request.Tools))
+	if t.enabled(tracePayloads) {
+		// the SDK headers are noise, the retries are traced above
+		for _, header := range logging.RedactHeaders(req.Header) {
+			t.printf("   %s", header)
+		}
+		if len(body) > 0 {
+			t.printf("   request: %s", logging.RedactJSON(body, t.maxString))
+		}
+	}
+
+	start := time.Now()
+	resp, err := next(req)
+	if err != nil {
+		t.printf("← %s %s failed after %s: %v", req.Method, req.URL.Path, time.Since(start).Round(time.Millisecond), err)
+		return resp, err
+	}
+	resp.Body = &tracedBody{ReadCloser: resp.Body, tracer: t, resp: resp, start: start, stream: request.Stream}
+	return resp, nil
+}
+
+// tracedBody traces the response when it has been consumed by the client
+type tracedBody struct {
+	io.ReadCloser
+	tracer *tracer
+	resp   *http.Response
+	start  time.Time
+	stream bool
+	data   bytes.Buffer
+	done   bool
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.data.Write(p[:n])
+	if err == io.EOF {
+		b.trace()
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	b.trace()
+	return b.ReadCloser.Close()
+}
+
+// trace prints the status, the duration, the finish reason and the tool calls of the response
+func (b *tracedBody) trace() {
+	if b.done {
+		return
+	}
+	b.done = true
+	t := b.tracer
+
+	var response struct {
+		Choices []struct {
+			FinishReason string `json:"finish_reason"`
+			Message      struct {
+				ToolCalls []struct {
+					Function struct {
+						Name string `json:"name"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+	summary := ""
+	if b.stream {
+		chunks := 0
+		scanner := bufio.NewScanner(bytes.NewReader(b.data.Bytes()))
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data:") {
+				chunks++
+			}
+		}
+		summary = fmt.Sprintf(" chunks=%d", chunks)
+	} else if json.Unmarshal(b.data.Bytes(), &response) == nil && len(response.Choices) > 0 {
+		toolCalls := []string{}
+		for _, toolCall := range response.Choices[0].Message.ToolCalls {
+			toolCalls = append(toolCalls, toolCall.Function.Name)
+		}
+		summary = fmt.Sprintf(" finish_reason=%s tool_calls=[%s]", response.Choices[0].FinishReason, strings.Join(toolCalls, ", "))
+	}
+	t.printf("← %s in %s%s", b.resp.Status, time.Since(b.start).Round(time.Millisecond), summary)
+	if t.enabled(tracePayloads) || b.resp.StatusCode >= 400 {
+		if b.stream {
+			t.printf("   response: %s", logging.Truncate(b.data.String(), 4*t.maxString))
+		} else {
+			t.printf("   response: %s", logging.RedactJSON(b.data.Bytes(), t.maxString))
+		}
+	}
+	b.data.Reset()
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// tokenUsage counts the requests and tokens of a model
+type tokenUsage struct {
+	Requests         int
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// usageTracker counts the tokens reported by the provider in the completion responses, per model.
+// It is an HTTP middleware of the OpenAI client, so the streamed completions are counted too
+// (the usage of the stream is requested with stream_options.include_usage).
+type usageTracker struct {
+	mutex   sync.Mutex
+	models  map[string]*tokenUsage
+	order   []string
+	pricing map[string]ModelPricing
+	// onUsage (optional) is called after each completion with its cost
+	onUsage func(model string, usage tokenUsage, cost float64)
+}
+
+// newUsageTracker creates a tracker estimating the cost with the known prices and the overrides
+func newUsageTracker(pricing map[string]ModelPricing) *usageTracker {
+	return &usageTracker{
+		models:  map[string]*tokenUsage{},
+		pricing: pricing,
+	}
+}
+
+// add counts the tokens of a completion
+func (t *usageTracker) add(model string, promptTokens, completionTokens int64) {
+	t.mutex.Lock()
+	usage, ok := t.models[model]
+	if !ok {
+		usage = &tokenUsage{}
+		t.models[model] = usage
+		t.order = append(t.order, model)
+	}
+	usage.Requests++
+	usage.PromptTokens += promptTokens
+	usage.CompletionTokens += completionTokens
+	onUsage := t.onUsage
+	t.mutex.Unlock()
+
+	if onUsage != nil {
+		completion := tokenUsage{Requests: 1, PromptTokens: promptTokens, CompletionTokens: completionTokens}
+		cost, _ := t.cost(model, completion)
+		onUsage(model, completion, cost)
+	}
+}
+
+// cost estimates the cost of the usage of a model, ok is false if its price is unknown
+func (t *usageTracker) cost(model string, usage tokenUsage) (float64, bool) {
+	pricing, ok := modelPrice(model, t.pricing)
+	if !ok {
+		return 0, false
+	}
+	return (float64(usage.PromptTokens)*pricing.Input + float64(usage.CompletionTokens)*pricing.Output) / 1e6, true
+}
+
+// turnSummary returns the line of the usage of a turn reported by the agent, with its estimated cost
+func (t *usageTracker) turnSummary(model string, usage mu.Usage) string {
+	if usage.Requests == 0 {
+		return "🪙 No token usage reported by the provider"
+	}
+	line := fmt.Sprintf("🪙 %d prompt + %d completion tokens (%d requests)", usage.PromptTokens, usage.CompletionTokens, usage.Requests)
+	if cost, ok := t.cost(model, tokenUsage{Requests: usage.Requests, PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens}); ok {
+		line += fmt.Sprintf(", $%.4f", cost)
+	}
+	return line
+}
+
+// summary returns the table of the usage per model with the totals
+func (t *usageTracker) summary() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if len(t.order) == 0 {
+		return "No token usage reported by the provider\n"
+	}
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%-40s %8s %12s %12s %10s\n", "Model", "Requests", "Prompt", "Completion", "Cost")
+	total := tokenUsage{}
+	totalCost, priced := 0.0, false
+	for _, model := range t.order {
+		usage := *t.models[model]
+		costText := "-"
+		if cost, ok := t.cost(model, usage); ok {
+			costText = fmt.Sprintf("$%.4f", cost)
+			totalCost += cost
+			priced = true
+		}
+		fmt.Fprintf(&builder, "%-40s %8d %12d %12d %10s\n", model, usage.Requests, usage.PromptTokens, usage.CompletionTokens, costText)
+		total.Requests += usage.Requests
+		total.PromptTokens += usage.PromptTokens
+		total.CompletionTokens += usage.CompletionTokens
+	}
+	totalCostText := "-"
+	if priced {
+		totalCostText = fmt.Sprintf("$%.4f", totalCost)
+	}
+	fmt.Fprintf(&builder, "%-40s %8d %12d %12d %10s\n", "Total", total.Requests, total.PromptTokens, total.CompletionTokens, totalCostText)
+	return builder.String()
+}
+
+// completionUsage is the usage object of the completion responses and of the last chunk of the streams
+type completionUsage struct {
+	Model string `json:"model"`
+	Usage *struct {
+		PromptTokens     int64 `json:"prompt_tokens"`
+		CompletionTokens int64 `json:"completion_tokens"`
+	} `json:"usage"`
+}
+
+// middleware requests the usage of the streamed completions and reads the usage of the responses
+func (t *usageTracker) middleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	if req.Body == nil || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return next(req)
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	var payload map[string]any
+	model, stream := "", false
+	if json.Unmarshal(body, &payload) == nil {
+		model, _ = payload["model"].(string)
+		stream, _ = payload["stream"].(bool)
+		if _, set := payload["stream_options"]; stream && !set {
+			payload["stream_options"] = map[string]any{"include_usage": true}
+			if data, err := json.Marshal(payload); err == nil {
+				body = data
+			}
+		}
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	resp, err := next(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	resp.Body = &usageReader{ReadCloser: resp.Body, tracker: t, model: model, stream: stream}
+	return resp, nil
+}
+
+// usageReader reads the usage of a response while it is consumed by the client
+type usageReader struct {
+	io.ReadCloser
+	tracker *usageTracker
+	model   string
+	stream  bool
+	data    bytes.Buffer
+	counted bool
+}
+
+func (r *usageReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.data.Write(p[:n])
+	if err == io.EOF {
+		r.count()
+	}
+	return n, err
+}
+
+func (r *usageReader) Close() error {
+	r.count()
+	return r.ReadCloser.Close()
+}
+
+// count parses the usage of the response (the JSON body, or the data lines of the stream) once
+func (r *usageReader) count() {
+	if r.counted {
+		return
+	}
+	r.counted = true
+
+	var usage *completionUsage
+	if !r.stream {
+		var response completionUsage
+		if json.Unmarshal(r.data.Bytes(), &response) == nil && response.Usage != nil {
+			usage = &response
+		}
+	} else {
+		scanner := bufio.NewScanner(&r.data)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line, found := strings.CutPrefix(scanner.Text(), "data:")
+			if !found || !strings.Contains(line, `"usage"`) {
+				continue
+			}
+			var chunk completionUsage
+			if json.Unmarshal([]byte(strings.TrimSpace(line)), &chunk) == nil && chunk.Usage != nil {
+				usage = &chunk
+			}
+		}
+	}
+	r.data.Reset()
+	if usage == nil {
+		return
+	}
+	model := r.model
+	if model == "" {
+		model = usage.Model
+	}
+	r.tracker.add(model, usage.Usage.PromptTokens, usage.Usage.CompletionTokens)
+}
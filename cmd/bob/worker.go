@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/queue"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
+
+	"github.com/openai/openai-go/v2"
+)
+
+// runWorker parses the flags of `bob worker` and processes the tasks of a NATS subject until interrupted,
+// it returns the exit code
+func runWorker(args []string, b *backend) int {
+	flags := flag.NewFlagSet("worker", flag.ContinueOnError)
+	natsURL := flags.String("nats", os.Getenv("NATS_URL"), "URL of the NATS server (nats://localhost:4222 if empty)")
+	subject := flags.String("subject", queue.DefaultTaskSubject, "subject of the tasks")
+	results := flags.String("results", queue.DefaultResultSubject, "subject of the results of the tasks without reply subject")
+	group := flags.String("group", "bob-workers", "queue group of the workers sharing the tasks")
+	concurrency := flags.Int("concurrency", 1, "number of tasks processed at the same time")
+	name := flags.String("name", "", "name of the worker in the results (<hostname>-<pid> if empty)")
+	approveTools := flags.Bool("approve-tools", false, "execute the tool calls with the ask policy (nobody can confirm them)")
+	if err := flags.Parse(args); err != nil {
+		return exitUsageError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	tasks, err := queue.DialNATS(ctx, *natsURL, queue.WithNATSName("bob"), queue.WithNATSLogger(ui.GetLogger()))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitFailure
+	}
+	defer tasks.Close()
+	if *natsURL == "" {
+		*natsURL = queue.DefaultNATSURL
+	}
+
+	// The tasks are concurrent: the sub-agents use the worker policies and the default model
+	b.delegation.bindUnattended(b.delegation.ctx, b.config, *approveTools)
+	executeFn := func(functionName string, arguments string) (string, error) {
+		if !approvedUnattended(b.config, functionName, *approveTools) {
+			ui.GetLogger().Info("tool call refused", "function", functionName)
+			return `{"result": "Function not executed"}`, nil
+		}
+		ui.GetLogger().Info("tool call", "function", functionName, "arguments", arguments)
+		return b.toolbox.call(functionName, arguments)
+	}
+	newAgent := func(ctx context.Context) (mu.Agent, error) {
+		agent, err := b.newAgent(b.model)
+		if err != nil {
+			return nil, err
+		}
+		agent.AddMessage(openai.SystemMessage(b.systemMessage))
+		return agent, nil
+	}
+
+	options := []queue.WorkerOption{
+		queue.WithTaskSubject(*subject),
+		queue.WithResultSubject(*results),
+		queue.WithGroup(*group),
+		queue.WithConcurrency(*concurrency),
+		queue.WithToolCallback(executeFn),
+		queue.WithWorkerLogger(ui.GetLogger()),
+	}
+	if *name != "" {
+		options = append(options, queue.WithWorkerName(*name))
+	}
+	worker := queue.NewWorker(tasks, newAgent, options...)
+
+	ui.Printf(ui.GetTheme().Info, "👷 Bob is processing the tasks of %s (group %s) on %s\n", *subject, *group, *natsURL)
+	if err := worker.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintln(os.Stderr, "worker error:", err)
+		return exitFailure
+	}
+	return exitSuccess
+}
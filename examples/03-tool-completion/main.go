@@ -2,19 +2,24 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/tools"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
-	"github.com/openai/openai-go/v2/shared"
 )
 
 func main() {
 
 	ctx := context.Background()
 
+	// The JSON schemas of the tools are generated from the argument structs
+	registry, err := GetToolsRegistry()
+	if err != nil {
+		panic(err)
+	}
+
 	client := openai.NewClient(
 		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
 		option.WithAPIKey(""),
@@ -28,7 +33,7 @@ func main() {
 			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
 				OfAuto: openai.String("auto"),
 			},
-			Tools:             GetToolsIndex(),
+			Tools:             registry.OpenAITools(),
 			ParallelToolCalls: openai.Opt(false),
 		}),
 	)
@@ -46,7 +51,7 @@ func main() {
 		`),
 	}
 
-	finishReason, results, assistantMessage, err := toolAgent.DetectToolCalls(messages, executeFunction)
+	finishReason, results, assistantMessage, err := toolAgent.DetectToolCalls(messages, registry.ToolCallback())
 	if err != nil {
 		panic(err)
 	}
@@ -56,87 +61,43 @@ func main() {
 
 }
 
-func GetToolsIndex() []openai.ChatCompletionToolUnionParam {
-	calculateSumTool := openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
-		Name:        "calculate_sum",
-		Description: openai.String("Calculate the sum of two numbers"),
-		Parameters: shared.FunctionParameters{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"a": map[string]string{
-					"type":        "number",
-					"description": "The first number",
-				},
-				"b": map[string]string{
-					"type":        "number",
-					"description": "The second number",
-				},
-			},
-			"required": []string{"a", "b"},
-		},
-	})
+// SumArgs are the arguments of calculate_sum
+type SumArgs struct {
+	A float64 `json:"a" description:"The first number"`
+	B float64 `json:"b" description:"The second number"`
+}
 
-	sayHelloTool := openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
-		Name:        "say_hello",
-		Description: openai.String("Say hello to the given name"),
-		Parameters: shared.FunctionParameters{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"name": map[string]string{
-					"type":        "string",
-					"description": "The name to greet",
-				},
-			},
-			"required": []string{"name"},
-		},
-	})
+// HelloArgs are the arguments of say_hello
+type HelloArgs struct {
+	Name string `json:"name" description:"The name to greet"`
+}
 
-	sayExit := openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
-		Name:        "say_exit",
-		Description: openai.String("Say exit"),
-		Parameters: shared.FunctionParameters{
-			"type":       "object",
-			"properties": map[string]interface{}{},
-		},
-	})
+func GetToolsRegistry() (*tools.Registry, error) {
+	registry := tools.NewRegistry()
 
-	return []openai.ChatCompletionToolUnionParam{
-		calculateSumTool,
-		sayHelloTool,
-		sayExit,
+	err := registry.Register("calculate_sum", "Calculate the sum of two numbers", func(args SumArgs) (map[string]float64, error) {
+		fmt.Printf("🟢 Executing function: calculate_sum with arguments: %+v\n", args)
+		return map[string]float64{"result": args.A + args.B}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-}
 
-func executeFunction(functionName string, arguments string) (string, error) {
-	fmt.Printf("🟢 Executing function: %s with arguments: %s\n", functionName, arguments)
-	switch functionName {
-	case "say_hello":
-		var args struct {
-			Name string `json:"name"`
-		}
-		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
-			return `{"error": "Invalid arguments for say_hello"}`, nil
-		}
-		hello := fmt.Sprintf("👋 Hello, %s!🙂", args.Name)
-		return fmt.Sprintf(`{"message": "%s"}`, hello), nil
-
-	case "calculate_sum":
-		var args struct {
-			A float64 `json:"a"`
-			B float64 `json:"b"`
-		}
-		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
-			return `{"error": "Invalid arguments for calculate_sum"}`, nil
-		}
-		sum := args.A + args.B
-		return fmt.Sprintf(`{"result": %g}`, sum), nil
-
-	case "say_exit":
+	err = registry.Register("say_hello", "Say hello to the given name", func(args HelloArgs) (map[string]string, error) {
+		fmt.Printf("🟢 Executing function: say_hello with arguments: %+v\n", args)
+		return map[string]string{"message": fmt.Sprintf("👋 Hello, %s!🙂", args.Name)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	err = registry.Register("say_exit", "Say exit", func(struct{}) (map[string]string, error) {
 		// NOTE: Returning a message and an ExitToolCallsLoopError to stop further processing
-		return fmt.Sprintf(`{"message": "%s"}`, "❌ EXIT"), &mu.ExitToolCallsLoopError{Message: "❌ EXIT"}
-
-	default:
-		return `{"error": "Unknown function"}`, &mu.ExitToolCallsLoopError{Message: fmt.Sprintf("Unknown function: %s", functionName)}
+		return map[string]string{"message": "❌ EXIT"}, &mu.ExitToolCallsLoopError{Message: "❌ EXIT"}
+	})
+	if err != nil {
+		return nil, err
 	}
+
+	return registry, nil
 }
@@ -50,30 +50,11 @@ func main() {
 		},
 	}
 
-	agentCallBack := func(taskRequest a2a.TaskRequest) (a2a.TaskResponse, error) {
-
+	// runAgent builds a task response from the agent completion
+	runAgent := func(taskRequest a2a.TaskRequest, systemMessage, userPrompt string) (a2a.TaskResponse, error) {
 		fmt.Printf("🟢 Processing task request: %s\n", taskRequest.ID)
-		// Extract user message
-		userMessage := taskRequest.Params.Message.Parts[0].Text
-		fmt.Printf("🔵 UserMessage: %s\n", userMessage)
 		fmt.Printf("🟡 TaskRequest Metadata: %v\n", taskRequest.Params.MetaData)
 
-		var systemMessage, userPrompt string
-
-		switch taskRequest.Params.MetaData["skill"] {
-		case "ask_for_something":
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = userMessage
-
-		case "greetings":
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = "Greetings to " + userMessage + " with emojis and use his name."
-
-		default:
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = "Be nice, and explain that " + fmt.Sprintf("%v", taskRequest.Params.MetaData["skill"]) + " is not a valid task ID."
-		}
-
 		answer, err := chatAgent.Run([]openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemMessage),
 			openai.UserMessage(userPrompt),
@@ -96,11 +77,8 @@ func main() {
 				History: []a2a.AgentMessage{
 					{
 						Role: "assistant",
-						Parts: []a2a.TextPart{
-							{
-								Text: answer,
-								Type: "text",
-							},
+						Parts: []a2a.Part{
+							a2a.NewTextPart(answer),
 						},
 					},
 				},
@@ -112,6 +90,27 @@ func main() {
 		return responseTask, nil
 	}
 
+	systemMessage := "You are Bob, a simple A2A agent. You can answer questions."
+
+	// Dispatch the task requests according to the "skill" metadata
+	router := a2a.NewSkillRouter(agentCard)
+
+	router.Handle("ask_for_something", func(taskRequest a2a.TaskRequest) (a2a.TaskResponse, error) {
+		userMessage := taskRequest.Params.Message.Parts[0].Text
+		return runAgent(taskRequest, systemMessage, userMessage)
+	})
+
+	router.Handle("greetings", func(taskRequest a2a.TaskRequest) (a2a.TaskResponse, error) {
+		userMessage := taskRequest.Params.Message.Parts[0].Text
+		return runAgent(taskRequest, systemMessage, "Greetings to "+userMessage+" with emojis and use his name.")
+	})
+
+	router.HandleDefault(func(taskRequest a2a.TaskRequest) (a2a.TaskResponse, error) {
+		return runAgent(taskRequest, systemMessage, "Be nice, and explain that "+a2a.GetSkill(taskRequest)+" is not a valid task ID.")
+	})
+
+	agentCallBack := router.Dispatch
+
 	a2aServer := a2a.NewA2AServer(7777, agentCard, agentCallBack)
 	fmt.Println("🚀 Starting A2A server on port 7777...")
 	if err := a2aServer.Start(); err != nil {
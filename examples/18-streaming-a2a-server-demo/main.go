@@ -50,31 +50,11 @@ func main() {
 		},
 	}
 
-	// Streaming callback (for /stream endpoint)
-	agentStreamCallback := func(taskRequest a2a.TaskRequest, streamFunc func(content string) error) error {
-
+	// runAgentStream streams the agent completion to the client
+	runAgentStream := func(taskRequest a2a.TaskRequest, streamFunc func(content string) error, systemMessage, userPrompt string) error {
 		fmt.Printf("🟢 Processing streaming task request: %s\n", taskRequest.ID)
-		// Extract user message
-		userMessage := taskRequest.Params.Message.Parts[0].Text
-		fmt.Printf("🔵 UserMessage: %s\n", userMessage)
 		fmt.Printf("🟡 TaskRequest Metadata: %v\n", taskRequest.Params.MetaData)
 
-		var systemMessage, userPrompt string
-
-		switch taskRequest.Params.MetaData["skill"] {
-		case "ask_for_something":
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = userMessage
-
-		case "greetings":
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = "Greetings to " + userMessage + " with emojis and use his name."
-
-		default:
-			systemMessage = "You are Bob, a simple A2A agent. You can answer questions."
-			userPrompt = "Be nice, and explain that " + fmt.Sprintf("%v", taskRequest.Params.MetaData["skill"]) + " is not a valid task ID."
-		}
-
 		// Use RunStream instead of Run for streaming
 		_, err := chatAgent.RunStream(
 			[]openai.ChatCompletionMessageParamUnion{
@@ -83,7 +63,7 @@ func main() {
 			},
 			func(content string) error {
 				if content != "" {
-					fmt.Print(content) // Print to console for debugging
+					fmt.Print(content)         // Print to console for debugging
 					return streamFunc(content) // Stream to client
 				}
 				return nil // Continue streaming
@@ -98,6 +78,28 @@ func main() {
 		return nil
 	}
 
+	systemMessage := "You are Bob, a simple A2A agent. You can answer questions."
+
+	// Dispatch the streaming task requests according to the "skill" metadata
+	router := a2a.NewSkillRouter(agentCard)
+
+	router.HandleStream("ask_for_something", func(taskRequest a2a.TaskRequest, streamFunc func(content string) error) error {
+		userMessage := taskRequest.Params.Message.Parts[0].Text
+		return runAgentStream(taskRequest, streamFunc, systemMessage, userMessage)
+	})
+
+	router.HandleStream("greetings", func(taskRequest a2a.TaskRequest, streamFunc func(content string) error) error {
+		userMessage := taskRequest.Params.Message.Parts[0].Text
+		return runAgentStream(taskRequest, streamFunc, systemMessage, "Greetings to "+userMessage+" with emojis and use his name.")
+	})
+
+	router.HandleDefaultStream(func(taskRequest a2a.TaskRequest, streamFunc func(content string) error) error {
+		return runAgentStream(taskRequest, streamFunc, systemMessage, "Be nice, and explain that "+a2a.GetSkill(taskRequest)+" is not a valid task ID.")
+	})
+
+	// Streaming callback (for /stream endpoint)
+	agentStreamCallback := router.DispatchStream
+
 	a2aServer := a2a.NewA2AServerWithStreaming(7777, agentCard, agentStreamCallback)
 	fmt.Println("🚀 Starting A2A server with streaming support on port 7777...")
 	if err := a2aServer.Start(); err != nil {
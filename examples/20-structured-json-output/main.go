@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 )
@@ -69,6 +70,7 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	println("Response:", response)
+	println("Response:")
+	ui.PrintJSON(response)
 
 }
@@ -2,12 +2,25 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/micro-agent/micro-agent-go/agent/mu"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 )
 
+// Country is the structure of the answer, its JSON schema is generated from the fields and their tags
+type Country struct {
+	Name      string   `json:"name"`
+	Capital   string   `json:"capital"`
+	Languages []string `json:"languages" description:"The official languages of the country"`
+}
+
+// Countries is a list of countries somewhere in the world
+type Countries struct {
+	Countries []Country `json:"countries"`
+}
+
 func main() {
 
 	ctx := context.Background()
@@ -17,65 +30,19 @@ func main() {
 		option.WithAPIKey(""),
 	)
 
-	// Get a list of countries somewhere in the world
-	schema := map[string]any{
-		"type": "object",
-		"properties": map[string]any{
-			"countries": map[string]any{
-				"type": "array",
-				"items": map[string]any{
-					"type": "object",
-					"properties": map[string]any{
-						"name": map[string]any{
-							"type": "string",
-						},
-						"capital": map[string]any{
-							"type": "string",
-						},
-						"languages": map[string]any{
-							"type": "array",
-							"items": map[string]any{
-								"type": "string",
-							},
-						},
-					},
-					"required": []string{"name", "capital", "languages"},
-				},
-			},
-		},
-		"required": []string{"countries"},
-	}
-
-	schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
-		Name:        "List of countries",
-		Description: openai.String("List of countries in the world"),
-		Schema:      schema,
-		Strict:      openai.Bool(true),
-	}
-
 	chatAgent, err := mu.NewAgent(ctx, "Bob",
 		mu.WithClient(client),
 		mu.WithParams(openai.ChatCompletionNewParams{
 			Model:       "ai/qwen2.5:1.5B-F16",
 			Temperature: openai.Opt(0.0),
-			// ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
-			// 	OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
-			// 		JSONSchema: schemaParam,
-			// 	},
-			// },
 		}),
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	chatAgent.SetResponseFormat(openai.ChatCompletionNewParamsResponseFormatUnion{
-		OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
-			JSONSchema: schemaParam,
-		},
-	})
-
-	response, err := chatAgent.Run([]openai.ChatCompletionMessageParamUnion{
+	// The response format is set from the struct, the answer is validated and decoded
+	countries, err := mu.RunStructured[Countries](chatAgent, []openai.ChatCompletionMessageParamUnion{
 		openai.SystemMessage(`
 		Your name is Bob. 
 		You are an assistant that answers questions about countries around the world.
@@ -85,6 +52,8 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	println("Response:", response)
+	for _, country := range countries.Countries {
+		fmt.Printf("%s: %s %v\n", country.Name, country.Capital, country.Languages)
+	}
 
 }
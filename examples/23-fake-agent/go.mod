@@ -8,6 +8,12 @@ replace github.com/micro-agent/micro-agent-go => ../../
 
 require github.com/micro-agent/micro-agent-go v0.0.0-00010101000000-000000000000
 
+require (
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
 require (
 	github.com/openai/openai-go/v2 v2.1.1
 	github.com/tidwall/gjson v1.14.4 // indirect
@@ -20,6 +20,18 @@ func main() {
 		option.WithAPIKey(""),
 	)
 
+	// The summarizer writes the summary of the oldest messages when the conversation grows too long
+	summarizer, err := mu.NewAgent(ctx, "Summarizer",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "ai/qwen2.5:1.5B-F16",
+			Temperature: openai.Opt(0.0),
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
 	chatAgent, err := mu.NewAgent(ctx, "Bob",
 		mu.WithClient(client),
 		mu.WithParams(openai.ChatCompletionNewParams{
@@ -29,6 +41,8 @@ func main() {
 				openai.SystemMessage("Your name is Bob. You are a helpful AI assistant."),
 			},
 		}),
+		// Beyond 10 messages, all but the last 4 are replaced by a summary
+		mu.WithMemoryStrategy(mu.NewSummaryMemory(summarizer, 10, 4)),
 	)
 	if err != nil {
 		panic(err)
@@ -8,6 +8,7 @@ import (
 	"github.com/micro-agent/micro-agent-go/agent/helpers"
 	"github.com/micro-agent/micro-agent-go/agent/mu"
 	"github.com/micro-agent/micro-agent-go/agent/rag"
+	"github.com/micro-agent/micro-agent-go/agent/ui"
 	"github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 )
@@ -44,8 +45,10 @@ func main() {
 
 	contextInstructionsContent, err := helpers.ReadTextFile("./sorcerer_background_and_personality.md")
 	chunks := rag.SplitMarkdownBySections(contextInstructionsContent)
+
+	progressBar := ui.NewProgressBar(ui.Cyan, "🔶 Embedding chunks", len(chunks))
+	progressBar.Start()
 	for idx, chunk := range chunks {
-		fmt.Println("🔶 Chunk", idx, ":", chunk)
 		embeddingVector, err := chatAgent.GenerateEmbeddingVector(chunk)
 		if err != nil {
 			panic(err)
@@ -58,9 +61,9 @@ func main() {
 		if errSave != nil {
 			fmt.Println("😡:", errSave)
 		}
-		fmt.Println("✅ Chunk", idx, "saved with embedding:", len(embeddingVector))
-
+		progressBar.Update(idx+1, len(chunks))
 	}
+	progressBar.Done()
 
 	fmt.Println("📝 Total records in the vector store:", len(store.Records))
 	//fmt.Println(store.Records)
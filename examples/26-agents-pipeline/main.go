@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro-agent/micro-agent-go/agent/flow"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func main() {
+
+	ctx := context.Background()
+	// Initialize OpenAI client
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	newAgent := func(name, systemMessage string) mu.Agent {
+		agent, err := mu.NewAgent(ctx, name,
+			mu.WithClient(client),
+			mu.WithParams(openai.ChatCompletionNewParams{
+				Model:       "ai/qwen2.5:1.5B-F16",
+				Temperature: openai.Opt(0.0),
+				Messages: []openai.ChatCompletionMessageParamUnion{
+					openai.SystemMessage(systemMessage),
+				},
+			}),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return agent
+	}
+
+	researcher := newAgent("Researcher", "You are a researcher. List 5 key facts about the topic, one per line.")
+	writer := newAgent("Writer", "You are a writer. Write a short paragraph from the facts you are given.")
+	reviewer := newAgent("Reviewer", "You are a reviewer. Fix the grammar and the style of the text, answer with the text only.")
+
+	pipeline := flow.NewPipeline(
+		flow.WithSteps(
+			flow.Step{Agent: researcher},
+			flow.Step{
+				Agent: writer,
+				Input: func(facts string) string { return "Facts:\n" + facts },
+			},
+			flow.Step{Agent: reviewer, OnError: flow.SkipOnError},
+		),
+		flow.WithOnStepStart(func(index int, name string, input string) {
+			fmt.Printf("▶ step %d: %s\n", index+1, name)
+		}),
+		flow.WithOnStepEnd(func(result flow.StepResult) error {
+			fmt.Printf("✅ %s done in %s\n", result.Name, result.Duration)
+			return nil
+		}),
+	)
+
+	article, _, err := pipeline.Run("The Go programming language")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println(article)
+}
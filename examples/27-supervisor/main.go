@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro-agent/micro-agent-go/agent/flow"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func main() {
+
+	ctx := context.Background()
+	// Initialize OpenAI client
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+	model := "ai/qwen2.5:latest"
+
+	newWorker := func(name, systemMessage string) mu.Agent {
+		agent, err := mu.NewAgent(ctx, name,
+			mu.WithClient(client),
+			mu.WithParams(openai.ChatCompletionNewParams{
+				Model:       model,
+				Temperature: openai.Opt(0.0),
+				Messages: []openai.ChatCompletionMessageParamUnion{
+					openai.SystemMessage(systemMessage),
+				},
+			}),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return agent
+	}
+
+	supervisor, err := flow.NewSupervisor(ctx, "Boss",
+		flow.WithSupervisorAgent(
+			mu.WithClient(client),
+			mu.WithParams(openai.ChatCompletionNewParams{
+				Model:       model,
+				Temperature: openai.Opt(0.0),
+			}),
+		),
+		flow.WithWorkers(
+			flow.Worker{
+				Name:   "coder",
+				Skills: "writes Go functions",
+				Agent:  newWorker("Coder", "You are a Go developer. Answer with Go code only."),
+			},
+			flow.Worker{
+				Name:   "tester",
+				Skills: "writes Go unit tests for a given function",
+				Agent:  newWorker("Tester", "You are a Go tester. Answer with Go test code only."),
+			},
+		),
+		flow.WithMaxDepth(3),
+		flow.WithOnDelegate(func(worker string, task string) {
+			fmt.Printf("➡️  %s: %s\n", worker, task)
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	answer, delegations, err := supervisor.Run("Write a Go function reversing a string, and its unit tests")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("\n%d delegations\n\n%s\n", len(delegations), answer)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/workflow"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func main() {
+
+	ctx := context.Background()
+	// Initialize OpenAI client
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	newAgent := func(name, systemMessage string) mu.Agent {
+		agent, err := mu.NewAgent(ctx, name,
+			mu.WithClient(client),
+			mu.WithParams(openai.ChatCompletionNewParams{
+				Model:       "ai/qwen2.5:1.5B-F16",
+				Temperature: openai.Opt(0.0),
+				Messages: []openai.ChatCompletionMessageParamUnion{
+					openai.SystemMessage(systemMessage),
+				},
+			}),
+		)
+		if err != nil {
+			panic(err)
+		}
+		return agent
+	}
+
+	writer := newAgent("Writer", "You are a poet. Answer with the poem only.")
+	judge := newAgent("Judge", "You are a strict poetry critic.")
+
+	graph := workflow.NewGraph(
+		workflow.WithTracer(func(event workflow.TraceEvent) {
+			fmt.Printf("[%d] %s (%s) → %s %s\n", event.Step, event.Node, event.Duration, event.Next, event.Edge)
+		}),
+	)
+	graph.AddAgentNode("write", writer, func(state *workflow.State) string {
+		if feedback, ok := state.Values["feedback"].(string); ok {
+			return state.Input + "\nImprove this version: " + feedback
+		}
+		return state.Input
+	})
+	graph.AddFuncNode("format", func(state *workflow.State) (string, error) {
+		poem := strings.TrimSpace(state.Last)
+		state.Values["feedback"] = poem
+		return poem, nil
+	})
+	graph.AddEdge(workflow.Edge{From: "write", To: "format"})
+	graph.AddEdge(workflow.Edge{
+		From:      "format",
+		To:        workflow.End,
+		Label:     "approved",
+		Condition: workflow.LLMCondition(judge, "Is this a haiku (3 lines of 5, 7 and 5 syllables)?"),
+	})
+	// Rewrite at most 2 times
+	graph.AddEdge(workflow.Edge{From: "format", To: "write", Label: "rewrite", MaxTraversals: 2})
+
+	state, _, err := graph.Run("Write a haiku about the Go gopher")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println(state.Last)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/micro-agent/micro-agent-go/agent/eval"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func main() {
+
+	ctx := context.Background()
+	// Initialize OpenAI client
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	chatAgent, err := mu.NewAgent(ctx, "Bob",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "ai/qwen2.5:1.5B-F16",
+			Temperature: openai.Opt(0.0),
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	judge, err := mu.NewAgent(ctx, "Judge",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "ai/qwen2.5:1.5B-F16",
+			Temperature: openai.Opt(0.0),
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+	embedder, err := mu.NewAgent(ctx, "Embedder",
+		mu.WithClient(client),
+		mu.WithEmbeddingParams(openai.EmbeddingNewParams{
+			Model: "ai/mxbai-embed-large",
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	cases, err := eval.LoadCases("cases.json")
+	if err != nil {
+		panic(err)
+	}
+	suite := eval.NewSuite("assistant",
+		eval.WithCases(cases...),
+		eval.WithSystemMessage("You are a helpful and concise assistant."),
+		eval.WithEmbedder(embedder),
+		eval.WithJudge(judge),
+		eval.WithThreshold(0.7),
+		eval.WithOnCase(func(result eval.CaseResult) {
+			fmt.Printf("%s passed=%t in %s\n", result.Name, result.Passed, result.Duration)
+		}),
+	)
+
+	report, err := suite.Run(chatAgent)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println(report.Markdown())
+
+	// Compare with the previous run
+	baseline, err := eval.LoadReport("report.json")
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		fmt.Println("No baseline yet")
+	case err != nil:
+		panic(err)
+	default:
+		if regressions := report.Regressions(baseline); len(regressions) > 0 {
+			fmt.Println("❌ Regressions:", regressions)
+		} else {
+			fmt.Println("✅ No regression")
+		}
+	}
+	if err := report.Save("report.json"); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,21 @@
+module gemini
+
+go 1.24.4
+
+require (
+	github.com/micro-agent/micro-agent-go v0.1.1
+	github.com/openai/openai-go/v2 v2.1.1
+)
+
+replace github.com/micro-agent/micro-agent-go => ../..
+
+require (
+	github.com/google/uuid v1.6.0 // indirect
+	github.com/tidwall/gjson v1.14.4 // indirect
+	github.com/tidwall/match v1.1.1 // indirect
+	github.com/tidwall/pretty v1.2.1 // indirect
+	github.com/tidwall/sjson v1.2.5 // indirect
+	golang.org/x/net v0.34.0 // indirect
+	golang.org/x/text v0.29.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/micro-agent/micro-agent-go/agent/providers/gemini"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+func main() {
+
+	ctx := context.Background()
+
+	// The API key is read from GEMINI_API_KEY
+	toolAgent, err := gemini.NewAgent(ctx, "Bob",
+		gemini.WithParams(openai.ChatCompletionNewParams{
+			Model:       "gemini-2.5-flash",
+			Temperature: openai.Opt(0.0),
+			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
+				OfAuto: openai.String("auto"),
+			},
+			Tools: GetToolsIndex(),
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.SystemMessage("You are a helpful assistant, use the tools when you need them."),
+		openai.UserMessage("Make the sum of 40 and 2, then make the sum of 5 and 37."),
+	}
+
+	fmt.Println("🚀 Starting streaming tool completion with Gemini...")
+	fmt.Println(strings.Repeat("=", 50))
+
+	finishReason, results, assistantMessage, err := toolAgent.DetectToolCallsStream(messages, executeFunction, func(content string) error {
+		fmt.Print(content)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 50))
+	fmt.Printf("Finish Reason: %s\n", finishReason)
+	fmt.Printf("Tool Results: %v\n", results)
+	fmt.Printf("Final Assistant Message: %s\n", assistantMessage)
+
+	// The conversation is kept as OpenAI messages: it continues like with any agent
+	answer, err := toolAgent.RunStream([]openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("What is the sum of the two results?"),
+	}, func(content string) error {
+		fmt.Print(content)
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println("Messages in the conversation:", len(toolAgent.GetMessages()), "- last answer:", len(answer), "characters")
+}
+
+func GetToolsIndex() []openai.ChatCompletionToolUnionParam {
+	calculateSumTool := openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+		Name:        "calculate_sum",
+		Description: openai.String("Calculate the sum of two numbers"),
+		Parameters: shared.FunctionParameters{
+			"type": "object",
+			"properties": map[string]any{
+				"a": map[string]string{
+					"type":        "number",
+					"description": "The first number",
+				},
+				"b": map[string]string{
+					"type":        "number",
+					"description": "The second number",
+				},
+			},
+			"required": []string{"a", "b"},
+		},
+	})
+	return []openai.ChatCompletionToolUnionParam{calculateSumTool}
+}
+
+func executeFunction(functionName string, arguments string) (string, error) {
+	fmt.Printf("🟢 Executing function: %s with arguments: %s\n", functionName, arguments)
+	switch functionName {
+	case "calculate_sum":
+		var args struct {
+			A float64 `json:"a"`
+			B float64 `json:"b"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf(`{"result": %g}`, args.A+args.B), nil
+	default:
+		return "", fmt.Errorf("unknown function %s", functionName)
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/micro-agent/micro-agent-go/agent/scheduler"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+func main() {
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	// Initialize OpenAI client
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	writer, err := mu.NewAgent(ctx, "Writer",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "ai/qwen2.5:1.5B-F16",
+			Temperature: openai.Opt(0.8),
+			Messages: []openai.ChatCompletionMessageParamUnion{
+				openai.SystemMessage("You are a poet, you write very short poems."),
+			},
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	tasks := scheduler.NewScheduler(
+		scheduler.WithHistoryFile("runs.jsonl"),
+		scheduler.WithOnRun(func(result scheduler.Result) {
+			switch {
+			case result.Skipped:
+				fmt.Printf("⏭️  %s #%d skipped\n", result.Task, result.Run)
+			case result.Err != nil:
+				fmt.Printf("❌ %s #%d failed: %v\n", result.Task, result.Run, result.Err)
+			default:
+				fmt.Printf("✅ %s #%d in %s:\n%s\n\n", result.Task, result.Run, result.Duration(), result.Output)
+			}
+		}),
+	)
+	err = tasks.AddTask(scheduler.Task{
+		Name:     "haiku",
+		Schedule: "* * * * *", // every minute
+		Agent:    writer,
+		Prompt: `Write a haiku about the time: {{.Now.Format "15:04"}}.
+{{if .LastOutput}}It must not look like the previous one:
+{{.LastOutput}}{{end}}`,
+		Sinks: []scheduler.Sink{
+			scheduler.FileSink{Path: "poems/{{.Date}}.md", Append: true},
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	for _, task := range tasks.Tasks() {
+		fmt.Printf("⏰ %s (%s), next run at %s\n", task.Name, task.Schedule, task.Next.Format("15:04:05"))
+	}
+	// Until Ctrl+C
+	if err := tasks.Start(ctx); err != nil && ctx.Err() == nil {
+		panic(err)
+	}
+	fmt.Println("👋 Bye")
+}
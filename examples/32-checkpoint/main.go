@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/shared"
+)
+
+// Stop the program (Ctrl+C) during the tool calls and run it again:
+// the run resumes from its last checkpoint, the tool calls already executed are not executed again.
+func main() {
+
+	ctx := context.Background()
+
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	store := mu.NewFileCheckpointStore(".checkpoints")
+
+	toolAgent, err := mu.NewAgent(ctx, "Bob",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "hf.co/menlo/jan-nano-gguf:q4_k_m",
+			Temperature: openai.Opt(0.0),
+			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
+				OfAuto: openai.String("auto"),
+			},
+			Tools:             GetToolsIndex(),
+			ParallelToolCalls: openai.Opt(false),
+		}),
+		mu.WithCheckpointStore(store),
+	)
+	if err != nil {
+		panic(err)
+	}
+	checkpointer := toolAgent.(mu.Checkpointer)
+
+	const runID = "sums"
+	finishReason, results, assistantMessage, err := checkpointer.Resume(runID, executeFunction)
+	if errors.Is(err, mu.ErrCheckpointNotFound) {
+		fmt.Println("🚀 New run")
+		messages := []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(`
+				Make the sum of 40 and 2,
+				then make the sum of 5 and 37,
+				then make the sum of 12 and 30,
+				then make the sum of 1 and 41
+			`),
+		}
+		finishReason, results, assistantMessage, err = checkpointer.DetectToolCallsWithCheckpoint(runID, messages, executeFunction)
+	} else {
+		fmt.Println("♻️  Resumed run")
+	}
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Finish Reason: %s\n", finishReason)
+	fmt.Printf("Results: %v\n", results)
+	fmt.Printf("Assistant Message: %s\n", assistantMessage)
+
+	// The run is completed: remove its checkpoint to start again at the next execution
+	if err := store.Delete(runID); err != nil {
+		panic(err)
+	}
+}
+
+func GetToolsIndex() []openai.ChatCompletionToolUnionParam {
+	calculateSumTool := openai.ChatCompletionFunctionTool(shared.FunctionDefinitionParam{
+		Name:        "calculate_sum",
+		Description: openai.String("Calculate the sum of two numbers"),
+		Parameters: shared.FunctionParameters{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"a": map[string]string{
+					"type":        "number",
+					"description": "The first number",
+				},
+				"b": map[string]string{
+					"type":        "number",
+					"description": "The second number",
+				},
+			},
+			"required": []string{"a", "b"},
+		},
+	})
+
+	return []openai.ChatCompletionToolUnionParam{
+		calculateSumTool,
+	}
+}
+
+func executeFunction(functionName string, arguments string) (string, error) {
+	fmt.Printf("🟢 Executing function: %s with arguments: %s\n", functionName, arguments)
+	switch functionName {
+	case "calculate_sum":
+		var args struct {
+			A float64 `json:"a"`
+			B float64 `json:"b"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return `{"error": "Invalid arguments for calculate_sum"}`, nil
+		}
+		// A slow tool: there is time to stop the program
+		time.Sleep(3 * time.Second)
+		return fmt.Sprintf(`{"result": %g}`, args.A+args.B), nil
+
+	default:
+		return `{"error": "Unknown function"}`, &mu.ExitToolCallsLoopError{Message: fmt.Sprintf("Unknown function: %s", functionName)}
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro-agent/micro-agent-go/agent/experimental/a2a"
+	"github.com/micro-agent/micro-agent-go/agent/mu"
+	"github.com/openai/openai-go/v2"
+	"github.com/openai/openai-go/v2/option"
+)
+
+// Start the streaming A2A server of the example 18 (port 7777) first:
+// its skills become the delegate tools of the local agent.
+func main() {
+
+	ctx := context.Background()
+
+	client := openai.NewClient(
+		option.WithBaseURL("http://localhost:12434/engines/llama.cpp/v1"),
+		option.WithAPIKey(""),
+	)
+
+	toolAgent, err := mu.NewAgent(ctx, "Alice",
+		mu.WithClient(client),
+		mu.WithParams(openai.ChatCompletionNewParams{
+			Model:       "hf.co/menlo/jan-nano-gguf:q4_k_m",
+			Temperature: openai.Opt(0.0),
+			ToolChoice: openai.ChatCompletionToolChoiceOptionUnionParam{
+				OfAuto: openai.String("auto"),
+			},
+			ParallelToolCalls: openai.Opt(false),
+		}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	delegation, err := a2a.RegisterDelegateTools(toolAgent, []string{"http://localhost:7777"})
+	if err != nil {
+		panic(err)
+	}
+	for _, tool := range delegation.Tools() {
+		fmt.Printf("🔧 %s (%s at %s)\n", tool.Name, tool.AgentName, tool.AgentURL)
+	}
+	fmt.Println()
+
+	// The chunks of the remote agents and of the local agent are displayed as they arrive
+	display := func(content string) error {
+		fmt.Print(content)
+		return nil
+	}
+
+	messages := []openai.ChatCompletionMessageParamUnion{
+		openai.UserMessage("Ask Bob who is James T. Kirk, then say hello to Bob."),
+	}
+	finishReason, results, _, err := toolAgent.DetectToolCallsStream(messages, delegation.ToolCallback(display), display)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println()
+	fmt.Println("✅ Finish reason:", finishReason)
+	fmt.Println("📦 Delegated tasks:", len(results))
+}
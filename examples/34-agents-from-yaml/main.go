@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/micro-agent/micro-agent-go/agent/approval"
+	"github.com/micro-agent/micro-agent-go/agent/config"
+)
+
+func main() {
+
+	ctx := context.Background()
+
+	document, err := config.Load("agents.yaml")
+	if err != nil {
+		panic(err)
+	}
+
+	// The tool calls whose policy is ask are confirmed in the terminal
+	fleet, err := config.Build(ctx, document, config.WithApprover(approval.NewTerminal()))
+	if err != nil {
+		panic(err)
+	}
+	defer fleet.Close()
+
+	for _, agent := range fleet.Agents() {
+		fmt.Printf("🤖 %s (%s): %s\n", agent.GetName(), agent.Definition.Model, agent.GetDescription())
+	}
+	fmt.Println()
+
+	calculator, _ := fleet.Agent("calculator")
+	messages, err := calculator.Messages("Add 40 and 2, then multiply the result by 10")
+	if err != nil {
+		panic(err)
+	}
+	_, results, _, err := calculator.DetectToolCalls(messages, calculator.ToolCallback(ctx))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("🧮 Results:", results)
+
+	poet, _ := fleet.Agent("poet")
+	messages, err = poet.Messages("Write about the number 420")
+	if err != nil {
+		panic(err)
+	}
+	answer, err := poet.Run(messages)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("📝", answer)
+}
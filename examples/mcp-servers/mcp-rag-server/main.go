@@ -14,12 +14,14 @@ import (
 	"github.com/openai/openai-go/v2" // imported as openai
 	"github.com/openai/openai-go/v2/option"
 
-	"mcp-rag-server/helpers"
-	"mcp-rag-server/rag"
+	"github.com/micro-agent/micro-agent-go/agent/experimental/a2a"
+	"github.com/micro-agent/micro-agent-go/agent/helpers"
+	"github.com/micro-agent/micro-agent-go/agent/rag"
 )
 
 var client openai.Client
-var store rag.MemoryVectorStore
+var store rag.VectorStore
+var records int
 var embeddingsModel string
 
 func main() {
@@ -59,94 +61,105 @@ func main() {
 
 	// -------------------------------------------------
 	// Create a vector store
+	// VECTOR_STORE_URL selects the store: memory (default, persisted to JSON_STORE_FILE_PATH),
+	// redis://, postgres://, qdrant://, bolt: or sqlite: (see rag.NewVectorStore)
 	// -------------------------------------------------
-	store = rag.MemoryVectorStore{
-		Records: make(map[string]rag.VectorRecord),
+	var err error
+	store, err = rag.NewVectorStore(os.Getenv("VECTOR_STORE_URL"))
+	if err != nil {
+		log.Fatalln("😡 Error creating vector store:", err)
 	}
-
-	// Load the vector store from a file if it exists
-	err := store.Load(jsonStoreFilePath)
+	memoryStore, inMemory := store.(*rag.MemoryVectorStore)
+	if inMemory {
+		// Load the vector store from a file if it exists
+		if err := memoryStore.Load(jsonStoreFilePath); err != nil && !os.IsNotExist(err) {
+			log.Fatalln("Error loading vector store:", err)
+		}
+	}
+	existing, err := store.GetAll()
 	if err != nil {
-		if os.IsNotExist(err) {
-			log.Println("🚀 No existing vector store found, starting fresh.")
+		log.Fatalln("Error reading vector store:", err)
+	}
+	records = len(existing)
 
-			// =================================================
-			// CHUNKS:
-			// =================================================
-			contents, err := helpers.GetContentFiles(documentsPath, ".md")
-			if err != nil {
-				log.Fatalln("😡 Error getting content files:", err)
-			}
-			chunks := []string{}
-			fmt.Println("💡 Found", len(contents), "content files to process.")
-			//fmt.Println("📂 Processing content files...", contents)
-			fmt.Println("📝 Processing(Chunking) content files...")
-
-			chunkSize := os.Getenv("CHUNK_SIZE")
-			if chunkSize == "" {
-				chunkSize = "1024"
-			}
-			chunkOverlap := os.Getenv("CHUNK_OVERLAP")
-			if chunkOverlap == "" {
-				chunkOverlap = "256"
-			}
-			chunkSizeInt, err := strconv.Atoi(chunkSize)
-			if err != nil {
-				log.Fatalln("😡 Error converting chunk size to int:", err)
-			}
-			chunkOverlapInt, err := strconv.Atoi(chunkOverlap)
-			if err != nil {
-				log.Fatalln("😡 Error converting chunk overlap to int:", err)
-			}
+	if records > 0 {
+		log.Println("Vector store loaded successfully, total records:", records)
+	} else {
+		log.Println("🚀 No existing vector store found, starting fresh.")
+
+		// =================================================
+		// CHUNKS:
+		// =================================================
+		files, err := helpers.FindContentFiles(documentsPath, helpers.WithExtensions(".md"))
+		if err != nil {
+			log.Fatalln("😡 Error getting content files:", err)
+		}
+		chunks := []string{}
+		fmt.Println("💡 Found", len(files), "content files to process.")
+		fmt.Println("📝 Processing(Chunking) content files...")
 
-			for _, content := range contents {
-				chunks = append(chunks, rag.ChunkText(content, chunkSizeInt, chunkOverlapInt)...)
-				//chunks = append(chunks, rag.SplitTextWithDelimiter(content, "---")...)
-				//chunks = append(chunks, rag.ChunkWithMarkdownHierarchy(content)... )
+		chunkSize := os.Getenv("CHUNK_SIZE")
+		if chunkSize == "" {
+			chunkSize = "1024"
+		}
+		chunkOverlap := os.Getenv("CHUNK_OVERLAP")
+		if chunkOverlap == "" {
+			chunkOverlap = "256"
+		}
+		chunkSizeInt, err := strconv.Atoi(chunkSize)
+		if err != nil {
+			log.Fatalln("😡 Error converting chunk size to int:", err)
+		}
+		chunkOverlapInt, err := strconv.Atoi(chunkOverlap)
+		if err != nil {
+			log.Fatalln("😡 Error converting chunk overlap to int:", err)
+		}
 
-			}
+		for _, file := range files {
+			chunks = append(chunks, rag.ChunkText(file.Content, chunkSizeInt, chunkOverlapInt)...)
+			//chunks = append(chunks, rag.SplitTextWithDelimiter(content, "---")...)
+			//chunks = append(chunks, rag.ChunkWithMarkdownHierarchy(content)... )
+		}
 
-			// -------------------------------------------------
-			// Create and save the embeddings from the chunks
-			// -------------------------------------------------
-			fmt.Println("⏳ Creating the embeddings...")
-
-			for idx, chunk := range chunks {
-				embeddingsResponse, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
-					Input: openai.EmbeddingNewParamsInputUnion{
-						OfString: openai.String(chunk),
-					},
-					Model: embeddingsModel,
-				})
+		// -------------------------------------------------
+		// Create and save the embeddings from the chunks
+		// -------------------------------------------------
+		fmt.Println("⏳ Creating the embeddings...")
+
+		for idx, chunk := range chunks {
+			embeddingsResponse, err := client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+				Input: openai.EmbeddingNewParamsInputUnion{
+					OfString: openai.String(chunk),
+				},
+				Model: embeddingsModel,
+			})
 
-				if err != nil {
-					fmt.Println(err)
+			if err != nil {
+				fmt.Println(err)
+			} else {
+				_, errSave := store.Save(rag.VectorRecord{
+					Prompt:    chunk,
+					Embedding: embeddingsResponse.Data[0].Embedding,
+				})
+				if errSave != nil {
+					fmt.Println("😡:", errSave)
 				} else {
-					_, errSave := store.Save(rag.VectorRecord{
-						Prompt:    chunk,
-						Embedding: embeddingsResponse.Data[0].Embedding,
-					})
-					if errSave != nil {
-						fmt.Println("😡:", errSave)
-					}
-					fmt.Println("✅ Chunk", idx, "saved with embedding:", len(embeddingsResponse.Data[0].Embedding))
+					records++
 				}
+				fmt.Println("✅ Chunk", idx, "saved with embedding:", len(embeddingsResponse.Data[0].Embedding))
 			}
+		}
 
-			fmt.Println("✋", "Embeddings created, total of records", len(store.Records))
-			err = store.Persist(jsonStoreFilePath)
+		fmt.Println("✋", "Embeddings created, total of records", records)
+		if inMemory {
+			err = memoryStore.Persist(jsonStoreFilePath)
 			if err != nil {
 				log.Fatalln("😡 Error saving vector store:", err)
 			}
 			fmt.Println("✅ Vector store saved to", jsonStoreFilePath)
-			fmt.Println("💾 Vector store initialized with", len(store.Records), "records.")
-			fmt.Println()
-
-		} else {
-			log.Fatalln("Error loading vector store:", err)
 		}
-	} else {
-		log.Println("Vector store loaded successfully, total records:", len(store.Records))
+		fmt.Println("💾 Vector store initialized with", records, "records.")
+		fmt.Println()
 	}
 
 	// =================================================
@@ -184,8 +197,20 @@ func main() {
 	// Register MCP handler with the mux
 	mux.Handle("/mcp", httpServer)
 
+	// Let the browser-based MCP clients of the origins of MCP_CORS_ORIGINS call the server
+	// (comma-separated, "*" for every origin), the requests are passed through untouched otherwise
+	handler := http.Handler(mux)
+	if origins := a2a.ParseCORSOrigins(os.Getenv("MCP_CORS_ORIGINS")); origins != nil {
+		cors := a2a.DefaultCORSConfig()
+		cors.AllowedOrigins = origins
+		cors.AllowedMethods = append(cors.AllowedMethods, http.MethodDelete)
+		cors.AllowedHeaders = append(cors.AllowedHeaders, "Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID")
+		cors.ExposedHeaders = []string{"Mcp-Session-Id"}
+		handler = a2a.CORSHandler(cors, mux)
+	}
+
 	// Start the HTTP server with custom mux
-	log.Fatal(http.ListenAndServe(":"+httpPort, mux))
+	log.Fatal(http.ListenAndServe(":"+httpPort, handler))
 }
 
 func searchInDocHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
@@ -258,7 +283,7 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	// Check if vector store is initialized and has records
-	if len(store.Records) == 0 {
+	if records == 0 {
 		w.WriteHeader(http.StatusServiceUnavailable)
 		response := map[string]interface{}{
 			"status": "unhealthy",
@@ -271,7 +296,7 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	response := map[string]any{
 		"status":           "healthy",
-		"records":          len(store.Records),
+		"records":          records,
 		"embeddings_model": embeddingsModel,
 	}
 	json.NewEncoder(w).Encode(response)
@@ -15,5 +15,6 @@ require (
 	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/mark3labs/mcp-go v0.31.0
+	github.com/micro-agent/micro-agent-go v0.0.0
 	github.com/openai/openai-go/v2 v2.0.2
 )
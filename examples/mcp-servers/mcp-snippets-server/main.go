@@ -10,6 +10,7 @@ import (
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
+	"github.com/micro-agent/micro-agent-go/agent/experimental/a2a"
 
 	"github.com/openai/openai-go/v2" // imported as openai
 	"github.com/openai/openai-go/v2/option"
@@ -180,8 +181,20 @@ func main() {
 	// Register MCP handler with the mux
 	mux.Handle("/mcp", httpServer)
 
+	// Let the browser-based MCP clients of the origins of MCP_CORS_ORIGINS call the server
+	// (comma-separated, "*" for every origin), the requests are passed through untouched otherwise
+	handler := http.Handler(mux)
+	if origins := a2a.ParseCORSOrigins(os.Getenv("MCP_CORS_ORIGINS")); origins != nil {
+		cors := a2a.DefaultCORSConfig()
+		cors.AllowedOrigins = origins
+		cors.AllowedMethods = append(cors.AllowedMethods, http.MethodDelete)
+		cors.AllowedHeaders = append(cors.AllowedHeaders, "Mcp-Session-Id", "Mcp-Protocol-Version", "Last-Event-ID")
+		cors.ExposedHeaders = []string{"Mcp-Session-Id"}
+		handler = a2a.CORSHandler(cors, mux)
+	}
+
 	// Start the HTTP server with custom mux
-	log.Fatal(http.ListenAndServe(":"+httpPort, mux))
+	log.Fatal(http.ListenAndServe(":"+httpPort, handler))
 }
 
 func searchInDocHandler(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {